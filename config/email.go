@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -24,12 +26,19 @@ var (
 	PusherSecret  string
 	PusherCluster string
 
+	// RealtimeBackplane carries the realtime events between the instances: "local" (default)
+	// for a single instance, or "redis"
+	RealtimeBackplane string
+	// RealtimeRedisURL is like redis://:password@localhost:6379/0
+	RealtimeRedisURL     string
+	RealtimeRedisChannel string
+
 	CloudinaryURL string
 	OpenAIKey     string
 	PDFContextURL string
 
-	GoogleClientID string
-	// GoogleClientSecret string
+	GoogleClientID     string
+	GoogleClientSecret string
 	GithubClientID     string
 	GithubClientSecret string
 	GoogleCredentials  string
@@ -43,6 +52,47 @@ var (
 	TwilioAccountSID string
 	TwilioAuthToken  string
 	TwilioFromNumber string
+
+	// StorageBackend selects the repositories: "firestore" (default) or "memory"
+	StorageBackend string
+	// ForumStorageBackend moves groups and forums to SQL when set to "sql"
+	ForumStorageBackend string
+	// SQLDriver is "sqlite" (default) or "postgres"
+	SQLDriver string
+	SQLDSN    string
+
+	// JWTAlgorithm is HS256 (default), RS256 or EdDSA
+	JWTAlgorithm string
+	// JWTKeyID is the "kid" of the signing key
+	JWTKeyID          string
+	JWTSigningKey     string
+	JWTSigningKeyFile string
+	// JWTVerificationKeys is a JSON array of {"kid", "alg", "key"} accepted during key rotation
+	JWTVerificationKeys string
+	JWTIssuer           string
+	JWTAccessTokenTTL   time.Duration
+	JWTRefreshTokenTTL  time.Duration
+
+	// IdentityProvider is "firebase" or "local". It defaults to "local" with the in-memory storage.
+	IdentityProvider string
+	// PasswordHashAlgorithm is "argon2id" (default) or "bcrypt" for the local identity provider
+	PasswordHashAlgorithm string
+	// MigrateFromFirebase lets the local identity provider check the passwords it
+	// does not know yet with Firebase, and keep them on the first successful login
+	MigrateFromFirebase bool
+
+	// RequireEmailVerification keeps unverified email users from messaging and creating projects
+	RequireEmailVerification bool
+	EmailVerificationTTL     time.Duration
+
+	// OIDCProviders is a JSON array of {"name", "issuer", "clientId", "clientSecret", "scopes"}
+	// adding OpenID Connect providers to Google, GitHub and LinkedIn
+	OIDCProviders string
+	// OAuthRedirectURL is where providers send the users back, "{provider}" is replaced by the provider name
+	OAuthRedirectURL string
+
+	// TwoFactorRequiredRoles must enable two-factor authentication to use their permissions
+	TwoFactorRequiredRoles []string
 )
 
 func LoadConfig() {
@@ -78,7 +128,7 @@ func LoadConfig() {
 	PDFContextURL = os.Getenv("PDF_CONTEXT_URL")
 
 	GoogleClientID = os.Getenv("GOOGLE_CLIENT_ID")
-	// GoogleClientSecret = os.Getenv("GOOGLE_CLIENT_SECRET")
+	GoogleClientSecret = os.Getenv("GOOGLE_CLIENT_SECRET")
 	GithubClientID = os.Getenv("GITHUB_CLIENT_ID")
 	GithubClientSecret = os.Getenv("GITHUB_CLIENT_SECRET")
 	GoogleCredentials = JsonServiceAccountPath
@@ -92,4 +142,70 @@ func LoadConfig() {
 	TwilioAccountSID = os.Getenv("TWILIO_ACCOUNT_ID")
 	TwilioAuthToken = os.Getenv("TWILIO_AUTH_TOKEN")
 	TwilioFromNumber = os.Getenv("TWILIO_FROM_NUMBER")
+
+	RealtimeBackplane = os.Getenv("REALTIME_BACKPLANE")
+	RealtimeRedisURL = os.Getenv("REALTIME_REDIS_URL")
+	if RealtimeRedisURL == "" {
+		RealtimeRedisURL = "redis://localhost:6379"
+	}
+	RealtimeRedisChannel = os.Getenv("REALTIME_REDIS_CHANNEL")
+	if RealtimeRedisChannel == "" {
+		RealtimeRedisChannel = "letusconnect:realtime"
+	}
+
+	StorageBackend = os.Getenv("STORAGE_BACKEND")
+	if StorageBackend == "" {
+		StorageBackend = "firestore"
+	}
+
+	ForumStorageBackend = os.Getenv("FORUM_STORAGE_BACKEND")
+	SQLDriver = os.Getenv("SQL_DRIVER")
+	if SQLDriver == "" {
+		SQLDriver = "sqlite"
+	}
+	SQLDSN = os.Getenv("SQL_DSN")
+	if SQLDSN == "" {
+		SQLDSN = "letusconnect.db"
+	}
+
+	JWTAlgorithm = os.Getenv("JWT_ALGORITHM")
+	JWTKeyID = os.Getenv("JWT_KEY_ID")
+	JWTSigningKey = os.Getenv("JWT_SIGNING_KEY")
+	JWTSigningKeyFile = os.Getenv("JWT_SIGNING_KEY_FILE")
+	JWTVerificationKeys = os.Getenv("JWT_VERIFICATION_KEYS")
+	JWTIssuer = os.Getenv("JWT_ISSUER")
+	if ttl, err := time.ParseDuration(os.Getenv("JWT_ACCESS_TOKEN_TTL")); err == nil {
+		JWTAccessTokenTTL = ttl
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("JWT_REFRESH_TOKEN_TTL")); err == nil {
+		JWTRefreshTokenTTL = ttl
+	}
+
+	IdentityProvider = os.Getenv("IDENTITY_PROVIDER")
+	if IdentityProvider == "" {
+		IdentityProvider = "firebase"
+		if StorageBackend == "memory" {
+			IdentityProvider = "local"
+		}
+	}
+	PasswordHashAlgorithm = os.Getenv("PASSWORD_HASH_ALGORITHM")
+	MigrateFromFirebase = os.Getenv("IDENTITY_MIGRATE_FROM_FIREBASE") == "true"
+
+	RequireEmailVerification = os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true"
+	if ttl, err := time.ParseDuration(os.Getenv("EMAIL_VERIFICATION_TTL")); err == nil {
+		EmailVerificationTTL = ttl
+	}
+
+	OIDCProviders = os.Getenv("OIDC_PROVIDERS")
+	OAuthRedirectURL = os.Getenv("OAUTH_REDIRECT_URL")
+	if OAuthRedirectURL == "" {
+		OAuthRedirectURL = strings.TrimSuffix(AppURL, "/") + "/auth/callback/{provider}"
+	}
+
+	TwoFactorRequiredRoles = nil
+	for _, role := range strings.Split(os.Getenv("TWO_FACTOR_REQUIRED_ROLES"), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			TwoFactorRequiredRoles = append(TwoFactorRequiredRoles, role)
+		}
+	}
 }
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+type AccountMergeHandler struct {
+	accountMergeService *services.AccountMergeService
+}
+
+func NewAccountMergeHandler(accountMergeService *services.AccountMergeService) *AccountMergeHandler {
+	return &AccountMergeHandler{accountMergeService: accountMergeService}
+}
+
+// MergeAccounts merges the source account into the target account and deletes the source
+func (h *AccountMergeHandler) MergeAccounts(c *fiber.Ctx) error {
+	var payload struct {
+		SourceUID string `json:"sourceUid"`
+		TargetUID string `json:"targetUid"`
+	}
+	if err := c.BodyParser(&payload); err != nil || strings.TrimSpace(payload.SourceUID) == "" || strings.TrimSpace(payload.TargetUID) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Source and target users are required",
+		})
+	}
+
+	middleware.SetAuditTarget(c, strings.TrimSpace(payload.TargetUID))
+	middleware.SetAuditDetail(c, "merged "+strings.TrimSpace(payload.SourceUID))
+
+	merge, err := h.accountMergeService.Merge(context.Background(), middleware.CurrentUID(c),
+		strings.TrimSpace(payload.SourceUID), strings.TrimSpace(payload.TargetUID))
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	case errors.Is(err, services.ErrMergeSameUser), errors.Is(err, services.ErrMergeOwnAccount):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		log.Printf("Error merging accounts: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to merge the accounts",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Accounts merged successfully",
+		"merge":   merge,
+	})
+}
+
+// ListMerges returns the accounts merged into a user
+func (h *AccountMergeHandler) ListMerges(c *fiber.Ctx) error {
+	uid := c.Params("uid")
+
+	merges, err := h.accountMergeService.ListMerges(context.Background(), uid)
+	if err != nil {
+		log.Printf("Error listing account merges: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch account merges",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"uid":    uid,
+		"merges": merges,
+	})
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// auditQuery reads the filters of the audit log from the query string
+func auditQuery(c *fiber.Ctx) (repository.AuditQuery, error) {
+	query := repository.AuditQuery{
+		ActorUID: c.Query("actor"),
+		Action:   c.Query("action"),
+		TargetID: c.Query("target"),
+		Outcome:  c.Query("outcome"),
+		After:    c.Query("after"),
+	}
+
+	for name, bound := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		if value := c.Query(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, fmt.Errorf("%s must be an RFC 3339 time", name)
+			}
+			*bound = parsed
+		}
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return query, errors.New("limit must be a positive number")
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
+
+// ListAuditEvents returns one page of the audit log, newest first
+func (h *AuditHandler) ListAuditEvents(c *fiber.Ctx) error {
+	query, err := auditQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	events, err := h.auditService.List(context.Background(), query)
+	if errors.Is(err, repository.ErrNotFound) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Unknown audit event in after",
+		})
+	}
+	if err != nil {
+		log.Printf("Error listing audit events: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch audit events",
+		})
+	}
+
+	response := fiber.Map{
+		"events": events,
+	}
+	if len(events) > 0 {
+		response["next"] = events[len(events)-1].ID
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// ExportAuditEvents streams every event matching the filters as newline-delimited JSON
+func (h *AuditHandler) ExportAuditEvents(c *fiber.Ctx) error {
+	query, err := auditQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="audit-log-%s.ndjson"`, time.Now().UTC().Format("20060102-150405")))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		count, err := h.auditService.Export(context.Background(), query, w)
+		if err != nil {
+			log.Printf("Error exporting audit events after %d events: %v", count, err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("Error flushing audit export: %v", err)
+		}
+	})
+	return nil
+}
@@ -1,28 +1,27 @@
 package handlers
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
+	"math"
 	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	// "os"
 	"strings"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/rogerjeasy/go-letusconnect/config"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 	"github.com/rogerjeasy/go-letusconnect/services"
-	"google.golang.org/api/iterator"
 )
 
 type AuthHandler struct {
@@ -37,8 +36,6 @@ func NewAuthHandler(authService *services.AuthService, containerService *service
 	}
 }
 
-var jwtSecretKey = []byte("your_jwt_secret_key")
-
 func generateRandomAvatar() string {
 
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -47,68 +44,77 @@ func generateRandomAvatar() string {
 	return fmt.Sprintf("https://picsum.photos/seed/%s/150/150?nature", uniqueID)
 }
 
-func GenerateJWT(user *models.User) (string, error) {
-	claims := jwt.MapClaims{
-		"uid":   user.UID,
-		"email": user.Email,
-		"exp":   time.Now().Add(24 * time.Hour).Unix(), // Token expires in 24 hours
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecretKey)
-}
-
-func validateToken(tokenString string) (string, error) {
-	if strings.TrimSpace(tokenString) == "" {
-		log.Printf("Empty token received")
-		return "", errors.New("token cannot be empty")
-	}
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			log.Printf("Invalid signing method: %v", token.Header["alg"])
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecretKey, nil
-	})
+// refreshTokenCookie holds the refresh token of browser clients. It is only sent to the auth routes.
+const refreshTokenCookie = "refresh_token"
 
+// startSession signs the user in on the requesting device and sets the session cookies
+func (a *AuthHandler) startSession(c *fiber.Ctx, user *models.User) (*services.TokenPair, error) {
+	pair, err := a.containerService.SessionService.CreateSession(context.Background(), user, c.Get(fiber.HeaderUserAgent), c.IP())
 	if err != nil {
-		log.Printf("Token parsing error: %v", err)
-		return "", fmt.Errorf("failed to parse token: %v", err)
-	}
-
-	if !token.Valid {
-		log.Printf("Token is invalid")
-		return "", errors.New("invalid token")
+		return nil, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		log.Printf("Failed to extract token claims")
-		return "", errors.New("invalid token claims")
-	}
+	setSessionCookies(c, pair)
+	return pair, nil
+}
 
-	if exp, ok := claims["exp"].(float64); ok {
-		if int64(exp) < time.Now().Unix() {
-			log.Printf("Token expired at %v", time.Unix(int64(exp), 0))
-			return "", errors.New("token has expired")
-		}
-	} else {
-		log.Printf("Token missing expiration claim")
-		return "", errors.New("invalid token: missing expiration")
-	}
+// setSessionCookies stores the access and refresh tokens in HTTP-only cookies
+func setSessionCookies(c *fiber.Ctx, pair *services.TokenPair) {
+	c.Cookie(&fiber.Cookie{
+		Name:     "jwt",
+		Value:    pair.AccessToken,
+		Expires:  pair.AccessExpiresAt,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Lax",
+		Path:     "/",
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     refreshTokenCookie,
+		Value:    pair.RefreshToken,
+		Expires:  pair.RefreshExpiresAt,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Strict",
+		Path:     "/api/v1/auth",
+	})
+}
 
-	uid, ok := claims["uid"].(string)
-	if !ok {
-		log.Printf("Missing or invalid UID in token claims")
-		return "", errors.New("missing or invalid UID in token")
-	}
+// clearSessionCookies removes the session cookies from the browser
+func clearSessionCookies(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     "jwt",
+		Value:    "",
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Lax",
+		Path:     "/",
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     refreshTokenCookie,
+		Value:    "",
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Strict",
+		Path:     "/api/v1/auth",
+	})
+}
 
-	if strings.TrimSpace(uid) == "" {
-		log.Printf("Empty UID in token claims")
-		return "", errors.New("empty UID in token")
-	}
+// sessionResponse adds the tokens of a session to a response body
+func sessionResponse(body fiber.Map, pair *services.TokenPair) fiber.Map {
+	body["token"] = pair.AccessToken
+	body["expiresAt"] = pair.AccessExpiresAt.Format(time.RFC3339)
+	body["refreshToken"] = pair.RefreshToken
+	body["refreshExpiresAt"] = pair.RefreshExpiresAt.Format(time.RFC3339)
+	return body
+}
 
-	return uid, nil
+// GetJWKS publishes the public keys that verify access tokens
+func GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(services.Tokens.JWKS())
 }
 
 func FormatTime(t time.Time, layout string) string {
@@ -145,12 +151,37 @@ func (a *AuthHandler) Register(c *fiber.Ctx) error {
 	ctx := context.Background()
 
 	// Check for existing user
-	if err := checkExistingUser(ctx, providerData); err != nil {
+	if err := checkExistingUser(ctx, a.containerService.AuthService, providerData); err != nil {
 		return c.Status(http.StatusConflict).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	// Create the account with the identity provider
+	uid, err := a.containerService.IdentityProvider.CreateAccount(ctx, providerData)
+	if errors.Is(err, services.ErrExternalSignUpUnsupported) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("Error creating account: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to authenticate user",
+		})
+	}
+
+	// The Google or GitHub account the identity provider verified, linked below so that
+	// the user can also sign in through the OAuth endpoints. Only such an account proves
+	// the user owns the email address.
+	var subject string
+	if providerType != models.EmailPassword {
+		subject, err = a.containerService.IdentityProvider.ExternalSubject(ctx, uid, providerType)
+		if err != nil {
+			log.Printf("Error reading %s account: %v", providerType, err)
+		}
+	}
+
 	var profilePictureURL string
 	if providerData.PhotoURL != "" {
 		profilePictureURL = providerData.PhotoURL
@@ -165,21 +196,12 @@ func (a *AuthHandler) Register(c *fiber.Ctx) error {
 		uploadedURL = profilePictureURL
 	}
 
-	// Create or get Firebase user
-	authUser, err := createFirebaseUser(ctx, providerData)
-	if err != nil {
-		log.Printf("Error with Firebase auth: %v", err)
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to authenticate user",
-		})
-	}
-
 	// Create user model
 	currentTime := time.Now()
 	customFormat := "Monday, Jan 2, 2006 at 3:04 PM"
 
 	user := models.User{
-		UID:              authUser.UID,
+		UID:              uid,
 		Username:         providerData.Username,
 		FirstName:        providerData.FirstName,
 		LastName:         providerData.LastName,
@@ -188,9 +210,9 @@ func (a *AuthHandler) Register(c *fiber.Ctx) error {
 		Program:          providerData.Program,
 		AccountCreatedAt: FormatTime(currentTime, customFormat),
 		IsActive:         true,
-		IsVerified:       providerType != models.EmailPassword,
+		IsVerified:       subject != "",
 		Role:             []string{"user"},
-		IsOnline:         true,
+		IsOnline:         false,
 		Bio:              "",
 		PhoneNumber:      "",
 		GraduationYear:   0,
@@ -209,22 +231,31 @@ func (a *AuthHandler) Register(c *fiber.Ctx) error {
 		})
 	}
 
-	// Generate JWT token
-	token, err := GenerateJWT(&user)
+	if subject != "" {
+		profile := &services.ExternalProfile{Subject: subject, Email: user.Email, EmailVerified: true}
+		if _, err := a.containerService.OAuthService.Link(ctx, uid, string(providerType), profile); err != nil {
+			log.Printf("Error linking %s account: %v", providerType, err)
+		}
+	}
+
+	middleware.SetAuditActor(c, user.UID, user.Email)
+
+	// Sign the user in on this device
+	pair, err := a.startSession(c, &user)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate token",
 		})
 	}
 
-	// Set JWT cookie
-	c.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    token,
-		Expires:  time.Now().Add(24 * time.Hour),
-		HTTPOnly: true,
-		Secure:   true,
-	})
+	// Send the verification link to the users no provider verified asynchronously
+	if !user.IsVerified {
+		go func() {
+			if err := a.sendVerificationEmail(context.Background(), &user); err != nil {
+				log.Printf("Error sending verification email: %v", err)
+			}
+		}()
+	}
 
 	// Send welcome email asynchronously
 	go func() {
@@ -240,20 +271,12 @@ func (a *AuthHandler) Register(c *fiber.Ctx) error {
 		}
 	}()
 
-	if err != nil {
-		log.Printf("Error creating user in Firebase Auth: %v", err)
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create user in Firebase Authentication",
-		})
-	}
-
 	// Map to frontend format and return response
 	frontendUser := mappers.MapUserBackendToFrontend(mappers.MapUserFrontendToBackend(&user))
-	return c.Status(http.StatusCreated).JSON(fiber.Map{
+	return c.Status(http.StatusCreated).JSON(sessionResponse(fiber.Map{
 		"message": "You have successfully created an account",
 		"user":    frontendUser,
-		"token":   token,
-	})
+	}, pair))
 }
 
 func (a *AuthHandler) Login(c *fiber.Ctx) error {
@@ -274,232 +297,616 @@ func (a *AuthHandler) Login(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	var userQuery firestore.Query
 	emailOrUsername := strings.TrimSpace(loginData.EmailOrUsername)
 
-	userQuery, err := a.containerService.AuthService.CheckEmailorUsername(ctx, emailOrUsername)
-	if err != nil {
+	dbUser, err := a.containerService.AuthService.GetUserByEmailOrUsername(ctx, emailOrUsername)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to construct user query",
+			"error": "Failed to retrieve user data",
 		})
 	}
 
-	// Execute the query
-	iter := userQuery.Documents(ctx)
-	defer iter.Stop()
+	if dbUser != nil {
+		middleware.SetAuditActor(c, dbUser.UID, dbUser.Email)
+	} else {
+		middleware.SetAuditDetail(c, "unknown account "+emailOrUsername)
+	}
 
-	doc, err := iter.Next()
-	if err == iterator.Done {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid credentials",
+	// Unknown accounts are throttled like the others, so that the answers tell nothing apart
+	protection := a.containerService.LoginProtectionService
+	if err := protection.Check(ctx, dbUser, emailOrUsername, c.IP()); err != nil {
+		return loginBlocked(c, err)
+	}
+
+	if dbUser == nil {
+		return a.loginFailed(c, nil, emailOrUsername, "Invalid credentials")
+	}
+
+	userEmail := dbUser.Email
+	if userEmail == "" {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Invalid user data",
+		})
+	}
+
+	if err := a.containerService.PasswordService.VerifyPassword(ctx, dbUser, loginData.Password); err != nil {
+		if !errors.Is(err, services.ErrInvalidCredentials) {
+			log.Printf("Error verifying password: %v", err)
+		}
+		return a.loginFailed(c, dbUser, emailOrUsername, "Invalid credentials")
+	}
+
+	// The failed logins are cleared once the second factor, if any, is checked too
+	return a.signIn(c, dbUser)
+}
+
+// loginBlocked answers a login that has to wait or whose account is locked out
+func loginBlocked(c *fiber.Ctx, err error) error {
+	var locked *services.AccountLockedError
+	if errors.As(err, &locked) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(time.Until(locked.Until).Seconds()))))
+		return c.Status(http.StatusLocked).JSON(fiber.Map{
+			"error":       locked.Error(),
+			"lockedUntil": locked.Until,
 		})
 	}
+
+	var throttled *services.ThrottledError
+	if errors.As(err, &throttled) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		return c.Status(http.StatusTooManyRequests).JSON(fiber.Map{
+			"error": throttled.Error(),
+		})
+	}
+
+	log.Printf("Error checking failed logins: %v", err)
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to check login attempts",
+	})
+}
+
+// loginFailed counts a failed login, a wrong password or second factor, and answers with
+// message. When it locks a known account out, the user is warned by email, with an unlock
+// link, and with a notification.
+func (a *AuthHandler) loginFailed(c *fiber.Ctx, dbUser *models.User, emailOrUsername, message string) error {
+	ipAddress := c.IP()
+	lockout, err := a.containerService.LoginProtectionService.RecordFailure(context.Background(), dbUser, emailOrUsername, ipAddress)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve user data",
+		log.Printf("Error recording failed login: %v", err)
+	}
+	if lockout == nil {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": message,
 		})
 	}
 
-	var dbUser map[string]interface{}
-	if err := doc.DataTo(&dbUser); err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to process user data",
+	if lockout.UnlockToken != "" {
+		protection := a.containerService.LoginProtectionService
+		link := fmt.Sprintf("%s/unlock-account?token=%s", strings.TrimRight(config.AppURL, "/"), url.QueryEscape(lockout.UnlockToken))
+		go func() {
+			if err := SendAccountLockedEmail(dbUser.Email, dbUser.Username, link, ipAddress, time.Until(lockout.Until), protection.UnlockTTL()); err != nil {
+				log.Printf("Error sending account locked email: %v", err)
+			}
+			if err := a.containerService.GeneralNotificationService.SendAccountLockedNotification(context.Background(), dbUser, lockout.Until, ipAddress); err != nil {
+				log.Printf("Failed to send account locked notification: %v", err)
+			}
+		}()
+	}
+
+	return loginBlocked(c, &services.AccountLockedError{Until: lockout.Until})
+}
+
+// UnlockAccount lifts a lockout with the token of the link mailed when the account was locked
+func (a *AuthHandler) UnlockAccount(c *fiber.Ctx) error {
+	var payload struct {
+		Token string `json:"token"`
+	}
+	if err := c.BodyParser(&payload); err != nil || strings.TrimSpace(payload.Token) == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token is required",
 		})
 	}
 
-	userEmail, ok := dbUser["email"].(string)
-	if !ok {
+	user, err := a.containerService.LoginProtectionService.Unlock(context.Background(), payload.Token)
+	if errors.Is(err, services.ErrInvalidUnlockToken) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("Error unlocking account: %v", err)
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user data",
+			"error": "Failed to unlock account",
 		})
 	}
 
-	payload := map[string]string{
-		"email":             userEmail,
-		"password":          loginData.Password,
-		"returnSecureToken": "true",
-	}
+	middleware.SetAuditActor(c, user.UID, user.Email)
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Your account has been unlocked, you can log in again",
+	})
+}
 
-	payloadBytes, err := json.Marshal(payload)
+// signIn signs in a user whose first factor was checked, or answers with a login
+// challenge when the user has a second factor
+func (a *AuthHandler) signIn(c *fiber.Ctx, dbUser *models.User) error {
+	ctx := context.Background()
+	middleware.SetAuditActor(c, dbUser.UID, dbUser.Email)
+
+	// Users with a second factor finish signing in with LoginTwoFactor
+	enabled, err := a.containerService.TwoFactorService.IsEnabled(ctx, dbUser.UID)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to process authentication request",
+			"error": "Failed to check two-factor authentication",
+		})
+	}
+	if enabled {
+		challenge, expiresAt, err := a.containerService.TwoFactorService.CreateChallenge(ctx, dbUser)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create login challenge",
+			})
+		}
+		return c.Status(http.StatusOK).JSON(fiber.Map{
+			"message":           "Enter the code from your authenticator app",
+			"twoFactorRequired": true,
+			"challengeToken":    challenge,
+			"expiresAt":         expiresAt,
 		})
 	}
 
-	// Make the REST API request to Firebase
-	resp, err := http.Post(config.FirebaseSignInURL, "application/json", bytes.NewReader(payloadBytes))
-	if err != nil || resp.StatusCode != http.StatusOK {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid credentials",
+	return a.completeLogin(c, dbUser)
+}
+
+// LoginTwoFactor finishes a login started with Login, with a code from the
+// authenticator app or a recovery code
+func (a *AuthHandler) LoginTwoFactor(c *fiber.Ctx) error {
+	var payload struct {
+		ChallengeToken string `json:"challengeToken"`
+		Code           string `json:"code"`
+	}
+	if err := c.BodyParser(&payload); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request payload",
+		})
+	}
+	if payload.ChallengeToken == "" || strings.TrimSpace(payload.Code) == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Challenge token and code are required",
 		})
 	}
-	defer resp.Body.Close()
 
-	// Parse the Firebase response
-	bodyBytes, _ := ioutil.ReadAll(resp.Body)
-	var firebaseResponse map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &firebaseResponse); err != nil {
+	ctx := context.Background()
+	challengeUser, err := a.containerService.TwoFactorService.ChallengeUser(ctx, payload.ChallengeToken)
+	if errors.Is(err, services.ErrInvalidLoginChallenge) {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to process authentication response",
+			"error": "Failed to verify two-factor code",
 		})
 	}
+	middleware.SetAuditActor(c, challengeUser.UID, challengeUser.Email)
 
-	// Convert Firestore data to the `models.User` struct
-	backendUser := mappers.MapBackendToUser(dbUser)
+	// Wrong codes count against the account like wrong passwords, so that logging in
+	// again for a new challenge does not allow more guesses
+	protection := a.containerService.LoginProtectionService
+	if err := protection.Check(ctx, challengeUser, challengeUser.Email, c.IP()); err != nil {
+		return loginBlocked(c, err)
+	}
 
-	// Generate JWT token
-	token, err := GenerateJWT(&backendUser)
+	dbUser, err := a.containerService.TwoFactorService.CompleteChallenge(ctx, payload.ChallengeToken, payload.Code)
+	if errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		return a.loginFailed(c, challengeUser, challengeUser.Email, err.Error())
+	}
+	if errors.Is(err, services.ErrInvalidLoginChallenge) || errors.Is(err, services.ErrTwoFactorNotEnabled) {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate token",
+			"error": "Failed to verify two-factor code",
 		})
 	}
 
-	// Set JWT token as a cookie
-	c.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    token,
-		Expires:  time.Now().Add(24 * time.Hour),
-		HTTPOnly: true,
-		Secure:   true,
-	})
+	return a.completeLogin(c, dbUser)
+}
 
-	// Update user's online status
-	backendUser.IsOnline = true
-	backendUpdates := mappers.MapUserFrontendToBackend(&backendUser)
+// completeLogin signs in a user whose credentials were checked, with their second factor
+func (a *AuthHandler) completeLogin(c *fiber.Ctx, dbUser *models.User) error {
+	ctx := context.Background()
+	backendUser := *dbUser
+	middleware.SetAuditActor(c, backendUser.UID, backendUser.Email)
+
+	if err := a.containerService.LoginProtectionService.Clear(ctx, backendUser.UID); err != nil {
+		log.Printf("Error clearing failed logins: %v", err)
+	}
 
-	// Update Firestore document
-	_, err = doc.Ref.Set(ctx, backendUpdates, firestore.MergeAll)
+	// Sign the user in on this device
+	pair, err := a.startSession(c, &backendUser)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update user status",
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate token",
 		})
 	}
 
 	// Map backend user to frontend format
 	frontendUser := mappers.MapUserToFrontend(&backendUser)
 
-	return c.Status(http.StatusOK).JSON(fiber.Map{
+	body := fiber.Map{
 		"message": "You have successfully logged in to your account",
-		"token":   token,
 		"user":    frontendUser,
-	})
+	}
+	if a.containerService.TwoFactorService.RequiredFor(backendUser.Role) {
+		// The role keeps its permissions only once a second factor is enabled
+		enabled, err := a.containerService.TwoFactorService.IsEnabled(ctx, backendUser.UID)
+		if err == nil && !enabled {
+			body["twoFactorSetupRequired"] = true
+		}
+	}
+
+	return c.Status(http.StatusOK).JSON(sessionResponse(body, pair))
 }
 
+// Logout signs the current device out: its session is revoked so that neither its
+// access token nor its refresh token can be used again. Its realtime connection is closed
+// when it is next revalidated, which takes the device out of the presence of the user.
 func (a *AuthHandler) Logout(c *fiber.Ctx) error {
+	principal := middleware.CurrentPrincipal(c)
+	uid := principal.UID
+
+	ctx := context.Background()
+	if err := a.containerService.SessionService.RevokeSession(ctx, uid, principal.SessionID, "logout"); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke session",
+		})
+	}
+	clearSessionCookies(c)
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Successfully logged out",
+	})
+}
 
-	uid, err := ExtractAndValidateToken(c)
+// GetSession returns the user signed in with the access token. Expired access tokens
+// are renewed with RefreshSession, not here.
+func (a *AuthHandler) GetSession(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
+
+	// Echo the token the request was authenticated with
+	token := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = c.Cookies("jwt")
+	}
+
+	backendUser, err := a.containerService.AuthService.GetUserByUID(uid)
 	if err != nil {
+		log.Printf("Error fetching user data: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch user data",
+		})
+	}
+
+	frontendUser := mappers.MapUserToFrontend(backendUser)
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"user":  frontendUser,
+		"token": token,
+	})
+}
+
+// RefreshSession exchanges a refresh token, from the body or the refresh_token cookie,
+// for a new access token and a new refresh token
+func (a *AuthHandler) RefreshSession(c *fiber.Ctx) error {
+	var payload struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request payload",
+			})
+		}
+	}
+	if payload.RefreshToken == "" {
+		payload.RefreshToken = c.Cookies(refreshTokenCookie)
+	}
+	if payload.RefreshToken == "" {
 		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
+			"error": "Refresh token is required",
 		})
 	}
 
-	// turn the IsOnline status to false
-	ctx := context.Background()
-	dbUser, err := a.containerService.AuthService.GetUserByUID(uid)
-	dbUser.IsOnline = false
+	pair, err := a.containerService.SessionService.Refresh(context.Background(), payload.RefreshToken, c.Get(fiber.HeaderUserAgent), c.IP())
+	if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+		clearSessionCookies(c)
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to refresh session",
+		})
+	}
 
-	// Convert the updated User struct to Firestore-compatible format
-	backendUpdates := mappers.MapUserFrontendToBackend(dbUser)
+	if claims, err := services.Tokens.Verify(pair.AccessToken); err == nil {
+		middleware.SetAuditActor(c, claims.UID, claims.Email)
+	}
 
-	// Update Firestore document
-	eror := a.containerService.AuthService.UpdateUser(ctx, uid, backendUpdates)
-	if eror != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update user status",
+	setSessionCookies(c, pair)
+	return c.Status(http.StatusOK).JSON(sessionResponse(fiber.Map{
+		"message": "Session refreshed successfully",
+	}, pair))
+}
+
+// ListSessions lists the devices the caller is signed in on
+func (a *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	principal := middleware.CurrentPrincipal(c)
+
+	sessions, err := a.containerService.SessionService.ListSessions(context.Background(), principal.UID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch sessions",
 		})
 	}
 
-	c.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    "",
-		Expires:  time.Now().Add(-time.Hour),
-		HTTPOnly: true,
-		Secure:   true,
-		SameSite: "Lax",
-		Path:     "/",
+	// The realtime connections of each device are listed with its session
+	devices, err := a.containerService.PresenceService.ListSessionDevices(context.Background(), principal.UID)
+	if err != nil {
+		log.Printf("Error fetching the realtime connections of user %s: %v", principal.UID, err)
+	}
+
+	frontendSessions := []map[string]interface{}{}
+	for _, session := range sessions {
+		frontendSession := mappers.MapSessionGoToFrontend(session, session.ID == principal.SessionID)
+		frontendSession["connections"] = mappers.MapPresenceDevicesToFrontend(devices[session.ID])
+		frontendSessions = append(frontendSessions, frontendSession)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"sessions": frontendSessions,
 	})
+}
+
+// RevokeSession signs the caller out of one of their devices
+func (a *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	principal := middleware.CurrentPrincipal(c)
+
+	sessionID := c.Params("sessionId")
+	if err := a.containerService.SessionService.RevokeSession(context.Background(), principal.UID, sessionID, "revoked by user"); err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if sessionID == principal.SessionID {
+		clearSessionCookies(c)
+	}
 
 	return c.Status(http.StatusOK).JSON(fiber.Map{
-		"message": "Successfully logged out",
+		"message": "Session revoked successfully",
 	})
 }
 
-func (a *AuthHandler) GetSession(c *fiber.Ctx) error {
+// RevokeOtherSessions signs the caller out of every device but the current one
+func (a *AuthHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	principal := middleware.CurrentPrincipal(c)
 
-	// Get token from Authorization header or cookie
-	var token string
-	authHeader := c.Get("Authorization")
-	if authHeader != "" {
-		token = strings.TrimPrefix(authHeader, "Bearer ")
-	} else {
-		token = c.Cookies("jwt")
-		if token != "" {
-			log.Printf("Token found in cookie: %s", token[:10])
-		} else {
-			log.Printf("No token found in either Authorization header or cookie")
-			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-				"error": "No authentication token provided",
-			})
-		}
+	revoked, err := a.containerService.SessionService.RevokeOtherSessions(context.Background(), principal.UID, principal.SessionID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke sessions",
+		})
 	}
 
-	// Validate token
-	uid, err := validateToken(token)
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Other sessions revoked successfully",
+		"revoked": revoked,
+	})
+}
+
+// sendVerificationEmail mails a new verification link to the user
+func (a *AuthHandler) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	verification := a.containerService.EmailVerificationService
+	token, err := verification.CreateToken(ctx, user)
 	if err != nil {
-		log.Printf("Token validation failed: %v", err)
-		c.Cookie(&fiber.Cookie{
-			Name:     "jwt",
-			Value:    "",
-			Expires:  time.Now().Add(-time.Hour),
-			HTTPOnly: true,
-			Secure:   true,
-			SameSite: "Lax",
-			Path:     "/",
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", strings.TrimRight(config.AppURL, "/"), url.QueryEscape(token))
+	return SendVerificationEmail(user.Email, user.Username, link, verification.TTL())
+}
+
+// VerifyEmail marks the email of a user as verified with the token of a verification link
+func (a *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	var payload struct {
+		Token string `json:"token"`
+	}
+	if err := c.BodyParser(&payload); err != nil || strings.TrimSpace(payload.Token) == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Verification token is required",
 		})
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"error": fmt.Sprintf("Token validation failed: %v", err),
+	}
+
+	user, err := a.containerService.EmailVerificationService.Verify(context.Background(), payload.Token)
+	if errors.Is(err, services.ErrInvalidVerificationToken) || errors.Is(err, services.ErrEmailAlreadyVerified) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify email",
 		})
 	}
 
-	backendUser, err := a.containerService.AuthService.GetUserByUID(uid)
+	middleware.SetAuditActor(c, user.UID, user.Email)
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Your email address has been verified",
+		"user":    mappers.MapUserBackendToFrontend(mappers.MapUserFrontendToBackend(user)),
+	})
+}
+
+// ResendVerificationEmail mails a new verification link to the caller
+func (a *AuthHandler) ResendVerificationEmail(c *fiber.Ctx) error {
+	user, err := a.authService.GetUserByUID(middleware.CurrentUID(c))
 	if err != nil {
-		log.Printf("Error fetching user data: %v", err)
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	err = a.sendVerificationEmail(context.Background(), user)
+	var throttled *services.ThrottledError
+	if errors.As(err, &throttled) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		return c.Status(http.StatusTooManyRequests).JSON(fiber.Map{
+			"error": throttled.Error(),
+		})
+	}
+	if errors.Is(err, services.ErrEmailAlreadyVerified) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("Error sending verification email: %v", err)
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch user data",
+			"error": "Failed to send verification email",
 		})
 	}
 
-	// Generate new token
-	newToken, err := GenerateJWT(backendUser)
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "A new verification link has been sent to your email address",
+	})
+}
+
+// ForgotPassword mails a password reset link. The response does not tell whether
+// the email belongs to an account.
+func (a *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var payload struct {
+		Email string `json:"email"`
+	}
+	if err := c.BodyParser(&payload); err != nil || strings.TrimSpace(payload.Email) == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Email is required",
+		})
+	}
+
+	go func() {
+		passwords := a.containerService.PasswordService
+		user, token, err := passwords.RequestReset(context.Background(), payload.Email)
+		if err != nil {
+			if !errors.Is(err, repository.ErrNotFound) {
+				log.Printf("Error creating password reset token: %v", err)
+			}
+			return
+		}
+
+		link := fmt.Sprintf("%s/reset-password?token=%s", strings.TrimRight(config.AppURL, "/"), url.QueryEscape(token))
+		if err := SendPasswordResetEmail(user.Email, user.Username, link, passwords.ResetTTL()); err != nil {
+			log.Printf("Error sending password reset email: %v", err)
+		}
+	}()
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password with the token of a reset link and signs the user out everywhere
+func (a *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var payload struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"newPassword"`
+	}
+	if err := c.BodyParser(&payload); err != nil || strings.TrimSpace(payload.Token) == "" || payload.NewPassword == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token and new password are required",
+		})
+	}
+
+	user, err := a.containerService.PasswordService.ResetPassword(context.Background(), payload.Token, payload.NewPassword)
+	if errors.Is(err, services.ErrInvalidResetToken) || errors.Is(err, services.ErrWeakPassword) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
-		log.Printf("Error generating new token: %v", err)
+		log.Printf("Error resetting password: %v", err)
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to generate token: %v", err),
+			"error": "Failed to reset password",
 		})
 	}
 
-	// Set cookie
-	c.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    newToken,
-		Expires:  time.Now().Add(24 * time.Hour),
-		HTTPOnly: true,
-		Secure:   true,
-		SameSite: "Lax",
-		Path:     "/",
+	middleware.SetAuditActor(c, user.UID, user.Email)
+
+	// A new password lifts the lockout of the account
+	if err := a.containerService.LoginProtectionService.Clear(context.Background(), user.UID); err != nil {
+		log.Printf("Error clearing failed logins: %v", err)
+	}
+
+	clearSessionCookies(c)
+	sendPasswordChangedEmail(user)
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Your password has been reset, please log in with your new password",
 	})
+}
 
-	frontendUser := mappers.MapUserToFrontend(backendUser)
+// ChangePassword replaces the password of the caller. Other devices are signed out.
+func (a *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	principal := middleware.CurrentPrincipal(c)
+
+	var payload struct {
+		CurrentPassword string `json:"currentPassword"`
+		NewPassword     string `json:"newPassword"`
+	}
+	if err := c.BodyParser(&payload); err != nil || payload.CurrentPassword == "" || payload.NewPassword == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Current and new passwords are required",
+		})
+	}
+
+	user, err := a.containerService.PasswordService.ChangePassword(context.Background(), principal.UID, principal.SessionID, payload.CurrentPassword, payload.NewPassword)
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Current password is incorrect",
+		})
+	}
+	if errors.Is(err, services.ErrWeakPassword) || errors.Is(err, services.ErrSamePassword) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("Error changing password: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to change password",
+		})
+	}
+
+	sendPasswordChangedEmail(user)
 
 	return c.Status(http.StatusOK).JSON(fiber.Map{
-		"user":  frontendUser,
-		"token": newToken,
+		"message": "Your password has been changed",
 	})
 }
 
+// sendPasswordChangedEmail warns the user asynchronously that their password changed
+func sendPasswordChangedEmail(user *models.User) {
+	go func() {
+		if err := SendPasswordChangedEmail(user.Email, user.Username); err != nil {
+			log.Printf("Error sending password changed email: %v", err)
+		}
+	}()
+}
+
 // FetchPlatformLogoURL retrieves the platform's logo URL from Firestore
 // func FetchPlatformLogoURL() (string, error) {
 // 	ctx := context.Background()
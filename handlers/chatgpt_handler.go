@@ -1,11 +1,10 @@
 package handlers
 
 import (
-	"strings"
-
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -18,21 +17,10 @@ func NewChatGPTHandler(service *services.ChatGPTService) *ChatGPTHandler {
 }
 
 func (h *ChatGPTHandler) HandleChat(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-
-	var uid string
-	var err error
-
-	if token == "" {
+	// Anonymous visitors get a throwaway ID
+	uid := middleware.CurrentUID(c)
+	if uid == "" {
 		uid = uuid.New().String()
-	} else {
-		// Validate token and get UID
-		uid, err = validateToken(strings.TrimPrefix(token, "Bearer "))
-		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid token",
-			})
-		}
 	}
 
 	var request struct {
@@ -64,19 +52,7 @@ func (h *ChatGPTHandler) HandleChat(c *fiber.Ctx) error {
 }
 
 func (h *ChatGPTHandler) GetUserConversations(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token required",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	conversations, err := h.service.GetUserConversations(c.Context(), uid)
 	if err != nil {
@@ -95,20 +71,7 @@ func (h *ChatGPTHandler) GetUserConversations(c *fiber.Ctx) error {
 }
 
 func (h *ChatGPTHandler) DeleteConversation(c *fiber.Ctx) error {
-	// Get and validate token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token required",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	conversationID := c.Params("id")
 	if conversationID == "" {
@@ -117,7 +80,7 @@ func (h *ChatGPTHandler) DeleteConversation(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.service.DeleteConversation(c.Context(), conversationID, uid)
+	err := h.service.DeleteConversation(c.Context(), conversationID, uid)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -130,20 +93,7 @@ func (h *ChatGPTHandler) DeleteConversation(c *fiber.Ctx) error {
 }
 
 func (h *ChatGPTHandler) GetConversation(c *fiber.Ctx) error {
-	// Get and validate token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token required",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	conversationID := c.Params("id")
 	if conversationID == "" {
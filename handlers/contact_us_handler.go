@@ -2,15 +2,12 @@ package handlers
 
 import (
 	"context"
-	"strings"
-	"time"
+	"errors"
 
-	"cloud.google.com/go/firestore"
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
-	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 	"github.com/rogerjeasy/go-letusconnect/services"
-	"google.golang.org/api/iterator"
 )
 
 type ContactUsHandler struct {
@@ -39,16 +36,12 @@ func (h *ContactUsHandler) CreateContact(c *fiber.Ctx) error {
 	// Map the request data to the ContactUs struct
 	newContact := mappers.FrontendToContactUs(requestData)
 
-	// Save to Firestore
-	docRef, _, err := services.Firestore.Collection("contact_us").Add(ctx, mappers.ContactUsToFirestore(newContact))
-	if err != nil {
+	if err := h.contactUsService.CreateContactUs(ctx, newContact); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create contact",
 		})
 	}
 
-	newContact.ID = docRef.ID
-
 	// Send automatic thank-you email
 	if err := SendAutomaticEmail(newContact.Email, newContact.Name); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -71,27 +64,11 @@ func (h *ContactUsHandler) GetContactByID(c *fiber.Ctx) error {
 		})
 	}
 
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate the token and extract the UID
-	_, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
-
 	ctx := context.Background()
 
-	// Fetch the document by ID
-	doc, err := services.Firestore.Collection("contact_us").Doc(contactID).Get(ctx)
+	contact, err := h.contactUsService.GetContactUs(ctx, contactID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, repository.ErrNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Contact not found",
 			})
@@ -101,53 +78,21 @@ func (h *ContactUsHandler) GetContactByID(c *fiber.Ctx) error {
 		})
 	}
 
-	// Map the Firestore document data to the ContactUs struct
-	contact := mappers.FirestoreToContactUs(doc.Data())
-
 	return c.Status(fiber.StatusOK).JSON(contact)
 }
 
 // GetAllContacts retrieves all contact form submissions
 func (h *ContactUsHandler) GetAllContacts(c *fiber.Ctx) error {
 
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
+	ctx := context.Background()
 
-	// Validate the token and extract the UID
-	_, err := validateToken(strings.TrimPrefix(token, "Bearer "))
+	contacts, err := h.contactUsService.ListContactUs(ctx)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch contacts",
 		})
 	}
 
-	ctx := context.Background()
-
-	// Query Firestore to get all contacts
-	iter := services.Firestore.Collection("contact_us").Documents(ctx)
-	defer iter.Stop()
-
-	var contacts []models.ContactUs
-
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch contacts",
-			})
-		}
-
-		contact := mappers.FirestoreToContactUs(doc.Data())
-		contacts = append(contacts, *contact)
-	}
-
 	return c.Status(fiber.StatusOK).JSON(contacts)
 }
 
@@ -160,21 +105,6 @@ func (h *ContactUsHandler) UpdateContactStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate the token and extract the UID
-	_, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
-
 	var requestData struct {
 		Status       string `json:"status"`
 		RepliedBy    string `json:"repliedBy,omitempty"`
@@ -189,22 +119,13 @@ func (h *ContactUsHandler) UpdateContactStatus(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	updates := []firestore.Update{
-		{Path: "status", Value: requestData.Status},
-		{Path: "updated_at", Value: time.Now()},
-	}
-
-	if requestData.RepliedBy != "" {
-		updates = append(updates, firestore.Update{Path: "replied_by", Value: requestData.RepliedBy})
-	}
-
-	if requestData.ReplyMessage != "" {
-		updates = append(updates, firestore.Update{Path: "reply_message", Value: requestData.ReplyMessage})
-	}
-
-	// Update Firestore document
-	_, err_new := services.Firestore.Collection("contacts").Doc(contactID).Update(ctx, updates)
-	if err_new != nil {
+	_, err := h.contactUsService.UpdateContactStatus(ctx, contactID, requestData.Status, requestData.RepliedBy, requestData.ReplyMessage)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Contact not found",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update contact status",
 		})
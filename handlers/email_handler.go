@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"fmt"
+	"html"
 	"log"
 	"net/smtp"
 	"strings"
+	"time"
 
 	"github.com/rogerjeasy/go-letusconnect/config"
 )
@@ -684,3 +686,277 @@ func SendEmailToNotifyRequestJoinProject(toEmails []string, requestingUserName,
 
 	return nil
 }
+
+// SendVerificationEmail sends the link that verifies the email address of a new account
+func SendVerificationEmail(toEmail, userName, verificationLink string, validFor time.Duration) error {
+	subject := "Verify Your Email Address for LetUsConnect"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+  <style>
+    body {
+      font-family: Arial, sans-serif;
+      color: #333333;
+      background-color: #f9f9f9;
+      padding: 20px;
+      text-align: center;
+    }
+    .container {
+      max-width: 600px;
+      margin: 0 auto;
+      background: #ffffff;
+      padding: 30px;
+      border-radius: 10px;
+      box-shadow: 0 4px 8px rgba(0, 0, 0, 0.1);
+    }
+    h2 {
+      color: #4A90E2;
+    }
+    p {
+      font-size: 16px;
+      line-height: 1.6;
+    }
+    .button {
+      display: inline-block;
+      margin: 20px 0;
+      padding: 12px 24px;
+      background-color: #4A90E2;
+      color: #ffffff;
+      text-decoration: none;
+      border-radius: 5px;
+    }
+    .footer {
+      margin-top: 30px;
+      font-size: 14px;
+      color: #777777;
+    }
+  </style>
+</head>
+<body>
+  <div class="container">
+    <h2>Welcome, %s!</h2>
+    <p>
+      Please confirm that this is your email address to finish setting up your <strong>LetUsConnect</strong> account.
+    </p>
+    <a href="%s" class="button">Verify Email Address</a>
+    <p>
+      This link can be used once and expires in %s. If you did not create an account, you can ignore this email.
+    </p>
+    <p class="footer">
+      Best regards, <br>
+      <strong>The LetUsConnect Team</strong>
+    </p>
+  </div>
+</body>
+</html>`, html.EscapeString(userName), verificationLink, formatValidity(validFor))
+
+	return sendEmail(toEmail, subject, body)
+}
+
+// formatValidity describes how long a mailed link stays valid, like "24 hours"
+func formatValidity(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		if hours := int(d / time.Hour); hours != 1 {
+			return fmt.Sprintf("%d hours", hours)
+		}
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d minutes", int(d.Round(time.Minute)/time.Minute))
+}
+
+// SendPasswordResetEmail sends the link that sets a new password
+func SendPasswordResetEmail(toEmail, userName, resetLink string, validFor time.Duration) error {
+	subject := "Reset Your LetUsConnect Password"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+  <style>
+    body {
+      font-family: Arial, sans-serif;
+      color: #333333;
+      background-color: #f9f9f9;
+      padding: 20px;
+      text-align: center;
+    }
+    .container {
+      max-width: 600px;
+      margin: 0 auto;
+      background: #ffffff;
+      padding: 30px;
+      border-radius: 10px;
+      box-shadow: 0 4px 8px rgba(0, 0, 0, 0.1);
+    }
+    h2 {
+      color: #4A90E2;
+    }
+    p {
+      font-size: 16px;
+      line-height: 1.6;
+    }
+    .button {
+      display: inline-block;
+      margin: 20px 0;
+      padding: 12px 24px;
+      background-color: #4A90E2;
+      color: #ffffff;
+      text-decoration: none;
+      border-radius: 5px;
+    }
+    .footer {
+      margin-top: 30px;
+      font-size: 14px;
+      color: #777777;
+    }
+  </style>
+</head>
+<body>
+  <div class="container">
+    <h2>Hello, %s</h2>
+    <p>
+      We received a request to reset the password of your <strong>LetUsConnect</strong> account.
+    </p>
+    <a href="%s" class="button">Reset Password</a>
+    <p>
+      This link can be used once and expires in %s. If you did not ask for a new password, you can ignore this email: your password will not change.
+    </p>
+    <p class="footer">
+      Best regards, <br>
+      <strong>The LetUsConnect Team</strong>
+    </p>
+  </div>
+</body>
+</html>`, html.EscapeString(userName), resetLink, formatValidity(validFor))
+
+	return sendEmail(toEmail, subject, body)
+}
+
+// SendPasswordChangedEmail tells the user that their password was changed
+func SendPasswordChangedEmail(toEmail, userName string) error {
+	subject := "Your LetUsConnect Password Was Changed"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+  <style>
+    body {
+      font-family: Arial, sans-serif;
+      color: #333333;
+      background-color: #f9f9f9;
+      padding: 20px;
+      text-align: center;
+    }
+    .container {
+      max-width: 600px;
+      margin: 0 auto;
+      background: #ffffff;
+      padding: 30px;
+      border-radius: 10px;
+      box-shadow: 0 4px 8px rgba(0, 0, 0, 0.1);
+    }
+    h2 {
+      color: #4A90E2;
+    }
+    p {
+      font-size: 16px;
+      line-height: 1.6;
+    }
+    .footer {
+      margin-top: 30px;
+      font-size: 14px;
+      color: #777777;
+    }
+  </style>
+</head>
+<body>
+  <div class="container">
+    <h2>Hello, %s</h2>
+    <p>
+      The password of your <strong>LetUsConnect</strong> account was just changed, and your other devices have been signed out.
+    </p>
+    <p>
+      If you did not make this change, reset your password right away and <a href="https://letusconnect.vercel.app/help" style="color: #4A90E2;">contact our support team</a>.
+    </p>
+    <p class="footer">
+      Best regards, <br>
+      <strong>The LetUsConnect Team</strong>
+    </p>
+  </div>
+</body>
+</html>`, html.EscapeString(userName))
+
+	return sendEmail(toEmail, subject, body)
+}
+
+// SendAccountLockedEmail tells the user that their account was locked after too many
+// failed logins, with the link that unlocks it
+func SendAccountLockedEmail(toEmail, userName, unlockLink, ipAddress string, lockedFor, validFor time.Duration) error {
+	subject := "Your LetUsConnect Account Was Locked"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+  <style>
+    body {
+      font-family: Arial, sans-serif;
+      color: #333333;
+      background-color: #f9f9f9;
+      padding: 20px;
+      text-align: center;
+    }
+    .container {
+      max-width: 600px;
+      margin: 0 auto;
+      background: #ffffff;
+      padding: 30px;
+      border-radius: 10px;
+      box-shadow: 0 4px 8px rgba(0, 0, 0, 0.1);
+    }
+    h2 {
+      color: #4A90E2;
+    }
+    p {
+      font-size: 16px;
+      line-height: 1.6;
+    }
+    .button {
+      display: inline-block;
+      margin: 20px 0;
+      padding: 12px 24px;
+      background-color: #4A90E2;
+      color: #ffffff;
+      text-decoration: none;
+      border-radius: 5px;
+    }
+    .footer {
+      margin-top: 30px;
+      font-size: 14px;
+      color: #777777;
+    }
+  </style>
+</head>
+<body>
+  <div class="container">
+    <h2>Hello, %s</h2>
+    <p>
+      There were too many failed attempts to log in to your <strong>LetUsConnect</strong> account, the last one from the address %s.
+      To protect your account, logins are blocked for the next %s.
+    </p>
+    <p>
+      If it was you, unlock your account now. The link can be used once and expires in %s.
+    </p>
+    <a href="%s" class="button">Unlock My Account</a>
+    <p>
+      If it was not you, someone may be guessing your password: unlock your account and change your password, or reset it from the login page.
+    </p>
+    <p class="footer">
+      Best regards, <br>
+      <strong>The LetUsConnect Team</strong>
+    </p>
+  </div>
+</body>
+</html>`, html.EscapeString(userName), html.EscapeString(ipAddress), formatValidity(lockedFor), formatValidity(validFor), unlockLink)
+
+	return sendEmail(toEmail, subject, body)
+}
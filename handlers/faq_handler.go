@@ -2,14 +2,13 @@ package handlers
 
 import (
 	"context"
-	"strings"
-	"time"
+	"errors"
 
-	"cloud.google.com/go/firestore"
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 	"github.com/rogerjeasy/go-letusconnect/services"
-	"google.golang.org/api/iterator"
 )
 
 type FAQHandler struct {
@@ -34,20 +33,7 @@ func NewFAQHandler(faqService *services.FAQService, userService *services.UserSe
 
 // CreateFAQ handles the creation of a new FAQ entry
 func (f *FAQHandler) CreateFAQ(c *fiber.Ctx) error {
-	// Extract and validate token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Get user details
 	userDetails, err := f.UserService.GetUserByUID(uid)
@@ -65,28 +51,6 @@ func (f *FAQHandler) CreateFAQ(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate admin privileges
-	roles, err := f.UserService.GetUserRole(uid)
-	if err != nil {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error":   "Failed to fetch user roles",
-			"details": err.Error(),
-		})
-	}
-
-	isAdmin := false
-	for _, role := range roles {
-		if role == "admin" {
-			isAdmin = true
-			break
-		}
-	}
-	if !isAdmin {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Admin privileges required",
-		})
-	}
-
 	// Parse request body
 	var requestData map[string]interface{}
 	if err := c.BodyParser(&requestData); err != nil {
@@ -121,21 +85,7 @@ func (f *FAQHandler) CreateFAQ(c *fiber.Ctx) error {
 
 // UpdateFAQ updates an existing FAQ (admin only)
 func (f *FAQHandler) UpdateFAQ(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Fetch the user's username
 	username, err := f.UserService.GetUsernameByUID(uid)
@@ -145,29 +95,6 @@ func (f *FAQHandler) UpdateFAQ(c *fiber.Ctx) error {
 		})
 	}
 
-	// Fetch the user's roles
-	roles, err := f.UserService.GetUserRole(uid)
-	if err != nil {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Failed to fetch user roles",
-		})
-	}
-
-	// Check if the user has the "admin" role
-	isAdmin := false
-	for _, role := range roles {
-		if role == "admin" {
-			isAdmin = true
-			break
-		}
-	}
-
-	if !isAdmin {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Admin privileges required",
-		})
-	}
-
 	// Get the FAQ ID from the route parameters
 	faqID := c.Params("id")
 	if faqID == "" {
@@ -184,20 +111,13 @@ func (f *FAQHandler) UpdateFAQ(c *fiber.Ctx) error {
 		})
 	}
 
-	// Prepare updates for Firestore
 	ctx := context.Background()
-	updates := []firestore.Update{
-		{Path: "question", Value: requestData["question"]},
-		{Path: "response", Value: requestData["response"]},
-		{Path: "category", Value: requestData["category"]},
-		{Path: "status", Value: requestData["status"]},
-		{Path: "updated_by", Value: username},
-		{Path: "updated_at", Value: time.Now()},
-	}
-
-	// Update the FAQ in Firestore
-	_, err = services.Firestore.Collection("faqs").Doc(faqID).Update(ctx, updates)
-	if err != nil {
+	if _, err := f.FAQService.UpdateFAQ(ctx, faqID, mappers.MapFAQFrontendToGo(requestData), username); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "FAQ not found",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update FAQ",
 		})
@@ -210,45 +130,6 @@ func (f *FAQHandler) UpdateFAQ(c *fiber.Ctx) error {
 
 // DeleteFAQ deletes an existing FAQ (admin only)
 func (f *FAQHandler) DeleteFAQ(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
-
-	// Fetch the user's roles
-	roles, err := f.UserService.GetUserRole(uid)
-	if err != nil {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Failed to fetch user roles",
-		})
-	}
-
-	// Check if the user has the "admin" role
-	isAdmin := false
-	for _, role := range roles {
-		if role == "admin" {
-			isAdmin = true
-			break
-		}
-	}
-
-	if !isAdmin {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Admin privileges required",
-		})
-	}
-
 	// Get the FAQ ID from the route parameters
 	faqID := c.Params("id")
 	if faqID == "" {
@@ -257,10 +138,13 @@ func (f *FAQHandler) DeleteFAQ(c *fiber.Ctx) error {
 		})
 	}
 
-	// Delete the FAQ from Firestore
 	ctx := context.Background()
-	_, err = services.Firestore.Collection("faqs").Doc(faqID).Delete(ctx)
-	if err != nil {
+	if err := f.FAQService.DeleteFAQ(ctx, faqID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "FAQ not found",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete FAQ",
 		})
@@ -273,31 +157,16 @@ func (f *FAQHandler) DeleteFAQ(c *fiber.Ctx) error {
 
 func (f *FAQHandler) GetAllFAQs(c *fiber.Ctx) error {
 	ctx := context.Background()
-	iter := services.Firestore.Collection("faqs").Documents(ctx)
-	defer iter.Stop()
-
-	faqs := make([]map[string]interface{}, 0)
-
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch FAQs",
-			})
-		}
-
-		data := doc.Data()
-		data["id"] = doc.Ref.ID
-
-		// Convert Firestore data to Go struct
-		faq := mappers.MapFAQFirestoreToGo(data)
+	list, err := f.FAQService.GetAllFAQs(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch FAQs",
+		})
+	}
 
-		// Convert Go struct to frontend format
-		frontendFAQ := mappers.MapFAQGoToFrontend(faq)
-		faqs = append(faqs, frontendFAQ)
+	faqs := make([]map[string]interface{}, 0, len(list))
+	for _, faq := range list {
+		faqs = append(faqs, mappers.MapFAQGoToFrontend(faq))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(faqs)
@@ -315,7 +184,7 @@ func (f *FAQHandler) GetFAQByID(c *fiber.Ctx) error {
 
 	faq, err := f.FAQService.GetFAQByID(ctx, id)
 	if err != nil {
-		if strings.Contains(err.Error(), "FAQ not found") {
+		if errors.Is(err, repository.ErrNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "FAQ not found",
 			})
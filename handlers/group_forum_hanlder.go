@@ -2,10 +2,10 @@ package handlers
 
 import (
 	"context"
-	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/models"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
@@ -23,19 +23,7 @@ func NewGroupHandler(groupService *services.GroupService, userService *services.
 }
 
 func (h *GroupHandler) CreateGroup(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	userId, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userId := middleware.CurrentUID(c)
 
 	var group models.Group
 	if err := c.BodyParser(&group); err != nil {
@@ -81,20 +69,6 @@ func (h *GroupHandler) GetGroup(c *fiber.Ctx) error {
 }
 
 func (h *GroupHandler) UpdateGroup(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
-
 	groupID := c.Params("id")
 
 	ctx := context.Background()
@@ -105,20 +79,6 @@ func (h *GroupHandler) UpdateGroup(c *fiber.Ctx) error {
 		})
 	}
 
-	isAdmin := false
-	for _, admin := range existingGroup.Admins {
-		if admin.UID == userID {
-			isAdmin = true
-			break
-		}
-	}
-
-	if !isAdmin {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Only group admins can update the group",
-		})
-	}
-
 	var updates models.Group
 	if err := c.BodyParser(&updates); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -141,19 +101,7 @@ func (h *GroupHandler) UpdateGroup(c *fiber.Ctx) error {
 }
 
 func (h *GroupHandler) DeleteGroup(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	groupID := c.Params("id")
 	ctx := context.Background()
@@ -203,19 +151,7 @@ func (h *GroupHandler) ListGroups(c *fiber.Ctx) error {
 }
 
 func (h *GroupHandler) ListGroupsByUser(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	ctx := context.Background()
 	groups, err := h.groupService.ListGroupsByUser(ctx, uid)
@@ -236,13 +172,6 @@ func (h *GroupHandler) ListGroupsByUser(c *fiber.Ctx) error {
 }
 
 func (h *GroupHandler) AddMember(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
 	groupID := c.Params("id")
 	var member models.Member
 	if err := c.BodyParser(&member); err != nil {
@@ -264,13 +193,6 @@ func (h *GroupHandler) AddMember(c *fiber.Ctx) error {
 }
 
 func (h *GroupHandler) RemoveMember(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
 	groupID := c.Params("id")
 	userID := c.Params("userId")
 
@@ -287,13 +209,6 @@ func (h *GroupHandler) RemoveMember(c *fiber.Ctx) error {
 }
 
 func (h *GroupHandler) UploadGroupImage(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
 	groupID := c.Params("id")
 	file, err := c.FormFile("image")
 	if err != nil {
@@ -315,13 +230,6 @@ func (h *GroupHandler) UploadGroupImage(c *fiber.Ctx) error {
 }
 
 func (h *GroupHandler) AddEvent(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
 	groupID := c.Params("id")
 	var event models.Event
 	if err := c.BodyParser(&event); err != nil {
@@ -343,13 +251,6 @@ func (h *GroupHandler) AddEvent(c *fiber.Ctx) error {
 }
 
 func (h *GroupHandler) RemoveEvent(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
 	groupID := c.Params("id")
 	eventID := c.Params("eventId")
 
@@ -366,13 +267,6 @@ func (h *GroupHandler) RemoveEvent(c *fiber.Ctx) error {
 }
 
 func (h *GroupHandler) AddResource(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
 	groupID := c.Params("id")
 	var resource models.Resource
 	if err := c.BodyParser(&resource); err != nil {
@@ -394,13 +288,6 @@ func (h *GroupHandler) AddResource(c *fiber.Ctx) error {
 }
 
 func (h *GroupHandler) RemoveResource(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
 	groupID := c.Params("id")
 	resourceID := c.Params("resourceId")
 
@@ -417,13 +304,6 @@ func (h *GroupHandler) RemoveResource(c *fiber.Ctx) error {
 }
 
 func (h *GroupHandler) UpdateGroupSettings(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
 	groupID := c.Params("id")
 	var settings struct {
 		Privacy  string `json:"privacy"`
@@ -1,106 +1,23 @@
 package handlers
 
 import (
-	// "context"
-	"errors"
-	"fmt"
-	"log"
-	"strings"
-	"time"
-
-	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
-	// "github.com/rogerjeasy/go-letusconnect/models"
-	// "github.com/rogerjeasy/go-letusconnect/services"
-	// "google.golang.org/api/iterator"
-	// "google.golang.org/grpc/codes"
-	// "google.golang.org/grpc/status"
+// "context"
+
+// "github.com/rogerjeasy/go-letusconnect/models"
+// "github.com/rogerjeasy/go-letusconnect/services"
+// "google.golang.org/api/iterator"
+// "google.golang.org/grpc/codes"
+// "google.golang.org/grpc/status"
 )
 
 // Error responses
 const (
-	errTokenRequired    = "Authorization token is required"
-	errInvalidToken     = "Invalid token"
 	errExperienceExists = "School experience already exists for this user"
 	errCheckExisting    = "Failed to check existing school experience"
 	errCreateExperience = "Failed to create school experience"
 	msgCreateSuccess    = "School experience created successfully"
 )
 
-func ValidateToken(tokenString string) (string, error) {
-	if strings.TrimSpace(tokenString) == "" {
-		log.Printf("Empty token received")
-		return "", errors.New("token cannot be empty")
-	}
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			log.Printf("Invalid signing method: %v", token.Header["alg"])
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecretKey, nil
-	})
-
-	if err != nil {
-		log.Printf("Token parsing error: %v", err)
-		return "", fmt.Errorf("failed to parse token: %v", err)
-	}
-
-	if !token.Valid {
-		log.Printf("Token is invalid")
-		return "", errors.New("invalid token")
-	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		log.Printf("Failed to extract token claims")
-		return "", errors.New("invalid token claims")
-	}
-
-	if exp, ok := claims["exp"].(float64); ok {
-		if int64(exp) < time.Now().Unix() {
-			log.Printf("Token expired at %v", time.Unix(int64(exp), 0))
-			return "", errors.New("token has expired")
-		}
-	} else {
-		log.Printf("Token missing expiration claim")
-		return "", errors.New("invalid token: missing expiration")
-	}
-
-	uid, ok := claims["uid"].(string)
-	if !ok {
-		log.Printf("Missing or invalid UID in token claims")
-		return "", errors.New("missing or invalid UID in token")
-	}
-
-	if strings.TrimSpace(uid) == "" {
-		log.Printf("Empty UID in token claims")
-		return "", errors.New("empty UID in token")
-	}
-
-	return uid, nil
-}
-
-// extractAndValidateToken extracts and validates the authorization token
-// Usage example: uid, err := extractAndValidateToken(c)
-func ExtractAndValidateToken(c *fiber.Ctx) (string, error) {
-	token := c.Get("Authorization")
-	if token == "" {
-		return "", c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": errTokenRequired,
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return "", c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": errInvalidToken,
-		})
-	}
-
-	return uid, nil
-}
-
 // handleFirestoreError handles Firestore-specific errors
 // func handleFirestoreError(c *fiber.Ctx, err error) error {
 // 	if status.Code(err) == codes.AlreadyExists {
@@ -2,15 +2,15 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
-	"firebase.google.com/go/auth"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 	"github.com/rogerjeasy/go-letusconnect/services"
-	"google.golang.org/api/iterator"
 )
 
 func extractProviderData(provider models.AuthProvider, data map[string]interface{}) (models.ProviderData, error) {
@@ -137,20 +137,6 @@ func extractProviderData(provider models.AuthProvider, data map[string]interface
 	return providerData, nil
 }
 
-func createFirebaseUser(ctx context.Context, data models.ProviderData) (*auth.UserRecord, error) {
-	switch data.ProviderType {
-	case models.EmailPassword:
-		return services.FirebaseAuth.CreateUser(ctx, (&auth.UserToCreate{}).
-			Email(data.Email).
-			Password(data.Password))
-
-	case models.Google, models.GitHub:
-		return services.FirebaseAuth.GetUserByEmail(ctx, data.Email)
-	}
-
-	return nil, fmt.Errorf("unsupported provider type: %s", data.ProviderType)
-}
-
 func generateUsername(displayName string) string {
 	username := strings.ToLower(strings.ReplaceAll(displayName, " ", "_"))
 	return fmt.Sprintf("%s_%d", username, time.Now().UnixNano()%1000)
@@ -169,16 +155,12 @@ func validateCommonFields(data models.ProviderData) error {
 	return nil
 }
 
-func checkExistingUser(ctx context.Context, data models.ProviderData) error {
-	emailQuery := services.Firestore.Collection("users").Where("email", "==", data.Email).Documents(ctx)
-	defer emailQuery.Stop()
-	if _, err := emailQuery.Next(); err != iterator.Done {
+func checkExistingUser(ctx context.Context, authService *services.AuthService, data models.ProviderData) error {
+	if _, err := authService.GetUserByEmailOrUsername(ctx, data.Email); !errors.Is(err, repository.ErrNotFound) {
 		return fmt.Errorf("email already in use")
 	}
 
-	usernameQuery := services.Firestore.Collection("users").Where("username", "==", data.Username).Documents(ctx)
-	defer usernameQuery.Stop()
-	if _, err := usernameQuery.Next(); err != iterator.Done {
+	if _, err := authService.GetUserByEmailOrUsername(ctx, data.Username); !errors.Is(err, repository.ErrNotFound) {
 		return fmt.Errorf("username already in use")
 	}
 
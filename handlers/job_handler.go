@@ -6,6 +6,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/models"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
@@ -20,10 +21,7 @@ func NewJobHandler(jobService *services.JobService) *JobHandler {
 
 // CreateJobHandler handles job creation requests
 func (h *JobHandler) CreateJobHandler(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	var jobData map[string]interface{}
 	if err := c.BodyParser(&jobData); err != nil {
@@ -53,10 +51,7 @@ func (h *JobHandler) CreateJobHandler(c *fiber.Ctx) error {
 
 // GetJobHandler fetches a single job by ID
 func (h *JobHandler) GetJobHandler(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	jobID := c.Params("id")
 	if jobID == "" {
@@ -76,10 +71,7 @@ func (h *JobHandler) GetJobHandler(c *fiber.Ctx) error {
 
 // GetJobsByUserHandler fetches all jobs for a user
 func (h *JobHandler) GetJobsByUserHandler(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	jobs, err := h.JobService.GetJobsByUser(c.Context(), uid)
 	if err != nil {
@@ -99,10 +91,7 @@ func (h *JobHandler) GetJobsByUserHandler(c *fiber.Ctx) error {
 
 // UpdateJobHandler updates an existing job
 func (h *JobHandler) UpdateJobHandler(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	jobID := c.Params("id")
 	if jobID == "" {
@@ -115,7 +104,7 @@ func (h *JobHandler) UpdateJobHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request payload"})
 	}
 
-	err = h.JobService.UpdateJob(c.Context(), jobID, uid, jobData)
+	err := h.JobService.UpdateJob(c.Context(), jobID, uid, jobData)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -125,17 +114,14 @@ func (h *JobHandler) UpdateJobHandler(c *fiber.Ctx) error {
 
 // DeleteJobHandler deletes a job
 func (h *JobHandler) DeleteJobHandler(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	jobID := c.Params("id")
 	if jobID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Job ID is required"})
 	}
 
-	err = h.JobService.DeleteJob(c.Context(), jobID, uid)
+	err := h.JobService.DeleteJob(c.Context(), jobID, uid)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -145,10 +131,7 @@ func (h *JobHandler) DeleteJobHandler(c *fiber.Ctx) error {
 
 // AddInterviewRoundHandler adds an interview round to a job
 func (h *JobHandler) AddInterviewRoundHandler(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	jobID := c.Params("id")
 	if jobID == "" {
@@ -162,7 +145,7 @@ func (h *JobHandler) AddInterviewRoundHandler(c *fiber.Ctx) error {
 	}
 
 	// Add interview round
-	err = h.JobService.AddInterviewRound(c.Context(), jobID, uid, interview)
+	err := h.JobService.AddInterviewRound(c.Context(), jobID, uid, interview)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -172,10 +155,7 @@ func (h *JobHandler) AddInterviewRoundHandler(c *fiber.Ctx) error {
 
 // RemoveInterviewRoundHandler removes an interview round from a job
 func (h *JobHandler) RemoveInterviewRoundHandler(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	jobID := c.Params("id")
 	roundNumber := c.Params("roundNumber")
@@ -183,7 +163,7 @@ func (h *JobHandler) RemoveInterviewRoundHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Job ID and Round Number are required"})
 	}
 
-	err = h.JobService.RemoveInterviewRound(c.Context(), jobID, uid, stringToInt(roundNumber))
+	err := h.JobService.RemoveInterviewRound(c.Context(), jobID, uid, stringToInt(roundNumber))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -193,10 +173,7 @@ func (h *JobHandler) RemoveInterviewRoundHandler(c *fiber.Ctx) error {
 
 // GetJobsByStatusHandler fetches jobs by their status
 func (h *JobHandler) GetJobsByStatusHandler(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	status := c.Params("status")
 	if status == "" {
@@ -13,23 +13,17 @@ import (
 	"github.com/rogerjeasy/go-letusconnect/config"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
 	"github.com/rogerjeasy/go-letusconnect/models"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
+// linkedInProvider is the name of LinkedIn in the linked identities
+const linkedInProvider = "linkedin"
+
 // Request structures
 type LinkedInAuthRequest struct {
 	Code string `json:"code"`
 }
 
-type LinkedInDataRequest struct {
-	LinkedinData struct {
-		Profile LinkedInProfileResponse `json:"profile"`
-		Email   LinkedInEmailResponse   `json:"email"`
-		Token   LinkedInTokenResponse   `json:"token"`
-	} `json:"linkedinData"`
-}
-
 type LinkedInTokenResponse struct {
 	AccessToken string `json:"access_token"`
 	ExpiresIn   int    `json:"expires_in"`
@@ -57,20 +51,16 @@ type LinkedInProfileResponse struct {
 	} `json:"lastName"`
 }
 
-// LinkedInCallback handles the OAuth callback from LinkedIn
+// LinkedInCallback handles the OAuth callback from LinkedIn. Only the authorization code
+// is accepted: profiles fetched by the client cannot be trusted to sign anyone in.
 func (a *AuthHandler) LinkedInCallback(c *fiber.Ctx) error {
-	var reqData LinkedInDataRequest
-	if err := c.BodyParser(&reqData); err != nil {
-		// Try parsing as auth request if data request fails
-		var authReq LinkedInAuthRequest
-		if err := c.BodyParser(&authReq); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid request body",
-			})
-		}
-		return a.handleLinkedInAuth(c, authReq)
+	var authReq LinkedInAuthRequest
+	if err := c.BodyParser(&authReq); err != nil || authReq.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "A LinkedIn authorization code is required, or sign in through /auth/oauth/linkedin",
+		})
 	}
-	return a.handleLinkedInData(c, reqData)
+	return a.handleLinkedInAuth(c, authReq)
 }
 
 // handleLinkedInAuth handles the initial OAuth code exchange
@@ -99,6 +89,11 @@ func (a *AuthHandler) handleLinkedInAuth(c *fiber.Ctx, req LinkedInAuthRequest)
 			"error": fmt.Sprintf("Failed to parse token response: %v", err),
 		})
 	}
+	if tokenResult.AccessToken == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "LinkedIn rejected the authorization code",
+		})
+	}
 
 	// Fetch user data from LinkedIn
 	profileData, emailData, err := fetchLinkedInUserData(tokenResult.AccessToken)
@@ -111,11 +106,6 @@ func (a *AuthHandler) handleLinkedInAuth(c *fiber.Ctx, req LinkedInAuthRequest)
 	return a.processLinkedInUser(c, *profileData, *emailData)
 }
 
-// handleLinkedInData processes pre-fetched LinkedIn data
-func (a *AuthHandler) handleLinkedInData(c *fiber.Ctx, req LinkedInDataRequest) error {
-	return a.processLinkedInUser(c, req.LinkedinData.Profile, req.LinkedinData.Email)
-}
-
 // fetchLinkedInUserData retrieves user profile and email from LinkedIn
 func fetchLinkedInUserData(accessToken string) (*LinkedInProfileResponse, *LinkedInEmailResponse, error) {
 	client := &http.Client{}
@@ -155,60 +145,43 @@ func fetchLinkedInUserData(accessToken string) (*LinkedInProfileResponse, *Linke
 	return &profileData, &emailData, nil
 }
 
-// processLinkedInUser handles the core user creation/login logic
+// processLinkedInUser signs in the user linked to the LinkedIn account, or the user with
+// its email address, and creates the user otherwise
 func (a *AuthHandler) processLinkedInUser(c *fiber.Ctx, profileData LinkedInProfileResponse, emailData LinkedInEmailResponse) error {
 	// Extract email
 	var email string
 	if len(emailData.Elements) > 0 {
-		email = emailData.Elements[0].Handle.EmailAddress
+		email = strings.ToLower(strings.TrimSpace(emailData.Elements[0].Handle.EmailAddress))
 	}
 
-	if email == "" {
+	if email == "" || profileData.ID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "No email address found in LinkedIn response",
 		})
 	}
 
-	// Check if user exists
-	existingUser, err := a.authService.GetUserByEmail(email)
-	if err != nil {
-		// Check if the error is not a "not found" error
-		if status, ok := status.FromError(err); !ok || status.Code() != codes.NotFound {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to check existing user",
-			})
-		}
-	}
-
-	if existingUser != nil {
-		return a.handleExistingUser(c, existingUser)
+	// LinkedIn only returns verified primary email addresses
+	profile := &services.ExternalProfile{
+		Subject:       profileData.ID,
+		Email:         email,
+		EmailVerified: true,
+		FirstName:     profileData.FirstName.Localized.EnUS,
+		LastName:      profileData.LastName.Localized.EnUS,
 	}
-
-	return a.createNewLinkedInUser(c, profileData, email)
-}
-
-// handleExistingUser processes login for existing users
-func (a *AuthHandler) handleExistingUser(c *fiber.Ctx, user *models.User) error {
-	// Generate JWT token for existing user
-	token, err := GenerateJWT(user)
+	result, err := a.containerService.OAuthService.Resolve(context.Background(), linkedInProvider, profile)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate token",
-		})
+		return oauthError(c, linkedInProvider, err)
 	}
 
-	setCookie(c, token)
+	if result.User != nil {
+		return a.signIn(c, result.User)
+	}
 
-	frontendUser := mappers.MapUserToFrontend(user)
-	return c.JSON(fiber.Map{
-		"user":    frontendUser,
-		"token":   token,
-		"message": "Successfully logged in with LinkedIn",
-	})
+	return a.createNewLinkedInUser(c, profile)
 }
 
-func (a *AuthHandler) createNewLinkedInUser(c *fiber.Ctx, profileData LinkedInProfileResponse, email string) error {
-	username := strings.Split(email, "@")[0]
+func (a *AuthHandler) createNewLinkedInUser(c *fiber.Ctx, profile *services.ExternalProfile) error {
+	username := strings.Split(profile.Email, "@")[0]
 	currentTime := time.Now()
 	customFormat := "Monday, Jan 2, 2006 at 3:04 PM"
 
@@ -225,17 +198,17 @@ func (a *AuthHandler) createNewLinkedInUser(c *fiber.Ctx, profileData LinkedInPr
 
 	// Create user
 	newUser := models.User{
-		UID:              profileData.ID,
+		UID:              profile.Subject,
 		Username:         username,
-		FirstName:        profileData.FirstName.Localized.EnUS,
-		LastName:         profileData.LastName.Localized.EnUS,
-		Email:            email,
+		FirstName:        profile.FirstName,
+		LastName:         profile.LastName,
+		Email:            profile.Email,
 		ProfilePicture:   uploadedURL,
 		AccountCreatedAt: FormatTime(currentTime, customFormat),
 		IsActive:         true,
 		IsVerified:       true,
 		Role:             []string{"user"},
-		IsOnline:         true,
+		IsOnline:         false,
 		Bio:              "",
 		PhoneNumber:      "",
 		GraduationYear:   0,
@@ -253,20 +226,24 @@ func (a *AuthHandler) createNewLinkedInUser(c *fiber.Ctx, profileData LinkedInPr
 			"error": "Failed to save user",
 		})
 	}
+	if _, err := a.containerService.OAuthService.Link(ctx, newUser.UID, linkedInProvider, profile); err != nil {
+		log.Printf("Error linking LinkedIn account: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save user",
+		})
+	}
 
-	// Generate token
-	token, err := GenerateJWT(&newUser)
+	// Sign the new user in on this device
+	pair, err := a.startSession(c, &newUser)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate token",
 		})
 	}
 
-	setCookie(c, token)
-
 	// Send welcome email
 	go func() {
-		if err := SendWelcomeEmail(newUser.Email, newUser.Username, "linkedin"); err != nil {
+		if err := SendWelcomeEmail(newUser.Email, newUser.Username, linkedInProvider); err != nil {
 			log.Printf("Error sending welcome email: %v", err)
 		}
 	}()
@@ -278,22 +255,8 @@ func (a *AuthHandler) createNewLinkedInUser(c *fiber.Ctx, profileData LinkedInPr
 		}
 	}()
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+	return c.Status(fiber.StatusCreated).JSON(sessionResponse(fiber.Map{
 		"message": "Successfully created account with LinkedIn",
 		"user":    mappers.MapUserToFrontend(&newUser),
-		"token":   token,
-	})
-}
-
-// Helper function to set JWT cookie
-func setCookie(c *fiber.Ctx, token string) {
-	c.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    token,
-		Expires:  time.Now().Add(24 * time.Hour),
-		HTTPOnly: true,
-		Secure:   true,
-		Path:     "/",
-		SameSite: "Lax",
-	})
+	}, pair))
 }
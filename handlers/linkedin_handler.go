@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/models"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
@@ -48,7 +49,7 @@ func (h *LinkedInJobsHandler) StoreJobApplication(c *fiber.Ctx) error {
 		})
 	}
 
-	userID := c.Locals("userID").(string)
+	userID := middleware.CurrentUID(c)
 
 	if err := h.jobsService.StoreJobApplication(ctx, userID, jobApp); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -64,7 +65,7 @@ func (h *LinkedInJobsHandler) StoreJobApplication(c *fiber.Ctx) error {
 
 func (h *LinkedInJobsHandler) GetUserApplications(c *fiber.Ctx) error {
 	ctx := context.Background()
-	userID := c.Locals("userID").(string)
+	userID := middleware.CurrentUID(c)
 
 	applications, err := h.jobsService.GetUserApplications(ctx, userID)
 	if err != nil {
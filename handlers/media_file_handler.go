@@ -4,29 +4,16 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strings"
 
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
 // UploadImageHandler handles image uploads to Cloudinary
 func UploadImageHandler(c *fiber.Ctx) error {
-	// Validate user token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required. Please log in.",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token. Please log in again.",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Initialize Cloudinary client
 	cld := services.CloudinaryClient
@@ -69,20 +56,7 @@ func UploadImageHandler(c *fiber.Ctx) error {
 
 // UploadPDFHandler handles PDF uploads to Cloudinary
 func UploadPDFHandler(c *fiber.Ctx) error {
-	// Validate user token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required. Please log in.",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token. Please log in again.",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Initialize Cloudinary client
 	cld := services.CloudinaryClient
@@ -127,20 +101,7 @@ func UploadPDFHandler(c *fiber.Ctx) error {
 
 // UploadVideoHandler handles video uploads to Cloudinary
 func UploadVideoHandler(c *fiber.Ctx) error {
-	// Validate user token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required. Please log in.",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token. Please log in again.",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Initialize Cloudinary client
 	cld := services.CloudinaryClient
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -9,39 +10,29 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
 type GroupChatHandler struct {
 	GroupChatService *services.GroupChatService
 	UserService      *services.UserService
+	TypingService    *services.TypingService
 }
 
-func NewGroupChatHandler(groupChatService *services.GroupChatService, userService *services.UserService) *GroupChatHandler {
+func NewGroupChatHandler(groupChatService *services.GroupChatService, userService *services.UserService, typingService *services.TypingService) *GroupChatHandler {
 	return &GroupChatHandler{
 		GroupChatService: groupChatService,
 		UserService:      userService,
+		TypingService:    typingService,
 	}
 }
 
 // CreateGroupChat handles the HTTP request for creating a new group chat
 func (h *GroupChatHandler) CreateGroupChatF(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Fetch the user's details
 	user, err := h.UserService.GetUserByUID(uid)
@@ -121,21 +112,7 @@ func (h *GroupChatHandler) CreateGroupChatF(c *fiber.Ctx) error {
 
 // AddParticipantsToGroupChatHandler handles updating the participants list of a group chat
 func (h *GroupChatHandler) AddParticipantsToGroupChatHandler(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Extract groupChatID or projectID from the URL
 	groupChatID := c.Params("groupChatId")
@@ -191,21 +168,6 @@ func (h *GroupChatHandler) AddParticipantsToGroupChatHandler(c *fiber.Ctx) error
 }
 
 func (h *GroupChatHandler) GetGroupChat(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	_, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
 
 	// Get group chat ID from params
 	groupChatId := c.Params("id")
@@ -230,21 +192,6 @@ func (h *GroupChatHandler) GetGroupChat(c *fiber.Ctx) error {
 }
 
 func (h *GroupChatHandler) GetGroupChatsByProject(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	_, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
 
 	// Get project ID from params
 	projectId := c.Params("projectId")
@@ -269,21 +216,7 @@ func (h *GroupChatHandler) GetGroupChatsByProject(c *fiber.Ctx) error {
 }
 
 func (h *GroupChatHandler) GetMyGroupChats(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Fetch group chats using service
 	groupChats, err := h.GroupChatService.GetGroupChatsByUserService(context.Background(), uid)
@@ -299,22 +232,40 @@ func (h *GroupChatHandler) GetMyGroupChats(c *fiber.Ctx) error {
 	})
 }
 
-func (h *GroupChatHandler) SendMessageHandler(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
+// GetGroupChatMessagesHandler returns one page of a group chat history.
+// The "before" and "after" query parameters are message IDs used as cursors.
+func (h *GroupChatHandler) GetGroupChatMessagesHandler(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
+
+	groupChatID := c.Params("groupChatId")
+	if groupChatID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "groupChatId is required",
 		})
 	}
 
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
+	page := repository.MessagePage{
+		Before: c.Query("before"),
+		After:  c.Query("after"),
+		Limit:  c.QueryInt("limit", 0),
+	}
+
+	messages, err := h.GroupChatService.ListMessagesService(context.Background(), groupChatID, uid, page)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
+	return c.JSON(fiber.Map{
+		"message": "Messages fetched successfully",
+		"data":    mappers.MapBaseMessagesArrayToFrontend(messages),
+	})
+}
+
+func (h *GroupChatHandler) SendMessageHandler(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
+
 	var requestData struct {
 		GroupChatID string `json:"groupChatId"`
 		Content     string `json:"content"`
@@ -346,12 +297,12 @@ func (h *GroupChatHandler) SendMessageHandler(c *fiber.Ctx) error {
 			"error": err.Error(),
 		})
 	}
+	h.TypingService.Stop(context.Background(), uid, services.GroupChatChannel(requestData.GroupChatID))
 
-	// Trigger Pusher event for the group chat
-	channelName := "group-messages-" + requestData.GroupChatID
-	err = services.PusherClient.Trigger(
-		channelName,
-		"new-group-message",
+	// Publish the message on the channel of the group chat
+	err = services.Realtime.Publish(
+		services.GroupChatChannel(requestData.GroupChatID),
+		services.EventNewGroupMessage,
 		mappers.MapBaseMessageGoToFrontend(*message),
 	)
 
@@ -375,10 +326,9 @@ func (h *GroupChatHandler) SendMessageHandler(c *fiber.Ctx) error {
 		}
 
 		// Notify participant about the new message
-		notificationChannelNewMessage := "user-notifications-new-msg-" + participant.UserID
-		err = services.PusherClient.Trigger(
-			notificationChannelNewMessage,
-			"new-unread-message",
+		err = services.Realtime.Publish(
+			services.UserNewMessagesChannel(participant.UserID),
+			services.EventNewUnreadMessage,
 			map[string]string{
 				"groupChatId": requestData.GroupChatID,
 				"senderName":  user["username"].(string),
@@ -390,10 +340,9 @@ func (h *GroupChatHandler) SendMessageHandler(c *fiber.Ctx) error {
 			fmt.Printf("Failed to notify participant %s: %v", participant.Username, err)
 		}
 
-		notificationChannel := "user-notifications-" + participant.UserID
-		err = services.PusherClient.Trigger(
-			notificationChannel,
-			"update-unread-count",
+		err = services.Realtime.Publish(
+			services.UserNotificationsChannel(participant.UserID),
+			services.EventUpdateUnreadCount,
 			map[string]string{
 				"groupChatId": requestData.GroupChatID,
 				"senderName":  user["username"].(string),
@@ -415,10 +364,9 @@ func (h *GroupChatHandler) SendMessageHandler(c *fiber.Ctx) error {
 			})
 		}
 
-		unreadCountNotificationChannel := "group-unread-counts-" + participant.UserID
-		err = services.PusherClient.Trigger(
-			unreadCountNotificationChannel,
-			"update-unread-count",
+		err = services.Realtime.Publish(
+			services.GroupUnreadCountsChannel(participant.UserID),
+			services.EventUpdateUnreadCount,
 			map[string]interface{}{
 				"groupChatId": requestData.GroupChatID,
 				"unreadCount": unreadCount,
@@ -439,21 +387,7 @@ func (h *GroupChatHandler) SendMessageHandler(c *fiber.Ctx) error {
 }
 
 func (h *GroupChatHandler) MarkMessagesAsReadHandler(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get user ID
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	// Get groupChatId from path parameters
 	groupChatID := c.Params("groupChatId")
@@ -463,10 +397,35 @@ func (h *GroupChatHandler) MarkMessagesAsReadHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// The messages are read up to messageId, or up to the latest one without it
+	var requestData struct {
+		MessageID string `json:"messageId"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&requestData); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request payload",
+			})
+		}
+	}
+
 	// Call the service to mark messages as read
 	ctx := context.Background()
-	err = h.GroupChatService.MarkMessagesAsReadService(ctx, groupChatID, userID)
-	if err != nil {
+	position, err := h.GroupChatService.MarkMessagesAsReadService(ctx, groupChatID, userID, requestData.MessageID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Group chat or message not found",
+		})
+	case errors.Is(err, services.ErrForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You are not a participant in this group chat",
+		})
+	case errors.Is(err, services.ErrNoGroupChatMessages):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to mark messages as read: %v", err),
 		})
@@ -474,27 +433,34 @@ func (h *GroupChatHandler) MarkMessagesAsReadHandler(c *fiber.Ctx) error {
 
 	// Respond with success
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Messages marked as read successfully",
+		"message":      "Messages marked as read successfully",
+		"readPosition": position,
 	})
 }
 
-func (h *GroupChatHandler) CountUnreadMessagesHandler(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
+// SendTypingHandler tells the participants of the group chat that the user started typing,
+// or stopped with "typing": false
+func (h *GroupChatHandler) SendTypingHandler(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
 
-	// Validate token and get user ID
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
+	var requestData struct {
+		Typing *bool `json:"typing"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&requestData); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request payload",
+			})
+		}
 	}
 
+	channel := services.GroupChatChannel(c.Params("groupChatId"))
+	return sendTyping(c, h.TypingService, uid, channel, requestData.Typing == nil || *requestData.Typing)
+}
+
+func (h *GroupChatHandler) CountUnreadMessagesHandler(c *fiber.Ctx) error {
+	userID := middleware.CurrentUID(c)
+
 	// Extract query parameters
 	groupChatID := c.Query("groupChatId")
 	projectID := c.Query("projectId")
@@ -514,18 +480,17 @@ func (h *GroupChatHandler) CountUnreadMessagesHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Trigger Pusher event for updating unread counts
-	notificationChannel := "group-unread-counts-" + userID
-	err = services.PusherClient.Trigger(
-		notificationChannel,
-		"update-unread-count",
+	// Publish the updated unread count
+	err = services.Realtime.Publish(
+		services.GroupUnreadCountsChannel(userID),
+		services.EventUpdateUnreadCount,
 		map[string]interface{}{
 			"groupChatId": groupChatID,
 			"unreadCount": unreadCount,
 		},
 	)
 	if err != nil {
-		log.Printf("Pusher trigger failed: %v", err)
+		log.Printf("Realtime publish failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to trigger unread count event",
 		})
@@ -538,21 +503,7 @@ func (h *GroupChatHandler) CountUnreadMessagesHandler(c *fiber.Ctx) error {
 
 // CountUnreadGroupMessagesHandler handles the request to count all unread messages across all group chats for a user
 func (h *GroupChatHandler) CountUnreadGroupMessagesFromAllChatHandler(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get user ID
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	// Call the service to count all unread messages
 	ctx := context.Background()
@@ -563,17 +514,16 @@ func (h *GroupChatHandler) CountUnreadGroupMessagesFromAllChatHandler(c *fiber.C
 		})
 	}
 
-	// Trigger Pusher event for updating total unread counts
-	notificationChannel := "group-total-unread-" + userID
-	err = services.PusherClient.Trigger(
-		notificationChannel,
-		"update-total-unread",
+	// Publish the updated total unread count
+	err = services.Realtime.Publish(
+		services.GroupTotalUnreadChannel(userID),
+		services.EventUpdateTotalUnread,
 		map[string]interface{}{
 			"totalUnreadCount": unreadCount,
 		},
 	)
 	if err != nil {
-		log.Printf("Pusher trigger failed: %v", err)
+		log.Printf("Realtime publish failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to trigger total unread count event",
 		})
@@ -585,21 +535,7 @@ func (h *GroupChatHandler) CountUnreadGroupMessagesFromAllChatHandler(c *fiber.C
 }
 
 func (h *GroupChatHandler) RemoveParticipantsFromGroupChatHandler(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get user ID
-	ownerID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	ownerID := middleware.CurrentUID(c)
 
 	// Extract groupChatId from URL parameter
 	groupChatID := c.Params("groupChatId")
@@ -628,7 +564,7 @@ func (h *GroupChatHandler) RemoveParticipantsFromGroupChatHandler(c *fiber.Ctx)
 
 	// Call the service to remove the participants
 	ctx := context.Background()
-	err = h.GroupChatService.RemoveParticipantsFromGroupChatService(ctx, groupChatID, ownerID, requestData.ParticipantIDs)
+	err := h.GroupChatService.RemoveParticipantsFromGroupChatService(ctx, groupChatID, ownerID, requestData.ParticipantIDs)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to remove participants: %v", err),
@@ -642,21 +578,7 @@ func (h *GroupChatHandler) RemoveParticipantsFromGroupChatHandler(c *fiber.Ctx)
 }
 
 func (h *GroupChatHandler) ReplyToMessageHandler(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get user ID
-	senderID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	senderID := middleware.CurrentUID(c)
 
 	// Parse the request body
 	var requestData struct {
@@ -704,6 +626,7 @@ func (h *GroupChatHandler) ReplyToMessageHandler(c *fiber.Ctx) error {
 			"error": fmt.Sprintf("Failed to reply to the message: %v", err),
 		})
 	}
+	h.TypingService.Stop(ctx, senderID, services.GroupChatChannel(requestData.GroupChatID))
 
 	// Respond with the new reply message
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -712,21 +635,7 @@ func (h *GroupChatHandler) ReplyToMessageHandler(c *fiber.Ctx) error {
 }
 
 func (h *GroupChatHandler) AttachFilesToMessageHandler(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get user ID
-	senderID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	senderID := middleware.CurrentUID(c)
 
 	// Parse form fields
 	groupChatID := c.FormValue("groupChatId")
@@ -767,6 +676,7 @@ func (h *GroupChatHandler) AttachFilesToMessageHandler(c *fiber.Ctx) error {
 			"error": fmt.Sprintf("Failed to attach files to message: %v", err),
 		})
 	}
+	h.TypingService.Stop(ctx, senderID, services.GroupChatChannel(groupChatID))
 
 	// Respond with the new message
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -775,21 +685,7 @@ func (h *GroupChatHandler) AttachFilesToMessageHandler(c *fiber.Ctx) error {
 }
 
 func (h *GroupChatHandler) PinMessageHandler(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get user ID
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	// Parse the request body
 	var requestData struct {
@@ -816,7 +712,7 @@ func (h *GroupChatHandler) PinMessageHandler(c *fiber.Ctx) error {
 
 	// Call the service to pin the message
 	ctx := context.Background()
-	err = h.GroupChatService.PinMessageService(ctx, requestData.GroupChatID, userID, requestData.MessageID)
+	err := h.GroupChatService.PinMessageService(ctx, requestData.GroupChatID, userID, requestData.MessageID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to pin message: %v", err),
@@ -830,21 +726,6 @@ func (h *GroupChatHandler) PinMessageHandler(c *fiber.Ctx) error {
 }
 
 func (h *GroupChatHandler) GetPinnedMessagesHandler(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get user ID
-	_, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
 
 	// Parse the request body
 	var requestData struct {
@@ -879,21 +760,7 @@ func (h *GroupChatHandler) GetPinnedMessagesHandler(c *fiber.Ctx) error {
 }
 
 func (h *GroupChatHandler) UnpinMessageHandler(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get user ID
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	// Parse the request body
 	var requestData struct {
@@ -920,7 +787,7 @@ func (h *GroupChatHandler) UnpinMessageHandler(c *fiber.Ctx) error {
 
 	// Call the service to unpin the message
 	ctx := context.Background()
-	err = h.GroupChatService.UnpinMessageService(ctx, requestData.GroupChatID, userID, requestData.MessageID)
+	err := h.GroupChatService.UnpinMessageService(ctx, requestData.GroupChatID, userID, requestData.MessageID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to unpin message: %v", err),
@@ -943,12 +810,9 @@ func (h *GroupChatHandler) ReactToMessageHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
 	}
 
-	userID, err := validateToken(c.Get("Authorization"))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
-	}
+	userID := middleware.CurrentUID(c)
 
-	err = h.GroupChatService.ReactToMessageService(context.Background(), requestData.GroupChatID, userID, requestData.MessageID, requestData.Reaction)
+	err := h.GroupChatService.ReactToMessageService(context.Background(), requestData.GroupChatID, userID, requestData.MessageID, requestData.Reaction)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -978,12 +842,9 @@ func (h *GroupChatHandler) SetParticipantRoleHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
 	}
 
-	userID, err := validateToken(c.Get("Authorization"))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
-	}
+	userID := middleware.CurrentUID(c)
 
-	err = h.GroupChatService.SetParticipantRoleService(context.Background(), requestData.GroupChatID, userID, requestData.ParticipantID, requestData.NewRole)
+	err := h.GroupChatService.SetParticipantRoleService(context.Background(), requestData.GroupChatID, userID, requestData.ParticipantID, requestData.NewRole)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -1002,12 +863,9 @@ func (h *GroupChatHandler) MuteParticipantHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
 	}
 
-	userID, err := validateToken(c.Get("Authorization"))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
-	}
+	userID := middleware.CurrentUID(c)
 
-	err = h.GroupChatService.MuteParticipantService(context.Background(), requestData.GroupChatID, userID, requestData.ParticipantID, time.Duration(requestData.Duration)*time.Second)
+	err := h.GroupChatService.MuteParticipantService(context.Background(), requestData.GroupChatID, userID, requestData.ParticipantID, time.Duration(requestData.Duration)*time.Second)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -1015,31 +873,12 @@ func (h *GroupChatHandler) MuteParticipantHandler(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"message": "Participant muted successfully"})
 }
 
-func (h *GroupChatHandler) UpdateLastSeenHandler(c *fiber.Ctx) error {
-	groupChatID := c.Params("groupChatId")
-
-	userID, err := validateToken(c.Get("Authorization"))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
-	}
-
-	err = h.GroupChatService.UpdateLastSeenService(context.Background(), groupChatID, userID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(fiber.Map{"message": "Last seen updated successfully"})
-}
-
 func (h *GroupChatHandler) ArchiveGroupChatHandler(c *fiber.Ctx) error {
 	groupChatID := c.Params("groupChatId")
 
-	userID, err := validateToken(c.Get("Authorization"))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
-	}
+	userID := middleware.CurrentUID(c)
 
-	err = h.GroupChatService.ArchiveGroupChatService(context.Background(), groupChatID, userID)
+	err := h.GroupChatService.ArchiveGroupChatService(context.Background(), groupChatID, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -1050,23 +889,9 @@ func (h *GroupChatHandler) ArchiveGroupChatHandler(c *fiber.Ctx) error {
 func (h *GroupChatHandler) LeaveGroupHandler(c *fiber.Ctx) error {
 	groupChatID := c.Params("groupChatId")
 
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
-	err = h.GroupChatService.LeaveGroupService(context.Background(), groupChatID, userID)
+	err := h.GroupChatService.LeaveGroupService(context.Background(), groupChatID, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -1084,10 +909,7 @@ func (h *GroupChatHandler) CreatePollHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
 	}
 
-	userID, err := validateToken(c.Get("Authorization"))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
-	}
+	userID := middleware.CurrentUID(c)
 
 	poll, err := h.GroupChatService.CreatePollService(context.Background(), requestData.GroupChatID, userID, requestData.Poll)
 	if err != nil {
@@ -1108,12 +930,9 @@ func (h *GroupChatHandler) ReportMessageHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
 	}
 
-	userID, err := validateToken(c.Get("Authorization"))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
-	}
+	userID := middleware.CurrentUID(c)
 
-	err = h.GroupChatService.ReportMessageService(context.Background(), requestData.GroupChatID, userID, requestData.MessageID, requestData.Reason)
+	err := h.GroupChatService.ReportMessageService(context.Background(), requestData.GroupChatID, userID, requestData.MessageID, requestData.Reason)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -1123,19 +942,7 @@ func (h *GroupChatHandler) ReportMessageHandler(c *fiber.Ctx) error {
 
 // UpdateGroupSettingsHandler handles the HTTP request to update group settings
 func (h *GroupChatHandler) UpdateGroupSettingsHandler(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	groupChatId := c.Params("groupChatId")
 	if groupChatId == "" {
@@ -1160,7 +967,7 @@ func (h *GroupChatHandler) UpdateGroupSettingsHandler(c *fiber.Ctx) error {
 	requestDataGo := mappers.MapGroupSettingsFrontendToGo(requestData)
 
 	ctx := context.Background()
-	err = h.GroupChatService.UpdateGroupSettingsService(ctx, groupChatId, userID, requestDataGo)
+	err := h.GroupChatService.UpdateGroupSettingsService(ctx, groupChatId, userID, requestDataGo)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to update group settings: %v", err),
@@ -1197,19 +1004,7 @@ func (h *GroupChatHandler) UpdateGroupSettingsHandler(c *fiber.Ctx) error {
 // }
 
 func (h *GroupChatHandler) DeleteGroupChat(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	chatID := c.Params("id")
 	if chatID == "" {
@@ -1218,7 +1013,7 @@ func (h *GroupChatHandler) DeleteGroupChat(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.GroupChatService.DeleteGroupChatService(context.Background(), chatID, uid)
+	err := h.GroupChatService.DeleteGroupChatService(context.Background(), chatID, uid)
 	if err != nil {
 		if strings.Contains(err.Error(), "unauthorized") {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -1241,19 +1036,7 @@ func (h *GroupChatHandler) DeleteGroupChat(c *fiber.Ctx) error {
 }
 
 func (h *GroupChatHandler) DeleteMultipleGroupChats(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	var requestData struct {
 		ChatIDs []string `json:"chatIds"`
@@ -1270,7 +1053,7 @@ func (h *GroupChatHandler) DeleteMultipleGroupChats(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.GroupChatService.DeleteMultipleGroupChatsService(context.Background(), requestData.ChatIDs, uid)
+	err := h.GroupChatService.DeleteMultipleGroupChatsService(context.Background(), requestData.ChatIDs, uid)
 	if err != nil {
 		if strings.Contains(err.Error(), "Unauthorized") || strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -2,49 +2,38 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"sort"
-	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 	"github.com/rogerjeasy/go-letusconnect/services"
-	"google.golang.org/api/iterator"
 )
 
 type MessageHandler struct {
-	MessageService *services.MessageService
-	UserService    *services.UserService
+	UserService         *services.UserService
+	ConversationService *services.ConversationService
+	TypingService       *services.TypingService
 }
 
-func NewMessageHandler(messageService *services.MessageService, userService *services.UserService) *MessageHandler {
+func NewMessageHandler(userService *services.UserService, conversationService *services.ConversationService, typingService *services.TypingService) *MessageHandler {
 	return &MessageHandler{
-		MessageService: messageService,
-		UserService:    userService,
+		UserService:         userService,
+		ConversationService: conversationService,
+		TypingService:       typingService,
 	}
 }
 
-// SendMessage handles sending a message and triggering a Pusher event
+// SendMessage handles sending a message and publishing a realtime event. The message is
+// stored as a direct message in the conversation of the sender and receiver.
 func (m *MessageHandler) SendMessage(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required. Please log in",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token. Please log in again",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	var payload map[string]interface{}
 	if err := c.BodyParser(&payload); err != nil {
@@ -60,22 +49,33 @@ func (m *MessageHandler) SendMessage(c *fiber.Ctx) error {
 	if message.SenderID != uid {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You can only send messages as yourself"})
 	}
+	if message.ReceiverID == "" || message.ReceiverID == uid {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "A receiver other than yourself is required"})
+	}
 
-	// Add message to Firestore
-	_, _, err = services.Firestore.Collection("messages").Add(context.Background(), mappers.MapMessageGoToFirestore(message))
+	directMessage := models.DirectMessage{
+		BaseMessage: models.BaseMessage{
+			ID:         message.ID,
+			SenderID:   message.SenderID,
+			Content:    message.Content,
+			CreatedAt:  message.CreatedAt.Format(time.RFC3339),
+			ReadStatus: map[string]bool{message.SenderID: true, message.ReceiverID: false},
+		},
+		ReceiverID: message.ReceiverID,
+	}
+
+	ctx := context.Background()
+	conversation, err := m.ConversationService.SendDirectMessage(ctx, &directMessage)
 	if err != nil {
+		log.Printf("Error sending message: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send message"})
 	}
+	m.TypingService.Stop(ctx, uid, services.DirectMessagesChannel(conversation.ID))
 
-	// Create a sorted list of sender and receiver IDs
-	ids := []string{message.SenderID, message.ReceiverID}
-	sort.Strings(ids)
-	channelName := "private-messages-" + strings.Join(ids, "-")
-
-	// Trigger Pusher event with the consistent channel name
-	err = services.PusherClient.Trigger(
-		channelName,
-		"new-message",
+	// Publish the message on the channel of the conversation
+	err = services.Realtime.Publish(
+		services.DirectMessagesChannel(conversation.ID),
+		services.EventNewMessage,
 		mappers.MapMessageGoToFrontend(message),
 	)
 
@@ -88,56 +88,24 @@ func (m *MessageHandler) SendMessage(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"success": "Message sent successfully", "message": message})
 }
 
-// GetMessages retrieves all messages for the authenticated user
+// GetMessages retrieves the direct messages of the authenticated user's most recent conversations
 func (m *MessageHandler) GetMessages(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
+	histories, err := m.latestMessages(context.Background(), uid)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": fmt.Sprintf("Failed to fetch messages: %v", err),
 		})
 	}
 
-	iter := services.Firestore.Collection("messages").
-		Documents(context.Background())
-
-	var directMessages []models.DirectMessage
-	var channelID string
-
-	for {
-		doc, err := iter.Next()
-		if err != nil {
-			if err == iterator.Done {
-				break
-			}
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": fmt.Sprintf("Failed to fetch messages: %v", err),
-			})
-		}
-
-		data := doc.Data()
-
-		channelID, ok := data["channel_id"].(string)
-		if ok && strings.Contains(channelID, uid) {
-			directMessage := mappers.MapDirectMessageFirestoreToGo(data)
-			fmt.Println("directMessages: ", directMessage)
-			directMessages = append(directMessages, directMessage)
-		}
-	}
-
-	if len(directMessages) == 0 {
-		directMessages = []models.DirectMessage{}
-		channelID = ""
+	directMessages := []models.DirectMessage{}
+	for _, history := range histories {
+		directMessages = append(directMessages, history.DirectMessages...)
 	}
 
 	messages := models.Messages{
-		ChannelID:      channelID,
+		ChannelID:      "",
 		DirectMessages: directMessages,
 	}
 
@@ -146,27 +114,38 @@ func (m *MessageHandler) GetMessages(c *fiber.Ctx) error {
 	return c.JSON(frontendMessages)
 }
 
-// SendTyping handles the typing event and triggers a Pusher event
-func (m *MessageHandler) SendTyping(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required. Please log in",
-		})
+// latestMessages returns the latest page of messages of the user's most recent conversations
+func (m *MessageHandler) latestMessages(ctx context.Context, uid string) ([]models.Messages, error) {
+	conversations, err := m.ConversationService.ListConversations(ctx, uid, repository.ConversationPage{})
+	if err != nil {
+		return nil, err
 	}
 
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token. Please log in again",
+	histories := []models.Messages{}
+	for _, conversation := range conversations {
+		messages, err := m.ConversationService.ListMessages(ctx, conversation.ID, uid, repository.MessagePage{})
+		if err != nil {
+			return nil, err
+		}
+		histories = append(histories, models.Messages{
+			ChannelID:      conversation.ID,
+			DirectMessages: messages,
 		})
 	}
 
+	return histories, nil
+}
+
+// SendTyping tells the receiver that the user started typing, or stopped with "typing": false.
+// Clients repeat the start every few seconds while the user types; the user stops typing on
+// their own without it.
+func (m *MessageHandler) SendTyping(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
+
 	// Parse request payload
 	var payload struct {
 		ReceiverID string `json:"receiverId"`
+		Typing     *bool  `json:"typing"`
 	}
 
 	if err := c.BodyParser(&payload); err != nil {
@@ -182,18 +161,26 @@ func (m *MessageHandler) SendTyping(c *fiber.Ctx) error {
 		})
 	}
 
-	// Create a sorted list of sender and receiver IDs
-	ids := []string{uid, payload.ReceiverID}
-	sort.Strings(ids)
-	channelName := "private-messages-" + strings.Join(ids, "-")
+	channel := services.DirectMessagesChannel(services.ConversationID(uid, payload.ReceiverID))
+	return sendTyping(c, m.TypingService, uid, channel, payload.Typing == nil || *payload.Typing)
+}
 
-	// Trigger Pusher event with the consistent channel name
-	err = services.PusherClient.Trigger(channelName, "user-typing", map[string]string{
-		"senderId":   uid,
-		"receiverId": payload.ReceiverID,
-	})
+// sendTyping starts or stops the user typing in the conversation of the channel
+func sendTyping(c *fiber.Ctx, typingService *services.TypingService, uid, channel string, typing bool) error {
+	var err error
+	if typing {
+		err = typingService.Start(context.Background(), uid, channel)
+	} else {
+		err = typingService.Stop(context.Background(), uid, channel)
+	}
 
+	if errors.Is(err, services.ErrChannelForbidden) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
+		log.Printf("Error sending the typing status of user %s: %v", uid, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to send typing notification",
 		})
@@ -204,21 +191,9 @@ func (m *MessageHandler) SendTyping(c *fiber.Ctx) error {
 	})
 }
 
-// SendDirectMessage handles sending a direct message and triggering a Pusher event
+// SendDirectMessage handles sending a direct message and publishing a realtime event
 func (m *MessageHandler) SendDirectMessage(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required. Please log in.",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token. Please log in again.",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	var payload map[string]interface{}
 	if err := c.BodyParser(&payload); err != nil {
@@ -253,43 +228,17 @@ func (m *MessageHandler) SendDirectMessage(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You can only send messages as yourself."})
 	}
 
-	ids := []string{message.SenderID, message.ReceiverID}
-	sort.Strings(ids)
-	channelID := strings.Join(ids, "-")
-
-	docRef := services.Firestore.Collection("messages").Doc(channelID)
-
-	docSnapshot, err := docRef.Get(context.Background())
-	if err != nil && !docSnapshot.Exists() {
-		messages := models.Messages{
-			ChannelID:      channelID,
-			DirectMessages: []models.DirectMessage{message},
-		}
-
-		_, err = docRef.Set(context.Background(), mappers.MapMessagesGoToFirestore(messages))
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send message."})
-		}
-	} else {
-		var existingMessages models.Messages
-		err := docSnapshot.DataTo(&existingMessages)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read existing messages."})
-		}
-
-		existingMessages.DirectMessages = append(existingMessages.DirectMessages, message)
-
-		_, err = docRef.Set(context.Background(), mappers.MapMessagesGoToFirestore(existingMessages))
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update messages."})
-		}
+	ctx := context.Background()
+	conversation, err := m.ConversationService.SendDirectMessage(ctx, &message)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send message."})
 	}
+	m.TypingService.Stop(ctx, uid, services.DirectMessagesChannel(conversation.ID))
 
-	// Trigger Pusher event with the consistent channel name
-	channelName := "private-messages-" + channelID
-	err = services.PusherClient.Trigger(
-		channelName,
-		"new-direct-message",
+	// Publish the message on the channel of the conversation
+	err = services.Realtime.Publish(
+		services.DirectMessagesChannel(conversation.ID),
+		services.EventNewDirectMessage,
 		mappers.MapDirectMessageGoToFrontend(message),
 	)
 
@@ -299,11 +248,15 @@ func (m *MessageHandler) SendDirectMessage(c *fiber.Ctx) error {
 		})
 	}
 
-	// Trigger Pusher event for notification
-	notificationChannel := "user-notifications-direct-msg-" + message.ReceiverID
-	err = services.PusherClient.Trigger(
-		notificationChannel,
-		"update-unread-count",
+	// The receiver is not notified about conversations they muted
+	if conversation.Muted[message.ReceiverID] {
+		return c.JSON(fiber.Map{"success": "Direct message sent successfully.", "message": message})
+	}
+
+	// Notify the receiver
+	err = services.Realtime.Publish(
+		services.UserDirectMessagesChannel(message.ReceiverID),
+		services.EventUpdateUnreadCount,
 		map[string]string{
 			"senderName": message.SenderName,
 			"content":    message.Content,
@@ -322,55 +275,18 @@ func (m *MessageHandler) SendDirectMessage(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"success": "Direct message sent successfully.", "message": message})
 }
 
-// GetDirectMessages fetches direct messages for the authenticated user based on their UID
+// GetDirectMessages fetches the latest direct messages of the authenticated user's most recent conversations
 func (m *MessageHandler) GetDirectMessages(c *fiber.Ctx) error {
 
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required. Please log in.",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
+	messagesList, err := m.latestMessages(context.Background(), uid)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token. Please log in again.",
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch messages.",
 		})
 	}
 
-	// Fetch all documents from the Firestore messages collection
-	iter := services.Firestore.Collection("messages").Documents(context.Background())
-
-	var messagesList []models.Messages
-
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch messages.",
-			})
-		}
-
-		data := doc.Data()
-		channelID, ok := data["channel_id"].(string)
-		if ok && strings.Contains(channelID, uid) {
-			var messages models.Messages
-			err = doc.DataTo(&messages)
-			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Failed to parse messages.",
-				})
-			}
-			messagesList = append(messagesList, messages)
-		}
-	}
-
 	// Check if no messages were found
 	if len(messagesList) == 0 {
 		return c.JSON(fiber.Map{
@@ -391,245 +307,207 @@ func (m *MessageHandler) GetDirectMessages(c *fiber.Ctx) error {
 	})
 }
 
-// ========================= Send Group Message =========================
+// GetUnreadMessagesCount fetches the count of unread direct messages for the logged-in user.
+// If a senderId query parameter is provided, it counts unread messages only from that sender.
+func (m *MessageHandler) GetUnreadMessagesCount(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
 
-// SendGroupMessage handles sending a group message and triggering a Pusher event
-func SendGroupMessage(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required. Please log in.",
-		})
-	}
+	// Optional sender ID parameter to filter unread messages by sender
+	senderID := c.Query("senderId")
 
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
+	unreadCount, err := m.ConversationService.CountUnread(context.Background(), uid, senderID)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token. Please log in again.",
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count unread messages.",
 		})
 	}
 
-	var payload map[string]interface{}
-	if err := c.BodyParser(&payload); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request payload."})
-	}
+	return c.JSON(fiber.Map{
+		"unreadCount": unreadCount,
+	})
+}
 
-	// Convert payload to GroupMessage struct
-	message := mappers.MapGroupMessageFrontendToGo(payload)
-	message.ID = uuid.New().String()
+// MarkMessagesAsRead updates the read status of direct messages for the logged-in user
+func (m *MessageHandler) MarkMessagesAsRead(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
 
-	// Ensure the sender ID matches the authenticated user
-	if message.SenderID != uid {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You can only send messages as yourself."})
+	var payload struct {
+		SenderID string `json:"senderId"`
 	}
 
-	// Fetch the list of group members (assuming there's a function to get group members)
-	groupMembers, err := services.GetGroupMembers(message.ProjectID, message.GroupID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch group members.",
+	if err := c.BodyParser(&payload); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request payload.",
 		})
 	}
 
-	// Initialize ReadStatus for all group members
-	message.ReadStatus = make(map[string]bool)
-	for _, memberID := range groupMembers {
-		// Set the sender's ReadStatus to true and others to false
-		if memberID == message.SenderID {
-			message.ReadStatus[memberID] = true
-		} else {
-			message.ReadStatus[memberID] = false
-		}
+	if payload.SenderID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Sender ID is required.",
+		})
 	}
 
-	// Add message to Firestore
-	_, _, err = services.Firestore.Collection("group_messages").Add(context.Background(), mappers.MapGroupMessageGoToFirestore(message))
+	ctx := context.Background()
+	conversation, err := m.ConversationService.GetConversation(ctx, services.ConversationID(uid, payload.SenderID), uid)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send group message."})
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "No messages found for the provided sender and receiver IDs.",
+		})
 	}
 
-	// Create a channel name based on the project ID or group ID
-	channelName := "group-messages-" + message.ProjectID
-	if message.GroupID != nil {
-		channelName += "-" + *message.GroupID
+	if conversation.UnreadCount[uid] == 0 {
+		return c.JSON(fiber.Map{
+			"success": "No unread messages to mark as read.",
+		})
 	}
 
-	// Trigger Pusher event with the group channel name
-	err = services.PusherClient.Trigger(
-		channelName,
-		"new-group-message",
-		mappers.MapGroupMessageGoToFrontend(message),
+	return m.markConversationRead(c, conversation.ID, uid)
+}
+
+// markConversationRead marks the conversation as read and notifies the user's other clients
+func (m *MessageHandler) markConversationRead(c *fiber.Ctx, conversationID, uid string) error {
+	if err := m.ConversationService.MarkRead(context.Background(), conversationID, uid); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update read status.",
+		})
+	}
+
+	// Notify the frontend to update the unread count
+	err := services.Realtime.Publish(
+		services.UserNotificationsChannel(uid),
+		services.EventMessageRead,
+		map[string]interface{}{
+			"timestamp": time.Now(),
+		},
 	)
 
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to trigger event.",
+			"error": "Failed to trigger notification event.",
 		})
 	}
 
-	return c.JSON(fiber.Map{"success": "Group message sent successfully.", "message": message})
+	return c.JSON(fiber.Map{
+		"success": "Messages marked as read successfully.",
+	})
 }
 
-// GetUnreadMessagesCount fetches the count of unread direct messages for the logged-in user.
-// If a senderId query parameter is provided, it counts unread messages only from that sender.
-func GetUnreadMessagesCount(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required. Please log in.",
-		})
+// ========================= Conversations =========================
+
+// GetConversations lists the authenticated user's conversations, most recently active first.
+// The before query parameter is the ID of the last conversation of the previous page.
+func (m *MessageHandler) GetConversations(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
+
+	page := repository.ConversationPage{
+		Before:   c.Query("before"),
+		Limit:    c.QueryInt("limit", 0),
+		Archived: c.QueryBool("archived", false),
 	}
 
-	// Validate token and get the user ID (UID)
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
+	conversations, err := m.ConversationService.ListConversations(context.Background(), uid, page)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token. Please log in again.",
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
-	// Optional sender ID parameter to filter unread messages by sender
-	senderID := c.Query("senderId")
-
-	// Query Firestore for all messages documents
-	iter := services.Firestore.Collection("messages").Documents(context.Background())
+	frontendConversations := []map[string]interface{}{}
+	for _, conversation := range conversations {
+		frontendConversations = append(frontendConversations, mappers.MapDirectConversationGoToFrontend(conversation, uid))
+	}
 
-	unreadCount := 0
+	return c.JSON(fiber.Map{
+		"success":       "Conversations fetched successfully.",
+		"conversations": frontendConversations,
+	})
+}
 
-	for {
-		doc, err := iter.Next()
-		if err != nil {
-			break
-		}
+// GetConversationMessages fetches one page of the messages of a conversation, oldest first
+func (m *MessageHandler) GetConversationMessages(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
 
-		var messages models.Messages
-		if err := doc.DataTo(&messages); err != nil {
-			log.Println("Error parsing document:", err)
-			continue
-		}
+	page := repository.MessagePage{
+		Before: c.Query("before"),
+		After:  c.Query("after"),
+		Limit:  c.QueryInt("limit", 0),
+	}
 
-		for _, message := range messages.DirectMessages {
-			// Check if the message is for the logged-in user
-			if message.ReceiverID == uid {
-				// If senderID is provided, filter by senderID
-				if senderID != "" && message.SenderID != senderID {
-					continue
-				}
-
-				// Check if the ReadStatus map contains the user's ID and if the value is false
-				if read, exists := message.ReadStatus[uid]; !exists || !read {
-					unreadCount++
-				}
-			}
-		}
+	messages, err := m.ConversationService.ListMessages(context.Background(), c.Params("conversationId"), uid, page)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
 	}
 
 	return c.JSON(fiber.Map{
-		"unreadCount": unreadCount,
+		"success":  "Messages fetched successfully.",
+		"messages": mappers.MapDirectMessagesArrayToFrontend(messages),
 	})
 }
 
-// MarkMessagesAsRead updates the read status of direct messages for the logged-in user
-func MarkMessagesAsRead(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required. Please log in.",
-		})
-	}
+// MarkConversationAsRead marks the messages the authenticated user received in a conversation as read
+func (m *MessageHandler) MarkConversationAsRead(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
 
-	// Validate token and get the user ID (UID)
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
+	conversation, err := m.ConversationService.GetConversation(context.Background(), c.Params("conversationId"), uid)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token. Please log in again.",
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
+	return m.markConversationRead(c, conversation.ID, uid)
+}
+
+// MuteConversation mutes or unmutes a conversation for the authenticated user
+func (m *MessageHandler) MuteConversation(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
+
 	var payload struct {
-		SenderID string `json:"senderId"`
+		Muted bool `json:"muted"`
 	}
-
 	if err := c.BodyParser(&payload); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid request payload.",
 		})
 	}
 
-	if payload.SenderID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Sender ID is required.",
-		})
-	}
-
-	// Create a sorted list of sender and receiver IDs for consistent channel ID
-	ids := []string{uid, payload.SenderID}
-	sort.Strings(ids)
-	channelID := strings.Join(ids, "-")
-
-	docRef := services.Firestore.Collection("messages").Doc(channelID)
-
-	// Fetch the document from Firestore
-	docSnapshot, err := docRef.Get(context.Background())
-	if err != nil || !docSnapshot.Exists() {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "No messages found for the provided sender and receiver IDs.",
-		})
-	}
-
-	// Convert Firestore data to the Messages struct
-	var messages models.Messages
-	if err := docSnapshot.DataTo(&messages); err != nil {
+	conversation, err := m.ConversationService.SetMuted(context.Background(), c.Params("conversationId"), uid, payload.Muted)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to parse messages.",
+			"error": err.Error(),
 		})
 	}
 
-	// Update the ReadStatus for all messages where the receiver is the logged-in user
-	updated := false
-	for i, message := range messages.DirectMessages {
-		if message.ReceiverID == uid {
-			if read, exists := message.ReadStatus[uid]; !exists || !read {
-				messages.DirectMessages[i].ReadStatus[uid] = true
-				updated = true
-			}
-		}
-	}
+	return c.JSON(fiber.Map{
+		"success":      "Conversation updated successfully.",
+		"conversation": mappers.MapDirectConversationGoToFrontend(*conversation, uid),
+	})
+}
 
-	if !updated {
-		return c.JSON(fiber.Map{
-			"success": "No unread messages to mark as read.",
-		})
-	}
+// ArchiveConversation archives or restores a conversation for the authenticated user
+func (m *MessageHandler) ArchiveConversation(c *fiber.Ctx) error {
+	uid := middleware.CurrentUID(c)
 
-	// Update the document in Firestore
-	_, err = docRef.Set(context.Background(), mappers.MapMessagesGoToFirestore(messages))
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update read status.",
+	var payload struct {
+		Archived bool `json:"archived"`
+	}
+	if err := c.BodyParser(&payload); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request payload.",
 		})
 	}
 
-	// Trigger a Pusher event to notify the frontend to update the unread count
-	notificationChannel := "user-notifications-" + uid
-	err = services.PusherClient.Trigger(
-		notificationChannel,
-		"message-read",
-		map[string]interface{}{
-			"timestamp": time.Now(),
-		},
-	)
-
+	conversation, err := m.ConversationService.SetArchived(context.Background(), c.Params("conversationId"), uid, payload.Archived)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to trigger notification event.",
+			"error": err.Error(),
 		})
 	}
 
 	return c.JSON(fiber.Map{
-		"success": "Messages marked as read successfully.",
+		"success":      "Conversation updated successfully.",
+		"conversation": mappers.MapDirectConversationGoToFrontend(*conversation, uid),
 	})
 }
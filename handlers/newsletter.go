@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/mail"
@@ -9,8 +10,8 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 	"github.com/rogerjeasy/go-letusconnect/services"
-	"google.golang.org/api/iterator"
 )
 
 type NewsletterHandler struct {
@@ -46,25 +47,12 @@ func (s *NewsletterHandler) SubscribeNewsletter(c *fiber.Ctx) error {
 	}
 
 	ctx := context.Background()
-	newsletterCollection := services.Firestore.Collection("newsletters")
-
-	// Check if the email already exists in the newsletter collection
-	emailQuery := newsletterCollection.Where("email", "==", email).Documents(ctx)
-	defer emailQuery.Stop()
-
-	if _, err := emailQuery.Next(); err != iterator.Done {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"error": "This email is already subscribed to the newsletter",
-		})
-	}
-
-	// Add the email to the newsletter collection
-	_, _, err := newsletterCollection.Add(ctx, map[string]interface{}{
-		"email":        email,
-		"subscribedAt": time.Now().Format(time.RFC3339),
-	})
-
-	if err != nil {
+	if _, err := s.newsletterService.Subscribe(ctx, email); err != nil {
+		if errors.Is(err, services.ErrAlreadySubscribed) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "This email is already subscribed to the newsletter",
+			})
+		}
 		log.Printf("Error subscribing email to newsletter: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to subscribe to the newsletter",
@@ -105,21 +93,12 @@ func (s *NewsletterHandler) UnsubscribeNewsletter(c *fiber.Ctx) error {
 	}
 
 	ctx := context.Background()
-	newsletterCollection := services.Firestore.Collection("newsletters")
-
-	// Find and delete the document with the specified email
-	emailQuery := newsletterCollection.Where("email", "==", email).Documents(ctx)
-	defer emailQuery.Stop()
-
-	doc, err := emailQuery.Next()
-	if err == iterator.Done {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Email not found in the newsletter subscriptions",
-		})
-	}
-
-	_, err = newsletterCollection.Doc(doc.Ref.ID).Delete(ctx)
-	if err != nil {
+	if err := s.newsletterService.Unsubscribe(ctx, email); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Email not found in the newsletter subscriptions",
+			})
+		}
 		log.Printf("Error unsubscribing email from newsletter: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to unsubscribe from the newsletter",
@@ -139,27 +118,12 @@ func (s *NewsletterHandler) UnsubscribeNewsletter(c *fiber.Ctx) error {
 // GetAllSubscribers retrieves a list of all subscribed users
 func (s *NewsletterHandler) GetAllSubscribers(c *fiber.Ctx) error {
 	ctx := context.Background()
-	newsletterCollection := services.Firestore.Collection("newsletters")
-
-	// Get all documents from the newsletter collection
-	iter := newsletterCollection.Documents(ctx)
-	defer iter.Stop()
-
-	var subscribers []map[string]interface{}
-
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			log.Printf("Error fetching subscribers: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch subscribers",
-			})
-		}
-
-		subscribers = append(subscribers, doc.Data())
+	subscribers, err := s.newsletterService.GetAllSubscribers(ctx)
+	if err != nil {
+		log.Printf("Error fetching subscribers: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch subscribers",
+		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
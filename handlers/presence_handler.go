@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+type PresenceHandler struct {
+	presenceService   *services.PresenceService
+	connectionService *services.UserConnectionService
+}
+
+func NewPresenceHandler(presenceService *services.PresenceService, connectionService *services.UserConnectionService) *PresenceHandler {
+	return &PresenceHandler{
+		presenceService:   presenceService,
+		connectionService: connectionService,
+	}
+}
+
+// GetPresences returns the presence of the users listed in the uids query parameter,
+// separated by commas
+func (h *PresenceHandler) GetPresences(c *fiber.Ctx) error {
+	uids := strings.Split(c.Query("uids"), ",")
+	if c.Query("uids") == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "uids is required",
+		})
+	}
+	if len(uids) > services.MaxPresenceQuery {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("At most %d users can be queried at once", services.MaxPresenceQuery),
+		})
+	}
+
+	return h.respond(c, func(ctx context.Context) (interface{}, error) {
+		return h.presenceService.GetPresences(ctx, uids)
+	})
+}
+
+// GetParticipantPresence returns the presence of one user
+func (h *PresenceHandler) GetParticipantPresence(c *fiber.Ctx) error {
+	return h.respond(c, func(ctx context.Context) (interface{}, error) {
+		presences, err := h.presenceService.GetPresences(ctx, []string{c.Params("participantId")})
+		if err != nil {
+			return nil, err
+		}
+		return presences[0], nil
+	})
+}
+
+// GetConnectionsPresence returns the presence of the connections of the current user
+func (h *PresenceHandler) GetConnectionsPresence(c *fiber.Ctx) error {
+	return h.respond(c, func(ctx context.Context) (interface{}, error) {
+		connections, err := h.connectionService.GetUserConnections(ctx, middleware.CurrentUID(c))
+		if err != nil {
+			return nil, err
+		}
+		uids := make([]string, 0, len(connections.Connections))
+		for uid := range connections.Connections {
+			uids = append(uids, uid)
+		}
+		return h.presenceService.GetPresences(ctx, uids)
+	})
+}
+
+// GetGroupChatPresence returns the presence of the participants of a group chat
+func (h *PresenceHandler) GetGroupChatPresence(c *fiber.Ctx) error {
+	return h.respond(c, func(ctx context.Context) (interface{}, error) {
+		return h.presenceService.GetGroupChatPresences(ctx, middleware.CurrentUID(c), c.Params("groupChatId"))
+	})
+}
+
+// GetMyPresence returns the presence of the current user with their connected devices
+func (h *PresenceHandler) GetMyPresence(c *fiber.Ctx) error {
+	return h.respond(c, func(ctx context.Context) (interface{}, error) {
+		return h.presenceService.GetOwnPresence(ctx, middleware.CurrentUID(c))
+	})
+}
+
+// UpdateMyVisibility hides the presence of the current user, or shows it again
+func (h *PresenceHandler) UpdateMyVisibility(c *fiber.Ctx) error {
+	var request struct {
+		Hidden *bool `json:"hidden"`
+	}
+	if err := c.BodyParser(&request); err != nil || request.Hidden == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "hidden is required",
+		})
+	}
+
+	return h.respond(c, func(ctx context.Context) (interface{}, error) {
+		return h.presenceService.SetHidden(ctx, middleware.CurrentUID(c), *request.Hidden)
+	})
+}
+
+// respond answers with the presence returned by fetch
+func (h *PresenceHandler) respond(c *fiber.Ctx, fetch func(context.Context) (interface{}, error)) error {
+	presence, err := fetch(context.Background())
+	switch {
+	// Users without a presence are offline, so only group chats can be missing
+	case errors.Is(err, repository.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Group chat not found",
+		})
+	case errors.Is(err, services.ErrForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		log.Printf("Error fetching presence: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch presence",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"presence": presence,
+	})
+}
@@ -4,48 +4,33 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
+	"github.com/rogerjeasy/go-letusconnect/models"
 	"github.com/rogerjeasy/go-letusconnect/services"
-	"google.golang.org/api/iterator"
 )
 
 type ProjectHandlerSetup struct {
-	// projectService *services.ProjectService
-	userService      *services.UserService
-	groupChatService *services.GroupChatService
+	projectCoreService *services.ProjectCoreService
+	userService        *services.UserService
+	groupChatService   *services.GroupChatService
 }
 
 func NewProjectHandlerSetup(projectCoreService *services.ProjectCoreService, userService *services.UserService, groupChatService *services.GroupChatService) *ProjectHandlerSetup {
 	return &ProjectHandlerSetup{
-		// projectService: projectService,
-		userService:      userService,
-		groupChatService: groupChatService,
+		projectCoreService: projectCoreService,
+		userService:        userService,
+		groupChatService:   groupChatService,
 	}
 }
 
 // CreateProject handles the creation of a new project
 func (h *ProjectHandlerSetup) CreateProject(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Fetch the user's details (username)
 	user, err := h.userService.GetUserByUID(uid)
@@ -120,9 +105,8 @@ func (h *ProjectHandlerSetup) CreateProject(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	// Save to Firestore
-	docRef, _, err := services.Firestore.Collection("projects").Add(ctx, mappers.MapProjectGoToFirestore(newProject))
-	if err != nil {
+	// Save the project
+	if err := h.projectCoreService.CreateProject(ctx, &newProject); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create project",
 		})
@@ -130,7 +114,7 @@ func (h *ProjectHandlerSetup) CreateProject(c *fiber.Ctx) error {
 
 	// Create group chat for the project
 	groupChatInput := services.GroupChatInput{
-		ProjectID:      docRef.ID,
+		ProjectID:      newProject.ID,
 		Name:           requestData["title"].(string),
 		CreatedByUID:   uid,
 		CreatedByName:  user["username"].(string),
@@ -141,10 +125,10 @@ func (h *ProjectHandlerSetup) CreateProject(c *fiber.Ctx) error {
 	_, err = h.groupChatService.CreateGroupChatService(ctx, groupChatInput)
 	if err != nil {
 		// Log the error but don't fail the project creation
-		log.Printf("Failed to create associated group chat for project %s: %v", docRef.ID, err)
+		log.Printf("Failed to create associated group chat for project %s: %v", newProject.ID, err)
 	}
 
-	c.Locals("id", docRef.ID)
+	c.Locals("id", newProject.ID)
 	c.Locals("message", "Project created successfully")
 
 	return h.GetProject(c)
@@ -152,22 +136,6 @@ func (h *ProjectHandlerSetup) CreateProject(c *fiber.Ctx) error {
 
 // UpdateProject handles updating project details
 func (h *ProjectHandlerSetup) UpdateProject(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
-
 	projectID := c.Params("id")
 	if projectID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -177,35 +145,14 @@ func (h *ProjectHandlerSetup) UpdateProject(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	// Fetch the project from Firestore
-	doc, err := services.Firestore.Collection("projects").Doc(projectID).Get(ctx)
+	// Fetch the project
+	project, err := h.projectCoreService.GetProject(ctx, projectID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Project not found",
 		})
 	}
 
-	// Map Firestore data to a Project struct
-	projectData := doc.Data()
-	project := mappers.MapProjectFirestoreToGo(projectData)
-
-	// Check if the user is the project owner
-	isOwner := project.OwnerID == uid
-
-	// Check if the user is an invited user with the role "owner"
-	for _, participant := range project.Participants {
-		if participant.UserID == uid && participant.Role == "owner" {
-			isOwner = true
-			break
-		}
-	}
-
-	if !isOwner {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You do not have permission to update this project",
-		})
-	}
-
 	// Parse the request payload
 	var requestData map[string]interface{}
 	if err := c.BodyParser(&requestData); err != nil {
@@ -240,8 +187,12 @@ func (h *ProjectHandlerSetup) UpdateProject(c *fiber.Ctx) error {
 		updatedProject.OwnerUsername = project.OwnerUsername
 	}
 
-	// Update project in Firestore
-	_, err = services.Firestore.Collection("projects").Doc(projectID).Set(ctx, mappers.MapProjectGoToFirestore(updatedProject), firestore.MergeAll)
+	// Update the stored project
+	_, err = h.projectCoreService.UpdateProject(ctx, projectID, func(project *models.Project) error {
+		updatedProject.ID = projectID
+		*project = updatedProject
+		return nil
+	})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update project",
@@ -250,28 +201,12 @@ func (h *ProjectHandlerSetup) UpdateProject(c *fiber.Ctx) error {
 
 	c.Locals("id", projectID)
 	c.Locals("message", "Project updated successfully")
-	c.Locals("token", token)
 
 	return h.GetProject(c)
 }
 
 // GetProject handles fetching a project by its ID
 func (h *ProjectHandlerSetup) GetProject(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	_, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
 
 	// Get the project ID from the route parameter
 	projectID := c.Params("id")
@@ -287,19 +222,16 @@ func (h *ProjectHandlerSetup) GetProject(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	// Fetch the project document from Firestore
-	doc, err := services.Firestore.Collection("projects").Doc(projectID).Get(ctx)
+	// Fetch the project
+	project, err := h.projectCoreService.GetProject(ctx, projectID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Project not found",
 		})
 	}
 
-	projectData := doc.Data()
-
 	// map project to client format
-	projectFrontend := mappers.MapProjectFirestoreToFrontend(projectData)
-	projectFrontend["id"] = projectID
+	projectFrontend := mappers.MapProjectGoToFrontend(*project)
 
 	// Get custom message if set, otherwise use default message
 	message := "Project fetched successfully"
@@ -315,22 +247,6 @@ func (h *ProjectHandlerSetup) GetProject(c *fiber.Ctx) error {
 
 // DeleteProject handles deleting a project by its ID
 func (h *ProjectHandlerSetup) DeleteProject(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
-
 	// Get the project ID from the route parameter
 	projectID := c.Params("id")
 	if projectID == "" {
@@ -341,38 +257,8 @@ func (h *ProjectHandlerSetup) DeleteProject(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	// Fetch the project document from Firestore
-	doc, err := services.Firestore.Collection("projects").Doc(projectID).Get(ctx)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Project not found",
-		})
-	}
-
-	// Map Firestore data to a Project struct
-	projectData := doc.Data()
-	project := mappers.MapProjectFirestoreToGo(projectData)
-
-	// Check if the user is the project owner
-	isOwner := project.OwnerID == uid
-
-	// Check if the user is a participant with the role "owner"
-	for _, participant := range project.Participants {
-		if participant.UserID == uid && participant.Role == "owner" {
-			isOwner = true
-			break
-		}
-	}
-
-	if !isOwner {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You do not have permission to delete this project",
-		})
-	}
-
-	// Delete the project from Firestore
-	_, err = services.Firestore.Collection("projects").Doc(projectID).Delete(ctx)
-	if err != nil {
+	// Delete the project
+	if err := h.projectCoreService.DeleteProject(ctx, projectID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete project",
 		})
@@ -387,19 +273,15 @@ func (h *ProjectHandlerSetup) DeleteProject(c *fiber.Ctx) error {
 func (h *ProjectHandlerSetup) GetAllPublicProjects(c *fiber.Ctx) error {
 	ctx := context.Background()
 
-	// Query Firestore for projects with collaboration_type == "public"
-	iter := services.Firestore.Collection("projects").Where("collaboration_type", "in", []interface{}{"public", "Public"}).Documents(ctx)
-	var projects []map[string]interface{}
+	// Fetch projects with collaboration_type == "public"
+	publicProjects, err := h.projectCoreService.ListPublicProjects(ctx)
+	if err != nil {
+		log.Printf("Failed to fetch public projects: %v", err)
+	}
 
-	for {
-		doc, err := iter.Next()
-		if err != nil {
-			break
-		}
-		projectData := doc.Data()
-		projectFrontend := mappers.MapProjectFirestoreToFrontend(projectData)
-		projectFrontend["id"] = doc.Ref.ID
-		projects = append(projects, projectFrontend)
+	var projects []map[string]interface{}
+	for _, project := range publicProjects {
+		projects = append(projects, mappers.MapProjectGoToFrontend(project))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -411,38 +293,20 @@ func (h *ProjectHandlerSetup) GetAllPublicProjects(c *fiber.Ctx) error {
 // GetOwnerProjects fetches all projects where the user is the owner
 func (h *ProjectHandlerSetup) GetOwnerProjects(c *fiber.Ctx) error {
 
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	ctx := context.Background()
 
 	var projects []map[string]interface{} = []map[string]interface{}{}
 
-	// Query Firestore for projects where owner_id == uid
-	iter := services.Firestore.Collection("projects").Where("owner_id", "==", uid).Documents(ctx)
+	// Fetch projects where owner_id == uid
+	ownerProjects, err := h.projectCoreService.ListOwnerProjects(ctx, uid)
+	if err != nil {
+		log.Printf("Failed to fetch projects owned by %s: %v", uid, err)
+	}
 
-	for {
-		doc, err := iter.Next()
-		if err != nil {
-			break
-		}
-		projectData := doc.Data()
-		projectFrontend := mappers.MapProjectFirestoreToFrontend(projectData)
-		projectFrontend["id"] = doc.Ref.ID
-		projects = append(projects, projectFrontend)
+	for _, project := range ownerProjects {
+		projects = append(projects, mappers.MapProjectGoToFrontend(project))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -452,55 +316,21 @@ func (h *ProjectHandlerSetup) GetOwnerProjects(c *fiber.Ctx) error {
 }
 
 func (h *ProjectHandlerSetup) GetParticipationProjects(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	ctx := context.Background()
 	var projects []map[string]interface{}
 
-	// Query Firestore for projects where user is a participant
-	query := services.Firestore.Collection("projects").Documents(ctx)
-	for {
-		doc, err := query.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch projects",
-			})
-		}
-
-		projectData := doc.Data()
-		projectData["id"] = doc.Ref.ID
-
-		project := mappers.MapProjectFirestoreToGo(projectData)
-
-		// Check if user is a participant (excluding owner)
-		isParticipant := false
-		for _, participant := range project.Participants {
-			if participant.UserID == uid && participant.Role != "owner" {
-				isParticipant = true
-				break
-			}
-		}
+	// Fetch projects where user is a participant (excluding owner)
+	participationProjects, err := h.projectCoreService.ListParticipationProjects(ctx, uid)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch projects",
+		})
+	}
 
-		// If user is a participant, add project to results
-		if isParticipant {
-			frontendProject := mappers.MapProjectFirestoreToFrontend(projectData)
-			projects = append(projects, frontendProject)
-		}
+	for _, project := range participationProjects {
+		projects = append(projects, mappers.MapProjectGoToFrontend(project))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
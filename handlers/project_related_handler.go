@@ -7,10 +7,10 @@ import (
 	"strings"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/models"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
@@ -36,20 +36,7 @@ func NewProjectHandler(projectService *services.ProjectService, userService *ser
 
 // JoinProject handles applying to join a project
 func (h *ProjectHandler) JoinProjectCollab(c *fiber.Ctx) error {
-	// Extract and validate token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Get project ID
 	projectID := c.Params("id")
@@ -71,7 +58,7 @@ func (h *ProjectHandler) JoinProjectCollab(c *fiber.Ctx) error {
 	}
 
 	// Call service method
-	err = h.projectService.JoinProject(c.Context(), projectID, uid, requestData.Message)
+	err := h.projectService.JoinProject(c.Context(), projectID, uid, requestData.Message)
 	if err != nil {
 		switch err.Error() {
 		case "project not found":
@@ -139,22 +126,6 @@ func (h *ProjectHandler) JoinProjectCollab(c *fiber.Ctx) error {
 }
 
 func (h *ProjectHandler) RemoveParticipantCollab(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
-
 	// Extract project ID and participant user ID from URL parameters
 	projectID := c.Params("id")
 	participantID := c.Params("participantId")
@@ -166,43 +137,21 @@ func (h *ProjectHandler) RemoveParticipantCollab(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	// Fetch the project from Firestore
-	doc, err := services.Firestore.Collection("projects").Doc(projectID).Get(ctx)
+	// Fetch the project
+	project, err := h.projectService.GetProjectByID(ctx, projectID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Project not found",
 		})
 	}
 
-	projectData := doc.Data()
-	project := mappers.MapProjectFirestoreToGo(projectData)
-
-	// Check if the user is the project owner or an owner-level participant
-	isOwner := project.OwnerID == uid
-	for _, participant := range project.Participants {
-		if participant.UserID == uid && participant.Role == "owner" {
-			isOwner = true
-			break
-		}
-	}
-
-	if !isOwner {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You do not have permission to remove participants",
-		})
-	}
-
 	// Check if the participant exists in the project
 	participantExists := false
-	var updatedParticipants []map[string]interface{}
-
-	for _, participant := range projectData["participants"].([]interface{}) {
-		participantMap := participant.(map[string]interface{})
-		if participantMap["user_id"] == participantID {
+	for _, participant := range project.Participants {
+		if participant.UserID == participantID {
 			participantExists = true
-			continue // Skip the participant to remove them
+			break
 		}
-		updatedParticipants = append(updatedParticipants, participantMap)
 	}
 
 	if !participantExists {
@@ -211,9 +160,16 @@ func (h *ProjectHandler) RemoveParticipantCollab(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update the participants list in Firestore
-	_, err = services.Firestore.Collection("projects").Doc(projectID).Update(ctx, []firestore.Update{
-		{Path: "participants", Value: updatedParticipants},
+	// Update the participants list
+	_, err = h.projectService.UpdateProject(ctx, projectID, func(project *models.Project) error {
+		var updatedParticipants []models.Participant
+		for _, participant := range project.Participants {
+			if participant.UserID != participantID {
+				updatedParticipants = append(updatedParticipants, participant)
+			}
+		}
+		project.Participants = updatedParticipants
+		return nil
 	})
 
 	if err != nil {
@@ -229,22 +185,6 @@ func (h *ProjectHandler) RemoveParticipantCollab(c *fiber.Ctx) error {
 
 // HandleJoinRequest handles accepting or rejecting join requests
 func (h *ProjectHandler) AcceptRejectJoinRequestCollab(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
-
 	projectID := c.Params("id")
 	userID := c.Params("uid")
 	if projectID == "" || userID == "" {
@@ -255,32 +195,14 @@ func (h *ProjectHandler) AcceptRejectJoinRequestCollab(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	// Fetch the project from Firestore
-	doc, err := services.Firestore.Collection("projects").Doc(projectID).Get(ctx)
+	// Fetch the project
+	project, err := h.projectService.GetProjectByID(ctx, projectID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Project not found",
 		})
 	}
 
-	projectData := doc.Data()
-	project := mappers.MapProjectFirestoreToGo(projectData)
-
-	// Check if the user is the project owner or an owner participant
-	isOwner := project.OwnerID == uid
-	for _, participant := range project.Participants {
-		if participant.UserID == uid && participant.Role == "owner" {
-			isOwner = true
-			break
-		}
-	}
-
-	if !isOwner {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You do not have permission to handle join requests",
-		})
-	}
-
 	// Parse the request payload for action ("accept" or "reject") and user details
 	var requestData struct {
 		Action         string `json:"action"`
@@ -296,75 +218,12 @@ func (h *ProjectHandler) AcceptRejectJoinRequestCollab(c *fiber.Ctx) error {
 		})
 	}
 
-	// Ensure join_requests is a valid slice
-	joinRequests, ok := projectData["join_requests"].([]interface{})
-	if !ok {
-		log.Printf("join_requests field is of unexpected type: %+v", projectData["join_requests"])
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Join requests data is invalid",
-		})
-	}
-
-	// Handle the join request based on action
-	var updatedJoinRequests []map[string]interface{}
+	// Make sure there is a pending join request for the user
 	participantExists := false
-
-	for _, jr := range joinRequests {
-		jrMap, ok := jr.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		if jrMap["user_id"] == userID {
+	for _, jr := range project.JoinRequests {
+		if jr.UserID == userID {
 			participantExists = true
-			if requestData.Action == "accept" {
-				// Add the user as a participant with the provided attributes
-				newParticipant := models.Participant{
-					UserID:         userID,
-					Role:           requestData.Role,
-					Username:       requestData.Username,
-					Email:          requestData.Email,
-					ProfilePicture: requestData.ProfilePicture,
-					JoinedAt:       time.Now(),
-				}
-				_, err := services.Firestore.Collection("projects").Doc(projectID).Update(ctx, []firestore.Update{
-					{Path: "participants", Value: firestore.ArrayUnion(mappers.MapParticipantGoToFirestore(newParticipant))},
-				})
-				if err != nil {
-					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-						"error": "Failed to add participant",
-					})
-				}
-
-				// Add the participant to the group chat using projectID
-				// participantsToAdd := []models.Participant{newParticipant}
-				// if err := services.AddParticipantsToGroupChat(ctx, "", projectID, uid, participantsToAdd); err != nil {
-				// 	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				// 		"error": fmt.Sprintf("Failed to add participant(s) to group chat: %v", err),
-				// 	})
-				// }
-				// Send notification email for accepted request
-				if err := SendJoinRequestAcceptedEmail(requestData.Email, requestData.Username, project.Title); err != nil {
-					log.Printf("Failed to send acceptance email: %v", err)
-				}
-			} else if requestData.Action == "reject" {
-				// Add the user ID to the RejectedParticipants list
-				_, err := services.Firestore.Collection("projects").Doc(projectID).Update(ctx, []firestore.Update{
-					{Path: "rejected_participants", Value: firestore.ArrayUnion(userID)},
-				})
-				if err != nil {
-					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-						"error": "Failed to add user to rejected participants",
-					})
-				}
-				// Send notification email for rejected request
-				if err := SendJoinRequestRejectedEmail(requestData.Email, requestData.Username, project.Title); err != nil {
-					log.Printf("Failed to send rejection email: %v", err)
-				}
-			}
-			// Skip adding this join request to updatedJoinRequests to remove it
-		} else {
-			updatedJoinRequests = append(updatedJoinRequests, jrMap)
+			break
 		}
 	}
 
@@ -374,9 +233,71 @@ func (h *ProjectHandler) AcceptRejectJoinRequestCollab(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update the join requests in Firestore to remove the processed request
-	_, err = services.Firestore.Collection("projects").Doc(projectID).Update(ctx, []firestore.Update{
-		{Path: "join_requests", Value: updatedJoinRequests},
+	// Handle the join request based on action
+	switch requestData.Action {
+	case "accept":
+		// Add the user as a participant with the provided attributes
+		newParticipant := models.Participant{
+			UserID:         userID,
+			Role:           requestData.Role,
+			Username:       requestData.Username,
+			Email:          requestData.Email,
+			ProfilePicture: requestData.ProfilePicture,
+			JoinedAt:       time.Now(),
+		}
+		_, err := h.projectService.UpdateProject(ctx, projectID, func(project *models.Project) error {
+			project.Participants = append(project.Participants, newParticipant)
+			return nil
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to add participant",
+			})
+		}
+
+		// Add the participant to the group chat using projectID
+		// participantsToAdd := []models.Participant{newParticipant}
+		// if err := services.AddParticipantsToGroupChat(ctx, "", projectID, uid, participantsToAdd); err != nil {
+		// 	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		// 		"error": fmt.Sprintf("Failed to add participant(s) to group chat: %v", err),
+		// 	})
+		// }
+		// Send notification email for accepted request
+		if err := SendJoinRequestAcceptedEmail(requestData.Email, requestData.Username, project.Title); err != nil {
+			log.Printf("Failed to send acceptance email: %v", err)
+		}
+	case "reject":
+		// Add the user ID to the RejectedParticipants list
+		_, err := h.projectService.UpdateProject(ctx, projectID, func(project *models.Project) error {
+			for _, rejectedUID := range project.RejectedParticipants {
+				if rejectedUID == userID {
+					return nil
+				}
+			}
+			project.RejectedParticipants = append(project.RejectedParticipants, userID)
+			return nil
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to add user to rejected participants",
+			})
+		}
+		// Send notification email for rejected request
+		if err := SendJoinRequestRejectedEmail(requestData.Email, requestData.Username, project.Title); err != nil {
+			log.Printf("Failed to send rejection email: %v", err)
+		}
+	}
+
+	// Remove the processed join request
+	_, err = h.projectService.UpdateProject(ctx, projectID, func(project *models.Project) error {
+		var updatedJoinRequests []models.JoinRequest
+		for _, jr := range project.JoinRequests {
+			if jr.UserID != userID {
+				updatedJoinRequests = append(updatedJoinRequests, jr)
+			}
+		}
+		project.JoinRequests = updatedJoinRequests
+		return nil
 	})
 
 	if err != nil {
@@ -406,21 +327,7 @@ func (h *ProjectHandler) AcceptRejectJoinRequestCollab(c *fiber.Ctx) error {
 
 // InviteUserCollab handles inviting a user to a project
 func (h *ProjectHandler) InviteUserCollab(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	projectID := c.Params("id")
 	if projectID == "" {
@@ -431,32 +338,14 @@ func (h *ProjectHandler) InviteUserCollab(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	// Fetch the project from Firestore
-	doc, err := services.Firestore.Collection("projects").Doc(projectID).Get(ctx)
+	// Fetch the project
+	project, err := h.projectService.GetProjectByID(ctx, projectID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Project not found",
 		})
 	}
 
-	projectData := doc.Data()
-	project := mappers.MapProjectFirestoreToGo(projectData)
-
-	// Check if the user is the project owner or an owner participant
-	isOwner := project.OwnerID == uid
-	for _, participant := range project.Participants {
-		if participant.UserID == uid && participant.Role == "owner" {
-			isOwner = true
-			break
-		}
-	}
-
-	if !isOwner {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You do not have permission to invite users to this project",
-		})
-	}
-
 	// Parse the request payload for the user to invite
 	var requestData struct {
 		EmailOrUsername string `json:"emailOrUsername"`
@@ -493,23 +382,9 @@ func (h *ProjectHandler) InviteUserCollab(c *fiber.Ctx) error {
 		}
 	}
 
-	// Initialize invited_users if nil
-	invitedUsersData, ok := projectData["invited_users"].([]interface{})
-	if !ok || invitedUsersData == nil {
-		fmt.Println("invited_users is nil or not a []interface{}. Initializing as empty slice.")
-		invitedUsersData = []interface{}{}
-	}
-
 	// Check if the user is already in the invited_users list
-	for _, existingUser := range invitedUsersData {
-		existingUserMap, ok := existingUser.(map[string]interface{})
-		if !ok {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Invalid format for invited user",
-			})
-		}
-
-		if existingUserMap["user_id"] == user.UID {
+	for _, invited := range project.InvitedUsers {
+		if invited.UserID == user.UID {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": fmt.Sprintf("%s has already been invited to this project", user.Username),
 			})
@@ -517,17 +392,18 @@ func (h *ProjectHandler) InviteUserCollab(c *fiber.Ctx) error {
 	}
 
 	// Add the user to invited_users
-	invite := map[string]interface{}{
-		"user_id":         user.UID,
-		"username":        user.Username,
-		"email":           user.Email,
-		"profile_picture": user.ProfilePicture,
-		"role":            requestData.Role,
-		"joined_at":       time.Now().Format(time.RFC3339),
-	}
-
-	_, err = services.Firestore.Collection("projects").Doc(projectID).Update(ctx, []firestore.Update{
-		{Path: "invited_users", Value: firestore.ArrayUnion(invite)},
+	invitedUser := models.InvitedUser{
+		UserID:         user.UID,
+		Username:       user.Username,
+		Email:          user.Email,
+		ProfilePicture: user.ProfilePicture,
+		Role:           requestData.Role,
+		JoinedAt:       time.Now(),
+	}
+
+	_, err = h.projectService.UpdateProject(ctx, projectID, func(project *models.Project) error {
+		project.InvitedUsers = append(project.InvitedUsers, invitedUser)
+		return nil
 	})
 
 	if err != nil {
@@ -537,7 +413,7 @@ func (h *ProjectHandler) InviteUserCollab(c *fiber.Ctx) error {
 	}
 
 	// Send invitation email
-	projectName := projectData["title"].(string)
+	projectName := project.Title
 	ownernerName, err := h.userService.GetUsernameByUID(uid)
 	if err != nil {
 		log.Printf("Error fetching project owner's username: %v", err)
@@ -548,28 +424,21 @@ func (h *ProjectHandler) InviteUserCollab(c *fiber.Ctx) error {
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message":     "User invited successfully",
-		"invitedUser": invite,
+		"message": "User invited successfully",
+		"invitedUser": fiber.Map{
+			"user_id":         invitedUser.UserID,
+			"username":        invitedUser.Username,
+			"email":           invitedUser.Email,
+			"profile_picture": invitedUser.ProfilePicture,
+			"role":            invitedUser.Role,
+			"joined_at":       invitedUser.JoinedAt.Format(time.RFC3339),
+		},
 	})
 }
 
 // AddTask handles adding a task to a project
 func (h *ProjectHandler) AddTask(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	projectID := c.Params("id")
 	if projectID == "" {
@@ -580,17 +449,14 @@ func (h *ProjectHandler) AddTask(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	// Fetch the project from Firestore
-	doc, err := services.Firestore.Collection("projects").Doc(projectID).Get(ctx)
+	// Fetch the project
+	project, err := h.projectService.GetProjectByID(ctx, projectID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Project not found",
 		})
 	}
 
-	projectData := doc.Data()
-	project := mappers.MapProjectFirestoreToGo(projectData)
-
 	// Check if the user is the project owner or a participant
 	isAuthorized := project.OwnerID == uid
 	for _, participant := range project.Participants {
@@ -626,9 +492,10 @@ func (h *ProjectHandler) AddTask(c *fiber.Ctx) error {
 	task.CreatedAt = time.Now()
 	task.UpdatedAt = time.Now()
 
-	// Add the task to Firestore
-	_, err = services.Firestore.Collection("projects").Doc(projectID).Update(ctx, []firestore.Update{
-		{Path: "tasks", Value: firestore.ArrayUnion(mappers.MapTaskGoToFirestore(task))},
+	// Add the task to the project
+	_, err = h.projectService.UpdateProject(ctx, projectID, func(project *models.Project) error {
+		project.Tasks = append(project.Tasks, task)
+		return nil
 	})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -644,21 +511,7 @@ func (h *ProjectHandler) AddTask(c *fiber.Ctx) error {
 
 // UpdateTask handles updating task details
 func (h *ProjectHandler) UpdateTask(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	projectID := c.Params("id")
 	taskID := c.Params("taskID")
@@ -670,17 +523,14 @@ func (h *ProjectHandler) UpdateTask(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	// Fetch the project from Firestore
-	doc, err := services.Firestore.Collection("projects").Doc(projectID).Get(ctx)
+	// Fetch the project
+	project, err := h.projectService.GetProjectByID(ctx, projectID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Project not found",
 		})
 	}
 
-	projectData := doc.Data()
-	project := mappers.MapProjectFirestoreToGo(projectData)
-
 	// Check if the user is authorized (project owner or participant)
 	isAuthorized := project.OwnerID == uid
 	for _, participant := range project.Participants {
@@ -704,28 +554,35 @@ func (h *ProjectHandler) UpdateTask(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update the specific task
-	taskUpdated := false
-	for i, task := range project.Tasks {
+	// Make sure the task exists
+	taskExists := false
+	for _, task := range project.Tasks {
 		if task.ID == taskID {
-			updatedTask := mappers.MapTaskFrontendToGo(updatedTaskData)
-			updatedTask.ID = task.ID
-			updatedTask.CreatedAt = task.CreatedAt
-			updatedTask.UpdatedAt = time.Now()
-			project.Tasks[i] = updatedTask
-			taskUpdated = true
+			taskExists = true
 			break
 		}
 	}
 
-	if !taskUpdated {
+	if !taskExists {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Task not found",
 		})
 	}
 
-	// Save the updated project back to Firestore
-	_, err = services.Firestore.Collection("projects").Doc(projectID).Set(ctx, mappers.MapProjectGoToFirestore(project))
+	// Update the specific task
+	updated, err := h.projectService.UpdateProject(ctx, projectID, func(project *models.Project) error {
+		for i, task := range project.Tasks {
+			if task.ID == taskID {
+				updatedTask := mappers.MapTaskFrontendToGo(updatedTaskData)
+				updatedTask.ID = task.ID
+				updatedTask.CreatedAt = task.CreatedAt
+				updatedTask.UpdatedAt = time.Now()
+				project.Tasks[i] = updatedTask
+				break
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update task",
@@ -734,7 +591,7 @@ func (h *ProjectHandler) UpdateTask(c *fiber.Ctx) error {
 
 	// Map each task to frontend format
 	var frontendTasks []map[string]interface{}
-	for _, task := range project.Tasks {
+	for _, task := range updated.Tasks {
 		frontendTasks = append(frontendTasks, mappers.MapTaskGoToFrontend(task))
 	}
 
@@ -747,21 +604,7 @@ func (h *ProjectHandler) UpdateTask(c *fiber.Ctx) error {
 
 // DeleteTask handles deleting a task from a project
 func (h *ProjectHandler) DeleteTask(c *fiber.Ctx) error {
-	// Extract the Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	projectID := c.Params("id")
 	taskID := c.Params("taskID")
@@ -773,17 +616,14 @@ func (h *ProjectHandler) DeleteTask(c *fiber.Ctx) error {
 
 	ctx := context.Background()
 
-	// Fetch the project from Firestore
-	doc, err := services.Firestore.Collection("projects").Doc(projectID).Get(ctx)
+	// Fetch the project
+	project, err := h.projectService.GetProjectByID(ctx, projectID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Project not found",
 		})
 	}
 
-	projectData := doc.Data()
-	project := mappers.MapProjectFirestoreToGo(projectData)
-
 	// Check if the user is authorized
 	isAuthorized := project.OwnerID == uid
 	for _, participant := range project.Participants {
@@ -800,20 +640,16 @@ func (h *ProjectHandler) DeleteTask(c *fiber.Ctx) error {
 	}
 
 	// Filter out the task to be deleted
-	var updatedTasks []map[string]interface{}
-	for _, task := range project.Tasks {
-		if task.ID != taskID {
-			updatedTasks = append(updatedTasks, mappers.MapTaskGoToFirestore(task))
+	_, err = h.projectService.UpdateProject(ctx, projectID, func(project *models.Project) error {
+		var updatedTasks []models.Task
+		for _, task := range project.Tasks {
+			if task.ID != taskID {
+				updatedTasks = append(updatedTasks, task)
+			}
 		}
-	}
-
-	// Debug: print the updated tasks before updating Firestore
-	fmt.Printf("Updated Tasks: %+v\n", updatedTasks)
-
-	// Update Firestore with the new tasks list
-	_, err = services.Firestore.Collection("projects").Doc(projectID).Set(ctx, map[string]interface{}{
-		"tasks": updatedTasks,
-	}, firestore.MergeAll)
+		project.Tasks = updatedTasks
+		return nil
+	})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete task",
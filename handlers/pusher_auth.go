@@ -1,30 +1,32 @@
 package handlers
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
-	"github.com/rogerjeasy/go-letusconnect/utils"
 )
 
-// PusherAuth handles Pusher authentication for private or encrypted channels
-func PusherAuth(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required. Please log in",
-		})
-	}
+// PusherAuthHandler signs the subscriptions of the users to private Pusher channels
+type PusherAuthHandler struct {
+	authorizationService *services.AuthorizationService
+}
 
-	_, err := utils.ValidateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token. Please log in again",
+func NewPusherAuthHandler(authorizationService *services.AuthorizationService) *PusherAuthHandler {
+	return &PusherAuthHandler{authorizationService: authorizationService}
+}
+
+// PusherAuth handles Pusher authentication for private or encrypted channels
+func (h *PusherAuthHandler) PusherAuth(c *fiber.Ctx) error {
+	if services.PusherClient == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Pusher is not configured",
 		})
 	}
 
@@ -37,6 +39,27 @@ func PusherAuth(c *fiber.Ctx) error {
 		})
 	}
 
+	// Only the private channels the hub publishes to are signed
+	channel, ok := services.RealtimeChannelFromPusher(channelName)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": services.ErrChannelForbidden.Error(),
+		})
+	}
+
+	// Channels are only signed for the users allowed to subscribe to them over WebSocket
+	err := h.authorizationService.AuthorizeChannel(context.Background(), middleware.CurrentUID(c), channel)
+	if errors.Is(err, services.ErrChannelForbidden) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Construct the string to sign
 	stringToSign := fmt.Sprintf("%s:%s", socketID, channelName)
 	signature := hmac.New(sha256.New, []byte(services.PusherClient.Secret))
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+type RoleHandler struct {
+	authorizationService *services.AuthorizationService
+}
+
+func NewRoleHandler(authorizationService *services.AuthorizationService) *RoleHandler {
+	return &RoleHandler{authorizationService: authorizationService}
+}
+
+// ListRoles returns the roles that can be granted
+func (h *RoleHandler) ListRoles(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"roles": services.Roles(),
+	})
+}
+
+// GetUserRoles returns the roles of a user
+func (h *RoleHandler) GetUserRoles(c *fiber.Ctx) error {
+	uid := c.Params("uid")
+
+	roles, err := h.authorizationService.GetRoles(context.Background(), uid)
+	if err != nil {
+		return roleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"uid":   uid,
+		"roles": roles,
+	})
+}
+
+// GrantRole adds a role to a user
+func (h *RoleHandler) GrantRole(c *fiber.Ctx) error {
+	uid := c.Params("uid")
+
+	var payload struct {
+		Role string `json:"role"`
+	}
+	if err := c.BodyParser(&payload); err != nil || strings.TrimSpace(payload.Role) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Role is required",
+		})
+	}
+
+	middleware.SetAuditDetail(c, "role "+strings.TrimSpace(payload.Role))
+	roles, err := h.authorizationService.GrantRole(context.Background(), uid, strings.TrimSpace(payload.Role))
+	if err != nil {
+		return roleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Role granted successfully",
+		"uid":     uid,
+		"roles":   roles,
+	})
+}
+
+// RevokeRole removes a role from a user
+func (h *RoleHandler) RevokeRole(c *fiber.Ctx) error {
+	uid := c.Params("uid")
+	middleware.SetAuditDetail(c, "role "+c.Params("role"))
+
+	roles, err := h.authorizationService.RevokeRole(context.Background(), middleware.CurrentUID(c), uid, c.Params("role"))
+	if err != nil {
+		return roleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Role revoked successfully",
+		"uid":     uid,
+		"roles":   roles,
+	})
+}
+
+// roleError maps the errors of the role operations to HTTP responses
+func roleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	case errors.Is(err, services.ErrUnknownRole):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+			"roles": services.Roles(),
+		})
+	case errors.Is(err, services.ErrBaseRole), errors.Is(err, services.ErrSelfRevocation):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/models"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
@@ -24,12 +25,7 @@ func NewTestimonialHandler(testimonialService *services.TestimonialService, user
 // CreateTestimonial handles the creation of a new testimonial
 func (h *TestimonialHandler) CreateTestimonial(c *fiber.Ctx) error {
 
-	userID, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	var testimonial models.Testimonial
 	if err := c.BodyParser(&testimonial); err != nil {
@@ -55,12 +51,7 @@ func (h *TestimonialHandler) CreateTestimonial(c *fiber.Ctx) error {
 // CreateAlumniTestimonial handles the creation of a new alumni testimonial
 func (h *TestimonialHandler) CreateAlumniTestimonial(c *fiber.Ctx) error {
 
-	userID, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	var testimonial models.AlumniTestimonial
 	if err := c.BodyParser(&testimonial); err != nil {
@@ -86,12 +77,7 @@ func (h *TestimonialHandler) CreateAlumniTestimonial(c *fiber.Ctx) error {
 // CreateStudentSpotlight handles the creation of a new student spotlight
 func (h *TestimonialHandler) CreateStudentSpotlight(c *fiber.Ctx) error {
 
-	userID, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	var spotlight models.StudentSpotlight
 	if err := c.BodyParser(&spotlight); err != nil {
@@ -183,12 +169,7 @@ func (h *TestimonialHandler) ListTestimonials(c *fiber.Ctx) error {
 // PublishTestimonial handles publishing a testimonial
 func (h *TestimonialHandler) PublishTestimonial(c *fiber.Ctx) error {
 
-	userID, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	testimonialID := c.Params("id")
 	if testimonialID == "" {
@@ -227,13 +208,6 @@ func (h *TestimonialHandler) PublishTestimonial(c *fiber.Ctx) error {
 // AddLike handles adding a like to a testimonial
 func (h *TestimonialHandler) AddLike(c *fiber.Ctx) error {
 
-	_, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
-
 	testimonialID := c.Params("id")
 	if testimonialID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -242,7 +216,7 @@ func (h *TestimonialHandler) AddLike(c *fiber.Ctx) error {
 	}
 
 	ctx := context.Background()
-	err = h.testimonialService.AddLike(ctx, testimonialID)
+	err := h.testimonialService.AddLike(ctx, testimonialID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -257,12 +231,7 @@ func (h *TestimonialHandler) AddLike(c *fiber.Ctx) error {
 // DeleteTestimonial handles deleting a testimonial
 func (h *TestimonialHandler) DeleteTestimonial(c *fiber.Ctx) error {
 
-	userID, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	userID := middleware.CurrentUID(c)
 
 	testimonialID := c.Params("id")
 	if testimonialID == "" {
@@ -272,7 +241,7 @@ func (h *TestimonialHandler) DeleteTestimonial(c *fiber.Ctx) error {
 	}
 
 	ctx := context.Background()
-	err = h.testimonialService.DeleteTestimonial(ctx, testimonialID, userID)
+	err := h.testimonialService.DeleteTestimonial(ctx, testimonialID, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *services.TwoFactorService
+	authService      *services.AuthService
+}
+
+func NewTwoFactorHandler(twoFactorService *services.TwoFactorService, authService *services.AuthService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+		authService:      authService,
+	}
+}
+
+// twoFactorCode reads the "code" of the request body
+func twoFactorCode(c *fiber.Ctx) (string, bool) {
+	var payload struct {
+		Code string `json:"code"`
+	}
+	if err := c.BodyParser(&payload); err != nil || strings.TrimSpace(payload.Code) == "" {
+		return "", false
+	}
+	return payload.Code, true
+}
+
+// GetStatus tells whether the caller uses a second factor
+func (h *TwoFactorHandler) GetStatus(c *fiber.Ctx) error {
+	user, err := h.authService.GetUserByUID(middleware.CurrentUID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve user data",
+		})
+	}
+
+	status, err := h.twoFactorService.Status(context.Background(), user)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"enabled":           status.Enabled,
+		"required":          status.Required,
+		"recoveryCodesLeft": status.RecoveryCodesLeft,
+	})
+}
+
+// BeginEnrollment returns a new secret and the otpauth:// URI to show as a QR code
+func (h *TwoFactorHandler) BeginEnrollment(c *fiber.Ctx) error {
+	user, err := h.authService.GetUserByUID(middleware.CurrentUID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve user data",
+		})
+	}
+
+	secret, uri, err := h.twoFactorService.BeginEnrollment(context.Background(), user)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":         "Scan the QR code with your authenticator app, then confirm with a code",
+		"secret":          secret,
+		"provisioningUri": uri,
+	})
+}
+
+// ConfirmEnrollment enables the second factor and returns the recovery codes
+func (h *TwoFactorHandler) ConfirmEnrollment(c *fiber.Ctx) error {
+	code, ok := twoFactorCode(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Code is required",
+		})
+	}
+
+	recoveryCodes, err := h.twoFactorService.ConfirmEnrollment(context.Background(), middleware.CurrentUID(c), code)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":       "Two-factor authentication enabled. Keep the recovery codes somewhere safe, they are shown only once",
+		"recoveryCodes": recoveryCodes,
+	})
+}
+
+// Disable turns the second factor off
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	code, ok := twoFactorCode(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Code is required",
+		})
+	}
+
+	user, err := h.authService.GetUserByUID(middleware.CurrentUID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve user data",
+		})
+	}
+
+	if err := h.twoFactorService.Disable(context.Background(), user, code); err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the caller
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	code, ok := twoFactorCode(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Code is required",
+		})
+	}
+
+	recoveryCodes, err := h.twoFactorService.RegenerateRecoveryCodes(context.Background(), middleware.CurrentUID(c), code)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":       "New recovery codes generated, the previous ones no longer work",
+		"recoveryCodes": recoveryCodes,
+	})
+}
+
+// ResetTwoFactor removes the second factor of a user who lost it
+func (h *TwoFactorHandler) ResetTwoFactor(c *fiber.Ctx) error {
+	if err := h.twoFactorService.Reset(context.Background(), c.Params("uid")); err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Two-factor authentication reset successfully",
+	})
+}
+
+// twoFactorError maps the errors of the two-factor operations to HTTP responses
+func twoFactorError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrTwoFactorEnforced):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrTwoFactorNotEnabled):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled), errors.Is(err, services.ErrNoPendingEnrollment):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+}
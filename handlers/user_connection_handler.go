@@ -3,10 +3,10 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -21,12 +21,7 @@ func NewUserConnectionHandler(connectionService *services.UserConnectionService)
 }
 
 func (h *UserConnectionHandler) GetUserConnections(c *fiber.Ctx) error {
-	uid, err := validateToken(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	connections, err := h.connectionService.GetUserConnections(context.Background(), uid)
 	if err != nil {
@@ -79,12 +74,7 @@ func (h *UserConnectionHandler) GetUserConnectionsByUID(c *fiber.Ctx) error {
 }
 
 func (h *UserConnectionHandler) SendConnectionRequest(c *fiber.Ctx) error {
-	fromUID, err := validateToken(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	fromUID := middleware.CurrentUID(c)
 
 	// Parse frontend format
 	var frontendRequest map[string]interface{}
@@ -97,7 +87,7 @@ func (h *UserConnectionHandler) SendConnectionRequest(c *fiber.Ctx) error {
 	// Convert to Go struct format
 	request := mappers.MapConnectionRequestFrontendToGo(frontendRequest)
 
-	err = h.connectionService.SendConnectionRequest(context.Background(), fromUID, request.ToUID, request.Message)
+	err := h.connectionService.SendConnectionRequest(context.Background(), fromUID, request.ToUID, request.Message)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to send connection request",
@@ -110,12 +100,7 @@ func (h *UserConnectionHandler) SendConnectionRequest(c *fiber.Ctx) error {
 }
 
 func (h *UserConnectionHandler) AcceptConnectionRequest(c *fiber.Ctx) error {
-	toUID, err := validateToken(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	toUID := middleware.CurrentUID(c)
 
 	fromUID := c.Params("fromUid")
 	if fromUID == "" {
@@ -124,7 +109,7 @@ func (h *UserConnectionHandler) AcceptConnectionRequest(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.connectionService.AcceptConnectionRequest(context.Background(), fromUID, toUID)
+	err := h.connectionService.AcceptConnectionRequest(context.Background(), fromUID, toUID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to accept connection request",
@@ -137,12 +122,7 @@ func (h *UserConnectionHandler) AcceptConnectionRequest(c *fiber.Ctx) error {
 }
 
 func (h *UserConnectionHandler) RejectConnectionRequest(c *fiber.Ctx) error {
-	toUID, err := validateToken(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	toUID := middleware.CurrentUID(c)
 
 	fromUID := c.Params("fromUid")
 	if fromUID == "" {
@@ -151,7 +131,7 @@ func (h *UserConnectionHandler) RejectConnectionRequest(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.connectionService.RejectConnectionRequest(context.Background(), fromUID, toUID)
+	err := h.connectionService.RejectConnectionRequest(context.Background(), fromUID, toUID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to reject connection request",
@@ -164,12 +144,7 @@ func (h *UserConnectionHandler) RejectConnectionRequest(c *fiber.Ctx) error {
 }
 
 func (h *UserConnectionHandler) RemoveConnection(c *fiber.Ctx) error {
-	uid1, err := validateToken(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid1 := middleware.CurrentUID(c)
 
 	uid2 := c.Params("uid")
 	if uid2 == "" {
@@ -178,7 +153,7 @@ func (h *UserConnectionHandler) RemoveConnection(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.connectionService.RemoveConnection(context.Background(), uid1, uid2)
+	err := h.connectionService.RemoveConnection(context.Background(), uid1, uid2)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to remove connection",
@@ -191,12 +166,7 @@ func (h *UserConnectionHandler) RemoveConnection(c *fiber.Ctx) error {
 }
 
 func (h *UserConnectionHandler) GetConnectionRequests(c *fiber.Ctx) error {
-	uid, err := validateToken(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	connections, err := h.connectionService.GetUserConnections(context.Background(), uid)
 	if err != nil {
@@ -230,12 +200,7 @@ func (h *UserConnectionHandler) GetConnectionRequests(c *fiber.Ctx) error {
 }
 
 func (h *UserConnectionHandler) UpdateRequestStatus(c *fiber.Ctx) error {
-	toUID, err := validateToken(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	toUID := middleware.CurrentUID(c)
 
 	requestID := c.Params("requestId")
 	if requestID == "" {
@@ -255,6 +220,7 @@ func (h *UserConnectionHandler) UpdateRequestStatus(c *fiber.Ctx) error {
 	}
 
 	// Validate status
+	var err error
 	switch updateRequest.Status {
 	case "accepted":
 		err = h.connectionService.AcceptConnectionRequest(context.Background(), requestID, toUID)
@@ -278,12 +244,7 @@ func (h *UserConnectionHandler) UpdateRequestStatus(c *fiber.Ctx) error {
 }
 
 func (h *UserConnectionHandler) CancelSentRequest(c *fiber.Ctx) error {
-	uid, err := validateToken(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	toUID := c.Params("toUid")
 	if toUID == "" {
@@ -292,7 +253,7 @@ func (h *UserConnectionHandler) CancelSentRequest(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.connectionService.CancelSentRequest(context.Background(), uid, toUID)
+	err := h.connectionService.CancelSentRequest(context.Background(), uid, toUID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to cancel connection request",
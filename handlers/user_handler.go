@@ -8,6 +8,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -24,21 +25,7 @@ func NewUserHandler(userService *services.UserService, containerService *service
 }
 
 func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
-	// Extract Authorization token
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	// Parse request body into a map
 	var requestData map[string]interface{}
@@ -124,17 +111,9 @@ func (h *UserHandler) GetUser(c *fiber.Ctx) error {
 	}
 	userGoStruct := mappers.MapBackendToUser(dbUser)
 	if userGoStruct.IsPrivate {
-		token := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
-		if token == "" {
-			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-				"error":     "This account is private",
-				"isPrivate": true,
-			})
-		}
-
-		// Validate token and check if requester is connected to user
-		requesterUID, err := validateToken(token)
-		if err != nil || !isConnected(ctx, requesterUID, uid) {
+		// Only signed-in users connected to the owner can see a private account
+		requesterUID := middleware.CurrentUID(c)
+		if requesterUID == "" || !h.isConnected(ctx, requesterUID, uid) {
 			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
 				"error":     "This account is private",
 				"isPrivate": true,
@@ -149,25 +128,10 @@ func (h *UserHandler) GetUser(c *fiber.Ctx) error {
 	})
 }
 
-func isConnected(ctx context.Context, requesterUID, targetUID string) bool {
-	connectionsRef := services.Firestore.Collection("connections")
-	query := connectionsRef.Where("status", "==", "accepted").
-		Where("users", "array-contains", requesterUID)
-
-	docs, err := query.Documents(ctx).GetAll()
-	if err != nil {
-		return false
-	}
-
-	for _, doc := range docs {
-		users := doc.Data()["users"].([]interface{})
-		for _, user := range users {
-			if user.(string) == targetUID {
-				return true
-			}
-		}
-	}
-	return false
+// isConnected reports whether the requester has an active connection with the target user
+func (h *UserHandler) isConnected(ctx context.Context, requesterUID, targetUID string) bool {
+	connected, err := h.containerService.ConnectionService.IsConnected(ctx, requesterUID, targetUID)
+	return err == nil && connected
 }
 
 // GetAllUsers retrieves all users from the Firestore "users" collection
@@ -189,20 +153,7 @@ func (h *UserHandler) GetAllUsers(c *fiber.Ctx) error {
 }
 
 func (h *UserHandler) GetProfileCompletion(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization token is required",
-		})
-	}
-
-	// Validate token and get UID
-	uid, err := validateToken(strings.TrimPrefix(token, "Bearer "))
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
-	}
+	uid := middleware.CurrentUID(c)
 
 	userDetails, err := h.userService.GetUserByUID(uid)
 	if err != nil {
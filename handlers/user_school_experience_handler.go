@@ -3,6 +3,7 @@ package handlers
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -28,10 +29,7 @@ func NewUserSchoolExperienceHandler(service *services.UserSchoolExperienceServic
 }
 
 func (h *UserSchoolExperienceHandler) CreateSchoolExperience(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	experience, err := h.schoolExperienceService.CreateSchoolExperience(c.Context(), uid)
 	if err != nil {
@@ -47,10 +45,7 @@ func (h *UserSchoolExperienceHandler) CreateSchoolExperience(c *fiber.Ctx) error
 }
 
 func (h *UserSchoolExperienceHandler) GetSchoolExperience(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	experience, err := h.schoolExperienceService.GetSchoolExperience(c.Context(), uid)
 	if err != nil {
@@ -66,10 +61,7 @@ func (h *UserSchoolExperienceHandler) GetSchoolExperience(c *fiber.Ctx) error {
 }
 
 func (h *UserSchoolExperienceHandler) UpdateUniversity(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	universityID := c.Params("universityID")
 	if universityID == "" {
@@ -98,10 +90,7 @@ func (h *UserSchoolExperienceHandler) UpdateUniversity(c *fiber.Ctx) error {
 }
 
 func (h *UserSchoolExperienceHandler) AddUniversity(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	var universityData map[string]interface{}
 	if err := c.BodyParser(&universityData); err != nil {
@@ -124,10 +113,7 @@ func (h *UserSchoolExperienceHandler) AddUniversity(c *fiber.Ctx) error {
 }
 
 func (h *UserSchoolExperienceHandler) DeleteUniversity(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	universityID := c.Params("id")
 	if universityID == "" {
@@ -136,7 +122,7 @@ func (h *UserSchoolExperienceHandler) DeleteUniversity(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.schoolExperienceService.DeleteUniversity(c.Context(), uid, universityID)
+	err := h.schoolExperienceService.DeleteUniversity(c.Context(), uid, universityID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete university",
@@ -149,10 +135,7 @@ func (h *UserSchoolExperienceHandler) DeleteUniversity(c *fiber.Ctx) error {
 }
 
 func (h *UserSchoolExperienceHandler) AddListOfUniversities(c *fiber.Ctx) error {
-	uid, err := ExtractAndValidateToken(c)
-	if err != nil {
-		return err
-	}
+	uid := middleware.CurrentUID(c)
 
 	var requestBody struct {
 		Universities []map[string]interface{} `json:"universities"`
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os/signal"
@@ -8,9 +9,11 @@ import (
 	"os"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/rogerjeasy/go-letusconnect/config"
 	"github.com/rogerjeasy/go-letusconnect/handlers"
 	"github.com/rogerjeasy/go-letusconnect/middleware"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 	"github.com/rogerjeasy/go-letusconnect/routes"
 	"github.com/rogerjeasy/go-letusconnect/services"
 	// "github.com/joho/godotenv"
@@ -24,32 +27,92 @@ func main() {
 
 	config.LoadConfig()
 
-	// Initialize Firebase
-	if err := services.InitializeFirebase(); err != nil {
-		log.Fatalf("Failed to initialize Firebase: %v", err)
+	if err := services.InitializeTokens(); err != nil {
+		log.Fatalf("Failed to initialize the token service: %v", err)
 	}
 
-	// Initialize Pusher
-	services.InitializePusher()
+	// Initialize the storage backend
+	migrate := len(os.Args) > 1 && os.Args[1] == "migrate"
+	var repos *repository.Repositories
+	if config.StorageBackend == "memory" {
+		if migrate {
+			log.Fatal("The in-memory storage has nothing to migrate")
+		}
+		log.Println("Using in-memory storage, data will be lost on restart")
+		repos = repository.NewMemoryRepositories()
+	} else {
+		if err := services.InitializeFirebase(); err != nil {
+			log.Fatalf("Failed to initialize Firebase: %v", err)
+		}
+		repos = repository.NewFirestoreRepositories(services.Firestore)
+
+		// The Firestore migrations scan whole collections, so they only run with
+		// "go run main.go migrate"; starting the server only checks their markers
+		if migrate {
+			if err := repository.MigrateFirestore(context.Background(), services.Firestore); err != nil {
+				log.Fatalf("Failed to migrate Firestore: %v", err)
+			}
+			log.Println("Firestore migrations are complete")
+			return
+		}
+		pending, err := repository.PendingFirestoreMigrations(context.Background(), services.Firestore)
+		if err != nil {
+			log.Fatalf("Failed to read the Firestore migrations: %v", err)
+		}
+		if len(pending) > 0 {
+			log.Printf("Firestore migrations %v have not run, run \"go run main.go migrate\"", pending)
+		}
+	}
+
+	if config.ForumStorageBackend == "sql" {
+		db, err := repository.OpenSQL(config.SQLDriver, config.SQLDSN)
+		if err != nil {
+			log.Fatalf("Failed to open SQL database: %v", err)
+		}
+		if err := repository.MigrateSQL(db); err != nil {
+			log.Fatalf("Failed to migrate SQL database: %v", err)
+		}
+		log.Printf("Using %s storage for groups and forums", config.SQLDriver)
+		repos = repository.NewSQLRepositories(repos, db)
+	}
 
-	userService := services.NewUserService(services.Firestore)
+	if config.IdentityProvider != "local" && services.FirebaseAuth == nil {
+		log.Fatal("The Firebase identity provider needs Firebase, set IDENTITY_PROVIDER=local to run without it")
+	}
+
+	// Access tokens of signed out sessions are rejected until they expire
+	services.Tokens.SetRevocationList(repos.Revocations)
+
+	userService := services.NewUserService(repos.Users)
 	cloudinary := services.InitCloudinary()
 
-	serviceContainer := services.NewServiceContainer(services.Firestore, userService, cloudinary)
+	serviceContainer := services.NewServiceContainer(repos, userService, cloudinary)
+	services.Tokens.SetPersonalAccessTokens(serviceContainer.PersonalAccessTokenService)
+
+	// Realtime events reach the clients over WebSocket, and over Pusher when it is configured.
+	// Notifications and unread counts are also streamed as Server-Sent Events.
+	// Subscriptions to channels other than the user's own are checked against memberships.
+	// The WebSocket connections and their heartbeats drive the presence of the users, and
+	// clients share over them who is typing.
+	websockets := services.NewWebSocketTransport(serviceContainer.AuthorizationService.AuthorizeChannel, serviceContainer.PresenceService, serviceContainer.TypingService)
+	if err := services.InitializeRealtime(websockets, serviceContainer.EventStream); err != nil {
+		log.Fatalf("Failed to initialize realtime events: %v", err)
+	}
+	serviceContainer.PresenceService.Start(context.Background())
 
 	// Start background services
 	// serviceContainer.StartServices(ctx)
 	// defer serviceContainer.StopServices()
 
-	authService := services.NewAuthService(services.Firestore)
+	authService := services.NewAuthService(repos.Users)
 	authHandler := handlers.NewAuthHandler(authService, serviceContainer)
 
 	app := fiber.New()
+	app.Use(recover.New())
 
 	app.Post("/auth/linkedin", authHandler.LinkedInCallback)
 
-	websocketService := services.NewGorillaWebSocketService()
-	websocketService.HandleWebSocket(app)
+	websockets.Mount(app)
 
 	// Improved CORS configuration
 	app.Use(middleware.ConfigureCORS())
@@ -0,0 +1,46 @@
+package mappers
+
+import (
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MapAccountMergeGoToFirestore maps an AccountMerge Go struct to Firestore format
+func MapAccountMergeGoToFirestore(merge models.AccountMerge) map[string]interface{} {
+	moved := make(map[string]interface{}, len(merge.Moved))
+	for kind, count := range merge.Moved {
+		moved[kind] = count
+	}
+
+	return map[string]interface{}{
+		"id":              merge.ID,
+		"source_uid":      merge.SourceUID,
+		"source_email":    merge.SourceEmail,
+		"source_username": merge.SourceUsername,
+		"target_uid":      merge.TargetUID,
+		"merged_by":       merge.MergedBy,
+		"moved":           moved,
+		"merged_at":       merge.MergedAt,
+	}
+}
+
+// MapAccountMergeFirestoreToGo maps Firestore AccountMerge data to Go struct format
+func MapAccountMergeFirestoreToGo(data map[string]interface{}) models.AccountMerge {
+	merge := models.AccountMerge{
+		ID:             getStringValue(data, "id"),
+		SourceUID:      getStringValue(data, "source_uid"),
+		SourceEmail:    getStringValue(data, "source_email"),
+		SourceUsername: getStringValue(data, "source_username"),
+		TargetUID:      getStringValue(data, "target_uid"),
+		MergedBy:       getStringValue(data, "merged_by"),
+		Moved:          map[string]int{},
+		MergedAt:       getTimeValue(data, "merged_at"),
+	}
+
+	if moved, ok := data["moved"].(map[string]interface{}); ok {
+		for kind := range moved {
+			merge.Moved[kind] = getIntValueSafe(moved, kind)
+		}
+	}
+
+	return merge
+}
@@ -0,0 +1,45 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MapActionTokenGoToFirestore maps an ActionToken Go struct to Firestore format
+func MapActionTokenGoToFirestore(token models.ActionToken) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":         token.ID,
+		"uid":        token.UID,
+		"email":      token.Email,
+		"purpose":    token.Purpose,
+		"created_at": token.CreatedAt,
+		"expires_at": token.ExpiresAt,
+		"attempts":   token.Attempts,
+	}
+
+	if token.UsedAt != nil {
+		data["used_at"] = *token.UsedAt
+	}
+
+	return data
+}
+
+// MapActionTokenFirestoreToGo maps Firestore ActionToken data to Go struct format
+func MapActionTokenFirestoreToGo(data map[string]interface{}) models.ActionToken {
+	token := models.ActionToken{
+		ID:        getStringValue(data, "id"),
+		UID:       getStringValue(data, "uid"),
+		Email:     getStringValue(data, "email"),
+		Purpose:   getStringValue(data, "purpose"),
+		CreatedAt: getTimeValue(data, "created_at"),
+		ExpiresAt: getTimeValue(data, "expires_at"),
+		Attempts:  getIntValueSafe(data, "attempts"),
+	}
+
+	if usedAt, ok := data["used_at"].(time.Time); ok {
+		token.UsedAt = &usedAt
+	}
+
+	return token
+}
@@ -0,0 +1,43 @@
+package mappers
+
+import (
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MapAuditEventGoToFirestore maps an AuditEvent Go struct to Firestore format
+func MapAuditEventGoToFirestore(event models.AuditEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          event.ID,
+		"actor_uid":   event.ActorUID,
+		"actor_email": event.ActorEmail,
+		"action":      event.Action,
+		"target_type": event.TargetType,
+		"target_id":   event.TargetID,
+		"route":       event.Route,
+		"ip_address":  event.IPAddress,
+		"user_agent":  event.UserAgent,
+		"outcome":     event.Outcome,
+		"status_code": event.StatusCode,
+		"detail":      event.Detail,
+		"created_at":  event.CreatedAt,
+	}
+}
+
+// MapAuditEventFirestoreToGo maps Firestore AuditEvent data to Go struct format
+func MapAuditEventFirestoreToGo(data map[string]interface{}) models.AuditEvent {
+	return models.AuditEvent{
+		ID:         getStringValue(data, "id"),
+		ActorUID:   getStringValue(data, "actor_uid"),
+		ActorEmail: getStringValue(data, "actor_email"),
+		Action:     getStringValue(data, "action"),
+		TargetType: getStringValue(data, "target_type"),
+		TargetID:   getStringValue(data, "target_id"),
+		Route:      getStringValue(data, "route"),
+		IPAddress:  getStringValue(data, "ip_address"),
+		UserAgent:  getStringValue(data, "user_agent"),
+		Outcome:    getStringValue(data, "outcome"),
+		StatusCode: getIntValueSafe(data, "status_code"),
+		Detail:     getStringValue(data, "detail"),
+		CreatedAt:  getTimeValue(data, "created_at"),
+	}
+}
@@ -0,0 +1,21 @@
+package mappers
+
+import "github.com/rogerjeasy/go-letusconnect/models"
+
+// MapCredentialGoToFirestore maps a Credential Go struct to Firestore format
+func MapCredentialGoToFirestore(credential models.Credential) map[string]interface{} {
+	return map[string]interface{}{
+		"uid":           credential.UID,
+		"password_hash": credential.PasswordHash,
+		"updated_at":    credential.UpdatedAt,
+	}
+}
+
+// MapCredentialFirestoreToGo maps Firestore Credential data to Go struct format
+func MapCredentialFirestoreToGo(data map[string]interface{}) models.Credential {
+	return models.Credential{
+		UID:          getStringValue(data, "uid"),
+		PasswordHash: getStringValue(data, "password_hash"),
+		UpdatedAt:    getTimeValue(data, "updated_at"),
+	}
+}
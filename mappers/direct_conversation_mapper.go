@@ -0,0 +1,131 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MapDirectConversationGoToFirestore maps a DirectConversation Go struct to Firestore format
+func MapDirectConversationGoToFirestore(conversation models.DirectConversation) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":           conversation.ID,
+		"participants": conversation.Participants,
+		"unread_count": conversation.UnreadCount,
+		"muted":        conversation.Muted,
+		"archived":     conversation.Archived,
+		"read_seq":     conversation.ReadSeq,
+		"created_at":   conversation.CreatedAt,
+		"updated_at":   conversation.UpdatedAt,
+	}
+
+	if conversation.LastMessage != nil {
+		data["last_message"] = map[string]interface{}{
+			"message_id":  conversation.LastMessage.MessageID,
+			"sender_id":   conversation.LastMessage.SenderID,
+			"sender_name": conversation.LastMessage.SenderName,
+			"content":     conversation.LastMessage.Content,
+			"created_at":  conversation.LastMessage.CreatedAt,
+		}
+	}
+
+	return data
+}
+
+// MapDirectConversationFirestoreToGo maps Firestore DirectConversation data to Go struct format
+func MapDirectConversationFirestoreToGo(data map[string]interface{}) models.DirectConversation {
+	conversation := models.DirectConversation{
+		ID:           getStringValue(data, "id"),
+		Participants: getStringArrayValue(data, "participants"),
+		UnreadCount:  getIntMapValue(data, "unread_count"),
+		Muted:        getBoolMapValue(data, "muted"),
+		Archived:     getBoolMapValue(data, "archived"),
+		ReadSeq:      getInt64MapValue(data, "read_seq"),
+		CreatedAt:    getTimeValue(data, "created_at"),
+		UpdatedAt:    getTimeValue(data, "updated_at"),
+	}
+
+	if lastMessage := getMapValue(data, "last_message"); lastMessage != nil {
+		conversation.LastMessage = &models.DirectConversationPreview{
+			MessageID:  getStringValue(lastMessage, "message_id"),
+			SenderID:   getStringValue(lastMessage, "sender_id"),
+			SenderName: getStringValue(lastMessage, "sender_name"),
+			Content:    getStringValue(lastMessage, "content"),
+			CreatedAt:  getStringValue(lastMessage, "created_at"),
+		}
+	}
+
+	return conversation
+}
+
+// MapDirectConversationGoToFrontend maps a DirectConversation Go struct to frontend format
+// as seen by userID: unread count and flags are the user's own
+func MapDirectConversationGoToFrontend(conversation models.DirectConversation, userID string) map[string]interface{} {
+	var lastMessage map[string]interface{}
+	if conversation.LastMessage != nil {
+		lastMessage = map[string]interface{}{
+			"messageId":  conversation.LastMessage.MessageID,
+			"senderId":   conversation.LastMessage.SenderID,
+			"senderName": conversation.LastMessage.SenderName,
+			"content":    conversation.LastMessage.Content,
+			"createdAt":  conversation.LastMessage.CreatedAt,
+		}
+	}
+
+	return map[string]interface{}{
+		"id":           conversation.ID,
+		"channelId":    conversation.ID,
+		"participants": conversation.Participants,
+		"lastMessage":  lastMessage,
+		"unreadCount":  conversation.UnreadCount[userID],
+		"isMuted":      conversation.Muted[userID],
+		"isArchived":   conversation.Archived[userID],
+		"createdAt":    conversation.CreatedAt.Format(time.RFC3339),
+		"updatedAt":    conversation.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// MapDirectMessagesArrayToFrontend maps an array of DirectMessage Go structs to frontend format
+func MapDirectMessagesArrayToFrontend(messages []models.DirectMessage) []map[string]interface{} {
+	frontendMessages := []map[string]interface{}{}
+	for _, message := range messages {
+		frontendMessages = append(frontendMessages, MapDirectMessageGoToFrontend(message))
+	}
+	return frontendMessages
+}
+
+// getIntMapValue reads a map of counters keyed by user ID
+func getIntMapValue(data map[string]interface{}, key string) map[string]int {
+	values := make(map[string]int)
+	for k, v := range getMapValue(data, key) {
+		switch count := v.(type) {
+		case int64:
+			values[k] = int(count)
+		case int:
+			values[k] = count
+		case float64:
+			values[k] = int(count)
+		}
+	}
+	return values
+}
+
+// getInt64MapValue reads a map of sequence numbers keyed by user ID
+func getInt64MapValue(data map[string]interface{}, key string) map[string]int64 {
+	values := make(map[string]int64)
+	for k, v := range getIntMapValue(data, key) {
+		values[k] = int64(v)
+	}
+	return values
+}
+
+// getBoolMapValue reads a map of flags keyed by user ID
+func getBoolMapValue(data map[string]interface{}, key string) map[string]bool {
+	values := make(map[string]bool)
+	for k, v := range getMapValue(data, key) {
+		if flag, ok := v.(bool); ok {
+			values[k] = flag
+		}
+	}
+	return values
+}
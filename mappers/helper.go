@@ -166,8 +166,12 @@ func getStringValue(data map[string]interface{}, key string) string {
 
 func getTimeValue(data map[string]interface{}, key string) time.Time {
 	if value, ok := data[key]; ok {
-		if timeStr, isString := value.(string); isString {
-			parsedTime, err := time.Parse(time.RFC3339, timeStr)
+		switch v := value.(type) {
+		case time.Time:
+			// Firestore returns timestamps as time.Time
+			return v
+		case string:
+			parsedTime, err := time.Parse(time.RFC3339, v)
 			if err != nil {
 				log.Printf("Error parsing time for key %s: %v", key, err)
 				return time.Time{} // return zero value of time.Time in case of an error
@@ -413,8 +417,14 @@ func getReactionsMap(data map[string]interface{}, key string) map[string]int {
 	if val, ok := data[key].(map[string]interface{}); ok {
 		reactions := make(map[string]int)
 		for k, v := range val {
-			if count, ok := v.(float64); ok {
+			switch count := v.(type) {
+			case float64:
+				reactions[k] = int(count)
+			case int64:
+				// Firestore returns integers as int64
 				reactions[k] = int(count)
+			case int:
+				reactions[k] = count
 			}
 		}
 		return reactions
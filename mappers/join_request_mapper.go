@@ -57,55 +57,83 @@ func MapJoinRequestFirestoreToGo(data map[string]interface{}) models.JoinRequest
 }
 
 func mapJoinRequestsArrayToFrontend(data interface{}) []map[string]interface{} {
-	if joinRequests, ok := data.([]interface{}); ok {
-		var result []map[string]interface{}
+	var result []map[string]interface{}
+	switch joinRequests := data.(type) {
+	case []interface{}:
 		for _, jr := range joinRequests {
 			if jrMap, ok := jr.(map[string]interface{}); ok {
 				result = append(result, MapJoinRequestFirestoreToFrontend(jrMap))
 			}
 		}
-		return result
+	case []map[string]interface{}:
+		// Data built in Go (e.g. by MapProjectGoToFirestore) is already typed
+		for _, jrMap := range joinRequests {
+			result = append(result, MapJoinRequestFirestoreToFrontend(jrMap))
+		}
+	default:
+		return []map[string]interface{}{}
 	}
-	return []map[string]interface{}{}
+	return result
 }
 
 func mapTasksArrayToFrontend(data interface{}) []map[string]interface{} {
-	if tasks, ok := data.([]interface{}); ok {
-		var result []map[string]interface{}
+	var result []map[string]interface{}
+	switch tasks := data.(type) {
+	case []interface{}:
 		for _, task := range tasks {
 			if taskMap, ok := task.(map[string]interface{}); ok {
 				result = append(result, MapTaskFirestoreToFrontend(taskMap))
 			}
 		}
-		return result
+	case []map[string]interface{}:
+		// Data built in Go (e.g. by MapProjectGoToFirestore) is already typed
+		for _, taskMap := range tasks {
+			result = append(result, MapTaskFirestoreToFrontend(taskMap))
+		}
+	default:
+		return []map[string]interface{}{}
 	}
-	return []map[string]interface{}{}
+	return result
 }
 
 func mapAttachmentsArrayToFrontend(data interface{}) []map[string]interface{} {
-	if attachments, ok := data.([]interface{}); ok {
-		var result []map[string]interface{}
+	var result []map[string]interface{}
+	switch attachments := data.(type) {
+	case []interface{}:
 		for _, attachment := range attachments {
 			if attachmentMap, ok := attachment.(map[string]interface{}); ok {
 				result = append(result, MapAttachmentFirestoreToFrontend(attachmentMap))
 			}
 		}
-		return result
+	case []map[string]interface{}:
+		// Data built in Go (e.g. by MapProjectGoToFirestore) is already typed
+		for _, attachmentMap := range attachments {
+			result = append(result, MapAttachmentFirestoreToFrontend(attachmentMap))
+		}
+	default:
+		return []map[string]interface{}{}
 	}
-	return []map[string]interface{}{}
+	return result
 }
 
 func mapFeedbacksArrayToFrontend(data interface{}) []map[string]interface{} {
-	if feedbacks, ok := data.([]interface{}); ok {
-		var result []map[string]interface{}
+	var result []map[string]interface{}
+	switch feedbacks := data.(type) {
+	case []interface{}:
 		for _, feedback := range feedbacks {
 			if feedbackMap, ok := feedback.(map[string]interface{}); ok {
 				result = append(result, MapFeedbackFirestoreToFrontend(feedbackMap))
 			}
 		}
-		return result
+	case []map[string]interface{}:
+		// Data built in Go (e.g. by MapProjectGoToFirestore) is already typed
+		for _, feedbackMap := range feedbacks {
+			result = append(result, MapFeedbackFirestoreToFrontend(feedbackMap))
+		}
+	default:
+		return []map[string]interface{}{}
 	}
-	return []map[string]interface{}{}
+	return result
 }
 
 // GetJoinRequestsArray extracts and maps the "join_requests" field to a slice of models.JoinRequest
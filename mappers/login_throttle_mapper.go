@@ -0,0 +1,47 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MapLoginThrottleGoToFirestore maps a LoginThrottle Go struct to Firestore format
+func MapLoginThrottleGoToFirestore(throttle models.LoginThrottle) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":              throttle.ID,
+		"kind":            throttle.Kind,
+		"subject":         throttle.Subject,
+		"failures":        throttle.Failures,
+		"last_failure_at": throttle.LastFailureAt,
+		"last_failure_ip": throttle.LastFailureIP,
+		"lockouts":        throttle.Lockouts,
+		"expires_at":      throttle.ExpiresAt,
+	}
+
+	if throttle.LockedUntil != nil {
+		data["locked_until"] = *throttle.LockedUntil
+	}
+
+	return data
+}
+
+// MapLoginThrottleFirestoreToGo maps Firestore LoginThrottle data to Go struct format
+func MapLoginThrottleFirestoreToGo(data map[string]interface{}) models.LoginThrottle {
+	throttle := models.LoginThrottle{
+		ID:            getStringValue(data, "id"),
+		Kind:          getStringValue(data, "kind"),
+		Subject:       getStringValue(data, "subject"),
+		Failures:      getIntValueSafe(data, "failures"),
+		LastFailureAt: getTimeValue(data, "last_failure_at"),
+		LastFailureIP: getStringValue(data, "last_failure_ip"),
+		Lockouts:      getIntValueSafe(data, "lockouts"),
+		ExpiresAt:     getTimeValue(data, "expires_at"),
+	}
+
+	if lockedUntil, ok := data["locked_until"].(time.Time); ok {
+		throttle.LockedUntil = &lockedUntil
+	}
+
+	return throttle
+}
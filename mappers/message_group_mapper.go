@@ -79,7 +79,8 @@ func MapGroupChatFrontendToGo(data map[string]interface{}) models.GroupChat {
 	}
 }
 
-// 2. MapGroupChatGoToFirestore maps Go struct GroupChat data to Firestore format
+// 2. MapGroupChatGoToFirestore maps Go struct GroupChat data to Firestore format.
+// Messages are not part of the chat document, they live in its "messages" subcollection.
 func MapGroupChatGoToFirestore(chat models.GroupChat) map[string]interface{} {
 	return map[string]interface{}{
 		"id":              chat.ID,
@@ -89,7 +90,6 @@ func MapGroupChatGoToFirestore(chat models.GroupChat) map[string]interface{} {
 		"name":            chat.Name,
 		"description":     chat.Description,
 		"participants":    MapParticipantsArrayToFirestore(chat.Participants),
-		"messages":        mapBaseMessagesArrayToFirestore(chat.Messages),
 		"pinned_messages": chat.PinnedMessages,
 		"is_archived":     chat.IsArchived,
 		"notifications":   chat.Notifications,
@@ -97,6 +97,9 @@ func MapGroupChatGoToFirestore(chat models.GroupChat) map[string]interface{} {
 		"updated_at":      chat.UpdatedAt,
 		"read_status":     chat.ReadStatus,
 		"group_settings":  MapGroupSettingsGoToFirestore(chat.GroupSettings),
+		"polls":           MapPollsArrayToFirestore(chat.Polls),
+		"reports":         MapReportsArrayToFirestore(chat.Reports),
+		"last_seen":       chat.LastSeen,
 	}
 }
 
@@ -139,9 +142,22 @@ func MapGroupChatFirestoreToGo(data map[string]interface{}) models.GroupChat {
 		UpdatedAt:      getTimeValue(data, "updated_at"),
 		ReadStatus:     getReadStatusMap(data, "read_status"),
 		GroupSettings:  MapGroupSettingsFirestoreToGo(getMapValue(data, "group_settings")),
+		Polls:          GetPollsArray(data, "polls"),
+		Reports:        GetReportsArray(data, "reports"),
+		LastSeen:       getLastSeenMap(data, "last_seen"),
 	}
 }
 
+// getLastSeenMap reads the per-user last seen timestamps of a group chat
+func getLastSeenMap(data map[string]interface{}, key string) map[string]time.Time {
+	raw := getMapValue(data, key)
+	lastSeen := make(map[string]time.Time, len(raw))
+	for userID := range raw {
+		lastSeen[userID] = getTimeValue(raw, userID)
+	}
+	return lastSeen
+}
+
 // MapGroupChatGoToFrontend maps Go struct GroupChat data to frontend format
 func MapGroupChatGoToFrontend(chat models.GroupChat) map[string]interface{} {
 	return map[string]interface{}{
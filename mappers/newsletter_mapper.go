@@ -0,0 +1,24 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MapNewsletterSubscriptionGoToFirestore maps a subscription to Firestore format.
+// The subscription time is kept as an RFC 3339 string, as it always was.
+func MapNewsletterSubscriptionGoToFirestore(subscription models.NewsletterSubscription) map[string]interface{} {
+	return map[string]interface{}{
+		"email":        subscription.Email,
+		"subscribedAt": subscription.SubscribedAt.Format(time.RFC3339),
+	}
+}
+
+// MapNewsletterSubscriptionFirestoreToGo maps Firestore subscription data to Go struct format
+func MapNewsletterSubscriptionFirestoreToGo(data map[string]interface{}) models.NewsletterSubscription {
+	return models.NewsletterSubscription{
+		Email:        getStringValue(data, "email"),
+		SubscribedAt: getTimeValue(data, "subscribedAt"),
+	}
+}
@@ -139,6 +139,12 @@ func MapNotificationGoToFirestore(notification models.Notification) map[string]i
 	if notification.ReadAt != nil {
 		data["read_at"] = *notification.ReadAt
 	}
+	if !notification.ScheduledAt.IsZero() {
+		data["scheduled_at"] = notification.ScheduledAt
+	}
+	if notification.Recipient != "" {
+		data["recipient"] = notification.Recipient
+	}
 
 	return data
 }
@@ -224,6 +230,10 @@ func MapNotificationFirestoreToGo(data map[string]interface{}) models.Notificati
 	if readAt, ok := data["read_at"].(time.Time); ok {
 		notification.ReadAt = &readAt
 	}
+	if scheduledAt, ok := data["scheduled_at"].(time.Time); ok {
+		notification.ScheduledAt = scheduledAt
+	}
+	notification.Recipient = getStringValue(data, "recipient")
 
 	return notification
 }
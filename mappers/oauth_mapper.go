@@ -0,0 +1,73 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MapOAuthStateGoToFirestore maps an OAuthState Go struct to Firestore format
+func MapOAuthStateGoToFirestore(state models.OAuthState) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":            state.ID,
+		"provider":      state.Provider,
+		"code_verifier": state.CodeVerifier,
+		"nonce":         state.Nonce,
+		"link_uid":      state.LinkUID,
+		"created_at":    state.CreatedAt,
+		"expires_at":    state.ExpiresAt,
+	}
+
+	if state.UsedAt != nil {
+		data["used_at"] = *state.UsedAt
+	}
+
+	return data
+}
+
+// MapOAuthStateFirestoreToGo maps Firestore OAuthState data to Go struct format
+func MapOAuthStateFirestoreToGo(data map[string]interface{}) models.OAuthState {
+	state := models.OAuthState{
+		ID:           getStringValue(data, "id"),
+		Provider:     getStringValue(data, "provider"),
+		CodeVerifier: getStringValue(data, "code_verifier"),
+		Nonce:        getStringValue(data, "nonce"),
+		LinkUID:      getStringValue(data, "link_uid"),
+		CreatedAt:    getTimeValue(data, "created_at"),
+		ExpiresAt:    getTimeValue(data, "expires_at"),
+	}
+
+	if usedAt, ok := data["used_at"].(time.Time); ok {
+		state.UsedAt = &usedAt
+	}
+
+	return state
+}
+
+// MapIdentityGoToFirestore maps an Identity Go struct to Firestore format
+func MapIdentityGoToFirestore(identity models.Identity) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             identity.ID,
+		"uid":            identity.UID,
+		"provider":       identity.Provider,
+		"subject":        identity.Subject,
+		"email":          identity.Email,
+		"email_verified": identity.EmailVerified,
+		"linked_at":      identity.LinkedAt,
+		"last_login_at":  identity.LastLoginAt,
+	}
+}
+
+// MapIdentityFirestoreToGo maps Firestore Identity data to Go struct format
+func MapIdentityFirestoreToGo(data map[string]interface{}) models.Identity {
+	return models.Identity{
+		ID:            getStringValue(data, "id"),
+		UID:           getStringValue(data, "uid"),
+		Provider:      getStringValue(data, "provider"),
+		Subject:       getStringValue(data, "subject"),
+		Email:         getStringValue(data, "email"),
+		EmailVerified: getBoolValueSafe(data, "email_verified"),
+		LinkedAt:      getTimeValue(data, "linked_at"),
+		LastLoginAt:   getTimeValue(data, "last_login_at"),
+	}
+}
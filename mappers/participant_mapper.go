@@ -28,6 +28,7 @@ func MapParticipantGoToFirestore(user models.Participant) map[string]interface{}
 		"username":        user.Username,
 		"email":           user.Email,
 		"joined_at":       user.JoinedAt,
+		"muted_until":     user.MutedUntil,
 	}
 }
 
@@ -63,6 +64,7 @@ func MapParticipantFirestoreToGo(data map[string]interface{}) models.Participant
 		Username:       getStringValue(data, "username"),
 		Email:          getStringValue(data, "email"),
 		JoinedAt:       getTimeValue(data, "joined_at"),
+		MutedUntil:     getTimeValue(data, "muted_until"),
 	}
 }
 
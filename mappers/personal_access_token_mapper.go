@@ -0,0 +1,75 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MapPersonalAccessTokenGoToFirestore maps a PersonalAccessToken Go struct to Firestore format
+func MapPersonalAccessTokenGoToFirestore(token models.PersonalAccessToken) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":         token.ID,
+		"uid":        token.UID,
+		"name":       token.Name,
+		"scopes":     token.Scopes,
+		"token_hash": token.TokenHash,
+		"hint":       token.Hint,
+		"created_at": token.CreatedAt,
+		"expires_at": token.ExpiresAt,
+	}
+
+	if token.LastUsedAt != nil {
+		data["last_used_at"] = *token.LastUsedAt
+		data["last_used_ip"] = token.LastUsedIP
+	}
+	if token.RevokedAt != nil {
+		data["revoked_at"] = *token.RevokedAt
+	}
+
+	return data
+}
+
+// MapPersonalAccessTokenFirestoreToGo maps Firestore PersonalAccessToken data to Go struct format
+func MapPersonalAccessTokenFirestoreToGo(data map[string]interface{}) models.PersonalAccessToken {
+	token := models.PersonalAccessToken{
+		ID:        getStringValue(data, "id"),
+		UID:       getStringValue(data, "uid"),
+		Name:      getStringValue(data, "name"),
+		Scopes:    getStringArrayValue(data, "scopes"),
+		TokenHash: getStringValue(data, "token_hash"),
+		Hint:      getStringValue(data, "hint"),
+		CreatedAt: getTimeValue(data, "created_at"),
+		ExpiresAt: getTimeValue(data, "expires_at"),
+	}
+
+	if lastUsedAt, ok := data["last_used_at"].(time.Time); ok {
+		token.LastUsedAt = &lastUsedAt
+		token.LastUsedIP = getStringValue(data, "last_used_ip")
+	}
+	if revokedAt, ok := data["revoked_at"].(time.Time); ok {
+		token.RevokedAt = &revokedAt
+	}
+
+	return token
+}
+
+// MapPersonalAccessTokenGoToFrontend maps a PersonalAccessToken Go struct to frontend format, without its hash
+func MapPersonalAccessTokenGoToFrontend(token models.PersonalAccessToken) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":        token.ID,
+		"name":      token.Name,
+		"scopes":    token.Scopes,
+		"hint":      token.Hint,
+		"createdAt": token.CreatedAt.Format(time.RFC3339),
+		"expiresAt": token.ExpiresAt.Format(time.RFC3339),
+		"expired":   time.Now().After(token.ExpiresAt),
+	}
+
+	if token.LastUsedAt != nil {
+		data["lastUsedAt"] = token.LastUsedAt.Format(time.RFC3339)
+		data["lastUsedIp"] = token.LastUsedIP
+	}
+
+	return data
+}
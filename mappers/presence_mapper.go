@@ -0,0 +1,79 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MapPresenceGoToFirestore maps a Presence Go struct to Firestore format
+func MapPresenceGoToFirestore(presence models.Presence) map[string]interface{} {
+	devices := make(map[string]interface{}, len(presence.Devices))
+	for id, device := range presence.Devices {
+		devices[id] = map[string]interface{}{
+			"connection_id": device.ConnectionID,
+			"session_id":    device.SessionID,
+			"device":        device.Device,
+			"user_agent":    device.UserAgent,
+			"ip_address":    device.IPAddress,
+			"state":         device.State,
+			"connected_at":  device.ConnectedAt,
+			"heartbeat_at":  device.HeartbeatAt,
+		}
+	}
+
+	return map[string]interface{}{
+		"uid":          presence.UID,
+		"status":       presence.Status,
+		"devices":      devices,
+		"last_seen_at": presence.LastSeenAt,
+		"hidden":       presence.Hidden,
+		"updated_at":   presence.UpdatedAt,
+	}
+}
+
+// MapPresenceFirestoreToGo maps Firestore Presence data to Go struct format
+func MapPresenceFirestoreToGo(data map[string]interface{}) models.Presence {
+	presence := models.Presence{
+		UID:        getStringValue(data, "uid"),
+		Status:     getStringValue(data, "status"),
+		Devices:    map[string]models.PresenceDevice{},
+		LastSeenAt: getTimeValue(data, "last_seen_at"),
+		Hidden:     getBoolValue(data, "hidden"),
+		UpdatedAt:  getTimeValue(data, "updated_at"),
+	}
+
+	for id, value := range getMapValue(data, "devices") {
+		if device, ok := value.(map[string]interface{}); ok {
+			presence.Devices[id] = models.PresenceDevice{
+				ConnectionID: getStringValue(device, "connection_id"),
+				SessionID:    getStringValue(device, "session_id"),
+				Device:       getStringValue(device, "device"),
+				UserAgent:    getStringValue(device, "user_agent"),
+				IPAddress:    getStringValue(device, "ip_address"),
+				State:        getStringValue(device, "state"),
+				ConnectedAt:  getTimeValue(device, "connected_at"),
+				HeartbeatAt:  getTimeValue(device, "heartbeat_at"),
+			}
+		}
+	}
+
+	return presence
+}
+
+// MapPresenceDevicesToFrontend maps the realtime connections of a session to frontend format
+func MapPresenceDevicesToFrontend(devices []models.PresenceDevice) []map[string]interface{} {
+	connections := make([]map[string]interface{}, 0, len(devices))
+	for _, device := range devices {
+		connections = append(connections, map[string]interface{}{
+			"id":          device.ConnectionID,
+			"device":      device.Device,
+			"userAgent":   device.UserAgent,
+			"ipAddress":   device.IPAddress,
+			"state":       device.State,
+			"connectedAt": device.ConnectedAt.Format(time.RFC3339),
+			"heartbeatAt": device.HeartbeatAt.Format(time.RFC3339),
+		})
+	}
+	return connections
+}
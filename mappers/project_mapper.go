@@ -105,14 +105,58 @@ func MapProjectFirestoreToGo(data map[string]interface{}) models.Project {
 		Participants:         GetParticipantsArray(data, "participants"),
 		RejectedParticipants: getStringArrayValue(data, "rejected_participants"),
 		InvitedUsers:         getInvitedUsersArray(data, "invited_users"),
-		JoinRequests:         getJoinRequestsArray(data, "join_requests"),
-		Tasks:                getTasksArray(data, "tasks"),
+		JoinRequests:         GetJoinRequestsArray(data, "join_requests"),
+		Tasks:                getTasksArrayFromFirestore(data, "tasks"),
 		Progress:             getStringValue(data, "progress"),
 		// Comments:             getCommentsArray(data, "comments"),
 		ChatRoomID:  getStringValue(data, "chat_room_id"),
-		Attachments: getAttachmentsArray(data, "attachments"),
-		Feedback:    getFeedbacksArray(data, "feedback"),
+		Attachments: getAttachmentsArrayFromFirestore(data, "attachments"),
+		Feedback:    getFeedbacksArrayFromFirestore(data, "feedback"),
 		CreatedAt:   getTimeValue(data, "created_at"),
 		UpdatedAt:   getTimeValue(data, "updated_at"),
 	}
 }
+
+// MapProjectGoToFrontend maps Go struct project data to frontend format
+func MapProjectGoToFrontend(project models.Project) map[string]interface{} {
+	return MapProjectFirestoreToFrontend(MapProjectGoToFirestore(project))
+}
+
+// getTasksArrayFromFirestore reads a Firestore (snake_case) task array
+func getTasksArrayFromFirestore(data map[string]interface{}, key string) []models.Task {
+	tasks := []models.Task{}
+	if value, ok := data[key].([]interface{}); ok {
+		for _, v := range value {
+			if taskData, ok := v.(map[string]interface{}); ok {
+				tasks = append(tasks, MapTaskFirestoreToGo(taskData))
+			}
+		}
+	}
+	return tasks
+}
+
+// getAttachmentsArrayFromFirestore reads a Firestore (snake_case) attachment array
+func getAttachmentsArrayFromFirestore(data map[string]interface{}, key string) []models.Attachment {
+	attachments := []models.Attachment{}
+	if value, ok := data[key].([]interface{}); ok {
+		for _, v := range value {
+			if attachmentData, ok := v.(map[string]interface{}); ok {
+				attachments = append(attachments, MapAttachmentFirestoreToGo(attachmentData))
+			}
+		}
+	}
+	return attachments
+}
+
+// getFeedbacksArrayFromFirestore reads a Firestore (snake_case) feedback array
+func getFeedbacksArrayFromFirestore(data map[string]interface{}, key string) []models.Feedback {
+	feedbacks := []models.Feedback{}
+	if value, ok := data[key].([]interface{}); ok {
+		for _, v := range value {
+			if feedbackData, ok := v.(map[string]interface{}); ok {
+				feedbacks = append(feedbacks, MapFeedbackFirestoreToGo(feedbackData))
+			}
+		}
+	}
+	return feedbacks
+}
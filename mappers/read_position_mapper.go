@@ -0,0 +1,25 @@
+package mappers
+
+import "github.com/rogerjeasy/go-letusconnect/models"
+
+// MapReadPositionGoToFirestore maps a ReadPosition Go struct to Firestore format
+func MapReadPositionGoToFirestore(position models.ReadPosition) map[string]interface{} {
+	return map[string]interface{}{
+		"chat_id":    position.ChatID,
+		"user_id":    position.UserID,
+		"message_id": position.MessageID,
+		"seq":        position.Seq,
+		"read_at":    position.ReadAt,
+	}
+}
+
+// MapReadPositionFirestoreToGo maps Firestore ReadPosition data to Go struct format
+func MapReadPositionFirestoreToGo(data map[string]interface{}) models.ReadPosition {
+	return models.ReadPosition{
+		ChatID:    getStringValue(data, "chat_id"),
+		UserID:    getStringValue(data, "user_id"),
+		MessageID: getStringValue(data, "message_id"),
+		Seq:       getInt64Value(data, "seq"),
+		ReadAt:    getTimeValue(data, "read_at"),
+	}
+}
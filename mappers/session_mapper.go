@@ -0,0 +1,69 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MapSessionGoToFirestore maps a Session Go struct to Firestore format
+func MapSessionGoToFirestore(session models.Session) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":                    session.ID,
+		"uid":                   session.UID,
+		"email":                 session.Email,
+		"roles":                 session.Roles,
+		"refresh_token_hash":    session.RefreshTokenHash,
+		"previous_token_hashes": session.PreviousTokenHashes,
+		"user_agent":            session.UserAgent,
+		"ip_address":            session.IPAddress,
+		"created_at":            session.CreatedAt,
+		"last_used_at":          session.LastUsedAt,
+		"expires_at":            session.ExpiresAt,
+	}
+
+	if session.RevokedAt != nil {
+		data["revoked_at"] = *session.RevokedAt
+		data["revoked_reason"] = session.RevokedReason
+	}
+
+	return data
+}
+
+// MapSessionFirestoreToGo maps Firestore Session data to Go struct format
+func MapSessionFirestoreToGo(data map[string]interface{}) models.Session {
+	session := models.Session{
+		ID:                  getStringValue(data, "id"),
+		UID:                 getStringValue(data, "uid"),
+		Email:               getStringValue(data, "email"),
+		Roles:               getStringArrayValue(data, "roles"),
+		RefreshTokenHash:    getStringValue(data, "refresh_token_hash"),
+		PreviousTokenHashes: getStringArrayValue(data, "previous_token_hashes"),
+		UserAgent:           getStringValue(data, "user_agent"),
+		IPAddress:           getStringValue(data, "ip_address"),
+		CreatedAt:           getTimeValue(data, "created_at"),
+		LastUsedAt:          getTimeValue(data, "last_used_at"),
+		ExpiresAt:           getTimeValue(data, "expires_at"),
+	}
+
+	if revokedAt, ok := data["revoked_at"].(time.Time); ok {
+		session.RevokedAt = &revokedAt
+		session.RevokedReason = getStringValue(data, "revoked_reason")
+	}
+
+	return session
+}
+
+// MapSessionGoToFrontend maps a Session Go struct to frontend format.
+// current flags the session the request was made from.
+func MapSessionGoToFrontend(session models.Session, current bool) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         session.ID,
+		"userAgent":  session.UserAgent,
+		"ipAddress":  session.IPAddress,
+		"createdAt":  session.CreatedAt.Format(time.RFC3339),
+		"lastUsedAt": session.LastUsedAt.Format(time.RFC3339),
+		"expiresAt":  session.ExpiresAt.Format(time.RFC3339),
+		"isCurrent":  current,
+	}
+}
@@ -0,0 +1,45 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MapTwoFactorGoToFirestore maps a TwoFactor Go struct to Firestore format
+func MapTwoFactorGoToFirestore(twoFactor models.TwoFactor) map[string]interface{} {
+	data := map[string]interface{}{
+		"uid":                  twoFactor.UID,
+		"secret":               twoFactor.Secret,
+		"pending_secret":       twoFactor.PendingSecret,
+		"enabled":              twoFactor.Enabled,
+		"recovery_code_hashes": twoFactor.RecoveryCodeHashes,
+		"last_used_step":       twoFactor.LastUsedStep,
+		"updated_at":           twoFactor.UpdatedAt,
+	}
+
+	if twoFactor.EnabledAt != nil {
+		data["enabled_at"] = *twoFactor.EnabledAt
+	}
+
+	return data
+}
+
+// MapTwoFactorFirestoreToGo maps Firestore TwoFactor data to Go struct format
+func MapTwoFactorFirestoreToGo(data map[string]interface{}) models.TwoFactor {
+	twoFactor := models.TwoFactor{
+		UID:                getStringValue(data, "uid"),
+		Secret:             getStringValue(data, "secret"),
+		PendingSecret:      getStringValue(data, "pending_secret"),
+		Enabled:            getBoolValueSafe(data, "enabled"),
+		RecoveryCodeHashes: getStringArrayValue(data, "recovery_code_hashes"),
+		LastUsedStep:       int64(getIntValueSafe(data, "last_used_step")),
+		UpdatedAt:          getTimeValue(data, "updated_at"),
+	}
+
+	if enabledAt, ok := data["enabled_at"].(time.Time); ok {
+		twoFactor.EnabledAt = &enabledAt
+	}
+
+	return twoFactor
+}
@@ -0,0 +1,13 @@
+package mappers
+
+import "github.com/rogerjeasy/go-letusconnect/models"
+
+// MapUploadRecordGoToFirestore maps an upload record to Firestore format
+func MapUploadRecordGoToFirestore(record models.UploadRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"url":        record.URL,
+		"filename":   record.Filename,
+		"uploadedAt": record.UploadedAt,
+		"type":       record.Type,
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+const (
+	auditActorKey  = "auditActor"
+	auditTargetKey = "auditTarget"
+	auditDetailKey = "auditDetail"
+)
+
+// auditTargetUser is the target type of the operations on user accounts
+const auditTargetUser = "user"
+
+// maxAuditDetail bounds the detail of an audit event
+const maxAuditDetail = 256
+
+type auditActor struct {
+	uid   string
+	email string
+}
+
+// SetAuditActor names the user the audited operation was performed by, for the routes
+// that identify the user themselves, like login. It takes precedence over the caller.
+func SetAuditActor(c *fiber.Ctx, uid, email string) {
+	c.Locals(auditActorKey, auditActor{uid: uid, email: email})
+}
+
+// SetAuditTarget names the target of the audited operation when no route parameter does
+func SetAuditTarget(c *fiber.Ctx, targetID string) {
+	c.Locals(auditTargetKey, targetID)
+}
+
+// SetAuditDetail describes the audited operation, like the role it grants. The error of
+// the response is appended to the detail of failures.
+func SetAuditDetail(c *fiber.Ctx, detail string) {
+	c.Locals(auditDetailKey, detail)
+}
+
+// Audit records the route in the audit log once its handler ran. The target is the
+// targetParam route parameter; without one, operations on a "user" target the actor.
+// Placed before the permission checks of a route, it records the denied attempts too.
+func Audit(audit *services.AuditService, action, targetType, targetParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		handlerErr := c.Next()
+
+		status := c.Response().StatusCode()
+		var fiberErr *fiber.Error
+		if errors.As(handlerErr, &fiberErr) {
+			status = fiberErr.Code
+		} else if handlerErr != nil {
+			status = fiber.StatusInternalServerError
+		}
+
+		event := &models.AuditEvent{
+			Action:     action,
+			TargetType: targetType,
+			Route:      c.Method() + " " + c.Route().Path,
+			IPAddress:  c.IP(),
+			UserAgent:  c.Get(fiber.HeaderUserAgent),
+			Outcome:    auditOutcome(status),
+			StatusCode: status,
+		}
+
+		if actor, ok := c.Locals(auditActorKey).(auditActor); ok {
+			event.ActorUID, event.ActorEmail = actor.uid, actor.email
+		} else if principal := CurrentPrincipal(c); principal != nil {
+			event.ActorUID, event.ActorEmail = principal.UID, principal.Email
+		}
+
+		if target, ok := c.Locals(auditTargetKey).(string); ok {
+			event.TargetID = target
+		} else if targetParam != "" {
+			event.TargetID = c.Params(targetParam)
+		} else if targetType == auditTargetUser {
+			event.TargetID = event.ActorUID
+		}
+
+		event.Detail, _ = c.Locals(auditDetailKey).(string)
+		if event.Outcome != services.AuditOutcomeSuccess {
+			if reason := responseError(c, handlerErr); reason == "" || event.Detail == "" {
+				event.Detail += reason
+			} else {
+				event.Detail += ": " + reason
+			}
+		}
+		if len(event.Detail) > maxAuditDetail {
+			event.Detail = event.Detail[:maxAuditDetail]
+		}
+
+		if err := audit.Record(context.Background(), event); err != nil {
+			log.Printf("Error recording audit event %s: %v", action, err)
+		}
+		return handlerErr
+	}
+}
+
+// auditOutcome tells the outcome of an operation from its status code
+func auditOutcome(status int) string {
+	switch {
+	case status < fiber.StatusBadRequest:
+		return services.AuditOutcomeSuccess
+	case status == fiber.StatusUnauthorized, status == fiber.StatusForbidden,
+		status == fiber.StatusLocked, status == fiber.StatusTooManyRequests:
+		return services.AuditOutcomeDenied
+	default:
+		return services.AuditOutcomeFailure
+	}
+}
+
+// responseError returns the error the route answered with
+func responseError(c *fiber.Ctx, handlerErr error) string {
+	if handlerErr != nil {
+		return handlerErr.Error()
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(c.Response().Body(), &body); err != nil {
+		return ""
+	}
+	return body.Error
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/rogerjeasy/go-letusconnect/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	signedIn := func(c *fiber.Ctx) error {
+		c.Locals(principalKey, &Principal{UID: "admin", Email: "admin@example.com"})
+		return c.Next()
+	}
+
+	tests := []struct {
+		name        string
+		targetType  string
+		targetParam string
+		path        string
+		// principal signs the caller in before the audit
+		principal bool
+		handler   fiber.Handler
+		want      models.AuditEvent
+	}{
+		{
+			name:        "success on a route parameter",
+			targetType:  auditTargetUser,
+			targetParam: "uid",
+			path:        "/users/user-1",
+			principal:   true,
+			handler: func(c *fiber.Ctx) error {
+				SetAuditDetail(c, "moderator")
+				return c.SendStatus(fiber.StatusNoContent)
+			},
+			want: models.AuditEvent{
+				ActorUID: "admin", ActorEmail: "admin@example.com", TargetType: auditTargetUser, TargetID: "user-1",
+				Outcome: services.AuditOutcomeSuccess, StatusCode: fiber.StatusNoContent, Detail: "moderator",
+			},
+		},
+		{
+			name:       "user target defaults to the actor",
+			targetType: auditTargetUser,
+			path:       "/users/user-1",
+			principal:  true,
+			handler:    func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) },
+			want: models.AuditEvent{
+				ActorUID: "admin", ActorEmail: "admin@example.com", TargetType: auditTargetUser, TargetID: "admin",
+				Outcome: services.AuditOutcomeSuccess, StatusCode: fiber.StatusOK,
+			},
+		},
+		{
+			name:       "actor named by the handler",
+			targetType: auditTargetUser,
+			path:       "/users/user-1",
+			handler: func(c *fiber.Ctx) error {
+				SetAuditActor(c, "user-2", "user2@example.com")
+				SetAuditTarget(c, "session-1")
+				return c.SendStatus(fiber.StatusOK)
+			},
+			want: models.AuditEvent{
+				ActorUID: "user-2", ActorEmail: "user2@example.com", TargetType: auditTargetUser, TargetID: "session-1",
+				Outcome: services.AuditOutcomeSuccess, StatusCode: fiber.StatusOK,
+			},
+		},
+		{
+			name: "denied with the error of the response",
+			path: "/users/user-1",
+			handler: func(c *fiber.Ctx) error {
+				SetAuditDetail(c, "admin")
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Insufficient permissions"})
+			},
+			want: models.AuditEvent{
+				Outcome: services.AuditOutcomeDenied, StatusCode: fiber.StatusForbidden, Detail: "admin: Insufficient permissions",
+			},
+		},
+		{
+			name:    "fiber error",
+			path:    "/users/user-1",
+			handler: func(c *fiber.Ctx) error { return fiber.NewError(fiber.StatusTooManyRequests, "Too many attempts") },
+			want: models.AuditEvent{
+				Outcome: services.AuditOutcomeDenied, StatusCode: fiber.StatusTooManyRequests, Detail: "Too many attempts",
+			},
+		},
+		{
+			name:    "other error",
+			path:    "/users/user-1",
+			handler: func(c *fiber.Ctx) error { return assert.AnError },
+			want: models.AuditEvent{
+				Outcome: services.AuditOutcomeFailure, StatusCode: fiber.StatusInternalServerError, Detail: assert.AnError.Error(),
+			},
+		},
+		{
+			name: "long detail is truncated",
+			path: "/users/user-1",
+			handler: func(c *fiber.Ctx) error {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": strings.Repeat("x", 2*maxAuditDetail)})
+			},
+			want: models.AuditEvent{
+				Outcome: services.AuditOutcomeFailure, StatusCode: fiber.StatusBadRequest, Detail: strings.Repeat("x", maxAuditDetail),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := repository.NewMemoryAuditEventRepository()
+			app := fiber.New()
+			handlers := []fiber.Handler{Audit(services.NewAuditService(events), services.AuditRoleGrant, tt.targetType, tt.targetParam), tt.handler}
+			if tt.principal {
+				handlers = append([]fiber.Handler{signedIn}, handlers...)
+			}
+			app.Post("/users/:uid", handlers...)
+
+			req := httptest.NewRequest(fiber.MethodPost, tt.path, nil)
+			req.Header.Set(fiber.HeaderUserAgent, "test-agent")
+			_, err := app.Test(req)
+			require.NoError(t, err)
+
+			recorded, err := events.List(context.Background(), repository.AuditQuery{})
+			require.NoError(t, err)
+			require.Len(t, recorded, 1)
+			event := recorded[0]
+			assert.NotEmpty(t, event.ID)
+			assert.False(t, event.CreatedAt.IsZero())
+			assert.Equal(t, services.AuditRoleGrant, event.Action)
+			assert.Equal(t, "POST /users/:uid", event.Route)
+			assert.Equal(t, "test-agent", event.UserAgent)
+
+			// The rest of the event depends on the case
+			event.ID, event.Action, event.Route, event.UserAgent, event.IPAddress = "", "", "", "", ""
+			tt.want.CreatedAt = event.CreatedAt
+			assert.Equal(t, tt.want, event)
+		})
+	}
+}
+
+func TestAuditOutcome(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{fiber.StatusOK, services.AuditOutcomeSuccess},
+		{fiber.StatusFound, services.AuditOutcomeSuccess},
+		{fiber.StatusBadRequest, services.AuditOutcomeFailure},
+		{fiber.StatusUnauthorized, services.AuditOutcomeDenied},
+		{fiber.StatusForbidden, services.AuditOutcomeDenied},
+		{fiber.StatusNotFound, services.AuditOutcomeFailure},
+		{fiber.StatusLocked, services.AuditOutcomeDenied},
+		{fiber.StatusTooManyRequests, services.AuditOutcomeDenied},
+		{fiber.StatusInternalServerError, services.AuditOutcomeFailure},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, auditOutcome(tt.status), tt.status)
+	}
+}
@@ -1,63 +1,164 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
+	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
-// JWTSecret is the secret key used to sign the JWT tokens.
-// Ensure this key is kept private and retrieved securely (e.g., via environment variables).
-var JWTSecret = []byte("your-secure-jwt-secret")
+// principalKey is the fiber.Ctx locals key holding the authenticated Principal
+const principalKey = "principal"
 
-// AuthMiddleware validates JWT tokens and adds user claims to the context.
-func AuthMiddleware(c *fiber.Ctx) error {
-	// Extract the Authorization header
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UID       string
+	Email     string
+	Roles     []string
+	SessionID string
+	// TokenID is the personal access token of the request, empty for session tokens
+	TokenID string
+	// Scopes limit the routes a personal access token can call. Session tokens have none
+	// and can call every route.
+	Scopes []string
+}
+
+// HasRole reports whether the caller has the given role
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// requestToken reads the access token from the Authorization header,
+// or from the jwt cookie of browser clients
+func requestToken(c *fiber.Ctx) (string, error) {
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization header is required",
-		})
+		return c.Cookies("jwt"), nil
 	}
 
 	// Check if the Authorization header is in the correct format
 	parts := strings.Split(authHeader, " ")
 	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid Authorization header format",
-		})
+		return "", errors.New("Invalid Authorization header format")
+	}
+	return parts[1], nil
+}
+
+// insufficientScopeError is returned when a personal access token cannot call the route
+type insufficientScopeError struct {
+	scope string
+}
+
+func (e *insufficientScopeError) Error() string {
+	if e.scope == "" {
+		return "Personal access tokens cannot call this route"
 	}
+	return fmt.Sprintf("This token lacks the %s scope", e.scope)
+}
 
-	// Extract the token from the Authorization header
-	tokenString := parts[1]
+// authenticate verifies the token and stores the caller in the request context
+func authenticate(c *fiber.Ctx, tokenString string) error {
+	if strings.HasPrefix(tokenString, services.PersonalAccessTokenPrefix) {
+		return authenticatePersonalAccessToken(c, tokenString)
+	}
 
-	// Parse and validate the JWT token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Ensure the signing method is HMAC
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid signing method")
-		}
-		return JWTSecret, nil
+	claims, err := services.Tokens.Verify(tokenString)
+	if err != nil {
+		return err
+	}
+
+	c.Locals(principalKey, &Principal{
+		UID:       claims.UID,
+		Email:     claims.Email,
+		Roles:     claims.Roles,
+		SessionID: claims.SessionID,
+	})
+	return nil
+}
+
+// authenticatePersonalAccessToken verifies a personal access token and its scope for the route
+func authenticatePersonalAccessToken(c *fiber.Ctx, tokenString string) error {
+	token, user, err := services.Tokens.VerifyPersonalAccessToken(context.Background(), tokenString, c.IP())
+	if err != nil {
+		return err
+	}
+
+	scope, ok := services.RouteScope(c.Method(), c.Route().Path)
+	if !ok {
+		return &insufficientScopeError{}
+	}
+	if !services.ScopesAllow(token.Scopes, scope) {
+		return &insufficientScopeError{scope: scope}
+	}
+
+	c.Locals(principalKey, &Principal{
+		UID:     user.UID,
+		Email:   user.Email,
+		Roles:   user.Role,
+		TokenID: token.ID,
+		Scopes:  token.Scopes,
 	})
+	return nil
+}
 
-	// Handle token validation errors
-	if err != nil || !token.Valid {
+// Authenticate rejects requests without a valid access token and stores the caller in the context
+func Authenticate(c *fiber.Ctx) error {
+	tokenString, err := requestToken(c)
+	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid or expired token",
+			"error": err.Error(),
+		})
+	}
+	if tokenString == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authorization token is required",
 		})
 	}
 
-	// Extract claims and store them in the request context
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		// Optionally, you can perform additional validation on claims
-		c.Locals("user", claims)
-	} else {
+	if err := authenticate(c, tokenString); err != nil {
+		var scopeErr *insufficientScopeError
+		if errors.As(err, &scopeErr) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": scopeErr.Error(),
+				"code":  "insufficient_scope",
+				"scope": scopeErr.scope,
+			})
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token claims",
+			"error": "Invalid or expired token",
 		})
 	}
 
-	// Proceed to the next handler
 	return c.Next()
 }
+
+// OptionalAuthenticate stores the caller in the context when the request carries a valid
+// access token. Public routes use it to tailor their response to signed-in users.
+func OptionalAuthenticate(c *fiber.Ctx) error {
+	if tokenString, err := requestToken(c); err == nil && tokenString != "" {
+		_ = authenticate(c, tokenString)
+	}
+	return c.Next()
+}
+
+// CurrentPrincipal returns the authenticated caller, or nil for anonymous callers of public routes
+func CurrentPrincipal(c *fiber.Ctx) *Principal {
+	principal, _ := c.Locals(principalKey).(*Principal)
+	return principal
+}
+
+// CurrentUID returns the UID of the caller, or an empty string for anonymous callers
+func CurrentUID(c *fiber.Ctx) string {
+	if principal := CurrentPrincipal(c); principal != nil {
+		return principal.UID
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+// ResourceCheck reports whether a user may act on the resource with the given ID,
+// like AuthorizationService.CanManageProject
+type ResourceCheck func(ctx context.Context, uid, resourceID string) (bool, error)
+
+// RequirePermission allows the route only to callers whose roles grant the permission.
+// It must run after Authenticate.
+func RequirePermission(authz *services.AuthorizationService, permission services.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := CurrentPrincipal(c)
+		if principal == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Authorization token is required",
+			})
+		}
+
+		allowed, err := authz.HasPermission(context.Background(), principal.UID, permission)
+		if errors.Is(err, services.ErrTwoFactorRequired) {
+			return twoFactorRequired(c)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check permissions",
+			})
+		}
+		if !allowed {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": services.ErrForbidden.Error(),
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireResource allows the route only to callers that pass the check for the resource
+// named by the route parameter. It must run after Authenticate.
+func RequireResource(check ResourceCheck, param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := CurrentPrincipal(c)
+		if principal == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Authorization token is required",
+			})
+		}
+
+		resourceID := c.Params(param)
+		if resourceID == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Resource ID is required",
+			})
+		}
+
+		allowed, err := check(context.Background(), principal.UID, resourceID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Resource not found",
+			})
+		}
+		if errors.Is(err, services.ErrTwoFactorRequired) {
+			return twoFactorRequired(c)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check permissions",
+			})
+		}
+		if !allowed {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": services.ErrForbidden.Error(),
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// twoFactorRequired answers callers whose role needs a second factor they did not enable
+func twoFactorRequired(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error": services.ErrTwoFactorRequired.Error(),
+		"code":  "two_factor_required",
+	})
+}
@@ -19,7 +19,7 @@ var allowedOrigins = map[string]bool{
 // ConfigureCORS returns CORS middleware configuration for HTTP requests
 func ConfigureCORS() fiber.Handler {
 	return cors.New(cors.Config{
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Requested-With",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Requested-With, Last-Event-ID",
 		AllowMethods:     "GET, HEAD, PUT, PATCH, POST, DELETE, OPTIONS",
 		AllowCredentials: true,
 		ExposeHeaders:    "Content-Length, Authorization",
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+// RequireVerifiedEmail rejects callers that have not verified their email address
+// when REQUIRE_EMAIL_VERIFICATION is enabled. It must run after Authenticate.
+func RequireVerifiedEmail(verification *services.EmailVerificationService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !verification.Required() {
+			return c.Next()
+		}
+
+		principal := CurrentPrincipal(c)
+		if principal == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Authorization token is required",
+			})
+		}
+
+		verified, err := verification.IsVerified(context.Background(), principal.UID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check email verification",
+			})
+		}
+		if !verified {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": services.ErrEmailNotVerified.Error(),
+				"code":  "email_not_verified",
+			})
+		}
+
+		return c.Next()
+	}
+}
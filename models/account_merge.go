@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// AccountMerge records that an admin merged a user account into another one.
+// The source account no longer exists afterwards.
+type AccountMerge struct {
+	ID             string `json:"id" firestore:"id"`
+	SourceUID      string `json:"sourceUid" firestore:"source_uid"`
+	SourceEmail    string `json:"sourceEmail" firestore:"source_email"`
+	SourceUsername string `json:"sourceUsername" firestore:"source_username"`
+	TargetUID      string `json:"targetUid" firestore:"target_uid"`
+	MergedBy       string `json:"mergedBy" firestore:"merged_by"`
+	// Moved counts the records moved to the target account, by kind
+	Moved    map[string]int `json:"moved" firestore:"moved"`
+	MergedAt time.Time      `json:"mergedAt" firestore:"merged_at"`
+}
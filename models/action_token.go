@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ActionToken records a signed token mailed to a user, like an email verification
+// link. The record makes the token single-use.
+type ActionToken struct {
+	ID        string     `json:"id" firestore:"id"`
+	UID       string     `json:"uid" firestore:"uid"`
+	Email     string     `json:"email" firestore:"email"`
+	Purpose   string     `json:"purpose" firestore:"purpose"`
+	CreatedAt time.Time  `json:"createdAt" firestore:"created_at"`
+	ExpiresAt time.Time  `json:"expiresAt" firestore:"expires_at"`
+	UsedAt    *time.Time `json:"usedAt,omitempty" firestore:"used_at,omitempty"`
+	// Attempts counts the tries of the tokens that allow several, like login challenges
+	Attempts int `json:"attempts" firestore:"attempts"`
+}
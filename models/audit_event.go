@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// AuditEvent records a security relevant operation, like a login or a role change.
+// Audit events are never updated nor deleted.
+type AuditEvent struct {
+	ID string `json:"id" firestore:"id"`
+	// ActorUID is the user who performed the operation, empty when nobody was identified
+	ActorUID   string `json:"actorUid,omitempty" firestore:"actor_uid"`
+	ActorEmail string `json:"actorEmail,omitempty" firestore:"actor_email"`
+	// Action is what was done, like "auth.login" or "admin.role_grant"
+	Action     string `json:"action" firestore:"action"`
+	TargetType string `json:"targetType,omitempty" firestore:"target_type"`
+	TargetID   string `json:"targetId,omitempty" firestore:"target_id"`
+	// Route is the method and path of the request, like "POST /api/v1/auth/login"
+	Route     string `json:"route" firestore:"route"`
+	IPAddress string `json:"ipAddress" firestore:"ip_address"`
+	UserAgent string `json:"userAgent" firestore:"user_agent"`
+	// Outcome is "success", "failure" or "denied"
+	Outcome    string `json:"outcome" firestore:"outcome"`
+	StatusCode int    `json:"statusCode" firestore:"status_code"`
+	// Detail explains the outcome, like the error of a failure
+	Detail    string    `json:"detail,omitempty" firestore:"detail"`
+	CreatedAt time.Time `json:"createdAt" firestore:"created_at"`
+}
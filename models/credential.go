@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Credential is the password hash of a user of the local identity provider.
+// It is kept apart from the user document, which is shared with other users.
+type Credential struct {
+	UID string `json:"uid" firestore:"uid"`
+	// PasswordHash is an argon2id or bcrypt hash in its standard encoded form
+	PasswordHash string    `json:"passwordHash" firestore:"password_hash"`
+	UpdatedAt    time.Time `json:"updatedAt" firestore:"updated_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// DirectConversation indexes the direct messages exchanged by two users.
+// Its ID is the channel ID: both user IDs sorted and joined with "-".
+type DirectConversation struct {
+	ID           string                     `json:"id" firestore:"id"`
+	Participants []string                   `json:"participants" firestore:"participants"`
+	LastMessage  *DirectConversationPreview `json:"lastMessage,omitempty" firestore:"last_message,omitempty"`
+	UnreadCount  map[string]int             `json:"unreadCount" firestore:"unread_count"`
+	Muted        map[string]bool            `json:"muted" firestore:"muted"`
+	Archived     map[string]bool            `json:"archived" firestore:"archived"`
+	CreatedAt    time.Time                  `json:"createdAt" firestore:"created_at"`
+	UpdatedAt    time.Time                  `json:"updatedAt" firestore:"updated_at"`
+	// ReadSeq is the sequence number of the last message each participant read
+	ReadSeq map[string]int64 `json:"readSeq,omitempty" firestore:"read_seq"`
+}
+
+// DirectConversationPreview summarizes the last message of a conversation
+type DirectConversationPreview struct {
+	MessageID  string `json:"messageId" firestore:"message_id"`
+	SenderID   string `json:"senderId" firestore:"sender_id"`
+	SenderName string `json:"senderName" firestore:"sender_name"`
+	Content    string `json:"content" firestore:"content"`
+	CreatedAt  string `json:"createdAt" firestore:"created_at"`
+}
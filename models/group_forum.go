@@ -7,11 +7,11 @@ type Group struct {
 	Name          string        `json:"name" gorm:"uniqueIndex"`
 	Description   string        `json:"description"`
 	ImageURL      string        `json:"imageUrl"`
-	Category      GroupCategory `json:"category" gorm:"embedded"`
+	Category      GroupCategory `json:"category" gorm:"embedded;embeddedPrefix:category_"`
 	ActivityLevel string        `json:"activityLevel"`
 	CreatedAt     time.Time     `json:"createdAt"`
 	UpdatedAt     time.Time     `json:"updatedAt"`
-	Members       []Member      `json:"members" gorm:"many2many:group_members;"`
+	Members       []Member      `json:"members" gorm:"foreignKey:GroupID"`
 	Topics        []Topic       `json:"topics" gorm:"many2many:group_topics;"`
 	Events        []Event       `json:"events" gorm:"foreignKey:GroupID"`
 	Resources     []Resource    `json:"resources" gorm:"foreignKey:GroupID"`
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// LoginThrottle counts the failed logins of an account or of an IP address
+type LoginThrottle struct {
+	ID string `json:"id" firestore:"id"`
+	// Kind is "account" or "ip"
+	Kind string `json:"kind" firestore:"kind"`
+	// Subject is the UID of the account, the identifier used for unknown accounts, or the IP address
+	Subject string `json:"subject" firestore:"subject"`
+	// Failures counts the failed logins since the last success, lockout or quiet period
+	Failures      int       `json:"failures" firestore:"failures"`
+	LastFailureAt time.Time `json:"lastFailureAt" firestore:"last_failure_at"`
+	LastFailureIP string    `json:"lastFailureIp" firestore:"last_failure_ip"`
+	// Lockouts counts the lockouts in a row; each one lasts longer than the previous
+	Lockouts    int        `json:"lockouts" firestore:"lockouts"`
+	LockedUntil *time.Time `json:"lockedUntil,omitempty" firestore:"locked_until,omitempty"`
+	// ExpiresAt is when the record can be purged
+	ExpiresAt time.Time `json:"expiresAt" firestore:"expires_at"`
+}
@@ -49,21 +49,24 @@ type Messages struct {
 }
 
 type GroupChat struct {
-	ID             string          `json:"id" firestore:"id"`
-	ProjectID      string          `json:"projectId" firestore:"project_id"`
-	CreatedByUID   string          `json:"createdByUid" firestore:"created_by_uid"`
-	CreatedByName  string          `json:"createdByName" firestore:"created_by_name"`
-	Name           string          `json:"name" firestore:"name"`
-	Description    string          `json:"description,omitempty" firestore:"description,omitempty"`
-	Participants   []Participant   `json:"participants" firestore:"participants"`
-	Messages       []BaseMessage   `json:"messages" firestore:"messages"`
-	PinnedMessages []string        `json:"pinnedMessages,omitempty" firestore:"pinned_messages,omitempty"`
-	IsArchived     bool            `json:"isArchived" firestore:"is_archived"`
-	Notifications  map[string]bool `json:"notifications" firestore:"notifications"`
-	CreatedAt      time.Time       `json:"createdAt" firestore:"created_at"`
-	UpdatedAt      time.Time       `json:"updatedAt" firestore:"updated_at"`
-	ReadStatus     map[string]bool `json:"readStatus" firestore:"read_status"`
-	GroupSettings  GroupSettings   `json:"groupSettings" firestore:"group_settings"`
+	ID             string               `json:"id" firestore:"id"`
+	ProjectID      string               `json:"projectId" firestore:"project_id"`
+	CreatedByUID   string               `json:"createdByUid" firestore:"created_by_uid"`
+	CreatedByName  string               `json:"createdByName" firestore:"created_by_name"`
+	Name           string               `json:"name" firestore:"name"`
+	Description    string               `json:"description,omitempty" firestore:"description,omitempty"`
+	Participants   []Participant        `json:"participants" firestore:"participants"`
+	Messages       []BaseMessage        `json:"messages" firestore:"messages"`
+	PinnedMessages []string             `json:"pinnedMessages,omitempty" firestore:"pinned_messages,omitempty"`
+	IsArchived     bool                 `json:"isArchived" firestore:"is_archived"`
+	Notifications  map[string]bool      `json:"notifications" firestore:"notifications"`
+	CreatedAt      time.Time            `json:"createdAt" firestore:"created_at"`
+	UpdatedAt      time.Time            `json:"updatedAt" firestore:"updated_at"`
+	ReadStatus     map[string]bool      `json:"readStatus" firestore:"read_status"`
+	GroupSettings  GroupSettings        `json:"groupSettings" firestore:"group_settings"`
+	Polls          []Poll               `json:"polls,omitempty" firestore:"polls,omitempty"`
+	Reports        []Report             `json:"reports,omitempty" firestore:"reports,omitempty"`
+	LastSeen       map[string]time.Time `json:"lastSeen,omitempty" firestore:"last_seen,omitempty"`
 }
 
 type GroupSettings struct {
@@ -74,3 +77,9 @@ type GroupSettings struct {
 	MuteNotifications bool `json:"muteNotifications" firestore:"mute_notifications"`
 	OnlyAdminsCanPost bool `json:"onlyAdminsCanPost" firestore:"only_admins_can_post"`
 }
+
+// TypingIndicator tells the participants of a conversation that a user started or stopped typing
+type TypingIndicator struct {
+	SenderID string `json:"senderId"`
+	Typing   bool   `json:"typing"`
+}
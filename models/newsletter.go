@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// NewsletterSubscription is an email address subscribed to the newsletter
+type NewsletterSubscription struct {
+	ID           string    `json:"id"`
+	Email        string    `json:"email"`
+	SubscribedAt time.Time `json:"subscribedAt"`
+}
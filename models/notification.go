@@ -159,3 +159,12 @@ func (nt NotificationType) IsSMS() bool {
 func (nt NotificationType) IsEmail() bool {
 	return nt == NotificationTypeEmail
 }
+
+// NotificationChange announces to a recipient that a notification was read, updated or deleted
+type NotificationChange struct {
+	NotificationID string `json:"notificationId"`
+	// Change is "read", "updated" or "deleted"
+	Change string `json:"change"`
+	// Notification is the notification as the recipient sees it, unless it was deleted
+	Notification *Notification `json:"notification,omitempty"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// OAuthState is an authorization started with an OAuth provider. It keeps the PKCE
+// verifier and the nonce on the server and can be completed once.
+type OAuthState struct {
+	// ID is the "state" parameter sent to the provider
+	ID           string `json:"id" firestore:"id"`
+	Provider     string `json:"provider" firestore:"provider"`
+	CodeVerifier string `json:"codeVerifier" firestore:"code_verifier"`
+	Nonce        string `json:"nonce" firestore:"nonce"`
+	// LinkUID is the user the identity is linked to, empty when signing in
+	LinkUID   string     `json:"linkUid" firestore:"link_uid"`
+	CreatedAt time.Time  `json:"createdAt" firestore:"created_at"`
+	ExpiresAt time.Time  `json:"expiresAt" firestore:"expires_at"`
+	UsedAt    *time.Time `json:"usedAt,omitempty" firestore:"used_at,omitempty"`
+}
+
+// Identity links an account at an external provider, like Google, to a user
+type Identity struct {
+	// ID is made of the provider and the subject, see services.IdentityID
+	ID       string `json:"id" firestore:"id"`
+	UID      string `json:"uid" firestore:"uid"`
+	Provider string `json:"provider" firestore:"provider"`
+	// Subject is the stable ID of the account at the provider
+	Subject       string    `json:"subject" firestore:"subject"`
+	Email         string    `json:"email" firestore:"email"`
+	EmailVerified bool      `json:"emailVerified" firestore:"email_verified"`
+	LinkedAt      time.Time `json:"linkedAt" firestore:"linked_at"`
+	LastLoginAt   time.Time `json:"lastLoginAt" firestore:"last_login_at"`
+}
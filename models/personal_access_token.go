@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// PersonalAccessToken lets scripts and integrations call the API on behalf of a user,
+// limited to its scopes. Only the hash of the token is stored.
+type PersonalAccessToken struct {
+	ID        string   `json:"id" firestore:"id"`
+	UID       string   `json:"uid" firestore:"uid"`
+	Name      string   `json:"name" firestore:"name"`
+	Scopes    []string `json:"scopes" firestore:"scopes"`
+	TokenHash string   `json:"tokenHash" firestore:"token_hash"`
+	// Hint is the end of the token, to tell the tokens of a user apart
+	Hint       string     `json:"hint" firestore:"hint"`
+	CreatedAt  time.Time  `json:"createdAt" firestore:"created_at"`
+	ExpiresAt  time.Time  `json:"expiresAt" firestore:"expires_at"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" firestore:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"lastUsedIp,omitempty" firestore:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" firestore:"revoked_at,omitempty"`
+}
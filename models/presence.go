@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Presence is the online state of a user, worked out from the realtime connections of
+// their devices
+type Presence struct {
+	UID string `json:"uid" firestore:"uid"`
+	// Status is "online", "idle", "away" or "offline": the most present state of the devices
+	Status string `json:"status" firestore:"status"`
+	// Devices holds the open realtime connections of the user, by connection ID
+	Devices map[string]PresenceDevice `json:"devices" firestore:"devices"`
+	// LastSeenAt is the last heartbeat or disconnection of any device of the user
+	LastSeenAt time.Time `json:"lastSeenAt" firestore:"last_seen_at"`
+	// Hidden users appear offline to the others, without a last-seen time
+	Hidden    bool      `json:"hidden" firestore:"hidden"`
+	UpdatedAt time.Time `json:"updatedAt" firestore:"updated_at"`
+}
+
+// PresenceDevice is an open realtime connection of a user
+type PresenceDevice struct {
+	ConnectionID string `json:"connectionId" firestore:"connection_id"`
+	// SessionID is the session whose access token opened the connection
+	SessionID string `json:"sessionId" firestore:"session_id"`
+	// Device names the device, as given by the client, like "Pixel 8"
+	Device    string `json:"device" firestore:"device"`
+	UserAgent string `json:"userAgent" firestore:"user_agent"`
+	IPAddress string `json:"ipAddress" firestore:"ip_address"`
+	// State is "online", "idle" or "away", as last reported by the client
+	State       string    `json:"state" firestore:"state"`
+	ConnectedAt time.Time `json:"connectedAt" firestore:"connected_at"`
+	HeartbeatAt time.Time `json:"heartbeatAt" firestore:"heartbeat_at"`
+}
+
+// PresenceStatus is the presence of a user as the other users see it
+type PresenceStatus struct {
+	UID        string     `json:"uid"`
+	Status     string     `json:"status"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ReadPosition is how far a user has read a group chat: every message up to and including
+// MessageID
+type ReadPosition struct {
+	ChatID    string `json:"chatId" firestore:"chat_id"`
+	UserID    string `json:"userId" firestore:"user_id"`
+	MessageID string `json:"messageId" firestore:"message_id"`
+	// Seq is the position of the message in the chat history, starting at 1
+	Seq    int64     `json:"seq" firestore:"seq"`
+	ReadAt time.Time `json:"readAt" firestore:"read_at"`
+}
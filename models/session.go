@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Session is a signed-in device. It owns the refresh token that keeps the device
+// signed in; only a hash of the token is stored.
+type Session struct {
+	ID    string `json:"id" firestore:"id"`
+	UID   string `json:"uid" firestore:"uid"`
+	Email string `json:"email" firestore:"email"`
+	// Roles are copied into the access tokens of the session
+	Roles            []string `json:"roles" firestore:"roles"`
+	RefreshTokenHash string   `json:"refreshTokenHash" firestore:"refresh_token_hash"`
+	// PreviousTokenHashes are the rotated refresh tokens, kept to detect their reuse
+	PreviousTokenHashes []string   `json:"previousTokenHashes" firestore:"previous_token_hashes"`
+	UserAgent           string     `json:"userAgent" firestore:"user_agent"`
+	IPAddress           string     `json:"ipAddress" firestore:"ip_address"`
+	CreatedAt           time.Time  `json:"createdAt" firestore:"created_at"`
+	LastUsedAt          time.Time  `json:"lastUsedAt" firestore:"last_used_at"`
+	ExpiresAt           time.Time  `json:"expiresAt" firestore:"expires_at"`
+	RevokedAt           *time.Time `json:"revokedAt,omitempty" firestore:"revoked_at,omitempty"`
+	RevokedReason       string     `json:"revokedReason,omitempty" firestore:"revoked_reason,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// TwoFactor is the TOTP enrollment of a user
+type TwoFactor struct {
+	UID string `json:"uid" firestore:"uid"`
+	// Secret is the base32 TOTP secret, set once the enrollment is confirmed
+	Secret string `json:"secret" firestore:"secret"`
+	// PendingSecret is the secret of an enrollment waiting for its first code
+	PendingSecret string `json:"pendingSecret" firestore:"pending_secret"`
+	Enabled       bool   `json:"enabled" firestore:"enabled"`
+	// RecoveryCodeHashes are the hashes of the unused recovery codes
+	RecoveryCodeHashes []string `json:"recoveryCodeHashes" firestore:"recovery_code_hashes"`
+	// LastUsedStep is the time step of the last accepted code, which cannot be used again
+	LastUsedStep int64      `json:"lastUsedStep" firestore:"last_used_step"`
+	EnabledAt    *time.Time `json:"enabledAt,omitempty" firestore:"enabled_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updatedAt" firestore:"updated_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// UnreadCountDelta changes one of the unread counters of a user, so that clients keep their
+// badges up to date without fetching the counts again
+type UnreadCountDelta struct {
+	// Kind is the counter: "notifications", "direct-messages" or "group-chats"
+	Kind string `json:"kind"`
+	// ConversationID is the direct conversation or the group chat whose count changed
+	ConversationID string `json:"conversationId,omitempty"`
+	Delta          int    `json:"delta"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// UploadRecord records a file uploaded to Cloudinary
+type UploadRecord struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Filename   string    `json:"filename"`
+	Type       string    `json:"type"`
+	UploadedAt time.Time `json:"uploadedAt"`
+}
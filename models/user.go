@@ -4,7 +4,7 @@ import "time"
 
 // User represents the user model for the application
 type User struct {
-	UID              string   `json:"uid"`
+	UID              string   `json:"uid" gorm:"primaryKey"`
 	Username         string   `json:"username"`
 	FirstName        string   `json:"first_name"`
 	LastName         string   `json:"last_name"`
@@ -12,25 +12,25 @@ type User struct {
 	PhoneNumber      string   `json:"phone_number"`
 	ProfilePicture   string   `json:"profile_picture"`
 	Bio              string   `json:"bio"`
-	Role             []string `json:"role"`
+	Role             []string `json:"role" gorm:"serializer:json"`
 	GraduationYear   int      `json:"graduation_year"`
 	CurrentJobTitle  string   `json:"current_job_title"`
-	AreasOfExpertise []string `json:"areas_of_expertise"`
-	Interests        []string `json:"interests"`
+	AreasOfExpertise []string `json:"areas_of_expertise" gorm:"serializer:json"`
+	Interests        []string `json:"interests" gorm:"serializer:json"`
 	LookingForMentor bool     `json:"looking_for_mentor"`
 	WillingToMentor  bool     `json:"willing_to_mentor"`
 	ConnectionsMade  int      `json:"connections_made"`
 	AccountCreatedAt string   `json:"account_creation_date"`
 	IsActive         bool     `json:"is_active"`
 	IsVerified       bool     `json:"is_verified"`
-	Password         string   `json:"password"`
+	Password         string   `json:"password" gorm:"-"`
 	Program          string   `json:"program"`
 	DateOfBirth      string   `json:"date_of_birth"`
 	PhoneCode        string   `json:"phone_code"`
-	Languages        []string `json:"languages"`
-	Skills           []string `json:"skills"`
-	Certifications   []string `json:"certifications"`
-	Projects         []string `json:"projects"`
+	Languages        []string `json:"languages" gorm:"serializer:json"`
+	Skills           []string `json:"skills" gorm:"serializer:json"`
+	Certifications   []string `json:"certifications" gorm:"serializer:json"`
+	Projects         []string `json:"projects" gorm:"serializer:json"`
 	IsOnline         bool     `json:"is_online"`
 	IsPrivate        bool     `json:"is_private"`
 }
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreAccountMergeRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreAccountMergeRepository creates an AccountMergeRepository backed by the "account_merges" collection
+func NewFirestoreAccountMergeRepository(client FirestoreClient) AccountMergeRepository {
+	return &firestoreAccountMergeRepository{client: client}
+}
+
+func (r *firestoreAccountMergeRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("account_merges")
+}
+
+func decodeAccountMerge(doc *firestore.DocumentSnapshot) models.AccountMerge {
+	return mappers.MapAccountMergeFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreAccountMergeRepository) Create(ctx context.Context, merge *models.AccountMerge) error {
+	_, err := r.collection().Doc(merge.ID).Create(ctx, mappers.MapAccountMergeGoToFirestore(*merge))
+	return err
+}
+
+func (r *firestoreAccountMergeRepository) ListByTarget(ctx context.Context, targetUID string) ([]models.AccountMerge, error) {
+	query := r.collection().Where("target_uid", "==", targetUID).OrderBy("merged_at", firestore.Asc)
+	return queryDocuments(ctx, query, decodeAccountMerge)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryAccountMergeRepository struct {
+	store *memoryStore[models.AccountMerge]
+}
+
+// NewMemoryAccountMergeRepository creates an in-memory AccountMergeRepository
+func NewMemoryAccountMergeRepository() AccountMergeRepository {
+	return &memoryAccountMergeRepository{store: newMemoryStore[models.AccountMerge]()}
+}
+
+func (r *memoryAccountMergeRepository) Create(ctx context.Context, merge *models.AccountMerge) error {
+	r.store.put(merge.ID, *merge)
+	return nil
+}
+
+func (r *memoryAccountMergeRepository) ListByTarget(ctx context.Context, targetUID string) ([]models.AccountMerge, error) {
+	merges := r.store.list(func(merge models.AccountMerge) bool {
+		return merge.TargetUID == targetUID
+	})
+	sort.SliceStable(merges, func(i, j int) bool {
+		return merges[i].MergedAt.Before(merges[j].MergedAt)
+	})
+	return merges, nil
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// AccountMergeRepository stores the records of the account merges
+type AccountMergeRepository interface {
+	Create(ctx context.Context, merge *models.AccountMerge) error
+	// ListByTarget lists the merges into the user, oldest first
+	ListByTarget(ctx context.Context, targetUID string) ([]models.AccountMerge, error)
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreActionTokenRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreActionTokenRepository creates an ActionTokenRepository backed by the "action_tokens"
+// collection. A Firestore TTL policy on "expires_at" can purge the expired tokens.
+func NewFirestoreActionTokenRepository(client FirestoreClient) ActionTokenRepository {
+	return &firestoreActionTokenRepository{client: client}
+}
+
+func (r *firestoreActionTokenRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("action_tokens")
+}
+
+func decodeActionToken(doc *firestore.DocumentSnapshot) models.ActionToken {
+	return mappers.MapActionTokenFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreActionTokenRepository) Create(ctx context.Context, token *models.ActionToken) error {
+	_, err := r.collection().Doc(token.ID).Create(ctx, mappers.MapActionTokenGoToFirestore(*token))
+	return err
+}
+
+func (r *firestoreActionTokenRepository) Get(ctx context.Context, tokenID string) (*models.ActionToken, error) {
+	return getDocument(ctx, r.collection().Doc(tokenID), mappers.MapActionTokenFirestoreToGo)
+}
+
+func (r *firestoreActionTokenRepository) Update(ctx context.Context, tokenID string, fn func(*models.ActionToken) error) (*models.ActionToken, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(tokenID),
+		mappers.MapActionTokenFirestoreToGo, mappers.MapActionTokenGoToFirestore, fn)
+}
+
+func (r *firestoreActionTokenRepository) ListByUser(ctx context.Context, uid, purpose string) ([]models.ActionToken, error) {
+	query := r.collection().Where("uid", "==", uid).Where("purpose", "==", purpose)
+	return queryDocuments(ctx, query, decodeActionToken)
+}
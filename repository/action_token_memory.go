@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryActionTokenRepository struct {
+	store *memoryStore[models.ActionToken]
+}
+
+// NewMemoryActionTokenRepository creates an in-memory ActionTokenRepository
+func NewMemoryActionTokenRepository() ActionTokenRepository {
+	return &memoryActionTokenRepository{store: newMemoryStore[models.ActionToken]()}
+}
+
+func (r *memoryActionTokenRepository) Create(ctx context.Context, token *models.ActionToken) error {
+	r.store.put(token.ID, *token)
+	return nil
+}
+
+func (r *memoryActionTokenRepository) Get(ctx context.Context, tokenID string) (*models.ActionToken, error) {
+	return r.store.get(tokenID)
+}
+
+func (r *memoryActionTokenRepository) Update(ctx context.Context, tokenID string, fn func(*models.ActionToken) error) (*models.ActionToken, error) {
+	return r.store.update(tokenID, fn)
+}
+
+func (r *memoryActionTokenRepository) ListByUser(ctx context.Context, uid, purpose string) ([]models.ActionToken, error) {
+	return r.store.list(func(token models.ActionToken) bool {
+		return token.UID == uid && token.Purpose == purpose
+	}), nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// ActionTokenRepository stores the tokens mailed to the users
+type ActionTokenRepository interface {
+	Create(ctx context.Context, token *models.ActionToken) error
+	Get(ctx context.Context, tokenID string) (*models.ActionToken, error)
+	// Update atomically applies fn to the stored token and saves the result
+	Update(ctx context.Context, tokenID string, fn func(*models.ActionToken) error) (*models.ActionToken, error)
+	// ListByUser lists the tokens issued to the user for the purpose, used ones included
+	ListByUser(ctx context.Context, uid, purpose string) ([]models.ActionToken, error)
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreAddressRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreAddressRepository creates an AddressRepository backed by the "user_addresses" collection
+func NewFirestoreAddressRepository(client FirestoreClient) AddressRepository {
+	return &firestoreAddressRepository{client: client}
+}
+
+func (r *firestoreAddressRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("user_addresses")
+}
+
+// decodeAddress takes the ID from the document name, as early addresses were added without it
+func decodeAddress(doc *firestore.DocumentSnapshot) models.UserAddress {
+	address := mappers.MapBackendToUserAddress(doc.Data())
+	address.ID = doc.Ref.ID
+	return address
+}
+
+func (r *firestoreAddressRepository) Create(ctx context.Context, address *models.UserAddress) error {
+	_, err := r.collection().Doc(address.ID).Set(ctx, mappers.MapUserAddressToBackend(*address))
+	return err
+}
+
+func (r *firestoreAddressRepository) Get(ctx context.Context, addressID string) (*models.UserAddress, error) {
+	address, err := getDocument(ctx, r.collection().Doc(addressID), mappers.MapBackendToUserAddress)
+	if err != nil {
+		return nil, err
+	}
+	address.ID = addressID
+	return address, nil
+}
+
+func (r *firestoreAddressRepository) Update(ctx context.Context, addressID string, fn func(*models.UserAddress) error) (*models.UserAddress, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(addressID),
+		mappers.MapBackendToUserAddress, mappers.MapUserAddressToBackend, func(address *models.UserAddress) error {
+			address.ID = addressID
+			return fn(address)
+		})
+}
+
+func (r *firestoreAddressRepository) Delete(ctx context.Context, addressID string) error {
+	_, err := r.collection().Doc(addressID).Delete(ctx)
+	return err
+}
+
+func (r *firestoreAddressRepository) ListByUser(ctx context.Context, uid string) ([]models.UserAddress, error) {
+	return queryDocuments(ctx, r.collection().Where("uid", "==", uid), decodeAddress)
+}
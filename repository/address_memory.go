@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryAddressRepository struct {
+	store *memoryStore[models.UserAddress]
+}
+
+// NewMemoryAddressRepository creates an in-memory AddressRepository
+func NewMemoryAddressRepository() AddressRepository {
+	return &memoryAddressRepository{store: newMemoryStore[models.UserAddress]()}
+}
+
+func (r *memoryAddressRepository) Create(ctx context.Context, address *models.UserAddress) error {
+	r.store.put(address.ID, *address)
+	return nil
+}
+
+func (r *memoryAddressRepository) Get(ctx context.Context, addressID string) (*models.UserAddress, error) {
+	return r.store.get(addressID)
+}
+
+func (r *memoryAddressRepository) Update(ctx context.Context, addressID string, fn func(*models.UserAddress) error) (*models.UserAddress, error) {
+	return r.store.update(addressID, fn)
+}
+
+func (r *memoryAddressRepository) Delete(ctx context.Context, addressID string) error {
+	return r.store.delete(addressID)
+}
+
+func (r *memoryAddressRepository) ListByUser(ctx context.Context, uid string) ([]models.UserAddress, error) {
+	return r.store.list(func(address models.UserAddress) bool { return address.UID == uid }), nil
+}
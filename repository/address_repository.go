@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// AddressRepository stores the postal addresses of the users
+type AddressRepository interface {
+	Create(ctx context.Context, address *models.UserAddress) error
+	Get(ctx context.Context, addressID string) (*models.UserAddress, error)
+	// Update atomically applies fn to the stored address and saves the result
+	Update(ctx context.Context, addressID string, fn func(*models.UserAddress) error) (*models.UserAddress, error)
+	Delete(ctx context.Context, addressID string) error
+	ListByUser(ctx context.Context, uid string) ([]models.UserAddress, error)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreAuditEventRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreAuditEventRepository creates an AuditEventRepository backed by the "audit_log" collection.
+// Security rules must keep clients from reading and writing it.
+func NewFirestoreAuditEventRepository(client FirestoreClient) AuditEventRepository {
+	return &firestoreAuditEventRepository{client: client}
+}
+
+func (r *firestoreAuditEventRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("audit_log")
+}
+
+func decodeAuditEvent(doc *firestore.DocumentSnapshot) models.AuditEvent {
+	return mappers.MapAuditEventFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreAuditEventRepository) Append(ctx context.Context, event *models.AuditEvent) error {
+	// Create fails when the ID exists, so that no event is ever overwritten
+	_, err := r.collection().Doc(event.ID).Create(ctx, mappers.MapAuditEventGoToFirestore(*event))
+	return err
+}
+
+func (r *firestoreAuditEventRepository) List(ctx context.Context, query AuditQuery) ([]models.AuditEvent, error) {
+	q := r.collection().Query
+	if query.ActorUID != "" {
+		q = q.Where("actor_uid", "==", query.ActorUID)
+	}
+	if query.Action != "" {
+		q = q.Where("action", "==", query.Action)
+	}
+	if query.TargetID != "" {
+		q = q.Where("target_id", "==", query.TargetID)
+	}
+	if query.Outcome != "" {
+		q = q.Where("outcome", "==", query.Outcome)
+	}
+	if !query.Since.IsZero() {
+		q = q.Where("created_at", ">=", query.Since)
+	}
+	if !query.Until.IsZero() {
+		q = q.Where("created_at", "<", query.Until)
+	}
+	q = q.OrderBy("created_at", firestore.Desc)
+
+	if query.After != "" {
+		last, err := r.collection().Doc(query.After).Get(ctx)
+		if err != nil {
+			if isNotFound(err) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		q = q.StartAfter(last)
+	}
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit)
+	}
+
+	return queryDocuments(ctx, q, decodeAuditEvent)
+}
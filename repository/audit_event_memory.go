@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryAuditEventRepository struct {
+	store *memoryStore[models.AuditEvent]
+}
+
+// NewMemoryAuditEventRepository creates an in-memory AuditEventRepository
+func NewMemoryAuditEventRepository() AuditEventRepository {
+	return &memoryAuditEventRepository{store: newMemoryStore[models.AuditEvent]()}
+}
+
+func (r *memoryAuditEventRepository) Append(ctx context.Context, event *models.AuditEvent) error {
+	r.store.put(event.ID, *event)
+	return nil
+}
+
+func (r *memoryAuditEventRepository) List(ctx context.Context, query AuditQuery) ([]models.AuditEvent, error) {
+	events := r.store.list(func(event models.AuditEvent) bool {
+		return (query.ActorUID == "" || event.ActorUID == query.ActorUID) &&
+			(query.Action == "" || event.Action == query.Action) &&
+			(query.TargetID == "" || event.TargetID == query.TargetID) &&
+			(query.Outcome == "" || event.Outcome == query.Outcome) &&
+			(query.Since.IsZero() || !event.CreatedAt.Before(query.Since)) &&
+			(query.Until.IsZero() || event.CreatedAt.Before(query.Until))
+	})
+
+	// Newest first; events appended in the same instant keep their reverse insertion order
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+
+	if query.After != "" {
+		start := -1
+		for i, event := range events {
+			if event.ID == query.After {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, ErrNotFound
+		}
+		events = events[start:]
+	}
+
+	if query.Limit > 0 && len(events) > query.Limit {
+		events = events[:query.Limit]
+	}
+	return events, nil
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// AuditQuery filters and pages the audit log. Empty fields do not filter.
+type AuditQuery struct {
+	ActorUID string
+	Action   string
+	TargetID string
+	Outcome  string
+	// Since and Until bound the creation time, Until excluded
+	Since time.Time
+	Until time.Time
+	// After is the ID of the last event of the previous page
+	After string
+	Limit int
+}
+
+// AuditEventRepository stores the audit log. It is append-only: events cannot be changed nor removed.
+type AuditEventRepository interface {
+	Append(ctx context.Context, event *models.AuditEvent) error
+	// List returns the events matching the query, newest first
+	List(ctx context.Context, query AuditQuery) ([]models.AuditEvent, error)
+}
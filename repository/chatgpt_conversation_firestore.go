@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreChatGPTConversationRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreChatGPTConversationRepository creates a ChatGPTConversationRepository backed by
+// the "chatgpt_conversations" collection
+func NewFirestoreChatGPTConversationRepository(client FirestoreClient) ChatGPTConversationRepository {
+	return &firestoreChatGPTConversationRepository{client: client}
+}
+
+func (r *firestoreChatGPTConversationRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("chatgpt_conversations")
+}
+
+func decodeChatGPTConversation(doc *firestore.DocumentSnapshot) models.Conversation {
+	return mappers.MapConversationFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreChatGPTConversationRepository) Create(ctx context.Context, conversation *models.Conversation) error {
+	_, err := r.collection().Doc(conversation.ID).Set(ctx, mappers.MapConversationGoToFirestore(*conversation))
+	return err
+}
+
+func (r *firestoreChatGPTConversationRepository) Get(ctx context.Context, conversationID string) (*models.Conversation, error) {
+	return getDocument(ctx, r.collection().Doc(conversationID), mappers.MapConversationFirestoreToGo)
+}
+
+func (r *firestoreChatGPTConversationRepository) Update(ctx context.Context, conversationID string, fn func(*models.Conversation) error) (*models.Conversation, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(conversationID),
+		mappers.MapConversationFirestoreToGo, mappers.MapConversationGoToFirestore, fn)
+}
+
+func (r *firestoreChatGPTConversationRepository) Delete(ctx context.Context, conversationID string) error {
+	_, err := r.collection().Doc(conversationID).Delete(ctx)
+	return err
+}
+
+func (r *firestoreChatGPTConversationRepository) ListByUser(ctx context.Context, userID string) ([]models.Conversation, error) {
+	return queryDocuments(ctx, r.collection().Where("user_id", "==", userID), decodeChatGPTConversation)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryChatGPTConversationRepository struct {
+	store *memoryStore[models.Conversation]
+}
+
+// NewMemoryChatGPTConversationRepository creates an in-memory ChatGPTConversationRepository
+func NewMemoryChatGPTConversationRepository() ChatGPTConversationRepository {
+	return &memoryChatGPTConversationRepository{store: newMemoryStore[models.Conversation]()}
+}
+
+func (r *memoryChatGPTConversationRepository) Create(ctx context.Context, conversation *models.Conversation) error {
+	r.store.put(conversation.ID, *conversation)
+	return nil
+}
+
+func (r *memoryChatGPTConversationRepository) Get(ctx context.Context, conversationID string) (*models.Conversation, error) {
+	return r.store.get(conversationID)
+}
+
+func (r *memoryChatGPTConversationRepository) Update(ctx context.Context, conversationID string, fn func(*models.Conversation) error) (*models.Conversation, error) {
+	return r.store.update(conversationID, fn)
+}
+
+func (r *memoryChatGPTConversationRepository) Delete(ctx context.Context, conversationID string) error {
+	return r.store.delete(conversationID)
+}
+
+func (r *memoryChatGPTConversationRepository) ListByUser(ctx context.Context, userID string) ([]models.Conversation, error) {
+	return r.store.list(func(conversation models.Conversation) bool { return conversation.UserID == userID }), nil
+}
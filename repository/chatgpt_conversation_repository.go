@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// ChatGPTConversationRepository stores the conversations of the users with the assistant
+type ChatGPTConversationRepository interface {
+	Create(ctx context.Context, conversation *models.Conversation) error
+	Get(ctx context.Context, conversationID string) (*models.Conversation, error)
+	// Update atomically applies fn to the stored conversation and saves the result
+	Update(ctx context.Context, conversationID string, fn func(*models.Conversation) error) (*models.Conversation, error)
+	Delete(ctx context.Context, conversationID string) error
+	ListByUser(ctx context.Context, userID string) ([]models.Conversation, error)
+}
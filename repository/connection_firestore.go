@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreConnectionRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreConnectionRepository creates a ConnectionRepository backed by the
+// "user_connections" collection, where the record of a user is found by its "uid" field
+func NewFirestoreConnectionRepository(client FirestoreClient) ConnectionRepository {
+	return &firestoreConnectionRepository{client: client}
+}
+
+func (r *firestoreConnectionRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("user_connections")
+}
+
+func (r *firestoreConnectionRepository) byUser(uid string) firestore.Query {
+	return r.collection().Where("uid", "==", uid).Limit(1)
+}
+
+func decodeConnections(doc *firestore.DocumentSnapshot) models.UserConnections {
+	connections := mappers.MapConnectionsFirestoreToGo(doc.Data())
+	connections.ID = doc.Ref.ID
+	return connections
+}
+
+func (r *firestoreConnectionRepository) GetByUser(ctx context.Context, uid string) (*models.UserConnections, error) {
+	records, err := queryDocuments(ctx, r.byUser(uid), decodeConnections)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrNotFound
+	}
+	return &records[0], nil
+}
+
+func (r *firestoreConnectionRepository) Create(ctx context.Context, connections *models.UserConnections) error {
+	_, err := r.collection().Doc(connections.ID).Set(ctx, mappers.MapConnectionsGoToFirestore(*connections))
+	return err
+}
+
+func (r *firestoreConnectionRepository) Update(ctx context.Context, uids []string, fn func(map[string]*models.UserConnections) error) error {
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		records := make(map[string]*models.UserConnections, len(uids))
+		for _, uid := range uids {
+			if _, ok := records[uid]; ok {
+				continue
+			}
+			docs, err := tx.Documents(r.byUser(uid)).GetAll()
+			if err != nil {
+				return err
+			}
+
+			connections := newUserConnections(uid)
+			if len(docs) > 0 {
+				connections = decodeConnections(docs[0])
+			}
+			records[uid] = &connections
+		}
+
+		if err := fn(records); err != nil {
+			return err
+		}
+
+		for _, connections := range records {
+			if err := tx.Set(r.collection().Doc(connections.ID), mappers.MapConnectionsGoToFirestore(*connections)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryConnectionRepository struct {
+	store *memoryStore[models.UserConnections]
+}
+
+// NewMemoryConnectionRepository creates an in-memory ConnectionRepository
+func NewMemoryConnectionRepository() ConnectionRepository {
+	return &memoryConnectionRepository{store: newMemoryStore[models.UserConnections]()}
+}
+
+func (r *memoryConnectionRepository) GetByUser(ctx context.Context, uid string) (*models.UserConnections, error) {
+	return r.store.get(uid)
+}
+
+func (r *memoryConnectionRepository) Create(ctx context.Context, connections *models.UserConnections) error {
+	r.store.put(connections.UID, *connections)
+	return nil
+}
+
+func (r *memoryConnectionRepository) Update(ctx context.Context, uids []string, fn func(map[string]*models.UserConnections) error) error {
+	return r.store.upsertMany(uids, func(records map[string]*models.UserConnections) error {
+		for uid, connections := range records {
+			if connections.ID == "" {
+				*connections = newUserConnections(uid)
+			}
+		}
+		return fn(records)
+	})
+}
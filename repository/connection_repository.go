@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// ConnectionRepository stores the connections and connection requests of the users,
+// one record per user
+type ConnectionRepository interface {
+	GetByUser(ctx context.Context, uid string) (*models.UserConnections, error)
+	Create(ctx context.Context, connections *models.UserConnections) error
+	// Update atomically applies fn to the records of the users, keyed by UID, and saves
+	// all of them. Users without a record get an empty one with a new ID.
+	Update(ctx context.Context, uids []string, fn func(map[string]*models.UserConnections) error) error
+}
+
+// newUserConnections returns the empty record of a user
+func newUserConnections(uid string) models.UserConnections {
+	return models.UserConnections{
+		ID:              uuid.New().String(),
+		UID:             uid,
+		Connections:     make(map[string]models.Connection),
+		PendingRequests: make(map[string]models.ConnectionRequest),
+		SentRequests:    make(map[string]models.SentRequest),
+	}
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreContactRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreContactRepository creates a ContactRepository backed by the "contact_us" collection
+func NewFirestoreContactRepository(client FirestoreClient) ContactRepository {
+	return &firestoreContactRepository{client: client}
+}
+
+func (r *firestoreContactRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("contact_us")
+}
+
+func mapContactFirestoreToGo(data map[string]interface{}) models.ContactUs {
+	return *mappers.FirestoreToContactUs(data)
+}
+
+func mapContactGoToFirestore(contact models.ContactUs) map[string]interface{} {
+	return mappers.ContactUsToFirestore(&contact)
+}
+
+// decodeContact takes the ID from the document name, as messages used to be added without it
+func decodeContact(doc *firestore.DocumentSnapshot) models.ContactUs {
+	contact := mapContactFirestoreToGo(doc.Data())
+	contact.ID = doc.Ref.ID
+	return contact
+}
+
+func (r *firestoreContactRepository) Create(ctx context.Context, contact *models.ContactUs) error {
+	_, err := r.collection().Doc(contact.ID).Set(ctx, mappers.ContactUsToFirestore(contact))
+	return err
+}
+
+func (r *firestoreContactRepository) Get(ctx context.Context, contactID string) (*models.ContactUs, error) {
+	contact, err := getDocument(ctx, r.collection().Doc(contactID), mapContactFirestoreToGo)
+	if err != nil {
+		return nil, err
+	}
+	contact.ID = contactID
+	return contact, nil
+}
+
+func (r *firestoreContactRepository) Update(ctx context.Context, contactID string, fn func(*models.ContactUs) error) (*models.ContactUs, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(contactID),
+		mapContactFirestoreToGo, mapContactGoToFirestore, func(contact *models.ContactUs) error {
+			contact.ID = contactID
+			return fn(contact)
+		})
+}
+
+func (r *firestoreContactRepository) List(ctx context.Context) ([]models.ContactUs, error) {
+	return queryDocuments(ctx, r.collection().Query, decodeContact)
+}
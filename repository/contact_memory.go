@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryContactRepository struct {
+	store *memoryStore[models.ContactUs]
+}
+
+// NewMemoryContactRepository creates an in-memory ContactRepository
+func NewMemoryContactRepository() ContactRepository {
+	return &memoryContactRepository{store: newMemoryStore[models.ContactUs]()}
+}
+
+func (r *memoryContactRepository) Create(ctx context.Context, contact *models.ContactUs) error {
+	r.store.put(contact.ID, *contact)
+	return nil
+}
+
+func (r *memoryContactRepository) Get(ctx context.Context, contactID string) (*models.ContactUs, error) {
+	return r.store.get(contactID)
+}
+
+func (r *memoryContactRepository) Update(ctx context.Context, contactID string, fn func(*models.ContactUs) error) (*models.ContactUs, error) {
+	return r.store.update(contactID, fn)
+}
+
+func (r *memoryContactRepository) List(ctx context.Context) ([]models.ContactUs, error) {
+	return r.store.list(nil), nil
+}
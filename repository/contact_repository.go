@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// ContactRepository stores the messages sent through the contact form
+type ContactRepository interface {
+	Create(ctx context.Context, contact *models.ContactUs) error
+	Get(ctx context.Context, contactID string) (*models.ContactUs, error)
+	// Update atomically applies fn to the stored message and saves the result
+	Update(ctx context.Context, contactID string, fn func(*models.ContactUs) error) (*models.ContactUs, error)
+	List(ctx context.Context) ([]models.ContactUs, error)
+}
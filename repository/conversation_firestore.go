@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"google.golang.org/api/iterator"
+)
+
+type firestoreConversationRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreConversationRepository creates a ConversationRepository backed by the
+// "conversations" collection. Messages live in the "messages" subcollection of each
+// conversation, ordered by "seq" like group chat messages.
+//
+// Listing a user's conversations needs a composite index on
+// participants (array-contains) and updated_at (descending).
+func NewFirestoreConversationRepository(client FirestoreClient) ConversationRepository {
+	return &firestoreConversationRepository{client: client}
+}
+
+func (r *firestoreConversationRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("conversations")
+}
+
+func conversationMessagesCollection(conversationRef *firestore.DocumentRef) *firestore.CollectionRef {
+	return conversationRef.Collection("messages")
+}
+
+func decodeDirectMessage(doc *firestore.DocumentSnapshot) models.DirectMessage {
+	return mappers.MapDirectMessageFirestoreToGo(doc.Data())
+}
+
+func decodeConversation(doc *firestore.DocumentSnapshot) models.DirectConversation {
+	conversation := mappers.MapDirectConversationFirestoreToGo(doc.Data())
+	conversation.ID = doc.Ref.ID
+	return conversation
+}
+
+func (r *firestoreConversationRepository) Get(ctx context.Context, conversationID string) (*models.DirectConversation, error) {
+	return getDocument(ctx, r.collection().Doc(conversationID), mappers.MapDirectConversationFirestoreToGo)
+}
+
+func (r *firestoreConversationRepository) AppendMessage(ctx context.Context, message *models.DirectMessage) (*models.DirectConversation, error) {
+	if message.ID == "" {
+		message.ID = uuid.New().String()
+	}
+
+	ref := r.collection().Doc(ConversationID(message.SenderID, message.ReceiverID))
+	var conversation models.DirectConversation
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var seq int64
+
+		doc, err := tx.Get(ref)
+		switch {
+		case err == nil:
+			conversation = decodeConversation(doc)
+			seq = messageSeq(doc.Data(), "message_count")
+		case isNotFound(err):
+			conversation = newConversation(message)
+		default:
+			return err
+		}
+
+		seq++
+		data := mappers.MapDirectMessageGoToFirestore(*message)
+		data["seq"] = seq
+		if err := tx.Create(conversationMessagesCollection(ref).Doc(message.ID), data); err != nil {
+			return err
+		}
+
+		recordMessage(&conversation, message)
+		record := mappers.MapDirectConversationGoToFirestore(conversation)
+		record["message_count"] = seq
+		return tx.Set(ref, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+func (r *firestoreConversationRepository) Update(ctx context.Context, conversationID string, fn func(*models.DirectConversation) error) (*models.DirectConversation, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(conversationID),
+		mappers.MapDirectConversationFirestoreToGo, mappers.MapDirectConversationGoToFirestore, fn)
+}
+
+func (r *firestoreConversationRepository) ListByParticipant(ctx context.Context, userID string, page ConversationPage) ([]models.DirectConversation, error) {
+	query := r.collection().
+		Where("participants", "array-contains", userID).
+		OrderBy("updated_at", firestore.Desc)
+
+	if page.Before != "" {
+		cursor, err := r.collection().Doc(page.Before).Get(ctx)
+		if err != nil {
+			if isNotFound(err) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		query = query.StartAfter(cursor)
+	}
+
+	// The archived flag is a per-user map entry that is missing for most users,
+	// so it is filtered here rather than in the query
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	results := []models.DirectConversation{}
+	for page.Limit <= 0 || len(results) < page.Limit {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate documents: %v", err)
+		}
+
+		conversation := decodeConversation(doc)
+		if conversation.Archived[userID] == page.Archived {
+			results = append(results, conversation)
+		}
+	}
+
+	return results, nil
+}
+
+func (r *firestoreConversationRepository) ListMessages(ctx context.Context, conversationID string, page MessagePage) ([]models.DirectMessage, error) {
+	return listSequencedMessages(ctx, conversationMessagesCollection(r.collection().Doc(conversationID)), page, decodeDirectMessage)
+}
+
+// MarkRead resets the unread count and moves the read position of the user to the last
+// message in a transaction, so that neither is lost to a concurrent AppendMessage. The
+// messages between the previous and the new read position are marked as read afterwards;
+// the earlier ones were marked by the previous calls, and those sent later stay unread.
+func (r *firestoreConversationRepository) MarkRead(ctx context.Context, conversationID, userID string) (int, error) {
+	ref := r.collection().Doc(conversationID)
+	var cleared int
+	var previousSeq, lastSeq int64
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			if isNotFound(err) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		conversation := decodeConversation(doc)
+		cleared = conversation.UnreadCount[userID]
+		previousSeq = conversation.ReadSeq[userID]
+		lastSeq = messageSeq(doc.Data(), "message_count")
+		return tx.Update(ref, []firestore.Update{
+			{FieldPath: firestore.FieldPath{"unread_count", userID}, Value: 0},
+			{FieldPath: firestore.FieldPath{"read_seq", userID}, Value: lastSeq},
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	if lastSeq <= previousSeq {
+		return cleared, nil
+	}
+
+	// Both bounds are on seq, so the query needs no composite index; the messages the
+	// user sent are skipped here
+	iter := conversationMessagesCollection(ref).
+		Where("seq", ">", previousSeq).
+		Where("seq", "<=", lastSeq).
+		Select("receiver_id", "read_status").
+		Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		message := decodeDirectMessage(doc)
+		if message.ReceiverID != userID || isMessageRead(message.BaseMessage, userID) {
+			continue
+		}
+		if _, err := doc.Ref.Update(ctx, []firestore.Update{
+			{FieldPath: firestore.FieldPath{"read_status", userID}, Value: true},
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	return cleared, nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryConversationRepository struct {
+	mu            sync.Mutex
+	conversations *memoryStore[models.DirectConversation]
+	messages      map[string][]models.DirectMessage
+}
+
+// NewMemoryConversationRepository creates an in-memory ConversationRepository
+func NewMemoryConversationRepository() ConversationRepository {
+	return &memoryConversationRepository{
+		conversations: newMemoryStore[models.DirectConversation](),
+		messages:      make(map[string][]models.DirectMessage),
+	}
+}
+
+func (r *memoryConversationRepository) Get(ctx context.Context, conversationID string) (*models.DirectConversation, error) {
+	return r.conversations.get(conversationID)
+}
+
+func (r *memoryConversationRepository) AppendMessage(ctx context.Context, message *models.DirectMessage) (*models.DirectConversation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if message.ID == "" {
+		message.ID = uuid.New().String()
+	}
+
+	conversationID := ConversationID(message.SenderID, message.ReceiverID)
+	conversation := newConversation(message)
+	if existing, err := r.conversations.get(conversationID); err == nil {
+		conversation = *existing
+	}
+
+	recordMessage(&conversation, message)
+	r.conversations.put(conversationID, conversation)
+	r.messages[conversationID] = append(r.messages[conversationID], clone(*message))
+
+	return &conversation, nil
+}
+
+func (r *memoryConversationRepository) Update(ctx context.Context, conversationID string, fn func(*models.DirectConversation) error) (*models.DirectConversation, error) {
+	return r.conversations.update(conversationID, fn)
+}
+
+func (r *memoryConversationRepository) ListByParticipant(ctx context.Context, userID string, page ConversationPage) ([]models.DirectConversation, error) {
+	conversations := r.conversations.list(func(conversation models.DirectConversation) bool {
+		return isConversationParticipant(conversation, userID) && conversation.Archived[userID] == page.Archived
+	})
+	sort.SliceStable(conversations, func(i, j int) bool {
+		return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
+	})
+
+	if page.Before != "" {
+		if _, err := r.conversations.get(page.Before); err != nil {
+			return nil, err
+		}
+		for i, conversation := range conversations {
+			if conversation.ID == page.Before {
+				conversations = conversations[i+1:]
+				break
+			}
+		}
+	}
+
+	if page.Limit > 0 && len(conversations) > page.Limit {
+		conversations = conversations[:page.Limit]
+	}
+	return conversations, nil
+}
+
+func (r *memoryConversationRepository) ListMessages(ctx context.Context, conversationID string, page MessagePage) ([]models.DirectMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return pageMessages(r.messages[conversationID], func(message models.DirectMessage) string { return message.ID }, page)
+}
+
+func (r *memoryConversationRepository) MarkRead(ctx context.Context, conversationID, userID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	messages := r.messages[conversationID]
+	var cleared int
+	var previousSeq int64
+	if _, err := r.conversations.update(conversationID, func(conversation *models.DirectConversation) error {
+		if conversation.UnreadCount == nil {
+			conversation.UnreadCount = make(map[string]int)
+		}
+		if conversation.ReadSeq == nil {
+			conversation.ReadSeq = make(map[string]int64)
+		}
+		cleared = conversation.UnreadCount[userID]
+		previousSeq = conversation.ReadSeq[userID]
+		conversation.UnreadCount[userID] = 0
+		conversation.ReadSeq[userID] = int64(len(messages))
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	// The messages are stored in order, so the one with sequence number n is at n-1
+	for i := int(previousSeq); i < len(messages); i++ {
+		message := &messages[i]
+		if message.ReceiverID != userID {
+			continue
+		}
+		if message.ReadStatus == nil {
+			message.ReadStatus = make(map[string]bool)
+		}
+		message.ReadStatus[userID] = true
+	}
+	return cleared, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryConversationMarkRead(t *testing.T) {
+	ctx := context.Background()
+	conversations := NewMemoryConversationRepository()
+	conversationID := ConversationID("ada", "bob")
+
+	send := func(from, to string, count int) {
+		for i := 0; i < count; i++ {
+			message := &models.DirectMessage{ReceiverID: to}
+			message.SenderID = from
+			_, err := conversations.AppendMessage(ctx, message)
+			require.NoError(t, err)
+		}
+	}
+
+	steps := []struct {
+		name        string
+		sendToBob   int
+		sendToAda   int
+		wantCleared int
+		wantReadSeq int64
+	}{
+		{name: "first read", sendToBob: 3, sendToAda: 1, wantCleared: 3, wantReadSeq: 4},
+		{name: "nothing new", wantReadSeq: 4},
+		{name: "new messages only", sendToBob: 2, wantCleared: 2, wantReadSeq: 6},
+		{name: "messages of the other participant", sendToAda: 2, wantReadSeq: 8},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			send("ada", "bob", step.sendToBob)
+			send("bob", "ada", step.sendToAda)
+
+			cleared, err := conversations.MarkRead(ctx, conversationID, "bob")
+			require.NoError(t, err)
+			assert.Equal(t, step.wantCleared, cleared)
+
+			conversation, err := conversations.Get(ctx, conversationID)
+			require.NoError(t, err)
+			assert.Equal(t, step.wantReadSeq, conversation.ReadSeq["bob"])
+			assert.Zero(t, conversation.UnreadCount["bob"])
+
+			messages, err := conversations.ListMessages(ctx, conversationID, MessagePage{})
+			require.NoError(t, err)
+			for _, message := range messages {
+				assert.Equal(t, message.ReceiverID == "bob", message.ReadStatus["bob"], "message %s", message.ID)
+			}
+		})
+	}
+
+	_, err := conversations.MarkRead(ctx, "missing", "bob")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
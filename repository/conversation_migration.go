@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"google.golang.org/api/iterator"
+)
+
+// migrateFirestoreDirectMessages builds the conversation index from the legacy
+// "messages/{channelID}" documents that embed every direct message of a pair of users.
+// The legacy documents are left untouched.
+func migrateFirestoreDirectMessages(ctx context.Context, client FirestoreClient) error {
+	iter := client.Collection("messages").Documents(ctx)
+	defer iter.Stop()
+
+	migrated := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to iterate messages: %v", err)
+		}
+
+		// Messages sent through /messages/send are stored in the same collection
+		// as single documents without a channel
+		if _, ok := doc.Data()["direct_messages"]; !ok {
+			continue
+		}
+
+		legacy := mappers.MapMessagesFirestoreToGo(doc.Data())
+		if len(legacy.DirectMessages) == 0 {
+			continue
+		}
+
+		if err := migrateLegacyConversation(ctx, client, doc.Ref.ID, legacy.DirectMessages); err != nil {
+			return fmt.Errorf("failed to migrate conversation %s: %v", doc.Ref.ID, err)
+		}
+		migrated++
+	}
+
+	log.Printf("Migrated %d direct message conversations to the conversation index", migrated)
+	return nil
+}
+
+// migrateLegacyConversation writes the messages of a channel to its conversation and
+// then the conversation record itself, so an interrupted run is simply repeated
+func migrateLegacyConversation(ctx context.Context, client FirestoreClient, channelID string, messages []models.DirectMessage) error {
+	ref := client.Collection("conversations").Doc(channelID)
+	if _, err := ref.Get(ctx); err == nil {
+		return nil
+	} else if !isNotFound(err) {
+		return err
+	}
+
+	for _, batch := range legacyDirectMessageBatches(channelID, messages) {
+		err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			for _, message := range batch {
+				if err := tx.Set(conversationMessagesCollection(ref).Doc(message.id), message.record); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	conversation := legacyConversation(channelID, messages)
+	record := mappers.MapDirectConversationGoToFirestore(conversation)
+	record["message_count"] = int64(len(messages))
+
+	_, err := ref.Set(ctx, record)
+	return err
+}
+
+// legacyDirectMessageBatches numbers the messages of a legacy history from 1 and splits their
+// records into batches that fit in a transaction. Messages without an ID are given one derived
+// from the channel and their number, so that a repeated run overwrites the same documents.
+func legacyDirectMessageBatches(channelID string, messages []models.DirectMessage) [][]legacyMessageRecord {
+	batches := [][]legacyMessageRecord{}
+	for start := 0; start < len(messages); start += legacyMessageBatchSize {
+		end := start + legacyMessageBatchSize
+		if end > len(messages) {
+			end = len(messages)
+		}
+
+		batch := make([]legacyMessageRecord, 0, end-start)
+		for i := start; i < end; i++ {
+			message := &messages[i]
+			if message.ID == "" {
+				message.ID = fmt.Sprintf("%s-%d", channelID, i+1)
+			}
+
+			record := mappers.MapDirectMessageGoToFirestore(*message)
+			record["seq"] = int64(i + 1)
+			batch = append(batch, legacyMessageRecord{id: message.ID, record: record})
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// legacyConversation derives the conversation record of a legacy message history
+func legacyConversation(channelID string, messages []models.DirectMessage) models.DirectConversation {
+	first := messages[0]
+	participants := []string{first.SenderID, first.ReceiverID}
+	sort.Strings(participants)
+
+	conversation := models.DirectConversation{
+		ID:           channelID,
+		Participants: participants,
+		UnreadCount:  map[string]int{first.SenderID: 0, first.ReceiverID: 0},
+		Muted:        map[string]bool{},
+		Archived:     map[string]bool{},
+		CreatedAt:    legacyMessageTime(first),
+	}
+
+	for i := range messages {
+		recordMessage(&conversation, &messages[i])
+		if isMessageRead(messages[i].BaseMessage, messages[i].ReceiverID) {
+			conversation.UnreadCount[messages[i].ReceiverID]--
+		}
+	}
+	conversation.UpdatedAt = legacyMessageTime(messages[len(messages)-1])
+
+	return conversation
+}
+
+// legacyMessageTime parses the RFC 3339 creation time of a legacy message
+func legacyMessageTime(message models.DirectMessage) time.Time {
+	createdAt, err := time.Parse(time.RFC3339, message.CreatedAt)
+	if err != nil {
+		return time.Now()
+	}
+	return createdAt
+}
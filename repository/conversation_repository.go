@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// ConversationPage selects a window of a user's conversations, most recently active first
+type ConversationPage struct {
+	// Before is the ID of the last conversation of the previous page
+	Before string
+	Limit  int
+	// Archived lists the conversations the user archived instead of the active ones
+	Archived bool
+}
+
+// ConversationRepository stores direct message conversations and their messages
+type ConversationRepository interface {
+	Get(ctx context.Context, conversationID string) (*models.DirectConversation, error)
+	// AppendMessage atomically stores the message, creating the conversation on the
+	// first message, and updates the last message preview and the receiver's unread count
+	AppendMessage(ctx context.Context, message *models.DirectMessage) (*models.DirectConversation, error)
+	// Update atomically applies fn to the stored conversation and saves the result
+	Update(ctx context.Context, conversationID string, fn func(*models.DirectConversation) error) (*models.DirectConversation, error)
+	ListByParticipant(ctx context.Context, userID string, page ConversationPage) ([]models.DirectConversation, error)
+	// ListMessages returns one page of the conversation messages, oldest first
+	ListMessages(ctx context.Context, conversationID string, page MessagePage) ([]models.DirectMessage, error)
+	// MarkRead resets the unread count of the user, atomically with AppendMessage, moves their
+	// read position to the last message, marks the messages they received since the previous
+	// position as read and returns the unread count it cleared
+	MarkRead(ctx context.Context, conversationID, userID string) (int, error)
+}
+
+// ConversationID returns the channel ID shared by two users: both IDs sorted and joined with "-"
+func ConversationID(userA, userB string) string {
+	ids := []string{userA, userB}
+	sort.Strings(ids)
+	return strings.Join(ids, "-")
+}
+
+// newConversation creates the conversation of the two users of a first message
+func newConversation(message *models.DirectMessage) models.DirectConversation {
+	participants := []string{message.SenderID, message.ReceiverID}
+	sort.Strings(participants)
+
+	now := time.Now()
+	return models.DirectConversation{
+		ID:           ConversationID(message.SenderID, message.ReceiverID),
+		Participants: participants,
+		UnreadCount:  map[string]int{message.SenderID: 0, message.ReceiverID: 0},
+		Muted:        map[string]bool{},
+		Archived:     map[string]bool{},
+		ReadSeq:      map[string]int64{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// recordMessage updates the conversation index for a new message
+func recordMessage(conversation *models.DirectConversation, message *models.DirectMessage) {
+	if conversation.UnreadCount == nil {
+		conversation.UnreadCount = map[string]int{}
+	}
+	conversation.UnreadCount[message.ReceiverID]++
+	conversation.LastMessage = &models.DirectConversationPreview{
+		MessageID:  message.ID,
+		SenderID:   message.SenderID,
+		SenderName: message.SenderName,
+		Content:    message.Content,
+		CreatedAt:  message.CreatedAt,
+	}
+	conversation.UpdatedAt = time.Now()
+}
+
+// isConversationParticipant reports whether userID takes part in the conversation
+func isConversationParticipant(conversation models.DirectConversation, userID string) bool {
+	for _, participant := range conversation.Participants {
+		if participant == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreCredentialRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreCredentialRepository creates a CredentialRepository backed by the "credentials" collection.
+// Security rules must keep clients from reading it.
+func NewFirestoreCredentialRepository(client FirestoreClient) CredentialRepository {
+	return &firestoreCredentialRepository{client: client}
+}
+
+func (r *firestoreCredentialRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("credentials")
+}
+
+func (r *firestoreCredentialRepository) Get(ctx context.Context, uid string) (*models.Credential, error) {
+	return getDocument(ctx, r.collection().Doc(uid), mappers.MapCredentialFirestoreToGo)
+}
+
+func (r *firestoreCredentialRepository) Put(ctx context.Context, credential *models.Credential) error {
+	_, err := r.collection().Doc(credential.UID).Set(ctx, mappers.MapCredentialGoToFirestore(*credential))
+	return err
+}
+
+func (r *firestoreCredentialRepository) Delete(ctx context.Context, uid string) error {
+	if _, err := r.Get(ctx, uid); err != nil {
+		return err
+	}
+	_, err := r.collection().Doc(uid).Delete(ctx)
+	return err
+}
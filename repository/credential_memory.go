@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryCredentialRepository struct {
+	store *memoryStore[models.Credential]
+}
+
+// NewMemoryCredentialRepository creates an in-memory CredentialRepository
+func NewMemoryCredentialRepository() CredentialRepository {
+	return &memoryCredentialRepository{store: newMemoryStore[models.Credential]()}
+}
+
+func (r *memoryCredentialRepository) Get(ctx context.Context, uid string) (*models.Credential, error) {
+	return r.store.get(uid)
+}
+
+func (r *memoryCredentialRepository) Put(ctx context.Context, credential *models.Credential) error {
+	r.store.put(credential.UID, *credential)
+	return nil
+}
+
+func (r *memoryCredentialRepository) Delete(ctx context.Context, uid string) error {
+	return r.store.delete(uid)
+}
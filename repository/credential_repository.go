@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// CredentialRepository stores the password hashes of the local identity provider
+type CredentialRepository interface {
+	Get(ctx context.Context, uid string) (*models.Credential, error)
+	// Put creates or replaces the credential of the user
+	Put(ctx context.Context, credential *models.Credential) error
+	Delete(ctx context.Context, uid string) error
+}
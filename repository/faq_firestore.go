@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreFAQRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreFAQRepository creates a FAQRepository backed by the "faqs" collection
+func NewFirestoreFAQRepository(client FirestoreClient) FAQRepository {
+	return &firestoreFAQRepository{client: client}
+}
+
+func (r *firestoreFAQRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("faqs")
+}
+
+// decodeFAQ takes the ID from the document name, as FAQs used to be added without it
+func decodeFAQ(doc *firestore.DocumentSnapshot) models.FAQ {
+	faq := mappers.MapFAQFirestoreToGo(doc.Data())
+	faq.ID = doc.Ref.ID
+	return faq
+}
+
+func (r *firestoreFAQRepository) Create(ctx context.Context, faq *models.FAQ) error {
+	_, err := r.collection().Doc(faq.ID).Set(ctx, mappers.MapFAQGoToFirestore(*faq))
+	return err
+}
+
+func (r *firestoreFAQRepository) Get(ctx context.Context, faqID string) (*models.FAQ, error) {
+	faq, err := getDocument(ctx, r.collection().Doc(faqID), mappers.MapFAQFirestoreToGo)
+	if err != nil {
+		return nil, err
+	}
+	faq.ID = faqID
+	return faq, nil
+}
+
+func (r *firestoreFAQRepository) Update(ctx context.Context, faqID string, fn func(*models.FAQ) error) (*models.FAQ, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(faqID),
+		mappers.MapFAQFirestoreToGo, mappers.MapFAQGoToFirestore, func(faq *models.FAQ) error {
+			faq.ID = faqID
+			return fn(faq)
+		})
+}
+
+func (r *firestoreFAQRepository) Delete(ctx context.Context, faqID string) error {
+	_, err := r.collection().Doc(faqID).Delete(ctx)
+	return err
+}
+
+func (r *firestoreFAQRepository) List(ctx context.Context) ([]models.FAQ, error) {
+	return queryDocuments(ctx, r.collection().Query, decodeFAQ)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryFAQRepository struct {
+	store *memoryStore[models.FAQ]
+}
+
+// NewMemoryFAQRepository creates an in-memory FAQRepository
+func NewMemoryFAQRepository() FAQRepository {
+	return &memoryFAQRepository{store: newMemoryStore[models.FAQ]()}
+}
+
+func (r *memoryFAQRepository) Create(ctx context.Context, faq *models.FAQ) error {
+	r.store.put(faq.ID, *faq)
+	return nil
+}
+
+func (r *memoryFAQRepository) Get(ctx context.Context, faqID string) (*models.FAQ, error) {
+	return r.store.get(faqID)
+}
+
+func (r *memoryFAQRepository) Update(ctx context.Context, faqID string, fn func(*models.FAQ) error) (*models.FAQ, error) {
+	return r.store.update(faqID, fn)
+}
+
+func (r *memoryFAQRepository) Delete(ctx context.Context, faqID string) error {
+	return r.store.delete(faqID)
+}
+
+func (r *memoryFAQRepository) List(ctx context.Context) ([]models.FAQ, error) {
+	return r.store.list(nil), nil
+}
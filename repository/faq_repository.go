@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// FAQRepository stores the frequently asked questions
+type FAQRepository interface {
+	Create(ctx context.Context, faq *models.FAQ) error
+	Get(ctx context.Context, faqID string) (*models.FAQ, error)
+	// Update atomically applies fn to the stored FAQ and saves the result
+	Update(ctx context.Context, faqID string, fn func(*models.FAQ) error) (*models.FAQ, error)
+	Delete(ctx context.Context, faqID string) error
+	List(ctx context.Context) ([]models.FAQ, error)
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/iterator"
+)
+
+// getDocument fetches a single document and decodes it with the given mapper
+func getDocument[T any](ctx context.Context, ref *firestore.DocumentRef, decode func(map[string]interface{}) T) (*T, error) {
+	doc, err := ref.Get(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	value := decode(doc.Data())
+	return &value, nil
+}
+
+// updateDocument reads a document, applies fn and writes the result back inside a transaction
+func updateDocument[T any](ctx context.Context, client FirestoreClient, ref *firestore.DocumentRef,
+	decode func(map[string]interface{}) T, encode func(T) map[string]interface{}, fn func(*T) error) (*T, error) {
+	var updated T
+
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			if isNotFound(err) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		updated = decode(doc.Data())
+		if err := fn(&updated); err != nil {
+			return err
+		}
+
+		return tx.Set(ref, encode(updated), firestore.MergeAll)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// upsertDocument is updateDocument for documents that may not exist yet: fn then starts
+// from the zero value
+func upsertDocument[T any](ctx context.Context, client FirestoreClient, ref *firestore.DocumentRef,
+	decode func(map[string]interface{}) T, encode func(T) map[string]interface{}, fn func(*T) error) (*T, error) {
+	var updated T
+
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var zero T
+		updated = zero
+
+		doc, err := tx.Get(ref)
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		if err == nil {
+			updated = decode(doc.Data())
+		}
+
+		if err := fn(&updated); err != nil {
+			return err
+		}
+
+		return tx.Set(ref, encode(updated))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// queryDocuments runs a query and decodes every resulting document
+func queryDocuments[T any](ctx context.Context, query firestore.Query, decode func(*firestore.DocumentSnapshot) T) ([]T, error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	results := []T{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate documents: %v", err)
+		}
+
+		results = append(results, decode(doc))
+	}
+
+	return results, nil
+}
+
+// messageSeq reads an integer field written by the message store
+func messageSeq(data map[string]interface{}, key string) int64 {
+	if value, ok := data[key].(int64); ok {
+		return value
+	}
+	return 0
+}
+
+// cursorSeq returns the sequence number of the message used as a page cursor
+func cursorSeq(ctx context.Context, messages *firestore.CollectionRef, messageID string) (int64, error) {
+	doc, err := messages.Doc(messageID).Get(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	return messageSeq(doc.Data(), "seq"), nil
+}
+
+// listSequencedMessages returns one page of a message collection ordered by its "seq" field, oldest first
+func listSequencedMessages[T any](ctx context.Context, messages *firestore.CollectionRef, page MessagePage, decode func(*firestore.DocumentSnapshot) T) ([]T, error) {
+	query := messages.Query
+	latestFirst := page.After == ""
+	if page.After != "" {
+		seq, err := cursorSeq(ctx, messages, page.After)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("seq", ">", seq)
+	}
+	if page.Before != "" {
+		seq, err := cursorSeq(ctx, messages, page.Before)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("seq", "<", seq)
+	}
+
+	if latestFirst {
+		query = query.OrderBy("seq", firestore.Desc)
+	} else {
+		query = query.OrderBy("seq", firestore.Asc)
+	}
+	if page.Limit > 0 {
+		query = query.Limit(page.Limit)
+	}
+
+	results, err := queryDocuments(ctx, query, decode)
+	if err != nil {
+		return nil, err
+	}
+
+	if latestFirst {
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
+	}
+	return results, nil
+}
+
+// countDocuments runs a count aggregation over the query
+func countDocuments(ctx context.Context, query firestore.Query) (int, error) {
+	result, err := query.NewAggregationQuery().WithCount("count").Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	value, ok := result["count"].(*firestorepb.Value)
+	if !ok {
+		return 0, nil
+	}
+	return int(value.GetIntegerValue()), nil
+}
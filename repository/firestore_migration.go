@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// migration is a one-off rewrite of the stored data, recorded under its name once it completed
+type migration struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// migrationMarkers records the migrations that completed
+type migrationMarkers interface {
+	applied(ctx context.Context, name string) (bool, error)
+	record(ctx context.Context, name string) error
+}
+
+// firestoreMigrationMarkers keeps a document per completed migration in schema_migrations
+type firestoreMigrationMarkers struct {
+	client FirestoreClient
+}
+
+func (m firestoreMigrationMarkers) applied(ctx context.Context, name string) (bool, error) {
+	_, err := m.client.Collection("schema_migrations").Doc(name).Get(ctx)
+	if err == nil {
+		return true, nil
+	}
+	if isNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (m firestoreMigrationMarkers) record(ctx context.Context, name string) error {
+	_, err := m.client.Collection("schema_migrations").Doc(name).Set(ctx, map[string]interface{}{"applied_at": time.Now()})
+	return err
+}
+
+// firestoreMigrations are the migrations of the Firestore data, in the order they run
+func firestoreMigrations(client FirestoreClient) []migration {
+	return []migration{
+		{name: "group_chat_messages", run: func(ctx context.Context) error { return migrateFirestoreGroupChatMessages(ctx, client) }},
+		{name: "direct_message_conversations", run: func(ctx context.Context) error { return migrateFirestoreDirectMessages(ctx, client) }},
+	}
+}
+
+// MigrateFirestore runs the Firestore migrations that did not complete yet. They scan whole
+// collections, so they run on demand with the migrate command rather than on every start.
+func MigrateFirestore(ctx context.Context, client FirestoreClient) error {
+	return runMigrations(ctx, firestoreMigrationMarkers{client: client}, firestoreMigrations(client))
+}
+
+// PendingFirestoreMigrations returns the names of the Firestore migrations that did not complete yet
+func PendingFirestoreMigrations(ctx context.Context, client FirestoreClient) ([]string, error) {
+	return pendingMigrations(ctx, firestoreMigrationMarkers{client: client}, firestoreMigrations(client))
+}
+
+// runMigrations runs the migrations without a marker and records each one that completes.
+// A failed migration is not recorded, and runs again, resuming its work, on the next run.
+func runMigrations(ctx context.Context, markers migrationMarkers, migrations []migration) error {
+	for _, m := range migrations {
+		applied, err := markers.applied(ctx, m.name)
+		if err != nil {
+			return fmt.Errorf("failed to read the status of migration %s: %v", m.name, err)
+		}
+		if applied {
+			continue
+		}
+
+		log.Printf("Running migration %s", m.name)
+		if err := m.run(ctx); err != nil {
+			return fmt.Errorf("migration %s failed: %v", m.name, err)
+		}
+		if err := markers.record(ctx, m.name); err != nil {
+			return fmt.Errorf("failed to record migration %s: %v", m.name, err)
+		}
+	}
+	return nil
+}
+
+// pendingMigrations returns the names of the migrations without a marker
+func pendingMigrations(ctx context.Context, markers migrationMarkers, migrations []migration) ([]string, error) {
+	pending := []string{}
+	for _, m := range migrations {
+		applied, err := markers.applied(ctx, m.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the status of migration %s: %v", m.name, err)
+		}
+		if !applied {
+			pending = append(pending, m.name)
+		}
+	}
+	return pending, nil
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryMigrationMarkers records the completed migrations in a map
+type memoryMigrationMarkers map[string]bool
+
+func (m memoryMigrationMarkers) applied(ctx context.Context, name string) (bool, error) {
+	return m[name], nil
+}
+
+func (m memoryMigrationMarkers) record(ctx context.Context, name string) error {
+	m[name] = true
+	return nil
+}
+
+func TestRunMigrations(t *testing.T) {
+	ctx := context.Background()
+	markers := memoryMigrationMarkers{}
+	runs := []string{}
+	failing := errors.New("deadline exceeded")
+	fail := true
+
+	migrations := []migration{
+		{name: "first", run: func(ctx context.Context) error {
+			runs = append(runs, "first")
+			return nil
+		}},
+		{name: "second", run: func(ctx context.Context) error {
+			runs = append(runs, "second")
+			if fail {
+				return failing
+			}
+			return nil
+		}},
+	}
+
+	pending, err := pendingMigrations(ctx, markers, migrations)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, pending)
+
+	// A failed migration is not recorded and stops the run
+	err = runMigrations(ctx, markers, migrations)
+	assert.ErrorContains(t, err, "migration second failed")
+	assert.Equal(t, []string{"first", "second"}, runs)
+	pending, err = pendingMigrations(ctx, markers, migrations)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"second"}, pending)
+
+	// The next run only repeats the failed migration
+	fail = false
+	require.NoError(t, runMigrations(ctx, markers, migrations))
+	assert.Equal(t, []string{"first", "second", "second"}, runs)
+
+	// Once every migration is recorded, runs do nothing
+	require.NoError(t, runMigrations(ctx, markers, migrations))
+	assert.Equal(t, []string{"first", "second", "second"}, runs)
+	pending, err = pendingMigrations(ctx, markers, migrations)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+// legacyGroupChat returns a group chat document embedding count messages, every other one without an ID
+func legacyGroupChat(count int, messageCount int64) map[string]interface{} {
+	messages := make([]interface{}, count)
+	for i := range messages {
+		message := map[string]interface{}{"content": fmt.Sprintf("message %d", i+1), "sender_id": "ada"}
+		if i%2 == 0 {
+			message["id"] = fmt.Sprintf("m%d", i+1)
+		}
+		messages[i] = message
+	}
+	return map[string]interface{}{"name": "gophers", "messages": messages, "message_count": messageCount}
+}
+
+// applyUpdates applies the updates of a batch to a document the way Firestore does
+func applyUpdates(data map[string]interface{}, updates []firestore.Update) {
+	for _, update := range updates {
+		if update.Value == firestore.Delete {
+			delete(data, update.Path)
+			continue
+		}
+		data[update.Path] = update.Value
+	}
+}
+
+func TestNextLegacyMessageBatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		messages     int
+		messageCount int64
+		wantBatches  []int
+	}{
+		{name: "no messages", messages: 0, wantBatches: []int{}},
+		{name: "one batch", messages: 3, wantBatches: []int{3}},
+		{name: "exactly one batch", messages: legacyMessageBatchSize, wantBatches: []int{legacyMessageBatchSize}},
+		{name: "several batches", messages: 950, wantBatches: []int{400, 400, 150}},
+		{name: "chat with messages in the subcollection", messages: 5, messageCount: 12, wantBatches: []int{5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := legacyGroupChat(tt.messages, tt.messageCount)
+			stored := map[string]map[string]interface{}{}
+			batches := []int{}
+
+			for {
+				records, updates := nextLegacyMessageBatch(data)
+				if len(updates) == 0 {
+					break
+				}
+				require.LessOrEqual(t, len(records), legacyMessageBatchSize)
+				for _, message := range records {
+					stored[message.id] = message.record
+				}
+				if len(records) > 0 {
+					batches = append(batches, len(records))
+				}
+				applyUpdates(data, updates)
+			}
+
+			assert.Equal(t, tt.wantBatches, batches)
+			assert.Len(t, stored, tt.messages)
+			assert.NotContains(t, data, "messages")
+			assert.Equal(t, "gophers", data["name"])
+			assert.Equal(t, tt.messageCount+int64(tt.messages), data["message_count"])
+
+			// The messages are numbered in their order after the existing ones
+			seqs := map[int64]bool{}
+			for _, record := range stored {
+				seqs[record["seq"].(int64)] = true
+			}
+			for seq := tt.messageCount + 1; seq <= tt.messageCount+int64(tt.messages); seq++ {
+				assert.True(t, seqs[seq], "seq %d", seq)
+			}
+			if tt.messages > 0 {
+				assert.Equal(t, "message 1", stored["m1"]["content"])
+				assert.Equal(t, tt.messageCount+1, stored["m1"]["seq"])
+			}
+
+			// A migrated chat has nothing left to migrate
+			records, updates := nextLegacyMessageBatch(data)
+			assert.Empty(t, records)
+			assert.Empty(t, updates)
+		})
+	}
+}
+
+func TestLegacyDirectMessageBatches(t *testing.T) {
+	legacyHistory := func(count int) []models.DirectMessage {
+		messages := make([]models.DirectMessage, count)
+		for i := range messages {
+			messages[i].SenderID, messages[i].ReceiverID = "ada", "bob"
+			messages[i].Content = fmt.Sprintf("message %d", i+1)
+			if i == 0 {
+				messages[i].ID = "first"
+			}
+		}
+		return messages
+	}
+
+	tests := []struct {
+		name        string
+		messages    int
+		wantBatches []int
+	}{
+		{name: "one message", messages: 1, wantBatches: []int{1}},
+		{name: "exactly one batch", messages: legacyMessageBatchSize, wantBatches: []int{legacyMessageBatchSize}},
+		{name: "several batches", messages: 801, wantBatches: []int{400, 400, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := legacyDirectMessageBatches("ada_bob", legacyHistory(tt.messages))
+
+			sizes := []int{}
+			seq := int64(0)
+			for _, batch := range batches {
+				sizes = append(sizes, len(batch))
+				for _, message := range batch {
+					seq++
+					assert.Equal(t, seq, message.record["seq"])
+					assert.Equal(t, message.id, message.record["id"])
+				}
+			}
+			assert.Equal(t, tt.wantBatches, sizes)
+			assert.Equal(t, "first", batches[0][0].id)
+
+			// An interrupted run is repeated from the legacy document, and writes the same documents again
+			rerun := legacyDirectMessageBatches("ada_bob", legacyHistory(tt.messages))
+			assert.Equal(t, batches, rerun)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreForumRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreForumRepository creates a ForumRepository backed by the "forums" collection
+func NewFirestoreForumRepository(client FirestoreClient) ForumRepository {
+	return &firestoreForumRepository{client: client}
+}
+
+func (r *firestoreForumRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("forums")
+}
+
+func (r *firestoreForumRepository) Save(ctx context.Context, forum *models.Forum) error {
+	_, err := r.collection().Doc(forum.ID).Set(ctx, mappers.MapForumGoToFirestore(*forum), firestore.MergeAll)
+	return err
+}
+
+func (r *firestoreForumRepository) Get(ctx context.Context, forumID string) (*models.Forum, error) {
+	return getDocument(ctx, r.collection().Doc(forumID), mappers.MapForumFirestoreToGo)
+}
+
+func (r *firestoreForumRepository) Delete(ctx context.Context, forumID string) error {
+	_, err := r.collection().Doc(forumID).Delete(ctx)
+	return err
+}
+
+func (r *firestoreForumRepository) ListByGroup(ctx context.Context, groupID string) ([]models.Forum, error) {
+	return queryDocuments(ctx, r.collection().Where("group_id", "==", groupID), func(doc *firestore.DocumentSnapshot) models.Forum {
+		return mappers.MapForumFirestoreToGo(doc.Data())
+	})
+}
+
+type firestoreGroupRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreGroupRepository creates a GroupRepository backed by the "group_forums" collection
+func NewFirestoreGroupRepository(client FirestoreClient) GroupRepository {
+	return &firestoreGroupRepository{client: client}
+}
+
+func (r *firestoreGroupRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("group_forums")
+}
+
+func (r *firestoreGroupRepository) Save(ctx context.Context, group *models.Group) error {
+	_, err := r.collection().Doc(group.ID).Set(ctx, mappers.MapGroupGoToFirestore(*group), firestore.MergeAll)
+	return err
+}
+
+func (r *firestoreGroupRepository) Get(ctx context.Context, groupID string) (*models.Group, error) {
+	return getDocument(ctx, r.collection().Doc(groupID), mappers.MapGroupFirestoreToGo)
+}
+
+func (r *firestoreGroupRepository) Delete(ctx context.Context, groupID string) error {
+	_, err := r.collection().Doc(groupID).Delete(ctx)
+	return err
+}
+
+func (r *firestoreGroupRepository) List(ctx context.Context, filters map[string]interface{}) ([]models.Group, error) {
+	query := r.collection().Query
+	for key, value := range filters {
+		query = query.Where(key, "==", value)
+	}
+
+	return queryDocuments(ctx, query, func(doc *firestore.DocumentSnapshot) models.Group {
+		return mappers.MapGroupFirestoreToGo(doc.Data())
+	})
+}
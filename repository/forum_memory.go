@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryForumRepository struct {
+	store *memoryStore[models.Forum]
+}
+
+// NewMemoryForumRepository creates an in-memory ForumRepository
+func NewMemoryForumRepository() ForumRepository {
+	return &memoryForumRepository{store: newMemoryStore[models.Forum]()}
+}
+
+func (r *memoryForumRepository) Save(ctx context.Context, forum *models.Forum) error {
+	r.store.put(forum.ID, *forum)
+	return nil
+}
+
+func (r *memoryForumRepository) Get(ctx context.Context, forumID string) (*models.Forum, error) {
+	return r.store.get(forumID)
+}
+
+func (r *memoryForumRepository) Delete(ctx context.Context, forumID string) error {
+	return r.store.delete(forumID)
+}
+
+func (r *memoryForumRepository) ListByGroup(ctx context.Context, groupID string) ([]models.Forum, error) {
+	return r.store.list(func(forum models.Forum) bool { return forum.GroupID == groupID }), nil
+}
+
+type memoryGroupRepository struct {
+	store *memoryStore[models.Group]
+}
+
+// NewMemoryGroupRepository creates an in-memory GroupRepository
+func NewMemoryGroupRepository() GroupRepository {
+	return &memoryGroupRepository{store: newMemoryStore[models.Group]()}
+}
+
+func (r *memoryGroupRepository) Save(ctx context.Context, group *models.Group) error {
+	r.store.put(group.ID, *group)
+	return nil
+}
+
+func (r *memoryGroupRepository) Get(ctx context.Context, groupID string) (*models.Group, error) {
+	return r.store.get(groupID)
+}
+
+func (r *memoryGroupRepository) Delete(ctx context.Context, groupID string) error {
+	return r.store.delete(groupID)
+}
+
+func (r *memoryGroupRepository) List(ctx context.Context, filters map[string]interface{}) ([]models.Group, error) {
+	return r.store.list(func(group models.Group) bool {
+		data := mappers.MapGroupGoToFirestore(group)
+		for path, expected := range filters {
+			if fieldValue(data, path) != expected {
+				return false
+			}
+		}
+		return true
+	}), nil
+}
+
+// fieldValue resolves a dotted Firestore field path in a mapped document
+func fieldValue(data map[string]interface{}, path string) interface{} {
+	var value interface{} = data
+	for _, key := range strings.Split(path, ".") {
+		current, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = current[key]
+	}
+	return value
+}
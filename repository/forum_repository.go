@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// ForumRepository stores forums together with their posts, comments and reactions
+type ForumRepository interface {
+	// Save creates the forum or overwrites the stored one with the same ID
+	Save(ctx context.Context, forum *models.Forum) error
+	Get(ctx context.Context, forumID string) (*models.Forum, error)
+	Delete(ctx context.Context, forumID string) error
+	ListByGroup(ctx context.Context, groupID string) ([]models.Forum, error)
+}
+
+// GroupRepository stores community groups
+type GroupRepository interface {
+	// Save creates the group or overwrites the stored one with the same ID
+	Save(ctx context.Context, group *models.Group) error
+	Get(ctx context.Context, groupID string) (*models.Group, error)
+	Delete(ctx context.Context, groupID string) error
+	// List returns the groups whose Firestore fields equal every filter value.
+	// Filter keys use the Firestore field paths, e.g. "category.name".
+	List(ctx context.Context, filters map[string]interface{}) ([]models.Group, error)
+}
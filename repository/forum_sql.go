@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sqlForumRepository struct {
+	db *gorm.DB
+}
+
+// NewSQLForumRepository creates a ForumRepository that keeps forums, posts,
+// comments and reactions in relational tables
+func NewSQLForumRepository(db *gorm.DB) ForumRepository {
+	return &sqlForumRepository{db: db}
+}
+
+// preloadForum loads the whole forum tree, oldest posts and comments first
+func preloadForum(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Moderators").
+		Preload("Categories", func(db *gorm.DB) *gorm.DB {
+			return db.Order(clause.OrderByColumn{Column: clause.Column{Name: "order"}})
+		}).
+		Preload("Posts", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("Posts.Tags").
+		Preload("Posts.Files").
+		Preload("Posts.Reactions", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("Posts.Comments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("Posts.Comments.Reactions", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") })
+}
+
+// deletePosts removes the posts selected by postIDs and every row that belongs to them
+func deletePosts(tx *gorm.DB, postIDs interface{}) error {
+	commentIDs := tx.Model(&models.Comment{}).Select("id").Where("post_id IN (?)", postIDs)
+
+	steps := []*gorm.DB{
+		tx.Where("post_id IN (?) OR comment_id IN (?)", postIDs, commentIDs).Delete(&models.Reaction{}),
+		tx.Where("post_id IN (?)", postIDs).Delete(&models.Comment{}),
+		tx.Exec("DELETE FROM post_tags WHERE post_id IN (?)", postIDs),
+		tx.Where("post_id IN (?)", postIDs).Delete(&models.File{}),
+		tx.Where("id IN (?)", postIDs).Delete(&models.Post{}),
+	}
+	for _, step := range steps {
+		if step.Error != nil {
+			return step.Error
+		}
+	}
+	return nil
+}
+
+// deleteForumTree removes the forum row and every row that belongs to it.
+// It reports whether the forum existed.
+func deleteForumTree(tx *gorm.DB, forumID string) (bool, error) {
+	postIDs := tx.Model(&models.Post{}).Select("id").Where("forum_id = ?", forumID)
+	if err := deletePosts(tx, postIDs); err != nil {
+		return false, err
+	}
+
+	steps := []*gorm.DB{
+		tx.Where("forum_id = ?", forumID).Delete(&models.ForumCategory{}),
+		tx.Exec("DELETE FROM forum_moderators WHERE forum_id = ?", forumID),
+	}
+	for _, step := range steps {
+		if step.Error != nil {
+			return false, step.Error
+		}
+	}
+
+	result := tx.Where("id = ?", forumID).Delete(&models.Forum{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// deletePost removes a post and every row that belongs to it
+func deletePost(tx *gorm.DB, post *models.Post) error {
+	return deletePosts(tx, []string{post.ID})
+}
+
+// deleteComment removes a comment and its reactions
+func deleteComment(tx *gorm.DB, comment *models.Comment) error {
+	if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.Reaction{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(comment).Error
+}
+
+// resolveTags returns the tags with the ID of the stored tag of the same name, as
+// names are unique: posts tagged alike share the tag row
+func resolveTags(tx *gorm.DB, tags []models.Tag) ([]models.Tag, error) {
+	if len(tags) == 0 {
+		return tags, nil
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+
+	var stored []models.Tag
+	if err := tx.Where("name IN ?", names).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	ids := make(map[string]string, len(stored))
+	for _, tag := range stored {
+		ids[tag.Name] = tag.ID
+	}
+
+	resolved := make([]models.Tag, len(tags))
+	for i, tag := range tags {
+		if id, ok := ids[tag.Name]; ok {
+			tag.ID = id
+		}
+		resolved[i] = tag
+	}
+	return resolved, nil
+}
+
+// savePost writes the rows of the post's files, reactions and comments that differ
+// from the stored post, and replaces its tag links. The post row is written by Save.
+func savePost(tx *gorm.DB, stored, post *models.Post) error {
+	if err := syncRows(tx, stored.Files, post.Files, deleteRow[models.File]); err != nil {
+		return err
+	}
+	if err := syncRows(tx, stored.Reactions, post.Reactions, deleteRow[models.Reaction]); err != nil {
+		return err
+	}
+	if err := syncRows(tx, stored.Comments, post.Comments, deleteComment); err != nil {
+		return err
+	}
+
+	storedComments := make(map[string]*models.Comment, len(stored.Comments))
+	for i := range stored.Comments {
+		storedComments[stored.Comments[i].ID] = &stored.Comments[i]
+	}
+	for _, comment := range post.Comments {
+		var storedReactions []models.Reaction
+		if storedComment, ok := storedComments[comment.ID]; ok {
+			storedReactions = storedComment.Reactions
+		}
+		if err := syncRows(tx, storedReactions, comment.Reactions, deleteRow[models.Reaction]); err != nil {
+			return err
+		}
+	}
+
+	tags, err := resolveTags(tx, post.Tags)
+	if err != nil {
+		return err
+	}
+	return replaceAssociation(tx, &models.Post{ID: post.ID}, "Tags", tags)
+}
+
+// Save writes the forum row and the rows of its categories, posts, comments, reactions
+// and files that differ from the stored forum, then replaces its moderator and tag links
+func (r *sqlForumRepository) Save(ctx context.Context, forum *models.Forum) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var stored models.Forum
+		if err := preloadForum(lockForUpdate(tx)).Where("id = ?", forum.ID).Limit(1).Find(&stored).Error; err != nil {
+			return err
+		}
+
+		record := *forum
+		if err := upsert(tx, &record); err != nil {
+			return err
+		}
+		// gorm replaces zero values by the column default on insert, which
+		// would turn AllowFiles=false into true
+		err := tx.Model(&models.Forum{}).Where("id = ?", forum.ID).UpdateColumn("allow_files", forum.AllowFiles).Error
+		if err != nil {
+			return err
+		}
+
+		if err := syncRows(tx, stored.Categories, forum.Categories, deleteRow[models.ForumCategory]); err != nil {
+			return err
+		}
+		if err := syncRows(tx, stored.Posts, forum.Posts, deletePost); err != nil {
+			return err
+		}
+
+		storedPosts := make(map[string]*models.Post, len(stored.Posts))
+		for i := range stored.Posts {
+			storedPosts[stored.Posts[i].ID] = &stored.Posts[i]
+		}
+		for i := range forum.Posts {
+			storedPost := storedPosts[forum.Posts[i].ID]
+			if storedPost == nil {
+				storedPost = &models.Post{}
+			}
+			if err := savePost(tx, storedPost, &forum.Posts[i]); err != nil {
+				return err
+			}
+		}
+
+		if err := saveUsers(tx, forum.Moderators); err != nil {
+			return err
+		}
+		return replaceAssociation(tx, &models.Forum{ID: forum.ID}, "Moderators", forum.Moderators)
+	})
+}
+
+func (r *sqlForumRepository) Get(ctx context.Context, forumID string) (*models.Forum, error) {
+	var forum models.Forum
+	err := preloadForum(r.db.WithContext(ctx)).Where("id = ?", forumID).First(&forum).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &forum, nil
+}
+
+func (r *sqlForumRepository) Delete(ctx context.Context, forumID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		found, err := deleteForumTree(tx, forumID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *sqlForumRepository) ListByGroup(ctx context.Context, groupID string) ([]models.Forum, error) {
+	var forums []models.Forum
+	err := preloadForum(r.db.WithContext(ctx)).Where("group_id = ?", groupID).Order("created_at").Find(&forums).Error
+	if err != nil {
+		return nil, err
+	}
+	return forums, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"google.golang.org/api/iterator"
+)
+
+type firestoreGroupChatRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreGroupChatRepository creates a GroupChatRepository backed by the "group_chats" collection
+func NewFirestoreGroupChatRepository(client FirestoreClient) GroupChatRepository {
+	return &firestoreGroupChatRepository{client: client}
+}
+
+func (r *firestoreGroupChatRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("group_chats")
+}
+
+// decodeGroupChat maps a group chat document, the document ID being the chat ID
+func decodeGroupChat(doc *firestore.DocumentSnapshot) models.GroupChat {
+	chat := mappers.MapGroupChatFirestoreToGo(doc.Data())
+	chat.ID = doc.Ref.ID
+	return chat
+}
+
+// findDoc resolves a chat by document ID, falling back to the "id" field used
+// by chats that were created with a random document ID
+func (r *firestoreGroupChatRepository) findDoc(ctx context.Context, chatID string) (*firestore.DocumentSnapshot, error) {
+	doc, err := r.collection().Doc(chatID).Get(ctx)
+	if err == nil {
+		return doc, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+
+	iter := r.collection().Where("id", "==", chatID).Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	doc, err = iter.Next()
+	if err == iterator.Done {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (r *firestoreGroupChatRepository) Create(ctx context.Context, chat *models.GroupChat) error {
+	if chat.ID == "" {
+		chat.ID = uuid.New().String()
+	}
+
+	_, err := r.collection().Doc(chat.ID).Set(ctx, mappers.MapGroupChatGoToFirestore(*chat))
+	return err
+}
+
+func (r *firestoreGroupChatRepository) Get(ctx context.Context, chatID string) (*models.GroupChat, error) {
+	doc, err := r.findDoc(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	chat := decodeGroupChat(doc)
+	return &chat, nil
+}
+
+func (r *firestoreGroupChatRepository) GetByProject(ctx context.Context, projectID string) (*models.GroupChat, error) {
+	iter := r.collection().Where("project_id", "==", projectID).Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	chat := decodeGroupChat(doc)
+	return &chat, nil
+}
+
+func (r *firestoreGroupChatRepository) Update(ctx context.Context, chatID string, fn func(*models.GroupChat) error) (*models.GroupChat, error) {
+	doc, err := r.findDoc(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	chat, err := updateDocument(ctx, r.client, doc.Ref,
+		mappers.MapGroupChatFirestoreToGo, mappers.MapGroupChatGoToFirestore, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	chat.ID = doc.Ref.ID
+	return chat, nil
+}
+
+func (r *firestoreGroupChatRepository) Delete(ctx context.Context, chatID string) error {
+	doc, err := r.findDoc(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	_, err = doc.Ref.Delete(ctx)
+	return err
+}
+
+func (r *firestoreGroupChatRepository) ListByProject(ctx context.Context, projectID string) ([]models.GroupChat, error) {
+	return queryDocuments(ctx, r.collection().Where("project_id", "==", projectID), decodeGroupChat)
+}
+
+func (r *firestoreGroupChatRepository) ListByParticipant(ctx context.Context, userID string) ([]models.GroupChat, error) {
+	chats, err := queryDocuments(ctx, r.collection().Query, decodeGroupChat)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.GroupChat{}
+	for _, chat := range chats {
+		if isGroupChatParticipant(chat, userID) {
+			result = append(result, chat)
+		}
+	}
+	return result, nil
+}
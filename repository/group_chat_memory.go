@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryGroupChatRepository struct {
+	store *memoryStore[models.GroupChat]
+}
+
+// NewMemoryGroupChatRepository creates an in-memory GroupChatRepository
+func NewMemoryGroupChatRepository() GroupChatRepository {
+	return &memoryGroupChatRepository{store: newMemoryStore[models.GroupChat]()}
+}
+
+func (r *memoryGroupChatRepository) Create(ctx context.Context, chat *models.GroupChat) error {
+	if chat.ID == "" {
+		chat.ID = uuid.New().String()
+	}
+	r.store.put(chat.ID, *chat)
+	return nil
+}
+
+func (r *memoryGroupChatRepository) Get(ctx context.Context, chatID string) (*models.GroupChat, error) {
+	return r.store.get(chatID)
+}
+
+func (r *memoryGroupChatRepository) GetByProject(ctx context.Context, projectID string) (*models.GroupChat, error) {
+	chats, _ := r.ListByProject(ctx, projectID)
+	if len(chats) == 0 {
+		return nil, ErrNotFound
+	}
+	return &chats[0], nil
+}
+
+func (r *memoryGroupChatRepository) Update(ctx context.Context, chatID string, fn func(*models.GroupChat) error) (*models.GroupChat, error) {
+	return r.store.update(chatID, fn)
+}
+
+func (r *memoryGroupChatRepository) Delete(ctx context.Context, chatID string) error {
+	return r.store.delete(chatID)
+}
+
+func (r *memoryGroupChatRepository) ListByProject(ctx context.Context, projectID string) ([]models.GroupChat, error) {
+	return r.store.list(func(chat models.GroupChat) bool { return chat.ProjectID == projectID }), nil
+}
+
+func (r *memoryGroupChatRepository) ListByParticipant(ctx context.Context, userID string) ([]models.GroupChat, error) {
+	return r.store.list(func(chat models.GroupChat) bool { return isGroupChatParticipant(chat, userID) }), nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// GroupChatRepository stores group chats
+type GroupChatRepository interface {
+	// Create saves a new group chat, assigning an ID when the chat has none
+	Create(ctx context.Context, chat *models.GroupChat) error
+	Get(ctx context.Context, chatID string) (*models.GroupChat, error)
+	// GetByProject returns the first group chat attached to a project
+	GetByProject(ctx context.Context, projectID string) (*models.GroupChat, error)
+	// Update atomically applies fn to the stored group chat and saves the result
+	Update(ctx context.Context, chatID string, fn func(*models.GroupChat) error) (*models.GroupChat, error)
+	Delete(ctx context.Context, chatID string) error
+	ListByProject(ctx context.Context, projectID string) ([]models.GroupChat, error)
+	ListByParticipant(ctx context.Context, userID string) ([]models.GroupChat, error)
+}
+
+// isGroupChatParticipant reports whether userID is one of the chat participants
+func isGroupChatParticipant(chat models.GroupChat, userID string) bool {
+	for _, participant := range chat.Participants {
+		if participant.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"google.golang.org/api/iterator"
+)
+
+type firestoreGroupMessageRepository struct {
+	client FirestoreClient
+	chats  *firestoreGroupChatRepository
+}
+
+// NewFirestoreGroupMessageRepository creates a GroupMessageRepository backed by the
+// "messages" subcollection of every group chat document. Each message carries a
+// "seq" number, taken from the chat's "message_count", that orders the history.
+func NewFirestoreGroupMessageRepository(client FirestoreClient) GroupMessageRepository {
+	return &firestoreGroupMessageRepository{
+		client: client,
+		chats:  &firestoreGroupChatRepository{client: client},
+	}
+}
+
+func groupMessagesCollection(chatRef *firestore.DocumentRef) *firestore.CollectionRef {
+	return chatRef.Collection("messages")
+}
+
+func decodeGroupMessage(doc *firestore.DocumentSnapshot) models.BaseMessage {
+	return mappers.MapBaseMessageFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreGroupMessageRepository) messages(ctx context.Context, chatID string) (*firestore.CollectionRef, error) {
+	chatDoc, err := r.chats.findDoc(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	return groupMessagesCollection(chatDoc.Ref), nil
+}
+
+func (r *firestoreGroupMessageRepository) Append(ctx context.Context, chatID string, message *models.BaseMessage) error {
+	chatDoc, err := r.chats.findDoc(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	if message.ID == "" {
+		message.ID = uuid.New().String()
+	}
+
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		chat, err := tx.Get(chatDoc.Ref)
+		if err != nil {
+			if isNotFound(err) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		seq := messageSeq(chat.Data(), "message_count") + 1
+		data := mappers.MapBaseMessageGoToFirestore(*message)
+		data["seq"] = seq
+
+		if err := tx.Create(groupMessagesCollection(chatDoc.Ref).Doc(message.ID), data); err != nil {
+			return err
+		}
+		return tx.Update(chatDoc.Ref, []firestore.Update{
+			{Path: "message_count", Value: seq},
+			{Path: "updated_at", Value: time.Now()},
+		})
+	})
+}
+
+func (r *firestoreGroupMessageRepository) Get(ctx context.Context, chatID, messageID string) (*models.BaseMessage, error) {
+	messages, err := r.messages(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	return getDocument(ctx, messages.Doc(messageID), mappers.MapBaseMessageFirestoreToGo)
+}
+
+func (r *firestoreGroupMessageRepository) Update(ctx context.Context, chatID, messageID string, fn func(*models.BaseMessage) error) (*models.BaseMessage, error) {
+	messages, err := r.messages(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	return updateDocument(ctx, r.client, messages.Doc(messageID),
+		mappers.MapBaseMessageFirestoreToGo, mappers.MapBaseMessageGoToFirestore, fn)
+}
+
+func (r *firestoreGroupMessageRepository) List(ctx context.Context, chatID string, page MessagePage) ([]models.BaseMessage, error) {
+	messages, err := r.messages(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	return listSequencedMessages(ctx, messages, page, decodeGroupMessage)
+}
+
+func (r *firestoreGroupMessageRepository) CountUnread(ctx context.Context, chatID, userID string) (int, error) {
+	messages, err := r.messages(ctx, chatID)
+	if err != nil {
+		return 0, err
+	}
+
+	total, err := countDocuments(ctx, messages.Query)
+	if err != nil {
+		return 0, err
+	}
+
+	read, err := countDocuments(ctx, messages.WherePath(firestore.FieldPath{"read_status", userID}, "==", true))
+	if err != nil {
+		return 0, err
+	}
+
+	return total - read, nil
+}
+
+func (r *firestoreGroupMessageRepository) Seq(ctx context.Context, chatID, messageID string) (int64, error) {
+	messages, err := r.messages(ctx, chatID)
+	if err != nil {
+		return 0, err
+	}
+	return cursorSeq(ctx, messages, messageID)
+}
+
+func (r *firestoreGroupMessageRepository) CountAfter(ctx context.Context, chatID string, seq int64) (int, error) {
+	messages, err := r.messages(ctx, chatID)
+	if err != nil {
+		return 0, err
+	}
+	return countDocuments(ctx, messages.Where("seq", ">", seq))
+}
+
+func (r *firestoreGroupMessageRepository) DeleteAll(ctx context.Context, chatID string) error {
+	messages, err := r.messages(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	iter := messages.DocumentRefs(ctx)
+	for {
+		ref, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := ref.Delete(ctx); err != nil {
+			return err
+		}
+	}
+}
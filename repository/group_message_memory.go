@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryGroupMessageRepository struct {
+	mu       sync.Mutex
+	messages map[string][]models.BaseMessage
+}
+
+// NewMemoryGroupMessageRepository creates an in-memory GroupMessageRepository
+func NewMemoryGroupMessageRepository() GroupMessageRepository {
+	return &memoryGroupMessageRepository{messages: make(map[string][]models.BaseMessage)}
+}
+
+// indexOf returns the position of a message in the chat history, or -1
+func (r *memoryGroupMessageRepository) indexOf(chatID, messageID string) int {
+	for i, message := range r.messages[chatID] {
+		if message.ID == messageID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *memoryGroupMessageRepository) Append(ctx context.Context, chatID string, message *models.BaseMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if message.ID == "" {
+		message.ID = uuid.New().String()
+	}
+	r.messages[chatID] = append(r.messages[chatID], clone(*message))
+	return nil
+}
+
+func (r *memoryGroupMessageRepository) Get(ctx context.Context, chatID, messageID string) (*models.BaseMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(chatID, messageID)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+
+	message := clone(r.messages[chatID][i])
+	return &message, nil
+}
+
+func (r *memoryGroupMessageRepository) Update(ctx context.Context, chatID, messageID string, fn func(*models.BaseMessage) error) (*models.BaseMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(chatID, messageID)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+
+	message := clone(r.messages[chatID][i])
+	if err := fn(&message); err != nil {
+		return nil, err
+	}
+	r.messages[chatID][i] = clone(message)
+
+	return &message, nil
+}
+
+func (r *memoryGroupMessageRepository) List(ctx context.Context, chatID string, page MessagePage) ([]models.BaseMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return pageMessages(r.messages[chatID], func(message models.BaseMessage) string { return message.ID }, page)
+}
+
+func (r *memoryGroupMessageRepository) CountUnread(ctx context.Context, chatID, userID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unread := 0
+	for _, message := range r.messages[chatID] {
+		if !isMessageRead(message, userID) {
+			unread++
+		}
+	}
+	return unread, nil
+}
+
+func (r *memoryGroupMessageRepository) Seq(ctx context.Context, chatID, messageID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(chatID, messageID)
+	if i < 0 {
+		return 0, ErrNotFound
+	}
+	return int64(i + 1), nil
+}
+
+func (r *memoryGroupMessageRepository) CountAfter(ctx context.Context, chatID string, seq int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if after := len(r.messages[chatID]) - int(seq); after > 0 {
+		return after, nil
+	}
+	return 0, nil
+}
+
+func (r *memoryGroupMessageRepository) DeleteAll(ctx context.Context, chatID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.messages, chatID)
+	return nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"google.golang.org/api/iterator"
+)
+
+// legacyMessageBatchSize keeps each migration transaction below Firestore's 500 writes limit
+const legacyMessageBatchSize = 400
+
+// migrateFirestoreGroupChatMessages moves the messages still embedded in the
+// "messages" array of group chat documents to the "messages" subcollection.
+// An interrupted run resumes where it stopped.
+func migrateFirestoreGroupChatMessages(ctx context.Context, client FirestoreClient) error {
+	iter := client.Collection("group_chats").Documents(ctx)
+	defer iter.Stop()
+
+	migrated := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to iterate group chats: %v", err)
+		}
+
+		for {
+			moved, err := migrateLegacyMessageBatch(ctx, client, doc.Ref)
+			if err != nil {
+				return fmt.Errorf("failed to migrate messages of group chat %s: %v", doc.Ref.ID, err)
+			}
+			if moved == 0 {
+				break
+			}
+			migrated += moved
+		}
+	}
+
+	log.Printf("Migrated %d group chat messages to the message store", migrated)
+	return nil
+}
+
+// legacyMessageRecord is a message moved out of the array of its group chat
+type legacyMessageRecord struct {
+	id     string
+	record map[string]interface{}
+}
+
+// nextLegacyMessageBatch returns the oldest embedded messages of a group chat document,
+// numbered after its message_count, and the updates that remove them from the document.
+// It returns no updates once the document embeds no messages anymore.
+func nextLegacyMessageBatch(data map[string]interface{}) ([]legacyMessageRecord, []firestore.Update) {
+	legacy, _ := data["messages"].([]interface{})
+	if len(legacy) == 0 {
+		if _, ok := data["messages"]; !ok {
+			return nil, nil
+		}
+		return nil, []firestore.Update{{Path: "messages", Value: firestore.Delete}}
+	}
+
+	batch := legacy
+	if len(batch) > legacyMessageBatchSize {
+		batch = batch[:legacyMessageBatchSize]
+	}
+
+	seq := messageSeq(data, "message_count")
+	records := make([]legacyMessageRecord, 0, len(batch))
+	for _, message := range mappers.GetBaseMessagesArrayFromFirestore(map[string]interface{}{"messages": batch}, "messages") {
+		if message.ID == "" {
+			message.ID = uuid.New().String()
+		}
+		seq++
+
+		record := mappers.MapBaseMessageGoToFirestore(message)
+		record["seq"] = seq
+		records = append(records, legacyMessageRecord{id: message.ID, record: record})
+	}
+
+	var remaining interface{} = firestore.Delete
+	if len(legacy) > len(batch) {
+		remaining = legacy[len(batch):]
+	}
+
+	return records, []firestore.Update{
+		{Path: "messages", Value: remaining},
+		{Path: "message_count", Value: seq},
+	}
+}
+
+// migrateLegacyMessageBatch moves the oldest embedded messages of a chat to its
+// subcollection and returns how many were moved
+func migrateLegacyMessageBatch(ctx context.Context, client FirestoreClient, chatRef *firestore.DocumentRef) (int, error) {
+	moved := 0
+
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		moved = 0
+
+		doc, err := tx.Get(chatRef)
+		if err != nil {
+			return err
+		}
+
+		records, updates := nextLegacyMessageBatch(doc.Data())
+		if len(updates) == 0 {
+			return nil
+		}
+		for _, message := range records {
+			if err := tx.Set(groupMessagesCollection(chatRef).Doc(message.id), message.record); err != nil {
+				return err
+			}
+		}
+
+		moved = len(records)
+		return tx.Update(chatRef, updates)
+	})
+
+	return moved, err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// MessagePage selects a window of a chat history. Before and After are
+// message IDs; without any cursor the latest messages are returned.
+type MessagePage struct {
+	Before string
+	After  string
+	Limit  int
+}
+
+// GroupMessageRepository stores the messages of group chats, one record per message
+type GroupMessageRepository interface {
+	// Append atomically stores the message after the last message of the chat
+	Append(ctx context.Context, chatID string, message *models.BaseMessage) error
+	Get(ctx context.Context, chatID, messageID string) (*models.BaseMessage, error)
+	// Update atomically applies fn to the stored message and saves the result
+	Update(ctx context.Context, chatID, messageID string, fn func(*models.BaseMessage) error) (*models.BaseMessage, error)
+	// List returns one page of messages, oldest first
+	List(ctx context.Context, chatID string, page MessagePage) ([]models.BaseMessage, error)
+	// CountUnread counts the messages whose read status is not set for the user. Read
+	// positions replaced the read status; it only counts for the users without one.
+	CountUnread(ctx context.Context, chatID, userID string) (int, error)
+	// Seq returns the position of a message in the chat history, starting at 1
+	Seq(ctx context.Context, chatID, messageID string) (int64, error)
+	// CountAfter counts the messages after the position seq
+	CountAfter(ctx context.Context, chatID string, seq int64) (int, error)
+	// DeleteAll removes every message of the chat
+	DeleteAll(ctx context.Context, chatID string) error
+}
+
+// isMessageRead reports whether the user has read the message
+func isMessageRead(message models.BaseMessage, userID string) bool {
+	return message.ReadStatus[userID]
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sqlGroupRepository struct {
+	db *gorm.DB
+}
+
+// NewSQLGroupRepository creates a GroupRepository that keeps groups and their
+// members, topics, events, resources and rules in relational tables
+func NewSQLGroupRepository(db *gorm.DB) GroupRepository {
+	return &sqlGroupRepository{db: db}
+}
+
+func preloadGroup(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Members", func(db *gorm.DB) *gorm.DB { return db.Order("joined_at") }).
+		Preload("Topics").
+		Preload("Admins").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("start_time") }).
+		Preload("Events.Attendees").
+		Preload("Resources", func(db *gorm.DB) *gorm.DB { return db.Order("added_at") }).
+		Preload("Rules", func(db *gorm.DB) *gorm.DB {
+			return db.Order(clause.OrderByColumn{Column: clause.Column{Name: "order"}})
+		})
+}
+
+// deleteGroupTree removes the group row and every row that belongs to it.
+// It reports whether the group existed.
+func deleteGroupTree(tx *gorm.DB, groupID string) (bool, error) {
+	eventIDs := tx.Model(&models.Event{}).Select("id").Where("group_id = ?", groupID)
+
+	steps := []*gorm.DB{
+		tx.Where("group_id = ?", groupID).Delete(&models.Member{}),
+		tx.Exec("DELETE FROM group_topics WHERE group_id = ?", groupID),
+		tx.Exec("DELETE FROM group_admins WHERE group_id = ?", groupID),
+		tx.Exec("DELETE FROM event_attendees WHERE event_id IN (?)", eventIDs),
+		tx.Where("group_id = ?", groupID).Delete(&models.Event{}),
+		tx.Where("group_id = ?", groupID).Delete(&models.Resource{}),
+		tx.Where("group_id = ?", groupID).Delete(&models.Rule{}),
+	}
+	for _, step := range steps {
+		if step.Error != nil {
+			return false, step.Error
+		}
+	}
+
+	result := tx.Where("id = ?", groupID).Delete(&models.Group{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// deleteRow removes a row that nothing else belongs to
+func deleteRow[T any](tx *gorm.DB, row *T) error {
+	return tx.Delete(row).Error
+}
+
+// deleteEvent removes an event and its attendee links
+func deleteEvent(tx *gorm.DB, event *models.Event) error {
+	if err := tx.Exec("DELETE FROM event_attendees WHERE event_id = ?", event.ID).Error; err != nil {
+		return err
+	}
+	return tx.Delete(event).Error
+}
+
+// Save writes the group row and the rows of its members, events, resources and rules
+// that differ from the stored group, then replaces its topic, admin and attendee links
+func (r *sqlGroupRepository) Save(ctx context.Context, group *models.Group) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var stored models.Group
+		if err := preloadGroup(lockForUpdate(tx)).Where("id = ?", group.ID).Limit(1).Find(&stored).Error; err != nil {
+			return err
+		}
+
+		record := *group
+		if err := upsert(tx, &record); err != nil {
+			return err
+		}
+
+		if err := syncRows(tx, stored.Members, group.Members, deleteRow[models.Member]); err != nil {
+			return err
+		}
+		if err := syncRows(tx, stored.Events, group.Events, deleteEvent); err != nil {
+			return err
+		}
+		if err := syncRows(tx, stored.Resources, group.Resources, deleteRow[models.Resource]); err != nil {
+			return err
+		}
+		if err := syncRows(tx, stored.Rules, group.Rules, deleteRow[models.Rule]); err != nil {
+			return err
+		}
+
+		for _, event := range group.Events {
+			if err := saveUsers(tx, event.Attendees); err != nil {
+				return err
+			}
+			if err := replaceAssociation(tx, &models.Event{ID: event.ID}, "Attendees", event.Attendees); err != nil {
+				return err
+			}
+		}
+		if err := saveUsers(tx, group.Admins); err != nil {
+			return err
+		}
+		if err := replaceAssociation(tx, &models.Group{ID: group.ID}, "Admins", group.Admins); err != nil {
+			return err
+		}
+		return replaceAssociation(tx, &models.Group{ID: group.ID}, "Topics", group.Topics)
+	})
+}
+
+func (r *sqlGroupRepository) Get(ctx context.Context, groupID string) (*models.Group, error) {
+	var group models.Group
+	err := preloadGroup(r.db.WithContext(ctx)).Where("id = ?", groupID).First(&group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *sqlGroupRepository) Delete(ctx context.Context, groupID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		found, err := deleteGroupTree(tx, groupID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// List maps the Firestore field paths of the filters to columns, the
+// embedded category being stored as category_* columns
+func (r *sqlGroupRepository) List(ctx context.Context, filters map[string]interface{}) ([]models.Group, error) {
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(&models.Group{}); err != nil {
+		return nil, err
+	}
+
+	query := preloadGroup(r.db.WithContext(ctx))
+	for path, value := range filters {
+		column := strings.ReplaceAll(path, ".", "_")
+		if _, ok := stmt.Schema.FieldsByDBName[column]; !ok {
+			return nil, fmt.Errorf("unsupported group filter %q", path)
+		}
+		query = query.Where(clause.Eq{Column: clause.Column{Name: column}, Value: value})
+	}
+
+	var groups []models.Group
+	if err := query.Order("created_at").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreJobRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreJobRepository creates a JobRepository backed by the "jobs" collection
+func NewFirestoreJobRepository(client FirestoreClient) JobRepository {
+	return &firestoreJobRepository{client: client}
+}
+
+func (r *firestoreJobRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("jobs")
+}
+
+func decodeJob(doc *firestore.DocumentSnapshot) models.Job {
+	return mappers.MapJobFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreJobRepository) Create(ctx context.Context, job *models.Job) error {
+	_, err := r.collection().Doc(job.ID).Set(ctx, mappers.MapJobGoToFirestore(*job))
+	return err
+}
+
+func (r *firestoreJobRepository) Get(ctx context.Context, jobID string) (*models.Job, error) {
+	return getDocument(ctx, r.collection().Doc(jobID), mappers.MapJobFirestoreToGo)
+}
+
+func (r *firestoreJobRepository) Update(ctx context.Context, jobID string, fn func(*models.Job) error) (*models.Job, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(jobID),
+		mappers.MapJobFirestoreToGo, mappers.MapJobGoToFirestore, fn)
+}
+
+func (r *firestoreJobRepository) Delete(ctx context.Context, jobID string) error {
+	_, err := r.collection().Doc(jobID).Delete(ctx)
+	return err
+}
+
+func (r *firestoreJobRepository) ListByUser(ctx context.Context, userID string) ([]models.Job, error) {
+	return queryDocuments(ctx, r.collection().Where("user_id", "==", userID), decodeJob)
+}
+
+func (r *firestoreJobRepository) ListByUserAndStatus(ctx context.Context, userID string, status models.JobStatus) ([]models.Job, error) {
+	query := r.collection().
+		Where("user_id", "==", userID).
+		Where("status", "==", string(status))
+	return queryDocuments(ctx, query, decodeJob)
+}
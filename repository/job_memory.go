@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryJobRepository struct {
+	store *memoryStore[models.Job]
+}
+
+// NewMemoryJobRepository creates an in-memory JobRepository
+func NewMemoryJobRepository() JobRepository {
+	return &memoryJobRepository{store: newMemoryStore[models.Job]()}
+}
+
+func (r *memoryJobRepository) Create(ctx context.Context, job *models.Job) error {
+	r.store.put(job.ID, *job)
+	return nil
+}
+
+func (r *memoryJobRepository) Get(ctx context.Context, jobID string) (*models.Job, error) {
+	return r.store.get(jobID)
+}
+
+func (r *memoryJobRepository) Update(ctx context.Context, jobID string, fn func(*models.Job) error) (*models.Job, error) {
+	return r.store.update(jobID, fn)
+}
+
+func (r *memoryJobRepository) Delete(ctx context.Context, jobID string) error {
+	return r.store.delete(jobID)
+}
+
+func (r *memoryJobRepository) ListByUser(ctx context.Context, userID string) ([]models.Job, error) {
+	return r.store.list(func(job models.Job) bool { return job.UserID == userID }), nil
+}
+
+func (r *memoryJobRepository) ListByUserAndStatus(ctx context.Context, userID string, status models.JobStatus) ([]models.Job, error) {
+	return r.store.list(func(job models.Job) bool {
+		return job.UserID == userID && job.Status == status
+	}), nil
+}
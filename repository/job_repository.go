@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// JobRepository stores tracked job applications
+type JobRepository interface {
+	Create(ctx context.Context, job *models.Job) error
+	Get(ctx context.Context, jobID string) (*models.Job, error)
+	// Update atomically applies fn to the stored job and saves the result
+	Update(ctx context.Context, jobID string, fn func(*models.Job) error) (*models.Job, error)
+	Delete(ctx context.Context, jobID string) error
+	ListByUser(ctx context.Context, userID string) ([]models.Job, error)
+	ListByUserAndStatus(ctx context.Context, userID string, status models.JobStatus) ([]models.Job, error)
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreLinkedInApplicationRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreLinkedInApplicationRepository creates a LinkedInApplicationRepository backed by
+// the "applications" subcollection of the user documents in "job_applications"
+func NewFirestoreLinkedInApplicationRepository(client FirestoreClient) LinkedInApplicationRepository {
+	return &firestoreLinkedInApplicationRepository{client: client}
+}
+
+func (r *firestoreLinkedInApplicationRepository) applications(userID string) *firestore.CollectionRef {
+	return r.client.Collection("job_applications").Doc(userID).Collection("applications")
+}
+
+func (r *firestoreLinkedInApplicationRepository) Create(ctx context.Context, userID string, application *models.LinkedInJobApplication) error {
+	_, err := r.applications(userID).Doc(application.JobID).Set(ctx, application)
+	return err
+}
+
+func (r *firestoreLinkedInApplicationRepository) ListByUser(ctx context.Context, userID string) ([]models.LinkedInJobApplication, error) {
+	docs, err := r.applications(userID).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	applications := make([]models.LinkedInJobApplication, 0, len(docs))
+	for _, doc := range docs {
+		var application models.LinkedInJobApplication
+		if err := doc.DataTo(&application); err != nil {
+			return nil, fmt.Errorf("failed to parse job application: %v", err)
+		}
+		applications = append(applications, application)
+	}
+	return applications, nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryLinkedInApplicationRepository struct {
+	// store holds the applications of each user, keyed by user ID
+	store *memoryStore[[]models.LinkedInJobApplication]
+}
+
+// NewMemoryLinkedInApplicationRepository creates an in-memory LinkedInApplicationRepository
+func NewMemoryLinkedInApplicationRepository() LinkedInApplicationRepository {
+	return &memoryLinkedInApplicationRepository{store: newMemoryStore[[]models.LinkedInJobApplication]()}
+}
+
+func (r *memoryLinkedInApplicationRepository) Create(ctx context.Context, userID string, application *models.LinkedInJobApplication) error {
+	_, err := r.store.upsert(userID, func(applications *[]models.LinkedInJobApplication) error {
+		*applications = append(*applications, *application)
+		return nil
+	})
+	return err
+}
+
+func (r *memoryLinkedInApplicationRepository) ListByUser(ctx context.Context, userID string) ([]models.LinkedInJobApplication, error) {
+	applications, err := r.store.get(userID)
+	if errors.Is(err, ErrNotFound) {
+		return []models.LinkedInJobApplication{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return *applications, nil
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// LinkedInApplicationRepository stores the LinkedIn job applications saved by the users
+type LinkedInApplicationRepository interface {
+	Create(ctx context.Context, userID string, application *models.LinkedInJobApplication) error
+	ListByUser(ctx context.Context, userID string) ([]models.LinkedInJobApplication, error)
+}
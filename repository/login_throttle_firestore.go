@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreLoginThrottleRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreLoginThrottleRepository creates a LoginThrottleRepository backed by the "login_throttles" collection
+func NewFirestoreLoginThrottleRepository(client FirestoreClient) LoginThrottleRepository {
+	return &firestoreLoginThrottleRepository{client: client}
+}
+
+func (r *firestoreLoginThrottleRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("login_throttles")
+}
+
+func (r *firestoreLoginThrottleRepository) Get(ctx context.Context, id string) (*models.LoginThrottle, error) {
+	return getDocument(ctx, r.collection().Doc(id), mappers.MapLoginThrottleFirestoreToGo)
+}
+
+func (r *firestoreLoginThrottleRepository) Record(ctx context.Context, id string, fn func(*models.LoginThrottle) error) (*models.LoginThrottle, error) {
+	return upsertDocument(ctx, r.client, r.collection().Doc(id),
+		mappers.MapLoginThrottleFirestoreToGo, mappers.MapLoginThrottleGoToFirestore, func(throttle *models.LoginThrottle) error {
+			throttle.ID = id
+			return fn(throttle)
+		})
+}
+
+func (r *firestoreLoginThrottleRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+	_, err := r.collection().Doc(id).Delete(ctx)
+	return err
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryLoginThrottleRepository struct {
+	store *memoryStore[models.LoginThrottle]
+}
+
+// NewMemoryLoginThrottleRepository creates an in-memory LoginThrottleRepository
+func NewMemoryLoginThrottleRepository() LoginThrottleRepository {
+	return &memoryLoginThrottleRepository{store: newMemoryStore[models.LoginThrottle]()}
+}
+
+func (r *memoryLoginThrottleRepository) Get(ctx context.Context, id string) (*models.LoginThrottle, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryLoginThrottleRepository) Record(ctx context.Context, id string, fn func(*models.LoginThrottle) error) (*models.LoginThrottle, error) {
+	return r.store.upsert(id, func(throttle *models.LoginThrottle) error {
+		throttle.ID = id
+		return fn(throttle)
+	})
+}
+
+func (r *memoryLoginThrottleRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
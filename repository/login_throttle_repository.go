@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// LoginThrottleRepository stores the failed login counters of the accounts and IP addresses
+type LoginThrottleRepository interface {
+	Get(ctx context.Context, id string) (*models.LoginThrottle, error)
+	// Record atomically applies fn to the stored counter, or to an empty one with the ID
+	// when there is none, and saves the result
+	Record(ctx context.Context, id string, fn func(*models.LoginThrottle) error) (*models.LoginThrottle, error)
+	Delete(ctx context.Context, id string) error
+}
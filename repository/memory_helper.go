@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// memoryStore is a concurrency-safe keyed store used by the in-memory repositories.
+// Values are deep copied on the way in and out so callers never share state with the store.
+type memoryStore[T any] struct {
+	mu    sync.RWMutex
+	items map[string]T
+	order []string
+}
+
+func newMemoryStore[T any]() *memoryStore[T] {
+	return &memoryStore[T]{
+		items: make(map[string]T),
+	}
+}
+
+func (m *memoryStore[T]) get(id string) (*T, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	item, ok := m.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	value := clone(item)
+	return &value, nil
+}
+
+func (m *memoryStore[T]) put(id string, value T) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.items[id]; !ok {
+		m.order = append(m.order, id)
+	}
+	m.items[id] = clone(value)
+}
+
+func (m *memoryStore[T]) delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.items[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(m.items, id)
+	for i, existing := range m.order {
+		if existing == id {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// update applies fn to a copy of the stored value and saves it when fn succeeds
+func (m *memoryStore[T]) update(id string, fn func(*T) error) (*T, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	value := clone(item)
+	if err := fn(&value); err != nil {
+		return nil, err
+	}
+	m.items[id] = clone(value)
+
+	return &value, nil
+}
+
+// upsert applies fn to a copy of the stored value, or to the zero value when there is
+// none, and saves it when fn succeeds
+func (m *memoryStore[T]) upsert(id string, fn func(*T) error) (*T, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[id]
+	value := clone(item)
+	if err := fn(&value); err != nil {
+		return nil, err
+	}
+	if !ok {
+		m.order = append(m.order, id)
+	}
+	m.items[id] = clone(value)
+
+	return &value, nil
+}
+
+// upsertMany applies fn to copies of the stored values of ids, the zero value standing in
+// for the missing ones, and saves all of them when fn succeeds
+func (m *memoryStore[T]) upsertMany(ids []string, fn func(map[string]*T) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values := make(map[string]*T, len(ids))
+	for _, id := range ids {
+		value := clone(m.items[id])
+		values[id] = &value
+	}
+	if err := fn(values); err != nil {
+		return err
+	}
+
+	for id, value := range values {
+		if _, ok := m.items[id]; !ok {
+			m.order = append(m.order, id)
+		}
+		m.items[id] = clone(*value)
+	}
+	return nil
+}
+
+// list returns, in insertion order, every value accepted by match
+func (m *memoryStore[T]) list(match func(T) bool) []T {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := []T{}
+	for _, id := range m.order {
+		item := m.items[id]
+		if match == nil || match(item) {
+			results = append(results, clone(item))
+		}
+	}
+	return results
+}
+
+// clone deep copies a value through its JSON representation
+func clone[T any](value T) T {
+	var copied T
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return value
+	}
+	return copied
+}
+
+// pageMessages returns copies of one page of an ordered message history, oldest first
+func pageMessages[T any](history []T, id func(T) string, page MessagePage) ([]T, error) {
+	indexOf := func(messageID string) int {
+		for i, message := range history {
+			if id(message) == messageID {
+				return i
+			}
+		}
+		return -1
+	}
+
+	start, end := 0, len(history)
+	if page.After != "" {
+		i := indexOf(page.After)
+		if i < 0 {
+			return nil, ErrNotFound
+		}
+		start = i + 1
+	}
+	if page.Before != "" {
+		i := indexOf(page.Before)
+		if i < 0 {
+			return nil, ErrNotFound
+		}
+		end = i
+	}
+
+	if page.Limit > 0 && end-start > page.Limit {
+		if page.After != "" {
+			end = start + page.Limit
+		} else {
+			start = end - page.Limit
+		}
+	}
+
+	results := []T{}
+	for i := start; i < end; i++ {
+		results = append(results, clone(history[i]))
+	}
+	return results, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreNewsletterRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreNewsletterRepository creates a NewsletterRepository backed by the "newsletters" collection
+func NewFirestoreNewsletterRepository(client FirestoreClient) NewsletterRepository {
+	return &firestoreNewsletterRepository{client: client}
+}
+
+func (r *firestoreNewsletterRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("newsletters")
+}
+
+func decodeNewsletterSubscription(doc *firestore.DocumentSnapshot) models.NewsletterSubscription {
+	subscription := mappers.MapNewsletterSubscriptionFirestoreToGo(doc.Data())
+	subscription.ID = doc.Ref.ID
+	return subscription
+}
+
+func (r *firestoreNewsletterRepository) Create(ctx context.Context, subscription *models.NewsletterSubscription) error {
+	_, err := r.collection().Doc(subscription.ID).Set(ctx, mappers.MapNewsletterSubscriptionGoToFirestore(*subscription))
+	return err
+}
+
+func (r *firestoreNewsletterRepository) GetByEmail(ctx context.Context, email string) (*models.NewsletterSubscription, error) {
+	subscriptions, err := queryDocuments(ctx, r.collection().Where("email", "==", email).Limit(1), decodeNewsletterSubscription)
+	if err != nil {
+		return nil, err
+	}
+	if len(subscriptions) == 0 {
+		return nil, ErrNotFound
+	}
+	return &subscriptions[0], nil
+}
+
+func (r *firestoreNewsletterRepository) Delete(ctx context.Context, subscriptionID string) error {
+	_, err := r.collection().Doc(subscriptionID).Delete(ctx)
+	return err
+}
+
+func (r *firestoreNewsletterRepository) List(ctx context.Context) ([]models.NewsletterSubscription, error) {
+	return queryDocuments(ctx, r.collection().Query, decodeNewsletterSubscription)
+}
+
+func (r *firestoreNewsletterRepository) Count(ctx context.Context) (int, error) {
+	return countDocuments(ctx, r.collection().Query)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryNewsletterRepository struct {
+	store *memoryStore[models.NewsletterSubscription]
+}
+
+// NewMemoryNewsletterRepository creates an in-memory NewsletterRepository
+func NewMemoryNewsletterRepository() NewsletterRepository {
+	return &memoryNewsletterRepository{store: newMemoryStore[models.NewsletterSubscription]()}
+}
+
+func (r *memoryNewsletterRepository) Create(ctx context.Context, subscription *models.NewsletterSubscription) error {
+	r.store.put(subscription.ID, *subscription)
+	return nil
+}
+
+func (r *memoryNewsletterRepository) GetByEmail(ctx context.Context, email string) (*models.NewsletterSubscription, error) {
+	subscriptions := r.store.list(func(subscription models.NewsletterSubscription) bool {
+		return subscription.Email == email
+	})
+	if len(subscriptions) == 0 {
+		return nil, ErrNotFound
+	}
+	return &subscriptions[0], nil
+}
+
+func (r *memoryNewsletterRepository) Delete(ctx context.Context, subscriptionID string) error {
+	return r.store.delete(subscriptionID)
+}
+
+func (r *memoryNewsletterRepository) List(ctx context.Context) ([]models.NewsletterSubscription, error) {
+	return r.store.list(nil), nil
+}
+
+func (r *memoryNewsletterRepository) Count(ctx context.Context) (int, error) {
+	return len(r.store.list(nil)), nil
+}
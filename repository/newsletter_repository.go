@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// NewsletterRepository stores the newsletter subscriptions
+type NewsletterRepository interface {
+	Create(ctx context.Context, subscription *models.NewsletterSubscription) error
+	GetByEmail(ctx context.Context, email string) (*models.NewsletterSubscription, error)
+	Delete(ctx context.Context, subscriptionID string) error
+	List(ctx context.Context) ([]models.NewsletterSubscription, error)
+	Count(ctx context.Context) (int, error)
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type firestoreNotificationRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreNotificationRepository creates a NotificationRepository backed by the "notifications" collection
+func NewFirestoreNotificationRepository(client FirestoreClient) NotificationRepository {
+	return &firestoreNotificationRepository{client: client}
+}
+
+func (r *firestoreNotificationRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("notifications")
+}
+
+func decodeNotification(doc *firestore.DocumentSnapshot) models.Notification {
+	return mappers.MapNotificationFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreNotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	_, err := r.collection().Doc(notification.ID).Set(ctx, mappers.MapNotificationGoToFirestore(*notification))
+	return err
+}
+
+func (r *firestoreNotificationRepository) Get(ctx context.Context, notificationID string) (*models.Notification, error) {
+	return getDocument(ctx, r.collection().Doc(notificationID), mappers.MapNotificationFirestoreToGo)
+}
+
+func (r *firestoreNotificationRepository) Update(ctx context.Context, notificationID string, fn func(*models.Notification) error) (*models.Notification, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(notificationID),
+		mappers.MapNotificationFirestoreToGo, mappers.MapNotificationGoToFirestore, fn)
+}
+
+func (r *firestoreNotificationRepository) Delete(ctx context.Context, notificationID string) error {
+	_, err := r.collection().Doc(notificationID).Delete(ctx)
+	return err
+}
+
+// startAfter positions the query after the given notification
+func (r *firestoreNotificationRepository) startAfter(ctx context.Context, query firestore.Query, afterID string) (firestore.Query, error) {
+	if afterID == "" {
+		return query, nil
+	}
+
+	lastDoc, err := r.collection().Doc(afterID).Get(ctx)
+	if err != nil {
+		return query, fmt.Errorf("failed to get last notification: %v", err)
+	}
+	return query.StartAfter(lastDoc), nil
+}
+
+func (r *firestoreNotificationRepository) ListByUser(ctx context.Context, userID string, afterID string) ([]models.Notification, error) {
+	query, err := r.startAfter(ctx, r.collection().Where("user_id", "==", userID), afterID)
+	if err != nil {
+		return nil, err
+	}
+	return queryDocuments(ctx, query, decodeNotification)
+}
+
+func (r *firestoreNotificationRepository) ListTargeted(ctx context.Context, userID string, limit int, afterID string) ([]models.Notification, error) {
+	query, err := r.startAfter(ctx, r.collection().Where("targeted_users", "array-contains", userID), afterID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = query.OrderBy("created_at", firestore.Desc).OrderBy("__name__", firestore.Desc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	notifications := []models.Notification{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			// Fall back to an unordered query while the composite index is not ready
+			if status.Code(err) == codes.FailedPrecondition {
+				return r.listTargetedWithoutOrdering(ctx, userID, limit)
+			}
+			return nil, fmt.Errorf("error iterating notifications: %v", err)
+		}
+
+		notifications = append(notifications, decodeNotification(doc))
+	}
+
+	return notifications, nil
+}
+
+func (r *firestoreNotificationRepository) listTargetedWithoutOrdering(ctx context.Context, userID string, limit int) ([]models.Notification, error) {
+	query := r.collection().Where("targeted_users", "array-contains", userID)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	notifications, err := queryDocuments(ctx, query, decodeNotification)
+	if err != nil {
+		return nil, fmt.Errorf("error in fallback query: %v", err)
+	}
+
+	// Sort notifications by created_at manually since we can't use Firestore ordering
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+
+	return notifications, nil
+}
+
+func (r *firestoreNotificationRepository) ListDue(ctx context.Context, now time.Time) ([]models.Notification, error) {
+	query := r.collection().
+		Where("status", "==", string(models.NotificationStatusPending)).
+		Where("scheduled_at", "<=", now)
+	return queryDocuments(ctx, query, decodeNotification)
+}
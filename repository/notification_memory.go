@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryNotificationRepository struct {
+	store *memoryStore[models.Notification]
+}
+
+// NewMemoryNotificationRepository creates an in-memory NotificationRepository
+func NewMemoryNotificationRepository() NotificationRepository {
+	return &memoryNotificationRepository{store: newMemoryStore[models.Notification]()}
+}
+
+func (r *memoryNotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	r.store.put(notification.ID, *notification)
+	return nil
+}
+
+func (r *memoryNotificationRepository) Get(ctx context.Context, notificationID string) (*models.Notification, error) {
+	return r.store.get(notificationID)
+}
+
+func (r *memoryNotificationRepository) Update(ctx context.Context, notificationID string, fn func(*models.Notification) error) (*models.Notification, error) {
+	return r.store.update(notificationID, fn)
+}
+
+func (r *memoryNotificationRepository) Delete(ctx context.Context, notificationID string) error {
+	return r.store.delete(notificationID)
+}
+
+// afterCursor drops every notification up to and including afterID
+func afterCursor(notifications []models.Notification, afterID string) ([]models.Notification, error) {
+	if afterID == "" {
+		return notifications, nil
+	}
+	for i, notification := range notifications {
+		if notification.ID == afterID {
+			return notifications[i+1:], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryNotificationRepository) ListByUser(ctx context.Context, userID string, afterID string) ([]models.Notification, error) {
+	notifications := r.store.list(func(n models.Notification) bool { return n.UserID == userID })
+	return afterCursor(notifications, afterID)
+}
+
+func (r *memoryNotificationRepository) ListTargeted(ctx context.Context, userID string, limit int, afterID string) ([]models.Notification, error) {
+	notifications := r.store.list(func(n models.Notification) bool {
+		for _, targeted := range n.TargetedUsers {
+			if targeted == userID {
+				return true
+			}
+		}
+		return false
+	})
+
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+
+	notifications, err := afterCursor(notifications, afterID)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+func (r *memoryNotificationRepository) ListDue(ctx context.Context, now time.Time) ([]models.Notification, error) {
+	return r.store.list(func(n models.Notification) bool {
+		return n.Status == models.NotificationStatusPending && !n.ScheduledAt.IsZero() && !n.ScheduledAt.After(now)
+	}), nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// NotificationRepository stores notifications
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	Get(ctx context.Context, notificationID string) (*models.Notification, error)
+	// Update atomically applies fn to the stored notification and saves the result
+	Update(ctx context.Context, notificationID string, fn func(*models.Notification) error) (*models.Notification, error)
+	Delete(ctx context.Context, notificationID string) error
+	// ListByUser lists notifications owned by userID, starting after afterID when it is set
+	ListByUser(ctx context.Context, userID string, afterID string) ([]models.Notification, error)
+	// ListTargeted lists notifications targeting userID, newest first.
+	// A limit of 0 returns every notification.
+	ListTargeted(ctx context.Context, userID string, limit int, afterID string) ([]models.Notification, error)
+	// ListDue lists the pending notifications scheduled at or before now
+	ListDue(ctx context.Context, now time.Time) ([]models.Notification, error)
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreOAuthStateRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreOAuthStateRepository creates an OAuthStateRepository backed by the "oauth_states"
+// collection. A Firestore TTL policy on "expires_at" can purge the expired states.
+func NewFirestoreOAuthStateRepository(client FirestoreClient) OAuthStateRepository {
+	return &firestoreOAuthStateRepository{client: client}
+}
+
+func (r *firestoreOAuthStateRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("oauth_states")
+}
+
+func (r *firestoreOAuthStateRepository) Create(ctx context.Context, state *models.OAuthState) error {
+	_, err := r.collection().Doc(state.ID).Create(ctx, mappers.MapOAuthStateGoToFirestore(*state))
+	return err
+}
+
+func (r *firestoreOAuthStateRepository) Update(ctx context.Context, stateID string, fn func(*models.OAuthState) error) (*models.OAuthState, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(stateID),
+		mappers.MapOAuthStateFirestoreToGo, mappers.MapOAuthStateGoToFirestore, fn)
+}
+
+type firestoreIdentityRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreIdentityRepository creates an IdentityRepository backed by the "identities" collection
+func NewFirestoreIdentityRepository(client FirestoreClient) IdentityRepository {
+	return &firestoreIdentityRepository{client: client}
+}
+
+func (r *firestoreIdentityRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("identities")
+}
+
+func decodeIdentity(doc *firestore.DocumentSnapshot) models.Identity {
+	return mappers.MapIdentityFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreIdentityRepository) Get(ctx context.Context, identityID string) (*models.Identity, error) {
+	return getDocument(ctx, r.collection().Doc(identityID), mappers.MapIdentityFirestoreToGo)
+}
+
+func (r *firestoreIdentityRepository) Put(ctx context.Context, identity *models.Identity) error {
+	_, err := r.collection().Doc(identity.ID).Set(ctx, mappers.MapIdentityGoToFirestore(*identity))
+	return err
+}
+
+func (r *firestoreIdentityRepository) ListByUser(ctx context.Context, uid string) ([]models.Identity, error) {
+	return queryDocuments(ctx, r.collection().Where("uid", "==", uid), decodeIdentity)
+}
+
+func (r *firestoreIdentityRepository) Delete(ctx context.Context, identityID string) error {
+	if _, err := r.Get(ctx, identityID); err != nil {
+		return err
+	}
+	_, err := r.collection().Doc(identityID).Delete(ctx)
+	return err
+}
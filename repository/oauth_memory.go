@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryOAuthStateRepository struct {
+	store *memoryStore[models.OAuthState]
+}
+
+// NewMemoryOAuthStateRepository creates an in-memory OAuthStateRepository
+func NewMemoryOAuthStateRepository() OAuthStateRepository {
+	return &memoryOAuthStateRepository{store: newMemoryStore[models.OAuthState]()}
+}
+
+func (r *memoryOAuthStateRepository) Create(ctx context.Context, state *models.OAuthState) error {
+	r.store.put(state.ID, *state)
+	return nil
+}
+
+func (r *memoryOAuthStateRepository) Update(ctx context.Context, stateID string, fn func(*models.OAuthState) error) (*models.OAuthState, error) {
+	return r.store.update(stateID, fn)
+}
+
+type memoryIdentityRepository struct {
+	store *memoryStore[models.Identity]
+}
+
+// NewMemoryIdentityRepository creates an in-memory IdentityRepository
+func NewMemoryIdentityRepository() IdentityRepository {
+	return &memoryIdentityRepository{store: newMemoryStore[models.Identity]()}
+}
+
+func (r *memoryIdentityRepository) Get(ctx context.Context, identityID string) (*models.Identity, error) {
+	return r.store.get(identityID)
+}
+
+func (r *memoryIdentityRepository) Put(ctx context.Context, identity *models.Identity) error {
+	r.store.put(identity.ID, *identity)
+	return nil
+}
+
+func (r *memoryIdentityRepository) ListByUser(ctx context.Context, uid string) ([]models.Identity, error) {
+	return r.store.list(func(identity models.Identity) bool {
+		return identity.UID == uid
+	}), nil
+}
+
+func (r *memoryIdentityRepository) Delete(ctx context.Context, identityID string) error {
+	return r.store.delete(identityID)
+}
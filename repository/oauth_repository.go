@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// OAuthStateRepository stores the authorizations started with OAuth providers
+type OAuthStateRepository interface {
+	Create(ctx context.Context, state *models.OAuthState) error
+	// Update atomically applies fn to the stored state and saves the result
+	Update(ctx context.Context, stateID string, fn func(*models.OAuthState) error) (*models.OAuthState, error)
+}
+
+// IdentityRepository stores the provider accounts linked to the users
+type IdentityRepository interface {
+	Get(ctx context.Context, identityID string) (*models.Identity, error)
+	Put(ctx context.Context, identity *models.Identity) error
+	ListByUser(ctx context.Context, uid string) ([]models.Identity, error)
+	Delete(ctx context.Context, identityID string) error
+}
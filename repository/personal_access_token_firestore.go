@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestorePersonalAccessTokenRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestorePersonalAccessTokenRepository creates a PersonalAccessTokenRepository backed by the
+// "personal_access_tokens" collection. Security rules must keep clients from reading it.
+func NewFirestorePersonalAccessTokenRepository(client FirestoreClient) PersonalAccessTokenRepository {
+	return &firestorePersonalAccessTokenRepository{client: client}
+}
+
+func (r *firestorePersonalAccessTokenRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("personal_access_tokens")
+}
+
+func decodePersonalAccessToken(doc *firestore.DocumentSnapshot) models.PersonalAccessToken {
+	return mappers.MapPersonalAccessTokenFirestoreToGo(doc.Data())
+}
+
+func (r *firestorePersonalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	_, err := r.collection().Doc(token.ID).Create(ctx, mappers.MapPersonalAccessTokenGoToFirestore(*token))
+	return err
+}
+
+func (r *firestorePersonalAccessTokenRepository) Get(ctx context.Context, tokenID string) (*models.PersonalAccessToken, error) {
+	return getDocument(ctx, r.collection().Doc(tokenID), mappers.MapPersonalAccessTokenFirestoreToGo)
+}
+
+func (r *firestorePersonalAccessTokenRepository) Update(ctx context.Context, tokenID string, fn func(*models.PersonalAccessToken) error) (*models.PersonalAccessToken, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(tokenID),
+		mappers.MapPersonalAccessTokenFirestoreToGo, mappers.MapPersonalAccessTokenGoToFirestore, fn)
+}
+
+func (r *firestorePersonalAccessTokenRepository) ListByUser(ctx context.Context, uid string) ([]models.PersonalAccessToken, error) {
+	return queryDocuments(ctx, r.collection().Where("uid", "==", uid), decodePersonalAccessToken)
+}
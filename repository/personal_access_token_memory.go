@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryPersonalAccessTokenRepository struct {
+	store *memoryStore[models.PersonalAccessToken]
+}
+
+// NewMemoryPersonalAccessTokenRepository creates an in-memory PersonalAccessTokenRepository
+func NewMemoryPersonalAccessTokenRepository() PersonalAccessTokenRepository {
+	return &memoryPersonalAccessTokenRepository{store: newMemoryStore[models.PersonalAccessToken]()}
+}
+
+func (r *memoryPersonalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	r.store.put(token.ID, *token)
+	return nil
+}
+
+func (r *memoryPersonalAccessTokenRepository) Get(ctx context.Context, tokenID string) (*models.PersonalAccessToken, error) {
+	return r.store.get(tokenID)
+}
+
+func (r *memoryPersonalAccessTokenRepository) Update(ctx context.Context, tokenID string, fn func(*models.PersonalAccessToken) error) (*models.PersonalAccessToken, error) {
+	return r.store.update(tokenID, fn)
+}
+
+func (r *memoryPersonalAccessTokenRepository) ListByUser(ctx context.Context, uid string) ([]models.PersonalAccessToken, error) {
+	return r.store.list(func(token models.PersonalAccessToken) bool { return token.UID == uid }), nil
+}
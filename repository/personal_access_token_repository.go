@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// PersonalAccessTokenRepository stores the personal access tokens of the users
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, token *models.PersonalAccessToken) error
+	Get(ctx context.Context, tokenID string) (*models.PersonalAccessToken, error)
+	// Update atomically applies fn to the stored token and saves the result
+	Update(ctx context.Context, tokenID string, fn func(*models.PersonalAccessToken) error) (*models.PersonalAccessToken, error)
+	// ListByUser lists every token of the user, revoked and expired ones included
+	ListByUser(ctx context.Context, uid string) ([]models.PersonalAccessToken, error)
+}
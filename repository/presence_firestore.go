@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// firestoreInQueryLimit is the most values an "in" filter of Firestore accepts
+const firestoreInQueryLimit = 30
+
+type firestorePresenceRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestorePresenceRepository creates a PresenceRepository backed by the "presence" collection
+func NewFirestorePresenceRepository(client FirestoreClient) PresenceRepository {
+	return &firestorePresenceRepository{client: client}
+}
+
+func (r *firestorePresenceRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("presence")
+}
+
+func decodePresence(doc *firestore.DocumentSnapshot) models.Presence {
+	return mappers.MapPresenceFirestoreToGo(doc.Data())
+}
+
+func (r *firestorePresenceRepository) Get(ctx context.Context, uid string) (*models.Presence, error) {
+	return getDocument(ctx, r.collection().Doc(uid), mappers.MapPresenceFirestoreToGo)
+}
+
+func (r *firestorePresenceRepository) GetMany(ctx context.Context, uids []string) ([]models.Presence, error) {
+	presences := []models.Presence{}
+	for start := 0; start < len(uids); start += firestoreInQueryLimit {
+		end := start + firestoreInQueryLimit
+		if end > len(uids) {
+			end = len(uids)
+		}
+		batch, err := queryDocuments(ctx, r.collection().Where("uid", "in", uids[start:end]), decodePresence)
+		if err != nil {
+			return nil, err
+		}
+		presences = append(presences, batch...)
+	}
+	return presences, nil
+}
+
+func (r *firestorePresenceRepository) Update(ctx context.Context, uid string, fn func(*models.Presence) error) (*models.Presence, error) {
+	return upsertDocument(ctx, r.client, r.collection().Doc(uid),
+		mappers.MapPresenceFirestoreToGo, mappers.MapPresenceGoToFirestore, func(presence *models.Presence) error {
+			presence.UID = uid
+			return fn(presence)
+		})
+}
+
+func (r *firestorePresenceRepository) ListConnected(ctx context.Context) ([]models.Presence, error) {
+	return queryDocuments(ctx, r.collection().Where("status", "!=", "offline"), decodePresence)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryPresenceRepository struct {
+	store *memoryStore[models.Presence]
+}
+
+// NewMemoryPresenceRepository creates an in-memory PresenceRepository
+func NewMemoryPresenceRepository() PresenceRepository {
+	return &memoryPresenceRepository{store: newMemoryStore[models.Presence]()}
+}
+
+func (r *memoryPresenceRepository) Get(ctx context.Context, uid string) (*models.Presence, error) {
+	return r.store.get(uid)
+}
+
+func (r *memoryPresenceRepository) GetMany(ctx context.Context, uids []string) ([]models.Presence, error) {
+	presences := []models.Presence{}
+	for _, uid := range uids {
+		if presence, err := r.store.get(uid); err == nil {
+			presences = append(presences, *presence)
+		}
+	}
+	return presences, nil
+}
+
+func (r *memoryPresenceRepository) Update(ctx context.Context, uid string, fn func(*models.Presence) error) (*models.Presence, error) {
+	return r.store.upsert(uid, func(presence *models.Presence) error {
+		presence.UID = uid
+		return fn(presence)
+	})
+}
+
+func (r *memoryPresenceRepository) ListConnected(ctx context.Context) ([]models.Presence, error) {
+	return r.store.list(func(presence models.Presence) bool {
+		return presence.Status != "" && presence.Status != "offline"
+	}), nil
+}
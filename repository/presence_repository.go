@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// PresenceRepository stores the presence of the users
+type PresenceRepository interface {
+	Get(ctx context.Context, uid string) (*models.Presence, error)
+	// GetMany returns the stored presences of the users, skipping the users without one
+	GetMany(ctx context.Context, uids []string) ([]models.Presence, error)
+	// Update atomically applies fn to the stored presence, or to an empty one with the UID
+	// when there is none, and saves the result
+	Update(ctx context.Context, uid string, fn func(*models.Presence) error) (*models.Presence, error)
+	// ListConnected returns the presences of the users who are not offline
+	ListConnected(ctx context.Context) ([]models.Presence, error)
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreProjectRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreProjectRepository creates a ProjectRepository backed by the "projects" collection
+func NewFirestoreProjectRepository(client FirestoreClient) ProjectRepository {
+	return &firestoreProjectRepository{client: client}
+}
+
+func (r *firestoreProjectRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("projects")
+}
+
+// decodeProject maps a project document, the document ID being the project ID
+func decodeProject(doc *firestore.DocumentSnapshot) models.Project {
+	project := mappers.MapProjectFirestoreToGo(doc.Data())
+	project.ID = doc.Ref.ID
+	return project
+}
+
+func (r *firestoreProjectRepository) Create(ctx context.Context, project *models.Project) error {
+	docRef := r.collection().NewDoc()
+	if project.ID != "" {
+		docRef = r.collection().Doc(project.ID)
+	}
+	project.ID = docRef.ID
+
+	_, err := docRef.Set(ctx, mappers.MapProjectGoToFirestore(*project))
+	return err
+}
+
+func (r *firestoreProjectRepository) Get(ctx context.Context, projectID string) (*models.Project, error) {
+	doc, err := r.collection().Doc(projectID).Get(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	project := decodeProject(doc)
+	return &project, nil
+}
+
+func (r *firestoreProjectRepository) Update(ctx context.Context, projectID string, fn func(*models.Project) error) (*models.Project, error) {
+	project, err := updateDocument(ctx, r.client, r.collection().Doc(projectID),
+		mappers.MapProjectFirestoreToGo, mappers.MapProjectGoToFirestore,
+		func(project *models.Project) error {
+			project.ID = projectID
+			return fn(project)
+		})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (r *firestoreProjectRepository) Delete(ctx context.Context, projectID string) error {
+	_, err := r.collection().Doc(projectID).Delete(ctx)
+	return err
+}
+
+func (r *firestoreProjectRepository) ListByCollaborationType(ctx context.Context, types ...string) ([]models.Project, error) {
+	values := make([]interface{}, 0, len(types))
+	for _, t := range types {
+		values = append(values, t)
+	}
+	return queryDocuments(ctx, r.collection().Where("collaboration_type", "in", values), decodeProject)
+}
+
+func (r *firestoreProjectRepository) ListByOwner(ctx context.Context, ownerID string) ([]models.Project, error) {
+	return queryDocuments(ctx, r.collection().Where("owner_id", "==", ownerID), decodeProject)
+}
+
+func (r *firestoreProjectRepository) List(ctx context.Context) ([]models.Project, error) {
+	return queryDocuments(ctx, r.collection().Query, decodeProject)
+}
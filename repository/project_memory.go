@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryProjectRepository struct {
+	store *memoryStore[models.Project]
+}
+
+// NewMemoryProjectRepository creates an in-memory ProjectRepository
+func NewMemoryProjectRepository() ProjectRepository {
+	return &memoryProjectRepository{store: newMemoryStore[models.Project]()}
+}
+
+func (r *memoryProjectRepository) Create(ctx context.Context, project *models.Project) error {
+	if project.ID == "" {
+		project.ID = uuid.New().String()
+	}
+	r.store.put(project.ID, *project)
+	return nil
+}
+
+func (r *memoryProjectRepository) Get(ctx context.Context, projectID string) (*models.Project, error) {
+	return r.store.get(projectID)
+}
+
+func (r *memoryProjectRepository) Update(ctx context.Context, projectID string, fn func(*models.Project) error) (*models.Project, error) {
+	return r.store.update(projectID, func(project *models.Project) error {
+		if err := fn(project); err != nil {
+			return err
+		}
+		project.ID = projectID
+		return nil
+	})
+}
+
+func (r *memoryProjectRepository) Delete(ctx context.Context, projectID string) error {
+	return r.store.delete(projectID)
+}
+
+func (r *memoryProjectRepository) ListByCollaborationType(ctx context.Context, types ...string) ([]models.Project, error) {
+	return r.store.list(func(p models.Project) bool {
+		for _, t := range types {
+			if p.CollaborationType == t {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (r *memoryProjectRepository) ListByOwner(ctx context.Context, ownerID string) ([]models.Project, error) {
+	return r.store.list(func(p models.Project) bool { return p.OwnerID == ownerID }), nil
+}
+
+func (r *memoryProjectRepository) List(ctx context.Context) ([]models.Project, error) {
+	return r.store.list(nil), nil
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// ProjectRepository stores collaboration projects
+type ProjectRepository interface {
+	// Create saves a new project, assigning an ID when the project has none
+	Create(ctx context.Context, project *models.Project) error
+	Get(ctx context.Context, projectID string) (*models.Project, error)
+	// Update atomically applies fn to the stored project and saves the result
+	Update(ctx context.Context, projectID string, fn func(*models.Project) error) (*models.Project, error)
+	Delete(ctx context.Context, projectID string) error
+	ListByCollaborationType(ctx context.Context, types ...string) ([]models.Project, error)
+	ListByOwner(ctx context.Context, ownerID string) ([]models.Project, error)
+	List(ctx context.Context) ([]models.Project, error)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"google.golang.org/api/iterator"
+)
+
+type firestoreReadPositionRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreReadPositionRepository creates a ReadPositionRepository backed by the
+// "read_positions" collection, with one document per user and chat
+func NewFirestoreReadPositionRepository(client FirestoreClient) ReadPositionRepository {
+	return &firestoreReadPositionRepository{client: client}
+}
+
+func (r *firestoreReadPositionRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("read_positions")
+}
+
+func decodeReadPosition(doc *firestore.DocumentSnapshot) models.ReadPosition {
+	return mappers.MapReadPositionFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreReadPositionRepository) Get(ctx context.Context, chatID, userID string) (*models.ReadPosition, error) {
+	return getDocument(ctx, r.collection().Doc(readPositionID(chatID, userID)), mappers.MapReadPositionFirestoreToGo)
+}
+
+func (r *firestoreReadPositionRepository) ListByChat(ctx context.Context, chatID string) ([]models.ReadPosition, error) {
+	return queryDocuments(ctx, r.collection().Where("chat_id", "==", chatID), decodeReadPosition)
+}
+
+func (r *firestoreReadPositionRepository) Advance(ctx context.Context, position models.ReadPosition) (*models.ReadPosition, int64, error) {
+	var previous int64
+	stored, err := upsertDocument(ctx, r.client, r.collection().Doc(readPositionID(position.ChatID, position.UserID)),
+		mappers.MapReadPositionFirestoreToGo, mappers.MapReadPositionGoToFirestore, func(stored *models.ReadPosition) error {
+			previous = advanceReadPosition(stored, position)
+			return nil
+		})
+	if err != nil {
+		return nil, 0, err
+	}
+	return stored, previous, nil
+}
+
+func (r *firestoreReadPositionRepository) DeleteByChat(ctx context.Context, chatID string) error {
+	iter := r.collection().Where("chat_id", "==", chatID).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := doc.Ref.Delete(ctx); err != nil {
+			return err
+		}
+	}
+}
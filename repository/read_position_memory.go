@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryReadPositionRepository struct {
+	store *memoryStore[models.ReadPosition]
+}
+
+// NewMemoryReadPositionRepository creates an in-memory ReadPositionRepository
+func NewMemoryReadPositionRepository() ReadPositionRepository {
+	return &memoryReadPositionRepository{store: newMemoryStore[models.ReadPosition]()}
+}
+
+func (r *memoryReadPositionRepository) Get(ctx context.Context, chatID, userID string) (*models.ReadPosition, error) {
+	return r.store.get(readPositionID(chatID, userID))
+}
+
+func (r *memoryReadPositionRepository) ListByChat(ctx context.Context, chatID string) ([]models.ReadPosition, error) {
+	return r.store.list(func(position models.ReadPosition) bool {
+		return position.ChatID == chatID
+	}), nil
+}
+
+func (r *memoryReadPositionRepository) Advance(ctx context.Context, position models.ReadPosition) (*models.ReadPosition, int64, error) {
+	var previous int64
+	stored, err := r.store.upsert(readPositionID(position.ChatID, position.UserID), func(stored *models.ReadPosition) error {
+		previous = advanceReadPosition(stored, position)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return stored, previous, nil
+}
+
+func (r *memoryReadPositionRepository) DeleteByChat(ctx context.Context, chatID string) error {
+	positions, _ := r.ListByChat(ctx, chatID)
+	for _, position := range positions {
+		r.store.delete(readPositionID(position.ChatID, position.UserID))
+	}
+	return nil
+}
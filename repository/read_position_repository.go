@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// ReadPositionRepository stores how far the users have read the group chats
+type ReadPositionRepository interface {
+	Get(ctx context.Context, chatID, userID string) (*models.ReadPosition, error)
+	ListByChat(ctx context.Context, chatID string) ([]models.ReadPosition, error)
+	// Advance atomically stores the position, unless the stored one is already as far. It
+	// returns the stored position and the Seq of the position before, 0 when the user had none;
+	// the position moved when their Seq differ.
+	Advance(ctx context.Context, position models.ReadPosition) (*models.ReadPosition, int64, error)
+	DeleteByChat(ctx context.Context, chatID string) error
+}
+
+// readPositionID is the key of the position of a user in a chat
+func readPositionID(chatID, userID string) string {
+	return chatID + "_" + userID
+}
+
+// advanceReadPosition moves stored forward to position, and returns the Seq it had before
+func advanceReadPosition(stored *models.ReadPosition, position models.ReadPosition) int64 {
+	if stored.MessageID == "" {
+		*stored = position
+		return 0
+	}
+	previous := stored.Seq
+	if previous < position.Seq {
+		*stored = position
+	}
+	return previous
+}
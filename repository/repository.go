@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNotFound is returned by every repository when the requested record does not exist
+var ErrNotFound = errors.New("not found")
+
+// FirestoreClient is the subset of the Firestore client used by the Firestore repositories.
+// services.FirestoreClient satisfies it.
+type FirestoreClient interface {
+	Collection(path string) *firestore.CollectionRef
+	RunTransaction(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error
+}
+
+// Repositories groups the typed repositories of every aggregate
+type Repositories struct {
+	Users                UserRepository
+	Projects             ProjectRepository
+	GroupChats           GroupChatRepository
+	GroupMessages        GroupMessageRepository
+	ReadPositions        ReadPositionRepository
+	Conversations        ConversationRepository
+	Notifications        NotificationRepository
+	Jobs                 JobRepository
+	Forums               ForumRepository
+	Groups               GroupRepository
+	Sessions             SessionRepository
+	Revocations          RevocationRepository
+	ActionTokens         ActionTokenRepository
+	Credentials          CredentialRepository
+	TwoFactor            TwoFactorRepository
+	OAuthStates          OAuthStateRepository
+	Identities           IdentityRepository
+	AccountMerges        AccountMergeRepository
+	PersonalAccessTokens PersonalAccessTokenRepository
+	LoginThrottles       LoginThrottleRepository
+	AuditEvents          AuditEventRepository
+	Presences            PresenceRepository
+	Addresses            AddressRepository
+	FAQs                 FAQRepository
+	Newsletters          NewsletterRepository
+	Contacts             ContactRepository
+	Testimonials         TestimonialRepository
+	SchoolExperiences    SchoolExperienceRepository
+	Connections          ConnectionRepository
+	LinkedInApplications LinkedInApplicationRepository
+	Uploads              UploadRepository
+	ChatGPTConversations ChatGPTConversationRepository
+}
+
+// NewFirestoreRepositories creates repositories backed by Firestore
+func NewFirestoreRepositories(client FirestoreClient) *Repositories {
+	return &Repositories{
+		Users:                NewFirestoreUserRepository(client),
+		Projects:             NewFirestoreProjectRepository(client),
+		GroupChats:           NewFirestoreGroupChatRepository(client),
+		GroupMessages:        NewFirestoreGroupMessageRepository(client),
+		ReadPositions:        NewFirestoreReadPositionRepository(client),
+		Conversations:        NewFirestoreConversationRepository(client),
+		Notifications:        NewFirestoreNotificationRepository(client),
+		Jobs:                 NewFirestoreJobRepository(client),
+		Forums:               NewFirestoreForumRepository(client),
+		Groups:               NewFirestoreGroupRepository(client),
+		Sessions:             NewFirestoreSessionRepository(client),
+		Revocations:          NewFirestoreRevocationRepository(client),
+		ActionTokens:         NewFirestoreActionTokenRepository(client),
+		Credentials:          NewFirestoreCredentialRepository(client),
+		TwoFactor:            NewFirestoreTwoFactorRepository(client),
+		OAuthStates:          NewFirestoreOAuthStateRepository(client),
+		Identities:           NewFirestoreIdentityRepository(client),
+		AccountMerges:        NewFirestoreAccountMergeRepository(client),
+		PersonalAccessTokens: NewFirestorePersonalAccessTokenRepository(client),
+		LoginThrottles:       NewFirestoreLoginThrottleRepository(client),
+		AuditEvents:          NewFirestoreAuditEventRepository(client),
+		Presences:            NewFirestorePresenceRepository(client),
+		Addresses:            NewFirestoreAddressRepository(client),
+		FAQs:                 NewFirestoreFAQRepository(client),
+		Newsletters:          NewFirestoreNewsletterRepository(client),
+		Contacts:             NewFirestoreContactRepository(client),
+		Testimonials:         NewFirestoreTestimonialRepository(client),
+		SchoolExperiences:    NewFirestoreSchoolExperienceRepository(client),
+		Connections:          NewFirestoreConnectionRepository(client),
+		LinkedInApplications: NewFirestoreLinkedInApplicationRepository(client),
+		Uploads:              NewFirestoreUploadRepository(client),
+		ChatGPTConversations: NewFirestoreChatGPTConversationRepository(client),
+	}
+}
+
+// NewMemoryRepositories creates repositories that keep everything in process memory.
+// They are meant for local development and tests and lose their data on restart.
+func NewMemoryRepositories() *Repositories {
+	return &Repositories{
+		Users:                NewMemoryUserRepository(),
+		Projects:             NewMemoryProjectRepository(),
+		GroupChats:           NewMemoryGroupChatRepository(),
+		GroupMessages:        NewMemoryGroupMessageRepository(),
+		ReadPositions:        NewMemoryReadPositionRepository(),
+		Conversations:        NewMemoryConversationRepository(),
+		Notifications:        NewMemoryNotificationRepository(),
+		Jobs:                 NewMemoryJobRepository(),
+		Forums:               NewMemoryForumRepository(),
+		Groups:               NewMemoryGroupRepository(),
+		Sessions:             NewMemorySessionRepository(),
+		Revocations:          NewMemoryRevocationRepository(),
+		ActionTokens:         NewMemoryActionTokenRepository(),
+		Credentials:          NewMemoryCredentialRepository(),
+		TwoFactor:            NewMemoryTwoFactorRepository(),
+		OAuthStates:          NewMemoryOAuthStateRepository(),
+		Identities:           NewMemoryIdentityRepository(),
+		AccountMerges:        NewMemoryAccountMergeRepository(),
+		PersonalAccessTokens: NewMemoryPersonalAccessTokenRepository(),
+		LoginThrottles:       NewMemoryLoginThrottleRepository(),
+		AuditEvents:          NewMemoryAuditEventRepository(),
+		Presences:            NewMemoryPresenceRepository(),
+		Addresses:            NewMemoryAddressRepository(),
+		FAQs:                 NewMemoryFAQRepository(),
+		Newsletters:          NewMemoryNewsletterRepository(),
+		Contacts:             NewMemoryContactRepository(),
+		Testimonials:         NewMemoryTestimonialRepository(),
+		SchoolExperiences:    NewMemorySchoolExperienceRepository(),
+		Connections:          NewMemoryConnectionRepository(),
+		LinkedInApplications: NewMemoryLinkedInApplicationRepository(),
+		Uploads:              NewMemoryUploadRepository(),
+		ChatGPTConversations: NewMemoryChatGPTConversationRepository(),
+	}
+}
+
+// isNotFound reports whether a Firestore error means the document does not exist
+func isNotFound(err error) bool {
+	return status.Code(err) == codes.NotFound
+}
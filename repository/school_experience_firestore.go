@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreSchoolExperienceRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreSchoolExperienceRepository creates a SchoolExperienceRepository backed by the
+// "user_school_experiences" collection, where the record of a user is found by its "uid" field
+func NewFirestoreSchoolExperienceRepository(client FirestoreClient) SchoolExperienceRepository {
+	return &firestoreSchoolExperienceRepository{client: client}
+}
+
+func (r *firestoreSchoolExperienceRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("user_school_experiences")
+}
+
+func (r *firestoreSchoolExperienceRepository) byUser(uid string) firestore.Query {
+	return r.collection().Where("uid", "==", uid).Limit(1)
+}
+
+func decodeSchoolExperience(doc *firestore.DocumentSnapshot) models.UserSchoolExperience {
+	return *mappers.MapUserSchoolExperienceFromFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreSchoolExperienceRepository) Get(ctx context.Context, uid string) (*models.UserSchoolExperience, error) {
+	experiences, err := queryDocuments(ctx, r.byUser(uid), decodeSchoolExperience)
+	if err != nil {
+		return nil, err
+	}
+	if len(experiences) == 0 {
+		return nil, ErrNotFound
+	}
+	return &experiences[0], nil
+}
+
+func (r *firestoreSchoolExperienceRepository) Create(ctx context.Context, experience *models.UserSchoolExperience) error {
+	_, err := r.collection().NewDoc().Create(ctx, mappers.MapUserSchoolExperienceFromGoToFirestore(experience))
+	return err
+}
+
+func (r *firestoreSchoolExperienceRepository) Upsert(ctx context.Context, uid string, fn func(*models.UserSchoolExperience) error) (*models.UserSchoolExperience, error) {
+	var updated models.UserSchoolExperience
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(r.byUser(uid)).GetAll()
+		if err != nil {
+			return err
+		}
+
+		ref := r.collection().NewDoc()
+		updated = models.UserSchoolExperience{UID: uid}
+		if len(docs) > 0 {
+			ref = docs[0].Ref
+			updated = decodeSchoolExperience(docs[0])
+		}
+
+		if err := fn(&updated); err != nil {
+			return err
+		}
+		return tx.Set(ref, mappers.MapUserSchoolExperienceFromGoToFirestore(&updated))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memorySchoolExperienceRepository struct {
+	store *memoryStore[models.UserSchoolExperience]
+}
+
+// NewMemorySchoolExperienceRepository creates an in-memory SchoolExperienceRepository
+func NewMemorySchoolExperienceRepository() SchoolExperienceRepository {
+	return &memorySchoolExperienceRepository{store: newMemoryStore[models.UserSchoolExperience]()}
+}
+
+func (r *memorySchoolExperienceRepository) Get(ctx context.Context, uid string) (*models.UserSchoolExperience, error) {
+	return r.store.get(uid)
+}
+
+func (r *memorySchoolExperienceRepository) Create(ctx context.Context, experience *models.UserSchoolExperience) error {
+	r.store.put(experience.UID, *experience)
+	return nil
+}
+
+func (r *memorySchoolExperienceRepository) Upsert(ctx context.Context, uid string, fn func(*models.UserSchoolExperience) error) (*models.UserSchoolExperience, error) {
+	return r.store.upsert(uid, func(experience *models.UserSchoolExperience) error {
+		experience.UID = uid
+		return fn(experience)
+	})
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// SchoolExperienceRepository stores the school experience of the users, one record per user
+type SchoolExperienceRepository interface {
+	Get(ctx context.Context, uid string) (*models.UserSchoolExperience, error)
+	Create(ctx context.Context, experience *models.UserSchoolExperience) error
+	// Upsert atomically applies fn to the stored experience of the user, or to an empty one
+	// with the UID when there is none, and saves the result
+	Upsert(ctx context.Context, uid string, fn func(*models.UserSchoolExperience) error) (*models.UserSchoolExperience, error)
+}
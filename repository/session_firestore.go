@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreSessionRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreSessionRepository creates a SessionRepository backed by the "sessions" collection
+func NewFirestoreSessionRepository(client FirestoreClient) SessionRepository {
+	return &firestoreSessionRepository{client: client}
+}
+
+func (r *firestoreSessionRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("sessions")
+}
+
+func decodeSession(doc *firestore.DocumentSnapshot) models.Session {
+	return mappers.MapSessionFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	_, err := r.collection().Doc(session.ID).Create(ctx, mappers.MapSessionGoToFirestore(*session))
+	return err
+}
+
+func (r *firestoreSessionRepository) Get(ctx context.Context, sessionID string) (*models.Session, error) {
+	return getDocument(ctx, r.collection().Doc(sessionID), mappers.MapSessionFirestoreToGo)
+}
+
+func (r *firestoreSessionRepository) Update(ctx context.Context, sessionID string, fn func(*models.Session) error) (*models.Session, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(sessionID),
+		mappers.MapSessionFirestoreToGo, mappers.MapSessionGoToFirestore, fn)
+}
+
+func (r *firestoreSessionRepository) ListByUser(ctx context.Context, uid string) ([]models.Session, error) {
+	return queryDocuments(ctx, r.collection().Where("uid", "==", uid), decodeSession)
+}
+
+type firestoreRevocationRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreRevocationRepository creates a RevocationRepository backed by the "revoked_tokens"
+// collection. A Firestore TTL policy on "expires_at" can purge the expired entries.
+func NewFirestoreRevocationRepository(client FirestoreClient) RevocationRepository {
+	return &firestoreRevocationRepository{client: client}
+}
+
+func (r *firestoreRevocationRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("revoked_tokens")
+}
+
+func (r *firestoreRevocationRepository) Revoke(ctx context.Context, id string, expiresAt time.Time) error {
+	_, err := r.collection().Doc(id).Set(ctx, map[string]interface{}{
+		"id":         id,
+		"revoked_at": time.Now(),
+		"expires_at": expiresAt,
+	})
+	return err
+}
+
+func (r *firestoreRevocationRepository) IsRevoked(ctx context.Context, id string) (bool, error) {
+	doc, err := r.collection().Doc(id).Get(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	expiresAt, ok := doc.Data()["expires_at"].(time.Time)
+	return !ok || time.Now().Before(expiresAt), nil
+}
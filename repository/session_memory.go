@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memorySessionRepository struct {
+	store *memoryStore[models.Session]
+}
+
+// NewMemorySessionRepository creates an in-memory SessionRepository
+func NewMemorySessionRepository() SessionRepository {
+	return &memorySessionRepository{store: newMemoryStore[models.Session]()}
+}
+
+func (r *memorySessionRepository) Create(ctx context.Context, session *models.Session) error {
+	r.store.put(session.ID, *session)
+	return nil
+}
+
+func (r *memorySessionRepository) Get(ctx context.Context, sessionID string) (*models.Session, error) {
+	return r.store.get(sessionID)
+}
+
+func (r *memorySessionRepository) Update(ctx context.Context, sessionID string, fn func(*models.Session) error) (*models.Session, error) {
+	return r.store.update(sessionID, fn)
+}
+
+func (r *memorySessionRepository) ListByUser(ctx context.Context, uid string) ([]models.Session, error) {
+	return r.store.list(func(session models.Session) bool { return session.UID == uid }), nil
+}
+
+type memoryRevocationRepository struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+// NewMemoryRevocationRepository creates an in-memory RevocationRepository
+func NewMemoryRevocationRepository() RevocationRepository {
+	return &memoryRevocationRepository{revoked: make(map[string]time.Time)}
+}
+
+func (r *memoryRevocationRepository) Revoke(ctx context.Context, id string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Drop the entries whose tokens have all expired
+	now := time.Now()
+	for revokedID, until := range r.revoked {
+		if now.After(until) {
+			delete(r.revoked, revokedID)
+		}
+	}
+
+	r.revoked[id] = expiresAt
+	return nil
+}
+
+func (r *memoryRevocationRepository) IsRevoked(ctx context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.revoked[id]
+	return ok && time.Now().Before(until), nil
+}
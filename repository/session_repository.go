@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// SessionRepository stores the signed-in devices of the users
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	Get(ctx context.Context, sessionID string) (*models.Session, error)
+	// Update atomically applies fn to the stored session and saves the result
+	Update(ctx context.Context, sessionID string, fn func(*models.Session) error) (*models.Session, error)
+	// ListByUser lists every session of the user, revoked and expired ones included
+	ListByUser(ctx context.Context, uid string) ([]models.Session, error)
+}
+
+// RevocationRepository is the list of revoked sessions whose access tokens may still be unexpired
+type RevocationRepository interface {
+	// Revoke adds the ID to the list until expiresAt, when every token carrying it has expired
+	Revoke(ctx context.Context, id string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, id string) (bool, error)
+}
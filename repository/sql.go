@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// OpenSQL opens a SQL database for the SQL repositories.
+// The driver is "sqlite" (default) for local development or "postgres".
+func OpenSQL(driver, dsn string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch driver {
+	case "", "sqlite":
+		dialector = sqlite.Open(dsn)
+	case "postgres":
+		dialector = postgres.Open(dsn)
+	default:
+		return nil, fmt.Errorf("unsupported SQL driver %q", driver)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Warn)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %v", driver, err)
+	}
+
+	if db.Dialector.Name() == "sqlite" {
+		// SQLite only enforces the foreign keys created by the migrations when asked to
+		if err := db.Exec("PRAGMA foreign_keys = ON").Error; err != nil {
+			return nil, fmt.Errorf("failed to enable foreign keys: %v", err)
+		}
+	}
+
+	return db, nil
+}
+
+// sqlMigration is one versioned step of the SQL schema
+type sqlMigration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+}
+
+// schemaMigration records an applied sqlMigration
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// sqlMigrations lists the schema versions in order. Applied migrations must
+// never be edited; add a new one instead.
+var sqlMigrations = []sqlMigration{
+	{
+		Version: 1,
+		Name:    "create groups and forums",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&models.Group{},
+				&models.Member{},
+				&models.Topic{},
+				&models.Event{},
+				&models.Resource{},
+				&models.Rule{},
+				&models.Forum{},
+				&models.ForumCategory{},
+				&models.Post{},
+				&models.Tag{},
+				&models.File{},
+				&models.Comment{},
+				&models.Reaction{},
+			)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "link admins, attendees and moderators through join tables",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&models.User{}, &models.Group{}, &models.Event{}, &models.Forum{}); err != nil {
+				return err
+			}
+			moves := []struct {
+				table     string
+				column    string
+				joinTable string
+				ownerKey  string
+			}{
+				{"groups", "admins", "group_admins", "group_id"},
+				{"events", "attendees", "event_attendees", "event_id"},
+				{"forums", "moderators", "forum_moderators", "forum_id"},
+			}
+			for _, move := range moves {
+				if err := moveUsersToJoinTable(tx, move.table, move.column, move.joinTable, move.ownerKey); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// moveUsersToJoinTable moves the users kept as a JSON column of the table to its join
+// table, and drops the column. Databases created after the join tables have no column.
+func moveUsersToJoinTable(tx *gorm.DB, table, column, joinTable, ownerKey string) error {
+	if !tx.Migrator().HasColumn(table, column) {
+		return nil
+	}
+
+	var rows []struct {
+		ID    string
+		Users string
+	}
+	if err := tx.Table(table).Select("id, " + column + " AS users").Where(column + " IS NOT NULL").Scan(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		var users []*models.User
+		if err := json.Unmarshal([]byte(row.Users), &users); err != nil {
+			return fmt.Errorf("failed to decode %s of %s %s: %v", column, table, row.ID, err)
+		}
+		if err := saveUsers(tx, users); err != nil {
+			return err
+		}
+		for _, user := range users {
+			link := map[string]interface{}{ownerKey: row.ID, "user_uid": user.UID}
+			if err := tx.Table(joinTable).Clauses(clause.OnConflict{DoNothing: true}).Create(link).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column)).Error
+}
+
+// MigrateSQL applies the migrations that have not run yet on the database
+func MigrateSQL(db *gorm.DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %v", err)
+	}
+
+	var applied []schemaMigration
+	if err := db.Find(&applied).Error; err != nil {
+		return fmt.Errorf("failed to read applied migrations: %v", err)
+	}
+
+	done := make(map[int]bool, len(applied))
+	for _, migration := range applied {
+		done[migration.Version] = true
+	}
+
+	for _, migration := range sqlMigrations {
+		if done[migration.Version] {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d (%s): %v", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// NewSQLRepositories replaces the group and forum repositories of repos with
+// ones backed by db. The other aggregates keep their current backend.
+func NewSQLRepositories(repos *Repositories, db *gorm.DB) *Repositories {
+	withSQL := *repos
+	withSQL.Forums = NewSQLForumRepository(db)
+	withSQL.Groups = NewSQLGroupRepository(db)
+	return &withSQL
+}
+
+// lockForUpdate locks the rows the query reads until the transaction ends, so that
+// concurrent saves of the same aggregate compare against what the other one wrote.
+// SQLite has no row locks: its transactions already run one at a time.
+func lockForUpdate(tx *gorm.DB) *gorm.DB {
+	if tx.Dialector.Name() == "sqlite" {
+		return tx
+	}
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"})
+}
+
+// upsert writes the rows, or overwrites every column of the stored rows with the same
+// primary key. Unlike clause.OnConflict{UpdateAll: true}, it keeps the UpdatedAt of the rows.
+func upsert(tx *gorm.DB, rows interface{}) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(rows); err != nil {
+		return err
+	}
+
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName != "" && !field.PrimaryKey {
+			columns = append(columns, field.DBName)
+		}
+	}
+	onConflict := clause.OnConflict{DoUpdates: clause.AssignmentColumns(columns)}
+	if len(columns) == 0 {
+		onConflict = clause.OnConflict{DoNothing: true}
+	}
+	return tx.Omit(clause.Associations).Clauses(onConflict).Create(rows).Error
+}
+
+// saveUsers stores the users referenced by the join tables, refreshing the stored
+// profile of the users that already exist
+func saveUsers(tx *gorm.DB, users []*models.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	return upsert(tx, users)
+}
+
+// replaceAssociation makes the many2many relation of the owner, which only needs its
+// primary key, list the values. Only the join rows change: the owner row is left alone.
+func replaceAssociation(tx *gorm.DB, owner interface{}, name string, values interface{}) error {
+	return tx.Session(&gorm.Session{SkipHooks: true}).Model(owner).Association(name).Replace(values)
+}
+
+// syncRows makes the rows of a has-many relation match wanted: it writes the rows that
+// are new or changed, and removes the stored ones wanted no longer has. The associations
+// of the rows are not written; remove deletes a row together with what belongs to it.
+func syncRows[T any](tx *gorm.DB, stored, wanted []T, remove func(tx *gorm.DB, row *T) error) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(new(T)); err != nil {
+		return err
+	}
+	rowSchema := stmt.Schema
+
+	byKey := make(map[string]*T, len(stored))
+	for i := range stored {
+		byKey[rowKey(tx, rowSchema, &stored[i])] = &stored[i]
+	}
+
+	var changed []*T
+	kept := make(map[string]bool, len(wanted))
+	for i := range wanted {
+		key := rowKey(tx, rowSchema, &wanted[i])
+		kept[key] = true
+		if old, ok := byKey[key]; !ok || !sameRow(tx, rowSchema, old, &wanted[i]) {
+			changed = append(changed, &wanted[i])
+		}
+	}
+
+	for i := range stored {
+		if !kept[rowKey(tx, rowSchema, &stored[i])] {
+			if err := remove(tx, &stored[i]); err != nil {
+				return err
+			}
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return upsert(tx, changed)
+}
+
+// rowKey joins the primary key values of the row
+func rowKey(tx *gorm.DB, rowSchema *schema.Schema, row interface{}) string {
+	value := reflect.ValueOf(row).Elem()
+	keys := make([]string, len(rowSchema.PrimaryFields))
+	for i, field := range rowSchema.PrimaryFields {
+		key, _ := field.ValueOf(tx.Statement.Context, value)
+		keys[i] = fmt.Sprint(key)
+	}
+	return strings.Join(keys, "\x00")
+}
+
+// sameRow reports whether the columns of both rows hold the same values
+func sameRow(tx *gorm.DB, rowSchema *schema.Schema, a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	for _, field := range rowSchema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		x, _ := field.ValueOf(tx.Statement.Context, va)
+		y, _ := field.ValueOf(tx.Statement.Context, vb)
+		if timeX, ok := x.(time.Time); ok {
+			if timeY, ok := y.(time.Time); !ok || !timeX.Equal(timeY) {
+				return false
+			}
+			continue
+		}
+		if !reflect.DeepEqual(x, y) {
+			return false
+		}
+	}
+	return true
+}
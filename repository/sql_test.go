@@ -0,0 +1,421 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// openTestSQL opens a migrated SQLite database of its own for the test
+func openTestSQL(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := OpenSQL("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	require.NoError(t, MigrateSQL(db))
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// sqlTestBackends are the backends the SQL repositories must behave like
+var sqlTestBackends = []struct {
+	name   string
+	groups func(t *testing.T) GroupRepository
+	forums func(t *testing.T) ForumRepository
+}{
+	{
+		name:   "memory",
+		groups: func(t *testing.T) GroupRepository { return NewMemoryGroupRepository() },
+		forums: func(t *testing.T) ForumRepository { return NewMemoryForumRepository() },
+	},
+	{
+		name:   "sql",
+		groups: func(t *testing.T) GroupRepository { return NewSQLGroupRepository(openTestSQL(t)) },
+		forums: func(t *testing.T) ForumRepository { return NewSQLForumRepository(openTestSQL(t)) },
+	},
+}
+
+func TestOpenSQLUnsupportedDriver(t *testing.T) {
+	_, err := OpenSQL("mysql", "")
+	assert.Error(t, err)
+}
+
+func TestMigrateSQL(t *testing.T) {
+	db := openTestSQL(t)
+
+	// Applied migrations are not applied again
+	require.NoError(t, MigrateSQL(db))
+	var applied []schemaMigration
+	require.NoError(t, db.Order("version").Find(&applied).Error)
+	require.Len(t, applied, len(sqlMigrations))
+	for i, migration := range applied {
+		assert.Equal(t, sqlMigrations[i].Version, migration.Version)
+		assert.Equal(t, sqlMigrations[i].Name, migration.Name)
+	}
+}
+
+func testGroup(id, name, category string, createdAt time.Time) *models.Group {
+	return &models.Group{
+		ID:        id,
+		Name:      name,
+		Category:  models.GroupCategory{Name: category, Count: 1},
+		Privacy:   "public",
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Members:   []models.Member{{UserID: "user-1", GroupID: id, Role: "admin", JoinedAt: createdAt}},
+		Topics:    []models.Topic{{ID: id + "-topic", Name: "Go"}},
+		Events:    []models.Event{{ID: id + "-event", GroupID: id, Title: "Meetup", StartTime: createdAt}},
+		Resources: []models.Resource{{ID: id + "-resource", GroupID: id, Title: "Docs", AddedAt: createdAt}},
+		Rules:     []models.Rule{{ID: id + "-rule-2", GroupID: id, Title: "Be kind", Order: 2}, {ID: id + "-rule-1", GroupID: id, Title: "No spam", Order: 1}},
+		Admins:    []*models.User{{UID: "user-1", Username: "ada"}},
+	}
+}
+
+func groupIDs(groups []models.Group) []string {
+	ids := make([]string, len(groups))
+	for i, group := range groups {
+		ids[i] = group.ID
+	}
+	return ids
+}
+
+func TestGroupRepository(t *testing.T) {
+	for _, backend := range sqlTestBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			groups := backend.groups(t)
+			now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			require.NoError(t, groups.Save(ctx, testGroup("g1", "Gophers", "tech", now)))
+			require.NoError(t, groups.Save(ctx, testGroup("g2", "Runners", "sport", now.Add(time.Hour))))
+			require.NoError(t, groups.Save(ctx, testGroup("g3", "Rustaceans", "tech", now.Add(2*time.Hour))))
+
+			group, err := groups.Get(ctx, "g1")
+			require.NoError(t, err)
+			assert.Equal(t, "Gophers", group.Name)
+			assert.Equal(t, "tech", group.Category.Name)
+			require.Len(t, group.Members, 1)
+			assert.Equal(t, "admin", group.Members[0].Role)
+			assert.Len(t, group.Topics, 1)
+			assert.Len(t, group.Events, 1)
+			assert.Len(t, group.Resources, 1)
+			assert.Len(t, group.Rules, 2)
+			require.Len(t, group.Admins, 1)
+			assert.Equal(t, "ada", group.Admins[0].Username)
+
+			// Saving again replaces the children
+			updated := testGroup("g1", "Gophers", "tech", now)
+			updated.Members = append(updated.Members, models.Member{UserID: "user-2", GroupID: "g1", Role: "member", JoinedAt: now.Add(time.Minute)})
+			updated.Events = nil
+			require.NoError(t, groups.Save(ctx, updated))
+			group, err = groups.Get(ctx, "g1")
+			require.NoError(t, err)
+			assert.Len(t, group.Members, 2)
+			assert.Empty(t, group.Events)
+
+			filters := []struct {
+				name    string
+				filters map[string]interface{}
+				want    []string
+			}{
+				{name: "no filter", want: []string{"g1", "g2", "g3"}},
+				{name: "top-level field", filters: map[string]interface{}{"name": "Runners"}, want: []string{"g2"}},
+				{name: "embedded field", filters: map[string]interface{}{"category.name": "tech"}, want: []string{"g1", "g3"}},
+				{name: "several fields", filters: map[string]interface{}{"category.name": "tech", "name": "Rustaceans"}, want: []string{"g3"}},
+				{name: "nothing matching", filters: map[string]interface{}{"privacy": "private"}, want: []string{}},
+			}
+			for _, tt := range filters {
+				listed, err := groups.List(ctx, tt.filters)
+				require.NoError(t, err, tt.name)
+				assert.ElementsMatch(t, tt.want, groupIDs(listed), tt.name)
+			}
+
+			require.NoError(t, groups.Delete(ctx, "g1"))
+			_, err = groups.Get(ctx, "g1")
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.ErrorIs(t, groups.Delete(ctx, "g1"), ErrNotFound)
+			_, err = groups.Get(ctx, "missing")
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestSQLGroupRepositoryOrdersChildren(t *testing.T) {
+	ctx := context.Background()
+	groups := NewSQLGroupRepository(openTestSQL(t))
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, groups.Save(ctx, testGroup("g2", "Runners", "sport", now.Add(time.Hour))))
+	require.NoError(t, groups.Save(ctx, testGroup("g1", "Gophers", "tech", now)))
+
+	group, err := groups.Get(ctx, "g1")
+	require.NoError(t, err)
+	assert.Equal(t, "No spam", group.Rules[0].Title)
+	assert.Equal(t, "Be kind", group.Rules[1].Title)
+
+	listed, err := groups.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g1", "g2"}, groupIDs(listed))
+
+	_, err = groups.List(ctx, map[string]interface{}{"members": "user-1"})
+	assert.Error(t, err)
+}
+
+func testForum(id, groupID string, createdAt time.Time) *models.Forum {
+	postID, commentID := id+"-post", id+"-comment"
+	return &models.Forum{
+		ID:          id,
+		GroupID:     groupID,
+		Name:        "General",
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+		Moderators:  []*models.User{{UID: "user-1"}},
+		Categories:  []models.ForumCategory{{ID: id + "-category", ForumID: id, Name: "Help", Order: 1}},
+		AllowFiles:  false,
+		MaxFileSize: 1024,
+		Posts: []models.Post{{
+			ID:        postID,
+			ForumID:   id,
+			UserID:    "user-1",
+			Title:     "Hello",
+			Status:    "active",
+			CreatedAt: createdAt,
+			Tags:      []models.Tag{{ID: id + "-tag", Name: id + "-go"}},
+			Files:     []models.File{{ID: id + "-file", PostID: postID, FileName: "notes.txt"}},
+			Reactions: []models.Reaction{{ID: id + "-post-reaction", UserID: "user-2", PostID: &postID, Type: "like", CreatedAt: createdAt}},
+			Comments: []models.Comment{{
+				ID:        commentID,
+				PostID:    postID,
+				UserID:    "user-2",
+				Content:   "Welcome",
+				CreatedAt: createdAt,
+				Reactions: []models.Reaction{{ID: id + "-comment-reaction", UserID: "user-1", CommentID: &commentID, Type: "heart", CreatedAt: createdAt}},
+			}},
+		}},
+	}
+}
+
+func TestForumRepository(t *testing.T) {
+	for _, backend := range sqlTestBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			forums := backend.forums(t)
+			now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			require.NoError(t, forums.Save(ctx, testForum("f1", "g1", now)))
+			require.NoError(t, forums.Save(ctx, testForum("f2", "g1", now.Add(time.Hour))))
+			require.NoError(t, forums.Save(ctx, testForum("f3", "g2", now)))
+
+			forum, err := forums.Get(ctx, "f1")
+			require.NoError(t, err)
+			assert.False(t, forum.AllowFiles)
+			assert.Equal(t, int64(1024), forum.MaxFileSize)
+			require.Len(t, forum.Moderators, 1)
+			assert.Len(t, forum.Categories, 1)
+			require.Len(t, forum.Posts, 1)
+			post := forum.Posts[0]
+			assert.Len(t, post.Tags, 1)
+			assert.Len(t, post.Files, 1)
+			assert.Len(t, post.Reactions, 1)
+			require.Len(t, post.Comments, 1)
+			assert.Len(t, post.Comments[0].Reactions, 1)
+
+			// Saving again replaces the posts
+			updated := testForum("f1", "g1", now)
+			updated.Posts[0].Comments = nil
+			updated.AllowFiles = true
+			require.NoError(t, forums.Save(ctx, updated))
+			forum, err = forums.Get(ctx, "f1")
+			require.NoError(t, err)
+			assert.True(t, forum.AllowFiles)
+			require.Len(t, forum.Posts, 1)
+			assert.Empty(t, forum.Posts[0].Comments)
+
+			listed, err := forums.ListByGroup(ctx, "g1")
+			require.NoError(t, err)
+			assert.Len(t, listed, 2)
+
+			require.NoError(t, forums.Delete(ctx, "f1"))
+			_, err = forums.Get(ctx, "f1")
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.ErrorIs(t, forums.Delete(ctx, "f1"), ErrNotFound)
+			listed, err = forums.ListByGroup(ctx, "g1")
+			require.NoError(t, err)
+			require.Len(t, listed, 1)
+			assert.Equal(t, "f2", listed[0].ID)
+		})
+	}
+}
+
+func TestSQLForumRepositoryDeletesTheWholeTree(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQL(t)
+	forums := NewSQLForumRepository(db)
+	require.NoError(t, forums.Save(ctx, testForum("f1", "g1", time.Now())))
+	require.NoError(t, forums.Delete(ctx, "f1"))
+
+	for _, table := range []interface{}{&models.ForumCategory{}, &models.Post{}, &models.Comment{}, &models.Reaction{}, &models.File{}} {
+		var count int64
+		require.NoError(t, db.Model(table).Count(&count).Error)
+		assert.Zero(t, count, "%T", table)
+	}
+	var tags int64
+	require.NoError(t, db.Table("post_tags").Count(&tags).Error)
+	assert.Zero(t, tags)
+}
+
+func TestNewSQLRepositories(t *testing.T) {
+	repos := NewMemoryRepositories()
+	withSQL := NewSQLRepositories(repos, openTestSQL(t))
+	assert.IsType(t, &sqlGroupRepository{}, withSQL.Groups)
+	assert.IsType(t, &sqlForumRepository{}, withSQL.Forums)
+	assert.Same(t, repos.Users, withSQL.Users)
+	// The repositories passed in are left alone
+	assert.NotSame(t, repos, withSQL)
+	assert.IsType(t, &memoryGroupRepository{}, repos.Groups)
+}
+
+// countWrites counts the rows inserted or updated per table
+func countWrites(t *testing.T, db *gorm.DB) map[string]int64 {
+	t.Helper()
+	writes := map[string]int64{}
+	count := func(tx *gorm.DB) {
+		if tx.Error == nil {
+			writes[tx.Statement.Table] += tx.Statement.RowsAffected
+		}
+	}
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:count_creates", count))
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:count_updates", count))
+	return writes
+}
+
+func TestSQLForumRepositoryWritesOnlyChangedRows(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQL(t)
+	forums := NewSQLForumRepository(db)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	forum := testForum("f1", "g1", now)
+	second := testForum("f1-second", "g1", now.Add(time.Minute)).Posts[0]
+	second.ForumID = "f1"
+	forum.Posts = append(forum.Posts, second)
+	require.NoError(t, forums.Save(ctx, forum))
+
+	stored, err := forums.Get(ctx, "f1")
+	require.NoError(t, err)
+	stored.Posts[1].Title = "Edited"
+	writes := countWrites(t, db)
+	require.NoError(t, forums.Save(ctx, stored))
+
+	assert.Equal(t, int64(1), writes["posts"])
+	assert.Zero(t, writes["comments"])
+	assert.Zero(t, writes["reactions"])
+	assert.Zero(t, writes["files"])
+	assert.Zero(t, writes["forum_categories"])
+
+	stored, err = forums.Get(ctx, "f1")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", stored.Posts[0].Title)
+	assert.Equal(t, "Edited", stored.Posts[1].Title)
+	assert.True(t, stored.UpdatedAt.Equal(now))
+}
+
+func TestSQLForumRepositorySharesTagsByName(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQL(t)
+	forums := NewSQLForumRepository(db)
+	now := time.Now()
+
+	// Both posts name their tag "go", under different IDs
+	for _, id := range []string{"f1", "f2"} {
+		forum := testForum(id, "g1", now)
+		forum.Posts[0].Tags = []models.Tag{{ID: id + "-tag", Name: "go"}}
+		require.NoError(t, forums.Save(ctx, forum))
+	}
+
+	var tags []models.Tag
+	require.NoError(t, db.Find(&tags).Error)
+	require.Len(t, tags, 1)
+	for _, id := range []string{"f1", "f2"} {
+		forum, err := forums.Get(ctx, id)
+		require.NoError(t, err)
+		require.Len(t, forum.Posts[0].Tags, 1)
+		assert.Equal(t, tags[0].ID, forum.Posts[0].Tags[0].ID)
+	}
+
+	// Removing the tag from a post keeps it for the other
+	forum, err := forums.Get(ctx, "f1")
+	require.NoError(t, err)
+	forum.Posts[0].Tags = nil
+	require.NoError(t, forums.Save(ctx, forum))
+	forum, err = forums.Get(ctx, "f2")
+	require.NoError(t, err)
+	assert.Len(t, forum.Posts[0].Tags, 1)
+}
+
+func TestSQLGroupRepositoryLinksUsers(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQL(t)
+	groups := NewSQLGroupRepository(db)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, groups.Save(ctx, testGroup("g1", "Gophers", "tech", now)))
+	group := testGroup("g2", "Runners", "sport", now)
+	group.Admins = []*models.User{{UID: "user-1", Username: "ada lovelace"}}
+	group.Events[0].Attendees = []*models.User{group.Admins[0], {UID: "user-2"}}
+	require.NoError(t, groups.Save(ctx, group))
+
+	// Both groups link the one stored user, whose profile is the latest saved
+	var users []models.User
+	require.NoError(t, db.Order("uid").Find(&users).Error)
+	require.Len(t, users, 2)
+	assert.Equal(t, "ada lovelace", users[0].Username)
+	for _, id := range []string{"g1", "g2"} {
+		stored, err := groups.Get(ctx, id)
+		require.NoError(t, err)
+		require.Len(t, stored.Admins, 1)
+		assert.Equal(t, "ada lovelace", stored.Admins[0].Username)
+		assert.True(t, stored.UpdatedAt.Equal(now))
+	}
+	stored, err := groups.Get(ctx, "g2")
+	require.NoError(t, err)
+	assert.Len(t, stored.Events[0].Attendees, 2)
+
+	// Removing an admin only removes the link
+	stored.Admins = nil
+	require.NoError(t, groups.Save(ctx, stored))
+	stored, err = groups.Get(ctx, "g2")
+	require.NoError(t, err)
+	assert.Empty(t, stored.Admins)
+	var count int64
+	require.NoError(t, db.Model(&models.User{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestMigrateSQLMovesUsersToJoinTables(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQL(t)
+
+	// A database migrated when the users were kept as JSON columns
+	require.NoError(t, db.Exec("ALTER TABLE groups ADD COLUMN admins text").Error)
+	require.NoError(t, db.Exec("ALTER TABLE forums ADD COLUMN moderators text").Error)
+	require.NoError(t, db.Exec(`INSERT INTO groups (id, name, admins) VALUES ('g1', 'Gophers', '[{"uid":"user-1","username":"ada"}]')`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO forums (id, group_id, moderators) VALUES ('f1', 'g1', NULL)`).Error)
+	require.NoError(t, db.Where("version = ?", 2).Delete(&schemaMigration{}).Error)
+
+	require.NoError(t, MigrateSQL(db))
+	assert.False(t, db.Migrator().HasColumn("groups", "admins"))
+	assert.False(t, db.Migrator().HasColumn("forums", "moderators"))
+
+	group, err := NewSQLGroupRepository(db).Get(ctx, "g1")
+	require.NoError(t, err)
+	require.Len(t, group.Admins, 1)
+	assert.Equal(t, "ada", group.Admins[0].Username)
+	forum, err := NewSQLForumRepository(db).Get(ctx, "f1")
+	require.NoError(t, err)
+	assert.Empty(t, forum.Moderators)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreTestimonialRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreTestimonialRepository creates a TestimonialRepository backed by the "testimonials",
+// "alumni_testimonials" and "student_spotlights" collections
+func NewFirestoreTestimonialRepository(client FirestoreClient) TestimonialRepository {
+	return &firestoreTestimonialRepository{client: client}
+}
+
+func (r *firestoreTestimonialRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection(models.TestimonialsCollection)
+}
+
+func decodeTestimonial(doc *firestore.DocumentSnapshot) models.Testimonial {
+	return mappers.MapTestimonialFirestoreToGo(doc.Data())
+}
+
+func (r *firestoreTestimonialRepository) Create(ctx context.Context, testimonial *models.Testimonial) error {
+	_, err := r.collection().Doc(testimonial.ID).Set(ctx, mappers.MapTestimonialGoToFirestore(*testimonial))
+	return err
+}
+
+func (r *firestoreTestimonialRepository) Get(ctx context.Context, testimonialID string) (*models.Testimonial, error) {
+	return getDocument(ctx, r.collection().Doc(testimonialID), mappers.MapTestimonialFirestoreToGo)
+}
+
+func (r *firestoreTestimonialRepository) Update(ctx context.Context, testimonialID string, fn func(*models.Testimonial) error) (*models.Testimonial, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(testimonialID),
+		mappers.MapTestimonialFirestoreToGo, mappers.MapTestimonialGoToFirestore, fn)
+}
+
+func (r *firestoreTestimonialRepository) Delete(ctx context.Context, testimonialID string) error {
+	_, err := r.collection().Doc(testimonialID).Delete(ctx)
+	return err
+}
+
+func (r *firestoreTestimonialRepository) ListPublished(ctx context.Context) ([]models.Testimonial, error) {
+	return queryDocuments(ctx, r.collection().Where("is_published", "==", true), decodeTestimonial)
+}
+
+func (r *firestoreTestimonialRepository) CreateAlumni(ctx context.Context, testimonial *models.AlumniTestimonial) error {
+	_, err := r.client.Collection("alumni_testimonials").Doc(testimonial.ID).
+		Set(ctx, mappers.MapAlumniTestimonialGoToFirestore(*testimonial))
+	return err
+}
+
+func (r *firestoreTestimonialRepository) GetAlumni(ctx context.Context, testimonialID string) (*models.AlumniTestimonial, error) {
+	return getDocument(ctx, r.client.Collection("alumni_testimonials").Doc(testimonialID),
+		mappers.MapAlumniTestimonialFirestoreToGo)
+}
+
+func (r *firestoreTestimonialRepository) CreateSpotlight(ctx context.Context, spotlight *models.StudentSpotlight) error {
+	_, err := r.client.Collection("student_spotlights").Doc(spotlight.ID).
+		Set(ctx, mappers.MapStudentSpotlightGoToFirestore(*spotlight))
+	return err
+}
+
+func (r *firestoreTestimonialRepository) GetSpotlight(ctx context.Context, testimonialID string) (*models.StudentSpotlight, error) {
+	return getDocument(ctx, r.client.Collection("student_spotlights").Doc(testimonialID),
+		mappers.MapStudentSpotlightFirestoreToGo)
+}
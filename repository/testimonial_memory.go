@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryTestimonialRepository struct {
+	testimonials *memoryStore[models.Testimonial]
+	alumni       *memoryStore[models.AlumniTestimonial]
+	spotlights   *memoryStore[models.StudentSpotlight]
+}
+
+// NewMemoryTestimonialRepository creates an in-memory TestimonialRepository
+func NewMemoryTestimonialRepository() TestimonialRepository {
+	return &memoryTestimonialRepository{
+		testimonials: newMemoryStore[models.Testimonial](),
+		alumni:       newMemoryStore[models.AlumniTestimonial](),
+		spotlights:   newMemoryStore[models.StudentSpotlight](),
+	}
+}
+
+func (r *memoryTestimonialRepository) Create(ctx context.Context, testimonial *models.Testimonial) error {
+	r.testimonials.put(testimonial.ID, *testimonial)
+	return nil
+}
+
+func (r *memoryTestimonialRepository) Get(ctx context.Context, testimonialID string) (*models.Testimonial, error) {
+	return r.testimonials.get(testimonialID)
+}
+
+func (r *memoryTestimonialRepository) Update(ctx context.Context, testimonialID string, fn func(*models.Testimonial) error) (*models.Testimonial, error) {
+	return r.testimonials.update(testimonialID, fn)
+}
+
+func (r *memoryTestimonialRepository) Delete(ctx context.Context, testimonialID string) error {
+	return r.testimonials.delete(testimonialID)
+}
+
+func (r *memoryTestimonialRepository) ListPublished(ctx context.Context) ([]models.Testimonial, error) {
+	return r.testimonials.list(func(testimonial models.Testimonial) bool { return testimonial.IsPublished }), nil
+}
+
+func (r *memoryTestimonialRepository) CreateAlumni(ctx context.Context, testimonial *models.AlumniTestimonial) error {
+	r.alumni.put(testimonial.ID, *testimonial)
+	return nil
+}
+
+func (r *memoryTestimonialRepository) GetAlumni(ctx context.Context, testimonialID string) (*models.AlumniTestimonial, error) {
+	return r.alumni.get(testimonialID)
+}
+
+func (r *memoryTestimonialRepository) CreateSpotlight(ctx context.Context, spotlight *models.StudentSpotlight) error {
+	r.spotlights.put(spotlight.ID, *spotlight)
+	return nil
+}
+
+func (r *memoryTestimonialRepository) GetSpotlight(ctx context.Context, testimonialID string) (*models.StudentSpotlight, error) {
+	return r.spotlights.get(testimonialID)
+}
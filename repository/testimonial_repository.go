@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// TestimonialRepository stores testimonials, with the alumni testimonials and student
+// spotlights that extend some of them
+type TestimonialRepository interface {
+	Create(ctx context.Context, testimonial *models.Testimonial) error
+	Get(ctx context.Context, testimonialID string) (*models.Testimonial, error)
+	// Update atomically applies fn to the stored testimonial and saves the result
+	Update(ctx context.Context, testimonialID string, fn func(*models.Testimonial) error) (*models.Testimonial, error)
+	Delete(ctx context.Context, testimonialID string) error
+	ListPublished(ctx context.Context) ([]models.Testimonial, error)
+	CreateAlumni(ctx context.Context, testimonial *models.AlumniTestimonial) error
+	GetAlumni(ctx context.Context, testimonialID string) (*models.AlumniTestimonial, error)
+	CreateSpotlight(ctx context.Context, spotlight *models.StudentSpotlight) error
+	GetSpotlight(ctx context.Context, testimonialID string) (*models.StudentSpotlight, error)
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreTwoFactorRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreTwoFactorRepository creates a TwoFactorRepository backed by the "two_factor" collection.
+// Security rules must keep clients from reading it.
+func NewFirestoreTwoFactorRepository(client FirestoreClient) TwoFactorRepository {
+	return &firestoreTwoFactorRepository{client: client}
+}
+
+func (r *firestoreTwoFactorRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("two_factor")
+}
+
+func (r *firestoreTwoFactorRepository) Get(ctx context.Context, uid string) (*models.TwoFactor, error) {
+	return getDocument(ctx, r.collection().Doc(uid), mappers.MapTwoFactorFirestoreToGo)
+}
+
+func (r *firestoreTwoFactorRepository) Put(ctx context.Context, twoFactor *models.TwoFactor) error {
+	_, err := r.collection().Doc(twoFactor.UID).Set(ctx, mappers.MapTwoFactorGoToFirestore(*twoFactor))
+	return err
+}
+
+func (r *firestoreTwoFactorRepository) Update(ctx context.Context, uid string, fn func(*models.TwoFactor) error) (*models.TwoFactor, error) {
+	return updateDocument(ctx, r.client, r.collection().Doc(uid),
+		mappers.MapTwoFactorFirestoreToGo, mappers.MapTwoFactorGoToFirestore, fn)
+}
+
+func (r *firestoreTwoFactorRepository) Delete(ctx context.Context, uid string) error {
+	if _, err := r.Get(ctx, uid); err != nil {
+		return err
+	}
+	_, err := r.collection().Doc(uid).Delete(ctx)
+	return err
+}
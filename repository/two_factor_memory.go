@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryTwoFactorRepository struct {
+	store *memoryStore[models.TwoFactor]
+}
+
+// NewMemoryTwoFactorRepository creates an in-memory TwoFactorRepository
+func NewMemoryTwoFactorRepository() TwoFactorRepository {
+	return &memoryTwoFactorRepository{store: newMemoryStore[models.TwoFactor]()}
+}
+
+func (r *memoryTwoFactorRepository) Get(ctx context.Context, uid string) (*models.TwoFactor, error) {
+	return r.store.get(uid)
+}
+
+func (r *memoryTwoFactorRepository) Put(ctx context.Context, twoFactor *models.TwoFactor) error {
+	r.store.put(twoFactor.UID, *twoFactor)
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) Update(ctx context.Context, uid string, fn func(*models.TwoFactor) error) (*models.TwoFactor, error) {
+	return r.store.update(uid, fn)
+}
+
+func (r *memoryTwoFactorRepository) Delete(ctx context.Context, uid string) error {
+	return r.store.delete(uid)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// TwoFactorRepository stores the TOTP enrollments of the users
+type TwoFactorRepository interface {
+	Get(ctx context.Context, uid string) (*models.TwoFactor, error)
+	// Put creates or replaces the enrollment of the user
+	Put(ctx context.Context, twoFactor *models.TwoFactor) error
+	// Update atomically applies fn to the stored enrollment and saves the result
+	Update(ctx context.Context, uid string, fn func(*models.TwoFactor) error) (*models.TwoFactor, error)
+	Delete(ctx context.Context, uid string) error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type firestoreUploadRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreUploadRepository creates an UploadRepository backed by the "website-context" collection
+func NewFirestoreUploadRepository(client FirestoreClient) UploadRepository {
+	return &firestoreUploadRepository{client: client}
+}
+
+func (r *firestoreUploadRepository) Create(ctx context.Context, record *models.UploadRecord) error {
+	_, err := r.client.Collection("website-context").Doc(record.ID).Set(ctx, mappers.MapUploadRecordGoToFirestore(*record))
+	return err
+}
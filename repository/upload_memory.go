@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryUploadRepository struct {
+	store *memoryStore[models.UploadRecord]
+}
+
+// NewMemoryUploadRepository creates an in-memory UploadRepository
+func NewMemoryUploadRepository() UploadRepository {
+	return &memoryUploadRepository{store: newMemoryStore[models.UploadRecord]()}
+}
+
+func (r *memoryUploadRepository) Create(ctx context.Context, record *models.UploadRecord) error {
+	r.store.put(record.ID, *record)
+	return nil
+}
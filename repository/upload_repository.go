@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// UploadRepository records the files uploaded to Cloudinary
+type UploadRepository interface {
+	Create(ctx context.Context, record *models.UploadRecord) error
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"google.golang.org/api/iterator"
+)
+
+type firestoreUserRepository struct {
+	client FirestoreClient
+}
+
+// NewFirestoreUserRepository creates a UserRepository backed by the "users" collection
+func NewFirestoreUserRepository(client FirestoreClient) UserRepository {
+	return &firestoreUserRepository{client: client}
+}
+
+func (r *firestoreUserRepository) collection() *firestore.CollectionRef {
+	return r.client.Collection("users")
+}
+
+func (r *firestoreUserRepository) Create(ctx context.Context, user *models.User) error {
+	data := mappers.MapUserFrontendToBackend(user)
+
+	// Users are keyed by their UID; older documents were created with random IDs,
+	// which is why lookups below always query on the uid field.
+	if user.UID == "" {
+		_, _, err := r.collection().Add(ctx, data)
+		return err
+	}
+	_, err := r.collection().Doc(user.UID).Set(ctx, data)
+	return err
+}
+
+func (r *firestoreUserRepository) findOne(ctx context.Context, field, value string) (*firestore.DocumentSnapshot, error) {
+	iter := r.collection().Where(field, "==", value).Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user data: %v", err)
+	}
+	return doc, nil
+}
+
+func (r *firestoreUserRepository) getBy(ctx context.Context, field, value string) (*models.User, error) {
+	doc, err := r.findOne(ctx, field, value)
+	if err != nil {
+		return nil, err
+	}
+
+	user := mappers.MapBackendToUser(doc.Data())
+	return &user, nil
+}
+
+func (r *firestoreUserRepository) GetByUID(ctx context.Context, uid string) (*models.User, error) {
+	return r.getBy(ctx, "uid", uid)
+}
+
+func (r *firestoreUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.getBy(ctx, "email", email)
+}
+
+func (r *firestoreUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.getBy(ctx, "username", username)
+}
+
+func (r *firestoreUserRepository) Update(ctx context.Context, uid string, updates map[string]interface{}) error {
+	doc, err := r.findOne(ctx, "uid", uid)
+	if err != nil {
+		return err
+	}
+
+	_, err = doc.Ref.Set(ctx, updates, firestore.MergeAll)
+	return err
+}
+
+func (r *firestoreUserRepository) List(ctx context.Context) ([]models.User, error) {
+	return queryDocuments(ctx, r.collection().Query, func(doc *firestore.DocumentSnapshot) models.User {
+		return mappers.MapBackendToUser(doc.Data())
+	})
+}
+
+func (r *firestoreUserRepository) Delete(ctx context.Context, uid string) error {
+	doc, err := r.findOne(ctx, "uid", uid)
+	if err != nil {
+		return err
+	}
+
+	_, err = doc.Ref.Delete(ctx)
+	return err
+}
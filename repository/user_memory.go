@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+type memoryUserRepository struct {
+	store *memoryStore[models.User]
+}
+
+// NewMemoryUserRepository creates an in-memory UserRepository
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{store: newMemoryStore[models.User]()}
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	id := user.UID
+	if id == "" {
+		id = uuid.New().String()
+	}
+	r.store.put(id, *user)
+	return nil
+}
+
+func (r *memoryUserRepository) GetByUID(ctx context.Context, uid string) (*models.User, error) {
+	return r.store.get(uid)
+}
+
+func (r *memoryUserRepository) findOne(match func(models.User) bool) (*models.User, error) {
+	users := r.store.list(match)
+	if len(users) == 0 {
+		return nil, ErrNotFound
+	}
+	return &users[0], nil
+}
+
+func (r *memoryUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.findOne(func(u models.User) bool { return u.Email == email })
+}
+
+func (r *memoryUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.findOne(func(u models.User) bool { return u.Username == username })
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, uid string, updates map[string]interface{}) error {
+	_, err := r.store.update(uid, func(user *models.User) error {
+		data := mappers.MapUserFrontendToBackend(user)
+		for key, value := range updates {
+			data[key] = value
+		}
+
+		password := user.Password
+		*user = mappers.MapBackendToUser(data)
+		user.Password = password
+		return nil
+	})
+	return err
+}
+
+func (r *memoryUserRepository) List(ctx context.Context) ([]models.User, error) {
+	return r.store.list(nil), nil
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, uid string) error {
+	return r.store.delete(uid)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// UserRepository stores user profiles
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByUID(ctx context.Context, uid string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	// Update merges snake_case fields (as produced by mappers.MapUserFrontendToBackend) into the user
+	Update(ctx context.Context, uid string, updates map[string]interface{}) error
+	List(ctx context.Context) ([]models.User, error)
+	Delete(ctx context.Context, uid string) error
+}
@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+// setupAdminRoutes initializes the routes that manage user roles, second factors and duplicate
+// accounts, and the routes of the audit log
+func setupAdminRoutes(api fiber.Router, sc *services.ServiceContainer) error {
+	if api == nil {
+		return fmt.Errorf("api router cannot be nil")
+	}
+	if sc == nil {
+		return fmt.Errorf("service container cannot be nil")
+	}
+	if sc.AuthorizationService == nil {
+		return fmt.Errorf("authorization service cannot be nil")
+	}
+	if sc.TwoFactorService == nil {
+		return fmt.Errorf("two-factor service cannot be nil")
+	}
+	if sc.AccountMergeService == nil {
+		return fmt.Errorf("account merge service cannot be nil")
+	}
+	if sc.AuditService == nil {
+		return fmt.Errorf("audit service cannot be nil")
+	}
+
+	handler := handlers.NewRoleHandler(sc.AuthorizationService)
+	if handler == nil {
+		return fmt.Errorf("failed to create role handler")
+	}
+
+	twoFactorHandler := handlers.NewTwoFactorHandler(sc.TwoFactorService, sc.AuthService)
+	if twoFactorHandler == nil {
+		return fmt.Errorf("failed to create two-factor handler")
+	}
+
+	accountMergeHandler := handlers.NewAccountMergeHandler(sc.AccountMergeService)
+	if accountMergeHandler == nil {
+		return fmt.Errorf("failed to create account merge handler")
+	}
+
+	auditHandler := handlers.NewAuditHandler(sc.AuditService)
+	if auditHandler == nil {
+		return fmt.Errorf("failed to create audit handler")
+	}
+
+	admin := api.Group("/admin")
+	canManageRoles := middleware.RequirePermission(sc.AuthorizationService, services.PermissionManageRoles)
+	canManageUsers := middleware.RequirePermission(sc.AuthorizationService, services.PermissionManageUsers)
+	canViewAuditLog := middleware.RequirePermission(sc.AuthorizationService, services.PermissionViewAuditLog)
+	audit := func(action, targetType, targetParam string) fiber.Handler {
+		return middleware.Audit(sc.AuditService, action, targetType, targetParam)
+	}
+
+	// Roles
+	admin.Get("/roles", canManageRoles, handler.ListRoles)
+	admin.Get("/users/:uid/roles", canManageRoles, handler.GetUserRoles)
+	admin.Post("/users/:uid/roles", audit(services.AuditRoleGrant, "user", "uid"), canManageRoles, handler.GrantRole)
+	admin.Delete("/users/:uid/roles/:role", audit(services.AuditRoleRevoke, "user", "uid"), canManageRoles, handler.RevokeRole)
+
+	// Second factors
+	admin.Delete("/users/:uid/two-factor", audit(services.AuditTwoFactorReset, "user", "uid"), canManageRoles, twoFactorHandler.ResetTwoFactor)
+
+	// Duplicate accounts
+	admin.Post("/users/merge", audit(services.AuditAccountMerge, "user", ""), canManageUsers, accountMergeHandler.MergeAccounts)
+	admin.Get("/users/:uid/merges", canManageUsers, accountMergeHandler.ListMerges)
+
+	// Security audit log
+	admin.Get("/audit-log", canViewAuditLog, auditHandler.ListAuditEvents)
+	admin.Get("/audit-log/export", audit(services.AuditLogExport, "", ""), canViewAuditLog, auditHandler.ExportAuditEvents)
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -20,6 +21,27 @@ func setupAuthRoutes(api fiber.Router, sc *services.ServiceContainer) error {
 	if sc.AuthService == nil {
 		return fmt.Errorf("auth service cannot be nil")
 	}
+	if sc.EmailVerificationService == nil {
+		return fmt.Errorf("email verification service cannot be nil")
+	}
+	if sc.PasswordService == nil {
+		return fmt.Errorf("password service cannot be nil")
+	}
+	if sc.TwoFactorService == nil {
+		return fmt.Errorf("two-factor service cannot be nil")
+	}
+	if sc.OAuthService == nil {
+		return fmt.Errorf("oauth service cannot be nil")
+	}
+	if sc.LoginProtectionService == nil {
+		return fmt.Errorf("login protection service cannot be nil")
+	}
+	if sc.PersonalAccessTokenService == nil {
+		return fmt.Errorf("personal access token service cannot be nil")
+	}
+	if sc.AuditService == nil {
+		return fmt.Errorf("audit service cannot be nil")
+	}
 
 	// Create handler
 	handler := handlers.NewAuthHandler(sc.AuthService, sc)
@@ -27,14 +49,59 @@ func setupAuthRoutes(api fiber.Router, sc *services.ServiceContainer) error {
 		return fmt.Errorf("failed to create auth handler")
 	}
 
+	twoFactorHandler := handlers.NewTwoFactorHandler(sc.TwoFactorService, sc.AuthService)
+	if twoFactorHandler == nil {
+		return fmt.Errorf("failed to create two-factor handler")
+	}
+
+	tokenHandler := handlers.NewPersonalAccessTokenHandler(sc.PersonalAccessTokenService)
+	if tokenHandler == nil {
+		return fmt.Errorf("failed to create personal access token handler")
+	}
+
 	// Setup auth routes group
 	auth := api.Group("/auth")
+	audit := func(action, targetType, targetParam string) fiber.Handler {
+		return middleware.Audit(sc.AuditService, action, targetType, targetParam)
+	}
 
 	// Register routes
-	auth.Post("/login", handler.Login)
-	auth.Post("/register", handler.Register)
+	auth.Post("/login", audit(services.AuditLogin, "user", ""), handler.Login)
+	auth.Post("/login/2fa", audit(services.AuditLoginTwoFactor, "user", ""), handler.LoginTwoFactor)
+	auth.Post("/unlock", audit(services.AuditAccountUnlock, "user", ""), handler.UnlockAccount)
+	auth.Post("/register", audit(services.AuditRegister, "user", ""), handler.Register)
 	auth.Get("/session", handler.GetSession)
-	auth.Patch("/logout", handler.Logout)
+	auth.Patch("/logout", audit(services.AuditLogout, "user", ""), handler.Logout)
+	auth.Post("/refresh", audit(services.AuditTokenRefresh, "user", ""), handler.RefreshSession)
+	auth.Get("/sessions", handler.ListSessions)
+	auth.Delete("/sessions", audit(services.AuditOtherSessionsRevoke, "user", ""), handler.RevokeOtherSessions)
+	auth.Delete("/sessions/:sessionId", audit(services.AuditSessionRevoke, "session", "sessionId"), handler.RevokeSession)
+	auth.Post("/verify-email", audit(services.AuditEmailVerify, "user", ""), handler.VerifyEmail)
+	auth.Post("/verify-email/resend", handler.ResendVerificationEmail)
+	auth.Post("/forgot-password", handler.ForgotPassword)
+	auth.Post("/reset-password", audit(services.AuditPasswordReset, "user", ""), handler.ResetPassword)
+	auth.Post("/change-password", audit(services.AuditPasswordChange, "user", ""), handler.ChangePassword)
+
+	// Sign-in and account linking with OAuth providers
+	auth.Get("/oauth/providers", handler.ListOAuthProviders)
+	auth.Post("/oauth/:provider/authorize", handler.StartOAuth)
+	auth.Post("/oauth/:provider/callback", audit(services.AuditOAuthCallback, "provider", "provider"), handler.OAuthCallback)
+	auth.Get("/identities", handler.ListIdentities)
+	auth.Post("/identities/:provider", audit(services.AuditIdentityLink, "provider", "provider"), handler.LinkIdentity)
+	auth.Delete("/identities/:identityId", audit(services.AuditIdentityUnlink, "identity", "identityId"), handler.UnlinkIdentity)
+
+	// Two-factor authentication
+	auth.Get("/2fa", twoFactorHandler.GetStatus)
+	auth.Post("/2fa/setup", audit(services.AuditTwoFactorSetup, "user", ""), twoFactorHandler.BeginEnrollment)
+	auth.Post("/2fa/confirm", audit(services.AuditTwoFactorEnable, "user", ""), twoFactorHandler.ConfirmEnrollment)
+	auth.Post("/2fa/disable", audit(services.AuditTwoFactorDisable, "user", ""), twoFactorHandler.Disable)
+	auth.Post("/2fa/recovery-codes", audit(services.AuditRecoveryCodes, "user", ""), twoFactorHandler.RegenerateRecoveryCodes)
+
+	// Personal access tokens for scripts and integrations
+	auth.Get("/tokens", tokenHandler.ListTokens)
+	auth.Get("/tokens/scopes", tokenHandler.ListScopes)
+	auth.Post("/tokens", audit(services.AuditAccessTokenCreate, "access_token", ""), tokenHandler.CreateToken)
+	auth.Delete("/tokens/:tokenId", audit(services.AuditAccessTokenRevoke, "access_token", "tokenId"), tokenHandler.RevokeToken)
 
 	return nil
 }
@@ -0,0 +1,195 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
+)
+
+// publicRoutes are the only API routes served without an access token.
+// Every other route registered through an authenticatedRouter requires one.
+// Public routes still identify the caller when a valid token is sent.
+var publicRoutes = []string{
+	"POST /api/v1/auth/login",
+	"POST /api/v1/auth/login/2fa",
+	"POST /api/v1/auth/unlock",
+	"POST /api/v1/auth/register",
+	"POST /api/v1/auth/refresh",
+	"POST /api/v1/auth/verify-email",
+	"POST /api/v1/auth/forgot-password",
+	"POST /api/v1/auth/reset-password",
+	"GET /api/v1/auth/oauth/providers",
+	"POST /api/v1/auth/oauth/:provider/authorize",
+	"POST /api/v1/auth/oauth/:provider/callback",
+
+	"POST /api/v1/contact_users",
+	"POST /api/v1/chat",
+	"GET /api/v1/faqs",
+	"GET /api/v1/faqs/:id",
+	"POST /api/v1/newsletters/subscribe",
+	"POST /api/v1/newsletters/unsubscribe",
+	"GET /api/v1/newsletters/subscribers/count",
+
+	"GET /api/v1/users",
+	"GET /api/v1/users/:uid",
+	"GET /api/v1/connections/:uid",
+	"GET /api/v1/connections/:uid/count",
+	"GET /api/v1/projects/public",
+
+	"GET /api/v1/group-forums",
+	"GET /api/v1/group-forums/search",
+	"GET /api/v1/group-forums/:id",
+	"GET /api/v1/forums/:id",
+	"GET /api/v1/forums/group/:groupId",
+	"GET /api/v1/forums/:id/posts/search",
+
+	"GET /api/v1/testimonials",
+	"GET /api/v1/testimonials/:id",
+	"GET /api/v1/testimonials/alumni/:id",
+}
+
+// routeAllowlist tracks which public routes were actually registered
+type routeAllowlist struct {
+	public     map[string]bool
+	registered map[string]bool
+}
+
+func newRouteAllowlist(routes []string) *routeAllowlist {
+	allowlist := &routeAllowlist{
+		public:     make(map[string]bool, len(routes)),
+		registered: make(map[string]bool, len(routes)),
+	}
+	for _, route := range routes {
+		allowlist.public[route] = true
+	}
+	return allowlist
+}
+
+// allows reports whether the route is public and records that it exists
+func (a *routeAllowlist) allows(method, path string) bool {
+	key := method + " " + path
+	if a.public[key] {
+		a.registered[key] = true
+		return true
+	}
+	return false
+}
+
+// unregistered returns the public routes that no route setup registered, usually typos
+func (a *routeAllowlist) unregistered() []string {
+	missing := []string{}
+	for route := range a.public {
+		if !a.registered[route] {
+			missing = append(missing, route)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// authenticatedRouter registers routes behind middleware.Authenticate,
+// except the ones on the public allowlist
+type authenticatedRouter struct {
+	fiber.Router
+	prefix    string
+	allowlist *routeAllowlist
+}
+
+func newAuthenticatedRouter(router fiber.Router, prefix string, allowlist *routeAllowlist) *authenticatedRouter {
+	return &authenticatedRouter{Router: router, prefix: prefix, allowlist: allowlist}
+}
+
+// routePath joins the group prefix and the route path the way fiber does
+func (r *authenticatedRouter) routePath(path string) string {
+	full := strings.TrimRight(r.prefix+"/"+strings.TrimLeft(path, "/"), "/")
+	if full == "" {
+		return "/"
+	}
+	return full
+}
+
+// guard prepends the authentication middleware to the route handlers
+func (r *authenticatedRouter) guard(method, path string, handlers []fiber.Handler) []fiber.Handler {
+	if r.allowlist.allows(method, r.routePath(path)) {
+		return append([]fiber.Handler{middleware.OptionalAuthenticate}, handlers...)
+	}
+	return append([]fiber.Handler{middleware.Authenticate}, handlers...)
+}
+
+func (r *authenticatedRouter) Get(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Get(path, r.guard(http.MethodGet, path, handlers)...)
+	return r
+}
+
+func (r *authenticatedRouter) Head(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Head(path, r.guard(http.MethodHead, path, handlers)...)
+	return r
+}
+
+func (r *authenticatedRouter) Post(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Post(path, r.guard(http.MethodPost, path, handlers)...)
+	return r
+}
+
+func (r *authenticatedRouter) Put(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Put(path, r.guard(http.MethodPut, path, handlers)...)
+	return r
+}
+
+func (r *authenticatedRouter) Delete(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Delete(path, r.guard(http.MethodDelete, path, handlers)...)
+	return r
+}
+
+func (r *authenticatedRouter) Connect(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Connect(path, r.guard(http.MethodConnect, path, handlers)...)
+	return r
+}
+
+func (r *authenticatedRouter) Options(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Options(path, r.guard(http.MethodOptions, path, handlers)...)
+	return r
+}
+
+func (r *authenticatedRouter) Trace(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Trace(path, r.guard(http.MethodTrace, path, handlers)...)
+	return r
+}
+
+func (r *authenticatedRouter) Patch(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Patch(path, r.guard(http.MethodPatch, path, handlers)...)
+	return r
+}
+
+func (r *authenticatedRouter) Add(method, path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Add(method, path, r.guard(strings.ToUpper(method), path, handlers)...)
+	return r
+}
+
+// All routes are never public: a single allowlist entry cannot cover every method
+func (r *authenticatedRouter) All(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.All(path, append([]fiber.Handler{middleware.Authenticate}, handlers...)...)
+	return r
+}
+
+func (r *authenticatedRouter) Group(prefix string, handlers ...fiber.Handler) fiber.Router {
+	return newAuthenticatedRouter(r.Router.Group(prefix, handlers...), r.routePath(prefix), r.allowlist)
+}
+
+func (r *authenticatedRouter) Route(prefix string, fn func(router fiber.Router), name ...string) fiber.Router {
+	group := r.Group(prefix)
+	if len(name) > 0 {
+		group.Name(name[0])
+	}
+	fn(group)
+	return group
+}
+
+// Mount is not supported: the mounted app's routes would bypass authentication
+func (r *authenticatedRouter) Mount(prefix string, app *fiber.App) fiber.Router {
+	panic(fmt.Sprintf("cannot mount an app on authenticated route group %s", r.routePath(prefix)))
+}
@@ -0,0 +1,145 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/config"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/rogerjeasy/go-letusconnect/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var routeParam = regexp.MustCompile(`:[^/]+`)
+
+// newTestApp registers every route against the in-memory repositories
+func newTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+	config.IdentityProvider = "local"
+	// The PDF context is loaded on start, an unreachable URL only logs the failure
+	config.PDFContextURL = "http://127.0.0.1:1/context.pdf"
+
+	repos := repository.NewMemoryRepositories()
+	sc := services.NewServiceContainer(repos, services.NewUserService(repos.Users), nil)
+
+	app := fiber.New()
+	require.NoError(t, SetupAllRoutes(app, sc))
+	return app
+}
+
+func TestSetupAllRoutesRequiresAuthentication(t *testing.T) {
+	app := newTestApp(t)
+	public := newRouteAllowlist(publicRoutes)
+
+	checked := 0
+	for _, route := range app.GetRoutes(true) {
+		if !strings.HasPrefix(route.Path, "/api/v1") || route.Method == http.MethodHead {
+			continue
+		}
+		// Group roots are listed with a trailing slash, the allowlist keys have none
+		if public.allows(route.Method, strings.TrimRight(route.Path, "/")) {
+			continue
+		}
+
+		path := routeParam.ReplaceAllString(route.Path, "x")
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			for _, authorization := range []string{"", "Bearer not-a-token", "Basic YWRhOnNlY3JldA=="} {
+				req := httptest.NewRequest(route.Method, path, nil)
+				if authorization != "" {
+					req.Header.Set("Authorization", authorization)
+				}
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode, "authorization %q", authorization)
+			}
+		})
+		checked++
+	}
+	assert.NotZero(t, checked)
+}
+
+func TestSetupAllRoutesServesPublicRoutes(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: "/api/v1/faqs"},
+		{method: http.MethodGet, path: "/api/v1/testimonials"},
+		{method: http.MethodGet, path: "/api/v1/auth/oauth/providers"},
+		{method: http.MethodGet, path: "/api/v1/newsletters/subscribers/count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+			assert.NotEqual(t, fiber.StatusUnauthorized, resp.StatusCode)
+		})
+	}
+}
+
+func TestRouteAllowlist(t *testing.T) {
+	allowlist := newRouteAllowlist([]string{"GET /api/v1/faqs", "GET /api/v1/faq"})
+
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{method: http.MethodGet, path: "/api/v1/faqs", want: true},
+		{method: http.MethodPost, path: "/api/v1/faqs"},
+		{method: http.MethodGet, path: "/api/v1/faqs/:id"},
+		{method: http.MethodGet, path: "/api/v1/FAQS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, allowlist.allows(tt.method, tt.path))
+		})
+	}
+
+	// The misspelled entry was never registered
+	assert.Equal(t, []string{"GET /api/v1/faq"}, allowlist.unregistered())
+}
+
+func TestAuthenticatedRouter(t *testing.T) {
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+
+	app := fiber.New()
+	allowlist := newRouteAllowlist([]string{"GET /api/v1/things", "GET /api/v1/things/:id", "GET /api/v1/everything"})
+	api := newAuthenticatedRouter(app.Group("/api/v1"), "/api/v1", allowlist)
+	api.Get("/things", ok)
+	api.Post("/things", ok)
+	api.Group("/things").Get("/:id", ok)
+	api.All("/everything", ok)
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{method: http.MethodGet, path: "/api/v1/things", want: fiber.StatusOK},
+		{method: http.MethodPost, path: "/api/v1/things", want: fiber.StatusUnauthorized},
+		{method: http.MethodGet, path: "/api/v1/things/1", want: fiber.StatusOK},
+		// All is authenticated even when its path is on the allowlist
+		{method: http.MethodGet, path: "/api/v1/everything", want: fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+
+	assert.Equal(t, []string{"GET /api/v1/everything"}, allowlist.unregistered())
+	assert.Panics(t, func() { api.Mount("/sub", fiber.New()) })
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -15,6 +16,9 @@ func setupContactUserRoutes(api fiber.Router, sc *services.ServiceContainer) err
 	if sc == nil {
 		return fmt.Errorf("service container cannot be nil")
 	}
+	if sc.AuthorizationService == nil {
+		return fmt.Errorf("authorization service cannot be nil")
+	}
 	if sc.ContactUsService == nil {
 		return fmt.Errorf("contact user service cannot be nil")
 	}
@@ -25,12 +29,13 @@ func setupContactUserRoutes(api fiber.Router, sc *services.ServiceContainer) err
 	}
 
 	contacts := api.Group("/contact_users")
+	canManage := middleware.RequirePermission(sc.AuthorizationService, services.PermissionManageContacts)
 
 	// Contact Us Routes
 	contacts.Post("/", handler.CreateContact)
-	contacts.Get("/", handler.GetAllContacts)
-	contacts.Get("/:id", handler.GetContactByID)
-	contacts.Put("/:id", handler.UpdateContactStatus)
+	contacts.Get("/", canManage, handler.GetAllContacts)
+	contacts.Get("/:id", canManage, handler.GetContactByID)
+	contacts.Put("/:id", canManage, handler.UpdateContactStatus)
 
 	return nil
 }
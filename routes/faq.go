@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -15,6 +16,9 @@ func setupFAQRoutes(api fiber.Router, sc *services.ServiceContainer) error {
 	if sc == nil {
 		return fmt.Errorf("service container cannot be nil")
 	}
+	if sc.AuthorizationService == nil {
+		return fmt.Errorf("authorization service cannot be nil")
+	}
 	if sc.FAQService == nil {
 		return fmt.Errorf("faq service cannot be nil")
 	}
@@ -25,11 +29,12 @@ func setupFAQRoutes(api fiber.Router, sc *services.ServiceContainer) error {
 	}
 
 	faqs := api.Group("/faqs")
+	canManage := middleware.RequirePermission(sc.AuthorizationService, services.PermissionManageFAQs)
 	faqs.Get("/", handler.GetAllFAQs)
 	faqs.Get("/:id", handler.GetFAQByID)
-	faqs.Post("/", handler.CreateFAQ)
-	faqs.Put("/:id", handler.UpdateFAQ)
-	faqs.Delete("/:id", handler.DeleteFAQ)
+	faqs.Post("/", canManage, handler.CreateFAQ)
+	faqs.Put("/:id", canManage, handler.UpdateFAQ)
+	faqs.Delete("/:id", canManage, handler.DeleteFAQ)
 
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -21,26 +22,40 @@ func setupGroupChatRoutes(api fiber.Router, sc *services.ServiceContainer) error
 	if sc.UserService == nil {
 		return fmt.Errorf("user service cannot be nil")
 	}
+	if sc.EmailVerificationService == nil {
+		return fmt.Errorf("email verification service cannot be nil")
+	}
+	if sc.PresenceService == nil {
+		return fmt.Errorf("presence service cannot be nil")
+	}
+	if sc.TypingService == nil {
+		return fmt.Errorf("typing service cannot be nil")
+	}
 
-	handler := handlers.NewGroupChatHandler(sc.GroupChatService, sc.UserService)
+	handler := handlers.NewGroupChatHandler(sc.GroupChatService, sc.UserService, sc.TypingService)
 	if handler == nil {
 		return fmt.Errorf("failed to create group chat handler")
 	}
 
+	presenceHandler := handlers.NewPresenceHandler(sc.PresenceService, sc.ConnectionService)
+
 	groupChats := api.Group("/group-chats")
+	isVerified := middleware.RequireVerifiedEmail(sc.EmailVerificationService)
 
 	// Group Chat Routes
-	groupChats.Post("/", handler.CreateGroupChatF)
+	groupChats.Post("/", isVerified, handler.CreateGroupChatF)
 	groupChats.Get("/:id", handler.GetGroupChat)
 	groupChats.Get("/projects/:projectId/group-chats", handler.GetGroupChatsByProject)
 	groupChats.Get("/my/group-chats", handler.GetMyGroupChats)
-	groupChats.Post("/messages", handler.SendMessageHandler)
+	groupChats.Post("/messages", isVerified, handler.SendMessageHandler)
+	groupChats.Get("/:groupChatId/messages", handler.GetGroupChatMessagesHandler)
 	groupChats.Patch("/:groupChatId/mark-messages-read", handler.MarkMessagesAsReadHandler)
+	groupChats.Post("/:groupChatId/typing", handler.SendTypingHandler)
 	groupChats.Get("/unread-messages/count", handler.CountUnreadMessagesHandler)
 	groupChats.Get("/unread/total", handler.CountUnreadGroupMessagesFromAllChatHandler)
 	groupChats.Post("/:groupChatId/remove-participants", handler.RemoveParticipantsFromGroupChatHandler)
-	groupChats.Post("/reply-message", handler.ReplyToMessageHandler)
-	groupChats.Post("/attach-files", handler.AttachFilesToMessageHandler)
+	groupChats.Post("/reply-message", isVerified, handler.ReplyToMessageHandler)
+	groupChats.Post("/attach-files", isVerified, handler.AttachFilesToMessageHandler)
 	groupChats.Post("/pin-message", handler.PinMessageHandler)
 	groupChats.Get("/pinned-messages", handler.GetPinnedMessagesHandler)
 	groupChats.Post("/unpin-message", handler.UnpinMessageHandler)
@@ -48,7 +63,7 @@ func setupGroupChatRoutes(api fiber.Router, sc *services.ServiceContainer) error
 	groupChats.Get("/message-read-receipts/:groupChatId/:messageId", handler.GetMessageReadReceiptsHandler)
 	groupChats.Post("/set-role", handler.SetParticipantRoleHandler)
 	groupChats.Post("/mute-participant", handler.MuteParticipantHandler)
-	groupChats.Get("/online-status/:participantId", handler.UpdateLastSeenHandler)
+	groupChats.Get("/online-status/:participantId", presenceHandler.GetParticipantPresence)
 
 	// delete group chat
 	groupChats.Delete("/:id", handler.DeleteGroupChat)
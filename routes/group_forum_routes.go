@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -15,6 +16,9 @@ func setupGroupRoutes(api fiber.Router, sc *services.ServiceContainer) error {
 	if sc == nil {
 		return fmt.Errorf("service container cannot be nil")
 	}
+	if sc.AuthorizationService == nil {
+		return fmt.Errorf("authorization service cannot be nil")
+	}
 	if sc.GroupService == nil {
 		return fmt.Errorf("group service cannot be nil")
 	}
@@ -28,32 +32,33 @@ func setupGroupRoutes(api fiber.Router, sc *services.ServiceContainer) error {
 	}
 
 	groups := api.Group("/group-forums")
+	isAdmin := middleware.RequireResource(sc.AuthorizationService.CanManageGroup, "id")
 
 	groups.Post("/", handler.CreateGroup)
 	groups.Get("/my-groups", handler.ListGroupsByUser)
 	groups.Get("/search", handler.SearchGroups)
 	groups.Get("/:id", handler.GetGroup)
-	groups.Put("/:id", handler.UpdateGroup)
-	groups.Delete("/:id", handler.DeleteGroup)
+	groups.Put("/:id", isAdmin, handler.UpdateGroup)
+	groups.Delete("/:id", isAdmin, handler.DeleteGroup)
 	groups.Get("/", handler.ListGroups)
 
 	// Members
-	groups.Post("/:id/members", handler.AddMember)
-	groups.Delete("/:id/members/:userId", handler.RemoveMember)
+	groups.Post("/:id/members", isAdmin, handler.AddMember)
+	groups.Delete("/:id/members/:userId", isAdmin, handler.RemoveMember)
 
 	// Images
-	groups.Post("/:id/image", handler.UploadGroupImage)
+	groups.Post("/:id/image", isAdmin, handler.UploadGroupImage)
 
 	// Events
-	groups.Post("/:id/events", handler.AddEvent)
-	groups.Delete("/:id/events/:eventId", handler.RemoveEvent)
+	groups.Post("/:id/events", isAdmin, handler.AddEvent)
+	groups.Delete("/:id/events/:eventId", isAdmin, handler.RemoveEvent)
 
 	// Resources
-	groups.Post("/:id/resources", handler.AddResource)
-	groups.Delete("/:id/resources/:resourceId", handler.RemoveResource)
+	groups.Post("/:id/resources", isAdmin, handler.AddResource)
+	groups.Delete("/:id/resources/:resourceId", isAdmin, handler.RemoveResource)
 
 	// Settings
-	groups.Put("/:id/settings", handler.UpdateGroupSettings)
+	groups.Put("/:id/settings", isAdmin, handler.UpdateGroupSettings)
 
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -15,28 +16,40 @@ func setupDirectMessageRoutes(api fiber.Router, sc *services.ServiceContainer) e
 	if sc == nil {
 		return fmt.Errorf("service container cannot be nil")
 	}
-	if sc.MessageService == nil {
-		return fmt.Errorf("message service cannot be nil")
-	}
 	if sc.UserService == nil {
 		return fmt.Errorf("user service cannot be nil")
 	}
+	if sc.ConversationService == nil {
+		return fmt.Errorf("conversation service cannot be nil")
+	}
+	if sc.EmailVerificationService == nil {
+		return fmt.Errorf("email verification service cannot be nil")
+	}
+	if sc.TypingService == nil {
+		return fmt.Errorf("typing service cannot be nil")
+	}
 
-	handler := handlers.NewMessageHandler(sc.MessageService, sc.UserService)
+	handler := handlers.NewMessageHandler(sc.UserService, sc.ConversationService, sc.TypingService)
 	if handler == nil {
 		return fmt.Errorf("failed to create message handler")
 	}
 
 	messages := api.Group("/messages")
+	isVerified := middleware.RequireVerifiedEmail(sc.EmailVerificationService)
 
-	messages.Post("/send", handler.SendMessage)
+	messages.Post("/send", isVerified, handler.SendMessage)
 	messages.Get("/", handler.GetMessages)
 	messages.Post("/typing", handler.SendTyping)
-	messages.Post("/direct", handler.SendDirectMessage)
-	// messages.Post("/group", handlers.SendGroupMessage)
+	messages.Post("/direct", isVerified, handler.SendDirectMessage)
 	messages.Get("/direct", handler.GetDirectMessages)
-	messages.Get("/unread", handlers.GetUnreadMessagesCount)
-	messages.Patch("/mark-as-read", handlers.MarkMessagesAsRead)
+	messages.Get("/unread", handler.GetUnreadMessagesCount)
+	messages.Patch("/mark-as-read", handler.MarkMessagesAsRead)
+
+	messages.Get("/conversations", handler.GetConversations)
+	messages.Get("/conversations/:conversationId/messages", handler.GetConversationMessages)
+	messages.Patch("/conversations/:conversationId/read", handler.MarkConversationAsRead)
+	messages.Patch("/conversations/:conversationId/mute", handler.MuteConversation)
+	messages.Patch("/conversations/:conversationId/archive", handler.ArchiveConversation)
 
 	return nil
 }
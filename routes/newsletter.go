@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -15,6 +16,9 @@ func setupNewsletterRoutes(api fiber.Router, sc *services.ServiceContainer) erro
 	if sc == nil {
 		return fmt.Errorf("service container cannot be nil")
 	}
+	if sc.AuthorizationService == nil {
+		return fmt.Errorf("authorization service cannot be nil")
+	}
 	if sc.NewsletterService == nil {
 		return fmt.Errorf("newsletter service cannot be nil")
 	}
@@ -25,11 +29,12 @@ func setupNewsletterRoutes(api fiber.Router, sc *services.ServiceContainer) erro
 	}
 
 	newsletters := api.Group("/newsletters")
+	canManage := middleware.RequirePermission(sc.AuthorizationService, services.PermissionManageNewsletter)
 
 	// newsletters
 	newsletters.Post("/subscribe", handler.SubscribeNewsletter)
 	newsletters.Post("/unsubscribe", handler.UnsubscribeNewsletter)
-	newsletters.Get("/subscribers", handler.GetAllSubscribers)
+	newsletters.Get("/subscribers", canManage, handler.GetAllSubscribers)
 	newsletters.Get("/subscribers/count", handler.GetTotalSubscribers)
 
 	return nil
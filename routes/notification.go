@@ -19,9 +19,12 @@ func setupNotificationRoutes(api fiber.Router, sc *services.ServiceContainer) er
 	if sc.NotificationService == nil {
 		return fmt.Errorf("notification service cannot be nil")
 	}
+	if sc.EventStream == nil {
+		return fmt.Errorf("event stream cannot be nil")
+	}
 
 	// Create handler
-	handler := handlers.NewNotificationHandler(sc.NotificationService)
+	handler := handlers.NewNotificationHandler(sc.NotificationService, sc.EventStream)
 	if handler == nil {
 		return fmt.Errorf("failed to create notification handler")
 	}
@@ -30,6 +33,7 @@ func setupNotificationRoutes(api fiber.Router, sc *services.ServiceContainer) er
 
 	notifications.Get("/targeted", handler.ListTargetedNotifications)
 	notifications.Get("/unread-count", handler.GetUnreadNotificationCount)
+	notifications.Get("/stream", handler.StreamEvents)
 	notifications.Get("/stats", handler.GetNotificationStats)
 	notifications.Patch("/:id", handler.MarkNotificationAsRead)
 	notifications.Post("/", handler.CreateNotification)
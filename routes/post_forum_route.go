@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -15,6 +16,9 @@ func setupForumRoutes(api fiber.Router, sc *services.ServiceContainer) error {
 	if sc == nil {
 		return fmt.Errorf("service container cannot be nil")
 	}
+	if sc.AuthorizationService == nil {
+		return fmt.Errorf("authorization service cannot be nil")
+	}
 	if sc.ForumService == nil {
 		return fmt.Errorf("forum service cannot be nil")
 	}
@@ -28,12 +32,13 @@ func setupForumRoutes(api fiber.Router, sc *services.ServiceContainer) error {
 	}
 
 	forums := api.Group("/forums")
+	isModerator := middleware.RequireResource(sc.AuthorizationService.CanModerateForum, "id")
 
 	// Basic CRUD operations
 	forums.Post("/", handler.CreateForum)
 	forums.Get("/:id", handler.GetForum)
-	forums.Put("/:id", handler.UpdateForum)
-	forums.Delete("/:id", handler.DeleteForum)
+	forums.Put("/:id", isModerator, handler.UpdateForum)
+	forums.Delete("/:id", isModerator, handler.DeleteForum)
 
 	// Group-related routes
 	forums.Get("/group/:groupId", handler.ListForumsByGroup)
@@ -49,8 +54,8 @@ func setupForumRoutes(api fiber.Router, sc *services.ServiceContainer) error {
 	forums.Post("/:id/reactions", handler.AddReaction)
 
 	// Moderator management
-	forums.Post("/:id/moderators", handler.AddModerator)
-	forums.Delete("/:id/moderators/:userId", handler.RemoveModerator)
+	forums.Post("/:id/moderators", isModerator, handler.AddModerator)
+	forums.Delete("/:id/moderators/:userId", isModerator, handler.RemoveModerator)
 
 	return nil
 }
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/services"
+)
+
+// setupPresenceRoutes sets up the routes that query who is online
+func setupPresenceRoutes(api fiber.Router, sc *services.ServiceContainer) error {
+	if api == nil {
+		return fmt.Errorf("api router cannot be nil")
+	}
+	if sc == nil {
+		return fmt.Errorf("service container cannot be nil")
+	}
+	if sc.PresenceService == nil {
+		return fmt.Errorf("presence service cannot be nil")
+	}
+	if sc.ConnectionService == nil {
+		return fmt.Errorf("connection service cannot be nil")
+	}
+
+	handler := handlers.NewPresenceHandler(sc.PresenceService, sc.ConnectionService)
+	if handler == nil {
+		return fmt.Errorf("failed to create presence handler")
+	}
+
+	presence := api.Group("/presence")
+	presence.Get("/", handler.GetPresences)
+	presence.Get("/me", handler.GetMyPresence)
+	presence.Put("/me/visibility", handler.UpdateMyVisibility)
+	presence.Get("/connections", handler.GetConnectionsPresence)
+	presence.Get("/group-chats/:groupChatId", handler.GetGroupChatPresence)
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -15,6 +16,9 @@ func setupProjectCollab(api fiber.Router, sc *services.ServiceContainer) error {
 	if sc == nil {
 		return fmt.Errorf("service container cannot be nil")
 	}
+	if sc.AuthorizationService == nil {
+		return fmt.Errorf("authorization service cannot be nil")
+	}
 	if sc.ProjectService == nil {
 		return fmt.Errorf("project service cannot be nil")
 	}
@@ -28,12 +32,13 @@ func setupProjectCollab(api fiber.Router, sc *services.ServiceContainer) error {
 	}
 
 	projects := api.Group("/projects")
+	isOwner := middleware.RequireResource(sc.AuthorizationService.CanManageProject, "id")
 
 	// 2. Collaboration Endpoints
 	projects.Post("/:id/join", handler.JoinProjectCollab)
-	projects.Put("/:id/join-requests/:uid", handler.AcceptRejectJoinRequestCollab)
-	projects.Post("/:id/invite", handler.InviteUserCollab)
-	projects.Delete("/:id/participants/:uid", handler.RemoveParticipantCollab)
+	projects.Put("/:id/join-requests/:uid", isOwner, handler.AcceptRejectJoinRequestCollab)
+	projects.Post("/:id/invite", isOwner, handler.InviteUserCollab)
+	projects.Delete("/:id/participants/:uid", isOwner, handler.RemoveParticipantCollab)
 
 	// 3. Task Endpoints
 	projects.Post("/:id/tasks", handler.AddTask)
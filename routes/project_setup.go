@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rogerjeasy/go-letusconnect/handlers"
+	"github.com/rogerjeasy/go-letusconnect/middleware"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -15,6 +16,12 @@ func setupProjectCoreRoutes(api fiber.Router, sc *services.ServiceContainer) err
 	if sc == nil {
 		return fmt.Errorf("service container cannot be nil")
 	}
+	if sc.AuthorizationService == nil {
+		return fmt.Errorf("authorization service cannot be nil")
+	}
+	if sc.EmailVerificationService == nil {
+		return fmt.Errorf("email verification service cannot be nil")
+	}
 	if sc.ProjectCoreService == nil {
 		return fmt.Errorf("project core service cannot be nil")
 	}
@@ -28,17 +35,18 @@ func setupProjectCoreRoutes(api fiber.Router, sc *services.ServiceContainer) err
 	}
 
 	projects := api.Group("/projects")
+	isOwner := middleware.RequireResource(sc.AuthorizationService.CanManageProject, "id")
+	isVerified := middleware.RequireVerifiedEmail(sc.EmailVerificationService)
 
 	// Project Management Routes
 	projects.Get("/owner", handler.GetOwnerProjects)
 	projects.Get("/participation", handler.GetParticipationProjects)
 	projects.Get("/public", handler.GetAllPublicProjects)
-	// projects.Use(middleware.AuthMiddleware)
-	projects.Post("/", handler.CreateProject)
+	projects.Post("/", isVerified, handler.CreateProject)
 	// projects.Get("/", handlers.GetAllProjects)
 	projects.Get("/:id", handler.GetProject)
-	projects.Put("/:id", handler.UpdateProject)
-	projects.Delete("/:id", handler.DeleteProject)
+	projects.Put("/:id", isOwner, handler.UpdateProject)
+	projects.Delete("/:id", isOwner, handler.DeleteProject)
 
 	return nil
 
@@ -2,8 +2,10 @@ package routes
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rogerjeasy/go-letusconnect/handlers"
 	"github.com/rogerjeasy/go-letusconnect/services"
 )
 
@@ -16,7 +18,11 @@ func SetupAllRoutes(app *fiber.App, sc *services.ServiceContainer) error {
 		return fmt.Errorf("service container cannot be nil")
 	}
 
-	api := app.Group("/api/v1")
+	app.Get("/.well-known/jwks.json", handlers.GetJWKS)
+
+	// Every API route requires an access token unless it is listed in publicRoutes
+	allowlist := newRouteAllowlist(publicRoutes)
+	api := newAuthenticatedRouter(app.Group("/api/v1"), "/api/v1", allowlist)
 
 	// Apply common middleware
 	// api.Use(middleware.ConfigureCORS())
@@ -28,6 +34,7 @@ func SetupAllRoutes(app *fiber.App, sc *services.ServiceContainer) error {
 		{"user", setupUserRoutes},
 		{"notification", setupNotificationRoutes},
 		{"auth", setupAuthRoutes},
+		{"admin", setupAdminRoutes},
 		{"faq", setupFAQRoutes},
 		{"projectCore", setupProjectCoreRoutes},
 		{"projectCollab", setupProjectCollab},
@@ -38,7 +45,8 @@ func SetupAllRoutes(app *fiber.App, sc *services.ServiceContainer) error {
 		{"newsletter", setupNewsletterRoutes},
 		{"contactUser", setupContactUserRoutes},
 		{"chat", setupChatRoutes},
-		// {"webSocket", SetupWebSocketRoutes},
+		{"pusher", setupPusherRoutes},
+		{"presence", setupPresenceRoutes},
 		{"schoolExperience", setupUserSchoolExperienceRoutes},
 		{"group", setupGroupRoutes},
 		{"forum", setupForumRoutes},
@@ -54,5 +62,9 @@ func SetupAllRoutes(app *fiber.App, sc *services.ServiceContainer) error {
 		}
 	}
 
+	if missing := allowlist.unregistered(); len(missing) > 0 {
+		return fmt.Errorf("public routes are not registered: %s", strings.Join(missing, ", "))
+	}
+
 	return nil
 }
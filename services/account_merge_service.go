@@ -0,0 +1,536 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+// mergePageSize is how many messages a merge reads at once
+const mergePageSize = 100
+
+var (
+	// ErrMergeSameUser is returned when merging an account into itself
+	ErrMergeSameUser = errors.New("cannot merge an account into itself")
+	// ErrMergeOwnAccount is returned when admins merge away the account they are signed in with
+	ErrMergeOwnAccount = errors.New("cannot merge the account you are signed in with into another one")
+)
+
+// ConnectionMerger moves the connections of a user to another user
+type ConnectionMerger interface {
+	MergeConnections(ctx context.Context, sourceUID, targetUID string) (int, error)
+}
+
+// AccountMergeService consolidates duplicate accounts: everything the source user owns or
+// takes part in moves to the target user, and the source account is deleted.
+// A merge is not atomic; it stops at the first error and the records moved so far stay moved.
+type AccountMergeService struct {
+	repos         *repository.Repositories
+	sessions      *SessionService
+	authorization *AuthorizationService
+	accounts      IdentityProvider
+	connections   ConnectionMerger
+}
+
+func NewAccountMergeService(repos *repository.Repositories, sessions *SessionService, authorization *AuthorizationService, accounts IdentityProvider, connections ConnectionMerger) *AccountMergeService {
+	return &AccountMergeService{
+		repos:         repos,
+		sessions:      sessions,
+		authorization: authorization,
+		accounts:      accounts,
+		connections:   connections,
+	}
+}
+
+// Merge moves the connections, projects, messages, notifications, linked identities and
+// roles of the source user to the target user, then deletes the source user
+func (s *AccountMergeService) Merge(ctx context.Context, actorUID, sourceUID, targetUID string) (*models.AccountMerge, error) {
+	if sourceUID == targetUID {
+		return nil, ErrMergeSameUser
+	}
+	if sourceUID == actorUID {
+		return nil, ErrMergeOwnAccount
+	}
+
+	source, err := s.repos.Users.GetByUID(ctx, sourceUID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.repos.Users.GetByUID(ctx, targetUID)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := map[string]int{}
+	steps := []struct {
+		kind  string
+		merge func(ctx context.Context, source, target *models.User) (int, error)
+	}{
+		{"connections", s.mergeConnections},
+		{"projects", s.mergeProjects},
+		{"groupChats", s.mergeGroupChats},
+		{"conversations", s.mergeConversations},
+		{"notifications", s.mergeNotifications},
+		{"identities", s.mergeIdentities},
+		{"roles", s.mergeRoles},
+	}
+	for _, step := range steps {
+		count, err := step.merge(ctx, source, target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge %s: %v", step.kind, err)
+		}
+		moved[step.kind] = count
+	}
+
+	if _, err := s.sessions.RevokeSessionsExcept(ctx, source.UID, "", "account merged"); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %v", err)
+	}
+	if err := s.repos.TwoFactor.Delete(ctx, source.UID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to delete two-factor enrollment: %v", err)
+	}
+	if err := s.accounts.DeleteAccount(ctx, source.UID); err != nil {
+		return nil, err
+	}
+	if err := s.repos.Users.Delete(ctx, source.UID); err != nil {
+		return nil, fmt.Errorf("failed to delete user: %v", err)
+	}
+
+	merge := &models.AccountMerge{
+		ID:             uuid.New().String(),
+		SourceUID:      source.UID,
+		SourceEmail:    source.Email,
+		SourceUsername: source.Username,
+		TargetUID:      target.UID,
+		MergedBy:       actorUID,
+		Moved:          moved,
+		MergedAt:       time.Now(),
+	}
+	if err := s.repos.AccountMerges.Create(ctx, merge); err != nil {
+		return nil, fmt.Errorf("failed to save account merge: %v", err)
+	}
+	return merge, nil
+}
+
+// ListMerges lists the accounts merged into the user, oldest first
+func (s *AccountMergeService) ListMerges(ctx context.Context, uid string) ([]models.AccountMerge, error) {
+	merges, err := s.repos.AccountMerges.ListByTarget(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account merges: %v", err)
+	}
+	return merges, nil
+}
+
+func (s *AccountMergeService) mergeConnections(ctx context.Context, source, target *models.User) (int, error) {
+	return s.connections.MergeConnections(ctx, source.UID, target.UID)
+}
+
+// mergeProjects replaces the source user in the projects they own or take part in
+func (s *AccountMergeService) mergeProjects(ctx context.Context, source, target *models.User) (int, error) {
+	projects, err := s.repos.Projects.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, project := range projects {
+		if !projectMentions(project, source.UID) {
+			continue
+		}
+		if _, err := s.repos.Projects.Update(ctx, project.ID, func(project *models.Project) error {
+			if project.OwnerID == source.UID {
+				project.OwnerID = target.UID
+				project.OwnerUsername = target.Username
+			}
+			project.Participants = mergeParticipants(project.Participants, source, target)
+			project.InvitedUsers = mergeInvitedUsers(project.InvitedUsers, source, target)
+			project.JoinRequests = mergeJoinRequests(project.JoinRequests, source, target)
+			project.RejectedParticipants = replaceUID(project.RejectedParticipants, source.UID, target.UID)
+			return nil
+		}); err != nil {
+			return 0, err
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// mergeGroupChats replaces the source user in their group chats and the messages of those chats
+func (s *AccountMergeService) mergeGroupChats(ctx context.Context, source, target *models.User) (int, error) {
+	chats, err := s.repos.GroupChats.ListByParticipant(ctx, source.UID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, chat := range chats {
+		if _, err := s.repos.GroupChats.Update(ctx, chat.ID, func(chat *models.GroupChat) error {
+			if chat.CreatedByUID == source.UID {
+				chat.CreatedByUID = target.UID
+				chat.CreatedByName = target.Username
+			}
+			chat.Participants = mergeParticipants(chat.Participants, source, target)
+			renameBoolKey(chat.ReadStatus, source.UID, target.UID)
+			renameBoolKey(chat.Notifications, source.UID, target.UID)
+			if seen, ok := chat.LastSeen[source.UID]; ok {
+				delete(chat.LastSeen, source.UID)
+				if seen.After(chat.LastSeen[target.UID]) {
+					chat.LastSeen[target.UID] = seen
+				}
+			}
+			return nil
+		}); err != nil {
+			return 0, err
+		}
+
+		if err := s.mergeGroupMessages(ctx, chat.ID, source, target); err != nil {
+			return 0, err
+		}
+		if err := s.mergeReadPosition(ctx, chat.ID, source, target); err != nil {
+			return 0, err
+		}
+	}
+	return len(chats), nil
+}
+
+// mergeReadPosition gives the target user the read position of the source user in the chat,
+// when it is further than their own
+func (s *AccountMergeService) mergeReadPosition(ctx context.Context, chatID string, source, target *models.User) error {
+	position, err := s.repos.ReadPositions.Get(ctx, chatID, source.UID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	position.UserID = target.UID
+	_, _, err = s.repos.ReadPositions.Advance(ctx, *position)
+	return err
+}
+
+// mergeGroupMessages rewrites the messages the source user sent or read, newest first
+func (s *AccountMergeService) mergeGroupMessages(ctx context.Context, chatID string, source, target *models.User) error {
+	page := repository.MessagePage{Limit: mergePageSize}
+	for {
+		messages, err := s.repos.GroupMessages.List(ctx, chatID, page)
+		if err != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			if _, read := message.ReadStatus[source.UID]; message.SenderID != source.UID && !read {
+				continue
+			}
+			if _, err := s.repos.GroupMessages.Update(ctx, chatID, message.ID, func(message *models.BaseMessage) error {
+				if message.SenderID == source.UID {
+					message.SenderID = target.UID
+					message.SenderName = target.Username
+				}
+				renameBoolKey(message.ReadStatus, source.UID, target.UID)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		if len(messages) < mergePageSize {
+			return nil
+		}
+		page.Before = messages[0].ID
+	}
+}
+
+// mergeConversations copies the direct conversations of the source user into the
+// conversations of the target user with the same people. The copied messages come after
+// the ones the target user already exchanged, and the old conversations are archived.
+func (s *AccountMergeService) mergeConversations(ctx context.Context, source, target *models.User) (int, error) {
+	conversations := []models.DirectConversation{}
+	for _, archived := range []bool{false, true} {
+		listed, err := s.repos.Conversations.ListByParticipant(ctx, source.UID, repository.ConversationPage{Archived: archived})
+		if err != nil {
+			return 0, err
+		}
+		conversations = append(conversations, listed...)
+	}
+
+	moved := 0
+	for _, conversation := range conversations {
+		other := ""
+		for _, participant := range conversation.Participants {
+			if participant != source.UID {
+				other = participant
+			}
+		}
+
+		if other != "" && other != target.UID {
+			if err := s.copyConversation(ctx, conversation, other, source, target); err != nil {
+				return 0, err
+			}
+			moved++
+		}
+
+		if _, err := s.repos.Conversations.Update(ctx, conversation.ID, func(conversation *models.DirectConversation) error {
+			if conversation.Archived == nil {
+				conversation.Archived = make(map[string]bool)
+			}
+			for _, participant := range conversation.Participants {
+				conversation.Archived[participant] = true
+			}
+			return nil
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return moved, nil
+}
+
+// copyConversation appends the messages of a conversation between the source user and
+// another user to the conversation between the target user and that user
+func (s *AccountMergeService) copyConversation(ctx context.Context, conversation models.DirectConversation, other string, source, target *models.User) error {
+	unread := map[string]int{}
+	existing, err := s.repos.Conversations.Get(ctx, repository.ConversationID(target.UID, other))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if existing != nil {
+		for uid, count := range existing.UnreadCount {
+			unread[uid] = count
+		}
+	}
+	unread[other] += conversation.UnreadCount[other]
+	unread[target.UID] += conversation.UnreadCount[source.UID]
+
+	// Pages come newest first, the messages are appended oldest first
+	history := [][]models.DirectMessage{}
+	page := repository.MessagePage{Limit: mergePageSize}
+	for {
+		messages, err := s.repos.Conversations.ListMessages(ctx, conversation.ID, page)
+		if err != nil {
+			return err
+		}
+		history = append(history, messages)
+		if len(messages) < mergePageSize {
+			break
+		}
+		page.Before = messages[0].ID
+	}
+
+	for i := len(history) - 1; i >= 0; i-- {
+		for _, message := range history[i] {
+			message := message
+			if message.SenderID == source.UID {
+				message.SenderID = target.UID
+				message.SenderName = target.Username
+			}
+			if message.ReceiverID == source.UID {
+				message.ReceiverID = target.UID
+				message.ReceiverName = target.Username
+			}
+			renameBoolKey(message.ReadStatus, source.UID, target.UID)
+			if _, err := s.repos.Conversations.AppendMessage(ctx, &message); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Appending counted every copied message as unread, the old counts are kept instead
+	_, err = s.repos.Conversations.Update(ctx, repository.ConversationID(target.UID, other), func(merged *models.DirectConversation) error {
+		merged.UnreadCount = unread
+		if conversation.Muted[source.UID] {
+			if merged.Muted == nil {
+				merged.Muted = make(map[string]bool)
+			}
+			merged.Muted[target.UID] = true
+		}
+		return nil
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		// The conversation had no messages, so nothing was copied
+		return nil
+	}
+	return err
+}
+
+// mergeNotifications moves the notifications of the source user and retargets the
+// notifications sent to them
+func (s *AccountMergeService) mergeNotifications(ctx context.Context, source, target *models.User) (int, error) {
+	owned, err := s.repos.Notifications.ListByUser(ctx, source.UID, "")
+	if err != nil {
+		return 0, err
+	}
+	targeted, err := s.repos.Notifications.ListTargeted(ctx, source.UID, 0, "")
+	if err != nil {
+		return 0, err
+	}
+
+	ids := map[string]bool{}
+	for _, notification := range append(owned, targeted...) {
+		if ids[notification.ID] {
+			continue
+		}
+		ids[notification.ID] = true
+
+		if _, err := s.repos.Notifications.Update(ctx, notification.ID, func(notification *models.Notification) error {
+			if notification.UserID == source.UID {
+				notification.UserID = target.UID
+			}
+			notification.TargetedUsers = replaceUID(notification.TargetedUsers, source.UID, target.UID)
+			return nil
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+// mergeIdentities links the provider accounts of the source user to the target user
+func (s *AccountMergeService) mergeIdentities(ctx context.Context, source, target *models.User) (int, error) {
+	identities, err := s.repos.Identities.ListByUser(ctx, source.UID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, identity := range identities {
+		identity.UID = target.UID
+		if err := s.repos.Identities.Put(ctx, &identity); err != nil {
+			return 0, err
+		}
+	}
+	return len(identities), nil
+}
+
+// mergeRoles grants the target user the roles the source user had
+func (s *AccountMergeService) mergeRoles(ctx context.Context, source, target *models.User) (int, error) {
+	granted := 0
+	for _, role := range source.Role {
+		if containsString(target.Role, role) {
+			continue
+		}
+		if _, err := s.authorization.GrantRole(ctx, target.UID, role); err != nil {
+			if errors.Is(err, ErrUnknownRole) {
+				continue
+			}
+			return 0, err
+		}
+		granted++
+	}
+	return granted, nil
+}
+
+// projectMentions reports whether the user appears anywhere in the project
+func projectMentions(project models.Project, uid string) bool {
+	if project.OwnerID == uid || containsString(project.RejectedParticipants, uid) {
+		return true
+	}
+	for _, participant := range project.Participants {
+		if participant.UserID == uid {
+			return true
+		}
+	}
+	for _, invited := range project.InvitedUsers {
+		if invited.UserID == uid {
+			return true
+		}
+	}
+	for _, request := range project.JoinRequests {
+		if request.UserID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeParticipants replaces the source user by the target user, unless the target already takes part
+func mergeParticipants(participants []models.Participant, source, target *models.User) []models.Participant {
+	merged := []models.Participant{}
+	seen := map[string]bool{}
+	for _, participant := range participants {
+		if participant.UserID == source.UID {
+			participant.UserID = target.UID
+			participant.Username = target.Username
+			participant.Email = target.Email
+		}
+		if seen[participant.UserID] {
+			continue
+		}
+		seen[participant.UserID] = true
+		merged = append(merged, participant)
+	}
+	return merged
+}
+
+func mergeInvitedUsers(invited []models.InvitedUser, source, target *models.User) []models.InvitedUser {
+	merged := []models.InvitedUser{}
+	seen := map[string]bool{}
+	for _, user := range invited {
+		if user.UserID == source.UID {
+			user.UserID = target.UID
+			user.Username = target.Username
+			user.Email = target.Email
+		}
+		if seen[user.UserID] {
+			continue
+		}
+		seen[user.UserID] = true
+		merged = append(merged, user)
+	}
+	return merged
+}
+
+func mergeJoinRequests(requests []models.JoinRequest, source, target *models.User) []models.JoinRequest {
+	merged := []models.JoinRequest{}
+	seen := map[string]bool{}
+	for _, request := range requests {
+		if request.UserID == source.UID {
+			request.UserID = target.UID
+			request.Username = target.Username
+			request.Email = target.Email
+		}
+		if seen[request.UserID] {
+			continue
+		}
+		seen[request.UserID] = true
+		merged = append(merged, request)
+	}
+	return merged
+}
+
+// replaceUID replaces oldUID by newUID in the list, keeping newUID once
+func replaceUID(uids []string, oldUID, newUID string) []string {
+	if !containsString(uids, oldUID) {
+		return uids
+	}
+	replaced := []string{}
+	for _, uid := range uids {
+		if uid == oldUID {
+			uid = newUID
+		}
+		if !containsString(replaced, uid) {
+			replaced = append(replaced, uid)
+		}
+	}
+	return replaced
+}
+
+// renameBoolKey moves the flag of oldKey to newKey, keeping true when either is set
+func renameBoolKey(flags map[string]bool, oldKey, newKey string) {
+	value, ok := flags[oldKey]
+	if !ok {
+		return
+	}
+	delete(flags, oldKey)
+	flags[newKey] = flags[newKey] || value
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConnectionMerger counts the connections it is asked to move
+type fakeConnectionMerger struct {
+	moved int
+}
+
+func (m *fakeConnectionMerger) MergeConnections(ctx context.Context, sourceUID, targetUID string) (int, error) {
+	return m.moved, nil
+}
+
+type accountMergeFixture struct {
+	service  *AccountMergeService
+	repos    *repository.Repositories
+	sessions *SessionService
+	source   *models.User
+	target   *models.User
+}
+
+func newTestAccountMergeService(t *testing.T) *accountMergeFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := repository.NewMemoryRepositories()
+	source := &models.User{UID: "source", Username: "old-ada", Email: "ada@old.example.com", Role: []string{RoleUser, RoleModerator}}
+	target := &models.User{UID: "target", Username: "ada", Email: "ada@example.com", Role: []string{RoleUser}}
+	for _, user := range []*models.User{source, target, {UID: "admin", Role: []string{RoleUser, RoleAdmin}}, {UID: "other"}} {
+		require.NoError(t, repos.Users.Create(ctx, user))
+	}
+
+	tokens := newTestTokenService(t)
+	sessions := NewSessionService(repos.Sessions, repos.Revocations, tokens, time.Hour)
+	authorization := NewAuthorizationService(repos.Users, repos.Projects, repos.Groups, repos.Forums, repos.GroupChats, repos.Sessions, nil)
+	accounts := NewLocalIdentityProvider(repos.Credentials, HashArgon2id, nil)
+	return &accountMergeFixture{
+		service:  NewAccountMergeService(repos, sessions, authorization, accounts, &fakeConnectionMerger{moved: 2}),
+		repos:    repos,
+		sessions: sessions,
+		source:   source,
+		target:   target,
+	}
+}
+
+func TestAccountMergeServiceRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		actorUID  string
+		sourceUID string
+		targetUID string
+		wantErr   error
+	}{
+		{"same user", "admin", "source", "source", ErrMergeSameUser},
+		{"own account", "source", "source", "target", ErrMergeOwnAccount},
+		{"unknown source", "admin", "missing", "target", repository.ErrNotFound},
+		{"unknown target", "admin", "source", "missing", repository.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestAccountMergeService(t)
+			_, err := f.service.Merge(context.Background(), tt.actorUID, tt.sourceUID, tt.targetUID)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			// Nothing was deleted
+			_, err = f.repos.Users.GetByUID(context.Background(), "source")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAccountMergeServiceMerge(t *testing.T) {
+	ctx := context.Background()
+	f := newTestAccountMergeService(t)
+	source, target := f.source, f.target
+
+	require.NoError(t, f.repos.Projects.Create(ctx, &models.Project{
+		ID:           "p1",
+		OwnerID:      source.UID,
+		Participants: []models.Participant{{UserID: source.UID, Role: "owner"}, {UserID: "other"}},
+	}))
+	require.NoError(t, f.repos.GroupChats.Create(ctx, &models.GroupChat{
+		ID:           "g1",
+		CreatedByUID: "other",
+		Participants: []models.Participant{{UserID: "other"}, {UserID: source.UID}},
+		ReadStatus:   map[string]bool{},
+	}))
+	require.NoError(t, f.repos.GroupMessages.Append(ctx, "g1", &models.BaseMessage{
+		ID: "m1", SenderID: source.UID, Content: "hello", ReadStatus: map[string]bool{source.UID: true},
+	}))
+	for i, sender := range []string{source.UID, "other", source.UID} {
+		receiver := "other"
+		if sender == "other" {
+			receiver = source.UID
+		}
+		_, err := f.repos.Conversations.AppendMessage(ctx, &models.DirectMessage{
+			BaseMessage: models.BaseMessage{ID: "d" + string(rune('1'+i)), SenderID: sender, Content: "hi", ReadStatus: map[string]bool{}},
+			ReceiverID:  receiver,
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.repos.Notifications.Create(ctx, &models.Notification{ID: "n1", UserID: source.UID}))
+	require.NoError(t, f.repos.Identities.Put(ctx, &models.Identity{ID: IdentityID("github", "42"), UID: source.UID, Provider: "github", Subject: "42"}))
+	session, err := f.sessions.CreateSession(ctx, source, "laptop", "127.0.0.1")
+	require.NoError(t, err)
+
+	merge, err := f.service.Merge(ctx, "admin", source.UID, target.UID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		"connections":   2,
+		"projects":      1,
+		"groupChats":    1,
+		"conversations": 1,
+		"notifications": 1,
+		"identities":    1,
+		"roles":         1,
+	}, merge.Moved)
+	assert.Equal(t, "admin", merge.MergedBy)
+
+	// The source account is gone and signed out
+	_, err = f.repos.Users.GetByUID(ctx, source.UID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = f.sessions.Refresh(ctx, session.RefreshToken, "laptop", "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	project, err := f.repos.Projects.Get(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, target.UID, project.OwnerID)
+	assert.Equal(t, target.UID, project.Participants[0].UserID)
+
+	chats, err := f.repos.GroupChats.ListByParticipant(ctx, target.UID)
+	require.NoError(t, err)
+	assert.Len(t, chats, 1)
+	message, err := f.repos.GroupMessages.Get(ctx, "g1", "m1")
+	require.NoError(t, err)
+	assert.Equal(t, target.UID, message.SenderID)
+	assert.Equal(t, map[string]bool{target.UID: true}, message.ReadStatus)
+
+	messages, err := f.repos.Conversations.ListMessages(ctx, repository.ConversationID(target.UID, "other"), repository.MessagePage{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+	assert.Equal(t, target.UID, messages[0].SenderID)
+	assert.Equal(t, target.UID, messages[1].ReceiverID)
+	old, err := f.repos.Conversations.Get(ctx, repository.ConversationID(source.UID, "other"))
+	require.NoError(t, err)
+	assert.True(t, old.Archived["other"])
+
+	notification, err := f.repos.Notifications.Get(ctx, "n1")
+	require.NoError(t, err)
+	assert.Equal(t, target.UID, notification.UserID)
+
+	identity, err := f.repos.Identities.Get(ctx, IdentityID("github", "42"))
+	require.NoError(t, err)
+	assert.Equal(t, target.UID, identity.UID)
+
+	merged, err := f.repos.Users.GetByUID(ctx, target.UID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{RoleUser, RoleModerator}, merged.Role)
+
+	merges, err := f.service.ListMerges(ctx, target.UID)
+	require.NoError(t, err)
+	require.Len(t, merges, 1)
+	assert.Equal(t, source.Email, merges[0].SourceEmail)
+}
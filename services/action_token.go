@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+const (
+	// actionTokenResendInterval is the minimum time between two mailed tokens of a purpose
+	actionTokenResendInterval = time.Minute
+	// maxActionTokensPerHour bounds the tokens of a purpose mailed to one user
+	maxActionTokensPerHour = 5
+)
+
+// errActionTokenInvalid is returned for unknown, expired and used action tokens.
+// Services replace it with the error of their purpose.
+var errActionTokenInvalid = errors.New("invalid action token")
+
+// ThrottledError is returned when an operation is repeated too often
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("too many requests, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// actionTokens issues and uses the signed, single-use tokens handed to the users
+type actionTokens struct {
+	repo    repository.ActionTokenRepository
+	signer  *TokenService
+	purpose string
+	ttl     time.Duration
+	// throttled limits how often tokens are issued, for tokens sent by email
+	throttled bool
+	// maxAttempts is how many times a token can be tried, when set
+	maxAttempts int
+}
+
+// issue saves a token record for the current email of the user and signs it.
+// Throttled tokens return a ThrottledError when the user asked for them too often.
+func (a *actionTokens) issue(ctx context.Context, user *models.User) (string, error) {
+	if a.throttled {
+		if err := a.throttle(ctx, user.UID); err != nil {
+			return "", err
+		}
+	}
+
+	now := time.Now()
+	record := models.ActionToken{
+		ID:        uuid.New().String(),
+		UID:       user.UID,
+		Email:     user.Email,
+		Purpose:   a.purpose,
+		CreatedAt: now,
+		ExpiresAt: now.Add(a.ttl),
+	}
+	if err := a.repo.Create(ctx, &record); err != nil {
+		return "", fmt.Errorf("failed to save token: %v", err)
+	}
+
+	return a.signer.IssueActionToken(record.ID, record.UID, record.Email, record.Purpose, record.ExpiresAt)
+}
+
+// throttle limits the tokens to one a minute and a few an hour
+func (a *actionTokens) throttle(ctx context.Context, uid string) error {
+	issued, err := a.repo.ListByUser(ctx, uid, a.purpose)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tokens: %v", err)
+	}
+
+	now := time.Now()
+	var latest, oldestInHour time.Time
+	inLastHour := 0
+	for _, token := range issued {
+		if token.CreatedAt.After(latest) {
+			latest = token.CreatedAt
+		}
+		if now.Sub(token.CreatedAt) < time.Hour {
+			inLastHour++
+			if oldestInHour.IsZero() || token.CreatedAt.Before(oldestInHour) {
+				oldestInHour = token.CreatedAt
+			}
+		}
+	}
+
+	if wait := actionTokenResendInterval - now.Sub(latest); wait > 0 {
+		return &ThrottledError{RetryAfter: wait}
+	}
+	if inLastHour >= maxActionTokensPerHour {
+		return &ThrottledError{RetryAfter: time.Hour - now.Sub(oldestInHour)}
+	}
+	return nil
+}
+
+// verify checks the signature, expiry and purpose of a token without using it
+func (a *actionTokens) verify(token string) (*ActionTokenClaims, error) {
+	claims, err := a.signer.VerifyActionToken(token, a.purpose)
+	if err != nil {
+		return nil, errActionTokenInvalid
+	}
+	return claims, nil
+}
+
+// use marks the record of a verified token as used. Only the first use succeeds.
+func (a *actionTokens) use(ctx context.Context, claims *ActionTokenClaims) error {
+	_, err := a.repo.Update(ctx, claims.ID, func(record *models.ActionToken) error {
+		if !a.usable(record, claims) {
+			return errActionTokenInvalid
+		}
+		now := time.Now()
+		record.UsedAt = &now
+		return nil
+	})
+	if errors.Is(err, repository.ErrNotFound) || errors.Is(err, errActionTokenInvalid) {
+		return errActionTokenInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("failed to use token: %v", err)
+	}
+	return nil
+}
+
+// attempt records an attempt to use a token, before its code is checked, so that
+// concurrent guesses count too. It returns errActionTokenInvalid once the token ran
+// out of attempts or was used.
+func (a *actionTokens) attempt(ctx context.Context, claims *ActionTokenClaims) error {
+	_, err := a.repo.Update(ctx, claims.ID, func(record *models.ActionToken) error {
+		if !a.usable(record, claims) || (a.maxAttempts > 0 && record.Attempts >= a.maxAttempts) {
+			return errActionTokenInvalid
+		}
+		record.Attempts++
+		return nil
+	})
+	if errors.Is(err, repository.ErrNotFound) || errors.Is(err, errActionTokenInvalid) {
+		return errActionTokenInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update token: %v", err)
+	}
+	return nil
+}
+
+// usable reports whether the record of a verified token can still be used
+func (a *actionTokens) usable(record *models.ActionToken, claims *ActionTokenClaims) bool {
+	return record.UsedAt == nil && record.UID == claims.UID && record.Purpose == a.purpose
+}
+
+// expireAll marks every unused token of the user as used
+func (a *actionTokens) expireAll(ctx context.Context, uid string) error {
+	issued, err := a.repo.ListByUser(ctx, uid, a.purpose)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tokens: %v", err)
+	}
+
+	now := time.Now()
+	for _, token := range issued {
+		if token.UsedAt != nil || now.After(token.ExpiresAt) {
+			continue
+		}
+		if _, err := a.repo.Update(ctx, token.ID, func(record *models.ActionToken) error {
+			if record.UsedAt == nil {
+				record.UsedAt = &now
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to expire token: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+// Outcomes of the audited operations
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	// AuditOutcomeDenied is an operation refused for lack of credentials, permission or attempts
+	AuditOutcomeDenied = "denied"
+)
+
+// Actions of the audit log
+const (
+	AuditLogin               = "auth.login"
+	AuditLoginTwoFactor      = "auth.login_2fa"
+	AuditRegister            = "auth.register"
+	AuditLogout              = "auth.logout"
+	AuditTokenRefresh        = "auth.token_refresh"
+	AuditSessionRevoke       = "auth.session_revoke"
+	AuditOtherSessionsRevoke = "auth.sessions_revoke"
+	AuditEmailVerify         = "auth.email_verify"
+	AuditPasswordReset       = "auth.password_reset"
+	AuditPasswordChange      = "auth.password_change"
+	AuditAccountUnlock       = "auth.account_unlock"
+	AuditOAuthCallback       = "auth.oauth_callback"
+	AuditIdentityLink        = "auth.identity_link"
+	AuditIdentityUnlink      = "auth.identity_unlink"
+	AuditTwoFactorSetup      = "auth.2fa_setup"
+	AuditTwoFactorEnable     = "auth.2fa_enable"
+	AuditTwoFactorDisable    = "auth.2fa_disable"
+	AuditRecoveryCodes       = "auth.2fa_recovery_codes"
+	AuditAccessTokenCreate   = "auth.access_token_create"
+	AuditAccessTokenRevoke   = "auth.access_token_revoke"
+	AuditRoleGrant           = "admin.role_grant"
+	AuditRoleRevoke          = "admin.role_revoke"
+	AuditTwoFactorReset      = "admin.2fa_reset"
+	AuditAccountMerge        = "admin.account_merge"
+	AuditLogExport           = "admin.audit_export"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+// AuditService writes and reads the append-only audit log
+type AuditService struct {
+	events repository.AuditEventRepository
+}
+
+func NewAuditService(events repository.AuditEventRepository) *AuditService {
+	return &AuditService{events: events}
+}
+
+// Record appends an event to the audit log
+func (s *AuditService) Record(ctx context.Context, event *models.AuditEvent) error {
+	event.ID = uuid.New().String()
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	if err := s.events.Append(ctx, event); err != nil {
+		return fmt.Errorf("failed to append audit event: %v", err)
+	}
+	return nil
+}
+
+// List returns one page of the events matching the query, newest first.
+// It returns repository.ErrNotFound when the After event does not exist.
+func (s *AuditService) List(ctx context.Context, query repository.AuditQuery) ([]models.AuditEvent, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultAuditPageSize
+	}
+	if query.Limit > maxAuditPageSize {
+		query.Limit = maxAuditPageSize
+	}
+
+	events, err := s.events.List(ctx, query)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audit events: %v", err)
+	}
+	return events, nil
+}
+
+// Export writes every event matching the query as newline-delimited JSON, newest first,
+// and returns how many were written. The limit of the query is ignored.
+func (s *AuditService) Export(ctx context.Context, query repository.AuditQuery, w io.Writer) (int, error) {
+	encoder := json.NewEncoder(w)
+	query.Limit = maxAuditPageSize
+
+	written := 0
+	for {
+		events, err := s.List(ctx, query)
+		if err != nil {
+			return written, err
+		}
+		for _, event := range events {
+			if err := encoder.Encode(event); err != nil {
+				return written, fmt.Errorf("failed to write audit event: %v", err)
+			}
+			written++
+		}
+		if len(events) < query.Limit {
+			return written, nil
+		}
+		query.After = events[len(events)-1].ID
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAuditService records the events a minute apart, the first one being the oldest
+func newTestAuditService(t *testing.T, events ...models.AuditEvent) *AuditService {
+	t.Helper()
+	service := NewAuditService(repository.NewMemoryAuditEventRepository())
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range events {
+		event := events[i]
+		event.CreatedAt = start.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, service.Record(context.Background(), &event))
+	}
+	return service
+}
+
+func auditActions(events []models.AuditEvent) []string {
+	actions := make([]string, len(events))
+	for i, event := range events {
+		actions[i] = event.Action
+	}
+	return actions
+}
+
+func TestAuditServiceList(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := newTestAuditService(t,
+		models.AuditEvent{ActorUID: "user-1", Action: AuditLogin, Outcome: AuditOutcomeSuccess},
+		models.AuditEvent{ActorUID: "user-2", Action: AuditLogin, Outcome: AuditOutcomeDenied},
+		models.AuditEvent{ActorUID: "admin", Action: AuditRoleGrant, TargetID: "user-1", Outcome: AuditOutcomeSuccess},
+		models.AuditEvent{ActorUID: "user-1", Action: AuditLogout, Outcome: AuditOutcomeSuccess},
+	)
+
+	tests := []struct {
+		name  string
+		query repository.AuditQuery
+		want  []string
+	}{
+		{name: "everything, newest first", want: []string{AuditLogout, AuditRoleGrant, AuditLogin, AuditLogin}},
+		{name: "by actor", query: repository.AuditQuery{ActorUID: "user-1"}, want: []string{AuditLogout, AuditLogin}},
+		{name: "by action", query: repository.AuditQuery{Action: AuditRoleGrant}, want: []string{AuditRoleGrant}},
+		{name: "by target", query: repository.AuditQuery{TargetID: "user-1"}, want: []string{AuditRoleGrant}},
+		{name: "by outcome", query: repository.AuditQuery{Outcome: AuditOutcomeDenied}, want: []string{AuditLogin}},
+		{
+			name:  "time range, until excluded",
+			query: repository.AuditQuery{Since: start.Add(time.Minute), Until: start.Add(3 * time.Minute)},
+			want:  []string{AuditRoleGrant, AuditLogin},
+		},
+		{name: "first page", query: repository.AuditQuery{Limit: 2}, want: []string{AuditLogout, AuditRoleGrant}},
+		{name: "nothing matching", query: repository.AuditQuery{ActorUID: "nobody"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := service.List(context.Background(), tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, auditActions(events))
+		})
+	}
+}
+
+func TestAuditServiceListPages(t *testing.T) {
+	ctx := context.Background()
+	service := newTestAuditService(t,
+		models.AuditEvent{Action: "a"}, models.AuditEvent{Action: "b"}, models.AuditEvent{Action: "c"},
+	)
+
+	first, err := service.List(ctx, repository.AuditQuery{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "b"}, auditActions(first))
+	second, err := service.List(ctx, repository.AuditQuery{Limit: 2, After: first[1].ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, auditActions(second))
+
+	_, err = service.List(ctx, repository.AuditQuery{After: "missing"})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestAuditServiceExport(t *testing.T) {
+	tests := []struct {
+		name   string
+		events int
+		query  repository.AuditQuery
+		want   int
+	}{
+		{name: "empty log", events: 0, want: 0},
+		{name: "one page", events: 3, want: 3},
+		{name: "exactly one full page", events: maxAuditPageSize, want: maxAuditPageSize},
+		{name: "several pages, whatever the limit", events: maxAuditPageSize + 1, query: repository.AuditQuery{Limit: 1}, want: maxAuditPageSize + 1},
+		{name: "filtered", events: 3, query: repository.AuditQuery{Action: "none"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestAuditService(t, make([]models.AuditEvent, tt.events)...)
+
+			var out bytes.Buffer
+			written, err := service.Export(context.Background(), tt.query, &out)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, written)
+
+			// Every event is written once, newest first
+			seen := map[string]bool{}
+			var previous time.Time
+			scanner := bufio.NewScanner(&out)
+			for scanner.Scan() {
+				var event models.AuditEvent
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+				assert.False(t, seen[event.ID])
+				seen[event.ID] = true
+				if !previous.IsZero() {
+					assert.True(t, event.CreatedAt.Before(previous))
+				}
+				previous = event.CreatedAt
+			}
+			assert.Len(t, seen, tt.want)
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+// Permission is an operation that a role allows
+type Permission string
+
+const (
+	PermissionManageFAQs       Permission = "faqs:manage"
+	PermissionManageContacts   Permission = "contacts:manage"
+	PermissionManageNewsletter Permission = "newsletter:manage"
+	PermissionManageRoles      Permission = "roles:manage"
+	// PermissionManageUsers lets staff merge duplicate user accounts
+	PermissionManageUsers Permission = "users:manage"
+	// PermissionViewAuditLog lets staff read and export the security audit log
+	PermissionViewAuditLog Permission = "audit:read"
+	// PermissionModerateContent lets staff act on any project, group or forum as if they owned it
+	PermissionModerateContent Permission = "content:moderate"
+)
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// rolePermissions is the permission model: what every role allows
+var rolePermissions = map[string][]Permission{
+	RoleUser: {},
+	RoleModerator: {
+		PermissionModerateContent,
+		PermissionManageFAQs,
+	},
+	RoleAdmin: {
+		PermissionModerateContent,
+		PermissionManageFAQs,
+		PermissionManageContacts,
+		PermissionManageNewsletter,
+		PermissionManageRoles,
+		PermissionManageUsers,
+		PermissionViewAuditLog,
+	},
+}
+
+var (
+	// ErrForbidden is returned when the user lacks the permission for an operation
+	ErrForbidden = errors.New("you do not have permission to perform this action")
+	// ErrUnknownRole is returned when granting a role that is not in the permission model
+	ErrUnknownRole = errors.New("unknown role")
+	// ErrBaseRole is returned when revoking the role every user has
+	ErrBaseRole = errors.New("the user role cannot be revoked")
+	// ErrSelfRevocation is returned when admins revoke their own admin role
+	ErrSelfRevocation = errors.New("admins cannot revoke their own admin role")
+)
+
+// AuthorizationService decides what users may do, from their roles and from their
+// relation to a resource (project owner, group admin, forum moderator)
+type AuthorizationService struct {
+	users      repository.UserRepository
+	projects   repository.ProjectRepository
+	groups     repository.GroupRepository
+	forums     repository.ForumRepository
+	groupChats repository.GroupChatRepository
+	sessions   repository.SessionRepository
+	// twoFactor, when set, withholds the permissions of roles that require a second factor
+	twoFactor *TwoFactorService
+}
+
+func NewAuthorizationService(users repository.UserRepository, projects repository.ProjectRepository, groups repository.GroupRepository, forums repository.ForumRepository, groupChats repository.GroupChatRepository, sessions repository.SessionRepository, twoFactor *TwoFactorService) *AuthorizationService {
+	return &AuthorizationService{
+		users:      users,
+		projects:   projects,
+		groups:     groups,
+		forums:     forums,
+		groupChats: groupChats,
+		sessions:   sessions,
+		twoFactor:  twoFactor,
+	}
+}
+
+// Roles lists the roles known to the permission model
+func Roles() []string {
+	roles := make([]string, 0, len(rolePermissions))
+	for role := range rolePermissions {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// RolesAllow reports whether any of the roles grants the permission
+func RolesAllow(roles []string, permission Permission) bool {
+	for _, role := range roles {
+		for _, granted := range rolePermissions[role] {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetRoles returns the current roles of the user. Roles in access tokens can be
+// up to one token lifetime old, so permission checks read them from the user.
+func (s *AuthorizationService) GetRoles(ctx context.Context, uid string) ([]string, error) {
+	user, err := s.users.GetByUID(ctx, uid)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %v", err)
+	}
+	return user.Role, nil
+}
+
+// HasPermission reports whether the roles of the user grant the permission. It returns
+// ErrTwoFactorRequired when the roles require a second factor the user did not enable.
+func (s *AuthorizationService) HasPermission(ctx context.Context, uid string, permission Permission) (bool, error) {
+	roles, err := s.GetRoles(ctx, uid)
+	if err != nil {
+		return false, err
+	}
+	if !RolesAllow(roles, permission) {
+		return false, nil
+	}
+
+	if s.twoFactor != nil && s.twoFactor.RequiredFor(roles) {
+		enabled, err := s.twoFactor.IsEnabled(ctx, uid)
+		if err != nil {
+			return false, err
+		}
+		if !enabled {
+			return false, ErrTwoFactorRequired
+		}
+	}
+	return true, nil
+}
+
+// The resource checks below return repository.ErrNotFound for unknown resources.
+
+// CanManageProject reports whether the user owns the project, directly or as an
+// invited participant with the "owner" role
+func (s *AuthorizationService) CanManageProject(ctx context.Context, uid, projectID string) (bool, error) {
+	project, err := s.projects.Get(ctx, projectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch project: %v", err)
+	}
+
+	if project.OwnerID == uid {
+		return true, nil
+	}
+	for _, participant := range project.Participants {
+		if participant.UserID == uid && participant.Role == "owner" {
+			return true, nil
+		}
+	}
+	return s.HasPermission(ctx, uid, PermissionModerateContent)
+}
+
+// CanManageGroup reports whether the user is an admin of the group
+func (s *AuthorizationService) CanManageGroup(ctx context.Context, uid, groupID string) (bool, error) {
+	group, err := s.groups.Get(ctx, groupID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch group: %v", err)
+	}
+
+	if containsUser(group.Admins, uid) {
+		return true, nil
+	}
+	return s.HasPermission(ctx, uid, PermissionModerateContent)
+}
+
+// CanModerateForum reports whether the user moderates the forum or administers its group
+func (s *AuthorizationService) CanModerateForum(ctx context.Context, uid, forumID string) (bool, error) {
+	forum, err := s.forums.Get(ctx, forumID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch forum: %v", err)
+	}
+
+	if containsUser(forum.Moderators, uid) {
+		return true, nil
+	}
+	if forum.GroupID != "" {
+		if group, err := s.groups.Get(ctx, forum.GroupID); err == nil && containsUser(group.Admins, uid) {
+			return true, nil
+		}
+	}
+	return s.HasPermission(ctx, uid, PermissionModerateContent)
+}
+
+func containsUser(users []*models.User, uid string) bool {
+	for _, user := range users {
+		if user != nil && user.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthorizeChannel returns ErrChannelForbidden unless the user may subscribe to the realtime
+// channel: their own channels, their direct conversations, the group chats they take part in
+// and the projects they own or joined. The presence of every user is public to the signed-in
+// users; hidden users only ever appear offline on it. It satisfies ChannelAuthorizer.
+func (s *AuthorizationService) AuthorizeChannel(ctx context.Context, uid, channel string) error {
+	if containsString(UserChannels(uid), channel) || strings.HasPrefix(channel, presenceChannelPrefix) {
+		return nil
+	}
+
+	if conversationID, ok := strings.CutPrefix(channel, directMessagesChannelPrefix); ok {
+		if !isConversationOf(conversationID, uid) {
+			return ErrChannelForbidden
+		}
+		return nil
+	}
+
+	if chatID, ok := strings.CutPrefix(channel, groupChatChannelPrefix); ok {
+		chat, err := s.groupChats.Get(ctx, chatID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrChannelForbidden
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch group chat: %v", err)
+		}
+		for _, participant := range chat.Participants {
+			if participant.UserID == uid {
+				return nil
+			}
+		}
+		return ErrChannelForbidden
+	}
+
+	if projectID, ok := strings.CutPrefix(channel, projectChannelPrefix); ok {
+		project, err := s.projects.Get(ctx, projectID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrChannelForbidden
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch project: %v", err)
+		}
+		if project.OwnerID == uid {
+			return nil
+		}
+		for _, participant := range project.Participants {
+			if participant.UserID == uid {
+				return nil
+			}
+		}
+		return ErrChannelForbidden
+	}
+
+	return ErrChannelForbidden
+}
+
+// isConversationOf reports whether the direct conversation is one of the user's. The ID of a
+// conversation starts or ends with the ID of each of its two users.
+func isConversationOf(conversationID, uid string) bool {
+	other, ok := strings.CutPrefix(conversationID, uid+"-")
+	if !ok {
+		other, ok = strings.CutSuffix(conversationID, "-"+uid)
+	}
+	return ok && other != "" && ConversationID(uid, other) == conversationID
+}
+
+// GrantRole adds a role to the user
+func (s *AuthorizationService) GrantRole(ctx context.Context, uid, role string) ([]string, error) {
+	if _, ok := rolePermissions[role]; !ok {
+		return nil, ErrUnknownRole
+	}
+
+	roles, err := s.GetRoles(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range roles {
+		if existing == role {
+			return roles, nil
+		}
+	}
+
+	return s.setRoles(ctx, uid, append(roles, role))
+}
+
+// RevokeRole removes a role from the user. Every user keeps the "user" role, and
+// admins cannot revoke their own admin role so that the platform keeps an admin.
+func (s *AuthorizationService) RevokeRole(ctx context.Context, actorUID, uid, role string) ([]string, error) {
+	if _, ok := rolePermissions[role]; !ok {
+		return nil, ErrUnknownRole
+	}
+	if role == RoleUser {
+		return nil, ErrBaseRole
+	}
+	if role == RoleAdmin && actorUID == uid {
+		return nil, ErrSelfRevocation
+	}
+
+	roles, err := s.GetRoles(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := []string{}
+	for _, existing := range roles {
+		if existing != role {
+			remaining = append(remaining, existing)
+		}
+	}
+	if len(remaining) == len(roles) {
+		return roles, nil
+	}
+
+	return s.setRoles(ctx, uid, remaining)
+}
+
+// setRoles saves the roles of the user and copies them to the user's sessions,
+// so that the next refreshed access tokens carry them
+func (s *AuthorizationService) setRoles(ctx context.Context, uid string, roles []string) ([]string, error) {
+	if err := s.users.Update(ctx, uid, map[string]interface{}{"role": roles}); err != nil {
+		return nil, fmt.Errorf("failed to update user roles: %v", err)
+	}
+
+	sessions, err := s.sessions.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %v", err)
+	}
+	for _, session := range sessions {
+		if session.RevokedAt != nil {
+			continue
+		}
+		if _, err := s.sessions.Update(ctx, session.ID, func(session *models.Session) error {
+			session.Roles = roles
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("failed to update session roles: %v", err)
+		}
+	}
+
+	return roles, nil
+}
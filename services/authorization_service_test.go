@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type authorizationFixture struct {
+	service   *AuthorizationService
+	twoFactor *TwoFactorService
+	repos     *repository.Repositories
+	users     map[string]*models.User
+}
+
+// newTestAuthorizationService stores a user per role and, when twoFactorRoles is set,
+// withholds the permissions of those roles until the user enables a second factor
+func newTestAuthorizationService(t *testing.T, twoFactorRoles []string) *authorizationFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := repository.NewMemoryRepositories()
+
+	users := map[string]*models.User{
+		RoleUser:      {UID: "member", Email: "member@example.com", Role: []string{RoleUser}},
+		RoleModerator: {UID: "moderator", Email: "moderator@example.com", Role: []string{RoleUser, RoleModerator}},
+		RoleAdmin:     {UID: "admin", Email: "admin@example.com", Role: []string{RoleUser, RoleAdmin}},
+		"outsider":    {UID: "outsider", Email: "outsider@example.com", Role: []string{RoleUser}},
+	}
+	for _, user := range users {
+		require.NoError(t, repos.Users.Create(ctx, user))
+	}
+
+	var twoFactor *TwoFactorService
+	if twoFactorRoles != nil {
+		twoFactor = NewTwoFactorService(repos.TwoFactor, repos.Users, repos.ActionTokens, newTestTokenService(t), twoFactorRoles)
+	}
+
+	return &authorizationFixture{
+		service:   NewAuthorizationService(repos.Users, repos.Projects, repos.Groups, repos.Forums, repos.GroupChats, repos.Sessions, twoFactor),
+		twoFactor: twoFactor,
+		repos:     repos,
+		users:     users,
+	}
+}
+
+func TestAuthorizationServiceHasPermission(t *testing.T) {
+	permissions := []Permission{
+		PermissionManageFAQs,
+		PermissionManageContacts,
+		PermissionManageNewsletter,
+		PermissionManageRoles,
+		PermissionManageUsers,
+		PermissionViewAuditLog,
+		PermissionModerateContent,
+	}
+
+	// granted is the expected permission model, written out so that a change to
+	// rolePermissions has to be made here too
+	granted := map[string][]Permission{
+		RoleUser:      {},
+		RoleModerator: {PermissionModerateContent, PermissionManageFAQs},
+		RoleAdmin:     permissions,
+	}
+
+	fixture := newTestAuthorizationService(t, nil)
+	ctx := context.Background()
+
+	for _, role := range Roles() {
+		for _, permission := range permissions {
+			want := false
+			for _, p := range granted[role] {
+				if p == permission {
+					want = true
+				}
+			}
+
+			t.Run(role+"/"+string(permission), func(t *testing.T) {
+				allowed, err := fixture.service.HasPermission(ctx, fixture.users[role].UID, permission)
+				require.NoError(t, err)
+				assert.Equal(t, want, allowed)
+				assert.Equal(t, want, RolesAllow([]string{role}, permission))
+			})
+		}
+	}
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := fixture.service.HasPermission(ctx, "nobody", PermissionManageFAQs)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+func TestAuthorizationServiceRequiresTwoFactorForAdmins(t *testing.T) {
+	ctx := context.Background()
+	fixture := newTestAuthorizationService(t, []string{RoleAdmin})
+	admin := fixture.users[RoleAdmin]
+
+	tests := []struct {
+		name       string
+		uid        string
+		permission Permission
+		want       bool
+		wantErr    error
+	}{
+		{name: "admin permission", uid: admin.UID, permission: PermissionManageRoles, wantErr: ErrTwoFactorRequired},
+		{name: "shared permission", uid: admin.UID, permission: PermissionModerateContent, wantErr: ErrTwoFactorRequired},
+		{name: "moderators are not required to", uid: fixture.users[RoleModerator].UID, permission: PermissionModerateContent, want: true},
+		{name: "missing permissions are denied first", uid: fixture.users[RoleUser].UID, permission: PermissionManageRoles},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := fixture.service.HasPermission(ctx, tt.uid, tt.permission)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, allowed)
+		})
+	}
+
+	// Resource checks fall back to the role permissions, so they are withheld too
+	project := &models.Project{OwnerID: "member"}
+	require.NoError(t, fixture.repos.Projects.Create(ctx, project))
+	_, err := fixture.service.CanManageProject(ctx, admin.UID, project.ID)
+	assert.ErrorIs(t, err, ErrTwoFactorRequired)
+
+	enrollTwoFactor(t, fixture.twoFactor, admin)
+
+	allowed, err := fixture.service.HasPermission(ctx, admin.UID, PermissionManageRoles)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = fixture.service.CanManageProject(ctx, admin.UID, project.ID)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestAuthorizationServiceResourceChecks(t *testing.T) {
+	ctx := context.Background()
+	fixture := newTestAuthorizationService(t, nil)
+	member := fixture.users[RoleUser]
+
+	// Resource roles are held by plain users, the role fallback needs them to exist
+	for _, uid := range []string{"owner", "co-owner", "group-admin", "forum-moderator"} {
+		require.NoError(t, fixture.repos.Users.Create(ctx, &models.User{UID: uid, Email: uid + "@example.com", Role: []string{RoleUser}}))
+	}
+
+	project := &models.Project{
+		OwnerID: "owner",
+		Participants: []models.Participant{
+			{UserID: "co-owner", Role: "owner"},
+			{UserID: member.UID, Role: "collaborator"},
+		},
+	}
+	require.NoError(t, fixture.repos.Projects.Create(ctx, project))
+
+	group := &models.Group{ID: "group-1", Name: "Gophers", Admins: []*models.User{{UID: "group-admin"}}}
+	require.NoError(t, fixture.repos.Groups.Save(ctx, group))
+
+	forum := &models.Forum{ID: "forum-1", GroupID: group.ID, Name: "General", Moderators: []*models.User{{UID: "forum-moderator"}}}
+	require.NoError(t, fixture.repos.Forums.Save(ctx, forum))
+
+	checks := map[string]func(uid, id string) (bool, error){
+		"project": func(uid, id string) (bool, error) { return fixture.service.CanManageProject(ctx, uid, id) },
+		"group":   func(uid, id string) (bool, error) { return fixture.service.CanManageGroup(ctx, uid, id) },
+		"forum":   func(uid, id string) (bool, error) { return fixture.service.CanModerateForum(ctx, uid, id) },
+	}
+
+	tests := []struct {
+		name     string
+		resource string
+		id       string
+		uid      string
+		want     bool
+		wantErr  error
+	}{
+		{name: "project owner", resource: "project", id: project.ID, uid: "owner", want: true},
+		{name: "project participant with the owner role", resource: "project", id: project.ID, uid: "co-owner", want: true},
+		{name: "project collaborator", resource: "project", id: project.ID, uid: member.UID},
+		{name: "project outsider", resource: "project", id: project.ID, uid: "outsider"},
+		{name: "project moderator", resource: "project", id: project.ID, uid: "moderator", want: true},
+		{name: "unknown project", resource: "project", id: "missing", uid: "owner", wantErr: repository.ErrNotFound},
+
+		{name: "group admin", resource: "group", id: group.ID, uid: "group-admin", want: true},
+		{name: "group outsider", resource: "group", id: group.ID, uid: "outsider"},
+		{name: "group moderator", resource: "group", id: group.ID, uid: "moderator", want: true},
+		{name: "unknown group", resource: "group", id: "missing", uid: "group-admin", wantErr: repository.ErrNotFound},
+
+		{name: "forum moderator", resource: "forum", id: forum.ID, uid: "forum-moderator", want: true},
+		{name: "admin of the forum group", resource: "forum", id: forum.ID, uid: "group-admin", want: true},
+		{name: "forum outsider", resource: "forum", id: forum.ID, uid: "outsider"},
+		{name: "site admin", resource: "forum", id: forum.ID, uid: "admin", want: true},
+		{name: "unknown forum", resource: "forum", id: "missing", uid: "forum-moderator", wantErr: repository.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := checks[tt.resource](tt.uid, tt.id)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, allowed)
+		})
+	}
+}
@@ -2,30 +2,28 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/google/uuid"
 	"github.com/rogerjeasy/go-letusconnect/config"
-	"github.com/rogerjeasy/go-letusconnect/mappers"
 	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 	openai "github.com/sashabaranov/go-openai"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 type ChatGPTService struct {
-	client          *openai.Client
-	firestoreClient FirestoreClient
-	pdfService      *PDFService
+	client        *openai.Client
+	conversations repository.ChatGPTConversationRepository
+	pdfService    *PDFService
 }
 
-func NewChatGPTService(firestoreClient FirestoreClient, pdfService *PDFService) *ChatGPTService {
+func NewChatGPTService(conversations repository.ChatGPTConversationRepository, pdfService *PDFService) *ChatGPTService {
 	return &ChatGPTService{
-		client:          openai.NewClient(config.OpenAIKey),
-		firestoreClient: firestoreClient,
-		pdfService:      pdfService,
+		client:        openai.NewClient(config.OpenAIKey),
+		conversations: conversations,
+		pdfService:    pdfService,
 	}
 }
 
@@ -67,12 +65,9 @@ func (s *ChatGPTService) GenerateResponse(ctx context.Context, prompt string, us
 		Role:      "user",
 	}
 
-	var conversation *models.Conversation
-	var docRef *firestore.DocumentRef
-
 	// If conversationID is empty, create new conversation
 	if conversationID == "" {
-		conversation = &models.Conversation{
+		conversation := &models.Conversation{
 			ID:        uuid.New().String(),
 			UserID:    userID,
 			Title:     prompt[:min(30, len(prompt))] + "...", // Create title from first 30 chars
@@ -81,89 +76,55 @@ func (s *ChatGPTService) GenerateResponse(ctx context.Context, prompt string, us
 			Messages:  []models.MessageConversation{newMessage},
 		}
 
-		// Convert to Firestore format and save
-		firestoreData := mappers.MapConversationGoToFirestore(*conversation)
-		docRef = s.firestoreClient.Collection("chatgpt_conversations").Doc(conversation.ID)
-
-		_, err = docRef.Set(ctx, firestoreData)
-		if err != nil {
+		if err := s.conversations.Create(ctx, conversation); err != nil {
 			return nil, fmt.Errorf("failed to create conversation: %v", err)
 		}
-	} else {
-		// Get existing conversation
-		docRef = s.firestoreClient.Collection("chatgpt_conversations").Doc(conversationID)
-		doc, err := docRef.Get(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get conversation: %v", err)
-		}
-
-		// Convert Firestore data to Go struct
-		firestoreData := doc.Data()
-		existingConversation := mappers.MapConversationFirestoreToGo(firestoreData)
-
-		// Update conversation with new message
-		existingConversation.Messages = append(existingConversation.Messages, newMessage)
-		existingConversation.UpdatedAt = now
-
-		// Convert back to Firestore format and update
-		updatedData := mappers.MapConversationGoToFirestore(existingConversation)
-		_, err = docRef.Set(ctx, updatedData)
-		if err != nil {
-			return nil, fmt.Errorf("failed to update conversation: %v", err)
-		}
+		return conversation, nil
+	}
 
-		conversation = &existingConversation
+	// Update the existing conversation with the new message
+	conversation, err := s.conversations.Update(ctx, conversationID, func(conversation *models.Conversation) error {
+		conversation.Messages = append(conversation.Messages, newMessage)
+		conversation.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update conversation: %v", err)
 	}
 
 	return conversation, nil
 }
 
 func (s *ChatGPTService) GetUserConversations(ctx context.Context, userID string) ([]models.Conversation, error) {
-	iter := s.firestoreClient.Collection("chatgpt_conversations").
-		Where("user_id", "==", userID).
-		Documents(ctx)
-
-	var conversations []models.Conversation
-	for {
-		doc, err := iter.Next()
-		if err != nil {
-			break
-		}
-		conversation := mappers.MapConversationFirestoreToGo(doc.Data())
-		conversations = append(conversations, conversation)
+	conversations, err := s.conversations.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversations: %v", err)
 	}
-
 	return conversations, nil
 }
 
 func (s *ChatGPTService) GetConversation(ctx context.Context, conversationID string) (*models.Conversation, error) {
-	doc, err := s.firestoreClient.Collection("chatgpt_conversations").Doc(conversationID).Get(ctx)
+	conversation, err := s.conversations.Get(ctx, conversationID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get conversation: %v", err)
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
-
-	conversation := mappers.MapConversationFirestoreToGo(doc.Data())
-	return &conversation, nil
+	return conversation, nil
 }
 
 func (s *ChatGPTService) DeleteConversation(ctx context.Context, conversationID string, userID string) error {
-	docRef := s.firestoreClient.Collection("chatgpt_conversations").Doc(conversationID)
-	doc, err := docRef.Get(ctx)
+	conversation, err := s.conversations.Get(ctx, conversationID)
 	if err != nil {
-		if status.Code(err) == codes.NotFound {
+		if errors.Is(err, repository.ErrNotFound) {
 			return fmt.Errorf("conversation not found")
 		}
 		return fmt.Errorf("failed to get conversation: %v", err)
 	}
 
-	conversation := mappers.MapConversationFirestoreToGo(doc.Data())
-
 	if conversation.UserID != userID {
 		return fmt.Errorf("unauthorized: user does not own this conversation")
 	}
 
-	_, err = docRef.Delete(ctx)
-	if err != nil {
+	if err := s.conversations.Delete(ctx, conversationID); err != nil {
 		return fmt.Errorf("failed to delete conversation: %v", err)
 	}
 
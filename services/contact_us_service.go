@@ -2,18 +2,66 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 type ContactUsService struct {
-	firestoreClient FirestoreClient
+	contacts repository.ContactRepository
 }
 
-func NewContactUsService(client FirestoreClient) *ContactUsService {
+func NewContactUsService(contacts repository.ContactRepository) *ContactUsService {
 	return &ContactUsService{
-		firestoreClient: client,
+		contacts: contacts,
+	}
+}
+
+// CreateContactUs stores a contact form submission
+func (s *ContactUsService) CreateContactUs(ctx context.Context, contact *models.ContactUs) error {
+	contact.ID = uuid.New().String()
+	if err := s.contacts.Create(ctx, contact); err != nil {
+		return fmt.Errorf("failed to create contact: %w", err)
 	}
+	return nil
 }
 
-func (s *ContactUsService) CreateContactUs(ctx context.Context) {
-	// do nothing
+// GetContactUs retrieves a contact form submission by ID
+func (s *ContactUsService) GetContactUs(ctx context.Context, contactID string) (*models.ContactUs, error) {
+	contact, err := s.contacts.Get(ctx, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contact: %w", err)
+	}
+	return contact, nil
+}
+
+// ListContactUs lists every contact form submission
+func (s *ContactUsService) ListContactUs(ctx context.Context) ([]models.ContactUs, error) {
+	contacts, err := s.contacts.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contacts: %w", err)
+	}
+	return contacts, nil
+}
+
+// UpdateContactStatus records the status of a submission and, when given, who replied and how
+func (s *ContactUsService) UpdateContactStatus(ctx context.Context, contactID, status, repliedBy, replyMessage string) (*models.ContactUs, error) {
+	contact, err := s.contacts.Update(ctx, contactID, func(contact *models.ContactUs) error {
+		contact.Status = status
+		contact.UpdatedAt = time.Now()
+		if repliedBy != "" {
+			contact.RepliedBy = repliedBy
+		}
+		if replyMessage != "" {
+			contact.ReplyMessage = replyMessage
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update contact status: %w", err)
+	}
+	return contact, nil
 }
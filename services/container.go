@@ -1,30 +1,45 @@
 package services
 
 import (
+	"log"
+
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/rogerjeasy/go-letusconnect/config"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 	"github.com/rogerjeasy/go-letusconnect/services/sms"
 )
 
 type ServiceContainer struct {
-	UserService           *UserService
-	ConnectionService     *UserConnectionService
-	NotificationService   *NotificationService
-	MessageService        *MessageService
-	GroupChatService      *GroupChatService
-	AuthService           *AuthService
-	FAQService            *FAQService
-	ProjectCoreService    *ProjectCoreService
-	ProjectService        *ProjectService
-	UserConnectionService *UserConnectionService
-	AddressService        *AddressService
-	NewsletterService     *NewsletterService
-	ContactUsService      *ContactUsService
-	ChatGPTService        *ChatGPTService
-	PDFService            *PDFService
-	UploadPDFService      *UploadPDFService
-	// WebSocketService      *WebSocketService
-	WebSocketService             *WebSocketService
+	UserService                  *UserService
+	ConnectionService            *UserConnectionService
+	NotificationService          *NotificationService
+	GroupChatService             *GroupChatService
+	ConversationService          *ConversationService
+	AuthService                  *AuthService
+	SessionService               *SessionService
+	AuthorizationService         *AuthorizationService
+	EmailVerificationService     *EmailVerificationService
+	PasswordService              *PasswordService
+	IdentityProvider             IdentityProvider
+	OAuthService                 *OAuthService
+	TwoFactorService             *TwoFactorService
+	AccountMergeService          *AccountMergeService
+	PersonalAccessTokenService   *PersonalAccessTokenService
+	LoginProtectionService       *LoginProtectionService
+	AuditService                 *AuditService
+	PresenceService              *PresenceService
+	EventStream                  *EventStreamTransport
+	TypingService                *TypingService
+	FAQService                   *FAQService
+	ProjectCoreService           *ProjectCoreService
+	ProjectService               *ProjectService
+	UserConnectionService        *UserConnectionService
+	AddressService               *AddressService
+	NewsletterService            *NewsletterService
+	ContactUsService             *ContactUsService
+	ChatGPTService               *ChatGPTService
+	PDFService                   *PDFService
+	UploadPDFService             *UploadPDFService
 	UserSchoolExperienceService  *UserSchoolExperienceService
 	GroupService                 *GroupService
 	ForumService                 *ForumService
@@ -37,9 +52,9 @@ type ServiceContainer struct {
 	// Add other services as needed
 }
 
-func NewServiceContainer(firestoreClient FirestoreClient, userSerrvice *UserService, cloudinary *cloudinary.Cloudinary) *ServiceContainer {
-	pdfService := NewPDFService(firestoreClient, config.PDFContextURL)
-	uploadPdfService, _ := NewUploadPDFService(firestoreClient, config.CloudinaryURL)
+func NewServiceContainer(repos *repository.Repositories, userSerrvice *UserService, cloudinary *cloudinary.Cloudinary) *ServiceContainer {
+	pdfService := NewPDFService(config.PDFContextURL)
+	uploadPdfService, _ := NewUploadPDFService(repos.Uploads, config.CloudinaryURL)
 
 	// Initialize SMS service
 	smsService := sms.NewSMSService(sms.Config{
@@ -49,39 +64,79 @@ func NewServiceContainer(firestoreClient FirestoreClient, userSerrvice *UserServ
 	})
 
 	// Initialize notification scheduler
-	notificationScheduler := NewNotificationScheduler(firestoreClient, smsService)
+	notificationScheduler := NewNotificationScheduler(repos.Notifications, smsService)
+
+	sessionService := NewSessionService(repos.Sessions, repos.Revocations, Tokens, config.JWTRefreshTokenTTL)
+	identityProvider := newIdentityProvider(repos)
+	oauthProviders, err := OAuthProvidersFromConfig()
+	if err != nil {
+		log.Printf("OAuth providers disabled: %v", err)
+	}
+	twoFactorService := NewTwoFactorService(repos.TwoFactor, repos.Users, repos.ActionTokens, Tokens, config.TwoFactorRequiredRoles)
+	authorizationService := NewAuthorizationService(repos.Users, repos.Projects, repos.Groups, repos.Forums, repos.GroupChats, repos.Sessions, twoFactorService)
+	connectionService := NewUserConnectionService(repos.Connections, userSerrvice)
 
 	return &ServiceContainer{
-		UserService:                  NewUserService(firestoreClient),
-		ConnectionService:            NewUserConnectionService(firestoreClient, userSerrvice),
-		NotificationService:          NewNotificationService(firestoreClient),
-		MessageService:               NewMessageService(firestoreClient),
-		GroupChatService:             NewGroupChatService(firestoreClient),
-		AuthService:                  NewAuthService(firestoreClient),
-		FAQService:                   NewFAQService(firestoreClient),
-		ProjectCoreService:           NewProjectCoreService(firestoreClient),
-		ProjectService:               NewProjectService(firestoreClient, userSerrvice),
-		AddressService:               NewAddressService(firestoreClient),
-		NewsletterService:            NewNewsletterService(firestoreClient),
-		ContactUsService:             NewContactUsService(firestoreClient),
+		UserService:                  NewUserService(repos.Users),
+		ConnectionService:            connectionService,
+		NotificationService:          NewNotificationService(repos.Notifications),
+		GroupChatService:             NewGroupChatService(repos.GroupChats, repos.GroupMessages, repos.ReadPositions),
+		ConversationService:          NewConversationService(repos.Conversations),
+		AuthService:                  NewAuthService(repos.Users),
+		SessionService:               sessionService,
+		AuthorizationService:         authorizationService,
+		EmailVerificationService:     NewEmailVerificationService(repos.Users, repos.ActionTokens, Tokens, config.EmailVerificationTTL, config.RequireEmailVerification),
+		PasswordService:              NewPasswordService(repos.Users, repos.ActionTokens, Tokens, sessionService, identityProvider),
+		IdentityProvider:             identityProvider,
+		TwoFactorService:             twoFactorService,
+		AccountMergeService:          NewAccountMergeService(repos, sessionService, authorizationService, identityProvider, connectionService),
+		PersonalAccessTokenService:   NewPersonalAccessTokenService(repos.PersonalAccessTokens, repos.Users),
+		LoginProtectionService:       NewLoginProtectionService(repos.LoginThrottles, repos.Users, repos.ActionTokens, Tokens),
+		AuditService:                 NewAuditService(repos.AuditEvents),
+		PresenceService:              NewPresenceService(repos.Presences, repos.Users, repos.GroupChats),
+		EventStream:                  NewEventStreamTransport(),
+		TypingService:                NewTypingService(authorizationService.AuthorizeChannel),
+		OAuthService:                 NewOAuthService(oauthProviders, repos.OAuthStates, repos.Identities, repos.Users, identityProvider, config.OAuthRedirectURL),
+		FAQService:                   NewFAQService(repos.FAQs),
+		ProjectCoreService:           NewProjectCoreService(repos.Projects),
+		ProjectService:               NewProjectService(repos.Projects, userSerrvice),
+		AddressService:               NewAddressService(repos.Addresses),
+		NewsletterService:            NewNewsletterService(repos.Newsletters),
+		ContactUsService:             NewContactUsService(repos.Contacts),
 		PDFService:                   pdfService,
-		ChatGPTService:               NewChatGPTService(firestoreClient, pdfService),
+		ChatGPTService:               NewChatGPTService(repos.ChatGPTConversations, pdfService),
 		UploadPDFService:             uploadPdfService,
-		UserSchoolExperienceService:  NewUserSchoolExperienceService(firestoreClient, userSerrvice),
-		GroupService:                 NewGroupService(firestoreClient, cloudinary, userSerrvice),
-		ForumService:                 NewForumService(firestoreClient, userSerrvice),
-		TestimonialService:           NewTestimonialService(firestoreClient, userSerrvice),
-		GeneralNotificationService:   NewGeneralNotificationService(firestoreClient),
-		JobService:                   NewJobService(firestoreClient),
-		LinkedInJobsService:          NewLinkedInJobsService(firestoreClient),
+		UserSchoolExperienceService:  NewUserSchoolExperienceService(repos.SchoolExperiences, userSerrvice),
+		GroupService:                 NewGroupService(repos.Groups, cloudinary, userSerrvice),
+		ForumService:                 NewForumService(repos.Forums, userSerrvice),
+		TestimonialService:           NewTestimonialService(repos.Testimonials, userSerrvice),
+		GeneralNotificationService:   NewGeneralNotificationService(repos.Users, repos.Notifications),
+		JobService:                   NewJobService(repos.Jobs),
+		LinkedInJobsService:          NewLinkedInJobsService(repos.LinkedInApplications),
 		notificationScheduler:        notificationScheduler,
 		SchedulerNotificationService: NewSchedulerNotificationService(notificationScheduler),
-		// WebSocketService:    NewWebSocketService(firestoreClient),
-		// UserConnectionService: NewUserConnectionService(firestoreClient, userSerrvice),
+		// UserConnectionService: NewUserConnectionService(repos.Connections, userSerrvice),
 		// Initialize other services
 	}
 }
 
+// newIdentityProvider selects the identity provider configured by IDENTITY_PROVIDER
+func newIdentityProvider(repos *repository.Repositories) IdentityProvider {
+	if config.IdentityProvider != "local" {
+		return NewFirebaseIdentityProvider(FirebaseAuth, config.FirebaseSignInURL)
+	}
+
+	var migrateFrom PasswordProvider
+	if config.MigrateFromFirebase {
+		if FirebaseAuth == nil {
+			log.Println("IDENTITY_MIGRATE_FROM_FIREBASE needs Firebase, passwords will not be migrated")
+		} else {
+			migrateFrom = NewFirebaseIdentityProvider(FirebaseAuth, config.FirebaseSignInURL)
+		}
+	}
+	return NewLocalIdentityProvider(repos.Credentials, config.PasswordHashAlgorithm, migrateFrom)
+}
+
 // StartServices initializes and starts any background services
 // func (sc *ServiceContainer) StartServices(ctx context.Context) {
 // 	// Start the notification scheduler
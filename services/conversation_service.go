@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+type ConversationService struct {
+	conversations repository.ConversationRepository
+}
+
+func NewConversationService(conversations repository.ConversationRepository) *ConversationService {
+	return &ConversationService{
+		conversations: conversations,
+	}
+}
+
+const (
+	// defaultConversationPageSize is the number of conversations returned when no limit is given
+	defaultConversationPageSize = 20
+	maxConversationPageSize     = 100
+)
+
+// ConversationID returns the ID of the conversation between two users,
+// which is also the suffix of their realtime channel
+func ConversationID(userA, userB string) string {
+	return repository.ConversationID(userA, userB)
+}
+
+// SendDirectMessage stores a direct message in the conversation of its sender and receiver
+func (s *ConversationService) SendDirectMessage(ctx context.Context, message *models.DirectMessage) (*models.DirectConversation, error) {
+	if message.SenderID == "" || message.ReceiverID == "" {
+		return nil, errors.New("sender and receiver are required")
+	}
+	if message.SenderID == message.ReceiverID {
+		return nil, errors.New("you cannot send a message to yourself")
+	}
+
+	conversation, err := s.conversations.AppendMessage(ctx, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send message: %v", err)
+	}
+
+	publishUnreadDelta(message.ReceiverID, UnreadDirectMessages, conversation.ID, 1)
+	return conversation, nil
+}
+
+// GetConversation returns a conversation the user takes part in
+func (s *ConversationService) GetConversation(ctx context.Context, conversationID, userID string) (*models.DirectConversation, error) {
+	if conversationID == "" {
+		return nil, errors.New("conversationID is required")
+	}
+
+	conversation, err := s.conversations.Get(ctx, conversationID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("conversation with ID %s not found", conversationID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch conversation: %v", err)
+	}
+
+	for _, participant := range conversation.Participants {
+		if participant == userID {
+			return conversation, nil
+		}
+	}
+	return nil, errors.New("user is not a participant in the conversation")
+}
+
+// ListConversations returns one page of the user's conversations, most recently active first.
+// page.Before is the ID of the last conversation of the previous page.
+func (s *ConversationService) ListConversations(ctx context.Context, userID string, page repository.ConversationPage) ([]models.DirectConversation, error) {
+	if page.Limit <= 0 {
+		page.Limit = defaultConversationPageSize
+	}
+	if page.Limit > maxConversationPageSize {
+		page.Limit = maxConversationPageSize
+	}
+
+	conversations, err := s.conversations.ListByParticipant(ctx, userID, page)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.New("cursor conversation not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch conversations: %v", err)
+	}
+
+	return conversations, nil
+}
+
+// ListMessages returns one page of the messages of a conversation, oldest message first.
+// page.Before and page.After are message IDs used as cursors.
+func (s *ConversationService) ListMessages(ctx context.Context, conversationID, userID string, page repository.MessagePage) ([]models.DirectMessage, error) {
+	conversation, err := s.GetConversation(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Limit <= 0 {
+		page.Limit = defaultMessagePageSize
+	}
+	if page.Limit > maxMessagePageSize {
+		page.Limit = maxMessagePageSize
+	}
+
+	messages, err := s.conversations.ListMessages(ctx, conversation.ID, page)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.New("cursor message not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch messages: %v", err)
+	}
+
+	return messages, nil
+}
+
+// MarkRead marks the messages the user received in the conversation as read
+func (s *ConversationService) MarkRead(ctx context.Context, conversationID, userID string) error {
+	conversation, err := s.GetConversation(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	cleared, err := s.conversations.MarkRead(ctx, conversation.ID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update read status: %v", err)
+	}
+	publishUnreadDelta(userID, UnreadDirectMessages, conversation.ID, -cleared)
+	return nil
+}
+
+// SetMuted mutes or unmutes the conversation for the user
+func (s *ConversationService) SetMuted(ctx context.Context, conversationID, userID string, muted bool) (*models.DirectConversation, error) {
+	return s.setFlag(ctx, conversationID, userID, func(conversation *models.DirectConversation) {
+		if conversation.Muted == nil {
+			conversation.Muted = make(map[string]bool)
+		}
+		conversation.Muted[userID] = muted
+	})
+}
+
+// SetArchived archives or restores the conversation for the user
+func (s *ConversationService) SetArchived(ctx context.Context, conversationID, userID string, archived bool) (*models.DirectConversation, error) {
+	return s.setFlag(ctx, conversationID, userID, func(conversation *models.DirectConversation) {
+		if conversation.Archived == nil {
+			conversation.Archived = make(map[string]bool)
+		}
+		conversation.Archived[userID] = archived
+	})
+}
+
+func (s *ConversationService) setFlag(ctx context.Context, conversationID, userID string, set func(*models.DirectConversation)) (*models.DirectConversation, error) {
+	conversation, err := s.GetConversation(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.conversations.Update(ctx, conversation.ID, func(conversation *models.DirectConversation) error {
+		set(conversation)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update conversation: %v", err)
+	}
+
+	return updated, nil
+}
+
+// CountUnread counts the direct messages the user has not read.
+// When senderID is set only the conversation with that sender is counted.
+func (s *ConversationService) CountUnread(ctx context.Context, userID, senderID string) (int, error) {
+	if senderID != "" {
+		conversation, err := s.conversations.Get(ctx, ConversationID(userID, senderID))
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch conversation: %v", err)
+		}
+		return conversation.UnreadCount[userID], nil
+	}
+
+	unread := 0
+	for _, archived := range []bool{false, true} {
+		conversations, err := s.conversations.ListByParticipant(ctx, userID, repository.ConversationPage{Archived: archived})
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch conversations: %v", err)
+		}
+		for _, conversation := range conversations {
+			unread += conversation.UnreadCount[userID]
+		}
+	}
+	return unread, nil
+}
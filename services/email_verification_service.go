@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+// PurposeEmailVerification is the purpose of the tokens in verification links
+const PurposeEmailVerification = "email_verification"
+
+// defaultEmailVerificationTTL is how long a verification link works when none is configured
+const defaultEmailVerificationTTL = 24 * time.Hour
+
+var (
+	// ErrInvalidVerificationToken is returned for unknown, expired and used verification tokens
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification link")
+	// ErrEmailAlreadyVerified is returned when verifying a verified email again
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	// ErrEmailNotVerified is returned when an unverified user attempts a restricted operation
+	ErrEmailNotVerified = errors.New("please verify your email address first")
+)
+
+// EmailVerificationService issues and checks the single-use tokens of the verification links
+type EmailVerificationService struct {
+	users  repository.UserRepository
+	tokens *actionTokens
+	// required restricts unverified users, see RequireVerifiedEmail
+	required bool
+}
+
+func NewEmailVerificationService(users repository.UserRepository, actionTokenRepo repository.ActionTokenRepository, signer *TokenService, ttl time.Duration, required bool) *EmailVerificationService {
+	if ttl <= 0 {
+		ttl = defaultEmailVerificationTTL
+	}
+	return &EmailVerificationService{
+		users: users,
+		tokens: &actionTokens{
+			repo:      actionTokenRepo,
+			signer:    signer,
+			purpose:   PurposeEmailVerification,
+			ttl:       ttl,
+			throttled: true,
+		},
+		required: required,
+	}
+}
+
+// Required reports whether unverified users are restricted until they verify their email
+func (s *EmailVerificationService) Required() bool {
+	return s.required
+}
+
+// TTL is how long a verification link works
+func (s *EmailVerificationService) TTL() time.Duration {
+	return s.tokens.ttl
+}
+
+// CreateToken issues the token of a verification link for the current email of the user.
+// It returns a ThrottledError when the user asked for links too often.
+func (s *EmailVerificationService) CreateToken(ctx context.Context, user *models.User) (string, error) {
+	if user.IsVerified {
+		return "", ErrEmailAlreadyVerified
+	}
+	return s.tokens.issue(ctx, user)
+}
+
+// Verify uses a verification token and marks the email of its user as verified
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) (*models.User, error) {
+	claims, err := s.tokens.verify(token)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.users.GetByUID(ctx, claims.UID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %v", err)
+	}
+	if user.IsVerified {
+		return nil, ErrEmailAlreadyVerified
+	}
+
+	// Links mailed to a previous address must not verify the current one
+	if user.Email != claims.Email {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	if err := s.tokens.use(ctx, claims); err != nil {
+		if errors.Is(err, errActionTokenInvalid) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+
+	if err := s.users.Update(ctx, user.UID, map[string]interface{}{"is_verified": true}); err != nil {
+		return nil, fmt.Errorf("failed to verify user: %v", err)
+	}
+
+	user.IsVerified = true
+	return user, nil
+}
+
+// IsVerified reports whether the user verified their email
+func (s *EmailVerificationService) IsVerified(ctx context.Context, uid string) (bool, error) {
+	user, err := s.users.GetByUID(ctx, uid)
+	if err != nil {
+		return false, err
+	}
+	return user.IsVerified, nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailVerificationServiceVerify(t *testing.T) {
+	tests := []struct {
+		name string
+		// token returns the token to verify, given the one issued to the user
+		token func(t *testing.T, issued string, tokens *TokenService) string
+		// changeEmail changes the email of the user after the link was sent
+		changeEmail bool
+		wantErr     error
+	}{
+		{
+			name:  "issued token",
+			token: func(t *testing.T, issued string, tokens *TokenService) string { return issued },
+		},
+		{
+			name:        "link mailed to a previous address",
+			token:       func(t *testing.T, issued string, tokens *TokenService) string { return issued },
+			changeEmail: true,
+			wantErr:     ErrInvalidVerificationToken,
+		},
+		{
+			name:    "garbage",
+			token:   func(t *testing.T, issued string, tokens *TokenService) string { return "not-a-token" },
+			wantErr: ErrInvalidVerificationToken,
+		},
+		{
+			name: "token of another purpose",
+			token: func(t *testing.T, issued string, tokens *TokenService) string {
+				token, err := tokens.IssueActionToken("id", "user-1", "user@example.com", PurposePasswordReset, time.Now().Add(time.Hour))
+				require.NoError(t, err)
+				return token
+			},
+			wantErr: ErrInvalidVerificationToken,
+		},
+		{
+			name: "signed token without a record",
+			token: func(t *testing.T, issued string, tokens *TokenService) string {
+				token, err := tokens.IssueActionToken("forged", "user-1", "user@example.com", PurposeEmailVerification, time.Now().Add(time.Hour))
+				require.NoError(t, err)
+				return token
+			},
+			wantErr: ErrInvalidVerificationToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := repository.NewMemoryUserRepository()
+			user := &models.User{UID: "user-1", Email: "user@example.com"}
+			require.NoError(t, users.Create(ctx, user))
+			tokens := newTestTokenService(t)
+			service := NewEmailVerificationService(users, repository.NewMemoryActionTokenRepository(), tokens, 0, true)
+
+			issued, err := service.CreateToken(ctx, user)
+			require.NoError(t, err)
+			if tt.changeEmail {
+				require.NoError(t, users.Update(ctx, user.UID, map[string]interface{}{"email": "new@example.com"}))
+			}
+
+			verified, err := service.Verify(ctx, tt.token(t, issued, tokens))
+			isVerified, checkErr := service.IsVerified(ctx, user.UID)
+			require.NoError(t, checkErr)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.False(t, isVerified)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, verified.IsVerified)
+			assert.True(t, isVerified)
+
+			_, err = service.Verify(ctx, issued)
+			assert.ErrorIs(t, err, ErrEmailAlreadyVerified)
+		})
+	}
+}
+
+func TestEmailVerificationServiceCreateToken(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewMemoryUserRepository()
+	service := NewEmailVerificationService(users, repository.NewMemoryActionTokenRepository(), newTestTokenService(t), 0, false)
+	assert.False(t, service.Required())
+	assert.Equal(t, defaultEmailVerificationTTL, service.TTL())
+
+	_, err := service.CreateToken(ctx, &models.User{UID: "user-1", IsVerified: true})
+	assert.ErrorIs(t, err, ErrEmailAlreadyVerified)
+
+	// Links are sent at most once a minute
+	user := &models.User{UID: "user-2", Email: "user@example.com"}
+	_, err = service.CreateToken(ctx, user)
+	require.NoError(t, err)
+	_, err = service.CreateToken(ctx, user)
+	var throttled *ThrottledError
+	require.ErrorAs(t, err, &throttled)
+	assert.InDelta(t, actionTokenResendInterval.Seconds(), throttled.RetryAfter.Seconds(), 1)
+}
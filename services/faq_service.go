@@ -5,23 +5,22 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/rogerjeasy/go-letusconnect/mappers"
+	"github.com/google/uuid"
 	"github.com/rogerjeasy/go-letusconnect/models"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 type FAQService struct {
-	firestoreClient FirestoreClient
+	faqs repository.FAQRepository
 }
 
-func NewFAQService(client FirestoreClient) *FAQService {
+func NewFAQService(faqs repository.FAQRepository) *FAQService {
 	return &FAQService{
-		firestoreClient: client,
+		faqs: faqs,
 	}
 }
 
-// CreateFAQ creates a new FAQ document in Firestore
+// CreateFAQ stores a new FAQ
 func (s *FAQService) CreateFAQ(ctx context.Context, faq models.FAQ, username string, uid string) (*models.FAQ, error) {
 	// Validate required fields
 	if faq.Question == "" {
@@ -36,41 +35,58 @@ func (s *FAQService) CreateFAQ(ctx context.Context, faq models.FAQ, username str
 
 	// Set metadata fields
 	now := time.Now()
+	faq.ID = uuid.New().String()
 	faq.CreatedAt = now
 	faq.UpdatedAt = now
 	faq.CreatedBy = uid
 	faq.Username = username
 
-	// Convert to Firestore format
-	firestoreData := mappers.MapFAQGoToFirestore(faq)
-
-	// Add to Firestore
-	docRef, _, err := s.firestoreClient.Collection("faqs").Add(ctx, firestoreData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to add FAQ to Firestore: %v", err)
+	if err := s.faqs.Create(ctx, &faq); err != nil {
+		return nil, fmt.Errorf("failed to store FAQ: %v", err)
 	}
 
-	// Set the document ID
-	faq.ID = docRef.ID
 	return &faq, nil
 }
 
 // GetFAQByID retrieves a single FAQ by its ID
 func (s *FAQService) GetFAQByID(ctx context.Context, id string) (*models.FAQ, error) {
-	docRef := s.firestoreClient.Collection("faqs").Doc(id)
-	doc, err := docRef.Get(ctx)
+	faq, err := s.faqs.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get FAQ: %w", err)
+	}
+	return faq, nil
+}
+
+// UpdateFAQ replaces the editable fields of an FAQ
+func (s *FAQService) UpdateFAQ(ctx context.Context, id string, changes models.FAQ, username string) (*models.FAQ, error) {
+	faq, err := s.faqs.Update(ctx, id, func(faq *models.FAQ) error {
+		faq.Question = changes.Question
+		faq.Response = changes.Response
+		faq.Category = changes.Category
+		faq.Status = changes.Status
+		faq.UpdatedBy = username
+		faq.UpdatedAt = time.Now()
+		return nil
+	})
 	if err != nil {
-		if status.Code(err) == codes.NotFound {
-			return nil, fmt.Errorf("FAQ not found")
-		}
-		return nil, fmt.Errorf("failed to get FAQ: %v", err)
+		return nil, fmt.Errorf("failed to update FAQ: %w", err)
 	}
+	return faq, nil
+}
 
-	// Get the Firestore data and add the document ID
-	data := doc.Data()
-	data["id"] = doc.Ref.ID
+// DeleteFAQ removes an FAQ
+func (s *FAQService) DeleteFAQ(ctx context.Context, id string) error {
+	if err := s.faqs.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete FAQ: %w", err)
+	}
+	return nil
+}
 
-	// Convert to FAQ struct
-	faq := mappers.MapFAQFirestoreToGo(data)
-	return &faq, nil
+// GetAllFAQs lists every FAQ
+func (s *FAQService) GetAllFAQs(ctx context.Context) ([]models.FAQ, error) {
+	faqs, err := s.faqs.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list FAQs: %v", err)
+	}
+	return faqs, nil
 }
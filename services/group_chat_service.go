@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"mime/multipart"
@@ -9,26 +10,36 @@ import (
 	"strings"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/google/uuid"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
 	"github.com/rogerjeasy/go-letusconnect/models"
-	"google.golang.org/api/iterator"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 type GroupChatService struct {
-	firestoreClient FirestoreClient // Use the FirestoreClient interface
+	groupChats    repository.GroupChatRepository
+	messages      repository.GroupMessageRepository
+	readPositions repository.ReadPositionRepository
 }
 
-func NewGroupChatService(client FirestoreClient) *GroupChatService {
+func NewGroupChatService(groupChats repository.GroupChatRepository, messages repository.GroupMessageRepository, readPositions repository.ReadPositionRepository) *GroupChatService {
 	return &GroupChatService{
-		firestoreClient: client,
+		groupChats:    groupChats,
+		messages:      messages,
+		readPositions: readPositions,
 	}
 }
 
+// ErrNoGroupChatMessages is returned when reading a group chat without messages
+var ErrNoGroupChatMessages = errors.New("no messages found in the group chat")
+
+const (
+	// defaultMessagePageSize is the number of messages returned when no limit is given
+	defaultMessagePageSize = 50
+	maxMessagePageSize     = 100
+)
+
 type GroupChatInput struct {
 	ProjectID      string
 	Name           string
@@ -93,40 +104,85 @@ func (s *GroupChatService) CreateGroupChatService(ctx context.Context, input Gro
 		},
 	}
 
-	// Save to Firestore
-	_, _, err := s.firestoreClient.Collection("group_chats").Add(ctx, mappers.MapGroupChatGoToFirestore(groupChat))
-	if err != nil {
+	if err := s.groupChats.Create(ctx, &groupChat); err != nil {
 		return nil, err
 	}
 
 	return &groupChat, nil
 }
 
+// getGroupChat fetches a group chat by its ID or, when no ID is given, by the project it belongs to
+func (s *GroupChatService) getGroupChat(ctx context.Context, groupChatID, projectID string) (*models.GroupChat, error) {
+	if groupChatID != "" {
+		return s.groupChats.Get(ctx, groupChatID)
+	}
+	return s.groupChats.GetByProject(ctx, projectID)
+}
+
 func (s *GroupChatService) GetGroupChatService(ctx context.Context, groupId string) (map[string]interface{}, error) {
 	if groupId == "" {
 		return nil, fmt.Errorf("group ID is required")
 	}
 
-	query := s.firestoreClient.Collection("group_chats").Where("id", "==", groupId).Limit(1)
-	iter := query.Documents(ctx)
-	docSnap, err := iter.Next()
-	if err == iterator.Done {
+	groupChat, err := s.groupChats.Get(ctx, groupId)
+	if errors.Is(err, repository.ErrNotFound) {
 		return nil, fmt.Errorf("no group chat found for the given project ID")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return nil, fmt.Errorf("group chat not found")
+	// Older history is fetched page by page with ListMessagesService
+	groupChat.Messages, err = s.messages.List(ctx, groupChat.ID, repository.MessagePage{Limit: defaultMessagePageSize})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch group chat messages: %v", err)
 	}
 
-	data["id"] = docSnap.Ref.ID
+	return mappers.MapGroupChatGoToFrontend(*groupChat), nil
+}
 
-	frontendData := mappers.MapGroupChatFirestoreToFrontend(data)
+// ListMessagesService returns one page of the history of a group chat, oldest message first.
+// page.Before and page.After are message IDs used as cursors.
+func (s *GroupChatService) ListMessagesService(ctx context.Context, groupChatID, userID string, page repository.MessagePage) ([]models.BaseMessage, error) {
+	if groupChatID == "" {
+		return nil, fmt.Errorf("groupChatID is required")
+	}
+
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("group chat with ID %s not found", groupChatID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch group chat: %v", err)
+	}
+
+	isParticipant := false
+	for _, participant := range groupChat.Participants {
+		if participant.UserID == userID {
+			isParticipant = true
+			break
+		}
+	}
+	if !isParticipant {
+		return nil, fmt.Errorf("user is not a participant in the group chat")
+	}
 
-	return frontendData, nil
+	if page.Limit <= 0 {
+		page.Limit = defaultMessagePageSize
+	}
+	if page.Limit > maxMessagePageSize {
+		page.Limit = maxMessagePageSize
+	}
+
+	messages, err := s.messages.List(ctx, groupChat.ID, page)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("cursor message not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch messages: %v", err)
+	}
+
+	return messages, nil
 }
 
 // GetGroupChatsByProjectService fetches all group chats for a project
@@ -135,22 +191,14 @@ func (s *GroupChatService) GetGroupChatsByProjectService(ctx context.Context, pr
 		return nil, fmt.Errorf("project ID is required")
 	}
 
-	// Query Firestore for group chats with matching project ID
-	query := s.firestoreClient.Collection("group_chats").Where("project_id", "==", projectId)
-	docs, err := query.Documents(ctx).GetAll()
+	chats, err := s.groupChats.ListByProject(ctx, projectId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch group chats: %v", err)
 	}
 
 	var groupChats []map[string]interface{}
-	for _, doc := range docs {
-		data := doc.Data()
-		// Ensure ID is in the data
-		data["id"] = doc.Ref.ID
-
-		// Convert each group chat to frontend format
-		frontendData := mappers.MapGroupChatFirestoreToFrontend(data)
-		groupChats = append(groupChats, frontendData)
+	for _, chat := range chats {
+		groupChats = append(groupChats, mappers.MapGroupChatGoToFrontend(chat))
 	}
 
 	return groupChats, nil
@@ -162,38 +210,14 @@ func (s *GroupChatService) GetGroupChatsByUserService(ctx context.Context, userI
 		return nil, fmt.Errorf("user ID is required")
 	}
 
-	query := s.firestoreClient.Collection("group_chats")
-
-	docs, err := query.Documents(ctx).GetAll()
+	chats, err := s.groupChats.ListByParticipant(ctx, userId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch group chats: %v", err)
 	}
 
 	groupChats := []map[string]interface{}{}
-
-	for _, doc := range docs {
-		data := doc.Data()
-
-		// Check if participants include the userId
-		if participants, ok := data["participants"].([]interface{}); ok {
-			userIsParticipant := false
-			for _, participant := range participants {
-				if participantMap, ok := participant.(map[string]interface{}); ok {
-					if participantMap["user_id"] == userId {
-						userIsParticipant = true
-						break
-					}
-				}
-			}
-
-			// Add the group chat to the result if the user is a participant
-			if userIsParticipant {
-				data["id"] = doc.Ref.ID
-
-				frontendData := mappers.MapGroupChatFirestoreToFrontend(data)
-				groupChats = append(groupChats, frontendData)
-			}
-		}
+	for _, chat := range chats {
+		groupChats = append(groupChats, mappers.MapGroupChatGoToFrontend(chat))
 	}
 
 	return groupChats, nil
@@ -209,39 +233,17 @@ func (s *GroupChatService) AddParticipantsToGroupChat(ctx context.Context, group
 		return fmt.Errorf("participants list cannot be empty")
 	}
 
-	var docRef *firestore.DocumentRef
-	var docSnap *firestore.DocumentSnapshot
-	var err error
-
-	// Fetch the group chat document
-	if groupChatID != "" {
-		docRef = s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-		docSnap, err = docRef.Get(ctx)
-	} else if projectID != "" {
-		query := s.firestoreClient.Collection("group_chats").Where("project_id", "==", projectID).Limit(1)
-		iter := query.Documents(ctx)
-		docSnap, err = iter.Next()
-		if err == iterator.Done {
-			return fmt.Errorf("group chat not found for the given project ID")
-		}
-		if err == nil {
-			docRef = docSnap.Ref
-		}
+	// Fetch the group chat
+	groupChat, err := s.getGroupChat(ctx, groupChatID, projectID)
+	if errors.Is(err, repository.ErrNotFound) && groupChatID == "" {
+		return fmt.Errorf("group chat not found for the given project ID")
 	}
-
 	if err != nil {
 		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return fmt.Errorf("group chat data is missing")
-	}
-
-	existingParticipants := mappers.GetParticipantsGoArray(data, "participants")
-
 	isOwner := false
-	for _, p := range existingParticipants {
+	for _, p := range groupChat.Participants {
 		if p.UserID == userID && p.Role == "owner" {
 			isOwner = true
 			break
@@ -252,29 +254,21 @@ func (s *GroupChatService) AddParticipantsToGroupChat(ctx context.Context, group
 		return fmt.Errorf("only owners can add participants to the group chat")
 	}
 
-	// Check for duplicate participants and append new ones
+	// Reject participants that are already part of the chat
 	for _, newParticipant := range participants {
-		exists := false
-		for _, existingParticipant := range existingParticipants {
+		for _, existingParticipant := range groupChat.Participants {
 			if existingParticipant.UserID == newParticipant.UserID {
-				exists = true
 				return fmt.Errorf("participant with name %s already exists", newParticipant.Username)
 			}
 		}
-
-		if !exists {
-			existingParticipants = append(existingParticipants, newParticipant)
-		}
 	}
 
-	// Map the participants back to Firestore format
-	participantsFirestore := mappers.MapParticipantsArrayToFirestore(existingParticipants)
-
-	// Update the Firestore document
-	if _, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "participants", Value: participantsFirestore},
-		{Path: "updated_at", Value: time.Now()},
-	}); err != nil {
+	_, err = s.groupChats.Update(ctx, groupChat.ID, func(chat *models.GroupChat) error {
+		chat.Participants = append(chat.Participants, participants...)
+		chat.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update group chat participants: %v", err)
 	}
 
@@ -286,22 +280,52 @@ func (s *GroupChatService) GetGroupChatParticipants(ctx context.Context, groupCh
 		return nil, fmt.Errorf("groupChatID is required")
 	}
 
-	groupChatDoc := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-
-	docSnapshot, err := groupChatDoc.Get(ctx)
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		// Check if the error is a "not found" error
-		if status.Code(err) == codes.NotFound {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, fmt.Errorf("group chat with ID %s not found", groupChatID)
 		}
 		return nil, fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	groupChat := mappers.MapGroupChatFirestoreToGo(docSnapshot.Data())
-
 	return groupChat.Participants, nil
 }
 
+// newGroupMessage builds a message whose read status is set for every participant, the sender having read it
+func newGroupMessage(senderID, senderName, content, messageType string, attachments []string) models.BaseMessage {
+	return models.BaseMessage{
+		ID:          uuid.New().String(),
+		SenderID:    senderID,
+		SenderName:  senderName,
+		Content:     content,
+		CreatedAt:   time.Now().Format(time.RFC3339),
+		IsDeleted:   false,
+		Attachments: attachments,
+		Reactions:   make(map[string]int),
+		MessageType: messageType,
+	}
+}
+
+// appendGroupMessage stores a new message at the end of the group chat history, and counts it
+// as unread for the other participants. Sending a message reads the chat up to it.
+func (s *GroupChatService) appendGroupMessage(ctx context.Context, groupChat *models.GroupChat, message models.BaseMessage) error {
+	groupChatID := groupChat.ID
+	if err := s.messages.Append(ctx, groupChatID, &message); err != nil {
+		return err
+	}
+
+	for _, participant := range groupChat.Participants {
+		if participant.UserID != message.SenderID {
+			publishUnreadDelta(participant.UserID, UnreadGroupChats, groupChatID, 1)
+		}
+	}
+	if _, _, err := s.readUpTo(ctx, groupChatID, message.SenderID, message.ID); err != nil {
+		log.Printf("Error moving the read position of user %s in group chat %s: %v", message.SenderID, groupChatID, err)
+	}
+	return nil
+}
+
 func (s *GroupChatService) SendMessageService(ctx context.Context, groupChatID string, senderID string, senderName string, content string) (*models.BaseMessage, error) {
 	// Validate required parameters
 	if groupChatID == "" {
@@ -314,49 +338,25 @@ func (s *GroupChatService) SendMessageService(ctx context.Context, groupChatID s
 		return nil, fmt.Errorf("message content cannot be empty")
 	}
 
-	// Fetch the group chat document
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	// Fetch the group chat
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return nil, fmt.Errorf("group chat not found")
-	}
-
-	// Retrieve participants to set read statuses
-	participants := mappers.GetParticipantsGoArray(data, "participants")
+	participants := groupChat.Participants
 	if len(participants) == 0 {
 		return nil, fmt.Errorf("no participants found in the group chat")
 	}
 
-	readStatus := make(map[string]bool)
 	for _, participant := range participants {
 		if participant.UserID == "" {
 			return nil, fmt.Errorf("participant UserID cannot be empty")
 		}
-		if participant.UserID == senderID {
-			readStatus[participant.UserID] = true
-		} else {
-			readStatus[participant.UserID] = false
-		}
 	}
 
 	// Create the new message
-	message := models.BaseMessage{
-		ID:          uuid.New().String(),
-		SenderID:    senderID,
-		SenderName:  senderName,
-		Content:     content,
-		CreatedAt:   time.Now().Format(time.RFC3339),
-		ReadStatus:  readStatus,
-		IsDeleted:   false,
-		Attachments: []string{},
-		Reactions:   make(map[string]int),
-		MessageType: "text",
-	}
+	message := newGroupMessage(senderID, senderName, content, "text", []string{})
 
 	// Send notification asynchronously
 	// go func() {
@@ -365,21 +365,7 @@ func (s *GroupChatService) SendMessageService(ctx context.Context, groupChatID s
 	// 	}
 	// }()
 
-	// Retrieve existing messages and append the new message
-	messages := mappers.GetBaseMessagesArrayFromFirestore(data, "messages")
-	if messages == nil {
-		messages = []models.BaseMessage{}
-	}
-	messages = append(messages, message)
-
-	// Prepare Firestore payload
-	firestorePayload := map[string]interface{}{
-		"messages":   mappers.MapBaseMessagesArrayToFirestore(messages),
-		"updated_at": time.Now(),
-	}
-
-	// Update Firestore
-	if _, err := docRef.Set(ctx, firestorePayload, firestore.MergeAll); err != nil {
+	if err := s.appendGroupMessage(ctx, groupChat, message); err != nil {
 		return nil, fmt.Errorf("failed to update group chat with new message: %v", err)
 	}
 
@@ -387,53 +373,115 @@ func (s *GroupChatService) SendMessageService(ctx context.Context, groupChatID s
 	return &message, nil
 }
 
-func (s *GroupChatService) MarkMessagesAsReadService(ctx context.Context, groupChatID, userID string) error {
+// MarkMessagesAsReadService reads the group chat up to the message, or up to its latest
+// message without one. The read position only moves forward. It fails with
+// repository.ErrNotFound when the chat or the message does not exist, ErrForbidden when the
+// user does not take part in the chat, and ErrNoGroupChatMessages when it has no messages.
+func (s *GroupChatService) MarkMessagesAsReadService(ctx context.Context, groupChatID, userID, messageID string) (*models.ReadPosition, error) {
 	// Validate required parameters
 	if groupChatID == "" {
-		return fmt.Errorf("groupChatID is required")
+		return nil, fmt.Errorf("groupChatID is required")
 	}
 	if userID == "" {
-		return fmt.Errorf("userID is required")
+		return nil, fmt.Errorf("userID is required")
 	}
 
-	// Fetch the group chat document
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	// Fetch the group chat
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
-		return fmt.Errorf("failed to fetch group chat: %v", err)
+		return nil, fmt.Errorf("failed to fetch group chat: %w", err)
+	}
+	if !isGroupChatParticipant(groupChat.Participants, userID) {
+		return nil, fmt.Errorf("%w: user is not a participant in the group chat", ErrForbidden)
+	}
+
+	if messageID == "" {
+		latest, err := s.messages.List(ctx, groupChat.ID, repository.MessagePage{Limit: 1})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch group chat messages: %v", err)
+		}
+		if len(latest) == 0 {
+			return nil, ErrNoGroupChatMessages
+		}
+		messageID = latest[0].ID
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return fmt.Errorf("group chat not found")
+	position, previousSeq, err := s.readUpTo(ctx, groupChat.ID, userID, messageID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("message %w", repository.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update the read position: %v", err)
 	}
 
-	// Retrieve existing messages
-	messages := mappers.GetBaseMessagesArrayFromFirestore(data, "messages")
-	if len(messages) == 0 {
-		return fmt.Errorf("no messages found in the group chat")
+	delta, err := s.readDelta(ctx, groupChat.ID, userID, position.Seq, previousSeq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %v", err)
 	}
+	publishUnreadDelta(userID, UnreadGroupChats, groupChat.ID, delta)
+	return position, nil
+}
 
-	// Update the `read_status` for the given user in each message
-	for i := range messages {
-		if messages[i].ReadStatus == nil {
-			messages[i].ReadStatus = make(map[string]bool)
-		}
-		messages[i].ReadStatus[userID] = true
+// readDelta is the change of the unread count of a user whose read position moved from
+// previousSeq to seq. Every message has a seq, so the messages read are the ones in between.
+// Users reading the chat for the first time since read positions replaced the read status
+// of each message had their unread messages counted with the read status.
+func (s *GroupChatService) readDelta(ctx context.Context, groupChatID, userID string, seq, previousSeq int64) (int, error) {
+	if previousSeq > 0 {
+		return -int(seq - previousSeq), nil
 	}
 
-	// Map updated messages to Firestore format
-	firestoreMessages := mappers.MapBaseMessagesArrayToFirestore(messages)
+	unread, err := s.messages.CountUnread(ctx, groupChatID, userID)
+	if err != nil {
+		return 0, err
+	}
+	stillUnread, err := s.messages.CountAfter(ctx, groupChatID, seq)
+	if err != nil {
+		return 0, err
+	}
+	return stillUnread - unread, nil
+}
 
-	// Update Firestore document
-	if _, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "messages", Value: firestoreMessages},
-		{Path: "updated_at", Value: time.Now()},
-	}); err != nil {
-		return fmt.Errorf("failed to update group chat messages: %v", err)
+// readUpTo moves the read position of the user forward to the message, and tells the
+// participants of the chat when it moved. It returns the position and the Seq of the
+// position before, 0 when the user had none.
+func (s *GroupChatService) readUpTo(ctx context.Context, groupChatID, userID, messageID string) (*models.ReadPosition, int64, error) {
+	seq, err := s.messages.Seq(ctx, groupChatID, messageID)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return nil
+	position, previousSeq, err := s.readPositions.Advance(ctx, models.ReadPosition{
+		ChatID:    groupChatID,
+		UserID:    userID,
+		MessageID: messageID,
+		Seq:       seq,
+		ReadAt:    time.Now(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if position.Seq > previousSeq {
+		if err := Realtime.Publish(GroupChatChannel(groupChatID), EventReadPositionChanged, position); err != nil {
+			log.Printf("Realtime publish failed: %v", err)
+		}
+	}
+	return position, previousSeq, nil
+}
+
+// countUnread counts the messages after the read position of the user. Users who have not
+// read the chat since read positions replaced the read status of each message are counted
+// with the read status.
+func (s *GroupChatService) countUnread(ctx context.Context, groupChatID, userID string) (int, error) {
+	position, err := s.readPositions.Get(ctx, groupChatID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return s.messages.CountUnread(ctx, groupChatID, userID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return s.messages.CountAfter(ctx, groupChatID, position.Seq)
 }
 
 func (s *GroupChatService) CountUnreadMessagesService(ctx context.Context, groupChatID, projectID, userID string) (int, error) {
@@ -445,41 +493,15 @@ func (s *GroupChatService) CountUnreadMessagesService(ctx context.Context, group
 		return 0, fmt.Errorf("userID is required")
 	}
 
-	var docRef *firestore.DocumentRef
-	var docSnap *firestore.DocumentSnapshot
-	var err error
-
-	// Fetch the group chat document
-	if groupChatID != "" {
-		docRef = s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-		docSnap, err = docRef.Get(ctx)
-	} else if projectID != "" {
-		query := s.firestoreClient.Collection("group_chats").Where("project_id", "==", projectID).Limit(1)
-		iter := query.Documents(ctx)
-		docSnap, err = iter.Next()
-	}
-
+	// Fetch the group chat
+	groupChat, err := s.getGroupChat(ctx, groupChatID, projectID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return 0, fmt.Errorf("group chat not found")
-	}
-
-	// Retrieve existing messages
-	messages := mappers.GetBaseMessagesArrayFromFirestore(data, "messages")
-	if len(messages) == 0 {
-		return 0, nil // No messages means no unread messages
-	}
-
-	// Count unread messages for the user
-	unreadCount := 0
-	for _, message := range messages {
-		if read, ok := message.ReadStatus[userID]; !ok || !read {
-			unreadCount++
-		}
+	unreadCount, err := s.countUnread(ctx, groupChat.ID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %v", err)
 	}
 
 	return unreadCount, nil
@@ -491,44 +513,19 @@ func (s *GroupChatService) CountUnreadGroupMessagesFromAllChatService(ctx contex
 		return 0, fmt.Errorf("userID is required")
 	}
 
-	// Query all group chats
-	query := s.firestoreClient.Collection("group_chats")
-	docs, err := query.Documents(ctx).GetAll()
+	// Fetch the group chats the user takes part in
+	chats, err := s.groupChats.ListByParticipant(ctx, userID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch group chats: %v", err)
 	}
 
 	totalUnreadCount := 0
-
-	// Iterate through each group chat
-	for _, doc := range docs {
-		data := doc.Data()
-		if data == nil {
-			continue
-		}
-
-		// Check if user is a participant in this group chat
-		participants := mappers.GetParticipantsGoArray(data, "participants")
-		isParticipant := false
-		for _, participant := range participants {
-			if participant.UserID == userID {
-				isParticipant = true
-				break
-			}
-		}
-
-		// Skip if user is not a participant
-		if !isParticipant {
-			continue
-		}
-
-		// Get messages and count unread ones
-		messages := mappers.GetBaseMessagesArrayFromFirestore(data, "messages")
-		for _, message := range messages {
-			if read, ok := message.ReadStatus[userID]; !ok || !read {
-				totalUnreadCount++
-			}
+	for _, chat := range chats {
+		unreadCount, err := s.countUnread(ctx, chat.ID, userID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count unread messages: %v", err)
 		}
+		totalUnreadCount += unreadCount
 	}
 
 	return totalUnreadCount, nil
@@ -545,26 +542,19 @@ func (s *GroupChatService) RemoveParticipantsFromGroupChatService(ctx context.Co
 		return fmt.Errorf("participantIDs list is required")
 	}
 
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return fmt.Errorf("group chat not found")
-	}
-
 	// Retrieve existing participants
-	existingParticipants := mappers.GetParticipantsGoArray(data, "participants")
-	if len(existingParticipants) == 0 {
+	if len(groupChat.Participants) == 0 {
 		return fmt.Errorf("no participants found in the group chat")
 	}
 
 	// Check if the owner has the required role
 	isOwner := false
-	for _, participant := range existingParticipants {
+	for _, participant := range groupChat.Participants {
 		if participant.UserID == ownerID && participant.Role == "owner" {
 			isOwner = true
 			break
@@ -581,28 +571,28 @@ func (s *GroupChatService) RemoveParticipantsFromGroupChatService(ctx context.Co
 	}
 
 	// Filter out participants that need to be removed
-	updatedParticipants := []models.Participant{}
-	removedCount := 0
-	for _, participant := range existingParticipants {
-		if _, found := participantIDsToRemove[participant.UserID]; found {
-			removedCount++
-			continue
+	errNoneRemoved := errors.New("none of the provided participant IDs were found in the group chat")
+	_, err = s.groupChats.Update(ctx, groupChatID, func(chat *models.GroupChat) error {
+		updatedParticipants := []models.Participant{}
+		for _, participant := range chat.Participants {
+			if _, found := participantIDsToRemove[participant.UserID]; found {
+				continue
+			}
+			updatedParticipants = append(updatedParticipants, participant)
 		}
-		updatedParticipants = append(updatedParticipants, participant)
-	}
-
-	if removedCount == 0 {
-		return fmt.Errorf("none of the provided participant IDs were found in the group chat")
-	}
 
-	// Map the updated participants to Firestore format
-	participantsFirestore := mappers.MapParticipantsArrayToFirestore(updatedParticipants)
+		if len(updatedParticipants) == len(chat.Participants) {
+			return errNoneRemoved
+		}
 
-	// Update the Firestore document
-	if _, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "participants", Value: participantsFirestore},
-		{Path: "updated_at", Value: time.Now()},
-	}); err != nil {
+		chat.Participants = updatedParticipants
+		chat.UpdatedAt = time.Now()
+		return nil
+	})
+	if errors.Is(err, errNoneRemoved) {
+		return err
+	}
+	if err != nil {
 		return fmt.Errorf("failed to update group chat participants: %v", err)
 	}
 
@@ -624,77 +614,29 @@ func (s *GroupChatService) ReplyToMessageService(ctx context.Context, groupChatI
 		return nil, fmt.Errorf("messageIDToReply is required")
 	}
 
-	// Fetch the group chat document
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	// Fetch the group chat
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return nil, fmt.Errorf("group chat not found")
-	}
-
-	// Retrieve existing messages
-	messages := mappers.GetBaseMessagesArrayFromFirestore(data, "messages")
-	if len(messages) == 0 {
-		return nil, fmt.Errorf("no messages found in the group chat")
-	}
-
 	// Find the message being replied to
-	var repliedMessage *models.BaseMessage
-	for _, msg := range messages {
-		if msg.ID == messageIDToReply {
-			repliedMessage = &msg
-			break
+	if _, err := s.messages.Get(ctx, groupChat.ID, messageIDToReply); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("message with ID %s not found", messageIDToReply)
 		}
-	}
-	if repliedMessage == nil {
-		return nil, fmt.Errorf("message with ID %s not found", messageIDToReply)
+		return nil, fmt.Errorf("failed to fetch message: %v", err)
 	}
 
-	// Retrieve participants to set read statuses
-	participants := mappers.GetParticipantsGoArray(data, "participants")
-	if len(participants) == 0 {
+	if len(groupChat.Participants) == 0 {
 		return nil, fmt.Errorf("no participants found in the group chat")
 	}
 
-	readStatus := make(map[string]bool)
-	for _, participant := range participants {
-		if participant.UserID == senderID {
-			readStatus[participant.UserID] = true
-		} else {
-			readStatus[participant.UserID] = false
-		}
-	}
-
 	// Create the reply message
-	replyMessage := models.BaseMessage{
-		ID:          uuid.New().String(),
-		SenderID:    senderID,
-		SenderName:  senderName,
-		Content:     content,
-		CreatedAt:   time.Now().Format(time.RFC3339),
-		ReadStatus:  readStatus,
-		IsDeleted:   false,
-		Attachments: []string{},
-		Reactions:   make(map[string]int),
-		MessageType: "reply",
-		ReplyToID:   &messageIDToReply, // Reference to the original message
-	}
-
-	// Append the reply message to the group chat
-	messages = append(messages, replyMessage)
-
-	// Prepare Firestore payload
-	firestorePayload := map[string]interface{}{
-		"messages":   mappers.MapBaseMessagesArrayToFirestore(messages),
-		"updated_at": time.Now(),
-	}
+	replyMessage := newGroupMessage(senderID, senderName, content, "reply", []string{})
+	replyMessage.ReplyToID = &messageIDToReply // Reference to the original message
 
-	// Update Firestore
-	if _, err := docRef.Set(ctx, firestorePayload, firestore.MergeAll); err != nil {
+	if err := s.appendGroupMessage(ctx, groupChat, replyMessage); err != nil {
 		return nil, fmt.Errorf("failed to update group chat with the reply: %v", err)
 	}
 
@@ -720,18 +662,12 @@ func (s *GroupChatService) AttachFilesToMessageService(ctx context.Context, grou
 		return nil, fmt.Errorf("cloudinary client not initialized")
 	}
 
-	// Fetch the group chat document
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	// Fetch the group chat
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return nil, fmt.Errorf("group chat not found")
-	}
-
 	// Upload files to Cloudinary
 	var attachments []string
 	for _, fileHeader := range files {
@@ -769,49 +705,14 @@ func (s *GroupChatService) AttachFilesToMessageService(ctx context.Context, grou
 		attachments = append(attachments, uploadResult.SecureURL)
 	}
 
-	// Retrieve participants to set read statuses
-	participants := mappers.GetParticipantsGoArray(data, "participants")
-	if len(participants) == 0 {
+	if len(groupChat.Participants) == 0 {
 		return nil, fmt.Errorf("no participants found in the group chat")
 	}
 
-	readStatus := make(map[string]bool)
-	for _, participant := range participants {
-		if participant.UserID == senderID {
-			readStatus[participant.UserID] = true
-		} else {
-			readStatus[participant.UserID] = false
-		}
-	}
-
 	// Create the message
-	message := models.BaseMessage{
-		ID:          uuid.New().String(),
-		SenderID:    senderID,
-		SenderName:  senderName,
-		Content:     content,
-		CreatedAt:   time.Now().Format(time.RFC3339),
-		ReadStatus:  readStatus,
-		IsDeleted:   false,
-		Attachments: attachments,
-		Reactions:   make(map[string]int),
-		MessageType: "attachment",
-	}
+	message := newGroupMessage(senderID, senderName, content, "attachment", attachments)
 
-	// Append the message to the group chat
-	messages := mappers.GetBaseMessagesArrayFromFirestore(data, "messages")
-	if messages == nil {
-		messages = []models.BaseMessage{}
-	}
-	messages = append(messages, message)
-
-	// Update Firestore
-	firestorePayload := map[string]interface{}{
-		"messages":   mappers.MapBaseMessagesArrayToFirestore(messages),
-		"updated_at": time.Now(),
-	}
-
-	if _, err := docRef.Set(ctx, firestorePayload, firestore.MergeAll); err != nil {
+	if err := s.appendGroupMessage(ctx, groupChat, message); err != nil {
 		return nil, fmt.Errorf("failed to update group chat with new message: %v", err)
 	}
 
@@ -837,6 +738,25 @@ func hasExtension(filename string, extensions []string) bool {
 	return false
 }
 
+// isGroupChatAdmin reports whether the user is an owner or an admin of the group chat
+func isGroupChatAdmin(participants []models.Participant, userID string) bool {
+	for _, participant := range participants {
+		if participant.UserID == userID && (participant.Role == "owner" || participant.Role == "admin") {
+			return true
+		}
+	}
+	return false
+}
+
+func isGroupChatParticipant(participants []models.Participant, userID string) bool {
+	for _, participant := range participants {
+		if participant.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *GroupChatService) PinMessageService(ctx context.Context, groupChatID, userID, messageID string) error {
 	// Validate required parameters
 	if groupChatID == "" {
@@ -849,63 +769,39 @@ func (s *GroupChatService) PinMessageService(ctx context.Context, groupChatID, u
 		return fmt.Errorf("messageID is required")
 	}
 
-	// Fetch the group chat document
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	// Fetch the group chat
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return fmt.Errorf("group chat not found")
-	}
-
-	// Retrieve participants to check user permissions
-	participants := mappers.GetParticipantsGoArray(data, "participants")
-	isAuthorized := false
-	for _, participant := range participants {
-		if participant.UserID == userID && (participant.Role == "owner" || participant.Role == "admin") {
-			isAuthorized = true
-			break
-		}
-	}
-
-	if !isAuthorized {
+	// Check user permissions
+	if !isGroupChatAdmin(groupChat.Participants, userID) {
 		return fmt.Errorf("only an owner or admin can pin messages")
 	}
 
-	// Retrieve existing messages and pinned messages
-	messages := mappers.GetBaseMessagesArrayFromFirestore(data, "messages")
-	pinnedMessages := mappers.GetStringArray(data, "pinned_messages")
-
 	// Check if the message exists
-	var messageExists bool
-	for _, msg := range messages {
-		if msg.ID == messageID {
-			messageExists = true
-			break
+	if _, err := s.messages.Get(ctx, groupChat.ID, messageID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("message with ID %s not found", messageID)
 		}
-	}
-	if !messageExists {
-		return fmt.Errorf("message with ID %s not found", messageID)
+		return fmt.Errorf("failed to fetch message: %v", err)
 	}
 
 	// Check if the message is already pinned
-	for _, pinnedMessage := range pinnedMessages {
+	for _, pinnedMessage := range groupChat.PinnedMessages {
 		if pinnedMessage == messageID {
 			return fmt.Errorf("message with ID %s is already pinned", messageID)
 		}
 	}
 
 	// Pin the message
-	pinnedMessages = append(pinnedMessages, messageID)
-
-	// Update Firestore
-	if _, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "pinned_messages", Value: pinnedMessages},
-		{Path: "updated_at", Value: time.Now()},
-	}); err != nil {
+	_, err = s.groupChats.Update(ctx, groupChatID, func(chat *models.GroupChat) error {
+		chat.PinnedMessages = append(chat.PinnedMessages, messageID)
+		chat.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to pin message: %v", err)
 	}
 
@@ -918,41 +814,28 @@ func (s *GroupChatService) GetPinnedMessagesService(ctx context.Context, groupCh
 		return nil, fmt.Errorf("groupChatID is required")
 	}
 
-	// Fetch the group chat document
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	// Fetch the group chat
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return nil, fmt.Errorf("group chat not found")
-	}
-
 	// Retrieve pinned messages
-	pinnedMessageIDs := mappers.GetStringArray(data, "pinned_messages")
-	if len(pinnedMessageIDs) == 0 {
+	if len(groupChat.PinnedMessages) == 0 {
 		return []models.BaseMessage{}, nil // No pinned messages
 	}
 
-	// Retrieve all messages
-	messages := mappers.GetBaseMessagesArrayFromFirestore(data, "messages")
-	if len(messages) == 0 {
-		return nil, fmt.Errorf("no messages found in the group chat")
-	}
-
-	// Filter messages to include only pinned messages
+	// Fetch the pinned messages that still exist
 	var pinnedMessages []models.BaseMessage
-	messageMap := make(map[string]models.BaseMessage)
-	for _, message := range messages {
-		messageMap[message.ID] = message
-	}
-
-	for _, pinnedID := range pinnedMessageIDs {
-		if pinnedMessage, exists := messageMap[pinnedID]; exists {
-			pinnedMessages = append(pinnedMessages, pinnedMessage)
+	for _, pinnedID := range groupChat.PinnedMessages {
+		pinnedMessage, err := s.messages.Get(ctx, groupChat.ID, pinnedID)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
 		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch pinned message: %v", err)
+		}
+		pinnedMessages = append(pinnedMessages, *pinnedMessage)
 	}
 
 	return pinnedMessages, nil
@@ -970,58 +853,46 @@ func (s *GroupChatService) UnpinMessageService(ctx context.Context, groupChatID,
 		return fmt.Errorf("messageID is required")
 	}
 
-	// Fetch the group chat document
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	// Fetch the group chat
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return fmt.Errorf("group chat not found")
-	}
-
-	// Retrieve participants to check user permissions
-	participants := mappers.GetParticipantsGoArray(data, "participants")
-	isAuthorized := false
-	for _, participant := range participants {
-		if participant.UserID == userID && (participant.Role == "owner" || participant.Role == "admin") {
-			isAuthorized = true
-			break
-		}
-	}
-
-	if !isAuthorized {
+	// Check user permissions
+	if !isGroupChatAdmin(groupChat.Participants, userID) {
 		return fmt.Errorf("only an owner or admin can unpin messages")
 	}
 
-	// Retrieve pinned messages
-	pinnedMessages := mappers.GetStringArray(data, "pinned_messages")
-	if len(pinnedMessages) == 0 {
+	if len(groupChat.PinnedMessages) == 0 {
 		return fmt.Errorf("no pinned messages to unpin")
 	}
 
-	// Remove the message ID from pinned messages
-	var updatedPinnedMessages []string
 	messageFound := false
-	for _, pinnedID := range pinnedMessages {
+	for _, pinnedID := range groupChat.PinnedMessages {
 		if pinnedID == messageID {
 			messageFound = true
-			continue
+			break
 		}
-		updatedPinnedMessages = append(updatedPinnedMessages, pinnedID)
 	}
 
 	if !messageFound {
 		return fmt.Errorf("message with ID %s is not pinned", messageID)
 	}
 
-	// Update Firestore
-	if _, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "pinned_messages", Value: updatedPinnedMessages},
-		{Path: "updated_at", Value: time.Now()},
-	}); err != nil {
+	// Remove the message ID from pinned messages
+	_, err = s.groupChats.Update(ctx, groupChatID, func(chat *models.GroupChat) error {
+		updatedPinnedMessages := []string{}
+		for _, pinnedID := range chat.PinnedMessages {
+			if pinnedID != messageID {
+				updatedPinnedMessages = append(updatedPinnedMessages, pinnedID)
+			}
+		}
+		chat.PinnedMessages = updatedPinnedMessages
+		chat.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to unpin message: %v", err)
 	}
 
@@ -1033,31 +904,22 @@ func (s *GroupChatService) ReactToMessageService(ctx context.Context, groupChatI
 		return fmt.Errorf("all parameters (groupChatID, userID, messageID, reaction) are required")
 	}
 
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	messages := mappers.GetBaseMessagesArrayFromFirestore(data, "messages")
-	for i, msg := range messages {
-		if msg.ID == messageID {
-			if msg.Reactions == nil {
-				msg.Reactions = map[string]int{}
-			}
-			msg.Reactions[reaction]++
-			messages[i] = msg
-			break
+	_, err = s.messages.Update(ctx, groupChat.ID, messageID, func(message *models.BaseMessage) error {
+		if message.Reactions == nil {
+			message.Reactions = map[string]int{}
 		}
+		message.Reactions[reaction]++
+		return nil
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("message with ID %s not found", messageID)
 	}
-
-	firestorePayload := map[string]interface{}{
-		"messages":   mappers.MapBaseMessagesArrayToFirestore(messages),
-		"updated_at": time.Now(),
-	}
-
-	if _, err := docRef.Set(ctx, firestorePayload, firestore.MergeAll); err != nil {
+	if err != nil {
 		return fmt.Errorf("failed to update message reactions: %v", err)
 	}
 
@@ -1069,60 +931,63 @@ func (s *GroupChatService) GetMessageReadReceiptsService(ctx context.Context, gr
 		return nil, fmt.Errorf("groupChatID and messageID are required")
 	}
 
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	messages := mappers.GetBaseMessagesArrayFromFirestore(data, "messages")
-	for _, msg := range messages {
-		if msg.ID == messageID {
-			return msg.ReadStatus, nil
-		}
+	message, err := s.messages.Get(ctx, groupChat.ID, messageID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("message not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch message: %v", err)
+	}
+	seq, err := s.messages.Seq(ctx, groupChat.ID, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch message: %v", err)
+	}
+
+	positions, err := s.readPositions.ListByChat(ctx, groupChat.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch read positions: %v", err)
 	}
 
-	return nil, fmt.Errorf("message not found")
+	// The participants read the message when their read position is past it, or those
+	// without one when they sent it or its legacy read status says so
+	receipts := make(map[string]bool, len(groupChat.Participants))
+	for _, participant := range groupChat.Participants {
+		receipts[participant.UserID] = participant.UserID == message.SenderID || message.ReadStatus[participant.UserID]
+	}
+	for _, position := range positions {
+		if isGroupChatParticipant(groupChat.Participants, position.UserID) {
+			receipts[position.UserID] = position.Seq >= seq
+		}
+	}
+	return receipts, nil
 }
 
 func (s *GroupChatService) SetParticipantRoleService(ctx context.Context, groupChatID, userID, participantID, newRole string) error {
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	participants := mappers.GetParticipantsGoArray(data, "participants")
-	isAdmin := false
-	for _, participant := range participants {
-		if participant.UserID == userID && (participant.Role == "owner" || participant.Role == "admin") {
-			isAdmin = true
-			break
-		}
-	}
-
-	if !isAdmin {
+	if !isGroupChatAdmin(groupChat.Participants, userID) {
 		return fmt.Errorf("only admins or owners can set roles")
 	}
 
-	for i, participant := range participants {
-		if participant.UserID == participantID {
-			participants[i].Role = newRole
-			break
+	_, err = s.groupChats.Update(ctx, groupChatID, func(chat *models.GroupChat) error {
+		for i, participant := range chat.Participants {
+			if participant.UserID == participantID {
+				chat.Participants[i].Role = newRole
+				break
+			}
 		}
-	}
-
-	firestorePayload := map[string]interface{}{
-		"participants": mappers.MapParticipantsArrayToFirestore(participants),
-		"updated_at":   time.Now(),
-	}
-
-	if _, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "participants", Value: firestorePayload["participants"]},
-		{Path: "updated_at", Value: firestorePayload["updated_at"]},
-	}); err != nil {
+		chat.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update participant role: %v", err)
 	}
 
@@ -1134,74 +999,38 @@ func (s *GroupChatService) MuteParticipantService(ctx context.Context, groupChat
 		return fmt.Errorf("groupChatID, userID, and participantID are required")
 	}
 
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	participants := mappers.GetParticipantsGoArray(data, "participants")
-
-	isAdmin := false
-	for _, participant := range participants {
-		if participant.UserID == userID && (participant.Role == "owner" || participant.Role == "admin") {
-			isAdmin = true
-			break
-		}
-	}
-
-	if !isAdmin {
+	if !isGroupChatAdmin(groupChat.Participants, userID) {
 		return fmt.Errorf("only admins or owners can mute participants")
 	}
 
-	for i, participant := range participants {
-		if participant.UserID == participantID {
-			participants[i].MutedUntil = time.Now().Add(duration)
-			break
+	_, err = s.groupChats.Update(ctx, groupChatID, func(chat *models.GroupChat) error {
+		for i, participant := range chat.Participants {
+			if participant.UserID == participantID {
+				chat.Participants[i].MutedUntil = time.Now().Add(duration)
+				break
+			}
 		}
-	}
-
-	firestorePayload := map[string]interface{}{
-		"participants": mappers.MapParticipantsArrayToFirestore(participants),
-		"updated_at":   time.Now(),
-	}
-
-	if _, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "participants", Value: firestorePayload["participants"]},
-		{Path: "updated_at", Value: firestorePayload["updated_at"]},
-	}); err != nil {
-		return fmt.Errorf("failed to mute participant: %v", err)
-	}
-
-	return nil
-}
-
-func (s *GroupChatService) UpdateLastSeenService(ctx context.Context, groupChatID, userID string) error {
-	if groupChatID == "" || userID == "" {
-		return fmt.Errorf("groupChatID and userID are required")
-	}
-
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	_, err := docRef.Update(ctx, []firestore.Update{
-		{Path: fmt.Sprintf("last_seen.%s", userID), Value: time.Now()},
-		{Path: "updated_at", Value: time.Now()},
+		chat.UpdatedAt = time.Now()
+		return nil
 	})
-
 	if err != nil {
-		return fmt.Errorf("failed to update last seen status: %v", err)
+		return fmt.Errorf("failed to mute participant: %v", err)
 	}
 
 	return nil
 }
 
 func (s *GroupChatService) ArchiveGroupChatService(ctx context.Context, groupChatID, userID string) error {
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	_, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "is_archived", Value: true},
-		{Path: "updated_at", Value: time.Now()},
+	_, err := s.groupChats.Update(ctx, groupChatID, func(chat *models.GroupChat) error {
+		chat.IsArchived = true
+		chat.UpdatedAt = time.Now()
+		return nil
 	})
-
 	if err != nil {
 		return fmt.Errorf("failed to archive group chat: %v", err)
 	}
@@ -1214,18 +1043,14 @@ func (s *GroupChatService) LeaveGroupService(ctx context.Context, groupChatID, u
 		return fmt.Errorf("groupChatID and userID are required")
 	}
 
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	participants := mappers.GetParticipantsGoArray(data, "participants")
-
 	updatedParticipants := []models.Participant{}
 	userFound := false
-	for _, participant := range participants {
+	for _, participant := range groupChat.Participants {
 		if participant.UserID == userID {
 			userFound = true
 			continue
@@ -1239,20 +1064,21 @@ func (s *GroupChatService) LeaveGroupService(ctx context.Context, groupChatID, u
 
 	if len(updatedParticipants) == 0 {
 		// If no participants remain, delete the group chat
-		_, err := docRef.Delete(ctx)
-		return err
+		return s.deleteGroupChat(ctx, groupChat.ID)
 	}
 
-	// Update Firestore with the updated participants
-	firestorePayload := map[string]interface{}{
-		"participants": mappers.MapParticipantsArrayToFirestore(updatedParticipants),
-		"updated_at":   time.Now(),
-	}
-
-	if _, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "participants", Value: firestorePayload["participants"]},
-		{Path: "updated_at", Value: firestorePayload["updated_at"]},
-	}); err != nil {
+	_, err = s.groupChats.Update(ctx, groupChatID, func(chat *models.GroupChat) error {
+		participants := []models.Participant{}
+		for _, participant := range chat.Participants {
+			if participant.UserID != userID {
+				participants = append(participants, participant)
+			}
+		}
+		chat.Participants = participants
+		chat.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update group chat participants: %v", err)
 	}
 
@@ -1264,16 +1090,13 @@ func (s *GroupChatService) CreatePollService(ctx context.Context, groupChatID, u
 		return nil, fmt.Errorf("groupChatID and userID are required")
 	}
 
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	participants := mappers.GetParticipantsGoArray(data, "participants")
 	userFound := false
-	for _, participant := range participants {
+	for _, participant := range groupChat.Participants {
 		if participant.UserID == userID {
 			userFound = true
 			break
@@ -1288,15 +1111,12 @@ func (s *GroupChatService) CreatePollService(ctx context.Context, groupChatID, u
 	poll.CreatedBy = userID
 	poll.CreatedAt = time.Now()
 
-	firestorePayload := map[string]interface{}{
-		"polls":      append(mappers.GetPollsArray(data, "polls"), poll),
-		"updated_at": time.Now(),
-	}
-
-	if _, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "polls", Value: firestorePayload["polls"]},
-		{Path: "updated_at", Value: firestorePayload["updated_at"]},
-	}); err != nil {
+	_, err = s.groupChats.Update(ctx, groupChatID, func(chat *models.GroupChat) error {
+		chat.Polls = append(chat.Polls, poll)
+		chat.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to create poll: %v", err)
 	}
 
@@ -1308,15 +1128,10 @@ func (s *GroupChatService) ReportMessageService(ctx context.Context, groupChatID
 		return fmt.Errorf("all fields are required")
 	}
 
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
-	if err != nil {
+	if _, err := s.groupChats.Get(ctx, groupChatID); err != nil {
 		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	reports := mappers.GetReportsArray(data, "reports")
-
 	report := models.Report{
 		ID:         uuid.New().String(),
 		MessageID:  messageID,
@@ -1325,17 +1140,12 @@ func (s *GroupChatService) ReportMessageService(ctx context.Context, groupChatID
 		CreatedAt:  time.Now(),
 	}
 
-	reports = append(reports, report)
-
-	firestorePayload := map[string]interface{}{
-		"reports":    mappers.MapReportsArrayToFirestore(reports),
-		"updated_at": time.Now(),
-	}
-
-	if _, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "reports", Value: firestorePayload["reports"]},
-		{Path: "updated_at", Value: firestorePayload["updated_at"]},
-	}); err != nil {
+	_, err := s.groupChats.Update(ctx, groupChatID, func(chat *models.GroupChat) error {
+		chat.Reports = append(chat.Reports, report)
+		chat.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to report message: %v", err)
 	}
 
@@ -1348,40 +1158,23 @@ func (s *GroupChatService) UpdateGroupSettingsService(ctx context.Context, group
 		return fmt.Errorf("groupChatID and userID are required")
 	}
 
-	// Fetch the group chat document
-	docRef := s.firestoreClient.Collection("group_chats").Doc(groupChatID)
-	docSnap, err := docRef.Get(ctx)
+	// Fetch the group chat
+	groupChat, err := s.groupChats.Get(ctx, groupChatID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	data := docSnap.Data()
-	if data == nil {
-		return fmt.Errorf("group chat not found")
-	}
-
-	// Retrieve participants to check user permissions
-	participants := mappers.GetParticipantsGoArray(data, "participants")
-	isAuthorized := false
-	for _, participant := range participants {
-		if participant.UserID == userID && (participant.Role == "owner" || participant.Role == "admin") {
-			isAuthorized = true
-			break
-		}
-	}
-
-	if !isAuthorized {
+	// Check user permissions
+	if !isGroupChatAdmin(groupChat.Participants, userID) {
 		return fmt.Errorf("only an owner or admin can update group settings")
 	}
 
-	// Map updated settings to Firestore format
-	settingsFirestore := mappers.MapGroupSettingsGoToFirestore(updatedSettings)
-
-	// Update the Firestore document
-	if _, err := docRef.Update(ctx, []firestore.Update{
-		{Path: "group_settings", Value: settingsFirestore},
-		{Path: "updated_at", Value: time.Now()},
-	}); err != nil {
+	_, err = s.groupChats.Update(ctx, groupChatID, func(chat *models.GroupChat) error {
+		chat.GroupSettings = updatedSettings
+		chat.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update group chat settings: %v", err)
 	}
 
@@ -1424,86 +1217,67 @@ func (s *GroupChatService) UpdateGroupSettingsService(ctx context.Context, group
 // 	return nil
 // }
 
-// DeleteGroupChatService deletes a single group chat
-func (s *GroupChatService) DeleteGroupChatService(ctx context.Context, chatID string, userID string) error {
-	doc, err := s.firestoreClient.Collection("group_chats").Doc(chatID).Get(ctx)
-	if err != nil {
-		if status.Code(err) == codes.NotFound {
-			return fmt.Errorf("group chat not found")
+// isGroupChatOwner reports whether the user owns the group chat
+func isGroupChatOwner(chat *models.GroupChat, userID string) bool {
+	for _, participant := range chat.Participants {
+		if participant.UserID == userID && participant.Role == "owner" {
+			return true
 		}
-		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
+	return false
+}
 
-	data := doc.Data()
-	participants, ok := data["participants"].([]interface{})
-	if !ok {
-		return fmt.Errorf("invalid participants data")
+// deleteGroupChat deletes a group chat together with its message history
+func (s *GroupChatService) deleteGroupChat(ctx context.Context, chatID string) error {
+	if err := s.messages.DeleteAll(ctx, chatID); err != nil {
+		return err
+	}
+	if err := s.readPositions.DeleteByChat(ctx, chatID); err != nil {
+		return err
 	}
+	return s.groupChats.Delete(ctx, chatID)
+}
 
-	isOwner := false
-	for _, p := range participants {
-		participant, ok := p.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		if participant["user_id"] == userID && participant["role"] == "owner" {
-			isOwner = true
-			break
+// DeleteGroupChatService deletes a single group chat
+func (s *GroupChatService) DeleteGroupChatService(ctx context.Context, chatID string, userID string) error {
+	groupChat, err := s.groupChats.Get(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("group chat not found")
 		}
+		return fmt.Errorf("failed to fetch group chat: %v", err)
 	}
 
-	if !isOwner {
+	if !isGroupChatOwner(groupChat, userID) {
 		return fmt.Errorf("unauthorized: only the owner can delete the group chat")
 	}
 
-	_, err = s.firestoreClient.Collection("group_chats").Doc(chatID).Delete(ctx)
-	if err != nil {
+	if err := s.deleteGroupChat(ctx, groupChat.ID); err != nil {
 		return fmt.Errorf("failed to delete group chat: %v", err)
 	}
 
 	return nil
 }
 
-// DeleteMultipleGroupChatsService deletes multiple group chats
+// DeleteMultipleGroupChatsService deletes multiple group chats.
+// Nothing is deleted unless the user owns every one of them.
 func (s *GroupChatService) DeleteMultipleGroupChatsService(ctx context.Context, chatIDs []string, userID string) error {
-	batch := s.firestoreClient.Batch()
 	unauthorized := make([]string, 0)
 	notFound := make([]string, 0)
 
 	for _, chatID := range chatIDs {
-		doc, err := s.firestoreClient.Collection("group_chats").Doc(chatID).Get(ctx)
+		groupChat, err := s.groupChats.Get(ctx, chatID)
 		if err != nil {
-			if status.Code(err) == codes.NotFound {
+			if errors.Is(err, repository.ErrNotFound) {
 				notFound = append(notFound, chatID)
 				continue
 			}
 			return fmt.Errorf("failed to fetch group chat %s: %v", chatID, err)
 		}
 
-		data := doc.Data()
-		participants, ok := data["participants"].([]interface{})
-		if !ok {
-			return fmt.Errorf("invalid participants data in chat %s", chatID)
-		}
-
-		isOwner := false
-		for _, p := range participants {
-			participant, ok := p.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			if participant["user_id"] == userID && participant["role"] == "owner" {
-				isOwner = true
-				break
-			}
-		}
-
-		if !isOwner {
+		if !isGroupChatOwner(groupChat, userID) {
 			unauthorized = append(unauthorized, chatID)
-			continue
 		}
-
-		batch.Delete(s.firestoreClient.Collection("group_chats").Doc(chatID))
 	}
 
 	if len(unauthorized) > 0 || len(notFound) > 0 {
@@ -1514,13 +1288,13 @@ func (s *GroupChatService) DeleteMultipleGroupChatsService(ctx context.Context,
 		if len(notFound) > 0 {
 			errMsg.WriteString(fmt.Sprintf("Chats not found: %v", notFound))
 		}
-		return fmt.Errorf(errMsg.String())
+		return errors.New(errMsg.String())
 	}
 
-	// Commit the batch
-	_, err := batch.Commit(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to delete group chats: %v", err)
+	for _, chatID := range chatIDs {
+		if err := s.deleteGroupChat(ctx, chatID); err != nil {
+			return fmt.Errorf("failed to delete group chats: %v", err)
+		}
 	}
 
 	return nil
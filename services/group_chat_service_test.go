@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTransport keeps the events delivered by the hub
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []RealtimeEvent
+}
+
+func (r *recordingTransport) Name() string { return "recording" }
+
+func (r *recordingTransport) Deliver(event RealtimeEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+// unreadDeltas sums the group chat unread count deltas published to each user
+func (r *recordingTransport) unreadDeltas() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deltas := map[string]int{}
+	for _, event := range r.events {
+		if delta, ok := event.Data.(models.UnreadCountDelta); ok && delta.Kind == UnreadGroupChats {
+			deltas[event.Channel] += delta.Delta
+		}
+	}
+	return deltas
+}
+
+// recordRealtime replaces the Realtime hub for the duration of the test
+func recordRealtime(t *testing.T) *recordingTransport {
+	t.Helper()
+	recorder := &recordingTransport{}
+	previous := Realtime
+	Realtime = NewRealtimeHub(recorder)
+	t.Cleanup(func() { Realtime = previous })
+	return recorder
+}
+
+func newTestGroupChatService(t *testing.T) (*GroupChatService, *repository.Repositories, *models.GroupChat) {
+	t.Helper()
+	repos := repository.NewMemoryRepositories()
+	chat := &models.GroupChat{
+		ID:   "chat-1",
+		Name: "Gophers",
+		Participants: []models.Participant{
+			{UserID: "ada", Role: "owner"},
+			{UserID: "bob", Role: "member"},
+			{UserID: "eve", Role: "member"},
+		},
+	}
+	require.NoError(t, repos.GroupChats.Create(context.Background(), chat))
+	return NewGroupChatService(repos.GroupChats, repos.GroupMessages, repos.ReadPositions), repos, chat
+}
+
+func TestGroupChatServiceMarkMessagesAsRead(t *testing.T) {
+	ctx := context.Background()
+	recorder := recordRealtime(t)
+	service, _, chat := newTestGroupChatService(t)
+
+	messages := []*models.BaseMessage{}
+	for _, content := range []string{"one", "two", "three", "four"} {
+		message, err := service.SendMessageService(ctx, chat.ID, "ada", "Ada", content)
+		require.NoError(t, err)
+		assert.Empty(t, message.ReadStatus)
+		messages = append(messages, message)
+	}
+
+	bob := UserNotificationsChannel("bob")
+	assert.Equal(t, map[string]int{bob: 4, UserNotificationsChannel("eve"): 4}, recorder.unreadDeltas())
+
+	steps := []struct {
+		name      string
+		messageID string
+		wantDelta int
+		wantSeq   int64
+	}{
+		{name: "first messages", messageID: messages[1].ID, wantDelta: -2, wantSeq: 2},
+		{name: "earlier message", messageID: messages[0].ID, wantDelta: 0, wantSeq: 2},
+		{name: "latest message", wantDelta: -2, wantSeq: 4},
+		{name: "again", wantDelta: 0, wantSeq: 4},
+	}
+
+	total := 4
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			position, err := service.MarkMessagesAsReadService(ctx, chat.ID, "bob", step.messageID)
+			require.NoError(t, err)
+			assert.Equal(t, step.wantSeq, position.Seq)
+
+			total += step.wantDelta
+			assert.Equal(t, total, recorder.unreadDeltas()[bob])
+
+			unread, err := service.countUnread(ctx, chat.ID, "bob")
+			require.NoError(t, err)
+			assert.Equal(t, total, unread)
+		})
+	}
+}
+
+func TestGroupChatServiceMarkMessagesAsReadWithoutReadPosition(t *testing.T) {
+	ctx := context.Background()
+	recorder := recordRealtime(t)
+	service, repos, chat := newTestGroupChatService(t)
+
+	// Messages stored before read positions carry the read status of each participant
+	for _, read := range []bool{true, true, false, false, false} {
+		message := &models.BaseMessage{SenderID: "ada", Content: "legacy", ReadStatus: map[string]bool{"ada": true, "bob": read}}
+		require.NoError(t, repos.GroupMessages.Append(ctx, chat.ID, message))
+	}
+	latest, err := repos.GroupMessages.List(ctx, chat.ID, repository.MessagePage{})
+	require.NoError(t, err)
+
+	_, err = service.MarkMessagesAsReadService(ctx, chat.ID, "bob", latest[3].ID)
+	require.NoError(t, err)
+	assert.Equal(t, -2, recorder.unreadDeltas()[UserNotificationsChannel("bob")])
+
+	unread, err := service.countUnread(ctx, chat.ID, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, 1, unread)
+}
+
+func TestGroupChatServiceReadReceipts(t *testing.T) {
+	ctx := context.Background()
+	recordRealtime(t)
+	service, _, chat := newTestGroupChatService(t)
+
+	first, err := service.SendMessageService(ctx, chat.ID, "ada", "Ada", "one")
+	require.NoError(t, err)
+	second, err := service.SendMessageService(ctx, chat.ID, "ada", "Ada", "two")
+	require.NoError(t, err)
+	_, err = service.MarkMessagesAsReadService(ctx, chat.ID, "bob", first.ID)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		messageID string
+		want      map[string]bool
+	}{
+		{name: "read by bob", messageID: first.ID, want: map[string]bool{"ada": true, "bob": true, "eve": false}},
+		{name: "after the position of bob", messageID: second.ID, want: map[string]bool{"ada": true, "bob": false, "eve": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipts, err := service.GetMessageReadReceiptsService(ctx, chat.ID, tt.messageID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, receipts)
+		})
+	}
+}
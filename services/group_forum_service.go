@@ -8,26 +8,22 @@ import (
 	"strings"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/google/uuid"
-	"github.com/rogerjeasy/go-letusconnect/mappers"
 	"github.com/rogerjeasy/go-letusconnect/models"
-	"google.golang.org/api/iterator"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 type GroupService struct {
-	firestoreClient  FirestoreClient
+	groups           repository.GroupRepository
 	cloudinaryClient *cloudinary.Cloudinary
 	userService      *UserService
 }
 
-func NewGroupService(fClient FirestoreClient, cClient *cloudinary.Cloudinary, uUserService *UserService) *GroupService {
+func NewGroupService(groups repository.GroupRepository, cClient *cloudinary.Cloudinary, uUserService *UserService) *GroupService {
 	return &GroupService{
-		firestoreClient:  fClient,
+		groups:           groups,
 		cloudinaryClient: cClient,
 		userService:      uUserService,
 	}
@@ -53,10 +49,7 @@ func (s *GroupService) CreateGroup(ctx context.Context, input models.Group, user
 	input.UpdatedAt = now
 	input.Admins = []*models.User{user}
 
-	groupData := mappers.MapGroupGoToFirestore(input)
-
-	_, err = s.firestoreClient.Collection("group_forums").Doc(input.ID).Set(ctx, groupData)
-	if err != nil {
+	if err := s.groups.Save(ctx, &input); err != nil {
 		return nil, fmt.Errorf("failed to create group: %v", err)
 	}
 
@@ -65,23 +58,20 @@ func (s *GroupService) CreateGroup(ctx context.Context, input models.Group, user
 
 // GetGroup retrieves a group by ID
 func (s *GroupService) GetGroup(ctx context.Context, groupID string) (*models.Group, error) {
-	doc, err := s.firestoreClient.Collection("group_forums").Doc(groupID).Get(ctx)
+	group, err := s.groups.Get(ctx, groupID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get group: %v", err)
 	}
 
-	group := mappers.MapGroupFirestoreToGo(doc.Data())
-	return &group, nil
+	return group, nil
 }
 
 // UpdateGroup updates an existing group
 func (s *GroupService) UpdateGroup(ctx context.Context, groupID string, updates models.Group) error {
+	updates.ID = groupID
 	updates.UpdatedAt = time.Now()
 
-	updateData := mappers.MapGroupGoToFirestore(updates)
-
-	_, err := s.firestoreClient.Collection("group_forums").Doc(groupID).Set(ctx, updateData, firestore.MergeAll)
-	if err != nil {
+	if err := s.groups.Save(ctx, &updates); err != nil {
 		return fmt.Errorf("failed to update group: %v", err)
 	}
 
@@ -106,8 +96,7 @@ func (s *GroupService) DeleteGroup(ctx context.Context, groupID string, userID s
 		return errors.New("unauthorized: only group admins can delete the group")
 	}
 
-	_, err = s.firestoreClient.Collection("group_forums").Doc(groupID).Delete(ctx)
-	if err != nil {
+	if err := s.groups.Delete(ctx, groupID); err != nil {
 		return fmt.Errorf("failed to delete group: %v", err)
 	}
 
@@ -116,38 +105,9 @@ func (s *GroupService) DeleteGroup(ctx context.Context, groupID string, userID s
 
 // ListGroups retrieves all groups with optional filtering
 func (s *GroupService) ListGroups(ctx context.Context, filters map[string]interface{}) ([]models.Group, error) {
-	collRef := s.firestoreClient.Collection("group_forums")
-
-	var query firestore.Query
-	first := true
-	for key, value := range filters {
-		if first {
-			query = collRef.Where(key, "==", value)
-			first = false
-		} else {
-			query = query.Where(key, "==", value)
-		}
-	}
-
-	var iter *firestore.DocumentIterator
-	if first {
-		iter = collRef.Documents(ctx)
-	} else {
-		iter = query.Documents(ctx)
-	}
-
-	var groups []models.Group
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate groups: %v", err)
-		}
-
-		group := mappers.MapGroupFirestoreToGo(doc.Data())
-		groups = append(groups, group)
+	groups, err := s.groups.List(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate groups: %v", err)
 	}
 
 	return groups, nil
@@ -156,14 +116,12 @@ func (s *GroupService) ListGroups(ctx context.Context, filters map[string]interf
 func (s *GroupService) ListGroupsByUser(ctx context.Context, userID string) ([]models.Group, error) {
 	var groups []models.Group
 
-	docs, err := s.firestoreClient.Collection("group_forums").Documents(ctx).GetAll()
-	if err != nil && status.Code(err) != codes.NotFound {
+	allGroups, err := s.groups.List(ctx, nil)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get groups: %v", err)
 	}
 
-	for _, doc := range docs {
-		group := mappers.MapGroupFirestoreToGo(doc.Data())
-
+	for _, group := range allGroups {
 		for _, member := range group.Members {
 			if member.UserID == userID {
 				groups = append(groups, group)
@@ -367,20 +325,13 @@ func (s *GroupService) UpdateGroupSettings(ctx context.Context, groupID string,
 
 // SearchGroups searches for groups based on name or description
 func (s *GroupService) SearchGroups(ctx context.Context, query string) ([]models.Group, error) {
-	iter := s.firestoreClient.Collection("groups").Documents(ctx)
-	var groups []models.Group
-
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate groups: %v", err)
-		}
-
-		group := mappers.MapGroupFirestoreToGo(doc.Data())
+	allGroups, err := s.groups.List(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate groups: %v", err)
+	}
 
+	var groups []models.Group
+	for _, group := range allGroups {
 		// Simple case-insensitive search
 		if containsCaseInsensitive(group.Name, query) || containsCaseInsensitive(group.Description, query) {
 			groups = append(groups, group)
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"firebase.google.com/go/auth"
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+var (
+	// ErrInvalidCredentials is returned when a password does not match the account
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrExternalSignUpUnsupported is returned when registering a Google or GitHub account
+	// with a provider that cannot verify it; those users sign up through the OAuth flow
+	ErrExternalSignUpUnsupported = errors.New("sign up with Google or GitHub through POST /api/v1/auth/oauth/{provider}/authorize")
+)
+
+// PasswordProvider checks and changes the passwords of the users.
+// Passwords never reach the user documents: the provider owns them.
+type PasswordProvider interface {
+	// VerifyPassword returns ErrInvalidCredentials when the password is wrong
+	VerifyPassword(ctx context.Context, user *models.User, password string) error
+	SetPassword(ctx context.Context, user *models.User, password string) error
+	// HasPassword reports whether the user can sign in with a password
+	HasPassword(ctx context.Context, user *models.User) (bool, error)
+}
+
+// IdentityProvider creates the accounts of new users and owns their passwords
+type IdentityProvider interface {
+	PasswordProvider
+	// CreateAccount registers a new user and returns the UID of the account.
+	// Email users get a password; Google and GitHub users sign in with their provider.
+	CreateAccount(ctx context.Context, data models.ProviderData) (string, error)
+	// ExternalSubject returns the ID of the user at Google or GitHub, as verified by the
+	// identity provider, or "" when it does not know it
+	ExternalSubject(ctx context.Context, uid string, provider models.AuthProvider) (string, error)
+	// DeleteAccount removes the account and password of the user, when they exist
+	DeleteAccount(ctx context.Context, uid string) error
+}
+
+// FirebaseIdentityProvider keeps the accounts and passwords in Firebase Authentication
+type FirebaseIdentityProvider struct {
+	client *auth.Client
+	// signInURL is the signInWithPassword endpoint of the Identity Toolkit REST API
+	signInURL  string
+	httpClient *http.Client
+}
+
+func NewFirebaseIdentityProvider(client *auth.Client, signInURL string) *FirebaseIdentityProvider {
+	return &FirebaseIdentityProvider{
+		client:     client,
+		signInURL:  signInURL,
+		httpClient: http.DefaultClient,
+	}
+}
+
+// CreateAccount creates the Firebase account of an email user. Google and GitHub users
+// already have one, created by the Firebase client SDK when they signed in.
+func (p *FirebaseIdentityProvider) CreateAccount(ctx context.Context, data models.ProviderData) (string, error) {
+	var record *auth.UserRecord
+	var err error
+
+	switch data.ProviderType {
+	case models.EmailPassword:
+		record, err = p.client.CreateUser(ctx, (&auth.UserToCreate{}).
+			Email(data.Email).
+			Password(data.Password))
+	case models.Google, models.GitHub:
+		record, err = p.client.GetUserByEmail(ctx, data.Email)
+	default:
+		return "", fmt.Errorf("unsupported provider type: %s", data.ProviderType)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create Firebase account: %v", err)
+	}
+	return record.UID, nil
+}
+
+// VerifyPassword signs in with the email and password through the Firebase REST API
+func (p *FirebaseIdentityProvider) VerifyPassword(ctx context.Context, user *models.User, password string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"email":             user.Email,
+		"password":          password,
+		"returnSecureToken": true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode sign-in request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.signInURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create sign-in request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Firebase Authentication: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusBadRequest:
+		// Firebase answers 400 for unknown emails, wrong passwords and disabled accounts
+		return ErrInvalidCredentials
+	default:
+		return fmt.Errorf("firebase sign-in failed with status %d", resp.StatusCode)
+	}
+}
+
+// SetPassword replaces the password of the Firebase account
+func (p *FirebaseIdentityProvider) SetPassword(ctx context.Context, user *models.User, password string) error {
+	if _, err := p.client.UpdateUser(ctx, user.UID, (&auth.UserToUpdate{}).Password(password)); err != nil {
+		return fmt.Errorf("failed to update password: %v", err)
+	}
+	return nil
+}
+
+// HasPassword reports whether the Firebase account has the password sign-in method
+func (p *FirebaseIdentityProvider) HasPassword(ctx context.Context, user *models.User) (bool, error) {
+	record, err := p.client.GetUser(ctx, user.UID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch Firebase account: %v", err)
+	}
+	for _, info := range record.ProviderUserInfo {
+		if info.ProviderID == "password" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// firebaseProviderIDs names the Firebase sign-in methods of the providers
+var firebaseProviderIDs = map[models.AuthProvider]string{
+	models.Google: "google.com",
+	models.GitHub: "github.com",
+}
+
+// ExternalSubject reads the ID of the user at the provider from their Firebase account
+func (p *FirebaseIdentityProvider) ExternalSubject(ctx context.Context, uid string, provider models.AuthProvider) (string, error) {
+	providerID, ok := firebaseProviderIDs[provider]
+	if !ok {
+		return "", nil
+	}
+
+	record, err := p.client.GetUser(ctx, uid)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch Firebase account: %v", err)
+	}
+	for _, info := range record.ProviderUserInfo {
+		if info.ProviderID == providerID {
+			return info.UID, nil
+		}
+	}
+	return "", nil
+}
+
+// DeleteAccount deletes the Firebase account of the user
+func (p *FirebaseIdentityProvider) DeleteAccount(ctx context.Context, uid string) error {
+	if err := p.client.DeleteUser(ctx, uid); err != nil && !auth.IsUserNotFound(err) {
+		return fmt.Errorf("failed to delete Firebase account: %v", err)
+	}
+	return nil
+}
+
+// LocalIdentityProvider keeps argon2id or bcrypt password hashes in our own database,
+// so that the API runs without Firebase Authentication.
+type LocalIdentityProvider struct {
+	credentials repository.CredentialRepository
+	// algorithm hashes new passwords; hashes of the other algorithm are replaced on sign-in
+	algorithm string
+	// migrateFrom checks the passwords of users without a local hash. After a successful
+	// sign-in the password is hashed locally, so that users move over one by one.
+	migrateFrom PasswordProvider
+}
+
+func NewLocalIdentityProvider(credentials repository.CredentialRepository, algorithm string, migrateFrom PasswordProvider) *LocalIdentityProvider {
+	if algorithm != HashBcrypt {
+		algorithm = HashArgon2id
+	}
+	return &LocalIdentityProvider{
+		credentials: credentials,
+		algorithm:   algorithm,
+		migrateFrom: migrateFrom,
+	}
+}
+
+// CreateAccount assigns a new UID and stores the password hash of email users. Google and
+// GitHub users get ErrExternalSignUpUnsupported: nothing proves they own the account.
+func (p *LocalIdentityProvider) CreateAccount(ctx context.Context, data models.ProviderData) (string, error) {
+	switch data.ProviderType {
+	case models.EmailPassword:
+	case models.Google, models.GitHub:
+		return "", ErrExternalSignUpUnsupported
+	default:
+		return "", fmt.Errorf("unsupported provider type: %s", data.ProviderType)
+	}
+
+	uid := uuid.New().String()
+	if err := p.SetPassword(ctx, &models.User{UID: uid, Email: data.Email}, data.Password); err != nil {
+		return "", err
+	}
+	return uid, nil
+}
+
+func (p *LocalIdentityProvider) VerifyPassword(ctx context.Context, user *models.User, password string) error {
+	credential, err := p.credentials.Get(ctx, user.UID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return p.migrate(ctx, user, password)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch credential: %v", err)
+	}
+
+	match, rehash, err := checkPasswordHash(credential.PasswordHash, password, p.algorithm)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return ErrInvalidCredentials
+	}
+
+	// The password is known now: upgrade an outdated hash. Signing in does not depend on it.
+	if rehash {
+		if err := p.SetPassword(ctx, user, password); err != nil {
+			log.Printf("Failed to rehash the password of user %s: %v", user.UID, err)
+		}
+	}
+	return nil
+}
+
+// migrate checks the password of a user without a local hash with the previous
+// provider, and stores a local hash when it matches
+func (p *LocalIdentityProvider) migrate(ctx context.Context, user *models.User, password string) error {
+	if p.migrateFrom == nil {
+		return ErrInvalidCredentials
+	}
+
+	if err := p.migrateFrom.VerifyPassword(ctx, user, password); err != nil {
+		return err
+	}
+
+	if err := p.SetPassword(ctx, user, password); err != nil {
+		log.Printf("Failed to migrate the password of user %s: %v", user.UID, err)
+	}
+	return nil
+}
+
+func (p *LocalIdentityProvider) SetPassword(ctx context.Context, user *models.User, password string) error {
+	hash, err := hashPassword(password, p.algorithm)
+	if err != nil {
+		return err
+	}
+
+	credential := models.Credential{
+		UID:          user.UID,
+		PasswordHash: hash,
+		UpdatedAt:    time.Now(),
+	}
+	if err := p.credentials.Put(ctx, &credential); err != nil {
+		return fmt.Errorf("failed to save credential: %v", err)
+	}
+	return nil
+}
+
+// HasPassword reports whether the user has a local hash, or a password with the provider
+// they are migrated from
+func (p *LocalIdentityProvider) HasPassword(ctx context.Context, user *models.User) (bool, error) {
+	_, err := p.credentials.Get(ctx, user.UID)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return false, fmt.Errorf("failed to fetch credential: %v", err)
+	}
+	if p.migrateFrom == nil {
+		return false, nil
+	}
+	return p.migrateFrom.HasPassword(ctx, user)
+}
+
+// ExternalSubject returns "": the local provider only knows passwords, and Google and
+// GitHub users sign in with the OAuth providers
+func (p *LocalIdentityProvider) ExternalSubject(ctx context.Context, uid string, provider models.AuthProvider) (string, error) {
+	return "", nil
+}
+
+// DeleteAccount deletes the local password hash of the user
+func (p *LocalIdentityProvider) DeleteAccount(ctx context.Context, uid string) error {
+	if err := p.credentials.Delete(ctx, uid); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to delete credential: %v", err)
+	}
+	return nil
+}
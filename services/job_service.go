@@ -5,24 +5,29 @@ import (
 	"fmt"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/google/uuid"
 	"github.com/rogerjeasy/go-letusconnect/mappers"
 	"github.com/rogerjeasy/go-letusconnect/models"
-	"google.golang.org/api/iterator"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 // JobService manages job tracking operations
 type JobService struct {
-	firestoreClient FirestoreClient
+	jobs repository.JobRepository
+}
+
+// updatableJobFields lists the fields a job owner may change through UpdateJob
+var updatableJobFields = []string{
+	"company", "position", "location", "status", "salary_range", "job_type",
+	"job_description", "offer_details", "rejection_reason", "follow_up_date", "company_rating",
 }
 
 // NewJobService initializes a new JobService
-func NewJobService(client FirestoreClient) *JobService {
-	return &JobService{firestoreClient: client}
+func NewJobService(jobs repository.JobRepository) *JobService {
+	return &JobService{jobs: jobs}
 }
 
-// CreateJob adds a new job application to Firestore
+// CreateJob adds a new job application
 func (s *JobService) CreateJob(ctx context.Context, job *models.Job) (*models.Job, error) {
 	job.ID = uuid.New().String()
 	job.CreatedAt = time.Now()
@@ -44,12 +49,8 @@ func (s *JobService) CreateJob(ctx context.Context, job *models.Job) (*models.Jo
 		return nil, fmt.Errorf("position is required")
 	}
 
-	// Convert job struct to Firestore format
-	firestoreData := mappers.MapJobGoToFirestore(*job)
-
-	// Store job in Firestore
-	_, err := s.firestoreClient.Collection("jobs").Doc(job.ID).Set(ctx, firestoreData)
-	if err != nil {
+	// Store job
+	if err := s.jobs.Create(ctx, job); err != nil {
 		return nil, fmt.Errorf("failed to create job: %v", err)
 	}
 
@@ -58,37 +59,24 @@ func (s *JobService) CreateJob(ctx context.Context, job *models.Job) (*models.Jo
 
 // GetJob retrieves a job application by ID
 func (s *JobService) GetJob(ctx context.Context, jobID string, userID string) (*models.Job, error) {
-	doc, err := s.firestoreClient.Collection("jobs").Doc(jobID).Get(ctx)
+	job, err := s.jobs.Get(ctx, jobID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get job: %v", err)
 	}
 
-	// Convert Firestore data to Go struct
-	job := mappers.MapJobFirestoreToGo(doc.Data())
-
 	// Ensure the job belongs to the user
 	if job.UserID != userID {
 		return nil, fmt.Errorf("unauthorized access to job")
 	}
 
-	return &job, nil
+	return job, nil
 }
 
 // GetJobsByUser retrieves all job applications for a user
 func (s *JobService) GetJobsByUser(ctx context.Context, userID string) ([]models.Job, error) {
-	iter := s.firestoreClient.Collection("jobs").Where("user_id", "==", userID).Documents(ctx)
-
-	var jobs []models.Job
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch jobs: %v", err)
-		}
-		job := mappers.MapJobFirestoreToGo(doc.Data())
-		jobs = append(jobs, job)
+	jobs, err := s.jobs.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jobs: %v", err)
 	}
 
 	return jobs, nil
@@ -96,37 +84,26 @@ func (s *JobService) GetJobsByUser(ctx context.Context, userID string) ([]models
 
 // UpdateJob updates an existing job application
 func (s *JobService) UpdateJob(ctx context.Context, jobID string, userID string, jobData map[string]interface{}) error {
-	docRef := s.firestoreClient.Collection("jobs").Doc(jobID)
-
 	// Fetch existing job to check ownership
-	doc, err := docRef.Get(ctx)
+	job, err := s.jobs.Get(ctx, jobID)
 	if err != nil {
 		return fmt.Errorf("job not found: %v", err)
 	}
-	job := mappers.MapJobFirestoreToGo(doc.Data())
 
 	// Ensure the user is the owner
 	if job.UserID != userID {
 		return fmt.Errorf("unauthorized: cannot update job")
 	}
 
-	// Add updated timestamp
-	jobData["updated_at"] = time.Now()
-
-	// Update Firestore document
-	_, err = docRef.Update(ctx, []firestore.Update{
-		{Path: "updated_at", Value: jobData["updated_at"]},
-		{Path: "company", Value: jobData["company"]},
-		{Path: "position", Value: jobData["position"]},
-		{Path: "location", Value: jobData["location"]},
-		{Path: "status", Value: jobData["status"]},
-		{Path: "salary_range", Value: jobData["salary_range"]},
-		{Path: "job_type", Value: jobData["job_type"]},
-		{Path: "job_description", Value: jobData["job_description"]},
-		{Path: "offer_details", Value: jobData["offer_details"]},
-		{Path: "rejection_reason", Value: jobData["rejection_reason"]},
-		{Path: "follow_up_date", Value: jobData["follow_up_date"]},
-		{Path: "company_rating", Value: jobData["company_rating"]},
+	_, err = s.jobs.Update(ctx, jobID, func(job *models.Job) error {
+		data := mappers.MapJobGoToFirestore(*job)
+		for _, field := range updatableJobFields {
+			data[field] = jobData[field]
+		}
+		data["updated_at"] = time.Now()
+
+		*job = mappers.MapJobFirestoreToGo(data)
+		return nil
 	})
 	if err != nil {
 		return fmt.Errorf("failed to update job: %v", err)
@@ -135,24 +112,20 @@ func (s *JobService) UpdateJob(ctx context.Context, jobID string, userID string,
 	return nil
 }
 
-// DeleteJob removes a job application from Firestore
+// DeleteJob removes a job application
 func (s *JobService) DeleteJob(ctx context.Context, jobID string, userID string) error {
-	docRef := s.firestoreClient.Collection("jobs").Doc(jobID)
-
 	// Fetch job to check ownership
-	doc, err := docRef.Get(ctx)
+	job, err := s.jobs.Get(ctx, jobID)
 	if err != nil {
 		return fmt.Errorf("job not found: %v", err)
 	}
-	job := mappers.MapJobFirestoreToGo(doc.Data())
 
 	// Ensure the user is the owner
 	if job.UserID != userID {
 		return fmt.Errorf("unauthorized: cannot delete job")
 	}
 
-	_, err = docRef.Delete(ctx)
-	if err != nil {
+	if err := s.jobs.Delete(ctx, jobID); err != nil {
 		return fmt.Errorf("failed to delete job: %v", err)
 	}
 
@@ -161,14 +134,11 @@ func (s *JobService) DeleteJob(ctx context.Context, jobID string, userID string)
 
 // AddInterviewRound adds a new interview round to a job application
 func (s *JobService) AddInterviewRound(ctx context.Context, jobID string, userID string, interview models.InterviewRound) error {
-	docRef := s.firestoreClient.Collection("jobs").Doc(jobID)
-
 	// Fetch job to ensure ownership
-	doc, err := docRef.Get(ctx)
+	job, err := s.jobs.Get(ctx, jobID)
 	if err != nil {
 		return fmt.Errorf("job not found: %v", err)
 	}
-	job := mappers.MapJobFirestoreToGo(doc.Data())
 
 	// Ensure the user owns the job
 	if job.UserID != userID {
@@ -176,12 +146,10 @@ func (s *JobService) AddInterviewRound(ctx context.Context, jobID string, userID
 	}
 
 	// Append new interview
-	job.Interviews = append(job.Interviews, interview)
-
-	// Update Firestore document
-	_, err = docRef.Update(ctx, []firestore.Update{
-		{Path: "interviews", Value: mappers.MapInterviewRoundsArrayToFirestore(job.Interviews)},
-		{Path: "updated_at", Value: time.Now()},
+	_, err = s.jobs.Update(ctx, jobID, func(job *models.Job) error {
+		job.Interviews = append(job.Interviews, interview)
+		job.UpdatedAt = time.Now()
+		return nil
 	})
 	if err != nil {
 		return fmt.Errorf("failed to add interview round: %v", err)
@@ -192,14 +160,11 @@ func (s *JobService) AddInterviewRound(ctx context.Context, jobID string, userID
 
 // RemoveInterviewRound removes an interview round from a job application
 func (s *JobService) RemoveInterviewRound(ctx context.Context, jobID string, userID string, roundNumber int) error {
-	docRef := s.firestoreClient.Collection("jobs").Doc(jobID)
-
 	// Fetch job to ensure ownership
-	doc, err := docRef.Get(ctx)
+	job, err := s.jobs.Get(ctx, jobID)
 	if err != nil {
 		return fmt.Errorf("job not found: %v", err)
 	}
-	job := mappers.MapJobFirestoreToGo(doc.Data())
 
 	// Ensure the user owns the job
 	if job.UserID != userID {
@@ -207,18 +172,16 @@ func (s *JobService) RemoveInterviewRound(ctx context.Context, jobID string, use
 	}
 
 	// Remove specified interview round
-	var updatedInterviews []models.InterviewRound
-	for _, interview := range job.Interviews {
-		if interview.RoundNumber != roundNumber {
-			updatedInterviews = append(updatedInterviews, interview)
+	_, err = s.jobs.Update(ctx, jobID, func(job *models.Job) error {
+		var updatedInterviews []models.InterviewRound
+		for _, interview := range job.Interviews {
+			if interview.RoundNumber != roundNumber {
+				updatedInterviews = append(updatedInterviews, interview)
+			}
 		}
-	}
-	job.Interviews = updatedInterviews
-
-	// Update Firestore document
-	_, err = docRef.Update(ctx, []firestore.Update{
-		{Path: "interviews", Value: mappers.MapInterviewRoundsArrayToFirestore(job.Interviews)},
-		{Path: "updated_at", Value: time.Now()},
+		job.Interviews = updatedInterviews
+		job.UpdatedAt = time.Now()
+		return nil
 	})
 	if err != nil {
 		return fmt.Errorf("failed to remove interview round: %v", err)
@@ -229,22 +192,9 @@ func (s *JobService) RemoveInterviewRound(ctx context.Context, jobID string, use
 
 // GetJobsByStatus fetches jobs based on status (e.g., Applied, Interviewing, Rejected)
 func (s *JobService) GetJobsByStatus(ctx context.Context, userID string, status models.JobStatus) ([]models.Job, error) {
-	iter := s.firestoreClient.Collection("jobs").
-		Where("user_id", "==", userID).
-		Where("status", "==", string(status)).
-		Documents(ctx)
-
-	var jobs []models.Job
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch jobs: %v", err)
-		}
-		job := mappers.MapJobFirestoreToGo(doc.Data())
-		jobs = append(jobs, job)
+	jobs, err := s.jobs.ListByUserAndStatus(ctx, userID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jobs: %v", err)
 	}
 
 	return jobs, nil
@@ -1 +1,452 @@
 package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/config"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// Tokens issues and verifies the access tokens of the API.
+// It is the only place where tokens are signed or checked.
+var Tokens *TokenService
+
+// defaultAccessTokenTTL is the lifetime of an access token when none is configured.
+// Devices stay signed in with their refresh token.
+const defaultAccessTokenTTL = 15 * time.Minute
+
+// TokenConfig describes the signing key and the extra verification keys of a TokenService
+type TokenConfig struct {
+	// Algorithm is HS256, RS256 or EdDSA
+	Algorithm string
+	// KeyID is written to the "kid" header of every issued token
+	KeyID string
+	// SigningKey is the HMAC secret or the PEM encoded private key
+	SigningKey string
+	// VerificationKeys are the previous keys still accepted during a rotation
+	VerificationKeys []VerificationKey
+	Issuer           string
+	TTL              time.Duration
+}
+
+// VerificationKey is a key that verifies tokens but never signs them.
+// Key is the HMAC secret or a PEM encoded public or private key.
+type VerificationKey struct {
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Key       string `json:"key"`
+}
+
+// TokenClaims are the claims of an access token
+type TokenClaims struct {
+	UID   string `json:"uid"`
+	Email string `json:"email,omitempty"`
+	// SessionID is the session the token was issued to
+	SessionID string   `json:"sid"`
+	Roles     []string `json:"roles,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ActionTokenClaims are the claims of a token mailed to a user, like an email verification link.
+// The purpose keeps action tokens from being accepted as access tokens and the other way round.
+type ActionTokenClaims struct {
+	UID     string `json:"uid"`
+	Email   string `json:"email,omitempty"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// JSONWebKey is the public part of a signing key, as published in the JWKS document
+type JSONWebKey struct {
+	KeyID     string `json:"kid"`
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is the JWKS document listing the keys that verify access tokens
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type tokenKey struct {
+	id     string
+	method jwt.SigningMethod
+	// sign is nil for keys that only verify tokens
+	sign   interface{}
+	verify interface{}
+}
+
+// RevocationChecker reports whether a session was revoked.
+// repository.RevocationRepository satisfies it.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, id string) (bool, error)
+}
+
+// PersonalAccessTokenVerifier checks the personal access tokens of the users.
+// *PersonalAccessTokenService satisfies it.
+type PersonalAccessTokenVerifier interface {
+	Verify(ctx context.Context, token, ipAddress string) (*models.PersonalAccessToken, *models.User, error)
+}
+
+// TokenService signs access tokens with one key and verifies them with any of its keys
+type TokenService struct {
+	signing *tokenKey
+	keys    map[string]*tokenKey
+	// order keeps the JWKS document stable
+	order          []string
+	issuer         string
+	ttl            time.Duration
+	revocations    RevocationChecker
+	personalTokens PersonalAccessTokenVerifier
+}
+
+// InitializeTokens creates the token service from the JWT_* environment variables
+func InitializeTokens() error {
+	cfg := TokenConfig{
+		Algorithm:  config.JWTAlgorithm,
+		KeyID:      config.JWTKeyID,
+		SigningKey: config.JWTSigningKey,
+		Issuer:     config.JWTIssuer,
+		TTL:        config.JWTAccessTokenTTL,
+	}
+
+	if config.JWTSigningKeyFile != "" {
+		data, err := os.ReadFile(config.JWTSigningKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read JWT signing key: %v", err)
+		}
+		cfg.SigningKey = string(data)
+	}
+
+	if config.JWTVerificationKeys != "" {
+		if err := json.Unmarshal([]byte(config.JWTVerificationKeys), &cfg.VerificationKeys); err != nil {
+			return fmt.Errorf("failed to parse JWT verification keys: %v", err)
+		}
+	}
+
+	if cfg.SigningKey == "" && (cfg.Algorithm == "" || cfg.Algorithm == jwt.SigningMethodHS256.Alg()) {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate JWT secret: %v", err)
+		}
+		cfg.SigningKey = base64.RawURLEncoding.EncodeToString(secret)
+		log.Println("JWT_SIGNING_KEY is not set, using a random key: tokens will not survive a restart")
+	}
+
+	service, err := NewTokenService(cfg)
+	if err != nil {
+		return err
+	}
+
+	Tokens = service
+	log.Printf("Token service initialized with %s key %q", service.signing.method.Alg(), service.signing.id)
+	return nil
+}
+
+// NewTokenService parses the configured keys
+func NewTokenService(cfg TokenConfig) (*TokenService, error) {
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = jwt.SigningMethodHS256.Alg()
+	}
+	if cfg.KeyID == "" {
+		cfg.KeyID = "default"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultAccessTokenTTL
+	}
+
+	signing, err := parseTokenKey(cfg.KeyID, cfg.Algorithm, cfg.SigningKey, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT signing key: %v", err)
+	}
+
+	service := &TokenService{
+		signing: signing,
+		keys:    map[string]*tokenKey{signing.id: signing},
+		order:   []string{signing.id},
+		issuer:  cfg.Issuer,
+		ttl:     cfg.TTL,
+	}
+
+	for _, verification := range cfg.VerificationKeys {
+		if verification.KeyID == "" {
+			return nil, errors.New("every JWT verification key needs a kid")
+		}
+		if _, exists := service.keys[verification.KeyID]; exists {
+			return nil, fmt.Errorf("duplicate JWT key ID %q", verification.KeyID)
+		}
+
+		key, err := parseTokenKey(verification.KeyID, verification.Algorithm, verification.Key, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT verification key %q: %v", verification.KeyID, err)
+		}
+		service.keys[key.id] = key
+		service.order = append(service.order, key.id)
+	}
+
+	return service, nil
+}
+
+// parseTokenKey decodes an HMAC secret or a PEM key for the given algorithm.
+// Signing keys must be private; verification keys may be public or private.
+func parseTokenKey(id, algorithm, material string, signing bool) (*tokenKey, error) {
+	material = strings.TrimSpace(material)
+	if material == "" {
+		return nil, errors.New("key is empty")
+	}
+
+	key := &tokenKey{id: id}
+	switch algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if len(material) < 32 {
+			return nil, errors.New("HS256 secrets must be at least 32 characters long")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.sign = []byte(material)
+		key.verify = []byte(material)
+
+	case jwt.SigningMethodRS256.Alg():
+		key.method = jwt.SigningMethodRS256
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(material)); err == nil {
+			key.sign = private
+			key.verify = &private.PublicKey
+		} else if signing {
+			return nil, fmt.Errorf("failed to parse RSA private key: %v", err)
+		} else if public, err := jwt.ParseRSAPublicKeyFromPEM([]byte(material)); err == nil {
+			key.verify = public
+		} else {
+			return nil, fmt.Errorf("failed to parse RSA key: %v", err)
+		}
+
+	case jwt.SigningMethodEdDSA.Alg():
+		key.method = jwt.SigningMethodEdDSA
+		if private, err := jwt.ParseEdPrivateKeyFromPEM([]byte(material)); err == nil {
+			key.sign = private
+			key.verify = private.(crypto.Signer).Public()
+		} else if signing {
+			return nil, fmt.Errorf("failed to parse Ed25519 private key: %v", err)
+		} else if public, err := jwt.ParseEdPublicKeyFromPEM([]byte(material)); err == nil {
+			key.verify = public
+		} else {
+			return nil, fmt.Errorf("failed to parse Ed25519 key: %v", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+
+	if !signing {
+		key.sign = nil
+	}
+	return key, nil
+}
+
+// SetRevocationList makes Verify reject the tokens of revoked sessions
+func (s *TokenService) SetRevocationList(revocations RevocationChecker) {
+	s.revocations = revocations
+}
+
+// SetPersonalAccessTokens makes VerifyPersonalAccessToken accept the personal access tokens of the users
+func (s *TokenService) SetPersonalAccessTokens(verifier PersonalAccessTokenVerifier) {
+	s.personalTokens = verifier
+}
+
+// VerifyPersonalAccessToken checks a personal access token and returns its record and user
+func (s *TokenService) VerifyPersonalAccessToken(ctx context.Context, token, ipAddress string) (*models.PersonalAccessToken, *models.User, error) {
+	if s.personalTokens == nil {
+		return nil, nil, errors.New("personal access tokens are not enabled")
+	}
+	return s.personalTokens.Verify(ctx, strings.TrimSpace(token), ipAddress)
+}
+
+// AccessTokenTTL is the lifetime of the issued access tokens
+func (s *TokenService) AccessTokenTTL() time.Duration {
+	return s.ttl
+}
+
+// IssueAccessToken signs an access token for a session of the user with the current signing key
+func (s *TokenService) IssueAccessToken(uid, email, sessionID string, roles []string) (string, error) {
+	now := time.Now()
+	claims := TokenClaims{
+		UID:       uid,
+		Email:     email,
+		SessionID: sessionID,
+		Roles:     roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   uid,
+			Issuer:    s.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+		},
+	}
+
+	token := jwt.NewWithClaims(s.signing.method, claims)
+	token.Header["kid"] = s.signing.id
+
+	signed, err := token.SignedString(s.signing.sign)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %v", err)
+	}
+	return signed, nil
+}
+
+// Verify checks the signature, expiry, issuer and session of a token and returns its claims.
+// The key is selected by the "kid" header and must match the token algorithm.
+func (s *TokenService) Verify(tokenString string) (*TokenClaims, error) {
+	tokenString = strings.TrimSpace(strings.TrimPrefix(tokenString, "Bearer "))
+	if tokenString == "" {
+		return nil, errors.New("token cannot be empty")
+	}
+
+	claims := &TokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.verificationKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %v", err)
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("invalid token: missing expiration")
+	}
+	if s.issuer != "" && !claims.VerifyIssuer(s.issuer, true) {
+		return nil, errors.New("invalid token issuer")
+	}
+	if strings.TrimSpace(claims.UID) == "" {
+		return nil, errors.New("missing or invalid UID in token")
+	}
+	if claims.SessionID == "" {
+		return nil, errors.New("missing session in token")
+	}
+
+	if s.revocations != nil {
+		revoked, err := s.revocations.IsRevoked(context.Background(), claims.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %v", err)
+		}
+		if revoked {
+			return nil, errors.New("token has been revoked")
+		}
+	}
+
+	return claims, nil
+}
+
+// verificationKey selects the key of a token by its "kid" header
+func (s *TokenService) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verify, nil
+}
+
+// IssueActionToken signs a token for one purpose. id is the token record that makes it single-use.
+func (s *TokenService) IssueActionToken(id, uid, email, purpose string, expiresAt time.Time) (string, error) {
+	claims := ActionTokenClaims{
+		UID:     uid,
+		Email:   email,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			Subject:   uid,
+			Issuer:    s.issuer,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(s.signing.method, claims)
+	token.Header["kid"] = s.signing.id
+
+	signed, err := token.SignedString(s.signing.sign)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %v", err)
+	}
+	return signed, nil
+}
+
+// VerifyActionToken checks the signature, expiry, issuer and purpose of an action token.
+// Callers must still check that its record was not used.
+func (s *TokenService) VerifyActionToken(tokenString, purpose string) (*ActionTokenClaims, error) {
+	tokenString = strings.TrimSpace(tokenString)
+	if tokenString == "" {
+		return nil, errors.New("token cannot be empty")
+	}
+
+	claims := &ActionTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.verificationKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %v", err)
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("invalid token: missing expiration")
+	}
+	if s.issuer != "" && !claims.VerifyIssuer(s.issuer, true) {
+		return nil, errors.New("invalid token issuer")
+	}
+	if claims.Purpose == "" || claims.Purpose != purpose {
+		return nil, errors.New("invalid token purpose")
+	}
+	if strings.TrimSpace(claims.UID) == "" || claims.ID == "" {
+		return nil, errors.New("missing or invalid UID in token")
+	}
+
+	return claims, nil
+}
+
+// JWKS returns the public keys that verify access tokens. HMAC secrets are never published.
+func (s *TokenService) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, id := range s.order {
+		key := s.keys[id]
+		jwk := JSONWebKey{KeyID: key.id, Algorithm: key.method.Alg(), Use: "sig"}
+
+		switch public := key.verify.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testHMACSecret      = "test-signing-key-of-at-least-32-characters"
+	otherTestHMACSecret = "another-signing-key-of-at-least-32-chars"
+)
+
+// testRSAKey returns an RSA key with its private and public PEM encodings
+func testRSAKey(t *testing.T) (*rsa.PrivateKey, string, string) {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	public, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	require.NoError(t, err)
+	return private,
+		string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}))
+}
+
+// testEd25519Key returns an Ed25519 public key with its PEM encoding
+func testEd25519Key(t *testing.T) (ed25519.PublicKey, string) {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+	return public, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// signTestToken signs claims with the key and writes kid to the header
+func signTestToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validTestClaims() TokenClaims {
+	now := time.Now()
+	return TokenClaims{
+		UID:       "ada",
+		SessionID: "session-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "letusconnect",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+	}
+}
+
+func TestTokenServiceKeyRotation(t *testing.T) {
+	_, rsaPrivate, _ := testRSAKey(t)
+
+	previous, err := NewTokenService(TokenConfig{KeyID: "2024", SigningKey: testHMACSecret})
+	require.NoError(t, err)
+	current, err := NewTokenService(TokenConfig{
+		Algorithm:        "RS256",
+		KeyID:            "2025",
+		SigningKey:       rsaPrivate,
+		VerificationKeys: []VerificationKey{{KeyID: "2024", Algorithm: "HS256", Key: testHMACSecret}},
+	})
+	require.NoError(t, err)
+
+	previousToken, err := previous.IssueAccessToken("ada", "", "session-1", nil)
+	require.NoError(t, err)
+	currentToken, err := current.IssueAccessToken("ada", "", "session-1", nil)
+	require.NoError(t, err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(currentToken, &TokenClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "2025", parsed.Header["kid"])
+	assert.Equal(t, "RS256", parsed.Header["alg"])
+
+	tests := []struct {
+		name    string
+		service *TokenService
+		token   string
+		wantErr bool
+	}{
+		{name: "current key", service: current, token: currentToken},
+		{name: "rotated out key still verifies", service: current, token: previousToken},
+		{name: "previous service does not know the new kid", service: previous, token: currentToken, wantErr: true},
+		{
+			name:    "known kid signed with another secret",
+			service: current,
+			token:   signTestToken(t, jwt.SigningMethodHS256, []byte(otherTestHMACSecret), "2024", validTestClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "unknown kid",
+			service: current,
+			token:   signTestToken(t, jwt.SigningMethodHS256, []byte(testHMACSecret), "1999", validTestClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "missing kid",
+			service: current,
+			token:   signTestToken(t, jwt.SigningMethodHS256, []byte(testHMACSecret), "", validTestClaims()),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.service.Verify(tt.token)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "ada", claims.UID)
+		})
+	}
+}
+
+func TestTokenServiceRejectsAlgorithmMismatch(t *testing.T) {
+	rsaKey, rsaPrivate, rsaPublic := testRSAKey(t)
+	service, err := NewTokenService(TokenConfig{
+		Algorithm:        "RS256",
+		KeyID:            "rsa",
+		SigningKey:       rsaPrivate,
+		VerificationKeys: []VerificationKey{{KeyID: "hmac", Algorithm: "HS256", Key: testHMACSecret}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{
+			// The classic confusion: the public key, which anyone can fetch, used as an HMAC secret
+			name:  "HS256 signed with the RSA public key",
+			token: signTestToken(t, jwt.SigningMethodHS256, []byte(rsaPublic), "rsa", validTestClaims()),
+		},
+		{
+			name:  "RS256 under the kid of an HS256 key",
+			token: signTestToken(t, jwt.SigningMethodRS256, rsaKey, "hmac", validTestClaims()),
+		},
+		{
+			name:  "HS512 under the kid of an HS256 key",
+			token: signTestToken(t, jwt.SigningMethodHS512, []byte(testHMACSecret), "hmac", validTestClaims()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Verify(tt.token)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTokenServiceRejectsMissingClaims(t *testing.T) {
+	service, err := NewTokenService(TokenConfig{KeyID: "k1", SigningKey: testHMACSecret, Issuer: "letusconnect"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		mutate  func(claims *TokenClaims)
+		wantErr string
+	}{
+		{name: "valid", mutate: func(claims *TokenClaims) {}},
+		{name: "missing exp", mutate: func(claims *TokenClaims) { claims.ExpiresAt = nil }, wantErr: "missing expiration"},
+		{
+			name:    "expired",
+			mutate:  func(claims *TokenClaims) { claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) },
+			wantErr: "expired",
+		},
+		{name: "missing iss", mutate: func(claims *TokenClaims) { claims.Issuer = "" }, wantErr: "issuer"},
+		{name: "other iss", mutate: func(claims *TokenClaims) { claims.Issuer = "someone-else" }, wantErr: "issuer"},
+		{name: "missing uid", mutate: func(claims *TokenClaims) { claims.UID = "" }, wantErr: "UID"},
+		{name: "blank uid", mutate: func(claims *TokenClaims) { claims.UID = "  " }, wantErr: "UID"},
+		{name: "missing sid", mutate: func(claims *TokenClaims) { claims.SessionID = "" }, wantErr: "session"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validTestClaims()
+			tt.mutate(&claims)
+			token := signTestToken(t, jwt.SigningMethodHS256, []byte(testHMACSecret), "k1", claims)
+
+			_, err := service.Verify(token)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestTokenServiceRejectsRevokedSessions(t *testing.T) {
+	ctx := context.Background()
+	service := newTestTokenService(t)
+	revocations := repository.NewMemoryRevocationRepository()
+	service.SetRevocationList(revocations)
+
+	revokedToken, err := service.IssueAccessToken("ada", "", "session-1", nil)
+	require.NoError(t, err)
+	activeToken, err := service.IssueAccessToken("ada", "", "session-2", nil)
+	require.NoError(t, err)
+
+	_, err = service.Verify(revokedToken)
+	require.NoError(t, err)
+
+	require.NoError(t, revocations.Revoke(ctx, "session-1", time.Now().Add(time.Hour)))
+
+	_, err = service.Verify(revokedToken)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "revoked")
+
+	_, err = service.Verify("Bearer " + activeToken)
+	assert.NoError(t, err)
+}
+
+func TestTokenServiceJWKS(t *testing.T) {
+	rsaKey, rsaPrivate, _ := testRSAKey(t)
+	edPublic, edPEM := testEd25519Key(t)
+
+	service, err := NewTokenService(TokenConfig{
+		Algorithm:  "RS256",
+		KeyID:      "rsa",
+		SigningKey: rsaPrivate,
+		VerificationKeys: []VerificationKey{
+			{KeyID: "hmac", Algorithm: "HS256", Key: testHMACSecret},
+			{KeyID: "ed", Algorithm: "EdDSA", Key: edPEM},
+		},
+	})
+	require.NoError(t, err)
+
+	set := service.JWKS()
+
+	// HMAC secrets are never published and the order follows the configuration
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, JSONWebKey{
+		KeyID:     "rsa",
+		KeyType:   "RSA",
+		Algorithm: "RS256",
+		Use:       "sig",
+		N:         base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}, set.Keys[0])
+	assert.Equal(t, JSONWebKey{
+		KeyID:     "ed",
+		KeyType:   "OKP",
+		Algorithm: "EdDSA",
+		Use:       "sig",
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(edPublic),
+	}, set.Keys[1])
+
+	hmacOnly := newTestTokenService(t)
+	assert.Empty(t, hmacOnly.JWKS().Keys)
+}
+
+func TestTokenServiceSeparatesActionAndAccessTokens(t *testing.T) {
+	service := newTestTokenService(t)
+
+	accessToken, err := service.IssueAccessToken("ada", "ada@example.com", "session-1", nil)
+	require.NoError(t, err)
+	actionToken, err := service.IssueActionToken("token-1", "ada", "ada@example.com", "verify_email", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		verify  func() error
+		wantErr bool
+	}{
+		{
+			name:   "access token as access token",
+			verify: func() error { _, err := service.Verify(accessToken); return err },
+		},
+		{
+			name:   "action token for its purpose",
+			verify: func() error { _, err := service.VerifyActionToken(actionToken, "verify_email"); return err },
+		},
+		{
+			name:    "action token as access token",
+			verify:  func() error { _, err := service.Verify(actionToken); return err },
+			wantErr: true,
+		},
+		{
+			name:    "access token as action token",
+			verify:  func() error { _, err := service.VerifyActionToken(accessToken, "verify_email"); return err },
+			wantErr: true,
+		},
+		{
+			name:    "access token as action token without purpose",
+			verify:  func() error { _, err := service.VerifyActionToken(accessToken, ""); return err },
+			wantErr: true,
+		},
+		{
+			name:    "action token for another purpose",
+			verify:  func() error { _, err := service.VerifyActionToken(actionToken, "reset_password"); return err },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.verify()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
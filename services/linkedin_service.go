@@ -9,15 +9,16 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 type LinkedInJobsService struct {
-	firestoreClient FirestoreClient
+	applications repository.LinkedInApplicationRepository
 }
 
-func NewLinkedInJobsService(client FirestoreClient) *LinkedInJobsService {
+func NewLinkedInJobsService(applications repository.LinkedInApplicationRepository) *LinkedInJobsService {
 	return &LinkedInJobsService{
-		firestoreClient: client,
+		applications: applications,
 	}
 }
 
@@ -57,8 +58,7 @@ func (s *LinkedInJobsService) StoreJobApplication(ctx context.Context, userID st
 	jobApp.ApplyTimestamp = time.Now().Unix()
 	jobApp.JobID = uuid.New().String()
 
-	_, err := s.firestoreClient.Collection("jobs").Doc(jobApp.JobID).Set(ctx, jobApp)
-	if err != nil {
+	if err := s.applications.Create(ctx, userID, &jobApp); err != nil {
 		return fmt.Errorf("failed to store job application: %v", err)
 	}
 
@@ -66,19 +66,10 @@ func (s *LinkedInJobsService) StoreJobApplication(ctx context.Context, userID st
 }
 
 func (s *LinkedInJobsService) GetUserApplications(ctx context.Context, userID string) ([]models.LinkedInJobApplication, error) {
-	docs, err := s.firestoreClient.Collection("job_applications").Doc(userID).Collection("applications").Documents(ctx).GetAll()
+	applications, err := s.applications.ListByUser(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch job applications: %v", err)
 	}
 
-	var applications []models.LinkedInJobApplication
-	for _, doc := range docs {
-		var app models.LinkedInJobApplication
-		if err := doc.DataTo(&app); err != nil {
-			return nil, fmt.Errorf("failed to parse job application: %v", err)
-		}
-		applications = append(applications, app)
-	}
-
 	return applications, nil
 }
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+// PurposeAccountUnlock is the purpose of the tokens in the unlock links of locked accounts
+const PurposeAccountUnlock = "account_unlock"
+
+const (
+	// accountUnlockTTL is how long an unlock link works
+	accountUnlockTTL = 24 * time.Hour
+	// loginFailureWindow is how long a failed login counts against an account or address
+	loginFailureWindow = 15 * time.Minute
+	// loginThrottleMemory is how long the lockouts of an account or address are remembered,
+	// making the next lockout longer
+	loginThrottleMemory = 24 * time.Hour
+)
+
+const (
+	loginThrottleAccount = "account"
+	loginThrottleIP      = "ip"
+)
+
+// loginPolicy describes how failed logins slow down and lock out an account or an address
+type loginPolicy struct {
+	// freeAttempts is how many failures are allowed before each attempt has to wait
+	freeAttempts int
+	// maxDelay bounds the wait, which doubles with every failure
+	maxDelay time.Duration
+	// lockoutThreshold is how many failures lock the account or address out
+	lockoutThreshold int
+	// lockoutDuration is the first lockout; every next one lasts twice as long
+	lockoutDuration    time.Duration
+	maxLockoutDuration time.Duration
+}
+
+var (
+	accountLoginPolicy = loginPolicy{
+		freeAttempts:       3,
+		maxDelay:           30 * time.Second,
+		lockoutThreshold:   10,
+		lockoutDuration:    15 * time.Minute,
+		maxLockoutDuration: 24 * time.Hour,
+	}
+	// An address may be shared by many users, like a campus network
+	ipLoginPolicy = loginPolicy{
+		freeAttempts:       20,
+		maxDelay:           30 * time.Second,
+		lockoutThreshold:   100,
+		lockoutDuration:    15 * time.Minute,
+		maxLockoutDuration: 24 * time.Hour,
+	}
+)
+
+// ErrInvalidUnlockToken is returned for unknown, expired and used unlock tokens
+var ErrInvalidUnlockToken = errors.New("invalid or expired unlock link")
+
+// AccountLockedError is returned while an account is locked out after too many failed logins
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("this account is locked after too many failed logins, try again in %s or use the link we emailed you",
+		time.Until(e.Until).Round(time.Second))
+}
+
+// LoginLockout is returned by RecordFailure when a failed login locked the account out
+type LoginLockout struct {
+	Until time.Time
+	// UnlockToken is the token of the unlock link to mail, empty for unknown accounts
+	UnlockToken string
+}
+
+// LoginProtectionService counts the failed logins of each account and IP address. Repeated
+// failures make the next attempts wait longer and longer, then lock the account or address out.
+type LoginProtectionService struct {
+	throttles repository.LoginThrottleRepository
+	users     repository.UserRepository
+	tokens    *actionTokens
+}
+
+func NewLoginProtectionService(throttles repository.LoginThrottleRepository, users repository.UserRepository, actionTokenRepo repository.ActionTokenRepository, signer *TokenService) *LoginProtectionService {
+	return &LoginProtectionService{
+		throttles: throttles,
+		users:     users,
+		tokens: &actionTokens{
+			repo:    actionTokenRepo,
+			signer:  signer,
+			purpose: PurposeAccountUnlock,
+			ttl:     accountUnlockTTL,
+		},
+	}
+}
+
+// UnlockTTL is how long an unlock link works
+func (s *LoginProtectionService) UnlockTTL() time.Duration {
+	return s.tokens.ttl
+}
+
+// accountSubject identifies the account of a login. Logins to unknown accounts are counted
+// by identifier, so that they are throttled like the others.
+func accountSubject(user *models.User, identifier string) string {
+	if user != nil {
+		return user.UID
+	}
+	return "unknown:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// loginThrottleID is the ID of the counter of an account or address
+func loginThrottleID(kind, subject string) string {
+	sum := sha256.Sum256([]byte(subject))
+	return kind + "_" + hex.EncodeToString(sum[:])
+}
+
+// Check returns a ThrottledError when a login for the account from the address has to wait,
+// and an AccountLockedError while the account is locked out. user is nil for unknown accounts.
+func (s *LoginProtectionService) Check(ctx context.Context, user *models.User, identifier, ipAddress string) error {
+	now := time.Now()
+
+	wait, locked, err := s.wait(ctx, loginThrottleID(loginThrottleIP, ipAddress), ipLoginPolicy, now)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		// Locked out addresses wait like throttled ones, the account itself is fine
+		return &ThrottledError{RetryAfter: wait}
+	}
+
+	wait, locked, err = s.wait(ctx, loginThrottleID(loginThrottleAccount, accountSubject(user, identifier)), accountLoginPolicy, now)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return &AccountLockedError{Until: now.Add(wait)}
+	}
+	if wait > 0 {
+		return &ThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// wait returns how long the next login of a counter has to wait, and whether it is locked out
+func (s *LoginProtectionService) wait(ctx context.Context, id string, policy loginPolicy, now time.Time) (time.Duration, bool, error) {
+	throttle, err := s.throttles.Get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to fetch failed logins: %v", err)
+	}
+	if !now.Before(throttle.ExpiresAt) {
+		return 0, false, nil
+	}
+
+	if throttle.LockedUntil != nil && now.Before(*throttle.LockedUntil) {
+		return throttle.LockedUntil.Sub(now), true, nil
+	}
+
+	if now.Sub(throttle.LastFailureAt) > loginFailureWindow || throttle.Failures < policy.freeAttempts {
+		return 0, false, nil
+	}
+	return throttle.LastFailureAt.Add(policy.delay(throttle.Failures)).Sub(now), false, nil
+}
+
+// delay is how long the attempt after the failures waits
+func (p loginPolicy) delay(failures int) time.Duration {
+	delay := time.Second
+	for i := p.freeAttempts; i < failures && delay < p.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.maxDelay {
+		return p.maxDelay
+	}
+	return delay
+}
+
+// lockout is how long the next lockout of a counter lasts
+func (p loginPolicy) lockout(previous int) time.Duration {
+	duration := p.lockoutDuration
+	for i := 0; i < previous && duration < p.maxLockoutDuration; i++ {
+		duration *= 2
+	}
+	if duration > p.maxLockoutDuration {
+		return p.maxLockoutDuration
+	}
+	return duration
+}
+
+// fail counts a failed login and reports whether it locked the counter out
+func (p loginPolicy) fail(throttle *models.LoginThrottle, ipAddress string, now time.Time) bool {
+	if !now.Before(throttle.ExpiresAt) {
+		// Forget the counter, which may not have been purged yet
+		*throttle = models.LoginThrottle{ID: throttle.ID, Kind: throttle.Kind, Subject: throttle.Subject}
+	}
+	if now.Sub(throttle.LastFailureAt) > loginFailureWindow {
+		throttle.Failures = 0
+	}
+
+	throttle.Failures++
+	throttle.LastFailureAt = now
+	throttle.LastFailureIP = ipAddress
+	throttle.ExpiresAt = now.Add(loginThrottleMemory)
+
+	if throttle.Failures < p.lockoutThreshold {
+		return false
+	}
+
+	until := now.Add(p.lockout(throttle.Lockouts))
+	throttle.LockedUntil = &until
+	throttle.Lockouts++
+	throttle.Failures = 0
+	throttle.ExpiresAt = until.Add(loginThrottleMemory)
+	return true
+}
+
+// RecordFailure counts a failed login for the account and the address. It returns a
+// LoginLockout when the failure locked the account out, with the token of an unlock
+// link for known accounts.
+func (s *LoginProtectionService) RecordFailure(ctx context.Context, user *models.User, identifier, ipAddress string) (*LoginLockout, error) {
+	now := time.Now()
+
+	if _, err := s.record(ctx, loginThrottleIP, ipAddress, ipAddress, ipLoginPolicy, now); err != nil {
+		return nil, err
+	}
+
+	throttle, err := s.record(ctx, loginThrottleAccount, accountSubject(user, identifier), ipAddress, accountLoginPolicy, now)
+	if err != nil || throttle == nil {
+		return nil, err
+	}
+
+	lockout := &LoginLockout{Until: *throttle.LockedUntil}
+	if user != nil {
+		token, err := s.tokens.issue(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		lockout.UnlockToken = token
+	}
+	return lockout, nil
+}
+
+// record counts a failed login for a counter and returns it when the failure locked it out
+func (s *LoginProtectionService) record(ctx context.Context, kind, subject, ipAddress string, policy loginPolicy, now time.Time) (*models.LoginThrottle, error) {
+	locked := false
+	throttle, err := s.throttles.Record(ctx, loginThrottleID(kind, subject), func(throttle *models.LoginThrottle) error {
+		throttle.Kind = kind
+		throttle.Subject = subject
+		locked = policy.fail(throttle, ipAddress, now)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record failed login: %v", err)
+	}
+	if !locked {
+		return nil, nil
+	}
+	return throttle, nil
+}
+
+// Clear forgets the failed logins and the lockout of the account, after a successful
+// login or a password reset
+func (s *LoginProtectionService) Clear(ctx context.Context, uid string) error {
+	err := s.throttles.Delete(ctx, loginThrottleID(loginThrottleAccount, uid))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to clear failed logins: %v", err)
+	}
+	return nil
+}
+
+// Unlock uses the token of an unlock link and lifts the lockout of its account
+func (s *LoginProtectionService) Unlock(ctx context.Context, token string) (*models.User, error) {
+	claims, err := s.tokens.verify(token)
+	if err != nil {
+		return nil, ErrInvalidUnlockToken
+	}
+
+	user, err := s.users.GetByUID(ctx, claims.UID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidUnlockToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %v", err)
+	}
+
+	if err := s.tokens.use(ctx, claims); err != nil {
+		if errors.Is(err, errActionTokenInvalid) {
+			return nil, ErrInvalidUnlockToken
+		}
+		return nil, err
+	}
+
+	if err := s.Clear(ctx, user.UID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
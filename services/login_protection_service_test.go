@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLoginProtectionService(t *testing.T) (*LoginProtectionService, repository.LoginThrottleRepository, *models.User) {
+	t.Helper()
+	users := repository.NewMemoryUserRepository()
+	user := &models.User{UID: "user-1", Email: "user@example.com"}
+	require.NoError(t, users.Create(context.Background(), user))
+
+	throttles := repository.NewMemoryLoginThrottleRepository()
+	service := NewLoginProtectionService(throttles, users, repository.NewMemoryActionTokenRepository(), newTestTokenService(t))
+	return service, throttles, user
+}
+
+func TestLoginProtectionServiceCheck(t *testing.T) {
+	const ip = "203.0.113.7"
+	now := time.Now()
+	lockedUntil := now.Add(10 * time.Minute)
+	lockedBefore := now.Add(-time.Minute)
+
+	tests := []struct {
+		name string
+		// kind and subject name the counter to store before the check, if any
+		kind     string
+		subject  string
+		throttle models.LoginThrottle
+		// user is nil for logins to unknown accounts, checked with identifier
+		unknown    bool
+		identifier string
+		// wantWait is the expected wait of a ThrottledError, wantLocked an AccountLockedError
+		wantWait   time.Duration
+		wantLocked bool
+	}{
+		{
+			name: "no failures",
+		},
+		{
+			name:     "free attempts",
+			kind:     loginThrottleAccount,
+			subject:  "user-1",
+			throttle: models.LoginThrottle{Failures: 2, LastFailureAt: now, ExpiresAt: now.Add(time.Hour)},
+		},
+		{
+			name:     "first delay",
+			kind:     loginThrottleAccount,
+			subject:  "user-1",
+			throttle: models.LoginThrottle{Failures: 3, LastFailureAt: now, ExpiresAt: now.Add(time.Hour)},
+			wantWait: time.Second,
+		},
+		{
+			name:     "delay doubles",
+			kind:     loginThrottleAccount,
+			subject:  "user-1",
+			throttle: models.LoginThrottle{Failures: 5, LastFailureAt: now, ExpiresAt: now.Add(time.Hour)},
+			wantWait: 4 * time.Second,
+		},
+		{
+			name:     "delay is bounded",
+			kind:     loginThrottleAccount,
+			subject:  "user-1",
+			throttle: models.LoginThrottle{Failures: 9, LastFailureAt: now, ExpiresAt: now.Add(time.Hour)},
+			wantWait: accountLoginPolicy.maxDelay,
+		},
+		{
+			name:     "delay already waited",
+			kind:     loginThrottleAccount,
+			subject:  "user-1",
+			throttle: models.LoginThrottle{Failures: 3, LastFailureAt: now.Add(-2 * time.Second), ExpiresAt: now.Add(time.Hour)},
+		},
+		{
+			name:     "failures outside the window",
+			kind:     loginThrottleAccount,
+			subject:  "user-1",
+			throttle: models.LoginThrottle{Failures: 9, LastFailureAt: now.Add(-loginFailureWindow - time.Minute), ExpiresAt: now.Add(time.Hour)},
+		},
+		{
+			name:     "expired counter",
+			kind:     loginThrottleAccount,
+			subject:  "user-1",
+			throttle: models.LoginThrottle{Failures: 9, LastFailureAt: now, LockedUntil: &lockedUntil, ExpiresAt: now.Add(-time.Second)},
+		},
+		{
+			name:       "locked account",
+			kind:       loginThrottleAccount,
+			subject:    "user-1",
+			throttle:   models.LoginThrottle{LastFailureAt: now, LockedUntil: &lockedUntil, ExpiresAt: lockedUntil.Add(time.Hour)},
+			wantLocked: true,
+		},
+		{
+			name:     "lockout over",
+			kind:     loginThrottleAccount,
+			subject:  "user-1",
+			throttle: models.LoginThrottle{LastFailureAt: now.Add(-time.Hour), LockedUntil: &lockedBefore, ExpiresAt: now.Add(time.Hour)},
+		},
+		{
+			name:       "unknown account, any case",
+			kind:       loginThrottleAccount,
+			subject:    "unknown:nobody@example.com",
+			throttle:   models.LoginThrottle{LastFailureAt: now, LockedUntil: &lockedUntil, ExpiresAt: lockedUntil.Add(time.Hour)},
+			unknown:    true,
+			identifier: " Nobody@Example.com ",
+			wantLocked: true,
+		},
+		{
+			name:     "throttled address",
+			kind:     loginThrottleIP,
+			subject:  ip,
+			throttle: models.LoginThrottle{Failures: 20, LastFailureAt: now, ExpiresAt: now.Add(time.Hour)},
+			wantWait: time.Second,
+		},
+		{
+			name:     "locked address only waits",
+			kind:     loginThrottleIP,
+			subject:  ip,
+			throttle: models.LoginThrottle{LastFailureAt: now, LockedUntil: &lockedUntil, ExpiresAt: lockedUntil.Add(time.Hour)},
+			wantWait: 10 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service, throttles, user := newTestLoginProtectionService(t)
+			if tt.kind != "" {
+				_, err := throttles.Record(ctx, loginThrottleID(tt.kind, tt.subject), func(throttle *models.LoginThrottle) error {
+					id := throttle.ID
+					*throttle = tt.throttle
+					throttle.ID, throttle.Kind, throttle.Subject = id, tt.kind, tt.subject
+					return nil
+				})
+				require.NoError(t, err)
+			}
+			if tt.unknown {
+				user = nil
+			}
+
+			err := service.Check(ctx, user, tt.identifier, ip)
+			switch {
+			case tt.wantLocked:
+				var locked *AccountLockedError
+				require.ErrorAs(t, err, &locked)
+				assert.WithinDuration(t, lockedUntil, locked.Until, time.Second)
+			case tt.wantWait > 0:
+				var throttled *ThrottledError
+				require.ErrorAs(t, err, &throttled)
+				assert.InDelta(t, tt.wantWait.Seconds(), throttled.RetryAfter.Seconds(), 1)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoginProtectionServiceLocksOutAndUnlocks(t *testing.T) {
+	ctx := context.Background()
+	service, _, user := newTestLoginProtectionService(t)
+
+	var lockout *LoginLockout
+	for i := 1; i <= accountLoginPolicy.lockoutThreshold; i++ {
+		var err error
+		lockout, err = service.RecordFailure(ctx, user, user.Email, "203.0.113.7")
+		require.NoError(t, err)
+		if i < accountLoginPolicy.lockoutThreshold {
+			assert.Nil(t, lockout, "failure %d", i)
+		}
+	}
+	require.NotNil(t, lockout)
+	assert.WithinDuration(t, time.Now().Add(accountLoginPolicy.lockoutDuration), lockout.Until, time.Second)
+	require.NotEmpty(t, lockout.UnlockToken)
+
+	// The account is locked from any address
+	var locked *AccountLockedError
+	assert.ErrorAs(t, service.Check(ctx, user, user.Email, "198.51.100.1"), &locked)
+
+	unlocked, err := service.Unlock(ctx, lockout.UnlockToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.UID, unlocked.UID)
+	assert.NoError(t, service.Check(ctx, user, user.Email, "198.51.100.1"))
+
+	// An unlock link works once
+	_, err = service.Unlock(ctx, lockout.UnlockToken)
+	assert.ErrorIs(t, err, ErrInvalidUnlockToken)
+}
+
+func TestLoginProtectionServiceUnknownAccountsGetNoUnlockLink(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newTestLoginProtectionService(t)
+
+	var lockout *LoginLockout
+	for i := 0; i < accountLoginPolicy.lockoutThreshold; i++ {
+		var err error
+		lockout, err = service.RecordFailure(ctx, nil, "nobody@example.com", "203.0.113.7")
+		require.NoError(t, err)
+	}
+	require.NotNil(t, lockout)
+	assert.Empty(t, lockout.UnlockToken)
+}
+
+func TestLoginPolicyLockoutGrows(t *testing.T) {
+	tests := []struct {
+		previous int
+		want     time.Duration
+	}{
+		{0, 15 * time.Minute},
+		{1, 30 * time.Minute},
+		{2, time.Hour},
+		{6, 16 * time.Hour},
+		{7, 24 * time.Hour},
+		{50, 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, accountLoginPolicy.lockout(tt.previous), tt.previous)
+	}
+}
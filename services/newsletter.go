@@ -4,43 +4,72 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
-	"google.golang.org/api/iterator"
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
+// ErrAlreadySubscribed is returned when the email is already on the newsletter
+var ErrAlreadySubscribed = errors.New("this email is already subscribed to the newsletter")
+
 type NewsletterService struct {
-	FirestoreClient FirestoreClient
+	subscriptions repository.NewsletterRepository
 }
 
-func NewNewsletterService(client FirestoreClient) *NewsletterService {
+func NewNewsletterService(subscriptions repository.NewsletterRepository) *NewsletterService {
 	return &NewsletterService{
-		FirestoreClient: client,
+		subscriptions: subscriptions,
 	}
 }
 
-func (s *NewsletterService) GetTotalSubscribers(ctx context.Context) (int, error) {
-	if s.FirestoreClient == nil {
-		return 0, errors.New("firestore client is not initialized")
+// Subscribe adds email to the newsletter
+func (s *NewsletterService) Subscribe(ctx context.Context, email string) (*models.NewsletterSubscription, error) {
+	_, err := s.subscriptions.GetByEmail(ctx, email)
+	if err == nil {
+		return nil, ErrAlreadySubscribed
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("error checking subscription: %w", err)
 	}
 
-	iter := s.FirestoreClient.Collection("newsletters").Documents(ctx)
-	defer iter.Stop()
-
-	count := 0
-	for {
-		_, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return 0, fmt.Errorf("error counting subscribers: %w", err)
-		}
-		count++
+	subscription := &models.NewsletterSubscription{
+		ID:           uuid.New().String(),
+		Email:        email,
+		SubscribedAt: time.Now(),
 	}
+	if err := s.subscriptions.Create(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("error storing subscription: %w", err)
+	}
+	return subscription, nil
+}
 
-	return count, nil
+// Unsubscribe removes email from the newsletter
+func (s *NewsletterService) Unsubscribe(ctx context.Context, email string) error {
+	subscription, err := s.subscriptions.GetByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("error finding subscription: %w", err)
+	}
+	if err := s.subscriptions.Delete(ctx, subscription.ID); err != nil {
+		return fmt.Errorf("error deleting subscription: %w", err)
+	}
+	return nil
 }
 
-func (s *NewsletterService) CreateNewsletter(ctx context.Context) {
+// GetAllSubscribers lists every subscription
+func (s *NewsletterService) GetAllSubscribers(ctx context.Context) ([]models.NewsletterSubscription, error) {
+	subscriptions, err := s.subscriptions.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching subscribers: %w", err)
+	}
+	return subscriptions, nil
+}
 
+func (s *NewsletterService) GetTotalSubscribers(ctx context.Context) (int, error) {
+	count, err := s.subscriptions.Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error counting subscribers: %w", err)
+	}
+	return count, nil
 }
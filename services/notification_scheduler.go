@@ -7,30 +7,27 @@ import (
 	"sync"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/google/uuid"
 	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 	"github.com/rogerjeasy/go-letusconnect/services/sms"
-	"google.golang.org/api/iterator"
 )
 
-const NOTIFICATIONS_COLLECTION = "notifications"
-
 type NotificationScheduler struct {
-	firestoreClient FirestoreClient
-	smsService      *sms.SMSService
-	stopChan        chan struct{}
-	wg              sync.WaitGroup
+	notifications repository.NotificationRepository
+	smsService    *sms.SMSService
+	stopChan      chan struct{}
+	wg            sync.WaitGroup
 }
 
 func NewNotificationScheduler(
-	client FirestoreClient,
+	notifications repository.NotificationRepository,
 	smsService *sms.SMSService,
 ) *NotificationScheduler {
 	return &NotificationScheduler{
-		firestoreClient: client,
-		smsService:      smsService,
-		stopChan:        make(chan struct{}),
+		notifications: notifications,
+		smsService:    smsService,
+		stopChan:      make(chan struct{}),
 	}
 }
 
@@ -62,44 +59,28 @@ func (s *NotificationScheduler) run(ctx context.Context) {
 }
 
 func (s *NotificationScheduler) processNotifications(ctx context.Context) {
-	now := time.Now()
-
-	// Query for pending notifications that are due
-	query := s.firestoreClient.Collection(NOTIFICATIONS_COLLECTION).
-		Where("status", "==", models.NotificationStatusPending).
-		Where("scheduledAt", "<=", now)
-
-	iter := query.Documents(ctx)
-	defer iter.Stop()
-
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			log.Printf("Error iterating notifications: %v", err)
-			continue
-		}
-
-		var notification models.Notification
-		if err := doc.DataTo(&notification); err != nil {
-			log.Printf("Error converting document to notification: %v", err)
-			continue
-		}
+	due, err := s.notifications.ListDue(ctx, time.Now())
+	if err != nil {
+		log.Printf("Error listing due notifications: %v", err)
+		return
+	}
 
-		err = s.sendNotification(&notification)
-		if err != nil {
-			log.Printf("Error sending notification %s: %v", notification.ID, err)
-			notification.Status = models.NotificationStatusFailed
-		} else {
-			notification.Status = models.NotificationStatusSent
-			sentTime := time.Now()
-			notification.SentAt = &sentTime
+	for _, notification := range due {
+		sendErr := s.sendNotification(&notification)
+		_, err := s.notifications.Update(ctx, notification.ID, func(stored *models.Notification) error {
+			if sendErr != nil {
+				stored.Status = models.NotificationStatusFailed
+			} else {
+				stored.Status = models.NotificationStatusSent
+				sentTime := time.Now()
+				stored.SentAt = &sentTime
+			}
+			stored.UpdatedAt = time.Now()
+			return nil
+		})
+		if sendErr != nil {
+			log.Printf("Error sending notification %s: %v", notification.ID, sendErr)
 		}
-
-		// Update notification status in Firestore
-		_, err = doc.Ref.Set(ctx, notification, firestore.MergeAll)
 		if err != nil {
 			log.Printf("Error updating notification status: %v", err)
 		}
@@ -134,79 +115,25 @@ func (s *NotificationScheduler) ScheduleNotification(ctx context.Context, req *m
 		Title:           req.Subject,
 		Content:         req.Content,
 		DeliveryChannel: string(req.Type),
+		Recipient:       req.Recipient,
 		ScheduledAt:     req.ScheduledAt,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 		Priority:        models.NotificationPriorityNormal,
 	}
 
-	_, err := s.firestoreClient.Collection(NOTIFICATIONS_COLLECTION).Doc(notification.ID).Set(ctx, notification)
-	return err
+	return s.notifications.Create(ctx, notification)
 }
 
 func (s *NotificationScheduler) CancelNotification(ctx context.Context, notificationID string) error {
-	_, err := s.firestoreClient.Collection(NOTIFICATIONS_COLLECTION).Doc(notificationID).Update(ctx, []firestore.Update{
-		{
-			Path:  "status",
-			Value: models.NotificationStatusCancelled,
-		},
-		{
-			Path:  "updatedAt",
-			Value: time.Now(),
-		},
+	_, err := s.notifications.Update(ctx, notificationID, func(notification *models.Notification) error {
+		notification.Status = models.NotificationStatusCancelled
+		notification.UpdatedAt = time.Now()
+		return nil
 	})
 	return err
 }
 
 func (s *NotificationScheduler) GetNotifications(ctx context.Context, userID string) ([]models.Notification, error) {
-	var notifications []models.Notification
-
-	query := s.firestoreClient.Collection(NOTIFICATIONS_COLLECTION).Where("userId", "==", userID)
-	iter := query.Documents(ctx)
-	defer iter.Stop()
-
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		var notification models.Notification
-		if err := doc.DataTo(&notification); err != nil {
-			return nil, err
-		}
-		notifications = append(notifications, notification)
-	}
-
-	return notifications, nil
-}
-
-// GetPendingNotifications returns all pending notifications
-func (s *NotificationScheduler) GetPendingNotifications(ctx context.Context) ([]models.Notification, error) {
-	var notifications []models.Notification
-
-	query := s.firestoreClient.Collection(NOTIFICATIONS_COLLECTION).Where("status", "==", models.NotificationStatusPending)
-	iter := query.Documents(ctx)
-	defer iter.Stop()
-
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		var notification models.Notification
-		if err := doc.DataTo(&notification); err != nil {
-			return nil, err
-		}
-		notifications = append(notifications, notification)
-	}
-
-	return notifications, nil
+	return s.notifications.ListByUser(ctx, userID, "")
 }
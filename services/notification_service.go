@@ -2,28 +2,25 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sort"
+	"log"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/google/uuid"
-	"github.com/rogerjeasy/go-letusconnect/mappers"
 	"github.com/rogerjeasy/go-letusconnect/models"
-	"google.golang.org/api/iterator"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 // NotificationService handles operations related to notifications
 type NotificationService struct {
-	firestoreClient FirestoreClient
+	notifications repository.NotificationRepository
 }
 
 // NewNotificationService creates a new NotificationService
-func NewNotificationService(client FirestoreClient) *NotificationService {
+func NewNotificationService(notifications repository.NotificationRepository) *NotificationService {
 	return &NotificationService{
-		firestoreClient: client,
+		notifications: notifications,
 	}
 }
 
@@ -33,26 +30,67 @@ func (s *NotificationService) CreateNotification(ctx context.Context, notificati
 	notification.CreatedAt = time.Now()
 	notification.UpdatedAt = time.Now()
 
-	_, err := s.firestoreClient.Collection("notifications").Doc(notification.ID).Set(ctx, mappers.MapNotificationGoToFirestore(notification))
-	if err != nil {
+	if err := s.notifications.Create(ctx, &notification); err != nil {
 		return nil, err
 	}
 
+	publishNotification(&notification)
 	return &notification, nil
 }
 
-// UpdateNotification updates an existing notification
-func (s *NotificationService) UpdateNotification(ctx context.Context, notificationID string, updates map[string]interface{}) (*models.Notification, error) {
-	docRef := s.firestoreClient.Collection("notifications").Doc(notificationID)
+// publishNotification sends a new notification to the realtime channels of its recipients,
+// the targeted users or else its user. Each recipient only sees their own read status.
+func publishNotification(notification *models.Notification) {
+	for _, uid := range notificationRecipients(notification) {
+		if err := Realtime.Publish(UserNotificationsChannel(uid), EventNewNotification, notificationFor(notification, uid)); err != nil {
+			log.Printf("Error publishing notification %s: %v", notification.ID, err)
+		}
+		if countsAsUnread(notification, uid) {
+			publishUnreadDelta(uid, UnreadNotifications, "", 1)
+		}
+	}
+}
 
-	err := s.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		doc, err := tx.Get(docRef)
-		if err != nil {
-			return err
+// publishNotificationChange tells the recipients of a notification that it was read, updated
+// or deleted
+func publishNotificationChange(notification *models.Notification, change string, uids []string) {
+	for _, uid := range uids {
+		payload := models.NotificationChange{NotificationID: notification.ID, Change: change}
+		if change != "deleted" {
+			published := notificationFor(notification, uid)
+			payload.Notification = &published
 		}
+		if err := Realtime.Publish(UserNotificationsChannel(uid), EventNotificationUpdated, payload); err != nil {
+			log.Printf("Error publishing the change of notification %s: %v", notification.ID, err)
+		}
+	}
+}
 
-		currentNotification := mappers.MapNotificationFirestoreToGo(doc.Data())
+// notificationRecipients lists the users a notification is sent to
+func notificationRecipients(notification *models.Notification) []string {
+	if len(notification.TargetedUsers) == 0 {
+		return []string{notification.UserID}
+	}
+	return notification.TargetedUsers
+}
 
+// notificationFor returns the notification as one of its recipients sees it
+func notificationFor(notification *models.Notification, uid string) models.Notification {
+	published := *notification
+	published.TargetedUsers = nil
+	published.ReadStatus = map[string]bool{uid: notification.ReadStatus[uid]}
+	return published
+}
+
+// countsAsUnread reports whether the notification is in the unread count of the user, which
+// only counts the notifications targeting them
+func countsAsUnread(notification *models.Notification, uid string) bool {
+	return containsString(notification.TargetedUsers, uid) && !notification.ReadStatus[uid]
+}
+
+// UpdateNotification updates an existing notification
+func (s *NotificationService) UpdateNotification(ctx context.Context, notificationID string, updates map[string]interface{}) (*models.Notification, error) {
+	notification, err := s.notifications.Update(ctx, notificationID, func(currentNotification *models.Notification) error {
 		for key, value := range updates {
 			switch key {
 			case "title":
@@ -66,247 +104,126 @@ func (s *NotificationService) UpdateNotification(ctx context.Context, notificati
 		}
 
 		currentNotification.UpdatedAt = time.Now()
-
-		return tx.Set(docRef, mappers.MapNotificationGoToFirestore(currentNotification))
+		return nil
 	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	updatedDoc, err := docRef.Get(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	updatedNotification := mappers.MapNotificationFirestoreToGo(updatedDoc.Data())
-	return &updatedNotification, nil
+	publishNotificationChange(notification, "updated", notificationRecipients(notification))
+	return notification, nil
 }
 
 // DeleteNotification deletes a notification
 func (s *NotificationService) DeleteNotification(ctx context.Context, notificationID string) error {
-	_, err := s.firestoreClient.Collection("notifications").Doc(notificationID).Delete(ctx)
-	return err
+	notification, err := s.notifications.Get(ctx, notificationID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err := s.notifications.Delete(ctx, notificationID); err != nil {
+		return err
+	}
+	if notification == nil {
+		return nil
+	}
+
+	recipients := notificationRecipients(notification)
+	publishNotificationChange(notification, "deleted", recipients)
+	for _, uid := range recipients {
+		if countsAsUnread(notification, uid) {
+			publishUnreadDelta(uid, UnreadNotifications, "", -1)
+		}
+	}
+	return nil
 }
 
 // GetNotification fetches a single notification by ID
 func (s *NotificationService) GetNotification(ctx context.Context, notificationID string) (*models.Notification, error) {
-	doc, err := s.firestoreClient.Collection("notifications").Doc(notificationID).Get(ctx)
+	notification, err := s.notifications.Get(ctx, notificationID)
 	if err != nil {
-		if status.Code(err) == codes.NotFound {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	notification := mappers.MapNotificationFirestoreToGo(doc.Data())
-	return &notification, nil
+	return notification, nil
 }
 
 // ListNotifications fetches notifications for a user
 func (s *NotificationService) ListNotifications(ctx context.Context, userID string, limit int, lastNotificationID string) ([]models.Notification, error) {
-	// query := s.firestoreClient.Collection("notifications").Where("user_id", "==", userID).OrderBy("created_at", firestore.Desc).Limit(limit)
-	query := s.firestoreClient.Collection("notifications").Where("user_id", "==", userID)
-
-	if lastNotificationID != "" {
-		lastDoc, err := s.firestoreClient.Collection("notifications").Doc(lastNotificationID).Get(ctx)
-		if err != nil {
-			return nil, err
-		}
-		query = query.StartAfter(lastDoc)
-	}
-
-	iter := query.Documents(ctx)
-	var notifications []models.Notification
-
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		notification := mappers.MapNotificationFirestoreToGo(doc.Data())
-		notifications = append(notifications, notification)
-	}
-
-	return notifications, nil
+	return s.notifications.ListByUser(ctx, userID, lastNotificationID)
 }
 
 // MarkNotificationAsRead marks a notification as read for a specific user
 func (s *NotificationService) MarkNotificationAsRead(ctx context.Context, notificationID string, userID string) error {
-	// First get the current notification to access the existing read_status map
-	docRef := s.firestoreClient.Collection("notifications").Doc(notificationID)
-	doc, err := docRef.Get(ctx)
-	if err != nil {
+	if _, err := s.notifications.Get(ctx, notificationID); err != nil {
 		return fmt.Errorf("failed to get notification: %v", err)
 	}
 
-	// Get current read status map
-	readStatus := make(map[string]bool)
-	currentReadStatus, exists := doc.Data()["read_status"]
-	if exists && currentReadStatus != nil {
-		// Type assert to map[string]interface{} first
-		if readStatusMap, ok := currentReadStatus.(map[string]interface{}); ok {
-			// Convert each value to bool
-			for k, v := range readStatusMap {
-				if boolVal, ok := v.(bool); ok {
-					readStatus[k] = boolVal
-				}
-			}
+	var wasUnread bool
+	notification, err := s.notifications.Update(ctx, notificationID, func(notification *models.Notification) error {
+		if notification.ReadStatus == nil {
+			notification.ReadStatus = make(map[string]bool)
 		}
-	}
-
-	// Update read status for the specific user
-	readStatus[userID] = true
-
-	// Update the document
-	_, err = docRef.Update(ctx, []firestore.Update{
-		{Path: "read_status", Value: readStatus},
-		{Path: "updated_at", Value: time.Now()},
-		{Path: "read_at", Value: time.Now()},
+		wasUnread = countsAsUnread(notification, userID)
+
+		// Update read status for the specific user
+		now := time.Now()
+		notification.ReadStatus[userID] = true
+		notification.UpdatedAt = now
+		notification.ReadAt = &now
+		return nil
 	})
 	if err != nil {
 		return fmt.Errorf("failed to update notification: %v", err)
 	}
 
+	publishNotificationChange(notification, "read", []string{userID})
+	if wasUnread {
+		publishUnreadDelta(userID, UnreadNotifications, "", -1)
+	}
 	return nil
 }
 
 // ListTargetedNotifications fetches notifications where the user is in the targeted_users list
 func (s *NotificationService) ListTargetedNotifications(ctx context.Context, userID string, limit int, lastNotificationID string) ([]models.Notification, error) {
-	// Create a query for notifications where userID is in targeted_users array
-	query := s.firestoreClient.Collection("notifications").Where("targeted_users", "array-contains", userID)
-
-	// Add pagination if lastNotificationID is provided
-	if lastNotificationID != "" {
-		lastDoc, err := s.firestoreClient.Collection("notifications").Doc(lastNotificationID).Get(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get last notification: %v", err)
-		}
-		query = query.StartAfter(lastDoc)
-	}
-
-	// Add ordering and limit
-	query = query.OrderBy("created_at", firestore.Desc).OrderBy("__name__", firestore.Desc)
-	if limit > 0 {
-		query = query.Limit(limit)
+	notifications, err := s.notifications.ListTargeted(ctx, userID, limit, lastNotificationID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Execute query with error handling for missing index
-	iter := query.Documents(ctx)
-	defer iter.Stop()
-
-	var notifications []models.Notification
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			// Check if the error is due to missing index
-			if status.Code(err) == codes.FailedPrecondition {
-				// Fallback to unordered query if index is not ready
-				return s.listTargetedNotificationsWithoutOrdering(ctx, userID, limit)
-			}
-			return nil, fmt.Errorf("error iterating notifications: %v", err)
-		}
-
-		notification := mappers.MapNotificationFirestoreToGo(doc.Data())
-
+	for i := range notifications {
 		// Initialize read status map if nil
-		if notification.ReadStatus == nil {
-			notification.ReadStatus = make(map[string]bool)
+		if notifications[i].ReadStatus == nil {
+			notifications[i].ReadStatus = make(map[string]bool)
 		}
-		if _, exists := notification.ReadStatus[userID]; !exists {
-			notification.ReadStatus[userID] = false
+		if _, exists := notifications[i].ReadStatus[userID]; !exists {
+			notifications[i].ReadStatus[userID] = false
 		}
 
 		// Initialize archived status map if nil
-		if notification.IsArchived == nil {
-			notification.IsArchived = make(map[string]bool)
-		}
-		if _, exists := notification.IsArchived[userID]; !exists {
-			notification.IsArchived[userID] = false
-		}
-
-		notifications = append(notifications, notification)
-	}
-
-	return notifications, nil
-}
-
-// Fallback function for when index is not ready
-func (s *NotificationService) listTargetedNotificationsWithoutOrdering(ctx context.Context, userID string, limit int) ([]models.Notification, error) {
-	// Simple query without ordering
-	query := s.firestoreClient.Collection("notifications").Where("targeted_users", "array-contains", userID)
-	if limit > 0 {
-		query = query.Limit(limit)
-	}
-
-	iter := query.Documents(ctx)
-	defer iter.Stop()
-
-	var notifications []models.Notification
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
+		if notifications[i].IsArchived == nil {
+			notifications[i].IsArchived = make(map[string]bool)
 		}
-		if err != nil {
-			return nil, fmt.Errorf("error in fallback query: %v", err)
+		if _, exists := notifications[i].IsArchived[userID]; !exists {
+			notifications[i].IsArchived[userID] = false
 		}
-
-		notification := mappers.MapNotificationFirestoreToGo(doc.Data())
-
-		// Initialize maps if nil
-		if notification.ReadStatus == nil {
-			notification.ReadStatus = make(map[string]bool)
-		}
-		if _, exists := notification.ReadStatus[userID]; !exists {
-			notification.ReadStatus[userID] = false
-		}
-
-		if notification.IsArchived == nil {
-			notification.IsArchived = make(map[string]bool)
-		}
-		if _, exists := notification.IsArchived[userID]; !exists {
-			notification.IsArchived[userID] = false
-		}
-
-		notifications = append(notifications, notification)
 	}
 
-	// Sort notifications by created_at manually since we can't use Firestore ordering
-	sort.Slice(notifications, func(i, j int) bool {
-		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
-	})
-
 	return notifications, nil
 }
 
 // CountUnreadNotifications counts notifications where ReadStatus[userID] is false
 func (s *NotificationService) CountUnreadNotifications(ctx context.Context, userID string) (int64, error) {
-	query := s.firestoreClient.Collection("notifications").Where("targeted_users", "array-contains", userID)
-
-	iter := query.Documents(ctx)
-	defer iter.Stop()
+	notifications, err := s.notifications.ListTargeted(ctx, userID, 0, "")
+	if err != nil {
+		return 0, fmt.Errorf("error counting unread notifications: %v", err)
+	}
 
 	var unreadCount int64 = 0
 
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return 0, fmt.Errorf("error counting unread notifications: %v", err)
-		}
-
-		notification := mappers.MapNotificationFirestoreToGo(doc.Data())
-
+	for _, notification := range notifications {
 		// Simply check if ReadStatus[userID] is false
 		if !notification.ReadStatus[userID] {
 			unreadCount++
@@ -317,26 +234,17 @@ func (s *NotificationService) CountUnreadNotifications(ctx context.Context, user
 }
 
 func (s *NotificationService) GetNotificationStats(ctx context.Context, userID string) (models.NotificationStats, error) {
-	query := s.firestoreClient.Collection("notifications").Where("targeted_users", "array-contains", userID)
-
-	iter := query.Documents(ctx)
-	defer iter.Stop()
-
 	stats := models.NotificationStats{
 		PriorityStats: make(map[string]int64),
 		TypeStats:     make(map[string]int64),
 	}
 
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return stats, fmt.Errorf("error getting notification stats: %v", err)
-		}
+	notifications, err := s.notifications.ListTargeted(ctx, userID, 0, "")
+	if err != nil {
+		return stats, fmt.Errorf("error getting notification stats: %v", err)
+	}
 
-		notification := mappers.MapNotificationFirestoreToGo(doc.Data())
+	for _, notification := range notifications {
 		stats.TotalCount++
 
 		// Simply check ReadStatus[userID]
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rogerjeasy/go-letusconnect/config"
+)
+
+// jwksRefreshInterval is how often the keys of a provider are fetched again
+// when an ID token names a key they do not have
+const jwksRefreshInterval = time.Minute
+
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ExternalProfile is a user as described by an OAuth provider
+type ExternalProfile struct {
+	// Subject is the stable ID of the account at the provider
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	DisplayName   string
+	Username      string
+	PictureURL    string
+}
+
+// OAuthProvider is an OAuth2 provider the users sign in with. Providers with an Issuer
+// use OpenID Connect: their endpoints come from the discovery document and the user
+// from the ID token, verified with the published keys.
+type OAuthProvider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	Issuer       string
+	AuthURL      string
+	TokenURL     string
+	Scopes       []string
+	// fetchProfile reads the user from a provider without OpenID Connect
+	fetchProfile func(ctx context.Context, client *http.Client, accessToken string) (*ExternalProfile, error)
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]interface{}
+	keysAt    time.Time
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oauthTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// flexibleBool reads booleans that some providers send as strings
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseBool(strings.Trim(string(data), `"`))
+	if err != nil {
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	*b = flexibleBool(value)
+	return nil
+}
+
+type idTokenClaims struct {
+	Nonce             string       `json:"nonce"`
+	AuthorizedParty   string       `json:"azp"`
+	Email             string       `json:"email"`
+	EmailVerified     flexibleBool `json:"email_verified"`
+	Name              string       `json:"name"`
+	GivenName         string       `json:"given_name"`
+	FamilyName        string       `json:"family_name"`
+	Picture           string       `json:"picture"`
+	PreferredUsername string       `json:"preferred_username"`
+	jwt.RegisteredClaims
+}
+
+// OAuthProvidersFromConfig returns the providers configured in the environment:
+// Google, GitHub and LinkedIn when their client credentials are set, and the
+// OpenID Connect providers listed in OIDC_PROVIDERS
+func OAuthProvidersFromConfig() ([]*OAuthProvider, error) {
+	var providers []*OAuthProvider
+
+	if config.GoogleClientID != "" && config.GoogleClientSecret != "" {
+		providers = append(providers, &OAuthProvider{
+			Name:         "google",
+			ClientID:     config.GoogleClientID,
+			ClientSecret: config.GoogleClientSecret,
+			Issuer:       "https://accounts.google.com",
+			Scopes:       []string{"openid", "email", "profile"},
+		})
+	}
+	if config.GithubClientID != "" && config.GithubClientSecret != "" {
+		providers = append(providers, &OAuthProvider{
+			Name:         "github",
+			ClientID:     config.GithubClientID,
+			ClientSecret: config.GithubClientSecret,
+			AuthURL:      "https://github.com/login/oauth/authorize",
+			TokenURL:     "https://github.com/login/oauth/access_token",
+			Scopes:       []string{"read:user", "user:email"},
+			fetchProfile: fetchGitHubProfile,
+		})
+	}
+	if config.LinkedInClientID != "" && config.LinkedInClientSecret != "" {
+		providers = append(providers, &OAuthProvider{
+			Name:         "linkedin",
+			ClientID:     config.LinkedInClientID,
+			ClientSecret: config.LinkedInClientSecret,
+			Issuer:       "https://www.linkedin.com/oauth",
+			Scopes:       []string{"openid", "email", "profile"},
+		})
+	}
+
+	if strings.TrimSpace(config.OIDCProviders) == "" {
+		return providers, nil
+	}
+
+	var generic []struct {
+		Name         string   `json:"name"`
+		Issuer       string   `json:"issuer"`
+		ClientID     string   `json:"clientId"`
+		ClientSecret string   `json:"clientSecret"`
+		Scopes       []string `json:"scopes"`
+	}
+	if err := json.Unmarshal([]byte(config.OIDCProviders), &generic); err != nil {
+		return nil, fmt.Errorf("failed to parse OIDC_PROVIDERS: %v", err)
+	}
+	for _, entry := range generic {
+		if !providerNamePattern.MatchString(entry.Name) {
+			return nil, fmt.Errorf("invalid OIDC provider name %q", entry.Name)
+		}
+		if entry.Issuer == "" || entry.ClientID == "" {
+			return nil, fmt.Errorf("OIDC provider %q needs an issuer and a clientId", entry.Name)
+		}
+		scopes := entry.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+		providers = append(providers, &OAuthProvider{
+			Name:         entry.Name,
+			ClientID:     entry.ClientID,
+			ClientSecret: entry.ClientSecret,
+			Issuer:       strings.TrimSuffix(entry.Issuer, "/"),
+			Scopes:       scopes,
+		})
+	}
+	return providers, nil
+}
+
+// endpoints returns the authorization and token endpoints of the provider
+func (p *OAuthProvider) endpoints(ctx context.Context, client *http.Client) (string, string, error) {
+	if p.Issuer == "" || (p.AuthURL != "" && p.TokenURL != "") {
+		return p.AuthURL, p.TokenURL, nil
+	}
+	discovery, err := p.discover(ctx, client)
+	if err != nil {
+		return "", "", err
+	}
+	return discovery.AuthorizationEndpoint, discovery.TokenEndpoint, nil
+}
+
+// discover fetches the OpenID Connect discovery document of the provider once
+func (p *OAuthProvider) discover(ctx context.Context, client *http.Client) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, client, p.Issuer+"/.well-known/openid-configuration", "", &discovery); err != nil {
+		return nil, fmt.Errorf("failed to fetch the discovery document of %s: %v", p.Name, err)
+	}
+	if discovery.Issuer != p.Issuer {
+		return nil, fmt.Errorf("discovery document of %s names issuer %q", p.Name, discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document of %s is incomplete", p.Name)
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// signingKey returns the published key that signs ID tokens with the given "kid"
+func (p *OAuthProvider) signingKey(ctx context.Context, client *http.Client, kid string) (interface{}, error) {
+	discovery, err := p.discover(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if !p.keysAt.IsZero() && time.Since(p.keysAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set JSONWebKeySet
+	if err := getJSON(ctx, client, discovery.JWKSURI, "", &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch the keys of %s: %v", p.Name, err)
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := parseJSONWebKey(jwk); err == nil {
+			keys[jwk.KeyID] = key
+		}
+	}
+	p.keys = keys
+	p.keysAt = time.Now()
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// parseJSONWebKey reads an RSA or EC public key
+func parseJSONWebKey(jwk JSONWebKey) (interface{}, error) {
+	decode := func(value string) (*big.Int, error) {
+		raw, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(raw) == 0 {
+			return nil, fmt.Errorf("invalid key parameter")
+		}
+		return new(big.Int).SetBytes(raw), nil
+	}
+
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil || !e.IsInt64() {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("invalid EC key")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+	}
+}
+
+// exchange trades an authorization code and its PKCE verifier for tokens
+func (p *OAuthProvider) exchange(ctx context.Context, client *http.Client, tokenURL, code, redirectURI, verifier string) (*oauthTokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var tokens oauthTokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %v", err)
+	}
+	if tokens.Error != "" {
+		return nil, fmt.Errorf("%s refused the code: %s %s", p.Name, tokens.Error, tokens.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || tokens.AccessToken == "" {
+		return nil, fmt.Errorf("%s token endpoint returned status %d", p.Name, resp.StatusCode)
+	}
+	return &tokens, nil
+}
+
+// verifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token
+func (p *OAuthProvider) verifyIDToken(ctx context.Context, client *http.Client, raw, nonce string) (*ExternalProfile, error) {
+	discovery, err := p.discover(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &idTokenClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.signingKey(ctx, client, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %v", err)
+	}
+
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("ID token has no expiry")
+	}
+	if !claims.VerifyIssuer(discovery.Issuer, true) {
+		return nil, fmt.Errorf("ID token was issued by %q", claims.Issuer)
+	}
+	if !claims.VerifyAudience(p.ClientID, true) {
+		return nil, errors.New("ID token was issued to another client")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.ClientID {
+		return nil, errors.New("ID token was issued to another client")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("ID token nonce does not match")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+
+	return &ExternalProfile{
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: bool(claims.EmailVerified),
+		FirstName:     claims.GivenName,
+		LastName:      claims.FamilyName,
+		DisplayName:   claims.Name,
+		Username:      claims.PreferredUsername,
+		PictureURL:    claims.Picture,
+	}, nil
+}
+
+// fetchGitHubProfile reads the GitHub user and their primary verified email address
+func fetchGitHubProfile(ctx context.Context, client *http.Client, accessToken string) (*ExternalProfile, error) {
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(ctx, client, "https://api.github.com/user", accessToken, &user); err != nil {
+		return nil, fmt.Errorf("failed to fetch GitHub user: %v", err)
+	}
+	if user.ID == 0 {
+		return nil, errors.New("GitHub returned no user")
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, "https://api.github.com/user/emails", accessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed to fetch GitHub emails: %v", err)
+	}
+
+	profile := &ExternalProfile{
+		Subject:     strconv.FormatInt(user.ID, 10),
+		DisplayName: user.Name,
+		Username:    user.Login,
+		PictureURL:  user.AvatarURL,
+	}
+	if first, last, found := strings.Cut(strings.TrimSpace(user.Name), " "); found {
+		profile.FirstName, profile.LastName = first, strings.TrimSpace(last)
+	} else {
+		profile.FirstName = first
+	}
+	for _, email := range emails {
+		if email.Primary {
+			profile.Email = strings.ToLower(email.Email)
+			profile.EmailVerified = email.Verified
+		}
+	}
+	return profile, nil
+}
+
+// getJSON decodes the JSON answer of a GET request, sent with a bearer token when one is given
+func getJSON(ctx context.Context, client *http.Client, endpoint, accessToken string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(target)
+}
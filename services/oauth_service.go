@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+// oauthStateTTL is how long a user has to come back from the provider
+const oauthStateTTL = 10 * time.Minute
+
+var (
+	ErrUnknownOAuthProvider = errors.New("unknown sign-in provider")
+	ErrInvalidOAuthState    = errors.New("sign-in request is invalid or expired, please try again")
+	// ErrOAuthLinkForbidden is returned when a link is completed by someone other than the
+	// signed-in user who started it
+	ErrOAuthLinkForbidden = errors.New("sign in as the user who started linking the account")
+	// ErrOAuthAccountExists is returned when the email of a provider account belongs to a user
+	// and either side did not verify it. The user must sign in first and then link the provider.
+	ErrOAuthAccountExists = errors.New("an account already uses this email address, sign in and link the provider from your settings")
+	// ErrIdentityLinkedElsewhere is returned when linking a provider account that another user linked
+	ErrIdentityLinkedElsewhere = errors.New("this provider account is linked to another user")
+	ErrOAuthEmailMissing       = errors.New("the provider did not share an email address")
+	// ErrLastSignInMethod is returned when unlinking the only way a user without a password signs in
+	ErrLastSignInMethod = errors.New("set a password or link another provider before unlinking this one")
+)
+
+// OAuthResult is the outcome of an authorization
+type OAuthResult struct {
+	Provider string
+	Profile  *ExternalProfile
+	// User is nil when no user has the identity or its email address yet
+	User *models.User
+	// Linked tells that the authorization linked the identity to User
+	Linked bool
+	// LinkOnly tells that the authorization was started by a signed-in user to link a
+	// provider, and must not sign anyone in
+	LinkOnly bool
+}
+
+// OAuthService signs users in with OAuth2 and OpenID Connect providers, using the
+// authorization code flow with PKCE, and links the provider accounts to the users
+type OAuthService struct {
+	providers  map[string]*OAuthProvider
+	names      []string
+	states     repository.OAuthStateRepository
+	identities repository.IdentityRepository
+	users      repository.UserRepository
+	// passwords tells whether users can still sign in after unlinking a provider
+	passwords PasswordProvider
+	// redirectURL is where providers send the users back, "{provider}" is replaced by its name
+	redirectURL string
+	httpClient  *http.Client
+}
+
+func NewOAuthService(providers []*OAuthProvider, states repository.OAuthStateRepository, identities repository.IdentityRepository, users repository.UserRepository, passwords PasswordProvider, redirectURL string) *OAuthService {
+	s := &OAuthService{
+		providers:   make(map[string]*OAuthProvider, len(providers)),
+		states:      states,
+		identities:  identities,
+		users:       users,
+		passwords:   passwords,
+		redirectURL: redirectURL,
+		httpClient:  &http.Client{Timeout: 15 * time.Second},
+	}
+	for _, provider := range providers {
+		if _, exists := s.providers[provider.Name]; !exists {
+			s.names = append(s.names, provider.Name)
+		}
+		s.providers[provider.Name] = provider
+	}
+	return s
+}
+
+// IdentityID returns the ID of the identity of an account at a provider
+func IdentityID(provider, subject string) string {
+	return provider + ":" + url.PathEscape(subject)
+}
+
+// Providers lists the names of the configured providers
+func (s *OAuthService) Providers() []string {
+	return append([]string(nil), s.names...)
+}
+
+// RedirectURL returns the address the provider sends the user back to
+func (s *OAuthService) RedirectURL(provider string) string {
+	return strings.ReplaceAll(s.redirectURL, "{provider}", provider)
+}
+
+// Authorize starts an authorization with the provider and returns the address to send the
+// user to, with its state. linkUID names the signed-in user the identity is linked to; it is
+// empty to sign in. The caller keeps the state ID in the browser of the user, which must hand
+// it back to Complete.
+func (s *OAuthService) Authorize(ctx context.Context, providerName, linkUID string) (string, *models.OAuthState, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", nil, ErrUnknownOAuthProvider
+	}
+
+	authURL, _, err := provider.endpoints(ctx, s.httpClient)
+	if err != nil {
+		return "", nil, err
+	}
+
+	stateID, err := randomURLToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	verifier, err := randomURLToken(48)
+	if err != nil {
+		return "", nil, err
+	}
+	nonce, err := randomURLToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	state := &models.OAuthState{
+		ID:           stateID,
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		LinkUID:      linkUID,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(oauthStateTTL),
+	}
+	if err := s.states.Create(ctx, state); err != nil {
+		return "", nil, fmt.Errorf("failed to save authorization state: %v", err)
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {provider.ClientID},
+		"redirect_uri":          {s.RedirectURL(providerName)},
+		"scope":                 {strings.Join(provider.Scopes, " ")},
+		"state":                 {stateID},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if provider.Issuer != "" {
+		query.Set("nonce", nonce)
+	}
+
+	separator := "?"
+	if strings.Contains(authURL, "?") {
+		separator = "&"
+	}
+	return authURL + separator + query.Encode(), state, nil
+}
+
+// Complete finishes an authorization with the code the provider sent back. browserStateID is
+// the state ID kept by the browser that started the authorization, so that a callback URL
+// handed to someone else is refused. It links the identity to the user who started a link,
+// who must be the caller, and otherwise resolves its user with Resolve.
+func (s *OAuthService) Complete(ctx context.Context, providerName, code, stateID, browserStateID, callerUID string) (*OAuthResult, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownOAuthProvider
+	}
+	if browserStateID == "" || subtle.ConstantTimeCompare([]byte(browserStateID), []byte(stateID)) != 1 {
+		return nil, ErrInvalidOAuthState
+	}
+
+	state, err := s.consumeState(ctx, providerName, stateID)
+	if err != nil {
+		return nil, err
+	}
+	if state.LinkUID != "" && state.LinkUID != callerUID {
+		return nil, ErrOAuthLinkForbidden
+	}
+
+	profile, err := s.fetchProfile(ctx, provider, code, state)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.LinkUID != "" {
+		if _, err := s.Link(ctx, state.LinkUID, providerName, profile); err != nil {
+			return nil, err
+		}
+		user, err := s.users.GetByUID(ctx, state.LinkUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch user: %v", err)
+		}
+		return &OAuthResult{Provider: providerName, Profile: profile, User: user, Linked: true, LinkOnly: true}, nil
+	}
+
+	return s.Resolve(ctx, providerName, profile)
+}
+
+// Resolve finds the user of an account at a provider that was verified by the caller.
+// The identity of an email address verified by both the provider and the user is linked
+// to the user; OAuthResult.User is
+// nil when nobody has the identity or the email address yet.
+func (s *OAuthService) Resolve(ctx context.Context, providerName string, profile *ExternalProfile) (*OAuthResult, error) {
+	result := &OAuthResult{Provider: providerName, Profile: profile}
+
+	identity, err := s.identities.Get(ctx, IdentityID(providerName, profile.Subject))
+	if err == nil {
+		user, err := s.users.GetByUID(ctx, identity.UID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch user: %v", err)
+		}
+		if _, err := s.Link(ctx, user.UID, providerName, profile); err != nil {
+			return nil, err
+		}
+		result.User = user
+		return result, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to fetch identity: %v", err)
+	}
+
+	if profile.Email == "" {
+		return nil, ErrOAuthEmailMissing
+	}
+	user, err := s.users.GetByEmail(ctx, profile.Email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %v", err)
+	}
+
+	// Only a provider that verified the address proves the user owns the account, and only
+	// if the account verified it too: anyone can register an address they do not own
+	if !profile.EmailVerified || !user.IsVerified {
+		return nil, ErrOAuthAccountExists
+	}
+	if _, err := s.Link(ctx, user.UID, providerName, profile); err != nil {
+		return nil, err
+	}
+	result.User, result.Linked = user, true
+	return result, nil
+}
+
+// Link links the account at the provider to the user, or records a new login
+// when it is already linked to them
+func (s *OAuthService) Link(ctx context.Context, uid, providerName string, profile *ExternalProfile) (*models.Identity, error) {
+	id := IdentityID(providerName, profile.Subject)
+	now := time.Now()
+
+	identity, err := s.identities.Get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		identity = &models.Identity{
+			ID:       id,
+			UID:      uid,
+			Provider: providerName,
+			Subject:  profile.Subject,
+			LinkedAt: now,
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch identity: %v", err)
+	} else if identity.UID != uid {
+		return nil, ErrIdentityLinkedElsewhere
+	}
+
+	identity.Email = profile.Email
+	identity.EmailVerified = profile.EmailVerified
+	identity.LastLoginAt = now
+	if err := s.identities.Put(ctx, identity); err != nil {
+		return nil, fmt.Errorf("failed to save identity: %v", err)
+	}
+	return identity, nil
+}
+
+// ListIdentities lists the provider accounts linked to the user
+func (s *OAuthService) ListIdentities(ctx context.Context, uid string) ([]models.Identity, error) {
+	identities, err := s.identities.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch identities: %v", err)
+	}
+	return identities, nil
+}
+
+// Unlink removes a provider account from the user. Users keep at least one way to sign in.
+func (s *OAuthService) Unlink(ctx context.Context, user *models.User, identityID string) error {
+	identity, err := s.identities.Get(ctx, identityID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && identity.UID != user.UID) {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch identity: %v", err)
+	}
+
+	identities, err := s.ListIdentities(ctx, user.UID)
+	if err != nil {
+		return err
+	}
+	if len(identities) <= 1 {
+		hasPassword, err := s.passwords.HasPassword(ctx, user)
+		if err != nil {
+			return err
+		}
+		if !hasPassword {
+			return ErrLastSignInMethod
+		}
+	}
+
+	if err := s.identities.Delete(ctx, identityID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to delete identity: %v", err)
+	}
+	return nil
+}
+
+// consumeState marks the state of an authorization as used. Each state works once.
+func (s *OAuthService) consumeState(ctx context.Context, providerName, stateID string) (*models.OAuthState, error) {
+	if stateID == "" {
+		return nil, ErrInvalidOAuthState
+	}
+
+	state, err := s.states.Update(ctx, stateID, func(state *models.OAuthState) error {
+		if state.UsedAt != nil || state.Provider != providerName || time.Now().After(state.ExpiresAt) {
+			return ErrInvalidOAuthState
+		}
+		now := time.Now()
+		state.UsedAt = &now
+		return nil
+	})
+	if errors.Is(err, repository.ErrNotFound) || errors.Is(err, ErrInvalidOAuthState) {
+		return nil, ErrInvalidOAuthState
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to use authorization state: %v", err)
+	}
+	return state, nil
+}
+
+// fetchProfile exchanges the code and reads the user, from the ID token with OpenID Connect
+func (s *OAuthService) fetchProfile(ctx context.Context, provider *OAuthProvider, code string, state *models.OAuthState) (*ExternalProfile, error) {
+	_, tokenURL, err := provider.endpoints(ctx, s.httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := provider.exchange(ctx, s.httpClient, tokenURL, code, s.RedirectURL(provider.Name), state.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if provider.Issuer != "" {
+		if tokens.IDToken == "" {
+			return nil, fmt.Errorf("%s returned no ID token", provider.Name)
+		}
+		return provider.verifyIDToken(ctx, s.httpClient, tokens.IDToken, state.Nonce)
+	}
+	return provider.fetchProfile(ctx, s.httpClient, tokens.AccessToken)
+}
+
+// randomURLToken returns size random bytes, base64url encoded
+func randomURLToken(size int) (string, error) {
+	random := make([]byte, size)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
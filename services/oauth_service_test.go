@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOAuthClientID = "letusconnect-test"
+
+// fakeOIDCGrant is what the fake provider knows about an authorization code
+type fakeOIDCGrant struct {
+	challenge     string
+	nonce         string
+	subject       string
+	email         string
+	emailVerified bool
+}
+
+// fakeOIDCProvider is an OpenID Connect provider that signs ID tokens with an RSA key and
+// checks the PKCE verifier of the codes it hands out
+type fakeOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]fakeOIDCGrant
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &fakeOIDCProvider{key: key, grants: map[string]fakeOIDCGrant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                p.server.URL,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+			JWKSURI:               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(JSONWebKeySet{Keys: []JSONWebKey{{
+			KeyID:   "test",
+			KeyType: "RSA",
+			Use:     "sig",
+			N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// token exchanges a code for an ID token once its PKCE verifier matches the challenge
+func (p *fakeOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	grant, ok := p.grants[r.FormValue("code")]
+	delete(p.grants, r.FormValue("code"))
+	p.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	if !ok || r.FormValue("client_id") != testOAuthClientID ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, idTokenClaims{
+		Nonce:         grant.nonce,
+		Email:         grant.email,
+		EmailVerified: flexibleBool(grant.emailVerified),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.server.URL,
+			Subject:   grant.subject,
+			Audience:  jwt.ClaimStrings{testOAuthClientID},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	token.Header["kid"] = "test"
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "id_token": idToken})
+}
+
+// authorize plays the user consenting at the provider: it reads the PKCE challenge and the
+// nonce of the authorization URL, and returns the code the provider sends back
+func (p *fakeOIDCProvider) authorize(t *testing.T, authURL string, grant fakeOIDCGrant) string {
+	t.Helper()
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, p.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, testOAuthClientID, query.Get("client_id"))
+	assert.Equal(t, "https://app.example.com/auth/callback/test", query.Get("redirect_uri"))
+	require.NotEmpty(t, query.Get("nonce"))
+
+	if grant.challenge == "" {
+		grant.challenge = query.Get("code_challenge")
+	}
+	if grant.nonce == "" {
+		grant.nonce = query.Get("nonce")
+	}
+	code, err := randomURLToken(16)
+	require.NoError(t, err)
+	p.mu.Lock()
+	p.grants[code] = grant
+	p.mu.Unlock()
+	return code
+}
+
+func newTestOAuthService(t *testing.T, provider *fakeOIDCProvider) (*OAuthService, *models.User) {
+	t.Helper()
+	users := repository.NewMemoryUserRepository()
+	user := &models.User{UID: "user-1", Email: "ada@example.com", IsVerified: true}
+	require.NoError(t, users.Create(context.Background(), user))
+	// user-2 registered an address they never verified
+	require.NoError(t, users.Create(context.Background(), &models.User{UID: "user-2", Email: "linus@example.com"}))
+
+	service := NewOAuthService([]*OAuthProvider{
+		{Name: "test", ClientID: testOAuthClientID, Issuer: provider.server.URL, Scopes: []string{"openid", "email"}},
+		{Name: "other", ClientID: testOAuthClientID, Issuer: provider.server.URL, Scopes: []string{"openid", "email"}},
+	}, repository.NewMemoryOAuthStateRepository(), repository.NewMemoryIdentityRepository(), users,
+		NewLocalIdentityProvider(repository.NewMemoryCredentialRepository(), HashBcrypt, nil),
+		"https://app.example.com/auth/callback/{provider}")
+	return service, user
+}
+
+func TestOAuthServiceComplete(t *testing.T) {
+	newcomer := fakeOIDCGrant{subject: "sub-new", email: "grace@example.com", emailVerified: true}
+	existing := fakeOIDCGrant{subject: "sub-ada", email: "ada@example.com", emailVerified: true}
+
+	tests := []struct {
+		name  string
+		grant fakeOIDCGrant
+		// linkUID starts a link instead of a sign-in, callerUID completes it
+		linkUID   string
+		callerUID string
+		// browserState returns the state the browser hands back, the state ID when nil
+		browserState func(stateID string) string
+		provider     string
+		// twice completes the same authorization a second time
+		twice        bool
+		wantErr      error
+		wantAnyErr   bool
+		wantUID      string
+		wantLinked   bool
+		wantLinkOnly bool
+	}{
+		{name: "new user", grant: newcomer},
+		{name: "verified email of a user links it", grant: existing, wantUID: "user-1", wantLinked: true},
+		{
+			name:    "unverified email of a user",
+			grant:   fakeOIDCGrant{subject: "sub-ada", email: "ada@example.com"},
+			wantErr: ErrOAuthAccountExists,
+		},
+		{
+			name:    "verified email of a user who did not verify it",
+			grant:   fakeOIDCGrant{subject: "sub-linus", email: "linus@example.com", emailVerified: true},
+			wantErr: ErrOAuthAccountExists,
+		},
+		{name: "no email", grant: fakeOIDCGrant{subject: "sub-anonymous"}, wantErr: ErrOAuthEmailMissing},
+		{
+			name:         "link by the user who started it",
+			grant:        newcomer,
+			linkUID:      "user-1",
+			callerUID:    "user-1",
+			wantUID:      "user-1",
+			wantLinked:   true,
+			wantLinkOnly: true,
+		},
+		{name: "link by someone else", grant: newcomer, linkUID: "user-1", callerUID: "user-2", wantErr: ErrOAuthLinkForbidden},
+		{name: "link without a signed-in caller", grant: newcomer, linkUID: "user-1", wantErr: ErrOAuthLinkForbidden},
+		{
+			name:         "callback without the state cookie",
+			grant:        newcomer,
+			browserState: func(string) string { return "" },
+			wantErr:      ErrInvalidOAuthState,
+		},
+		{
+			name:         "callback from another browser",
+			grant:        newcomer,
+			browserState: func(string) string { return "state-of-another-browser" },
+			wantErr:      ErrInvalidOAuthState,
+		},
+		{name: "state used twice", grant: newcomer, twice: true, wantErr: ErrInvalidOAuthState},
+		{name: "state of another provider", grant: newcomer, provider: "other", wantErr: ErrInvalidOAuthState},
+		{name: "unknown provider", grant: newcomer, provider: "missing", wantErr: ErrUnknownOAuthProvider},
+		{
+			name:       "wrong PKCE verifier",
+			grant:      fakeOIDCGrant{subject: "sub-new", email: "grace@example.com", challenge: "another-challenge"},
+			wantAnyErr: true,
+		},
+		{
+			name:       "ID token for another nonce",
+			grant:      fakeOIDCGrant{subject: "sub-new", email: "grace@example.com", nonce: "another-nonce"},
+			wantAnyErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			provider := newFakeOIDCProvider(t)
+			service, _ := newTestOAuthService(t, provider)
+
+			authURL, state, err := service.Authorize(ctx, "test", tt.linkUID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.linkUID, state.LinkUID)
+			code := provider.authorize(t, authURL, tt.grant)
+
+			browserState := state.ID
+			if tt.browserState != nil {
+				browserState = tt.browserState(state.ID)
+			}
+			providerName := "test"
+			if tt.provider != "" {
+				providerName = tt.provider
+			}
+
+			result, err := service.Complete(ctx, providerName, code, state.ID, browserState, tt.callerUID)
+			if tt.twice {
+				require.NoError(t, err)
+				result, err = service.Complete(ctx, providerName, code, state.ID, browserState, tt.callerUID)
+			}
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+				identities, err := service.ListIdentities(ctx, "user-2")
+				require.NoError(t, err)
+				assert.Empty(t, identities)
+				return
+			case tt.wantAnyErr:
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.grant.subject, result.Profile.Subject)
+			assert.Equal(t, tt.grant.email, result.Profile.Email)
+			assert.Equal(t, tt.wantLinked, result.Linked)
+			assert.Equal(t, tt.wantLinkOnly, result.LinkOnly)
+			if tt.wantUID == "" {
+				assert.Nil(t, result.User)
+				return
+			}
+			require.NotNil(t, result.User)
+			assert.Equal(t, tt.wantUID, result.User.UID)
+
+			identities, err := service.ListIdentities(ctx, tt.wantUID)
+			require.NoError(t, err)
+			require.Len(t, identities, 1)
+			assert.Equal(t, IdentityID("test", tt.grant.subject), identities[0].ID)
+		})
+	}
+}
+
+func TestOAuthServiceResolveLinkedIdentity(t *testing.T) {
+	ctx := context.Background()
+	service, user := newTestOAuthService(t, newFakeOIDCProvider(t))
+
+	// Once linked, the identity signs its user in whatever its email address
+	_, err := service.Link(ctx, user.UID, "test", &ExternalProfile{Subject: "sub-ada", Email: "ada@example.com"})
+	require.NoError(t, err)
+	result, err := service.Resolve(ctx, "test", &ExternalProfile{Subject: "sub-ada", Email: "changed@example.com"})
+	require.NoError(t, err)
+	require.NotNil(t, result.User)
+	assert.Equal(t, user.UID, result.User.UID)
+	assert.False(t, result.Linked)
+
+	_, err = service.Link(ctx, "user-2", "test", &ExternalProfile{Subject: "sub-ada"})
+	assert.ErrorIs(t, err, ErrIdentityLinkedElsewhere)
+}
+
+func TestOAuthServiceAuthorizeUnknownProvider(t *testing.T) {
+	service, _ := newTestOAuthService(t, newFakeOIDCProvider(t))
+	_, _, err := service.Authorize(context.Background(), "missing", "")
+	assert.ErrorIs(t, err, ErrUnknownOAuthProvider)
+}
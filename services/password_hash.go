@@ -0,0 +1,103 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+)
+
+// argon2idParams are the parameters of new argon2id hashes, following the OWASP recommendation
+var argon2idParams = struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	saltLen int
+	keyLen  uint32
+}{memory: 64 * 1024, time: 3, threads: 2, saltLen: 16, keyLen: 32}
+
+// bcryptCost is the cost of new bcrypt hashes
+const bcryptCost = 12
+
+var errUnknownHash = errors.New("unknown password hash format")
+
+// hashPassword hashes a password with the algorithm, argon2id or bcrypt
+func hashPassword(password, algorithm string) (string, error) {
+	if algorithm == HashBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash password: %v", err)
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, argon2idParams.saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %v", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, argon2idParams.time, argon2idParams.memory, argon2idParams.threads, argon2idParams.keyLen)
+
+	// The PHC string format, as written by the reference implementation
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argon2idParams.memory, argon2idParams.time, argon2idParams.threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// checkPasswordHash reports whether the password matches an argon2id or bcrypt hash, and
+// whether the hash should be replaced because it uses another algorithm or weaker parameters
+func checkPasswordHash(hash, password, algorithm string) (match bool, rehash bool, err error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		var version int
+		var memory, iterations uint32
+		var threads uint8
+		parts := strings.Split(hash, "$")
+		if len(parts) != 6 {
+			return false, false, errUnknownHash
+		}
+		if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+			return false, false, errUnknownHash
+		}
+		if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+			return false, false, errUnknownHash
+		}
+		salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+		if err != nil {
+			return false, false, errUnknownHash
+		}
+		key, err := base64.RawStdEncoding.DecodeString(parts[5])
+		if err != nil || len(key) == 0 {
+			return false, false, errUnknownHash
+		}
+
+		computed := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(key, computed) != 1 {
+			return false, false, nil
+		}
+		weaker := memory < argon2idParams.memory || iterations < argon2idParams.time
+		return true, algorithm != HashArgon2id || weaker, nil
+
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, fmt.Errorf("failed to check password hash: %v", err)
+		}
+		cost, _ := bcrypt.Cost([]byte(hash))
+		return true, algorithm != HashBcrypt || cost < bcryptCost, nil
+	}
+
+	return false, false, errUnknownHash
+}
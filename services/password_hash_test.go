@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "correct horse battery staple"
+
+// weakArgon2idHash hashes the password with parameters below the current ones
+func weakArgon2idHash(password string) string {
+	salt := []byte("0123456789abcdef")
+	key := argon2.IDKey([]byte(password), salt, 1, 1024, 1, 32)
+	return fmt.Sprintf("$argon2id$v=%d$m=1024,t=1,p=1$%s$%s", argon2.Version,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func TestCheckPasswordHash(t *testing.T) {
+	argon2idHash, err := hashPassword(testPassword, HashArgon2id)
+	require.NoError(t, err)
+	bcryptHash, err := hashPassword(testPassword, HashBcrypt)
+	require.NoError(t, err)
+	weakBcrypt, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		hash       string
+		password   string
+		algorithm  string
+		wantMatch  bool
+		wantRehash bool
+		wantErr    bool
+	}{
+		{name: "argon2id", hash: argon2idHash, password: testPassword, algorithm: HashArgon2id, wantMatch: true},
+		{name: "argon2id, wrong password", hash: argon2idHash, password: "wrong", algorithm: HashArgon2id},
+		{name: "argon2id while hashing with bcrypt", hash: argon2idHash, password: testPassword, algorithm: HashBcrypt, wantMatch: true, wantRehash: true},
+		{name: "weaker argon2id", hash: weakArgon2idHash(testPassword), password: testPassword, algorithm: HashArgon2id, wantMatch: true, wantRehash: true},
+		{name: "bcrypt", hash: bcryptHash, password: testPassword, algorithm: HashBcrypt, wantMatch: true},
+		{name: "bcrypt, wrong password", hash: bcryptHash, password: "wrong", algorithm: HashBcrypt},
+		{name: "bcrypt while hashing with argon2id", hash: bcryptHash, password: testPassword, algorithm: HashArgon2id, wantMatch: true, wantRehash: true},
+		{name: "cheaper bcrypt", hash: string(weakBcrypt), password: testPassword, algorithm: HashBcrypt, wantMatch: true, wantRehash: true},
+		{name: "unknown format", hash: "plaintext", password: "plaintext", algorithm: HashArgon2id, wantErr: true},
+		{name: "truncated argon2id", hash: "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA", password: testPassword, algorithm: HashArgon2id, wantErr: true},
+		{name: "argon2id of another version", hash: "$argon2id$v=16$m=65536,t=3,p=2$c2FsdA$a2V5", password: testPassword, algorithm: HashArgon2id, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, rehash, err := checkPasswordHash(tt.hash, tt.password, tt.algorithm)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMatch, match)
+			assert.Equal(t, tt.wantRehash, rehash)
+		})
+	}
+}
+
+func TestHashPasswordSaltsEveryHash(t *testing.T) {
+	first, err := hashPassword(testPassword, HashArgon2id)
+	require.NoError(t, err)
+	second, err := hashPassword(testPassword, HashArgon2id)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.Regexp(t, `^\$argon2id\$v=19\$m=65536,t=3,p=2\$`, first)
+}
+
+// fakePasswordProvider is the provider the passwords are migrated from
+type fakePasswordProvider struct {
+	passwords map[string]string
+	checked   int
+}
+
+func (p *fakePasswordProvider) VerifyPassword(ctx context.Context, user *models.User, password string) error {
+	p.checked++
+	if stored, ok := p.passwords[user.UID]; !ok || stored != password {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+func (p *fakePasswordProvider) SetPassword(ctx context.Context, user *models.User, password string) error {
+	p.passwords[user.UID] = password
+	return nil
+}
+
+func (p *fakePasswordProvider) HasPassword(ctx context.Context, user *models.User) (bool, error) {
+	_, ok := p.passwords[user.UID]
+	return ok, nil
+}
+
+func TestLocalIdentityProviderMigratesPasswords(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{UID: "user-1", Email: "user@example.com"}
+	tests := []struct {
+		name        string
+		migrateFrom *fakePasswordProvider
+		password    string
+		wantErr     error
+		// wantMigrated is whether a local hash was stored
+		wantMigrated bool
+	}{
+		{
+			name:         "right password is migrated",
+			migrateFrom:  &fakePasswordProvider{passwords: map[string]string{"user-1": testPassword}},
+			password:     testPassword,
+			wantMigrated: true,
+		},
+		{
+			name:        "wrong password is not",
+			migrateFrom: &fakePasswordProvider{passwords: map[string]string{"user-1": testPassword}},
+			password:    "wrong",
+			wantErr:     ErrInvalidCredentials,
+		},
+		{
+			name:     "nothing to migrate from",
+			password: testPassword,
+			wantErr:  ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credentials := repository.NewMemoryCredentialRepository()
+			var migrateFrom PasswordProvider
+			if tt.migrateFrom != nil {
+				migrateFrom = tt.migrateFrom
+			}
+			provider := NewLocalIdentityProvider(credentials, HashArgon2id, migrateFrom)
+
+			err := provider.VerifyPassword(ctx, user, tt.password)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			_, err = credentials.Get(ctx, user.UID)
+			assert.Equal(t, tt.wantMigrated, err == nil)
+			if !tt.wantMigrated {
+				return
+			}
+
+			// The next sign-ins only check the local hash
+			require.NoError(t, provider.VerifyPassword(ctx, user, tt.password))
+			assert.Equal(t, 1, tt.migrateFrom.checked)
+			assert.ErrorIs(t, provider.VerifyPassword(ctx, user, "wrong"), ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestLocalIdentityProviderRehashesOutdatedHashes(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{UID: "user-1"}
+	credentials := repository.NewMemoryCredentialRepository()
+	require.NoError(t, credentials.Put(ctx, &models.Credential{UID: user.UID, PasswordHash: weakArgon2idHash(testPassword)}))
+
+	provider := NewLocalIdentityProvider(credentials, "", nil)
+	require.NoError(t, provider.VerifyPassword(ctx, user, testPassword))
+
+	credential, err := credentials.Get(ctx, user.UID)
+	require.NoError(t, err)
+	_, rehash, err := checkPasswordHash(credential.PasswordHash, testPassword, HashArgon2id)
+	require.NoError(t, err)
+	assert.False(t, rehash)
+}
+
+func TestLocalIdentityProviderHasPassword(t *testing.T) {
+	ctx := context.Background()
+	migrateFrom := &fakePasswordProvider{passwords: map[string]string{"old": testPassword}}
+	provider := NewLocalIdentityProvider(repository.NewMemoryCredentialRepository(), HashArgon2id, migrateFrom)
+
+	uid, err := provider.CreateAccount(ctx, models.ProviderData{ProviderType: models.EmailPassword, Email: "new@example.com", Password: testPassword})
+	require.NoError(t, err)
+
+	tests := []struct {
+		uid  string
+		want bool
+	}{
+		{uid, true},
+		{"old", true},
+		{"oauth-only", false},
+	}
+	for _, tt := range tests {
+		has, err := provider.HasPassword(ctx, &models.User{UID: tt.uid})
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, has, tt.uid)
+	}
+}
+
+func TestLocalIdentityProviderCreateAccount(t *testing.T) {
+	tests := []struct {
+		provider models.AuthProvider
+		wantErr  error
+	}{
+		{provider: models.EmailPassword},
+		// Nothing proves the user owns the Google or GitHub account
+		{provider: models.Google, wantErr: ErrExternalSignUpUnsupported},
+		{provider: models.GitHub, wantErr: ErrExternalSignUpUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.provider), func(t *testing.T) {
+			ctx := context.Background()
+			credentials := repository.NewMemoryCredentialRepository()
+			provider := NewLocalIdentityProvider(credentials, HashArgon2id, nil)
+
+			uid, err := provider.CreateAccount(ctx, models.ProviderData{ProviderType: tt.provider, Email: "new@example.com", Password: testPassword})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, provider.VerifyPassword(ctx, &models.User{UID: uid}, testPassword))
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+// PurposePasswordReset is the purpose of the tokens in password reset links
+const PurposePasswordReset = "password_reset"
+
+const (
+	// passwordResetTTL is how long a password reset link works
+	passwordResetTTL = time.Hour
+	// minPasswordLength is the shortest accepted password
+	minPasswordLength = 8
+)
+
+var (
+	// ErrInvalidResetToken is returned for unknown, expired and used reset tokens
+	ErrInvalidResetToken = errors.New("invalid or expired password reset link")
+	// ErrWeakPassword is returned for passwords that are too short
+	ErrWeakPassword = fmt.Errorf("password must be at least %d characters long", minPasswordLength)
+	// ErrSamePassword is returned when the new password is the current one
+	ErrSamePassword = errors.New("new password must be different from the current one")
+)
+
+// PasswordService resets and changes the passwords of the users. Once a password
+// changes, the other sessions of the user are signed out.
+type PasswordService struct {
+	users    repository.UserRepository
+	tokens   *actionTokens
+	sessions *SessionService
+	provider PasswordProvider
+}
+
+func NewPasswordService(users repository.UserRepository, actionTokenRepo repository.ActionTokenRepository, signer *TokenService, sessions *SessionService, provider PasswordProvider) *PasswordService {
+	return &PasswordService{
+		users: users,
+		tokens: &actionTokens{
+			repo:      actionTokenRepo,
+			signer:    signer,
+			purpose:   PurposePasswordReset,
+			ttl:       passwordResetTTL,
+			throttled: true,
+		},
+		sessions: sessions,
+		provider: provider,
+	}
+}
+
+// ResetTTL is how long a password reset link works
+func (s *PasswordService) ResetTTL() time.Duration {
+	return s.tokens.ttl
+}
+
+// validatePassword checks the password policy
+func validatePassword(password string) error {
+	if utf8.RuneCountInString(password) < minPasswordLength || strings.TrimSpace(password) == "" {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+// VerifyPassword checks the password of the user, returning ErrInvalidCredentials when it is wrong
+func (s *PasswordService) VerifyPassword(ctx context.Context, user *models.User, password string) error {
+	return s.provider.VerifyPassword(ctx, user, password)
+}
+
+// RequestReset issues the token of a password reset link for the user with the email.
+// It returns repository.ErrNotFound for unknown emails and a ThrottledError when
+// the user asked for links too often.
+func (s *PasswordService) RequestReset(ctx context.Context, email string) (*models.User, string, error) {
+	user, err := s.users.GetByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, "", err
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch user: %v", err)
+	}
+
+	token, err := s.tokens.issue(ctx, user)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, token, nil
+}
+
+// ResetPassword uses a reset token to set a new password and signs the user out of every device
+func (s *PasswordService) ResetPassword(ctx context.Context, token, newPassword string) (*models.User, error) {
+	if err := validatePassword(newPassword); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.tokens.verify(token)
+	if err != nil {
+		return nil, ErrInvalidResetToken
+	}
+
+	user, err := s.users.GetByUID(ctx, claims.UID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidResetToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %v", err)
+	}
+
+	// Links mailed to a previous address must not reset the account
+	if user.Email != claims.Email {
+		return nil, ErrInvalidResetToken
+	}
+
+	if err := s.tokens.use(ctx, claims); err != nil {
+		if errors.Is(err, errActionTokenInvalid) {
+			return nil, ErrInvalidResetToken
+		}
+		return nil, err
+	}
+
+	if err := s.setPassword(ctx, user, newPassword, ""); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ChangePassword replaces the password of a signed-in user after checking the current one.
+// The session the change was made from stays signed in.
+func (s *PasswordService) ChangePassword(ctx context.Context, uid, currentSessionID, currentPassword, newPassword string) (*models.User, error) {
+	if err := validatePassword(newPassword); err != nil {
+		return nil, err
+	}
+	if currentPassword == newPassword {
+		return nil, ErrSamePassword
+	}
+
+	user, err := s.users.GetByUID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.provider.VerifyPassword(ctx, user, currentPassword); err != nil {
+		return nil, err
+	}
+
+	if err := s.setPassword(ctx, user, newPassword, currentSessionID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// setPassword saves the password, expires the outstanding reset links and signs out
+// every session but keepSessionID
+func (s *PasswordService) setPassword(ctx context.Context, user *models.User, password, keepSessionID string) error {
+	if err := s.provider.SetPassword(ctx, user, password); err != nil {
+		return err
+	}
+
+	if err := s.tokens.expireAll(ctx, user.UID); err != nil {
+		return err
+	}
+
+	if _, err := s.sessions.RevokeSessionsExcept(ctx, user.UID, keepSessionID, "password changed"); err != nil {
+		return fmt.Errorf("failed to sign out other sessions: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type passwordServiceFixture struct {
+	service  *PasswordService
+	sessions *SessionService
+	users    repository.UserRepository
+	provider *LocalIdentityProvider
+	user     *models.User
+}
+
+// newTestPasswordService creates a user with the password testPassword
+func newTestPasswordService(t *testing.T) *passwordServiceFixture {
+	t.Helper()
+	ctx := context.Background()
+	users := repository.NewMemoryUserRepository()
+	user := &models.User{UID: "user-1", Email: "user@example.com"}
+	require.NoError(t, users.Create(ctx, user))
+
+	provider := NewLocalIdentityProvider(repository.NewMemoryCredentialRepository(), HashArgon2id, nil)
+	require.NoError(t, provider.SetPassword(ctx, user, testPassword))
+
+	sessions, _, tokens := newTestSessionService(t)
+	return &passwordServiceFixture{
+		service:  NewPasswordService(users, repository.NewMemoryActionTokenRepository(), tokens, sessions, provider),
+		sessions: sessions,
+		users:    users,
+		provider: provider,
+		user:     user,
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		password string
+		wantErr  bool
+	}{
+		{"12345678", false},
+		{"éééééééé", false},
+		{"1234567", true},
+		{"ééééééé", true},
+		{"        ", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		if tt.wantErr {
+			assert.ErrorIs(t, validatePassword(tt.password), ErrWeakPassword, tt.password)
+		} else {
+			assert.NoError(t, validatePassword(tt.password), tt.password)
+		}
+	}
+}
+
+func TestPasswordServiceResetPassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		newPassword string
+		// changeEmail changes the email of the user after the link was sent
+		changeEmail bool
+		// garbage replaces the issued token
+		garbage bool
+		wantErr error
+	}{
+		{name: "issued token", newPassword: "a brand new password"},
+		{name: "weak password", newPassword: "short", wantErr: ErrWeakPassword},
+		{name: "link mailed to a previous address", newPassword: "a brand new password", changeEmail: true, wantErr: ErrInvalidResetToken},
+		{name: "garbage", newPassword: "a brand new password", garbage: true, wantErr: ErrInvalidResetToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newTestPasswordService(t)
+			session, err := f.sessions.CreateSession(ctx, f.user, "laptop", "127.0.0.1")
+			require.NoError(t, err)
+
+			user, token, err := f.service.RequestReset(ctx, " user@example.com ")
+			require.NoError(t, err)
+			assert.Equal(t, f.user.UID, user.UID)
+			if tt.changeEmail {
+				require.NoError(t, f.users.Update(ctx, f.user.UID, map[string]interface{}{"email": "new@example.com"}))
+			}
+			if tt.garbage {
+				token = "not-a-token"
+			}
+
+			_, err = f.service.ResetPassword(ctx, token, tt.newPassword)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.NoError(t, f.provider.VerifyPassword(ctx, f.user, testPassword))
+				return
+			}
+			require.NoError(t, err)
+
+			assert.NoError(t, f.provider.VerifyPassword(ctx, f.user, tt.newPassword))
+			assert.ErrorIs(t, f.provider.VerifyPassword(ctx, f.user, testPassword), ErrInvalidCredentials)
+
+			// Every device is signed out, and the link works once
+			_, err = f.sessions.Refresh(ctx, session.RefreshToken, "laptop", "127.0.0.1")
+			assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+			_, err = f.service.ResetPassword(ctx, token, "yet another password")
+			assert.ErrorIs(t, err, ErrInvalidResetToken)
+		})
+	}
+}
+
+func TestPasswordServiceRequestResetUnknownEmail(t *testing.T) {
+	f := newTestPasswordService(t)
+	_, _, err := f.service.RequestReset(context.Background(), "nobody@example.com")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestPasswordServiceChangePassword(t *testing.T) {
+	tests := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		wantErr         error
+	}{
+		{name: "right current password", currentPassword: testPassword, newPassword: "a brand new password"},
+		{name: "wrong current password", currentPassword: "wrong password", newPassword: "a brand new password", wantErr: ErrInvalidCredentials},
+		{name: "same password", currentPassword: testPassword, newPassword: testPassword, wantErr: ErrSamePassword},
+		{name: "weak password", currentPassword: testPassword, newPassword: "short", wantErr: ErrWeakPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newTestPasswordService(t)
+			current, err := f.sessions.CreateSession(ctx, f.user, "laptop", "127.0.0.1")
+			require.NoError(t, err)
+			other, err := f.sessions.CreateSession(ctx, f.user, "phone", "127.0.0.1")
+			require.NoError(t, err)
+			_, resetToken, err := f.service.RequestReset(ctx, f.user.Email)
+			require.NoError(t, err)
+
+			_, err = f.service.ChangePassword(ctx, f.user.UID, current.SessionID, tt.currentPassword, tt.newPassword)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				_, err = f.sessions.Refresh(ctx, other.RefreshToken, "phone", "127.0.0.1")
+				assert.NoError(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, f.provider.VerifyPassword(ctx, f.user, tt.newPassword))
+
+			// The device the password was changed from stays signed in
+			_, err = f.sessions.Refresh(ctx, current.RefreshToken, "laptop", "127.0.0.1")
+			assert.NoError(t, err)
+			_, err = f.sessions.Refresh(ctx, other.RefreshToken, "phone", "127.0.0.1")
+			assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+			// Reset links sent before the change stop working
+			_, err = f.service.ResetPassword(ctx, resetToken, "yet another password")
+			assert.ErrorIs(t, err, ErrInvalidResetToken)
+		})
+	}
+}
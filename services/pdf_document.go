@@ -16,23 +16,21 @@ import (
 )
 
 type PDFService struct {
-	firestoreClient FirestoreClient
-	context         string
-	mutex           sync.RWMutex
-	lastUpdated     time.Time
-	pdfURL          string
-	stopRefresh     chan struct{}
+	context     string
+	mutex       sync.RWMutex
+	lastUpdated time.Time
+	pdfURL      string
+	stopRefresh chan struct{}
 }
 
-func NewPDFService(firestoreClient FirestoreClient, pdfURL string) *PDFService {
+func NewPDFService(pdfURL string) *PDFService {
 	if pdfURL == "" {
 		log.Fatal("PDF URL cannot be empty")
 	}
 
 	service := &PDFService{
-		firestoreClient: firestoreClient,
-		pdfURL:          pdfURL,
-		stopRefresh:     make(chan struct{}),
+		pdfURL:      pdfURL,
+		stopRefresh: make(chan struct{}),
 	}
 
 	// Initial load of the context
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+const (
+	// PersonalAccessTokenPrefix starts every personal access token, which tells them apart from session tokens
+	PersonalAccessTokenPrefix = "lcp_"
+	// DefaultPersonalAccessTokenTTL is the lifetime of a token created without one
+	DefaultPersonalAccessTokenTTL = 30 * 24 * time.Hour
+	// MaxPersonalAccessTokenTTL is the longest lifetime of a token
+	MaxPersonalAccessTokenTTL = 365 * 24 * time.Hour
+	// maxPersonalAccessTokens bounds the active tokens of a user
+	maxPersonalAccessTokens = 50
+	// tokenUsageInterval is how often the last use of a token is saved
+	tokenUsageInterval = time.Minute
+)
+
+var (
+	ErrInvalidPersonalAccessToken = errors.New("invalid, expired or revoked personal access token")
+	ErrUnknownScope               = errors.New("unknown scope")
+	ErrTokenLifetime              = errors.New("personal access tokens must expire within a year")
+	ErrTooManyTokens              = errors.New("too many personal access tokens, revoke unused ones first")
+	ErrTokenNameRequired          = errors.New("token name is required")
+)
+
+// scopeResources maps the first segment of the API routes to the resource their scopes
+// grant. Routes of other segments, like auth and admin, only accept session tokens.
+var scopeResources = map[string]string{
+	"users":              "profile",
+	"addresses":          "profile",
+	"school-experiences": "profile",
+	"connections":        "connections",
+	"presence":           "connections",
+	"jobs":               "jobs",
+	"linkedin":           "jobs",
+	"projects":           "projects",
+	"messages":           "messages",
+	"group-chats":        "messages",
+	"notifications":      "notifications",
+	"group-forums":       "forums",
+	"forums":             "forums",
+	"testimonials":       "testimonials",
+}
+
+// Scopes returns the scopes a personal access token can be granted. The write scope
+// of a resource includes its read scope.
+func Scopes() []string {
+	resources := map[string]bool{}
+	for _, resource := range scopeResources {
+		resources[resource] = true
+	}
+
+	scopes := make([]string, 0, 2*len(resources))
+	for resource := range resources {
+		scopes = append(scopes, "read:"+resource, "write:"+resource)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// RouteScope returns the scope a personal access token needs to call a route.
+// ok is false for the routes that personal access tokens cannot call.
+func RouteScope(method, path string) (scope string, ok bool) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/v1"), "/"), "/")
+	resource, ok := scopeResources[segments[0]]
+	if !ok {
+		return "", false
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return "read:" + resource, true
+	default:
+		return "write:" + resource, true
+	}
+}
+
+// ScopesAllow reports whether the granted scopes include the scope
+func ScopesAllow(granted []string, scope string) bool {
+	write := "write:" + strings.TrimPrefix(scope, "read:")
+	for _, g := range granted {
+		if g == scope || g == write {
+			return true
+		}
+	}
+	return false
+}
+
+// PersonalAccessTokenService creates and checks the personal access tokens of the users
+type PersonalAccessTokenService struct {
+	tokens repository.PersonalAccessTokenRepository
+	users  repository.UserRepository
+}
+
+func NewPersonalAccessTokenService(tokens repository.PersonalAccessTokenRepository, users repository.UserRepository) *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{tokens: tokens, users: users}
+}
+
+// Create issues a token for the user. The token is returned once; only its hash is kept.
+func (s *PersonalAccessTokenService) Create(ctx context.Context, uid, name string, scopes []string, ttl time.Duration) (*models.PersonalAccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrTokenNameRequired
+	}
+	if ttl == 0 {
+		ttl = DefaultPersonalAccessTokenTTL
+	}
+	if ttl < 0 || ttl > MaxPersonalAccessTokenTTL {
+		return nil, "", ErrTokenLifetime
+	}
+
+	granted, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	active, err := s.List(ctx, uid)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(active) >= maxPersonalAccessTokens {
+		return nil, "", ErrTooManyTokens
+	}
+
+	record := models.PersonalAccessToken{
+		ID:        uuid.New().String(),
+		UID:       uid,
+		Name:      name,
+		Scopes:    granted,
+		CreatedAt: time.Now(),
+	}
+	record.ExpiresAt = record.CreatedAt.Add(ttl)
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate token: %v", err)
+	}
+	token := PersonalAccessTokenPrefix + record.ID + "." + base64.RawURLEncoding.EncodeToString(secret)
+	record.TokenHash = hashRefreshToken(token)
+	record.Hint = token[len(token)-4:]
+
+	if err := s.tokens.Create(ctx, &record); err != nil {
+		return nil, "", fmt.Errorf("failed to save token: %v", err)
+	}
+	return &record, token, nil
+}
+
+// normalizeScopes checks the scopes and removes the duplicates
+func normalizeScopes(scopes []string) ([]string, error) {
+	known := map[string]bool{}
+	for _, scope := range Scopes() {
+		known[scope] = true
+	}
+
+	granted := []string{}
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !known[scope] {
+			return nil, fmt.Errorf("%w %q", ErrUnknownScope, scope)
+		}
+		if !containsString(granted, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	if len(granted) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrUnknownScope)
+	}
+	sort.Strings(granted)
+	return granted, nil
+}
+
+// List lists the tokens of the user that were not revoked, newest first. Expired tokens are kept
+// so that users see why a script stopped working.
+func (s *PersonalAccessTokenService) List(ctx context.Context, uid string) ([]models.PersonalAccessToken, error) {
+	tokens, err := s.tokens.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tokens: %v", err)
+	}
+
+	active := []models.PersonalAccessToken{}
+	for _, token := range tokens {
+		if token.RevokedAt == nil {
+			active = append(active, token)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].CreatedAt.After(active[j].CreatedAt)
+	})
+	return active, nil
+}
+
+// Revoke stops a token of the user from working
+func (s *PersonalAccessTokenService) Revoke(ctx context.Context, uid, tokenID string) error {
+	_, err := s.tokens.Update(ctx, tokenID, func(token *models.PersonalAccessToken) error {
+		if token.UID != uid || token.RevokedAt != nil {
+			return repository.ErrNotFound
+		}
+		now := time.Now()
+		token.RevokedAt = &now
+		return nil
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %v", err)
+	}
+	return nil
+}
+
+// Verify checks a token and returns its record and user. The last use of the token is
+// recorded at most once a minute.
+func (s *PersonalAccessTokenService) Verify(ctx context.Context, token, ipAddress string) (*models.PersonalAccessToken, *models.User, error) {
+	id, _, ok := strings.Cut(strings.TrimPrefix(token, PersonalAccessTokenPrefix), ".")
+	if !strings.HasPrefix(token, PersonalAccessTokenPrefix) || !ok || id == "" {
+		return nil, nil, ErrInvalidPersonalAccessToken
+	}
+
+	record, err := s.tokens.Get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, ErrInvalidPersonalAccessToken
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch token: %v", err)
+	}
+
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(record.TokenHash), []byte(hashRefreshToken(token))) != 1 ||
+		record.RevokedAt != nil || !now.Before(record.ExpiresAt) {
+		return nil, nil, ErrInvalidPersonalAccessToken
+	}
+
+	user, err := s.users.GetByUID(ctx, record.UID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, ErrInvalidPersonalAccessToken
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch user: %v", err)
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= tokenUsageInterval || record.LastUsedIP != ipAddress {
+		if _, err := s.tokens.Update(ctx, record.ID, func(token *models.PersonalAccessToken) error {
+			token.LastUsedAt = &now
+			token.LastUsedIP = ipAddress
+			return nil
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to record token use: %v", err)
+		}
+	}
+
+	return record, user, nil
+}
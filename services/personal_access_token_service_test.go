@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteScope(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{http.MethodGet, "/api/v1/users/user-1", "read:profile", true},
+		{http.MethodHead, "/api/v1/projects", "read:projects", true},
+		{http.MethodOptions, "/api/v1/projects", "read:projects", true},
+		{http.MethodPost, "/api/v1/projects", "write:projects", true},
+		{http.MethodPut, "/api/v1/addresses/a1", "write:profile", true},
+		{http.MethodDelete, "/api/v1/group-chats/g1/messages", "write:messages", true},
+		{http.MethodPatch, "/api/v1/notifications/n1/read", "write:notifications", true},
+		{http.MethodGet, "/api/v1/linkedin/jobs", "read:jobs", true},
+		{http.MethodGet, "/api/v1/presence/connections", "read:connections", true},
+		{http.MethodGet, "/api/v1/group-chats/", "read:messages", true},
+		{http.MethodGet, "/api/v1/auth/sessions", "", false},
+		{http.MethodPost, "/api/v1/auth/tokens", "", false},
+		{http.MethodGet, "/api/v1/admin/audit", "", false},
+		{http.MethodGet, "/api/v1", "", false},
+		{http.MethodGet, "/ws", "", false},
+	}
+
+	for _, tt := range tests {
+		scope, ok := RouteScope(tt.method, tt.path)
+		assert.Equal(t, tt.wantOK, ok, "%s %s", tt.method, tt.path)
+		assert.Equal(t, tt.want, scope, "%s %s", tt.method, tt.path)
+	}
+}
+
+func TestScopesAllow(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		scope   string
+		want    bool
+	}{
+		{"read granted", []string{"read:projects"}, "read:projects", true},
+		{"write includes read", []string{"write:projects"}, "read:projects", true},
+		{"write granted", []string{"write:projects"}, "write:projects", true},
+		{"read does not include write", []string{"read:projects"}, "write:projects", false},
+		{"other resource", []string{"write:jobs"}, "read:projects", false},
+		{"among others", []string{"read:jobs", "write:messages"}, "write:messages", true},
+		{"nothing granted", nil, "read:profile", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ScopesAllow(tt.granted, tt.scope), tt.name)
+	}
+}
+
+func TestScopesCoverEveryResource(t *testing.T) {
+	scopes := Scopes()
+	for _, resource := range scopeResources {
+		assert.Contains(t, scopes, "read:"+resource)
+		assert.Contains(t, scopes, "write:"+resource)
+	}
+	assert.IsIncreasing(t, scopes)
+}
+
+func newTestPersonalAccessTokenService(t *testing.T) (*PersonalAccessTokenService, *models.User) {
+	t.Helper()
+	users := repository.NewMemoryUserRepository()
+	user := &models.User{UID: "user-1", Email: "user@example.com"}
+	require.NoError(t, users.Create(context.Background(), user))
+	return NewPersonalAccessTokenService(repository.NewMemoryPersonalAccessTokenRepository(), users), user
+}
+
+func TestPersonalAccessTokenServiceCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		tokenName  string
+		scopes     []string
+		ttl        time.Duration
+		wantScopes []string
+		wantErr    error
+	}{
+		{name: "scopes are normalized", tokenName: "ci", scopes: []string{" Write:Projects", "read:jobs", "write:projects"}, wantScopes: []string{"read:jobs", "write:projects"}},
+		{name: "unknown scope", tokenName: "ci", scopes: []string{"read:everything"}, wantErr: ErrUnknownScope},
+		{name: "no scope", tokenName: "ci", wantErr: ErrUnknownScope},
+		{name: "no name", tokenName: " ", scopes: []string{"read:jobs"}, wantErr: ErrTokenNameRequired},
+		{name: "too long", tokenName: "ci", scopes: []string{"read:jobs"}, ttl: MaxPersonalAccessTokenTTL + time.Hour, wantErr: ErrTokenLifetime},
+		{name: "negative lifetime", tokenName: "ci", scopes: []string{"read:jobs"}, ttl: -time.Hour, wantErr: ErrTokenLifetime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, user := newTestPersonalAccessTokenService(t)
+			record, token, err := service.Create(context.Background(), user.UID, tt.tokenName, tt.scopes, tt.ttl)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantScopes, record.Scopes)
+			assert.True(t, len(token) > len(PersonalAccessTokenPrefix))
+			assert.Equal(t, PersonalAccessTokenPrefix, token[:len(PersonalAccessTokenPrefix)])
+			assert.Equal(t, token[len(token)-4:], record.Hint)
+			assert.NotContains(t, record.TokenHash, token)
+			assert.WithinDuration(t, time.Now().Add(DefaultPersonalAccessTokenTTL), record.ExpiresAt, time.Minute)
+		})
+	}
+}
+
+func TestPersonalAccessTokenServiceVerify(t *testing.T) {
+	ctx := context.Background()
+	service, user := newTestPersonalAccessTokenService(t)
+
+	record, token, err := service.Create(ctx, user.UID, "ci", []string{"read:projects"}, time.Hour)
+	require.NoError(t, err)
+	revoked, revokedToken, err := service.Create(ctx, user.UID, "old", []string{"read:projects"}, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, service.Revoke(ctx, user.UID, revoked.ID))
+	assert.ErrorIs(t, service.Revoke(ctx, user.UID, revoked.ID), repository.ErrNotFound)
+	assert.ErrorIs(t, service.Revoke(ctx, "someone-else", record.ID), repository.ErrNotFound)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"valid", token, nil},
+		{"revoked", revokedToken, ErrInvalidPersonalAccessToken},
+		{"wrong secret", token[:len(token)-4] + "AAAA", ErrInvalidPersonalAccessToken},
+		{"unknown token", PersonalAccessTokenPrefix + "missing.secret", ErrInvalidPersonalAccessToken},
+		{"without prefix", token[len(PersonalAccessTokenPrefix):], ErrInvalidPersonalAccessToken},
+		{"malformed", PersonalAccessTokenPrefix, ErrInvalidPersonalAccessToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified, owner, err := service.Verify(ctx, tt.token, "127.0.0.1")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, record.ID, verified.ID)
+			assert.Equal(t, user.UID, owner.UID)
+		})
+	}
+
+	active, err := service.List(ctx, user.UID)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.NotNil(t, active[0].LastUsedAt)
+	assert.Equal(t, "127.0.0.1", active[0].LastUsedIP)
+}
@@ -6,23 +6,20 @@ import (
 	"fmt"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/google/uuid"
-	"github.com/rogerjeasy/go-letusconnect/mappers"
 	"github.com/rogerjeasy/go-letusconnect/models"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 type ForumService struct {
-	firestoreClient FirestoreClient
-	userService     *UserService
+	forums      repository.ForumRepository
+	userService *UserService
 }
 
-func NewForumService(fClient FirestoreClient, uService *UserService) *ForumService {
+func NewForumService(forums repository.ForumRepository, uService *UserService) *ForumService {
 	return &ForumService{
-		firestoreClient: fClient,
-		userService:     uService,
+		forums:      forums,
+		userService: uService,
 	}
 }
 
@@ -50,10 +47,7 @@ func (s *ForumService) CreateForum(ctx context.Context, input models.Forum, user
 	input.UpdatedAt = now
 	input.Moderators = []*models.User{user}
 
-	forumData := mappers.MapForumGoToFirestore(input)
-
-	_, err = s.firestoreClient.Collection("forums").Doc(input.ID).Set(ctx, forumData)
-	if err != nil {
+	if err := s.forums.Save(ctx, &input); err != nil {
 		return nil, fmt.Errorf("failed to create forum: %v", err)
 	}
 
@@ -62,22 +56,20 @@ func (s *ForumService) CreateForum(ctx context.Context, input models.Forum, user
 
 // GetForum retrieves a forum by ID
 func (s *ForumService) GetForum(ctx context.Context, forumID string) (*models.Forum, error) {
-	doc, err := s.firestoreClient.Collection("forums").Doc(forumID).Get(ctx)
+	forum, err := s.forums.Get(ctx, forumID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get forum: %v", err)
 	}
 
-	forum := mappers.MapForumFirestoreToGo(doc.Data())
-	return &forum, nil
+	return forum, nil
 }
 
 // UpdateForum updates an existing forum
 func (s *ForumService) UpdateForum(ctx context.Context, forumID string, updates models.Forum) error {
+	updates.ID = forumID
 	updates.UpdatedAt = time.Now()
-	updateData := mappers.MapForumGoToFirestore(updates)
 
-	_, err := s.firestoreClient.Collection("forums").Doc(forumID).Set(ctx, updateData, firestore.MergeAll)
-	if err != nil {
+	if err := s.forums.Save(ctx, &updates); err != nil {
 		return fmt.Errorf("failed to update forum: %v", err)
 	}
 
@@ -103,8 +95,7 @@ func (s *ForumService) DeleteForum(ctx context.Context, forumID string, userID s
 		return errors.New("unauthorized: only forum moderators can delete the forum")
 	}
 
-	_, err = s.firestoreClient.Collection("forums").Doc(forumID).Delete(ctx)
-	if err != nil {
+	if err := s.forums.Delete(ctx, forumID); err != nil {
 		return fmt.Errorf("failed to delete forum: %v", err)
 	}
 
@@ -215,19 +206,11 @@ func (s *ForumService) AddReaction(ctx context.Context, forumID string, reaction
 
 // ListForumsByGroup retrieves all forums for a specific group
 func (s *ForumService) ListForumsByGroup(ctx context.Context, groupID string) ([]models.Forum, error) {
-	query := s.firestoreClient.Collection("forums").Where("group_id", "==", groupID)
-
-	docs, err := query.Documents(ctx).GetAll()
-	if err != nil && status.Code(err) != codes.NotFound {
+	forums, err := s.forums.ListByGroup(ctx, groupID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get forums: %v", err)
 	}
 
-	var forums []models.Forum
-	for _, doc := range docs {
-		forum := mappers.MapForumFirestoreToGo(doc.Data())
-		forums = append(forums, forum)
-	}
-
 	return forums, nil
 }
 
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+// Presence states. A user is in the most present state of their devices, and offline
+// without any.
+const (
+	PresenceOnline  = "online"
+	PresenceIdle    = "idle"
+	PresenceAway    = "away"
+	PresenceOffline = "offline"
+)
+
+const (
+	// presenceHeartbeatTTL is how long a device stays present without a heartbeat. The
+	// realtime connections send one at least every minute.
+	presenceHeartbeatTTL = 90 * time.Second
+	// presenceSweepPeriod is how often the devices that stopped sending heartbeats, like
+	// those of a server that crashed, are forgotten
+	presenceSweepPeriod = 30 * time.Second
+	// MaxPresenceQuery bounds the users of a bulk presence query
+	MaxPresenceQuery = 200
+)
+
+// ErrInvalidPresenceState is returned for a heartbeat in an unknown state
+var ErrInvalidPresenceState = errors.New("presence state must be online, idle or away")
+
+// presenceRanks orders the states of the devices, the most present first
+var presenceRanks = map[string]int{
+	PresenceOnline: 3,
+	PresenceIdle:   2,
+	PresenceAway:   1,
+}
+
+// PresenceService tracks which users are online from the heartbeats of their realtime
+// connections, and publishes the changes on their presence channel
+type PresenceService struct {
+	presences  repository.PresenceRepository
+	users      repository.UserRepository
+	groupChats repository.GroupChatRepository
+}
+
+func NewPresenceService(presences repository.PresenceRepository, users repository.UserRepository, groupChats repository.GroupChatRepository) *PresenceService {
+	return &PresenceService{
+		presences:  presences,
+		users:      users,
+		groupChats: groupChats,
+	}
+}
+
+// Connect records a new realtime connection of the user, who is online on it
+func (s *PresenceService) Connect(ctx context.Context, uid string, device models.PresenceDevice) error {
+	now := time.Now()
+	device.State = PresenceOnline
+	device.HeartbeatAt = now
+	if device.ConnectedAt.IsZero() {
+		device.ConnectedAt = now
+	}
+
+	_, err := s.update(ctx, uid, func(presence *models.Presence) {
+		presence.Devices[device.ConnectionID] = device
+	})
+	return err
+}
+
+// ListSessionDevices returns the open realtime connections of the user by session ID
+func (s *PresenceService) ListSessionDevices(ctx context.Context, uid string) (map[string][]models.PresenceDevice, error) {
+	presence, err := s.GetOwnPresence(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := map[string][]models.PresenceDevice{}
+	for _, device := range presence.Devices {
+		devices[device.SessionID] = append(devices[device.SessionID], device)
+	}
+	for _, sessionDevices := range devices {
+		sort.Slice(sessionDevices, func(i, j int) bool {
+			return sessionDevices[i].ConnectedAt.Before(sessionDevices[j].ConnectedAt)
+		})
+	}
+	return devices, nil
+}
+
+// Heartbeat keeps a connection present in the state reported by its client: online while
+// the user is active, idle after a while without input and away when the app is in the
+// background. Without a state, the connection keeps its last one.
+func (s *PresenceService) Heartbeat(ctx context.Context, uid, connectionID, state string) error {
+	if _, ok := presenceRanks[state]; state != "" && !ok {
+		return ErrInvalidPresenceState
+	}
+
+	now := time.Now()
+	_, err := s.update(ctx, uid, func(presence *models.Presence) {
+		device, ok := presence.Devices[connectionID]
+		if !ok {
+			// The connection was forgotten while its heartbeats were late
+			device = models.PresenceDevice{ConnectionID: connectionID, State: PresenceOnline, ConnectedAt: now}
+		}
+		if state != "" {
+			device.State = state
+		}
+		device.HeartbeatAt = now
+		presence.Devices[connectionID] = device
+	})
+	return err
+}
+
+// Disconnect forgets a closed realtime connection of the user
+func (s *PresenceService) Disconnect(ctx context.Context, uid, connectionID string) error {
+	now := time.Now()
+	_, err := s.update(ctx, uid, func(presence *models.Presence) {
+		delete(presence.Devices, connectionID)
+		presence.LastSeenAt = now
+	})
+	return err
+}
+
+// SetHidden opts the user out of presence, or back in. Hidden users appear offline.
+func (s *PresenceService) SetHidden(ctx context.Context, uid string, hidden bool) (*models.Presence, error) {
+	return s.update(ctx, uid, func(presence *models.Presence) {
+		presence.Hidden = hidden
+	})
+}
+
+// GetOwnPresence returns the presence of the user with their devices and settings
+func (s *PresenceService) GetOwnPresence(ctx context.Context, uid string) (*models.Presence, error) {
+	presence, err := s.presences.Get(ctx, uid)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &models.Presence{UID: uid, Status: PresenceOffline, Devices: map[string]models.PresenceDevice{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch presence: %v", err)
+	}
+	return presence, nil
+}
+
+// GetPresences returns, in order, the presence of each user as the other users see it.
+// Users who never connected are offline.
+func (s *PresenceService) GetPresences(ctx context.Context, uids []string) ([]models.PresenceStatus, error) {
+	unique := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		if uid != "" && !containsString(unique, uid) {
+			unique = append(unique, uid)
+		}
+	}
+
+	presences, err := s.presences.GetMany(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch presences: %v", err)
+	}
+	byUID := make(map[string]*models.Presence, len(presences))
+	for i := range presences {
+		byUID[presences[i].UID] = &presences[i]
+	}
+
+	statuses := make([]models.PresenceStatus, 0, len(unique))
+	for _, uid := range unique {
+		statuses = append(statuses, presenceStatusOf(uid, byUID[uid]))
+	}
+	return statuses, nil
+}
+
+// GetGroupChatPresences returns the presence of the participants of a group chat the user
+// takes part in
+func (s *PresenceService) GetGroupChatPresences(ctx context.Context, uid, groupChatID string) ([]models.PresenceStatus, error) {
+	chat, err := s.groupChats.Get(ctx, groupChatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to fetch group chat: %v", err)
+	}
+
+	participants := make([]string, 0, len(chat.Participants))
+	for _, participant := range chat.Participants {
+		participants = append(participants, participant.UserID)
+	}
+	if !containsString(participants, uid) {
+		return nil, ErrForbidden
+	}
+	return s.GetPresences(ctx, participants)
+}
+
+// Start forgets the devices that stopped sending heartbeats until the context is done
+func (s *PresenceService) Start(ctx context.Context) {
+	go s.run(ctx)
+}
+
+func (s *PresenceService) run(ctx context.Context) {
+	ticker := time.NewTicker(presenceSweepPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ExpireDevices(ctx); err != nil {
+				log.Printf("Error expiring presence devices: %v", err)
+			}
+		}
+	}
+}
+
+// ExpireDevices forgets the devices whose last heartbeat is older than presenceHeartbeatTTL
+func (s *PresenceService) ExpireDevices(ctx context.Context) error {
+	presences, err := s.presences.ListConnected(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list connected users: %v", err)
+	}
+
+	var errs []error
+	for _, presence := range presences {
+		if len(expiredDevices(&presence)) == 0 {
+			continue
+		}
+		_, err := s.update(ctx, presence.UID, func(presence *models.Presence) {
+			for _, connectionID := range expiredDevices(presence) {
+				delete(presence.Devices, connectionID)
+			}
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// expiredDevices lists the connections of a presence without a recent heartbeat
+func expiredDevices(presence *models.Presence) []string {
+	var expired []string
+	for connectionID, device := range presence.Devices {
+		if time.Since(device.HeartbeatAt) > presenceHeartbeatTTL {
+			expired = append(expired, connectionID)
+		}
+	}
+	return expired
+}
+
+// update applies fn to the presence of the user, works out their status again and
+// publishes the change others can see
+func (s *PresenceService) update(ctx context.Context, uid string, fn func(*models.Presence)) (*models.Presence, error) {
+	var before models.PresenceStatus
+	presence, err := s.presences.Update(ctx, uid, func(presence *models.Presence) error {
+		before = presenceStatusOf(uid, presence)
+		if presence.Devices == nil {
+			presence.Devices = map[string]models.PresenceDevice{}
+		}
+
+		fn(presence)
+
+		presence.Status = PresenceOffline
+		for _, device := range presence.Devices {
+			if presenceRanks[device.State] > presenceRanks[presence.Status] {
+				presence.Status = device.State
+			}
+			if device.HeartbeatAt.After(presence.LastSeenAt) {
+				presence.LastSeenAt = device.HeartbeatAt
+			}
+		}
+		presence.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update presence: %v", err)
+	}
+
+	after := presenceStatusOf(uid, presence)
+	if after.Status != before.Status || (before.LastSeenAt == nil) != (after.LastSeenAt == nil) {
+		s.publish(ctx, after, before.Status)
+	}
+	return presence, nil
+}
+
+// publish announces a new presence status, and keeps the online flag of the user profile
+// in step for the clients reading it
+func (s *PresenceService) publish(ctx context.Context, status models.PresenceStatus, previous string) {
+	if err := Realtime.Publish(PresenceChannel(status.UID), EventPresenceChanged, status); err != nil {
+		log.Printf("Realtime publish failed: %v", err)
+	}
+
+	online := status.Status != PresenceOffline
+	if online == (previous != PresenceOffline) {
+		return
+	}
+	err := s.users.Update(ctx, status.UID, map[string]interface{}{"is_online": online})
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Printf("Error updating the online status of user %s: %v", status.UID, err)
+	}
+}
+
+// presenceStatusOf returns the presence of a user as the other users see it
+func presenceStatusOf(uid string, presence *models.Presence) models.PresenceStatus {
+	status := models.PresenceStatus{UID: uid, Status: PresenceOffline}
+	if presence == nil || presence.Hidden {
+		return status
+	}
+	if presence.Status != "" {
+		status.Status = presence.Status
+	}
+	if !presence.LastSeenAt.IsZero() {
+		lastSeen := presence.LastSeenAt
+		status.LastSeenAt = &lastSeen
+	}
+	return status
+}
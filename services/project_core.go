@@ -1,12 +1,20 @@
 package services
 
+import (
+	"context"
+	"log"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
 type ProjectCoreService struct {
-	firestoreClient FirestoreClient
+	projects repository.ProjectRepository
 }
 
-func NewProjectCoreService(client FirestoreClient) *ProjectCoreService {
+func NewProjectCoreService(projects repository.ProjectRepository) *ProjectCoreService {
 	return &ProjectCoreService{
-		firestoreClient: client,
+		projects: projects,
 	}
 }
 
@@ -14,3 +22,65 @@ func (s *ProjectCoreService) GetGroupMembers(projectID string, groupID *string)
 	// Logic to fetch group members from Firestore or another data source
 	return []string{}, nil
 }
+
+// CreateProject stores a new project and sets its generated ID
+func (s *ProjectCoreService) CreateProject(ctx context.Context, project *models.Project) error {
+	return s.projects.Create(ctx, project)
+}
+
+// GetProject retrieves a project by its ID
+func (s *ProjectCoreService) GetProject(ctx context.Context, projectID string) (*models.Project, error) {
+	return s.projects.Get(ctx, projectID)
+}
+
+// UpdateProject atomically applies fn to a stored project and publishes the result to its members
+func (s *ProjectCoreService) UpdateProject(ctx context.Context, projectID string, fn func(*models.Project) error) (*models.Project, error) {
+	project, err := s.projects.Update(ctx, projectID, fn)
+	if err != nil {
+		return nil, err
+	}
+	publishProjectUpdate(project)
+	return project, nil
+}
+
+// DeleteProject removes a project
+func (s *ProjectCoreService) DeleteProject(ctx context.Context, projectID string) error {
+	return s.projects.Delete(ctx, projectID)
+}
+
+// ListPublicProjects returns every project open to public collaboration
+func (s *ProjectCoreService) ListPublicProjects(ctx context.Context) ([]models.Project, error) {
+	return s.projects.ListByCollaborationType(ctx, "public", "Public")
+}
+
+// ListOwnerProjects returns the projects owned by the user
+func (s *ProjectCoreService) ListOwnerProjects(ctx context.Context, ownerID string) ([]models.Project, error) {
+	return s.projects.ListByOwner(ctx, ownerID)
+}
+
+// ListParticipationProjects returns the projects the user takes part in without owning them
+func (s *ProjectCoreService) ListParticipationProjects(ctx context.Context, userID string) ([]models.Project, error) {
+	projects, err := s.projects.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var participation []models.Project
+	for _, project := range projects {
+		for _, participant := range project.Participants {
+			if participant.UserID == userID && participant.Role != "owner" {
+				participation = append(participation, project)
+				break
+			}
+		}
+	}
+
+	return participation, nil
+}
+
+// publishProjectUpdate sends the changed project to the realtime channel of its members
+func publishProjectUpdate(project *models.Project) {
+	if err := Realtime.Publish(ProjectChannel(project.ID), EventProjectUpdated, project); err != nil {
+		log.Printf("Error publishing update of project %s: %v", project.ID, err)
+	}
+}
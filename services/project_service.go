@@ -2,31 +2,34 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
-	"cloud.google.com/go/firestore"
-	"github.com/rogerjeasy/go-letusconnect/mappers"
 	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+// Reasons a join request is turned down, matched by the project handler
+var (
+	errProjectCompleted       = errors.New("this project has been completed")
+	errOwnerCannotJoin        = errors.New("owners cannot join their own project")
+	errAlreadyAppliedToJoin   = errors.New("you have already applied to join this project")
+	errJoinPreviouslyRejected = errors.New("your request was previously rejected")
 )
 
 type ProjectService struct {
-	firestoreClient FirestoreClient
-	userService     *UserService
+	projects    repository.ProjectRepository
+	userService *UserService
 }
 
-func NewProjectService(client FirestoreClient, userService *UserService) *ProjectService {
+func NewProjectService(projects repository.ProjectRepository, userService *UserService) *ProjectService {
 	return &ProjectService{
-		firestoreClient: client,
-		userService:     userService,
+		projects:    projects,
+		userService: userService,
 	}
 }
 
-func GetGroupMembers(projectID string, groupID *string) ([]string, error) {
-	// Logic to fetch group members from Firestore or another data source
-	return []string{}, nil
-}
-
 func (s *ProjectService) JoinProject(ctx context.Context, projectID, userID, message string) error {
 	// Get user details
 	user, err := s.userService.GetUserByUID(userID)
@@ -45,46 +48,42 @@ func (s *ProjectService) JoinProject(ctx context.Context, projectID, userID, mes
 		Status:         "pending",
 	}
 
-	// Get project
-	doc, err := s.firestoreClient.Collection("projects").Doc(projectID).Get(ctx)
-	if err != nil {
-		return fmt.Errorf("project not found: %v", err)
-	}
-
-	projectData := doc.Data()
-
-	// Validate project status
-	if projectData["status"] == "completed" {
-		return fmt.Errorf("this project has been completed")
-	}
+	// Add join request once the project accepts it
+	_, err = s.projects.Update(ctx, projectID, func(project *models.Project) error {
+		// Validate project status
+		if project.Status == "completed" {
+			return errProjectCompleted
+		}
 
-	// Check if user is owner
-	if projectData["owner_id"] == userID {
-		return fmt.Errorf("owners cannot join their own project")
-	}
+		// Check if user is owner
+		if project.OwnerID == userID {
+			return errOwnerCannotJoin
+		}
 
-	// Check existing requests
-	joinRequests := mappers.GetJoinRequestsArray(projectData, "join_requests")
-	for _, jr := range joinRequests {
-		if jr.UserID == userID {
-			return fmt.Errorf("you have already applied to join this project")
+		// Check existing requests
+		for _, jr := range project.JoinRequests {
+			if jr.UserID == userID {
+				return errAlreadyAppliedToJoin
+			}
 		}
-	}
 
-	// Check rejected participants
-	if rejectedParticipants, ok := projectData["rejected_participants"].([]interface{}); ok {
-		for _, rejectedUID := range rejectedParticipants {
+		// Check rejected participants
+		for _, rejectedUID := range project.RejectedParticipants {
 			if rejectedUID == userID {
-				return fmt.Errorf("your request was previously rejected")
+				return errJoinPreviouslyRejected
 			}
 		}
-	}
 
-	// Add join request
-	joinRequestMap := mappers.MapJoinRequestGoToFirestore(joinRequest)
-	_, err = s.firestoreClient.Collection("projects").Doc(projectID).Update(ctx, []firestore.Update{
-		{Path: "join_requests", Value: firestore.ArrayUnion(joinRequestMap)},
+		project.JoinRequests = append(project.JoinRequests, joinRequest)
+		return nil
 	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return errors.New("project not found")
+	}
+	if errors.Is(err, errProjectCompleted) || errors.Is(err, errOwnerCannotJoin) ||
+		errors.Is(err, errAlreadyAppliedToJoin) || errors.Is(err, errJoinPreviouslyRejected) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("failed to apply to join project: %v", err)
 	}
@@ -92,22 +91,22 @@ func (s *ProjectService) JoinProject(ctx context.Context, projectID, userID, mes
 	return nil
 }
 
-// GetProjectByID retrieves a project from Firestore by its ID and maps it to a Go struct
+// GetProjectByID retrieves a project by its ID
 func (s *ProjectService) GetProjectByID(ctx context.Context, projectID string) (models.Project, error) {
-	// Get project document from Firestore
-	doc, err := s.firestoreClient.Collection("projects").Doc(projectID).Get(ctx)
+	project, err := s.projects.Get(ctx, projectID)
 	if err != nil {
 		return models.Project{}, fmt.Errorf("failed to fetch project: %v", err)
 	}
 
-	// Get the project data
-	projectData := doc.Data()
-	if projectData == nil {
-		return models.Project{}, fmt.Errorf("project data is nil")
-	}
-
-	// Map the Firestore data to a Go struct
-	project := mappers.MapProjectFirestoreToGo(projectData)
+	return *project, nil
+}
 
+// UpdateProject atomically applies fn to a stored project and publishes the result to its members
+func (s *ProjectService) UpdateProject(ctx context.Context, projectID string, fn func(*models.Project) error) (*models.Project, error) {
+	project, err := s.projects.Update(ctx, projectID, fn)
+	if err != nil {
+		return nil, err
+	}
+	publishProjectUpdate(project)
 	return project, nil
 }
@@ -2,16 +2,23 @@ package services
 
 import (
 	"log"
+	"strings"
 
 	"github.com/pusher/pusher-http-go/v5"
 	"github.com/rogerjeasy/go-letusconnect/config"
 )
 
-// PusherClient is the instance of the Pusher client
+// PusherClient is the instance of the Pusher client, nil when Pusher is not configured
 var PusherClient *pusher.Client
 
-// InitializePusher sets up the Pusher client with environment variables
-func InitializePusher() {
+// InitializePusher sets up the Pusher client with environment variables and reports
+// whether Pusher is configured
+func InitializePusher() bool {
+	if config.PusherAppID == "" || config.PusherKey == "" || config.PusherSecret == "" {
+		log.Println("Pusher is not configured, realtime events are only delivered over WebSocket")
+		return false
+	}
+
 	PusherClient = &pusher.Client{
 		AppID:   config.PusherAppID,
 		Key:     config.PusherKey,
@@ -21,4 +28,52 @@ func InitializePusher() {
 	}
 
 	log.Println("Pusher client initialized successfully")
+	return true
+}
+
+// pusherPrivatePrefix marks the Pusher channels that need a signed subscription
+const pusherPrivatePrefix = "private-"
+
+// PusherChannelName returns the private Pusher channel of a hub channel. Pusher treats every
+// other name as public, so the hub channels are all published under "private-". The direct
+// message channels already carry the prefix. The presence channels become
+// "private-presence-<uid>": "presence-" is reserved for Pusher presence channels, whose
+// subscriptions carry member data the hub does not use.
+func PusherChannelName(channel string) string {
+	if strings.HasPrefix(channel, directMessagesChannelPrefix) {
+		return channel
+	}
+	return pusherPrivatePrefix + channel
+}
+
+// RealtimeChannelFromPusher returns the hub channel of a Pusher channel, and false when the
+// Pusher channel is not one PusherChannelName produces
+func RealtimeChannelFromPusher(name string) (string, bool) {
+	if strings.HasPrefix(name, directMessagesChannelPrefix) {
+		return name, true
+	}
+	channel, ok := strings.CutPrefix(name, pusherPrivatePrefix)
+	if !ok || channel == "" {
+		return "", false
+	}
+	return channel, true
+}
+
+// PusherTransport delivers the realtime events to the private Pusher channels of the hub channels
+type PusherTransport struct {
+	client *pusher.Client
+}
+
+func NewPusherTransport(client *pusher.Client) *PusherTransport {
+	return &PusherTransport{client: client}
+}
+
+func (t *PusherTransport) Name() string {
+	return "pusher"
+}
+
+// Deliver triggers the event with its payload only, which Pusher clients receive as before
+// the envelope existed
+func (t *PusherTransport) Deliver(event RealtimeEvent) error {
+	return t.client.Trigger(PusherChannelName(event.Channel), event.Type, event.Data)
 }
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPusherChannelName(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel string
+		want    string
+	}{
+		{name: "user channel", channel: UserNotificationsChannel("ada"), want: "private-user-notifications-ada"},
+		{name: "group chat", channel: GroupChatChannel("chat-1"), want: "private-group-messages-chat-1"},
+		{name: "project", channel: ProjectChannel("p-1"), want: "private-project-p-1"},
+		{name: "presence is not a Pusher presence channel", channel: PresenceChannel("ada"), want: "private-presence-ada"},
+		{name: "direct messages keep their name", channel: DirectMessagesChannel("ada-bob"), want: "private-messages-ada-bob"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PusherChannelName(tt.channel)
+			assert.Equal(t, tt.want, got)
+
+			channel, ok := RealtimeChannelFromPusher(got)
+			assert.True(t, ok)
+			assert.Equal(t, tt.channel, channel)
+		})
+	}
+}
+
+func TestRealtimeChannelFromPusherRejectsPublicChannels(t *testing.T) {
+	for _, name := range []string{"user-notifications-ada", "presence-ada", "private-", ""} {
+		t.Run(name, func(t *testing.T) {
+			_, ok := RealtimeChannelFromPusher(name)
+			assert.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"sync"
+)
+
+// RealtimeBackplane carries the events published on any instance of the API to the hubs of
+// every instance, so that they reach the clients connected to each one
+type RealtimeBackplane interface {
+	Name() string
+	// Publish sends an event to the hubs of every instance, this one included
+	Publish(event RealtimeEvent) error
+	// Subscribe hands the events published by every instance to deliver, until Close
+	Subscribe(deliver func(RealtimeEvent) error) error
+	Close() error
+}
+
+// errBackplaneNotSubscribed is returned when publishing on a backplane nothing listens to
+var errBackplaneNotSubscribed = errors.New("nothing is subscribed to the backplane")
+
+// localBackplane delivers the events to the hub of this instance only. It is the backplane of
+// a single instance deployment.
+type localBackplane struct {
+	mu      sync.RWMutex
+	deliver func(RealtimeEvent) error
+}
+
+// NewLocalBackplane creates an in-process RealtimeBackplane. Events are delivered before
+// Publish returns.
+func NewLocalBackplane() RealtimeBackplane {
+	return &localBackplane{}
+}
+
+func (b *localBackplane) Name() string {
+	return "local"
+}
+
+func (b *localBackplane) Publish(event RealtimeEvent) error {
+	b.mu.RLock()
+	deliver := b.deliver
+	b.mu.RUnlock()
+
+	if deliver == nil {
+		return errBackplaneNotSubscribed
+	}
+	return deliver(event)
+}
+
+func (b *localBackplane) Subscribe(deliver func(RealtimeEvent) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deliver = deliver
+	return nil
+}
+
+func (b *localBackplane) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deliver = nil
+	return nil
+}
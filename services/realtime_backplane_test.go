@@ -0,0 +1,124 @@
+package services
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBackplaneDeliversBeforePublishReturns(t *testing.T) {
+	backplane := NewLocalBackplane()
+	event := newRealtimeEvent("group-messages-g1", EventNewGroupMessage, "hello")
+
+	assert.ErrorIs(t, backplane.Publish(event), errBackplaneNotSubscribed)
+
+	var received []RealtimeEvent
+	require.NoError(t, backplane.Subscribe(func(event RealtimeEvent) error {
+		received = append(received, event)
+		return nil
+	}))
+	require.NoError(t, backplane.Publish(event))
+	require.Len(t, received, 1)
+	assert.Equal(t, event.Channel, received[0].Channel)
+
+	require.NoError(t, backplane.Close())
+	assert.ErrorIs(t, backplane.Publish(event), errBackplaneNotSubscribed)
+}
+
+func TestRedisBackplaneCarriesEventsBetweenInstances(t *testing.T) {
+	server := miniredis.RunT(t)
+	url := "redis://" + server.Addr()
+
+	publisher, err := NewRedisBackplane(url, "realtime-test")
+	require.NoError(t, err)
+	defer publisher.Close()
+	subscriber, err := NewRedisBackplane(url, "realtime-test")
+	require.NoError(t, err)
+	defer subscriber.Close()
+
+	received := make(chan RealtimeEvent, 10)
+	require.NoError(t, subscriber.Subscribe(func(event RealtimeEvent) error {
+		received <- event
+		return nil
+	}))
+
+	for i, eventType := range []string{EventNewGroupMessage, EventUserTyping, EventNewNotification} {
+		event := newRealtimeEvent("group-messages-g1", eventType, map[string]int{"n": i})
+		event.ID = eventType
+		require.NoError(t, publisher.Publish(event))
+	}
+
+	// Events arrive in the order they were published
+	for _, eventType := range []string{EventNewGroupMessage, EventUserTyping, EventNewNotification} {
+		select {
+		case event := <-received:
+			assert.Equal(t, eventType, event.ID)
+			assert.Equal(t, eventType, event.Type)
+			assert.Equal(t, "group-messages-g1", event.Channel)
+			assert.Equal(t, RealtimeProtocolVersion, event.Version)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %s was not received", eventType)
+		}
+	}
+}
+
+func TestRedisBackplanePublishDoesNotWaitForRedis(t *testing.T) {
+	// The server accepts connections and never answers, so the writer hangs on its first PUBLISH
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	backplane, err := NewRedisBackplane("redis://"+listener.Addr().String(), "realtime-test")
+	require.NoError(t, err)
+
+	event := newRealtimeEvent("group-messages-g1", EventNewGroupMessage, "hello")
+	started := time.Now()
+	var full int
+	for i := 0; i < redisPublishQueue+10; i++ {
+		if err := backplane.Publish(event); err != nil {
+			assert.ErrorIs(t, err, errBackplaneFull)
+			full++
+		}
+	}
+	assert.Less(t, time.Since(started), time.Second)
+	// The writer may hold one of the events already
+	assert.GreaterOrEqual(t, full, 9)
+
+	// Closing does not wait for the pending PUBLISH either
+	started = time.Now()
+	require.NoError(t, backplane.Close())
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+func TestRedisBackplaneRejectsEventsOnceClosed(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	backplane, err := NewRedisBackplane("redis://"+server.Addr(), "realtime-test")
+	require.NoError(t, err)
+	require.NoError(t, backplane.Subscribe(func(RealtimeEvent) error { return nil }))
+	require.NoError(t, backplane.Close())
+	require.NoError(t, backplane.Close())
+
+	event := newRealtimeEvent("group-messages-g1", EventNewGroupMessage, "hello")
+	assert.ErrorIs(t, backplane.Publish(event), errBackplaneClosed)
+}
+
+func TestNewRedisBackplaneRejectsInvalidURLs(t *testing.T) {
+	for _, url := range []string{"http://localhost:6379", "redis://localhost:6379/notadb", "::"} {
+		_, err := NewRedisBackplane(url, "realtime-test")
+		assert.Error(t, err, url)
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import "time"
+
+const (
+	// realtimeHistorySize and realtimeHistoryTTL bound the events kept for the clients that
+	// resume after a network blip
+	realtimeHistorySize = 1000
+	realtimeHistoryTTL  = 5 * time.Minute
+)
+
+// realtimeEphemeral lists the events that are stale by the time a client resumes, like
+// someone starting to type. They are not sent again.
+var realtimeEphemeral = map[string]bool{
+	EventUserTyping:      true,
+	EventPresenceChanged: true,
+}
+
+// realtimeHistoryEntry is a delivered event with its encoding. Ephemeral events have no
+// encoding; they are only kept so that clients may resume after them.
+type realtimeHistoryEntry struct {
+	id          string
+	channel     string
+	publishedAt time.Time
+	frame       []byte
+}
+
+// realtimeHistory keeps the last events delivered, in delivery order, in a ring of
+// realtimeHistorySize entries. It is not safe for concurrent use.
+type realtimeHistory struct {
+	entries []realtimeHistoryEntry
+	// start is the index of the oldest entry, count the number of entries
+	start int
+	count int
+}
+
+// at returns the i-th oldest entry
+func (h *realtimeHistory) at(i int) *realtimeHistoryEntry {
+	return &h.entries[(h.start+i)%len(h.entries)]
+}
+
+// add keeps a delivered event and its encoding, replacing the oldest one when the history
+// is full, and forgets the expired ones
+func (h *realtimeHistory) add(event RealtimeEvent, frame []byte) {
+	if h.entries == nil {
+		h.entries = make([]realtimeHistoryEntry, realtimeHistorySize)
+	}
+	for h.count > 0 && time.Since(h.at(0).publishedAt) > realtimeHistoryTTL {
+		*h.at(0) = realtimeHistoryEntry{}
+		h.start = (h.start + 1) % len(h.entries)
+		h.count--
+	}
+
+	entry := realtimeHistoryEntry{id: event.ID, channel: event.Channel, publishedAt: event.PublishedAt}
+	if !realtimeEphemeral[event.Type] {
+		entry.frame = frame
+	}
+	if h.count == len(h.entries) {
+		*h.at(0) = entry
+		h.start = (h.start + 1) % len(h.entries)
+		return
+	}
+	*h.at(h.count) = entry
+	h.count++
+}
+
+// since returns the encoded events of the channels delivered after the event lastEventID,
+// leaving out the ephemeral ones. ok is false when that event is not in the history anymore,
+// or more than limit events followed it.
+func (h *realtimeHistory) since(lastEventID string, channels []string, limit int) (frames [][]byte, ok bool) {
+	for i := h.count - 1; i >= 0; i-- {
+		last := h.at(i)
+		if time.Since(last.publishedAt) > realtimeHistoryTTL {
+			return nil, false
+		}
+		if last.id != lastEventID {
+			continue
+		}
+		for j := i + 1; j < h.count; j++ {
+			entry := h.at(j)
+			if entry.frame != nil && containsString(channels, entry.channel) {
+				frames = append(frames, entry.frame)
+			}
+		}
+		return frames, len(frames) <= limit
+	}
+	return nil, false
+}
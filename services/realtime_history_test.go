@@ -0,0 +1,194 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// addHistoryEvents adds the events of the channel with IDs e<from> to e<to>, published at
+// the given time
+func addHistoryEvents(h *realtimeHistory, channel, eventType string, from, to int, publishedAt time.Time) {
+	for i := from; i <= to; i++ {
+		event := newRealtimeEvent(channel, eventType, nil)
+		event.ID = fmt.Sprintf("e%d", i)
+		event.PublishedAt = publishedAt
+		h.add(event, []byte(event.ID))
+	}
+}
+
+func frameIDs(frames [][]byte) []string {
+	ids := []string{}
+	for _, frame := range frames {
+		ids = append(ids, string(frame))
+	}
+	return ids
+}
+
+func TestRealtimeHistorySince(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		fill        func(h *realtimeHistory)
+		lastEventID string
+		channels    []string
+		limit       int
+		want        []string
+		wantOK      bool
+	}{
+		{
+			name:        "events after the last one",
+			fill:        func(h *realtimeHistory) { addHistoryEvents(h, "a", EventNewMessage, 1, 5, now) },
+			lastEventID: "e2",
+			channels:    []string{"a"},
+			limit:       10,
+			want:        []string{"e3", "e4", "e5"},
+			wantOK:      true,
+		},
+		{
+			name:        "nothing missed",
+			fill:        func(h *realtimeHistory) { addHistoryEvents(h, "a", EventNewMessage, 1, 5, now) },
+			lastEventID: "e5",
+			channels:    []string{"a"},
+			limit:       10,
+			want:        []string{},
+			wantOK:      true,
+		},
+		{
+			name: "only the channels asked for",
+			fill: func(h *realtimeHistory) {
+				addHistoryEvents(h, "a", EventNewMessage, 1, 2, now)
+				addHistoryEvents(h, "b", EventNewMessage, 3, 4, now)
+				addHistoryEvents(h, "a", EventNewMessage, 5, 5, now)
+				addHistoryEvents(h, "c", EventNewMessage, 6, 6, now)
+			},
+			lastEventID: "e1",
+			channels:    []string{"a", "c"},
+			limit:       10,
+			want:        []string{"e2", "e5", "e6"},
+			wantOK:      true,
+		},
+		{
+			name: "the last event may be on another channel",
+			fill: func(h *realtimeHistory) {
+				addHistoryEvents(h, "b", EventNewMessage, 1, 1, now)
+				addHistoryEvents(h, "a", EventNewMessage, 2, 2, now)
+			},
+			lastEventID: "e1",
+			channels:    []string{"a"},
+			limit:       10,
+			want:        []string{"e2"},
+			wantOK:      true,
+		},
+		{
+			name: "typing and presence are not replayed",
+			fill: func(h *realtimeHistory) {
+				addHistoryEvents(h, "a", EventNewMessage, 1, 1, now)
+				addHistoryEvents(h, "a", EventUserTyping, 2, 2, now)
+				addHistoryEvents(h, "a", EventPresenceChanged, 3, 3, now)
+				addHistoryEvents(h, "a", EventNewMessage, 4, 4, now)
+			},
+			lastEventID: "e1",
+			channels:    []string{"a"},
+			limit:       10,
+			want:        []string{"e4"},
+			wantOK:      true,
+		},
+		{
+			name: "resume after a typing event",
+			fill: func(h *realtimeHistory) {
+				addHistoryEvents(h, "a", EventUserTyping, 1, 1, now)
+				addHistoryEvents(h, "a", EventNewMessage, 2, 2, now)
+			},
+			lastEventID: "e1",
+			channels:    []string{"a"},
+			limit:       10,
+			want:        []string{"e2"},
+			wantOK:      true,
+		},
+		{
+			name:        "unknown event",
+			fill:        func(h *realtimeHistory) { addHistoryEvents(h, "a", EventNewMessage, 1, 5, now) },
+			lastEventID: "e9",
+			channels:    []string{"a"},
+			limit:       10,
+		},
+		{
+			name:        "empty history",
+			fill:        func(h *realtimeHistory) {},
+			lastEventID: "e1",
+			channels:    []string{"a"},
+			limit:       10,
+		},
+		{
+			name:        "too many missed events",
+			fill:        func(h *realtimeHistory) { addHistoryEvents(h, "a", EventNewMessage, 1, 5, now) },
+			lastEventID: "e1",
+			channels:    []string{"a"},
+			limit:       3,
+		},
+		{
+			name:        "forgotten once the ring wrapped around",
+			fill:        func(h *realtimeHistory) { addHistoryEvents(h, "a", EventNewMessage, 1, realtimeHistorySize+1, now) },
+			lastEventID: "e1",
+			channels:    []string{"a"},
+			limit:       10,
+		},
+		{
+			name:        "kept until the ring wraps around",
+			fill:        func(h *realtimeHistory) { addHistoryEvents(h, "a", EventNewMessage, 1, realtimeHistorySize+1, now) },
+			lastEventID: fmt.Sprintf("e%d", realtimeHistorySize-1),
+			channels:    []string{"a"},
+			limit:       10,
+			want:        []string{fmt.Sprintf("e%d", realtimeHistorySize), fmt.Sprintf("e%d", realtimeHistorySize+1)},
+			wantOK:      true,
+		},
+		{
+			name: "expired events",
+			fill: func(h *realtimeHistory) {
+				addHistoryEvents(h, "a", EventNewMessage, 1, 2, now.Add(-realtimeHistoryTTL-time.Minute))
+				addHistoryEvents(h, "a", EventNewMessage, 3, 3, now)
+			},
+			lastEventID: "e1",
+			channels:    []string{"a"},
+			limit:       10,
+		},
+		{
+			name: "expired events are forgotten as new ones come",
+			fill: func(h *realtimeHistory) {
+				addHistoryEvents(h, "a", EventNewMessage, 1, 2, now.Add(-realtimeHistoryTTL-time.Minute))
+				addHistoryEvents(h, "a", EventNewMessage, 3, 4, now)
+			},
+			lastEventID: "e3",
+			channels:    []string{"a"},
+			limit:       10,
+			want:        []string{"e4"},
+			wantOK:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h realtimeHistory
+			tt.fill(&h)
+
+			frames, ok := h.since(tt.lastEventID, tt.channels, tt.limit)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, frameIDs(frames))
+			}
+		})
+	}
+}
+
+func TestRealtimeHistoryForgetsExpiredEventsOnAdd(t *testing.T) {
+	var h realtimeHistory
+	addHistoryEvents(&h, "a", EventNewMessage, 1, 3, time.Now().Add(-realtimeHistoryTTL-time.Minute))
+	addHistoryEvents(&h, "a", EventNewMessage, 4, 4, time.Now())
+
+	assert.Equal(t, 1, h.count)
+	assert.Len(t, h.entries, realtimeHistorySize)
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/config"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// Events published on the realtime channels
+const (
+	EventNewMessage        = "new-message"
+	EventNewDirectMessage  = "new-direct-message"
+	EventNewGroupMessage   = "new-group-message"
+	EventNewUnreadMessage  = "new-unread-message"
+	EventUserTyping        = "user-typing"
+	EventMessageRead       = "message-read"
+	EventUpdateUnreadCount = "update-unread-count"
+	EventUpdateTotalUnread = "update-total-unread"
+	EventNewNotification   = "new-notification"
+	EventProjectUpdated    = "project-updated"
+	EventPresenceChanged   = "presence-changed"
+	// EventNotificationUpdated tells a recipient that a notification was read, updated or deleted
+	EventNotificationUpdated = "notification-updated"
+	// EventUnreadCountChanged adds a delta to an unread counter of a user
+	EventUnreadCountChanged = "unread-count-changed"
+	// EventReadPositionChanged tells the participants of a group chat how far a user has read it
+	EventReadPositionChanged = "read-position-changed"
+)
+
+// realtimeSchemas names the schema of the payload of each event
+var realtimeSchemas = map[string]string{
+	EventNewMessage:          "message.v1",
+	EventNewDirectMessage:    "direct-message.v1",
+	EventNewGroupMessage:     "group-message.v1",
+	EventNewUnreadMessage:    "unread-message.v1",
+	EventUserTyping:          "typing.v2",
+	EventMessageRead:         "message-read.v1",
+	EventUpdateUnreadCount:   "unread-count.v1",
+	EventUpdateTotalUnread:   "total-unread.v1",
+	EventNewNotification:     "notification.v1",
+	EventProjectUpdated:      "project.v1",
+	EventPresenceChanged:     "presence.v1",
+	EventNotificationUpdated: "notification-change.v1",
+	EventUnreadCountChanged:  "unread-count-delta.v1",
+	EventReadPositionChanged: "read-position.v1",
+}
+
+// RealtimeProtocolVersion is the version of the realtime protocol, sent as the v of every
+// envelope. It changes when the envelope or the frames do; the payloads are versioned by their
+// schema. Version 2 added the acknowledgement of the frames.
+const RealtimeProtocolVersion = 2
+
+// negotiateRealtimeProtocol returns the version spoken with a client asking for requested.
+// Clients that ask for none speak version 1, and newer clients speak the version of the server.
+func negotiateRealtimeProtocol(requested int) int {
+	if requested < 1 {
+		return 1
+	}
+	if requested > RealtimeProtocolVersion {
+		return RealtimeProtocolVersion
+	}
+	return requested
+}
+
+// RealtimeEvent is the envelope of an event published on a channel of the realtime hub
+type RealtimeEvent struct {
+	Version int `json:"v"`
+	// ID identifies a published event, for acknowledgements and resuming. The answers of the
+	// server to the messages of a client have none.
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	// Schema names the shape of Data, like "group-message.v1"
+	Schema      string      `json:"schema,omitempty"`
+	Data        interface{} `json:"data"`
+	PublishedAt time.Time   `json:"publishedAt"`
+}
+
+// newRealtimeEvent wraps a payload in the envelope of the current protocol version
+func newRealtimeEvent(channel, eventType string, data interface{}) RealtimeEvent {
+	return RealtimeEvent{
+		Version:     RealtimeProtocolVersion,
+		Type:        eventType,
+		Channel:     channel,
+		Schema:      realtimeSchemas[eventType],
+		Data:        data,
+		PublishedAt: time.Now(),
+	}
+}
+
+// RealtimeTransport delivers the events of the hub to the clients subscribed to their channel
+type RealtimeTransport interface {
+	Name() string
+	Deliver(event RealtimeEvent) error
+}
+
+// ErrChannelForbidden is returned when a user subscribes to a channel they cannot read
+var ErrChannelForbidden = errors.New("you cannot subscribe to this channel")
+
+// ChannelAuthorizer returns ErrChannelForbidden when the user may not subscribe to the channel.
+// AuthorizationService.AuthorizeChannel is one.
+type ChannelAuthorizer func(ctx context.Context, uid, channel string) error
+
+// RealtimeHub publishes the domain events on named channels, through every transport clients
+// can connect with. The events go through the backplane first, so that the transports of
+// every instance deliver them to the clients connected to it.
+type RealtimeHub struct {
+	mu         sync.RWMutex
+	backplane  RealtimeBackplane
+	transports []RealtimeTransport
+	// external transports are services reaching the clients of every instance themselves, like
+	// Pusher; only the instance publishing an event delivers it through them
+	external []RealtimeTransport
+}
+
+// Realtime is the hub the services and handlers publish their events on. Without transports,
+// publishing does nothing.
+var Realtime = NewRealtimeHub()
+
+func NewRealtimeHub(transports ...RealtimeTransport) *RealtimeHub {
+	hub := &RealtimeHub{transports: transports}
+	hub.backplane = NewLocalBackplane()
+	hub.backplane.Subscribe(hub.deliver)
+	return hub
+}
+
+// AddTransport delivers the next events through the transport too
+func (h *RealtimeHub) AddTransport(transport RealtimeTransport) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.transports = append(h.transports, transport)
+}
+
+// AddExternalTransport delivers the next events published on this instance through the
+// transport too
+func (h *RealtimeHub) AddExternalTransport(transport RealtimeTransport) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.external = append(h.external, transport)
+}
+
+// SetBackplane carries the next events between the instances through the backplane
+func (h *RealtimeHub) SetBackplane(backplane RealtimeBackplane) error {
+	if err := backplane.Subscribe(h.deliver); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	previous := h.backplane
+	h.backplane = backplane
+	h.mu.Unlock()
+	return previous.Close()
+}
+
+// Publish delivers an event to the subscribers of the channel. The event is delivered
+// through every transport, even when some of them fail.
+func (h *RealtimeHub) Publish(channel, event string, data interface{}) error {
+	h.mu.RLock()
+	backplane, external := h.backplane, h.external
+	h.mu.RUnlock()
+
+	published := newRealtimeEvent(channel, event, data)
+	published.ID = uuid.New().String()
+
+	var errs []error
+	if err := backplane.Publish(published); err != nil {
+		errs = append(errs, fmt.Errorf("failed to publish %s over the %s backplane: %v", event, backplane.Name(), err))
+	}
+	errs = append(errs, deliverRealtimeEvent(external, published))
+	return errors.Join(errs...)
+}
+
+// deliver delivers an event received from the backplane through the transports of this instance
+func (h *RealtimeHub) deliver(event RealtimeEvent) error {
+	h.mu.RLock()
+	transports := h.transports
+	h.mu.RUnlock()
+	return deliverRealtimeEvent(transports, event)
+}
+
+// deliverRealtimeEvent delivers an event through every transport, even when some of them fail
+func deliverRealtimeEvent(transports []RealtimeTransport, event RealtimeEvent) error {
+	var errs []error
+	for _, transport := range transports {
+		if err := transport.Deliver(event); err != nil {
+			errs = append(errs, fmt.Errorf("failed to deliver %s over %s: %v", event.Type, transport.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// InitializeRealtime sets up the transports of the Realtime hub. Pusher is added when it is
+// configured, and the events go through Redis when REALTIME_BACKPLANE is "redis".
+func InitializeRealtime(transports ...RealtimeTransport) error {
+	for _, transport := range transports {
+		Realtime.AddTransport(transport)
+	}
+	if InitializePusher() {
+		Realtime.AddExternalTransport(NewPusherTransport(PusherClient))
+	}
+
+	switch config.RealtimeBackplane {
+	case "", "local":
+		return nil
+	case "redis":
+		backplane, err := NewRedisBackplane(config.RealtimeRedisURL, config.RealtimeRedisChannel)
+		if err != nil {
+			return err
+		}
+		if err := Realtime.SetBackplane(backplane); err != nil {
+			return err
+		}
+		log.Printf("Realtime events go through the Redis channel %s", config.RealtimeRedisChannel)
+		return nil
+	default:
+		return fmt.Errorf("unknown realtime backplane %q", config.RealtimeBackplane)
+	}
+}
+
+// Unread counters of a user
+const (
+	UnreadNotifications  = "notifications"
+	UnreadDirectMessages = "direct-messages"
+	UnreadGroupChats     = "group-chats"
+)
+
+// publishUnreadDelta changes an unread counter of a user on their notifications channel.
+// conversationID names the direct conversation or the group chat of the messages.
+func publishUnreadDelta(uid, kind, conversationID string, delta int) {
+	if delta == 0 {
+		return
+	}
+	payload := models.UnreadCountDelta{Kind: kind, ConversationID: conversationID, Delta: delta}
+	if err := Realtime.Publish(UserNotificationsChannel(uid), EventUnreadCountChanged, payload); err != nil {
+		log.Printf("Error publishing the unread count of user %s: %v", uid, err)
+	}
+}
+
+// UserNotificationsChannel is the channel of the notifications and read receipts of a user
+func UserNotificationsChannel(uid string) string {
+	return "user-notifications-" + uid
+}
+
+// UserNewMessagesChannel is the channel announcing the new group chat messages to a user
+func UserNewMessagesChannel(uid string) string {
+	return "user-notifications-new-msg-" + uid
+}
+
+// UserDirectMessagesChannel is the channel announcing the new direct messages to a user
+func UserDirectMessagesChannel(uid string) string {
+	return "user-notifications-direct-msg-" + uid
+}
+
+// GroupUnreadCountsChannel is the channel of the unread counts of the group chats of a user
+func GroupUnreadCountsChannel(uid string) string {
+	return "group-unread-counts-" + uid
+}
+
+// GroupTotalUnreadChannel is the channel of the unread count of all the group chats of a user
+func GroupTotalUnreadChannel(uid string) string {
+	return "group-total-unread-" + uid
+}
+
+// UserChannels lists the channels of a user, which the user's connections subscribe to
+func UserChannels(uid string) []string {
+	return []string{
+		UserNotificationsChannel(uid),
+		UserNewMessagesChannel(uid),
+		UserDirectMessagesChannel(uid),
+		GroupUnreadCountsChannel(uid),
+		GroupTotalUnreadChannel(uid),
+	}
+}
+
+// Prefixes of the channels of the resources shared by several users
+const (
+	directMessagesChannelPrefix = "private-messages-"
+	groupChatChannelPrefix      = "group-messages-"
+	projectChannelPrefix        = "project-"
+	presenceChannelPrefix       = "presence-"
+)
+
+// DirectMessagesChannel is the channel of the messages of a direct conversation
+func DirectMessagesChannel(conversationID string) string {
+	return directMessagesChannelPrefix + conversationID
+}
+
+// GroupChatChannel is the channel of the messages of a group chat
+func GroupChatChannel(groupChatID string) string {
+	return groupChatChannelPrefix + groupChatID
+}
+
+// ProjectChannel is the channel of the changes of a project
+func ProjectChannel(projectID string) string {
+	return projectChannelPrefix + projectID
+}
+
+// PresenceChannel is the channel of the presence changes of a user
+func PresenceChannel(uid string) string {
+	return presenceChannelPrefix + uid
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisDialTimeout = 5 * time.Second
+	// redisCommandTimeout bounds a PUBLISH and the first SUBSCRIBE
+	redisCommandTimeout = 5 * time.Second
+	// redisPublishQueue bounds the events waiting to be published. Publish fails without
+	// waiting when Redis is too slow to take them.
+	redisPublishQueue = 1024
+)
+
+var (
+	errBackplaneClosed = errors.New("the backplane is closed")
+	errBackplaneFull   = errors.New("too many events are waiting to be published to the backplane")
+)
+
+// RedisBackplane carries the realtime events between the instances over a Redis pub/sub
+// channel. Servers speaking the Redis protocol, like Valkey, KeyDB or Dragonfly, work too.
+// Events are queued and published by a single writer in the background, so that a slow or
+// unreachable server does not hold up the requests publishing them. Events published while
+// the subscription is down are not received; the clients of the instance resume from their
+// last event and fetch the state again when it is gone.
+type RedisBackplane struct {
+	client  *redis.Client
+	channel string
+	queue   chan []byte
+
+	// mu guards pubsub
+	mu     sync.Mutex
+	pubsub *redis.PubSub
+
+	// ctx is canceled by Close, which stops the writer and its PUBLISH
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	// written is closed once the writer stopped
+	written chan struct{}
+}
+
+// NewRedisBackplane creates a backplane publishing on the channel of the server at rawURL,
+// like redis://:password@localhost:6379/0, or rediss:// over TLS
+func NewRedisBackplane(rawURL, channel string) (*RedisBackplane, error) {
+	options, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %v", err)
+	}
+	options.DialTimeout = redisDialTimeout
+
+	b := &RedisBackplane{
+		client:  redis.NewClient(options),
+		channel: channel,
+		queue:   make(chan []byte, redisPublishQueue),
+		written: make(chan struct{}),
+	}
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	go b.write()
+	return b, nil
+}
+
+func (b *RedisBackplane) Name() string {
+	return "redis"
+}
+
+// Publish queues the event for the writer. It fails right away when the queue is full.
+func (b *RedisBackplane) Publish(event RealtimeEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %v", err)
+	}
+
+	if b.ctx.Err() != nil {
+		return errBackplaneClosed
+	}
+	select {
+	case b.queue <- payload:
+		return nil
+	default:
+		return errBackplaneFull
+	}
+}
+
+// write publishes the queued events in order until the backplane is closed
+func (b *RedisBackplane) write() {
+	defer close(b.written)
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case payload := <-b.queue:
+			ctx, cancel := context.WithTimeout(b.ctx, redisCommandTimeout)
+			err := b.client.Publish(ctx, b.channel, payload).Err()
+			cancel()
+			if err != nil && b.ctx.Err() == nil {
+				log.Printf("Error publishing to the realtime backplane: %v", err)
+			}
+		}
+	}
+}
+
+// Subscribe subscribes to the channel. The client subscribes again in the background when
+// the connection breaks. It fails when the first subscription does.
+func (b *RedisBackplane) Subscribe(deliver func(RealtimeEvent) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.ctx.Err() != nil {
+		return errBackplaneClosed
+	}
+
+	pubsub := b.client.Subscribe(b.ctx, b.channel)
+	ctx, cancel := context.WithTimeout(b.ctx, redisCommandTimeout)
+	defer cancel()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe to redis: %v", err)
+	}
+
+	b.pubsub = pubsub
+	go b.listen(pubsub.Channel(), deliver)
+	return nil
+}
+
+// listen delivers the received events until the subscription is closed
+func (b *RedisBackplane) listen(messages <-chan *redis.Message, deliver func(RealtimeEvent) error) {
+	for message := range messages {
+		var event RealtimeEvent
+		if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+			log.Printf("Error decoding realtime backplane event: %v", err)
+			continue
+		}
+		if err := deliver(event); err != nil {
+			log.Printf("Error delivering realtime event %s: %v", event.ID, err)
+		}
+	}
+}
+
+// Close stops the writer and the subscription, and closes the connections. Events still
+// queued are dropped.
+func (b *RedisBackplane) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		b.mu.Lock()
+		b.cancel()
+		if b.pubsub != nil {
+			err = b.pubsub.Close()
+		}
+		b.mu.Unlock()
+
+		<-b.written
+		if closeErr := b.client.Close(); err == nil {
+			err = closeErr
+		}
+	})
+	return err
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// eventStreamBuffer bounds the events waiting to be written to a stream. Streams that fall
+	// further behind are closed, and their client resumes from its last event.
+	eventStreamBuffer = 256
+	// eventStreamPingPeriod keeps the proxies from closing idle streams
+	eventStreamPingPeriod = 30 * time.Second
+	// eventStreamMaxAge is how long a stream stays open. Clients reconnect right away with the
+	// ID of their last event, which authenticates them again, so that streams do not outlive
+	// their access token or their session.
+	eventStreamMaxAge = 5 * time.Minute
+	// eventStreamRetry is the delay, in milliseconds, clients wait before reconnecting
+	eventStreamRetry = 3000
+)
+
+// eventStreamEvents are the events of the notifications channel of a user sent over their
+// event streams
+var eventStreamEvents = map[string]bool{
+	EventNewNotification:     true,
+	EventNotificationUpdated: true,
+	EventUnreadCountChanged:  true,
+}
+
+// eventStream is the Server-Sent Events stream of a user
+type eventStream struct {
+	// channel is the notifications channel of the user
+	channel   string
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// push queues an encoded event, closing the stream when the client does not keep up
+func (s *eventStream) push(frame []byte) {
+	select {
+	case s.send <- frame:
+	default:
+		s.close()
+	}
+}
+
+func (s *eventStream) close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// EventStreamTransport delivers the notifications of the users and the changes of their
+// unread counters as Server-Sent Events, for the clients and the networks where WebSockets
+// are unreliable. The last events are kept so that reconnecting clients resume where they
+// left off.
+type EventStreamTransport struct {
+	mu sync.Mutex
+	// streams holds the open streams by channel
+	streams map[string]map[*eventStream]bool
+	history realtimeHistory
+}
+
+func NewEventStreamTransport() *EventStreamTransport {
+	return &EventStreamTransport{
+		streams: map[string]map[*eventStream]bool{},
+	}
+}
+
+func (t *EventStreamTransport) Name() string {
+	return "sse"
+}
+
+func (t *EventStreamTransport) Deliver(event RealtimeEvent) error {
+	if !eventStreamEvents[event.Type] {
+		return nil
+	}
+	frame, err := encodeServerSentEvent(event.ID, event.Type, event)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.history.add(event, frame)
+	for stream := range t.streams[event.Channel] {
+		stream.push(frame)
+	}
+	return nil
+}
+
+// encodeServerSentEvent encodes data as an event of the text/event-stream format
+func encodeServerSentEvent(id, event string, data interface{}) ([]byte, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var frame []byte
+	if id != "" {
+		frame = append(frame, "id: "+id+"\n"...)
+	}
+	frame = append(frame, "event: "+event+"\ndata: "...)
+	frame = append(frame, payload...)
+	return append(frame, "\n\n"...), nil
+}
+
+// open adds a stream to those of its user. With a lastEventID, the events published after
+// that event are sent again.
+func (t *EventStreamTransport) open(stream *eventStream, lastEventID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.streams[stream.channel] == nil {
+		t.streams[stream.channel] = map[*eventStream]bool{}
+	}
+	t.streams[stream.channel][stream] = true
+	if lastEventID == "" {
+		return
+	}
+
+	// Holding the lock keeps the new events from overtaking the missed ones
+	frames, ok := t.history.since(lastEventID, []string{stream.channel}, eventStreamBuffer/2)
+	if !ok {
+		frame, _ := encodeServerSentEvent("", realtimeResumeFailed, map[string]string{"lastEventId": lastEventID})
+		stream.push(frame)
+		return
+	}
+	for _, frame := range frames {
+		stream.push(frame)
+	}
+}
+
+func (t *EventStreamTransport) remove(stream *eventStream) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.streams[stream.channel], stream)
+	if len(t.streams[stream.channel]) == 0 {
+		delete(t.streams, stream.channel)
+	}
+	stream.close()
+}
+
+// Stream answers the request with the event stream of the user. Reconnecting clients pass
+// the ID of the last event they received in the Last-Event-ID header, as EventSource does, or
+// as the lastEventId query parameter. When those events are gone, a resume-failed event asks
+// the client to fetch its counters again.
+func (t *EventStreamTransport) Stream(c *fiber.Ctx, uid string) error {
+	lastEventID := c.Get("Last-Event-ID", c.Query("lastEventId"))
+	// The values of the request are only valid until the handler returns, so it is copied
+	lastEventID = strings.Clone(lastEventID)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	// Keeps nginx from buffering the events
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		stream := &eventStream{
+			channel: UserNotificationsChannel(uid),
+			send:    make(chan []byte, eventStreamBuffer),
+			done:    make(chan struct{}),
+		}
+		t.open(stream, lastEventID)
+		defer t.remove(stream)
+		t.write(w, stream)
+	})
+	return nil
+}
+
+// write writes the queued events to the stream until the client leaves, the stream falls
+// behind or reaches eventStreamMaxAge
+func (t *EventStreamTransport) write(w *bufio.Writer, stream *eventStream) {
+	ticker := time.NewTicker(eventStreamPingPeriod)
+	defer ticker.Stop()
+	maxAge := time.NewTimer(eventStreamMaxAge)
+	defer maxAge.Stop()
+
+	w.WriteString("retry: " + strconv.Itoa(eventStreamRetry) + "\n\n")
+	if err := w.Flush(); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case frame := <-stream.send:
+			w.Write(frame)
+			if err := w.Flush(); err != nil {
+				return
+			}
+		case <-ticker.C:
+			w.WriteString(": ping\n\n")
+			if err := w.Flush(); err != nil {
+				return
+			}
+		case <-maxAge.C:
+			return
+		case <-stream.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,587 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+const (
+	websocketWriteWait  = 10 * time.Second
+	websocketPingPeriod = 30 * time.Second
+	// websocketMaxUnacked bounds the frames a client has not acknowledged yet. Clients that fall
+	// further behind are closed and resume from their last event.
+	websocketMaxUnacked = 256
+	// websocketRevalidatePeriod is how often the access token and the subscriptions of a
+	// connection are checked again
+	websocketRevalidatePeriod = time.Minute
+	// websocketCloseUnauthorized closes the connections whose access token expired or was revoked
+	websocketCloseUnauthorized = 4001
+	// websocketCloseBehind closes the connections that do not keep up with their events
+	websocketCloseBehind = 4008
+	// websocketMaxConnections bounds the open connections of a user, across their devices.
+	// Opening one more closes the oldest with websocketCloseReplaced.
+	websocketMaxConnections = 10
+	websocketCloseReplaced  = 4009
+	// maxDeviceName bounds the name a client gives its device
+	maxDeviceName = 64
+)
+
+// Actions of the messages clients send over their connection
+const (
+	realtimeSubscribe   = "subscribe"
+	realtimeUnsubscribe = "unsubscribe"
+	// realtimeAck acknowledges every frame up to a sequence number
+	realtimeAck = "ack"
+	// realtimeAuthenticate replaces the access token of the connection with a renewed one
+	realtimeAuthenticate = "authenticate"
+	// realtimeHeartbeat reports the presence state of the user on the device, like "idle"
+	realtimeHeartbeat = "heartbeat"
+	// realtimeTyping starts the user typing in the conversation of a channel, or stops them
+	// with the "stop" state
+	realtimeTyping = "typing"
+)
+
+// Events answering the messages of the clients
+const (
+	// realtimeConnected tells the client the ID of its connection and of its session, which
+	// identify the device in the session management of the user, and the protocol version
+	// spoken on the connection
+	realtimeConnected     = "connected"
+	realtimeSubscribed    = "subscribed"
+	realtimeUnsubscribed  = "unsubscribed"
+	realtimeAuthenticated = "authenticated"
+	// realtimeResumeFailed tells the client that the events after its last event are not
+	// available anymore, and that it has to fetch the state of the channels again
+	realtimeResumeFailed = "resume-failed"
+	// realtimeTokenExpiring asks the client to send a renewed access token
+	realtimeTokenExpiring = "token-expiring"
+	realtimeError         = "error"
+)
+
+// realtimeCommand is a message of a client, like {"action": "subscribe", "channel": "..."}
+type realtimeCommand struct {
+	Action  string `json:"action"`
+	Channel string `json:"channel"`
+	// LastEventID resumes a subscription after the last event the client received
+	LastEventID string `json:"lastEventId"`
+	Seq         uint64 `json:"seq"`
+	Token       string `json:"token"`
+	// State is the presence state of a heartbeat, or "stop" to stop typing
+	State string `json:"state"`
+}
+
+// PresenceTracker is told when the realtime connections of the users open and close, and
+// about their heartbeats. PresenceService is one.
+type PresenceTracker interface {
+	Connect(ctx context.Context, uid string, device models.PresenceDevice) error
+	Heartbeat(ctx context.Context, uid, connectionID, state string) error
+	Disconnect(ctx context.Context, uid, connectionID string) error
+}
+
+// TypingTracker shares who is typing in the conversations. TypingService is one.
+type TypingTracker interface {
+	Start(ctx context.Context, uid, channel string) error
+	Stop(ctx context.Context, uid, channel string) error
+}
+
+// realtimeConnection is the WebSocket connection of a user
+type realtimeConnection struct {
+	id          string
+	uid         string
+	connectedAt time.Time
+	send        chan []byte
+	done        chan struct{}
+	closeOnce   sync.Once
+	// channels is guarded by the mutex of the transport
+	channels map[string]bool
+
+	// protocol is the negotiated protocol version. Clients of the current version acknowledge
+	// the frames; those of older clients count as acknowledged once they are written.
+	protocol int
+	acks     bool
+
+	mu    sync.Mutex
+	token string
+	// seq is the sequence number of the last frame sent, acked the last one acknowledged
+	seq   uint64
+	acked uint64
+	// closeCode and closeReason are sent to the client when the server closes the connection
+	closeCode   int
+	closeReason string
+}
+
+// withSequence adds the sequence number of a connection to an encoded envelope
+func withSequence(frame []byte, seq uint64) []byte {
+	sequenced := make([]byte, 0, len(frame)+24)
+	sequenced = append(sequenced, `{"seq":`...)
+	sequenced = strconv.AppendUint(sequenced, seq, 10)
+	sequenced = append(sequenced, ',')
+	return append(sequenced, frame[1:]...)
+}
+
+// push numbers and queues an encoded envelope, closing the connection when the client
+// does not keep up
+func (c *realtimeConnection) push(frame []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	c.seq++
+	if c.acks && c.seq-c.acked > websocketMaxUnacked {
+		log.Printf("Closing the realtime connection of user %s, which fell behind", c.uid)
+		c.closeLocked(websocketCloseBehind, "too many unacknowledged events")
+		return
+	}
+	// The queue holds every unacknowledged frame, so it only fills up when acks run ahead
+	// of the writes, or when the frames of a client without acks are not written fast enough
+	select {
+	case c.send <- withSequence(frame, c.seq):
+	default:
+		c.closeLocked(websocketCloseBehind, "too many unacknowledged events")
+	}
+}
+
+// ack acknowledges the frames up to seq. Clients without acks acknowledge nothing.
+func (c *realtimeConnection) ack(seq uint64) {
+	if !c.acks {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if seq > c.acked && seq <= c.seq {
+		c.acked = seq
+	}
+}
+
+func (c *realtimeConnection) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// closeWith closes the connection, telling the client why
+func (c *realtimeConnection) closeWith(code int, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeLocked(code, reason)
+}
+
+func (c *realtimeConnection) closeLocked(code int, reason string) {
+	if c.closeCode == 0 {
+		c.closeCode, c.closeReason = code, reason
+	}
+	c.close()
+}
+
+func (c *realtimeConnection) accessToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+func (c *realtimeConnection) setAccessToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// reply queues an answer to a message of the client
+func (c *realtimeConnection) reply(channel, event string, data interface{}) {
+	frame, err := json.Marshal(newRealtimeEvent(channel, event, data))
+	if err != nil {
+		log.Printf("Error encoding realtime reply: %v", err)
+		return
+	}
+	c.push(frame)
+}
+
+// WebSocketTransport delivers the realtime events to the WebSocket connections subscribed to
+// their channel. Connections are subscribed to the channels of their user when they open, and
+// to the other channels the authorizer allows when they ask. The last events are kept so that
+// reconnecting clients resume where they left off. A user may be connected from several
+// devices at once; each connection gets the events of the user. The presence tracker learns
+// which users are connected, and from which devices.
+type WebSocketTransport struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*realtimeConnection]bool
+	// connections holds the open connections of each user
+	connections map[string]map[*realtimeConnection]bool
+	history     realtimeHistory
+	authorize   ChannelAuthorizer
+	presence    PresenceTracker
+	typing      TypingTracker
+}
+
+func NewWebSocketTransport(authorize ChannelAuthorizer, presence PresenceTracker, typing TypingTracker) *WebSocketTransport {
+	return &WebSocketTransport{
+		subscribers: map[string]map[*realtimeConnection]bool{},
+		connections: map[string]map[*realtimeConnection]bool{},
+		authorize:   authorize,
+		presence:    presence,
+		typing:      typing,
+	}
+}
+
+func (t *WebSocketTransport) Name() string {
+	return "websocket"
+}
+
+func (t *WebSocketTransport) Deliver(event RealtimeEvent) error {
+	frame, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.history.add(event, frame)
+	for conn := range t.subscribers[event.Channel] {
+		conn.push(frame)
+	}
+	return nil
+}
+
+// subscribe subscribes a connection to channels. With a lastEventID, the events of the
+// channels published after that event are sent again.
+func (t *WebSocketTransport) subscribe(conn *realtimeConnection, lastEventID string, channels ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, channel := range channels {
+		if t.subscribers[channel] == nil {
+			t.subscribers[channel] = map[*realtimeConnection]bool{}
+		}
+		t.subscribers[channel][conn] = true
+		conn.channels[channel] = true
+	}
+	if lastEventID == "" {
+		return
+	}
+
+	// Holding the lock keeps the new events from overtaking the missed ones
+	frames, ok := t.history.since(lastEventID, channels, websocketMaxUnacked/2)
+	if !ok {
+		conn.reply("", realtimeResumeFailed, map[string]interface{}{"lastEventId": lastEventID, "channels": channels})
+		return
+	}
+	for _, frame := range frames {
+		conn.push(frame)
+	}
+}
+
+func (t *WebSocketTransport) unsubscribe(conn *realtimeConnection, channel string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.removeLocked(conn, channel)
+}
+
+func (t *WebSocketTransport) removeLocked(conn *realtimeConnection, channel string) {
+	delete(t.subscribers[channel], conn)
+	if len(t.subscribers[channel]) == 0 {
+		delete(t.subscribers, channel)
+	}
+	delete(conn.channels, channel)
+}
+
+// subscriptions lists the channels of a connection
+func (t *WebSocketTransport) subscriptions(conn *realtimeConnection) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	channels := make([]string, 0, len(conn.channels))
+	for channel := range conn.channels {
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+// connect adds a connection to those of its user, closing the oldest one when the user has
+// too many
+func (t *WebSocketTransport) connect(conn *realtimeConnection) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.connections[conn.uid] == nil {
+		t.connections[conn.uid] = map[*realtimeConnection]bool{}
+	}
+	if len(t.connections[conn.uid]) >= websocketMaxConnections {
+		var oldest *realtimeConnection
+		for other := range t.connections[conn.uid] {
+			if oldest == nil || other.connectedAt.Before(oldest.connectedAt) {
+				oldest = other
+			}
+		}
+		delete(t.connections[conn.uid], oldest)
+		oldest.closeWith(websocketCloseReplaced, "too many connections")
+	}
+	t.connections[conn.uid][conn] = true
+}
+
+// disconnect unsubscribes a connection from all its channels and stops its writer
+func (t *WebSocketTransport) disconnect(conn *realtimeConnection) {
+	t.mu.Lock()
+	for channel := range conn.channels {
+		t.removeLocked(conn, channel)
+	}
+	delete(t.connections[conn.uid], conn)
+	if len(t.connections[conn.uid]) == 0 {
+		delete(t.connections, conn.uid)
+	}
+	t.mu.Unlock()
+	conn.close()
+}
+
+// Mount serves the WebSocket connections at /ws. Browsers cannot set headers on WebSocket
+// requests, so the access token is the token query parameter. Reconnecting clients pass the
+// ID of the last event they received as the lastEventId query parameter, and clients may name
+// their device with the device query parameter, and name the protocol version they speak with
+// the protocol query parameter.
+func (t *WebSocketTransport) Mount(router fiber.Router) {
+	router.Use("/ws", func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+
+		claims, err := Tokens.Verify(c.Query("token"))
+		if err != nil {
+			return fiber.ErrUnauthorized
+		}
+		// The values of the request are only valid until the upgrade, so they are copied
+		device := c.Query("device")
+		if len(device) > maxDeviceName {
+			device = device[:maxDeviceName]
+		}
+		c.Locals("uid", claims.UID)
+		c.Locals("token", strings.Clone(c.Query("token")))
+		c.Locals("lastEventId", strings.Clone(c.Query("lastEventId")))
+		c.Locals("protocol", negotiateRealtimeProtocol(c.QueryInt("protocol")))
+		c.Locals("device", models.PresenceDevice{
+			SessionID: claims.SessionID,
+			Device:    strings.Clone(device),
+			UserAgent: strings.Clone(c.Get(fiber.HeaderUserAgent)),
+			IPAddress: strings.Clone(c.IP()),
+		})
+		return c.Next()
+	})
+
+	router.Get("/ws", websocket.New(t.serve))
+
+	// The user ID in the path is kept for the clients connecting to /ws/:userID
+	router.Get("/ws/:userID", func(c *fiber.Ctx) error {
+		if c.Locals("uid") != c.Params("userID") {
+			return fiber.ErrForbidden
+		}
+		return c.Next()
+	}, websocket.New(t.serve))
+}
+
+// serve runs a connection until the client leaves
+func (t *WebSocketTransport) serve(ws *websocket.Conn) {
+	uid, _ := ws.Locals("uid").(string)
+	token, _ := ws.Locals("token").(string)
+	lastEventID, _ := ws.Locals("lastEventId").(string)
+	device, _ := ws.Locals("device").(models.PresenceDevice)
+	protocol, _ := ws.Locals("protocol").(int)
+	conn := &realtimeConnection{
+		id:          uuid.New().String(),
+		uid:         uid,
+		connectedAt: time.Now(),
+		protocol:    protocol,
+		acks:        protocol == RealtimeProtocolVersion,
+		token:       token,
+		// Every unacknowledged frame fits in the queue
+		send:     make(chan []byte, websocketMaxUnacked),
+		done:     make(chan struct{}),
+		channels: map[string]bool{},
+	}
+	t.connect(conn)
+	conn.reply("", realtimeConnected, map[string]interface{}{
+		"connectionId": conn.id,
+		"sessionId":    device.SessionID,
+		"protocol":     conn.protocol,
+	})
+	t.subscribe(conn, lastEventID, UserChannels(uid)...)
+
+	device.ConnectionID, device.ConnectedAt = conn.id, conn.connectedAt
+	if err := t.presence.Connect(context.Background(), uid, device); err != nil {
+		log.Printf("Error recording the connection of user %s: %v", uid, err)
+	}
+	go t.watch(conn)
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		t.write(ws, conn)
+	}()
+
+	for {
+		var command realtimeCommand
+		if err := ws.ReadJSON(&command); err != nil {
+			break
+		}
+		t.handle(conn, command)
+	}
+
+	t.disconnect(conn)
+	if err := t.presence.Disconnect(context.Background(), uid, conn.id); err != nil {
+		log.Printf("Error recording the disconnection of user %s: %v", uid, err)
+	}
+	// The connection is released once the handler returns, so the writer has to stop first
+	<-written
+}
+
+// handle answers a message of the client
+func (t *WebSocketTransport) handle(conn *realtimeConnection, command realtimeCommand) {
+	switch command.Action {
+	case realtimeAck:
+		conn.ack(command.Seq)
+	case realtimeSubscribe:
+		err := t.authorize(context.Background(), conn.uid, command.Channel)
+		if errors.Is(err, ErrChannelForbidden) {
+			conn.reply(command.Channel, realtimeError, map[string]string{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			log.Printf("Error authorizing the subscription of user %s to %s: %v", conn.uid, command.Channel, err)
+			conn.reply(command.Channel, realtimeError, map[string]string{"error": "failed to authorize the subscription"})
+			return
+		}
+		conn.reply(command.Channel, realtimeSubscribed, nil)
+		t.subscribe(conn, command.LastEventID, command.Channel)
+	case realtimeUnsubscribe:
+		t.unsubscribe(conn, command.Channel)
+		conn.reply(command.Channel, realtimeUnsubscribed, nil)
+	case realtimeAuthenticate:
+		claims, err := Tokens.Verify(command.Token)
+		if err != nil || claims.UID != conn.uid {
+			conn.reply("", realtimeError, map[string]string{"error": "invalid access token"})
+			return
+		}
+		conn.setAccessToken(command.Token)
+		conn.reply("", realtimeAuthenticated, map[string]time.Time{"expiresAt": claims.ExpiresAt.Time})
+	case realtimeHeartbeat:
+		err := t.presence.Heartbeat(context.Background(), conn.uid, conn.id, command.State)
+		if errors.Is(err, ErrInvalidPresenceState) {
+			conn.reply("", realtimeError, map[string]string{"error": err.Error()})
+		} else if err != nil {
+			log.Printf("Error recording the heartbeat of user %s: %v", conn.uid, err)
+		}
+	case realtimeTyping:
+		var err error
+		if command.State == "stop" {
+			err = t.typing.Stop(context.Background(), conn.uid, command.Channel)
+		} else {
+			err = t.typing.Start(context.Background(), conn.uid, command.Channel)
+		}
+		if errors.Is(err, ErrChannelForbidden) || errors.Is(err, ErrInvalidTypingChannel) {
+			conn.reply(command.Channel, realtimeError, map[string]string{"error": err.Error()})
+		} else if err != nil {
+			log.Printf("Error sending the typing status of user %s in %s: %v", conn.uid, command.Channel, err)
+			conn.reply(command.Channel, realtimeError, map[string]string{"error": "failed to send the typing status"})
+		}
+	default:
+		conn.reply(command.Channel, realtimeError, map[string]string{"error": "unknown action " + command.Action})
+	}
+}
+
+// watch re-validates the connection until it closes. The connection is kept present meanwhile,
+// in the last state its client reported.
+func (t *WebSocketTransport) watch(conn *realtimeConnection) {
+	ticker := time.NewTicker(websocketRevalidatePeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.revalidate(conn)
+			select {
+			case <-conn.done:
+				return
+			default:
+			}
+			if err := t.presence.Heartbeat(context.Background(), conn.uid, conn.id, ""); err != nil {
+				log.Printf("Error recording the heartbeat of user %s: %v", conn.uid, err)
+			}
+		case <-conn.done:
+			return
+		}
+	}
+}
+
+// revalidate closes the connection once its access token expired or its session was revoked,
+// and unsubscribes it from the channels its user may no longer read, like the group chats
+// they left. Clients renew the token with the authenticate action.
+func (t *WebSocketTransport) revalidate(conn *realtimeConnection) {
+	claims, err := Tokens.Verify(conn.accessToken())
+	if err != nil {
+		conn.closeWith(websocketCloseUnauthorized, "access token expired or revoked")
+		return
+	}
+	if expiresAt := claims.ExpiresAt.Time; time.Until(expiresAt) < 2*websocketRevalidatePeriod {
+		conn.reply("", realtimeTokenExpiring, map[string]time.Time{"expiresAt": expiresAt})
+	}
+
+	for _, channel := range t.subscriptions(conn) {
+		if containsString(UserChannels(conn.uid), channel) {
+			continue
+		}
+		err := t.authorize(context.Background(), conn.uid, channel)
+		if errors.Is(err, ErrChannelForbidden) {
+			t.unsubscribe(conn, channel)
+			conn.reply(channel, realtimeUnsubscribed, map[string]string{"error": err.Error()})
+		} else if err != nil {
+			log.Printf("Error authorizing the subscription of user %s to %s: %v", conn.uid, channel, err)
+		}
+	}
+}
+
+// write writes the queued frames to the connection and keeps it alive. Closing the
+// connection stops the read loop of serve.
+func (t *WebSocketTransport) write(ws *websocket.Conn, conn *realtimeConnection) {
+	ticker := time.NewTicker(websocketPingPeriod)
+	defer func() {
+		ticker.Stop()
+		ws.Close()
+	}()
+
+	for {
+		select {
+		case frame := <-conn.send:
+			ws.SetWriteDeadline(time.Now().Add(websocketWriteWait))
+			if err := ws.WriteMessage(websocket.TextMessage, frame); err != nil {
+				conn.close()
+				return
+			}
+		case <-ticker.C:
+			ws.SetWriteDeadline(time.Now().Add(websocketWriteWait))
+			if err := ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				conn.close()
+				return
+			}
+		case <-conn.done:
+			conn.mu.Lock()
+			code, reason := conn.closeCode, conn.closeReason
+			conn.mu.Unlock()
+			if code != 0 {
+				ws.SetWriteDeadline(time.Now().Add(websocketWriteWait))
+				ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+			}
+			return
+		}
+	}
+}
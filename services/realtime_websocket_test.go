@@ -0,0 +1,175 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRealtimeConnection(acks bool) *realtimeConnection {
+	return &realtimeConnection{
+		uid:      "user-1",
+		acks:     acks,
+		send:     make(chan []byte, websocketMaxUnacked),
+		done:     make(chan struct{}),
+		channels: map[string]bool{},
+	}
+}
+
+func isClosed(conn *realtimeConnection) bool {
+	select {
+	case <-conn.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestWithSequence(t *testing.T) {
+	frame, err := json.Marshal(newRealtimeEvent("a", EventNewMessage, "hello"))
+	require.NoError(t, err)
+
+	var envelope struct {
+		Seq uint64 `json:"seq"`
+		RealtimeEvent
+	}
+	require.NoError(t, json.Unmarshal(withSequence(frame, 42), &envelope))
+	assert.Equal(t, uint64(42), envelope.Seq)
+	assert.Equal(t, "a", envelope.Channel)
+	assert.Equal(t, EventNewMessage, envelope.Type)
+	assert.Equal(t, "hello", envelope.Data)
+}
+
+func TestRealtimeConnectionPush(t *testing.T) {
+	tests := []struct {
+		name string
+		// acks is whether the client acknowledges its frames, from protocol v2 on
+		acks bool
+		// written is whether the writer takes the frames off the queue as they come
+		written bool
+		// ackEvery acknowledges every frame pushed so far each time that many were pushed
+		ackEvery   int
+		pushes     int
+		wantClosed bool
+	}{
+		{name: "acknowledging client keeping up", acks: true, written: true, ackEvery: 100, pushes: 1000},
+		{name: "acknowledging client not acknowledging", acks: true, written: true, pushes: websocketMaxUnacked + 1, wantClosed: true},
+		{name: "acknowledging client at the limit", acks: true, written: true, pushes: websocketMaxUnacked},
+		{name: "client without acks whose frames are written", written: true, pushes: 1000},
+		{name: "client without acks whose frames are not written", pushes: websocketMaxUnacked + 1, wantClosed: true},
+		{name: "client without acks at the limit", pushes: websocketMaxUnacked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestRealtimeConnection(tt.acks)
+			frame := []byte(`{"type":"new-message"}`)
+
+			for i := 1; i <= tt.pushes; i++ {
+				conn.push(frame)
+				if tt.written {
+					select {
+					case <-conn.send:
+					default:
+					}
+				}
+				if tt.ackEvery > 0 && i%tt.ackEvery == 0 {
+					conn.ack(uint64(i))
+				}
+			}
+
+			assert.Equal(t, tt.wantClosed, isClosed(conn))
+			if tt.wantClosed {
+				assert.Equal(t, websocketCloseBehind, conn.closeCode)
+			}
+		})
+	}
+}
+
+func TestRealtimeConnectionAck(t *testing.T) {
+	conn := newTestRealtimeConnection(true)
+	for i := 0; i < 5; i++ {
+		conn.push([]byte(`{"type":"new-message"}`))
+	}
+
+	conn.ack(3)
+	assert.Equal(t, uint64(3), conn.acked)
+	// Acks never go back, nor beyond the frames sent
+	conn.ack(2)
+	assert.Equal(t, uint64(3), conn.acked)
+	conn.ack(6)
+	assert.Equal(t, uint64(3), conn.acked)
+	conn.ack(5)
+	assert.Equal(t, uint64(5), conn.acked)
+
+	// Frames are numbered in order
+	for seq := uint64(1); seq <= 5; seq++ {
+		var envelope struct {
+			Seq uint64 `json:"seq"`
+		}
+		require.NoError(t, json.Unmarshal(<-conn.send, &envelope))
+		assert.Equal(t, seq, envelope.Seq)
+	}
+
+	// The acks of clients without them are ignored
+	legacy := newTestRealtimeConnection(false)
+	legacy.push([]byte(`{"type":"new-message"}`))
+	legacy.ack(1)
+	assert.Equal(t, uint64(0), legacy.acked)
+}
+
+func TestWebSocketTransportResumesSubscriptions(t *testing.T) {
+	transport := NewWebSocketTransport(nil, nil, nil)
+	conn := newTestRealtimeConnection(true)
+
+	var ids []string
+	for _, eventType := range []string{EventNewMessage, EventUserTyping, EventNewMessage} {
+		event := newRealtimeEvent("a", eventType, nil)
+		event.ID = uuid.New().String()
+		ids = append(ids, event.ID)
+		require.NoError(t, transport.Deliver(event))
+	}
+
+	transport.subscribe(conn, ids[0], "a")
+	require.Len(t, conn.send, 1)
+	var resumed RealtimeEvent
+	require.NoError(t, json.Unmarshal(<-conn.send, &resumed))
+	assert.Equal(t, ids[2], resumed.ID)
+
+	// Events delivered after the subscription are sent right away
+	event := newRealtimeEvent("a", EventNewMessage, nil)
+	event.ID = uuid.New().String()
+	require.NoError(t, transport.Deliver(event))
+	require.Len(t, conn.send, 1)
+
+	// A client resuming after a forgotten event is told to fetch the state again
+	other := newTestRealtimeConnection(true)
+	transport.subscribe(other, "forgotten", "a")
+	require.Len(t, other.send, 1)
+	var failed RealtimeEvent
+	require.NoError(t, json.Unmarshal(<-other.send, &failed))
+	assert.Equal(t, realtimeResumeFailed, failed.Type)
+}
+
+func TestNegotiateRealtimeProtocol(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested int
+		want      int
+	}{
+		{name: "no version", requested: 0, want: 1},
+		{name: "invalid version", requested: -3, want: 1},
+		{name: "version 1", requested: 1, want: 1},
+		{name: "current version", requested: RealtimeProtocolVersion, want: RealtimeProtocolVersion},
+		{name: "newer client", requested: RealtimeProtocolVersion + 1, want: RealtimeProtocolVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, negotiateRealtimeProtocol(tt.requested))
+		})
+	}
+}
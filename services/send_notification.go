@@ -6,16 +6,18 @@ import (
 	"time"
 
 	"github.com/rogerjeasy/go-letusconnect/models"
-	"google.golang.org/api/iterator"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 type GeneralNotificationService struct {
-	firestoreClient FirestoreClient // Use the FirestoreClient interface
+	users         repository.UserRepository
+	notifications repository.NotificationRepository
 }
 
-func NewGeneralNotificationService(client FirestoreClient) *GeneralNotificationService {
+func NewGeneralNotificationService(users repository.UserRepository, notifications repository.NotificationRepository) *GeneralNotificationService {
 	return &GeneralNotificationService{
-		firestoreClient: client,
+		users:         users,
+		notifications: notifications,
 	}
 }
 
@@ -24,38 +26,29 @@ func (s *GeneralNotificationService) SendNewUserNotification(ctx context.Context
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	// Get all users from Firestore
-	iter := s.firestoreClient.Collection("users").Documents(ctx)
-	defer iter.Stop()
+	// Get all users
+	users, err := s.users.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch users: %v", err)
+	}
 
 	targetedUsersIDsList := []string{}
 	readStatus := make(map[string]bool)
 
-	// Iterate through all users
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("failed to fetch users: %v", err)
-		}
-
-		data := doc.Data()
-		targetUID, ok := data["uid"].(string)
-		if !ok {
+	for _, target := range users {
+		if target.UID == "" {
 			continue // Skip invalid users without interrupting
 		}
 
 		// Don't include the new user in the targeted users list
-		if targetUID != user.UID {
-			targetedUsersIDsList = append(targetedUsersIDsList, targetUID)
-			readStatus[targetUID] = false
+		if target.UID != user.UID {
+			targetedUsersIDsList = append(targetedUsersIDsList, target.UID)
+			readStatus[target.UID] = false
 		}
 	}
 
 	// Create notification service with error handling
-	notificationService := NewNotificationService(s.firestoreClient)
+	notificationService := NewNotificationService(s.notifications)
 	if notificationService == nil {
 		return fmt.Errorf("failed to create notification service")
 	}
@@ -78,7 +71,7 @@ func (s *GeneralNotificationService) SendNewUserNotification(ctx context.Context
 	}
 
 	// Save the notification with error handling
-	_, err := notificationService.CreateNotification(ctx, notification)
+	_, err = notificationService.CreateNotification(ctx, notification)
 	if err != nil {
 		return fmt.Errorf("failed to create notification: %v", err)
 	}
@@ -106,7 +99,7 @@ func (s *GeneralNotificationService) SendNewGroupMessageNotification(ctx context
 	}
 
 	// Create notification service with error handling
-	notificationService := NewNotificationService(s.firestoreClient)
+	notificationService := NewNotificationService(s.notifications)
 	if notificationService == nil {
 		return fmt.Errorf("failed to create notification service")
 	}
@@ -144,7 +137,7 @@ func (s *GeneralNotificationService) SendConnectionRequestNotification(ctx conte
 
 	readStatus := map[string]bool{toUID: false}
 
-	notificationService := NewNotificationService(s.firestoreClient)
+	notificationService := NewNotificationService(s.notifications)
 	if notificationService == nil {
 		return fmt.Errorf("failed to create notification service")
 	}
@@ -183,7 +176,7 @@ func (s *GeneralNotificationService) SendConnectionAcceptedNotification(ctx cont
 
 	readStatus := map[string]bool{toUID: false}
 
-	notificationService := NewNotificationService(s.firestoreClient)
+	notificationService := NewNotificationService(s.notifications)
 	if notificationService == nil {
 		return fmt.Errorf("failed to create notification service")
 	}
@@ -229,7 +222,7 @@ func (s *GeneralNotificationService) SendProjectJoinRequestNotification(ctx cont
 	}
 
 	// Create notification service with error handling
-	notificationService := NewNotificationService(s.firestoreClient)
+	notificationService := NewNotificationService(s.notifications)
 	if notificationService == nil {
 		return fmt.Errorf("failed to create notification service")
 	}
@@ -260,3 +253,32 @@ func (s *GeneralNotificationService) SendProjectJoinRequestNotification(ctx cont
 
 	return nil
 }
+
+// SendAccountLockedNotification warns a user that their account was locked after too many failed logins
+func (s *GeneralNotificationService) SendAccountLockedNotification(ctx context.Context, user *models.User, until time.Time, ipAddress string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	notification := models.Notification{
+		UserID:    user.UID,
+		ActorID:   user.UID,
+		ActorName: user.Username,
+		ActorType: "system",
+		Type:      models.NotificationTypeSystem,
+		Title:     "Your account was locked",
+		Content: fmt.Sprintf("Your account was locked until %s after too many failed logins from %s. If this was not you, change your password.",
+			until.UTC().Format("Jan 2, 2006 at 3:04 PM UTC"), ipAddress),
+		Category:        "security",
+		Priority:        models.NotificationPriorityHigh,
+		Status:          models.NotificationStatusUnread,
+		ReadStatus:      map[string]bool{user.UID: false},
+		IsImportant:     true,
+		TargetedUsers:   []string{user.UID},
+		DeliveryChannel: "push",
+	}
+
+	if _, err := NewNotificationService(s.notifications).CreateNotification(ctx, notification); err != nil {
+		return fmt.Errorf("failed to create notification: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+// defaultRefreshTokenTTL is how long an unused session stays signed in when none is configured
+const defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+// maxPreviousTokenHashes bounds the rotated refresh tokens remembered per session
+const maxPreviousTokenHashes = 50
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired and revoked refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when a rotated refresh token is presented again.
+	// The session is revoked, since the token was likely stolen.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected, the session has been revoked")
+)
+
+// TokenPair is what a client receives when it signs in or refreshes its session
+type TokenPair struct {
+	SessionID        string
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+type SessionService struct {
+	sessions    repository.SessionRepository
+	revocations repository.RevocationRepository
+	tokens      *TokenService
+	refreshTTL  time.Duration
+}
+
+func NewSessionService(sessions repository.SessionRepository, revocations repository.RevocationRepository, tokens *TokenService, refreshTTL time.Duration) *SessionService {
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+	return &SessionService{
+		sessions:    sessions,
+		revocations: revocations,
+		tokens:      tokens,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+// newRefreshToken returns a refresh token for the session: the session ID and a random secret
+func newRefreshToken(sessionID string) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %v", err)
+	}
+	return sessionID + "." + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession signs the user in on a new device
+func (s *SessionService) CreateSession(ctx context.Context, user *models.User, userAgent, ipAddress string) (*TokenPair, error) {
+	now := time.Now()
+	session := models.Session{
+		ID:                  uuid.New().String(),
+		UID:                 user.UID,
+		Email:               user.Email,
+		Roles:               user.Role,
+		PreviousTokenHashes: []string{},
+		UserAgent:           userAgent,
+		IPAddress:           ipAddress,
+		CreatedAt:           now,
+		LastUsedAt:          now,
+		ExpiresAt:           now.Add(s.refreshTTL),
+	}
+
+	refreshToken, err := newRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	session.RefreshTokenHash = hashRefreshToken(refreshToken)
+
+	if err := s.sessions.Create(ctx, &session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %v", err)
+	}
+
+	return s.issue(&session, refreshToken)
+}
+
+// issue signs an access token for the session
+func (s *SessionService) issue(session *models.Session, refreshToken string) (*TokenPair, error) {
+	accessToken, err := s.tokens.IssueAccessToken(session.UID, session.Email, session.ID, session.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		SessionID:        session.ID,
+		AccessToken:      accessToken,
+		AccessExpiresAt:  time.Now().Add(s.tokens.AccessTokenTTL()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token.
+// Presenting an already rotated refresh token revokes the whole session.
+func (s *SessionService) Refresh(ctx context.Context, refreshToken, userAgent, ipAddress string) (*TokenPair, error) {
+	sessionID, _, ok := strings.Cut(refreshToken, ".")
+	if !ok || sessionID == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	presented := hashRefreshToken(refreshToken)
+	rotated, err := newRefreshToken(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	reused := false
+	session, err := s.sessions.Update(ctx, sessionID, func(session *models.Session) error {
+		now := time.Now()
+		if session.RevokedAt != nil || now.After(session.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		if presented != session.RefreshTokenHash {
+			for _, previous := range session.PreviousTokenHashes {
+				if previous == presented {
+					reused = true
+					session.RevokedAt = &now
+					session.RevokedReason = "refresh token reuse"
+					return nil
+				}
+			}
+			return ErrInvalidRefreshToken
+		}
+
+		session.PreviousTokenHashes = append(session.PreviousTokenHashes, session.RefreshTokenHash)
+		if len(session.PreviousTokenHashes) > maxPreviousTokenHashes {
+			session.PreviousTokenHashes = session.PreviousTokenHashes[len(session.PreviousTokenHashes)-maxPreviousTokenHashes:]
+		}
+		session.RefreshTokenHash = hashRefreshToken(rotated)
+		session.UserAgent = userAgent
+		session.IPAddress = ipAddress
+		session.LastUsedAt = now
+		session.ExpiresAt = now.Add(s.refreshTTL)
+		return nil
+	})
+	if errors.Is(err, repository.ErrNotFound) || errors.Is(err, ErrInvalidRefreshToken) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %v", err)
+	}
+
+	if reused {
+		if err := s.revoke(ctx, sessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	return s.issue(session, rotated)
+}
+
+// ListSessions lists the user's active sessions, most recently used first
+func (s *SessionService) ListSessions(ctx context.Context, uid string) ([]models.Session, error) {
+	sessions, err := s.sessions.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %v", err)
+	}
+
+	now := time.Now()
+	active := []models.Session{}
+	for _, session := range sessions {
+		if session.RevokedAt == nil && now.Before(session.ExpiresAt) {
+			active = append(active, session)
+		}
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].LastUsedAt.After(active[j].LastUsedAt)
+	})
+	return active, nil
+}
+
+// RevokeSession signs one of the user's devices out: its refresh token stops working
+// and its access tokens are rejected until they expire
+func (s *SessionService) RevokeSession(ctx context.Context, uid, sessionID, reason string) error {
+	_, err := s.sessions.Update(ctx, sessionID, func(session *models.Session) error {
+		if session.UID != uid {
+			return repository.ErrNotFound
+		}
+		if session.RevokedAt == nil {
+			now := time.Now()
+			session.RevokedAt = &now
+			session.RevokedReason = reason
+		}
+		return nil
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("session with ID %s not found", sessionID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %v", err)
+	}
+
+	return s.revoke(ctx, sessionID)
+}
+
+// RevokeOtherSessions signs the user out of every device but the current one
+// and returns how many sessions were revoked
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, uid, currentSessionID string) (int, error) {
+	return s.RevokeSessionsExcept(ctx, uid, currentSessionID, "signed out from another device")
+}
+
+// RevokeSessionsExcept signs the user out of every device but the kept session,
+// or of every device when keepSessionID is empty
+func (s *SessionService) RevokeSessionsExcept(ctx context.Context, uid, keepSessionID, reason string) (int, error) {
+	sessions, err := s.ListSessions(ctx, uid)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == keepSessionID {
+			continue
+		}
+		if err := s.RevokeSession(ctx, uid, session.ID, reason); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// revoke adds the session to the revocation list for as long as its access tokens live
+func (s *SessionService) revoke(ctx context.Context, sessionID string) error {
+	if err := s.revocations.Revoke(ctx, sessionID, time.Now().Add(s.tokens.AccessTokenTTL())); err != nil {
+		return fmt.Errorf("failed to revoke session tokens: %v", err)
+	}
+	return nil
+}
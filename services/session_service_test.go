@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTokenService creates a token service signing with a fixed HS256 secret
+func newTestTokenService(t *testing.T) *TokenService {
+	t.Helper()
+	tokens, err := NewTokenService(TokenConfig{SigningKey: "test-signing-key-of-at-least-32-characters"})
+	require.NoError(t, err)
+	return tokens
+}
+
+func newTestSessionService(t *testing.T) (*SessionService, repository.SessionRepository, *TokenService) {
+	t.Helper()
+	sessions := repository.NewMemorySessionRepository()
+	revocations := repository.NewMemoryRevocationRepository()
+	tokens := newTestTokenService(t)
+	tokens.SetRevocationList(revocations)
+	return NewSessionService(sessions, revocations, tokens, time.Hour), sessions, tokens
+}
+
+func TestSessionServiceRefresh(t *testing.T) {
+	user := &models.User{UID: "user-1", Email: "user@example.com", Role: []string{"user"}}
+
+	tests := []struct {
+		name string
+		// present returns the refresh token to present, given the first and the rotated one
+		present func(first, rotated string) string
+		// expire makes the session expire before the refresh
+		expire  bool
+		wantErr error
+		// wantRevoked tells whether the session ends up revoked
+		wantRevoked bool
+	}{
+		{
+			name:    "rotated token",
+			present: func(first, rotated string) string { return rotated },
+		},
+		{
+			name:        "reused token revokes the session",
+			present:     func(first, rotated string) string { return first },
+			wantErr:     ErrRefreshTokenReused,
+			wantRevoked: true,
+		},
+		{
+			name:    "unknown secret",
+			present: func(first, rotated string) string { return first[:len(first)-4] + "AAAA" },
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name:    "unknown session",
+			present: func(first, rotated string) string { return "missing.secret" },
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name:    "malformed token",
+			present: func(first, rotated string) string { return "no-separator" },
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name:    "expired session",
+			present: func(first, rotated string) string { return rotated },
+			expire:  true,
+			wantErr: ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service, sessions, tokens := newTestSessionService(t)
+
+			first, err := service.CreateSession(ctx, user, "agent", "127.0.0.1")
+			require.NoError(t, err)
+			second, err := service.Refresh(ctx, first.RefreshToken, "agent", "127.0.0.1")
+			require.NoError(t, err)
+			assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+			if tt.expire {
+				_, err := sessions.Update(ctx, first.SessionID, func(session *models.Session) error {
+					session.ExpiresAt = time.Now().Add(-time.Minute)
+					return nil
+				})
+				require.NoError(t, err)
+			}
+
+			pair, err := service.Refresh(ctx, tt.present(first.RefreshToken, second.RefreshToken), "agent", "127.0.0.1")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, pair)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, first.SessionID, pair.SessionID)
+				_, err := tokens.Verify(pair.AccessToken)
+				assert.NoError(t, err)
+			}
+
+			session, err := sessions.Get(ctx, first.SessionID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRevoked, session.RevokedAt != nil)
+			if tt.wantRevoked {
+				// Neither the thief nor the user may use the session anymore
+				_, err := service.Refresh(ctx, second.RefreshToken, "agent", "127.0.0.1")
+				assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+				_, err = tokens.Verify(second.AccessToken)
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestSessionServiceRevokeSessionsExcept(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newTestSessionService(t)
+	user := &models.User{UID: "user-1", Email: "user@example.com"}
+
+	current, err := service.CreateSession(ctx, user, "laptop", "127.0.0.1")
+	require.NoError(t, err)
+	other, err := service.CreateSession(ctx, user, "phone", "127.0.0.1")
+	require.NoError(t, err)
+
+	revoked, err := service.RevokeOtherSessions(ctx, user.UID, current.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, revoked)
+
+	_, err = service.Refresh(ctx, other.RefreshToken, "phone", "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = service.Refresh(ctx, current.RefreshToken, "laptop", "127.0.0.1")
+	assert.NoError(t, err)
+
+	assert.Error(t, service.RevokeSession(ctx, "someone-else", current.SessionID, "stolen"))
+}
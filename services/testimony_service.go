@@ -6,23 +6,20 @@ import (
 	"fmt"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/google/uuid"
-	"github.com/rogerjeasy/go-letusconnect/mappers"
 	"github.com/rogerjeasy/go-letusconnect/models"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 type TestimonialService struct {
-	firestoreClient FirestoreClient
-	userService     *UserService
+	testimonials repository.TestimonialRepository
+	userService  *UserService
 }
 
-func NewTestimonialService(fClient FirestoreClient, uService *UserService) *TestimonialService {
+func NewTestimonialService(testimonials repository.TestimonialRepository, uService *UserService) *TestimonialService {
 	return &TestimonialService{
-		firestoreClient: fClient,
-		userService:     uService,
+		testimonials: testimonials,
+		userService:  uService,
 	}
 }
 
@@ -45,10 +42,7 @@ func (s *TestimonialService) CreateTestimonial(ctx context.Context, input models
 	input.IsPublished = false
 	input.Likes = 0
 
-	testimonialData := mappers.MapTestimonialGoToFirestore(input)
-
-	_, err := s.firestoreClient.Collection("testimonials").Doc(input.ID).Set(ctx, testimonialData)
-	if err != nil {
+	if err := s.testimonials.Create(ctx, &input); err != nil {
 		return nil, fmt.Errorf("failed to create testimonial: %v", err)
 	}
 
@@ -67,10 +61,7 @@ func (s *TestimonialService) CreateAlumniTestimonial(ctx context.Context, input
 	}
 
 	input.Testimonial = *testimonial
-	alumniData := mappers.MapAlumniTestimonialGoToFirestore(input)
-
-	_, err = s.firestoreClient.Collection("alumni_testimonials").Doc(input.ID).Set(ctx, alumniData)
-	if err != nil {
+	if err := s.testimonials.CreateAlumni(ctx, &input); err != nil {
 		return nil, fmt.Errorf("failed to create alumni testimonial: %v", err)
 	}
 
@@ -89,10 +80,7 @@ func (s *TestimonialService) CreateStudentSpotlight(ctx context.Context, input m
 	}
 
 	input.Testimonial = *testimonial
-	spotlightData := mappers.MapStudentSpotlightGoToFirestore(input)
-
-	_, err = s.firestoreClient.Collection("student_spotlights").Doc(input.ID).Set(ctx, spotlightData)
-	if err != nil {
+	if err := s.testimonials.CreateSpotlight(ctx, &input); err != nil {
 		return nil, fmt.Errorf("failed to create student spotlight: %v", err)
 	}
 
@@ -101,43 +89,42 @@ func (s *TestimonialService) CreateStudentSpotlight(ctx context.Context, input m
 
 // GetTestimonial retrieves a testimonial by ID
 func (s *TestimonialService) GetTestimonial(ctx context.Context, testimonialID string) (*models.Testimonial, error) {
-	doc, err := s.firestoreClient.Collection("testimonials").Doc(testimonialID).Get(ctx)
+	testimonial, err := s.testimonials.Get(ctx, testimonialID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get testimonial: %v", err)
+		return nil, fmt.Errorf("failed to get testimonial: %w", err)
 	}
-
-	testimonial := mappers.MapTestimonialFirestoreToGo(doc.Data())
-	return &testimonial, nil
+	return testimonial, nil
 }
 
 // GetAlumniTestimonial retrieves an alumni testimonial by ID
 func (s *TestimonialService) GetAlumniTestimonial(ctx context.Context, testimonialID string) (*models.AlumniTestimonial, error) {
-	doc, err := s.firestoreClient.Collection("alumni_testimonials").Doc(testimonialID).Get(ctx)
+	testimonial, err := s.testimonials.GetAlumni(ctx, testimonialID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get alumni testimonial: %v", err)
+		return nil, fmt.Errorf("failed to get alumni testimonial: %w", err)
 	}
-
-	testimonial := mappers.MapAlumniTestimonialFirestoreToGo(doc.Data())
-	return &testimonial, nil
+	return testimonial, nil
 }
 
 // GetStudentSpotlight retrieves a student spotlight by ID
 func (s *TestimonialService) GetStudentSpotlight(ctx context.Context, testimonialID string) (*models.StudentSpotlight, error) {
-	doc, err := s.firestoreClient.Collection("student_spotlights").Doc(testimonialID).Get(ctx)
+	spotlight, err := s.testimonials.GetSpotlight(ctx, testimonialID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get student spotlight: %v", err)
+		return nil, fmt.Errorf("failed to get student spotlight: %w", err)
 	}
-
-	spotlight := mappers.MapStudentSpotlightFirestoreToGo(doc.Data())
-	return &spotlight, nil
+	return spotlight, nil
 }
 
-// UpdateTestimonial updates an existing testimonial
+// UpdateTestimonial replaces the content of an existing testimonial
 func (s *TestimonialService) UpdateTestimonial(ctx context.Context, testimonialID string, updates models.Testimonial) error {
-	updates.UpdatedAt = time.Now()
-	updateData := mappers.MapTestimonialGoToFirestore(updates)
-
-	_, err := s.firestoreClient.Collection("testimonials").Doc(testimonialID).Set(ctx, updateData, firestore.MergeAll)
+	_, err := s.testimonials.Update(ctx, testimonialID, func(testimonial *models.Testimonial) error {
+		testimonial.Type = updates.Type
+		testimonial.Title = updates.Title
+		testimonial.Content = updates.Content
+		testimonial.MediaURLs = updates.MediaURLs
+		testimonial.Tags = updates.Tags
+		testimonial.UpdatedAt = time.Now()
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update testimonial: %v", err)
 	}
@@ -147,36 +134,29 @@ func (s *TestimonialService) UpdateTestimonial(ctx context.Context, testimonialI
 
 // PublishTestimonial publishes a testimonial
 func (s *TestimonialService) PublishTestimonial(ctx context.Context, testimonialID string) error {
-	_, err := s.firestoreClient.Collection("testimonials").Doc(testimonialID).Update(ctx, []firestore.Update{
-		{Path: "is_published", Value: true},
-		{Path: "updated_at", Value: time.Now()},
+	_, err := s.testimonials.Update(ctx, testimonialID, func(testimonial *models.Testimonial) error {
+		testimonial.IsPublished = true
+		testimonial.UpdatedAt = time.Now()
+		return nil
 	})
 	return err
 }
 
 // ListTestimonials retrieves all published testimonials
 func (s *TestimonialService) ListTestimonials(ctx context.Context) ([]models.Testimonial, error) {
-	query := s.firestoreClient.Collection("testimonials").Where("is_published", "==", true)
-
-	docs, err := query.Documents(ctx).GetAll()
-	if err != nil && status.Code(err) != codes.NotFound {
+	testimonials, err := s.testimonials.ListPublished(ctx)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get testimonials: %v", err)
 	}
-
-	var testimonials []models.Testimonial
-	for _, doc := range docs {
-		testimonial := mappers.MapTestimonialFirestoreToGo(doc.Data())
-		testimonials = append(testimonials, testimonial)
-	}
-
 	return testimonials, nil
 }
 
 // AddLike increments the like count for a testimonial
 func (s *TestimonialService) AddLike(ctx context.Context, testimonialID string) error {
-	_, err := s.firestoreClient.Collection("testimonials").Doc(testimonialID).Update(ctx, []firestore.Update{
-		{Path: "likes", Value: firestore.Increment(1)},
-		{Path: "updated_at", Value: time.Now()},
+	_, err := s.testimonials.Update(ctx, testimonialID, func(testimonial *models.Testimonial) error {
+		testimonial.Likes++
+		testimonial.UpdatedAt = time.Now()
+		return nil
 	})
 	return err
 }
@@ -192,8 +172,7 @@ func (s *TestimonialService) DeleteTestimonial(ctx context.Context, testimonialI
 		return errors.New("unauthorized: only the testimonial author can delete it")
 	}
 
-	_, err = s.firestoreClient.Collection("testimonials").Doc(testimonialID).Delete(ctx)
-	if err != nil {
+	if err := s.testimonials.Delete(ctx, testimonialID); err != nil {
 		return fmt.Errorf("failed to delete testimonial: %v", err)
 	}
 
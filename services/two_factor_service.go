@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+)
+
+// PurposeLoginChallenge is the purpose of the tokens that finish a login with a second factor
+const PurposeLoginChallenge = "login_challenge"
+
+const (
+	// totpIssuer names the account in authenticator apps
+	totpIssuer = "LetUsConnect"
+	// loginChallengeTTL is how long a user has to enter their code after their password
+	loginChallengeTTL = 5 * time.Minute
+	// maxChallengeAttempts is how many wrong codes a login challenge allows
+	maxChallengeAttempts = 5
+	// recoveryCodeCount is how many recovery codes an enrollment gets
+	recoveryCodeCount = 10
+)
+
+var (
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrNoPendingEnrollment     = errors.New("start the two-factor enrollment first")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	// ErrInvalidLoginChallenge is returned for unknown, expired, used and exhausted login challenges
+	ErrInvalidLoginChallenge = errors.New("login challenge is invalid or expired, please log in again")
+	// ErrTwoFactorRequired is returned when the roles of a user require a second factor they did not enroll
+	ErrTwoFactorRequired = errors.New("your role requires two-factor authentication, please enable it first")
+	// ErrTwoFactorEnforced is returned when disabling a second factor that the roles of the user require
+	ErrTwoFactorEnforced = errors.New("two-factor authentication cannot be disabled for your role")
+)
+
+// TwoFactorStatus describes the second factor of a user
+type TwoFactorStatus struct {
+	Enabled           bool
+	Required          bool
+	RecoveryCodesLeft int
+}
+
+// TwoFactorService manages the TOTP enrollments of the users and the login challenges
+type TwoFactorService struct {
+	enrollments repository.TwoFactorRepository
+	users       repository.UserRepository
+	challenges  *actionTokens
+	// requiredRoles must use a second factor to use their permissions
+	requiredRoles []string
+}
+
+func NewTwoFactorService(enrollments repository.TwoFactorRepository, users repository.UserRepository, actionTokenRepo repository.ActionTokenRepository, signer *TokenService, requiredRoles []string) *TwoFactorService {
+	return &TwoFactorService{
+		enrollments: enrollments,
+		users:       users,
+		challenges: &actionTokens{
+			repo:        actionTokenRepo,
+			signer:      signer,
+			purpose:     PurposeLoginChallenge,
+			ttl:         loginChallengeTTL,
+			maxAttempts: maxChallengeAttempts,
+		},
+		requiredRoles: requiredRoles,
+	}
+}
+
+// RequiredFor reports whether any of the roles must use a second factor
+func (s *TwoFactorService) RequiredFor(roles []string) bool {
+	for _, role := range roles {
+		for _, required := range s.requiredRoles {
+			if role == required {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsEnabled reports whether the user signs in with a second factor
+func (s *TwoFactorService) IsEnabled(ctx context.Context, uid string) (bool, error) {
+	enrollment, err := s.enrollments.Get(ctx, uid)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch two-factor enrollment: %v", err)
+	}
+	return enrollment.Enabled, nil
+}
+
+// Status describes the second factor of the user
+func (s *TwoFactorService) Status(ctx context.Context, user *models.User) (*TwoFactorStatus, error) {
+	status := &TwoFactorStatus{Required: s.RequiredFor(user.Role)}
+
+	enrollment, err := s.enrollments.Get(ctx, user.UID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch two-factor enrollment: %v", err)
+	}
+
+	status.Enabled = enrollment.Enabled
+	if enrollment.Enabled {
+		status.RecoveryCodesLeft = len(enrollment.RecoveryCodeHashes)
+	}
+	return status, nil
+}
+
+// BeginEnrollment creates a new secret for the user and returns it with its provisioning
+// URI, which the client shows as a QR code. It takes effect once ConfirmEnrollment gets a code.
+func (s *TwoFactorService) BeginEnrollment(ctx context.Context, user *models.User) (string, string, error) {
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	enrollment, err := s.enrollments.Get(ctx, user.UID)
+	if errors.Is(err, repository.ErrNotFound) {
+		enrollment = &models.TwoFactor{UID: user.UID, RecoveryCodeHashes: []string{}}
+	} else if err != nil {
+		return "", "", fmt.Errorf("failed to fetch two-factor enrollment: %v", err)
+	}
+	if enrollment.Enabled {
+		return "", "", ErrTwoFactorAlreadyEnabled
+	}
+
+	enrollment.PendingSecret = secret
+	enrollment.UpdatedAt = time.Now()
+	if err := s.enrollments.Put(ctx, enrollment); err != nil {
+		return "", "", fmt.Errorf("failed to save two-factor enrollment: %v", err)
+	}
+
+	return secret, totpProvisioningURI(totpIssuer, user.Email, secret), nil
+}
+
+// ConfirmEnrollment enables the pending secret once the user entered a code it generated,
+// and returns the recovery codes. They are only ever shown here.
+func (s *TwoFactorService) ConfirmEnrollment(ctx context.Context, uid, code string) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.enrollments.Update(ctx, uid, func(enrollment *models.TwoFactor) error {
+		if enrollment.Enabled {
+			return ErrTwoFactorAlreadyEnabled
+		}
+		if enrollment.PendingSecret == "" {
+			return ErrNoPendingEnrollment
+		}
+
+		step, err := matchTOTP(enrollment.PendingSecret, code, time.Now(), enrollment.LastUsedStep)
+		if err != nil {
+			return err
+		}
+		if step < 0 {
+			return ErrInvalidTwoFactorCode
+		}
+
+		now := time.Now()
+		enrollment.Secret = enrollment.PendingSecret
+		enrollment.PendingSecret = ""
+		enrollment.Enabled = true
+		enrollment.EnabledAt = &now
+		enrollment.LastUsedStep = step
+		enrollment.RecoveryCodeHashes = hashes
+		enrollment.UpdatedAt = now
+		return nil
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNoPendingEnrollment
+	}
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns the second factor off after checking a code
+func (s *TwoFactorService) Disable(ctx context.Context, user *models.User, code string) error {
+	if s.RequiredFor(user.Role) {
+		return ErrTwoFactorEnforced
+	}
+	if err := s.Verify(ctx, user.UID, code); err != nil {
+		return err
+	}
+	if err := s.enrollments.Delete(ctx, user.UID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to delete two-factor enrollment: %v", err)
+	}
+	return nil
+}
+
+// Reset removes the second factor of a user who lost it. It is meant for admins.
+func (s *TwoFactorService) Reset(ctx context.Context, uid string) error {
+	err := s.enrollments.Delete(ctx, uid)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete two-factor enrollment: %v", err)
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a code
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, uid, code string) ([]string, error) {
+	if err := s.Verify(ctx, uid, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.enrollments.Update(ctx, uid, func(enrollment *models.TwoFactor) error {
+		enrollment.RecoveryCodeHashes = hashes
+		enrollment.UpdatedAt = time.Now()
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %v", err)
+	}
+	return codes, nil
+}
+
+// Verify checks a TOTP code or a recovery code of the user. Each code works once.
+func (s *TwoFactorService) Verify(ctx context.Context, uid, code string) error {
+	_, err := s.enrollments.Update(ctx, uid, func(enrollment *models.TwoFactor) error {
+		if !enrollment.Enabled {
+			return ErrTwoFactorNotEnabled
+		}
+
+		step, err := matchTOTP(enrollment.Secret, code, time.Now(), enrollment.LastUsedStep)
+		if err != nil {
+			return err
+		}
+		if step >= 0 {
+			enrollment.LastUsedStep = step
+			enrollment.UpdatedAt = time.Now()
+			return nil
+		}
+
+		hash := hashRecoveryCode(code)
+		for i, stored := range enrollment.RecoveryCodeHashes {
+			if stored == hash {
+				enrollment.RecoveryCodeHashes = append(enrollment.RecoveryCodeHashes[:i], enrollment.RecoveryCodeHashes[i+1:]...)
+				enrollment.UpdatedAt = time.Now()
+				return nil
+			}
+		}
+		return ErrInvalidTwoFactorCode
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTwoFactorNotEnabled
+	}
+	return err
+}
+
+// CreateChallenge issues the token that lets a user whose password was checked
+// finish their login with a code
+func (s *TwoFactorService) CreateChallenge(ctx context.Context, user *models.User) (string, time.Time, error) {
+	token, err := s.challenges.issue(ctx, user)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, time.Now().Add(s.challenges.ttl), nil
+}
+
+// ChallengeUser returns the user of a login challenge without counting an attempt, so that
+// the failed logins of their account are checked before the code
+func (s *TwoFactorService) ChallengeUser(ctx context.Context, challenge string) (*models.User, error) {
+	claims, err := s.challenges.verify(challenge)
+	if err != nil {
+		return nil, ErrInvalidLoginChallenge
+	}
+
+	user, err := s.users.GetByUID(ctx, claims.UID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidLoginChallenge
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %v", err)
+	}
+	return user, nil
+}
+
+// CompleteChallenge checks the code of a login challenge and returns the user to sign in.
+// A challenge stops working after a few wrong codes.
+func (s *TwoFactorService) CompleteChallenge(ctx context.Context, challenge, code string) (*models.User, error) {
+	claims, err := s.challenges.verify(challenge)
+	if err != nil {
+		return nil, ErrInvalidLoginChallenge
+	}
+
+	if err := s.challenges.attempt(ctx, claims); err != nil {
+		if errors.Is(err, errActionTokenInvalid) {
+			return nil, ErrInvalidLoginChallenge
+		}
+		return nil, err
+	}
+	if err := s.Verify(ctx, claims.UID, code); err != nil {
+		return nil, err
+	}
+
+	if err := s.challenges.use(ctx, claims); err != nil {
+		if errors.Is(err, errActionTokenInvalid) {
+			return nil, ErrInvalidLoginChallenge
+		}
+		return nil, err
+	}
+
+	user, err := s.users.GetByUID(ctx, claims.UID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %v", err)
+	}
+	return user, nil
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCodes returns new recovery codes, like "7kq2m-xa4pd", and their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		random := make([]byte, 7)
+		if _, err := rand.Read(random); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %v", err)
+		}
+		encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(random))[:10]
+		code := encoded[:5] + "-" + encoded[5:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring case, spaces and dashes
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
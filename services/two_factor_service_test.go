@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA-1 secret of the test vectors of RFC 6238, "12345678901234567890"
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// The RFC lists 8-digit codes; the 6-digit ones are their last digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := totpCode(rfc6238Secret, tt.unix/totpPeriod)
+		require.NoError(t, err)
+		assert.Equal(t, tt.code, code, tt.unix)
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / totpPeriod
+	codeAt := func(step int64) string {
+		code, err := totpCode(rfc6238Secret, step)
+		require.NoError(t, err)
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		lastStep int64
+		want     int64
+		wantErr  bool
+	}{
+		{name: "current step", secret: rfc6238Secret, code: codeAt(current), want: current},
+		{name: "lowercase secret", secret: "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", code: codeAt(current), want: current},
+		{name: "previous step", secret: rfc6238Secret, code: codeAt(current - 1), want: current - 1},
+		{name: "next step", secret: rfc6238Secret, code: codeAt(current + 1), want: current + 1},
+		{name: "too old", secret: rfc6238Secret, code: codeAt(current - 2), want: -1},
+		{name: "too far ahead", secret: rfc6238Secret, code: codeAt(current + 2), want: -1},
+		{name: "spaces", secret: rfc6238Secret, code: " " + codeAt(current)[:3] + " " + codeAt(current)[3:] + " ", want: current},
+		{name: "already used", secret: rfc6238Secret, code: codeAt(current), lastStep: current, want: -1},
+		{name: "later step than the used one", secret: rfc6238Secret, code: codeAt(current + 1), lastStep: current, want: current + 1},
+		{name: "too short", secret: rfc6238Secret, code: codeAt(current)[:5], want: -1},
+		{name: "too long", secret: rfc6238Secret, code: codeAt(current) + "0", want: -1},
+		{name: "invalid secret", secret: "not base32!", code: "123456", want: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, err := matchTOTP(tt.secret, tt.code, now, tt.lastStep)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, step)
+		})
+	}
+}
+
+func TestHashRecoveryCodeIgnoresFormatting(t *testing.T) {
+	hash := hashRecoveryCode("7kq2m-xa4pd")
+	for _, code := range []string{"7KQ2M-XA4PD", "7kq2mxa4pd", " 7kq2m xa4pd "} {
+		assert.Equal(t, hash, hashRecoveryCode(code), code)
+	}
+	assert.NotEqual(t, hash, hashRecoveryCode("7kq2m-xa4pe"))
+
+	codes, hashes, err := newRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, codes[0])
+	assert.Equal(t, hashRecoveryCode(codes[0]), hashes[0])
+}
+
+// enrollTwoFactor enables a second factor for the user and returns its secret and recovery codes
+func enrollTwoFactor(t *testing.T, service *TwoFactorService, user *models.User) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	secret, uri, err := service.BeginEnrollment(ctx, user)
+	require.NoError(t, err)
+	assert.Contains(t, uri, "secret="+secret)
+
+	code, err := totpCode(secret, time.Now().Unix()/totpPeriod)
+	require.NoError(t, err)
+	recoveryCodes, err := service.ConfirmEnrollment(ctx, user.UID, code)
+	require.NoError(t, err)
+	return secret, recoveryCodes
+}
+
+func newTestTwoFactorService(t *testing.T) (*TwoFactorService, *models.User) {
+	t.Helper()
+	users := repository.NewMemoryUserRepository()
+	user := &models.User{UID: "user-1", Email: "user@example.com", Role: []string{"user"}}
+	require.NoError(t, users.Create(context.Background(), user))
+
+	service := NewTwoFactorService(repository.NewMemoryTwoFactorRepository(), users,
+		repository.NewMemoryActionTokenRepository(), newTestTokenService(t), []string{"admin"})
+	return service, user
+}
+
+func TestTwoFactorServiceVerify(t *testing.T) {
+	ctx := context.Background()
+	service, user := newTestTwoFactorService(t)
+
+	assert.ErrorIs(t, service.Verify(ctx, user.UID, "123456"), ErrTwoFactorNotEnabled)
+
+	secret, recoveryCodes := enrollTwoFactor(t, service, user)
+	_, _, err := service.BeginEnrollment(ctx, user)
+	assert.ErrorIs(t, err, ErrTwoFactorAlreadyEnabled)
+
+	// The code of the enrollment was used, the next one works once
+	next, err := totpCode(secret, time.Now().Unix()/totpPeriod+1)
+	require.NoError(t, err)
+	assert.NoError(t, service.Verify(ctx, user.UID, next))
+	assert.ErrorIs(t, service.Verify(ctx, user.UID, next), ErrInvalidTwoFactorCode)
+
+	// So does each recovery code, in any case
+	assert.NoError(t, service.Verify(ctx, user.UID, recoveryCodes[0]))
+	assert.ErrorIs(t, service.Verify(ctx, user.UID, recoveryCodes[0]), ErrInvalidTwoFactorCode)
+	assert.NoError(t, service.Verify(ctx, user.UID, " "+recoveryCodes[1]+" "))
+
+	status, err := service.Status(ctx, user)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.False(t, status.Required)
+	assert.Equal(t, recoveryCodeCount-2, status.RecoveryCodesLeft)
+
+	assert.ErrorIs(t, service.Verify(ctx, user.UID, "not-a-code"), ErrInvalidTwoFactorCode)
+}
+
+func TestTwoFactorServiceLoginChallenge(t *testing.T) {
+	ctx := context.Background()
+	service, user := newTestTwoFactorService(t)
+	_, recoveryCodes := enrollTwoFactor(t, service, user)
+
+	challenge, expiresAt, err := service.CreateChallenge(ctx, user)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(loginChallengeTTL), expiresAt, time.Second)
+
+	challenged, err := service.ChallengeUser(ctx, challenge)
+	require.NoError(t, err)
+	assert.Equal(t, user.UID, challenged.UID)
+
+	_, err = service.CompleteChallenge(ctx, challenge, "wrong")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	signedIn, err := service.CompleteChallenge(ctx, challenge, recoveryCodes[0])
+	require.NoError(t, err)
+	assert.Equal(t, user.UID, signedIn.UID)
+
+	// A challenge signs in once
+	_, err = service.CompleteChallenge(ctx, challenge, recoveryCodes[1])
+	assert.ErrorIs(t, err, ErrInvalidLoginChallenge)
+
+	_, err = service.ChallengeUser(ctx, "garbage")
+	assert.ErrorIs(t, err, ErrInvalidLoginChallenge)
+}
+
+func TestTwoFactorServiceLoginChallengeStopsAfterWrongCodes(t *testing.T) {
+	ctx := context.Background()
+	service, user := newTestTwoFactorService(t)
+	_, recoveryCodes := enrollTwoFactor(t, service, user)
+
+	challenge, _, err := service.CreateChallenge(ctx, user)
+	require.NoError(t, err)
+	for i := 0; i < maxChallengeAttempts; i++ {
+		_, err := service.CompleteChallenge(ctx, challenge, "wrong")
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	}
+
+	_, err = service.CompleteChallenge(ctx, challenge, recoveryCodes[0])
+	assert.ErrorIs(t, err, ErrInvalidLoginChallenge)
+}
+
+func TestTwoFactorServiceDisable(t *testing.T) {
+	ctx := context.Background()
+	service, user := newTestTwoFactorService(t)
+	_, recoveryCodes := enrollTwoFactor(t, service, user)
+
+	admin := &models.User{UID: user.UID, Role: []string{"user", "admin"}}
+	assert.ErrorIs(t, service.Disable(ctx, admin, recoveryCodes[0]), ErrTwoFactorEnforced)
+	assert.ErrorIs(t, service.Disable(ctx, user, "wrong"), ErrInvalidTwoFactorCode)
+	require.NoError(t, service.Disable(ctx, user, recoveryCodes[0]))
+
+	enabled, err := service.IsEnabled(ctx, user.UID)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+	assert.ErrorIs(t, service.Reset(ctx, user.UID), ErrTwoFactorNotEnabled)
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+)
+
+// typingTTL is how long a user stays typing after their last start. Clients repeat it every
+// few seconds while the user types.
+const typingTTL = 5 * time.Second
+
+// ErrInvalidTypingChannel is returned for typing in a channel other than a conversation
+var ErrInvalidTypingChannel = errors.New("typing is only shared in direct conversations and group chats")
+
+// typingKey is a user typing in the conversation of a channel
+type typingKey struct {
+	channel string
+	uid     string
+}
+
+// typingState is the expiry of a user typing. The timer fires at the latest at expiresAt.
+type typingState struct {
+	timer     *time.Timer
+	expiresAt time.Time
+}
+
+// TypingService shares who is typing in the direct conversations and the group chats. Typing
+// is not stored: the start and the stop are published on the channel of the conversation, and
+// a user who stops sending starts is stopped after typingTTL.
+type TypingService struct {
+	authorize ChannelAuthorizer
+
+	mu     sync.Mutex
+	typing map[typingKey]*typingState
+}
+
+func NewTypingService(authorize ChannelAuthorizer) *TypingService {
+	return &TypingService{
+		authorize: authorize,
+		typing:    map[typingKey]*typingState{},
+	}
+}
+
+// Start tells the participants of the conversation of the channel that the user is typing,
+// unless they already know
+func (s *TypingService) Start(ctx context.Context, uid, channel string) error {
+	if !isTypingChannel(channel) {
+		return ErrInvalidTypingChannel
+	}
+	if err := s.authorize(ctx, uid, channel); err != nil {
+		return err
+	}
+
+	key := typingKey{channel: channel, uid: uid}
+	expiresAt := time.Now().Add(typingTTL)
+
+	s.mu.Lock()
+	if state, ok := s.typing[key]; ok {
+		state.expiresAt = expiresAt
+		s.mu.Unlock()
+		return nil
+	}
+	state := &typingState{expiresAt: expiresAt}
+	state.timer = time.AfterFunc(typingTTL, func() { s.expire(key, state) })
+	s.typing[key] = state
+	s.mu.Unlock()
+
+	publishTyping(key, true)
+	return nil
+}
+
+// Stop tells the participants of the conversation of the channel that the user stopped
+// typing, like when they sent their message
+func (s *TypingService) Stop(ctx context.Context, uid, channel string) error {
+	if !isTypingChannel(channel) {
+		return ErrInvalidTypingChannel
+	}
+
+	key := typingKey{channel: channel, uid: uid}
+	s.mu.Lock()
+	state, ok := s.typing[key]
+	if ok {
+		state.timer.Stop()
+		delete(s.typing, key)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		publishTyping(key, false)
+	}
+	return nil
+}
+
+// expire stops a user who did not start typing again since the timer was set
+func (s *TypingService) expire(key typingKey, state *typingState) {
+	s.mu.Lock()
+	if s.typing[key] != state {
+		s.mu.Unlock()
+		return
+	}
+	if remaining := time.Until(state.expiresAt); remaining > 0 {
+		state.timer.Reset(remaining)
+		s.mu.Unlock()
+		return
+	}
+	delete(s.typing, key)
+	s.mu.Unlock()
+
+	publishTyping(key, false)
+}
+
+func publishTyping(key typingKey, typing bool) {
+	indicator := models.TypingIndicator{SenderID: key.uid, Typing: typing}
+	if err := Realtime.Publish(key.channel, EventUserTyping, indicator); err != nil {
+		log.Printf("Realtime publish failed: %v", err)
+	}
+}
+
+// isTypingChannel reports whether the channel is that of a direct conversation or a group chat
+func isTypingChannel(channel string) bool {
+	return strings.HasPrefix(channel, directMessagesChannelPrefix) || strings.HasPrefix(channel, groupChatChannelPrefix)
+}
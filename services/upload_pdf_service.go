@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"mime/multipart"
 	"os"
+	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"github.com/google/uuid"
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 type UploadPDFService struct {
-	firestoreClient FirestoreClient
-	cloudinary      *cloudinary.Cloudinary
+	uploads    repository.UploadRepository
+	cloudinary *cloudinary.Cloudinary
 }
 
 type UploadResponse struct {
@@ -21,7 +24,7 @@ type UploadResponse struct {
 	Message string `json:"message"`
 }
 
-func NewUploadPDFService(firestoreClient FirestoreClient, cloudinaryURL string) (*UploadPDFService, error) {
+func NewUploadPDFService(uploads repository.UploadRepository, cloudinaryURL string) (*UploadPDFService, error) {
 	// Initialize Cloudinary
 	cld, err := cloudinary.NewFromURL(cloudinaryURL)
 	if err != nil {
@@ -29,8 +32,8 @@ func NewUploadPDFService(firestoreClient FirestoreClient, cloudinaryURL string)
 	}
 
 	return &UploadPDFService{
-		firestoreClient: firestoreClient,
-		cloudinary:      cld,
+		uploads:    uploads,
+		cloudinary: cld,
 	}, nil
 }
 
@@ -76,7 +79,7 @@ func (s *UploadPDFService) UploadPDF(file *multipart.FileHeader) (*UploadRespons
 		return nil, fmt.Errorf("error uploading to Cloudinary: %v", err)
 	}
 
-	// Store upload record in Firestore if needed
+	// Keep a record of the upload
 	if err := s.storeUploadRecord(ctx, uploadResult.SecureURL, file.Filename); err != nil {
 		// Log error but don't fail the upload
 		fmt.Printf("Error storing upload record: %v\n", err)
@@ -89,11 +92,11 @@ func (s *UploadPDFService) UploadPDF(file *multipart.FileHeader) (*UploadRespons
 }
 
 func (s *UploadPDFService) storeUploadRecord(ctx context.Context, url string, filename string) error {
-	_, _, err := s.firestoreClient.Collection("website-context").Add(ctx, map[string]interface{}{
-		"url":        url,
-		"filename":   filename,
-		"uploadedAt": firestore.ServerTimestamp,
-		"type":       "website-context",
+	return s.uploads.Create(ctx, &models.UploadRecord{
+		ID:         uuid.New().String(),
+		URL:        url,
+		Filename:   filename,
+		Type:       "website-context",
+		UploadedAt: time.Now(),
 	})
-	return err
 }
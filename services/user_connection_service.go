@@ -2,130 +2,87 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
-	"cloud.google.com/go/firestore"
-	"github.com/google/uuid"
-	"github.com/rogerjeasy/go-letusconnect/mappers"
 	"github.com/rogerjeasy/go-letusconnect/models"
-	"google.golang.org/api/iterator"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/rogerjeasy/go-letusconnect/repository"
 )
 
 type UserConnectionService struct {
-	firestoreClient FirestoreClient
-	UserService     *UserService
+	connections repository.ConnectionRepository
+	UserService *UserService
 }
 
-func NewUserConnectionService(client FirestoreClient, userService *UserService) *UserConnectionService {
+func NewUserConnectionService(connections repository.ConnectionRepository, userService *UserService) *UserConnectionService {
 	return &UserConnectionService{
-		firestoreClient: client,
-		UserService:     userService,
+		connections: connections,
+		UserService: userService,
 	}
 }
 
-func (s *UserConnectionService) CreateUserConnections(ctx context.Context, uid string) (*models.UserConnections, error) {
-	connections := models.UserConnections{
-		ID:              uuid.New().String(),
-		UID:             uid,
-		Connections:     make(map[string]models.Connection),
-		PendingRequests: make(map[string]models.ConnectionRequest),
-		SentRequests:    make(map[string]models.SentRequest),
+// GetUserConnections returns the connections of the user, creating an empty record on first use
+func (s *UserConnectionService) GetUserConnections(ctx context.Context, uid string) (*models.UserConnections, error) {
+	connections, err := s.connections.GetByUser(ctx, uid)
+	if err == nil {
+		return connections, nil
 	}
-
-	_, err := s.firestoreClient.Collection("user_connections").Doc(connections.ID).Set(ctx, mappers.MapConnectionsGoToFirestore(connections))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create user connections: %v", err)
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get user connections: %v", err)
 	}
 
-	return &connections, nil
-}
-
-func (s *UserConnectionService) CheckUserConnectionsExist(ctx context.Context, uid string) (bool, string, error) {
-	query := s.firestoreClient.Collection("user_connections").Where("uid", "==", uid).Limit(1)
-	iter := query.Documents(ctx)
-	doc, err := iter.Next()
-
+	var created *models.UserConnections
+	err = s.connections.Update(ctx, []string{uid}, func(records map[string]*models.UserConnections) error {
+		created = records[uid]
+		return nil
+	})
 	if err != nil {
-		if status.Code(err) == codes.NotFound || err == iterator.Done {
-			return false, "", nil
-		}
-		return false, "", fmt.Errorf("failed to check user connections: %v", err)
+		return nil, fmt.Errorf("failed to create user connections: %v", err)
 	}
-
-	return true, doc.Ref.ID, nil
+	return created, nil
 }
 
-// Update GetUserConnections to use this check
-func (s *UserConnectionService) GetUserConnections(ctx context.Context, uid string) (*models.UserConnections, error) {
-	if s.firestoreClient == nil {
-		return nil, fmt.Errorf("firestore client not initialized")
+// IsConnected reports whether uid has an active connection with targetUID
+func (s *UserConnectionService) IsConnected(ctx context.Context, uid, targetUID string) (bool, error) {
+	connections, err := s.connections.GetByUser(ctx, uid)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
 	}
-
-	exists, docID, err := s.CheckUserConnectionsExist(ctx, uid)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check user connections: %v", err)
+		return false, fmt.Errorf("failed to get user connections: %v", err)
 	}
 
-	if !exists {
-		// Create new connections if none exist
-		return s.CreateUserConnections(ctx, uid)
-	}
-
-	// Get existing connections
-	doc, err := s.firestoreClient.Collection("user_connections").Doc(docID).Get(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user connections document: %v", err)
-	}
-
-	data := doc.Data()
-	if data == nil {
-		// If document exists but is empty, create new connections
-		return s.CreateUserConnections(ctx, uid)
-	}
-
-	connections := mappers.MapConnectionsFirestoreToGo(data)
-	return &connections, nil
+	connection, ok := connections.Connections[targetUID]
+	return ok && connection.Status == "active", nil
 }
 
 func (s *UserConnectionService) SendConnectionRequest(ctx context.Context, fromUID, toUID, message string) error {
-	// First check if users exist and get their connections
-	fromConnections, err := s.GetUserConnections(ctx, fromUID)
+	fromUsername, err := s.UserService.GetUsernameByUID(fromUID)
 	if err != nil {
-		return fmt.Errorf("failed to get sender's connections: %v", err)
+		return fmt.Errorf("failed to get username: %v", err)
 	}
 
-	toConnections, err := s.GetUserConnections(ctx, toUID)
-	if err != nil {
-		return fmt.Errorf("failed to get recipient's connections: %v", err)
-	}
+	return s.connections.Update(ctx, []string{fromUID, toUID}, func(records map[string]*models.UserConnections) error {
+		fromConnections, toConnections := records[fromUID], records[toUID]
 
-	// Check if users are already connected
-	if _, exists := fromConnections.Connections[toUID]; exists {
-		return fmt.Errorf("you are already connected with this user")
-	}
-
-	// Check if there's an existing sent request
-	if sentReq, exists := fromConnections.SentRequests[toUID]; exists {
-		if sentReq.Status == "pending" {
-			return fmt.Errorf("you already have a pending connection request to this user")
+		// Check if users are already connected
+		if _, exists := fromConnections.Connections[toUID]; exists {
+			return fmt.Errorf("you are already connected with this user")
 		}
-	}
 
-	// Check if there's an existing pending request from the target user
-	if pendingReq, exists := fromConnections.PendingRequests[toUID]; exists {
-		if pendingReq.Status == "pending" {
-			return fmt.Errorf("this user has already sent you a connection request")
+		// Check if there's an existing sent request
+		if sentReq, exists := fromConnections.SentRequests[toUID]; exists {
+			if sentReq.Status == "pending" {
+				return fmt.Errorf("you already have a pending connection request to this user")
+			}
 		}
-	}
 
-	// Start a transaction for the actual request sending
-	err = s.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		fromUsername, err := s.UserService.GetUsernameByUID(fromUID)
-		if err != nil {
-			return fmt.Errorf("failed to get username: %v", err)
+		// Check if there's an existing pending request from the target user
+		if pendingReq, exists := fromConnections.PendingRequests[toUID]; exists {
+			if pendingReq.Status == "pending" {
+				return fmt.Errorf("this user has already sent you a connection request")
+			}
 		}
 
 		// Create pending request for recipient
@@ -146,68 +103,34 @@ func (s *UserConnectionService) SendConnectionRequest(ctx context.Context, fromU
 			Status:  "pending",
 		}
 
-		// Update both users' records
 		toConnections.PendingRequests[fromUID] = request
 		fromConnections.SentRequests[toUID] = sentRequest
-
-		// Save both updates in transaction
-		err = tx.Set(s.firestoreClient.Collection("user_connections").Doc(fromConnections.ID),
-			mappers.MapConnectionsGoToFirestore(*fromConnections))
-		if err != nil {
-			return fmt.Errorf("failed to update sender's connections: %v", err)
-		}
-
-		err = tx.Set(s.firestoreClient.Collection("user_connections").Doc(toConnections.ID),
-			mappers.MapConnectionsGoToFirestore(*toConnections))
-		if err != nil {
-			return fmt.Errorf("failed to update recipient's connections: %v", err)
-		}
-
-		// if err := SendConnectionRequestNotification(ctx, fromUID, fromUsername, message, toUID); err != nil {
-		// 	fmt.Printf("Failed to send connection request notification: %v\n", err)
-		// }
-
 		return nil
 	})
-
-	if err != nil {
-		return fmt.Errorf("failed to process connection request: %v", err)
-	}
-
-	return nil
 }
 
 func (s *UserConnectionService) AcceptConnectionRequest(ctx context.Context, fromUID, toUID string) error {
-	return s.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		fromConnections, err := s.GetUserConnections(ctx, fromUID)
-		if err != nil {
-			return err
-		}
-		toConnections, err := s.GetUserConnections(ctx, toUID)
-		if err != nil {
-			return err
-		}
+	uidUsername, err := s.UserService.GetUsernameByUID(toUID)
+	if err != nil {
+		return fmt.Errorf("failed to get username: %v", err)
+	}
+	fromUsername, err := s.UserService.GetUsernameByUID(fromUID)
+	if err != nil {
+		return fmt.Errorf("failed to get username: %v", err)
+	}
 
-		uidUsername, err := s.UserService.GetUsernameByUID(toUID)
-		if err != nil {
-			return fmt.Errorf("failed to get username: %v", err)
-		}
+	return s.connections.Update(ctx, []string{fromUID, toUID}, func(records map[string]*models.UserConnections) error {
+		fromConnections, toConnections := records[fromUID], records[toUID]
 
 		now := time.Now()
-		connection1 := models.Connection{
+		fromConnections.Connections[toUID] = models.Connection{
 			TargetUID:  toUID,
 			TargetName: uidUsername,
 			SentAt:     fromConnections.SentRequests[toUID].SentAt,
 			AcceptedAt: now,
 			Status:     "active",
 		}
-
-		fromUsername, err := s.UserService.GetUsernameByUID(fromUID)
-		if err != nil {
-			return fmt.Errorf("failed to get username: %v", err)
-		}
-
-		connection2 := models.Connection{
+		toConnections.Connections[fromUID] = models.Connection{
 			TargetUID:  fromUID,
 			TargetName: fromUsername,
 			SentAt:     toConnections.PendingRequests[fromUID].SentAt,
@@ -215,52 +138,15 @@ func (s *UserConnectionService) AcceptConnectionRequest(ctx context.Context, fro
 			Status:     "active",
 		}
 
-		// Update connections and manage requests
-		fromConnections.Connections[toUID] = connection1
-		toConnections.Connections[fromUID] = connection2
-
-		// Update sent request status
-		if sentReq, exists := fromConnections.SentRequests[toUID]; exists {
-			sentReq.Status = "accepted"
-			sentReq.Accepted = now
-			fromConnections.SentRequests[toUID] = sentReq
-		}
-
 		delete(toConnections.PendingRequests, fromUID)
 		delete(fromConnections.SentRequests, toUID)
-
-		// Save updates
-		err = tx.Set(s.firestoreClient.Collection("user_connections").Doc(fromConnections.ID),
-			mappers.MapConnectionsGoToFirestore(*fromConnections))
-		if err != nil {
-			return err
-		}
-
-		err = tx.Set(s.firestoreClient.Collection("user_connections").Doc(toConnections.ID),
-			mappers.MapConnectionsGoToFirestore(*toConnections))
-		if err != nil {
-			return err
-		}
-
-		// if err := SendConnectionAcceptedNotification(ctx, toUID, fromUsername, uidUsername, fromUID); err != nil {
-		// 	fmt.Printf("Failed to send connection accepted notification: %v\n", err)
-		// }
-
 		return nil
 	})
 }
 
 func (s *UserConnectionService) RejectConnectionRequest(ctx context.Context, fromUID, toUID string) error {
-	return s.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		fromConnections, err := s.GetUserConnections(ctx, fromUID)
-		if err != nil {
-			return err
-		}
-
-		toConnections, err := s.GetUserConnections(ctx, toUID)
-		if err != nil {
-			return err
-		}
+	return s.connections.Update(ctx, []string{fromUID, toUID}, func(records map[string]*models.UserConnections) error {
+		fromConnections, toConnections := records[fromUID], records[toUID]
 
 		// Update sent request status
 		if sentReq, exists := fromConnections.SentRequests[toUID]; exists {
@@ -269,102 +155,173 @@ func (s *UserConnectionService) RejectConnectionRequest(ctx context.Context, fro
 		}
 
 		delete(toConnections.PendingRequests, fromUID)
+		return nil
+	})
+}
 
-		// Save updates
-		err = tx.Set(s.firestoreClient.Collection("user_connections").Doc(fromConnections.ID),
-			mappers.MapConnectionsGoToFirestore(*fromConnections))
-		if err != nil {
-			return err
-		}
-
-		err = tx.Set(s.firestoreClient.Collection("user_connections").Doc(toConnections.ID),
-			mappers.MapConnectionsGoToFirestore(*toConnections))
-		if err != nil {
-			return err
-		}
+func (s *UserConnectionService) RemoveConnection(ctx context.Context, uid1, uid2 string) error {
+	return s.connections.Update(ctx, []string{uid1, uid2}, func(records map[string]*models.UserConnections) error {
+		delete(records[uid1].Connections, uid2)
+		delete(records[uid2].Connections, uid1)
+		return nil
+	})
+}
 
+func (s *UserConnectionService) CancelSentRequest(ctx context.Context, fromUID, toUID string) error {
+	return s.connections.Update(ctx, []string{fromUID, toUID}, func(records map[string]*models.UserConnections) error {
+		// Remove the sent request from the sender and the pending request from the recipient
+		delete(records[fromUID].SentRequests, toUID)
+		delete(records[toUID].PendingRequests, fromUID)
 		return nil
 	})
 }
 
-func (s *UserConnectionService) RemoveConnection(ctx context.Context, uid1, uid2 string) error {
-	err := s.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		// Get both users' connections
-		conn1, err := s.GetUserConnections(ctx, uid1)
-		if err != nil {
-			return err
-		}
-		conn2, err := s.GetUserConnections(ctx, uid2)
-		if err != nil {
-			return err
-		}
+func (s *UserConnectionService) GetUserConnectionCount(ctx context.Context, uid string) (*int, error) {
+	userConnections, error := s.GetUserConnections(ctx, uid)
+	if error != nil {
+		return nil, error
+	}
 
-		// Remove connections
-		delete(conn1.Connections, uid2)
-		delete(conn2.Connections, uid1)
+	connections := len(userConnections.Connections)
 
-		// Update both documents
-		err = tx.Set(s.firestoreClient.Collection("user_connections").Doc(conn1.ID),
-			mappers.MapConnectionsGoToFirestore(*conn1))
-		if err != nil {
-			return err
-		}
+	return &connections, nil
+}
 
-		err = tx.Set(s.firestoreClient.Collection("user_connections").Doc(conn2.ID),
-			mappers.MapConnectionsGoToFirestore(*conn2))
-		if err != nil {
-			return err
-		}
+// MergeConnections moves the connections and requests of the source user to the target
+// user and rewrites the records of the other users. It returns the number of moved entries.
+func (s *UserConnectionService) MergeConnections(ctx context.Context, sourceUID, targetUID string) (int, error) {
+	source, err := s.connections.GetByUser(ctx, sourceUID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
 
-		return nil
-	})
+	targetName, err := s.UserService.GetUsernameByUID(targetUID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get username: %v", err)
+	}
 
-	return err
-}
+	// The records of the other users touched by the merge are updated together
+	uids := []string{sourceUID, targetUID}
+	for uid := range source.Connections {
+		uids = append(uids, uid)
+	}
+	for uid := range source.PendingRequests {
+		uids = append(uids, uid)
+	}
+	for uid := range source.SentRequests {
+		uids = append(uids, uid)
+	}
 
-func (s *UserConnectionService) CancelSentRequest(ctx context.Context, fromUID, toUID string) error {
-	return s.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		// Get both users' connections
-		fromConnections, err := s.GetUserConnections(ctx, fromUID)
-		if err != nil {
-			return err
+	moved := 0
+	err = s.connections.Update(ctx, uids, func(records map[string]*models.UserConnections) error {
+		moved = 0
+		source, target := records[sourceUID], records[targetUID]
+		peer := func(uid string) (*models.UserConnections, error) {
+			if other, ok := records[uid]; ok {
+				return other, nil
+			}
+			return nil, fmt.Errorf("connections of %s changed during the merge", sourceUID)
 		}
 
-		toConnections, err := s.GetUserConnections(ctx, toUID)
-		if err != nil {
-			return err
+		// The two accounts become one, so what links them to each other goes away
+		delete(target.Connections, sourceUID)
+		delete(target.PendingRequests, sourceUID)
+		delete(target.SentRequests, sourceUID)
+
+		for uid, connection := range source.Connections {
+			if uid == targetUID {
+				continue
+			}
+			if _, connected := target.Connections[uid]; !connected {
+				target.Connections[uid] = connection
+				delete(target.PendingRequests, uid)
+				delete(target.SentRequests, uid)
+				moved++
+			}
+
+			other, err := peer(uid)
+			if err != nil {
+				return err
+			}
+			if existing, ok := other.Connections[sourceUID]; ok {
+				delete(other.Connections, sourceUID)
+				if _, connected := other.Connections[targetUID]; !connected {
+					existing.TargetUID = targetUID
+					existing.TargetName = targetName
+					other.Connections[targetUID] = existing
+				}
+				delete(other.PendingRequests, targetUID)
+				delete(other.SentRequests, targetUID)
+			}
 		}
 
-		// Remove the sent request from the sender
-		delete(fromConnections.SentRequests, toUID)
-
-		// Remove the pending request from the recipient
-		delete(toConnections.PendingRequests, fromUID)
-
-		// Save updates for both users
-		err = tx.Set(s.firestoreClient.Collection("user_connections").Doc(fromConnections.ID),
-			mappers.MapConnectionsGoToFirestore(*fromConnections))
-		if err != nil {
-			return err
+		// Requests received by the source user
+		for uid, request := range source.PendingRequests {
+			if uid == targetUID {
+				continue
+			}
+			_, connected := target.Connections[uid]
+			_, pending := target.PendingRequests[uid]
+			_, sent := target.SentRequests[uid]
+			keep := !connected && !pending && !sent
+			if keep {
+				request.ToUID = targetUID
+				target.PendingRequests[uid] = request
+				moved++
+			}
+
+			other, err := peer(uid)
+			if err != nil {
+				return err
+			}
+			if sentRequest, ok := other.SentRequests[sourceUID]; ok {
+				delete(other.SentRequests, sourceUID)
+				if keep {
+					sentRequest.ToUID = targetUID
+					other.SentRequests[targetUID] = sentRequest
+				}
+			}
 		}
 
-		err = tx.Set(s.firestoreClient.Collection("user_connections").Doc(toConnections.ID),
-			mappers.MapConnectionsGoToFirestore(*toConnections))
-		if err != nil {
-			return err
+		// Requests sent by the source user
+		for uid, sentRequest := range source.SentRequests {
+			if uid == targetUID {
+				continue
+			}
+			_, connected := target.Connections[uid]
+			_, pending := target.PendingRequests[uid]
+			_, sent := target.SentRequests[uid]
+			keep := !connected && !pending && !sent
+			if keep {
+				target.SentRequests[uid] = sentRequest
+				moved++
+			}
+
+			other, err := peer(uid)
+			if err != nil {
+				return err
+			}
+			if request, ok := other.PendingRequests[sourceUID]; ok {
+				delete(other.PendingRequests, sourceUID)
+				if keep {
+					request.FromUID = targetUID
+					request.FromName = targetName
+					other.PendingRequests[targetUID] = request
+				}
+			}
 		}
 
+		source.Connections = make(map[string]models.Connection)
+		source.PendingRequests = make(map[string]models.ConnectionRequest)
+		source.SentRequests = make(map[string]models.SentRequest)
 		return nil
 	})
-}
-
-func (s *UserConnectionService) GetUserConnectionCount(ctx context.Context, uid string) (*int, error) {
-	userConnections, error := s.GetUserConnections(ctx, uid)
-	if error != nil {
-		return nil, error
+	if err != nil {
+		return 0, fmt.Errorf("failed to merge connections: %v", err)
 	}
 
-	connections := len(userConnections.Connections)
-
-	return &connections, nil
+	return moved, nil
 }
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rogerjeasy/go-letusconnect/models"
+	"github.com/rogerjeasy/go-letusconnect/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUserConnectionService(t *testing.T) (*UserConnectionService, *repository.Repositories) {
+	t.Helper()
+	ctx := context.Background()
+	repos := repository.NewMemoryRepositories()
+	for _, user := range []*models.User{{UID: "ada", Username: "ada"}, {UID: "bob", Username: "bob"}, {UID: "eve", Username: "eve"}} {
+		require.NoError(t, repos.Users.Create(ctx, user))
+	}
+	return NewUserConnectionService(repos.Connections, NewUserService(repos.Users)), repos
+}
+
+func TestUserConnectionServiceRequestFlow(t *testing.T) {
+	tests := []struct {
+		name          string
+		answer        func(s *UserConnectionService, ctx context.Context) error
+		wantConnected bool
+		wantSent      string
+	}{
+		{
+			name: "accepted",
+			answer: func(s *UserConnectionService, ctx context.Context) error {
+				return s.AcceptConnectionRequest(ctx, "ada", "bob")
+			},
+			wantConnected: true,
+		},
+		{
+			name: "rejected",
+			answer: func(s *UserConnectionService, ctx context.Context) error {
+				return s.RejectConnectionRequest(ctx, "ada", "bob")
+			},
+			wantSent: "rejected",
+		},
+		{
+			name: "cancelled",
+			answer: func(s *UserConnectionService, ctx context.Context) error {
+				return s.CancelSentRequest(ctx, "ada", "bob")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service, _ := newTestUserConnectionService(t)
+
+			require.NoError(t, service.SendConnectionRequest(ctx, "ada", "bob", "hello"))
+			bob, err := service.GetUserConnections(ctx, "bob")
+			require.NoError(t, err)
+			assert.Equal(t, "ada", bob.PendingRequests["ada"].FromName)
+
+			require.NoError(t, tt.answer(service, ctx))
+
+			for _, pair := range [][2]string{{"ada", "bob"}, {"bob", "ada"}} {
+				connected, err := service.IsConnected(ctx, pair[0], pair[1])
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantConnected, connected)
+			}
+
+			ada, err := service.GetUserConnections(ctx, "ada")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSent, ada.SentRequests["bob"].Status)
+			bob, err = service.GetUserConnections(ctx, "bob")
+			require.NoError(t, err)
+			assert.Empty(t, bob.PendingRequests)
+		})
+	}
+}
+
+func TestUserConnectionServiceRejectsDuplicateRequest(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestUserConnectionService(t)
+
+	require.NoError(t, service.SendConnectionRequest(ctx, "ada", "bob", ""))
+	assert.Error(t, service.SendConnectionRequest(ctx, "ada", "bob", ""))
+
+	require.NoError(t, service.AcceptConnectionRequest(ctx, "ada", "bob"))
+	assert.Error(t, service.SendConnectionRequest(ctx, "ada", "bob", ""))
+}
+
+func TestUserConnectionServiceMergeConnections(t *testing.T) {
+	ctx := context.Background()
+	service, repos := newTestUserConnectionService(t)
+	require.NoError(t, repos.Users.Create(ctx, &models.User{UID: "ada2", Username: "ada"}))
+
+	require.NoError(t, service.SendConnectionRequest(ctx, "ada", "bob", ""))
+	require.NoError(t, service.AcceptConnectionRequest(ctx, "ada", "bob"))
+	require.NoError(t, service.SendConnectionRequest(ctx, "eve", "ada", ""))
+
+	moved, err := service.MergeConnections(ctx, "ada", "ada2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+
+	connected, err := service.IsConnected(ctx, "bob", "ada2")
+	require.NoError(t, err)
+	assert.True(t, connected)
+	connected, err = service.IsConnected(ctx, "bob", "ada")
+	require.NoError(t, err)
+	assert.False(t, connected)
+
+	eve, err := service.GetUserConnections(ctx, "eve")
+	require.NoError(t, err)
+	assert.Contains(t, eve.SentRequests, "ada2")
+	assert.NotContains(t, eve.SentRequests, "ada")
+
+	// A user without connections has nothing to merge
+	moved, err = service.MergeConnections(ctx, "nobody", "ada2")
+	require.NoError(t, err)
+	assert.Zero(t, moved)
+}